@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+// newConfigCmd builds `aagent config`, which shows the configuration the
+// other commands run with.
+func newConfigCmd() *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Show the effective configuration",
+		Long: `Configuration is merged from the file aagent saves (` + "`" + `data_path/config.json` + "`" + `),
+the user config (~/.config/aagent/config.yaml) and the project config
+(.aagent/config.yaml), later files overriding the keys they set, and then
+from AAGENT_* environment variables. Files may be YAML or JSON.`,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the merged configuration with secrets masked",
+		Args:  cobra.NoArgs,
+		RunE:  showConfig,
+	}
+	showCmd.Flags().Bool("json", false, "Print JSON instead of YAML")
+	configCmd.AddCommand(showCmd)
+
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "env",
+		Short: "List the environment variables that override config keys",
+		Args:  cobra.NoArgs,
+		RunE:  listConfigEnv,
+	})
+
+	for _, sub := range configCmd.Commands() {
+		sub.SilenceUsage = true
+		sub.SilenceErrors = true
+	}
+	return configCmd
+}
+
+func showConfig(cmd *cobra.Command, args []string) error {
+	homeDir, _ := os.UserHomeDir()
+	godotenv.Load(".env")
+	godotenv.Load(filepath.Join(homeDir, ".env"))
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	for _, key := range cfg.UnknownKeys {
+		fmt.Fprintf(os.Stderr, "warning: unknown config key %s\n", key)
+	}
+
+	masked := cfg.Masked()
+	if wantsJSON(cmd) {
+		return printJSON(masked)
+	}
+
+	// Going through JSON keeps the JSON key names and field order
+	data, err := json.Marshal(masked)
+	if err != nil {
+		return err
+	}
+	var tree yaml.MapSlice
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	out, err := yaml.Marshal(tree)
+	if err != nil {
+		return err
+	}
+	if len(cfg.Files) == 0 {
+		fmt.Println("# No config files; defaults and environment only")
+	}
+	for _, path := range cfg.Files {
+		fmt.Printf("# From %s\n", path)
+	}
+	fmt.Print(string(out))
+	return nil
+}
+
+func listConfigEnv(cmd *cobra.Command, args []string) error {
+	vars := config.EnvVars()
+	width := 0
+	for _, v := range vars {
+		width = max(width, len(v.Name))
+	}
+	for _, v := range vars {
+		line := fmt.Sprintf("%-*s  %s", width, v.Name, v.Key)
+		if v.Alias != "" {
+			line += " (also " + v.Alias + ")"
+		}
+		fmt.Println(line)
+	}
+	fmt.Println()
+	fmt.Println("AAGENT_API_TOKENS adds name:token pairs to server.api_tokens.")
+	return nil
+}
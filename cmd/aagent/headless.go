@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/session"
+)
+
+// Exit codes of a headless run.
+const (
+	exitRunFailed         = 1
+	exitStepLimitReached  = 2
+	headlessStatusLimited = "step_limit_reached"
+	// headlessStatusTimedOut reports a run stopped at max_run_minutes; the
+	// session is paused and content holds the answer so far.
+	headlessStatusTimedOut = "timed_out"
+)
+
+// exitCodeError ends the process with code after the command returns. An
+// empty message means the failure was already reported.
+type exitCodeError struct {
+	code    int
+	message string
+}
+
+func (e *exitCodeError) Error() string {
+	return e.message
+}
+
+// headlessResult is printed to stdout with --output json.
+type headlessResult struct {
+	Content   string        `json:"content"`
+	SessionID string        `json:"session_id"`
+	Status    string        `json:"status"`
+	Steps     int           `json:"steps"`
+	Usage     headlessUsage `json:"usage"`
+	Error     string        `json:"error,omitempty"`
+}
+
+type headlessUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// runHeadless runs the agent on the task already added to sess without the
+// TUI. Progress goes to stderr and the final answer, or a JSON summary, to
+// stdout. It returns an *exitCodeError when the run did not complete.
+func runHeadless(ctx context.Context, ag *agent.Agent, sess *session.Session, task string, output string, stdout, stderr io.Writer) error {
+	steps := 0
+	limitReached := false
+	onEvent := func(event agent.Event) {
+		if event.Step > steps {
+			steps = event.Step
+		}
+		switch event.Type {
+		case agent.EventToolCallStarted:
+			for _, call := range event.ToolCalls {
+				fmt.Fprintf(stderr, "[step %d] %s %s\n", event.Step, call.Name, call.InputPreview)
+			}
+		case agent.EventToolCallFinished:
+			if result := event.ToolResult; result != nil {
+				mark := "ok"
+				if result.IsError {
+					mark = "error"
+				}
+				fmt.Fprintf(stderr, "[step %d] %s %s (%s)\n", event.Step, result.Name, mark, result.Duration.Round(time.Millisecond))
+			}
+		case agent.EventStepLimitReached:
+			limitReached = true
+			fmt.Fprintf(stderr, "[step %d] step limit reached\n", event.Step)
+		}
+	}
+
+	content, usage, runErr := ag.RunWithEvents(ctx, sess, task, onEvent)
+	if runErr == nil && sess.Status == session.StatusRunning {
+		sess.SetStatus(session.StatusCompleted)
+	}
+
+	result := headlessResult{
+		Content:   content,
+		SessionID: sess.ID,
+		Status:    string(sess.Status),
+		Steps:     steps,
+		Usage:     headlessUsage{InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens},
+	}
+	if runErr != nil {
+		result.Error = runErr.Error()
+		if sess.Status == session.StatusRunning || sess.Status == session.StatusCompleted {
+			result.Status = string(session.StatusFailed)
+		}
+	}
+	if limitReached {
+		result.Status = headlessStatusLimited
+	}
+	if errors.Is(runErr, agent.ErrMaxDurationExceeded) {
+		result.Status = headlessStatusTimedOut
+	}
+
+	if output == "json" {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return fmt.Errorf("failed to write result: %w", err)
+		}
+	} else if content != "" {
+		fmt.Fprintln(stdout, content)
+	}
+
+	switch {
+	case runErr != nil:
+		if output != "json" {
+			fmt.Fprintf(stderr, "Run failed: %v\n", runErr)
+		}
+		return &exitCodeError{code: exitRunFailed}
+	case limitReached:
+		return &exitCodeError{code: exitStepLimitReached}
+	case result.Status != string(session.StatusCompleted):
+		if output != "json" {
+			fmt.Fprintf(stderr, "Run ended with status %s (session %s)\n", result.Status, sess.ID)
+		}
+		return &exitCodeError{code: exitRunFailed}
+	}
+	return nil
+}
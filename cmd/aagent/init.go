@@ -0,0 +1,484 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/llm/anthropic"
+	"github.com/A2gent/brute/internal/llm/lmstudio"
+	"github.com/charmbracelet/x/term"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// maxListedModels is the longest model list init prints; longer lists, like
+// OpenRouter's, are only counted.
+const maxListedModels = 30
+
+// initOptions are the flags of `aagent init`.
+type initOptions struct {
+	provider   string
+	apiKeyEnv  string
+	model      string
+	workDir    string
+	dataPath   string
+	skipVerify bool
+}
+
+// newInitCmd builds `aagent init`, which writes the provider, model and
+// directories to the config file aagent saves.
+func newInitCmd() *cobra.Command {
+	var opts initOptions
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "Set up the provider, API key, model and directories",
+		Long: `Asks for the provider, its API key, the default model, the working directory
+and the data path, checks the key by listing the provider's models, and
+writes them to the config file aagent saves (` + "`" + `data_path/config.json` + "`" + `), readable
+by you only. The current settings are the defaults, so init can be run again
+to change one of them.
+
+With --provider nothing is asked: the key is read from the variable named by
+--api-key-env (or the provider's usual variable, e.g. ANTHROPIC_API_KEY, which
+is then not written to the file) and the other settings come from the flags
+or stay as they are.`,
+		Example: `  aagent init
+  aagent init --provider anthropic --api-key-env ANTHROPIC_API_KEY --model claude-sonnet-4-5`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInit(opts)
+		},
+	}
+	initCmd.Flags().StringVar(&opts.provider, "provider", "", "Provider to set up without prompts: kimi, anthropic, gemini, openai, openrouter, lmstudio or ollama")
+	initCmd.Flags().StringVar(&opts.apiKeyEnv, "api-key-env", "", "Environment variable holding the API key to write")
+	initCmd.Flags().StringVarP(&opts.model, "model", "m", "", "Default model (default: the provider's current or default model)")
+	initCmd.Flags().StringVar(&opts.workDir, "workdir", "", "Working directory for agent tools")
+	initCmd.Flags().StringVar(&opts.dataPath, "data-path", "", "Directory for sessions, logs and other data")
+	initCmd.Flags().BoolVar(&opts.skipVerify, "skip-verify", false, "Do not check the API key by listing the provider's models")
+	return initCmd
+}
+
+func runInit(opts initOptions) error {
+	homeDir, _ := os.UserHomeDir()
+	godotenv.Load(".env")
+	godotenv.Load(filepath.Join(homeDir, ".env"))
+
+	path := config.GetConfigPath()
+	tree := map[string]interface{}{}
+	if _, err := os.Stat(path); err == nil {
+		if tree, err = config.ReadConfigFile(path); err != nil {
+			return err
+		}
+	}
+	current, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; starting from the defaults\n", err)
+		current = config.DefaultConfig()
+	}
+
+	wizard := &initWizard{
+		in:          bufio.NewReader(os.Stdin),
+		out:         os.Stdout,
+		interactive: opts.provider == "",
+		listModels:  listProviderModels,
+	}
+	if wizard.interactive {
+		if !term.IsTerminal(os.Stdin.Fd()) {
+			return fmt.Errorf("stdin is not a terminal; pass --provider (and --api-key-env) to set up without prompts")
+		}
+		wizard.readSecret = func() (string, error) {
+			key, err := term.ReadPassword(os.Stdin.Fd())
+			fmt.Fprintln(wizard.out)
+			return string(key), err
+		}
+	}
+
+	settings, err := wizard.run(current, opts)
+	if err != nil {
+		return err
+	}
+	applyInitSettings(tree, settings)
+	if err := config.WriteConfigFile(path, tree); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	fmt.Printf("Wrote %s\n", path)
+
+	// The user and project config files and AAGENT_* variables are applied
+	// after the saved config.
+	if cfg, err := config.Load(); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	} else if cfg.ActiveProvider != string(settings.provider) {
+		fmt.Fprintf(os.Stderr, "warning: the active provider is still %s, set by another config file (%s) or the environment\n", cfg.ActiveProvider, strings.Join(cfg.Files, ", "))
+	}
+	return nil
+}
+
+// initSettings are the answers init writes. Empty fields leave the file's
+// value as it is.
+type initSettings struct {
+	provider config.ProviderType
+	apiKey   string
+	baseURL  string
+	model    string
+	workDir  string
+	dataPath string
+}
+
+// initWizard asks for the settings of `aagent init`, or takes them from the
+// flags and current config when not interactive.
+type initWizard struct {
+	in          *bufio.Reader
+	out         io.Writer
+	interactive bool
+	// readSecret reads the API key without echoing it; nil reads a line
+	// from in.
+	readSecret func() (string, error)
+	listModels func(ctx context.Context, providerType config.ProviderType, apiKey, baseURL string) ([]string, error)
+}
+
+func (w *initWizard) run(current *config.Config, opts initOptions) (initSettings, error) {
+	var settings initSettings
+	var err error
+	if settings.provider, settings.baseURL, err = w.chooseProvider(current, opts.provider); err != nil {
+		return settings, err
+	}
+	existing := current.Providers[string(settings.provider)]
+
+	models, err := w.chooseKey(&settings, existing, opts)
+	if err != nil {
+		return settings, err
+	}
+	if settings.model, err = w.chooseModel(current, settings.provider, models, opts.model); err != nil {
+		return settings, err
+	}
+
+	workDir, dataPath := opts.workDir, opts.dataPath
+	if w.interactive {
+		if workDir == "" {
+			workDir = current.WorkDir
+		}
+		if dataPath == "" {
+			dataPath = current.DataPath
+		}
+	}
+	for workDir != "" {
+		if w.interactive && opts.workDir == "" {
+			if workDir, err = w.ask("Working directory", workDir); err != nil {
+				return settings, err
+			}
+		}
+		settings.workDir, err = resolveWorkDir(expandHome(workDir))
+		if err == nil {
+			break
+		}
+		if !w.interactive || opts.workDir != "" {
+			return settings, err
+		}
+		fmt.Fprintf(w.out, "%v\n", err)
+	}
+	if w.interactive && opts.dataPath == "" {
+		if dataPath, err = w.ask("Data path", dataPath); err != nil {
+			return settings, err
+		}
+	}
+	if dataPath != "" {
+		if settings.dataPath, err = filepath.Abs(expandHome(dataPath)); err != nil {
+			return settings, fmt.Errorf("invalid data path %q: %w", dataPath, err)
+		}
+	}
+	return settings, nil
+}
+
+// initProviders returns the providers init sets up: those used with an API
+// key or a local server. Codex signs in with OAuth from the TUI or web app.
+func initProviders() []config.ProviderDefinition {
+	var defs []config.ProviderDefinition
+	for _, def := range config.SupportedProviders() {
+		switch def.Type {
+		case config.ProviderAutoRouter, config.ProviderFallback, config.ProviderOpenAICodex:
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// chooseProvider returns the provider named by the flag or chosen from the
+// list, with the base URL to write for it, if any.
+func (w *initWizard) chooseProvider(current *config.Config, flag string) (config.ProviderType, string, error) {
+	defs := initProviders()
+	pick := func(answer string) (config.ProviderType, string, error) {
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(defs) {
+			return defs[n-1].Type, "", nil
+		}
+		providerType, ok := resolveProviderName(answer)
+		if !ok || !slices.ContainsFunc(defs, func(def config.ProviderDefinition) bool { return def.Type == providerType }) {
+			return "", "", fmt.Errorf("unknown provider %q", answer)
+		}
+		if config.NormalizeProviderRef(answer) == "ollama" {
+			return providerType, ollamaBaseURL(), nil
+		}
+		return providerType, "", nil
+	}
+	if !w.interactive {
+		return pick(flag)
+	}
+
+	defaultIndex := 1
+	fmt.Fprintln(w.out, "Providers:")
+	for i, def := range defs {
+		if string(def.Type) == current.ActiveProvider {
+			defaultIndex = i + 1
+		}
+		fmt.Fprintf(w.out, "  %d) %-22s %s\n", i+1, def.DisplayName, def.Type)
+	}
+	for {
+		answer, err := w.ask("Provider", strconv.Itoa(defaultIndex))
+		if err != nil {
+			return "", "", err
+		}
+		providerType, baseURL, err := pick(answer)
+		if err == nil {
+			return providerType, baseURL, nil
+		}
+		fmt.Fprintf(w.out, "%v\n", err)
+	}
+}
+
+// chooseKey sets the API key, or for LM Studio the server URL, and returns
+// the provider's models, listed to check them. It asks again while the
+// listing fails.
+func (w *initWizard) chooseKey(settings *initSettings, existing config.Provider, opts initOptions) ([]string, error) {
+	def := config.GetProviderDefinition(settings.provider)
+	baseURL := settings.baseURL
+	if baseURL == "" {
+		baseURL = strings.TrimSpace(existing.BaseURL)
+	}
+	if baseURL == "" {
+		baseURL = def.DefaultURL
+	}
+
+	// The key in use when none is entered: the configured one, else the
+	// provider's usual variable.
+	currentKey, keyHint := strings.TrimSpace(existing.APIKey), ""
+	if currentKey != "" {
+		keyHint = "keep " + config.MaskSecret(currentKey)
+	} else {
+		for _, name := range providerKeyEnv(settings.provider) {
+			if currentKey = strings.TrimSpace(os.Getenv(name)); currentKey != "" {
+				keyHint = "from " + name
+				break
+			}
+		}
+	}
+	if opts.apiKeyEnv != "" {
+		settings.apiKey = strings.TrimSpace(os.Getenv(opts.apiKeyEnv))
+		if settings.apiKey == "" {
+			return nil, fmt.Errorf("%s is not set", opts.apiKeyEnv)
+		}
+	}
+
+	for {
+		var err error
+		switch {
+		case !def.RequiresKey:
+			if w.interactive {
+				if baseURL, err = w.ask("Server URL", baseURL); err != nil {
+					return nil, err
+				}
+			}
+			if baseURL != def.DefaultURL {
+				settings.baseURL = baseURL
+			}
+		case opts.apiKeyEnv != "":
+		case w.interactive:
+			if settings.apiKey, err = w.askSecret(def.DisplayName+" API key", keyHint); err != nil {
+				return nil, err
+			}
+			if settings.apiKey == "" && currentKey == "" {
+				fmt.Fprintln(w.out, "An API key is required")
+				continue
+			}
+		case currentKey == "":
+			envNames := append(providerKeyEnv(settings.provider), "AAGENT_PROVIDERS_"+strings.ToUpper(string(settings.provider))+"_API_KEY")
+			return nil, fmt.Errorf("no API key for %s: pass --api-key-env or set %s", def.DisplayName, strings.Join(envNames, " or "))
+		}
+		if opts.skipVerify {
+			return nil, nil
+		}
+
+		apiKey := settings.apiKey
+		if apiKey == "" {
+			apiKey = currentKey
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		models, err := w.listModels(ctx, settings.provider, apiKey, baseURL)
+		cancel()
+		if err == nil {
+			fmt.Fprintf(w.out, "%s: %d models available\n", def.DisplayName, len(models))
+			return models, nil
+		}
+		err = fmt.Errorf("could not list %s models: %w", def.DisplayName, err)
+		if !w.interactive || opts.apiKeyEnv != "" {
+			return nil, fmt.Errorf("%w (pass --skip-verify to write the key anyway)", err)
+		}
+		fmt.Fprintf(w.out, "%v\n", err)
+	}
+}
+
+// chooseModel returns the model named by the flag or chosen from models,
+// by default the provider's configured or default model.
+func (w *initWizard) chooseModel(current *config.Config, providerType config.ProviderType, models []string, flag string) (string, error) {
+	model := flag
+	if model == "" {
+		model = strings.TrimSpace(current.Providers[string(providerType)].Model)
+	}
+	if model == "" && current.ActiveProvider == string(providerType) {
+		model = strings.TrimSpace(current.DefaultModel)
+	}
+	if model == "" {
+		model = config.GetProviderDefinition(providerType).DefaultModel
+	}
+	if model == "" && len(models) > 0 {
+		model = models[0]
+	}
+
+	if w.interactive && flag == "" {
+		switch {
+		case len(models) > maxListedModels:
+			fmt.Fprintf(w.out, "Enter one of the %d model IDs\n", len(models))
+		case len(models) > 0:
+			for i, id := range models {
+				fmt.Fprintf(w.out, "  %d) %s\n", i+1, id)
+			}
+		}
+		for {
+			answer, err := w.ask("Model", model)
+			if err != nil {
+				return "", err
+			}
+			if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(models) {
+				answer = models[n-1]
+			}
+			if answer != "" {
+				model = answer
+				break
+			}
+		}
+	}
+
+	if model == "" {
+		return "", fmt.Errorf("no model for %s: pass --model", providerType)
+	}
+	if len(models) > 0 && !slices.Contains(models, model) {
+		warning := fmt.Sprintf("model %q is not in the provider's model list", model)
+		if similar := similarModels(models, model, 5); len(similar) > 0 {
+			warning += " (similar: " + strings.Join(similar, ", ") + ")"
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+	return model, nil
+}
+
+// ask prints question with its default answer and returns the answer, or
+// def when it is empty.
+func (w *initWizard) ask(question, def string) (string, error) {
+	if def != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	answer, err := w.readLine()
+	if answer == "" {
+		return def, err
+	}
+	return answer, err
+}
+
+// askSecret asks for a value that is not echoed; hint says what an empty
+// answer keeps.
+func (w *initWizard) askSecret(question, hint string) (string, error) {
+	if hint != "" {
+		question += " [" + hint + "]"
+	}
+	fmt.Fprintf(w.out, "%s: ", question)
+	if w.readSecret == nil {
+		return w.readLine()
+	}
+	secret, err := w.readSecret()
+	return strings.TrimSpace(secret), err
+}
+
+func (w *initWizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	} else if err == io.EOF {
+		err = errors.New("input ended before setup was complete")
+	}
+	return strings.TrimSpace(line), err
+}
+
+// applyInitSettings sets the answers in tree, the contents of the config
+// file, keeping its other keys.
+func applyInitSettings(tree map[string]interface{}, settings initSettings) {
+	name := string(settings.provider)
+	providers, ok := tree["providers"].(map[string]interface{})
+	if !ok {
+		providers = map[string]interface{}{}
+		tree["providers"] = providers
+	}
+	provider, ok := providers[name].(map[string]interface{})
+	if !ok {
+		provider = map[string]interface{}{}
+		providers[name] = provider
+	}
+	provider["name"] = name
+	provider["model"] = settings.model
+	if settings.apiKey != "" {
+		provider["api_key"] = settings.apiKey
+	}
+	if settings.baseURL != "" {
+		provider["base_url"] = settings.baseURL
+	}
+
+	tree["active_provider"] = name
+	tree["default_model"] = settings.model
+	if settings.workDir != "" {
+		tree["work_dir"] = settings.workDir
+	}
+	if settings.dataPath != "" {
+		tree["data_path"] = settings.dataPath
+	}
+}
+
+// listProviderModels lists the models of a provider, failing when the key
+// is rejected.
+func listProviderModels(ctx context.Context, providerType config.ProviderType, apiKey, baseURL string) ([]string, error) {
+	if providerType == config.ProviderAnthropic {
+		return anthropic.NewClientWithBaseURL(apiKey, "", baseURL).FetchModelIDs(ctx)
+	}
+	return lmstudio.NewClient(apiKey, "", baseURL).ListModelIDs(ctx)
+}
+
+// expandHome replaces a leading ~/ with the home directory.
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+)
+
+func TestInitWizard(t *testing.T) {
+	workDir := t.TempDir()
+	dataPath := filepath.Join(t.TempDir(), "data")
+	for _, name := range []string{"KIMI_API_KEY", "ANTHROPIC_API_KEY", "SCRIPT_KEY"} {
+		t.Setenv(name, "")
+	}
+	listModels := func(ctx context.Context, providerType config.ProviderType, apiKey, baseURL string) ([]string, error) {
+		if apiKey != "sk-ant-good-key-1234" {
+			return nil, errors.New("API error (401): invalid x-api-key")
+		}
+		return []string{"claude-opus-4-6", "claude-sonnet-4-5"}, nil
+	}
+	wizard := func(input string, interactive bool) *initWizard {
+		return &initWizard{in: bufio.NewReader(strings.NewReader(input)), out: io.Discard, interactive: interactive, listModels: listModels}
+	}
+
+	current := config.DefaultConfig()
+	current.WorkDir = workDir
+	// Anthropic by name, a rejected key and then a good one, the second
+	// model, and the current directories.
+	settings, err := wizard("anthropic\nbad-key\nsk-ant-good-key-1234\n2\n\n"+dataPath+"\n", true).run(current, initOptions{})
+	if err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+	want := initSettings{provider: config.ProviderAnthropic, apiKey: "sk-ant-good-key-1234", model: "claude-sonnet-4-5", workDir: workDir, dataPath: dataPath}
+	if settings != want {
+		t.Fatalf("run() = %+v, want %+v", settings, want)
+	}
+
+	tree := map[string]interface{}{"max_steps": 20.0, "providers": map[string]interface{}{"kimi": map[string]interface{}{"api_key": "kimi-key"}}}
+	applyInitSettings(tree, settings)
+	wantTree := map[string]interface{}{
+		"max_steps":       20.0,
+		"active_provider": "anthropic",
+		"default_model":   "claude-sonnet-4-5",
+		"work_dir":        workDir,
+		"data_path":       dataPath,
+		"providers": map[string]interface{}{
+			"kimi":      map[string]interface{}{"api_key": "kimi-key"},
+			"anthropic": map[string]interface{}{"name": "anthropic", "api_key": "sk-ant-good-key-1234", "model": "claude-sonnet-4-5"},
+		},
+	}
+	if !reflect.DeepEqual(tree, wantTree) {
+		t.Fatalf("applyInitSettings() tree = %v, want %v", tree, wantTree)
+	}
+
+	// Re-running offers the written values: empty answers keep them.
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := config.WriteConfigFile(path, tree); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("config file mode = %v, %v, want 0600", info.Mode().Perm(), err)
+	}
+	current.ActiveProvider = "anthropic"
+	current.Providers = map[string]config.Provider{"anthropic": {Name: "anthropic", APIKey: "sk-ant-good-key-1234", Model: "claude-sonnet-4-5"}}
+	current.DataPath = dataPath
+	settings, err = wizard("\n\n\n\n\n", true).run(current, initOptions{})
+	if err != nil {
+		t.Fatalf("re-run error = %v", err)
+	}
+	want.apiKey = ""
+	if settings != want {
+		t.Fatalf("re-run = %+v, want %+v", settings, want)
+	}
+
+	// Without prompts the key comes from --api-key-env and is checked.
+	t.Setenv("SCRIPT_KEY", "bad-key")
+	if _, err := wizard("", false).run(config.DefaultConfig(), initOptions{provider: "claude", apiKeyEnv: "SCRIPT_KEY"}); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Fatalf("non-interactive run with a bad key error = %v", err)
+	}
+	if _, err := wizard("", false).run(config.DefaultConfig(), initOptions{provider: "kimi"}); err == nil || !strings.Contains(err.Error(), "KIMI_API_KEY") {
+		t.Fatalf("non-interactive run without a key error = %v", err)
+	}
+	t.Setenv("SCRIPT_KEY", "sk-ant-good-key-1234")
+	settings, err = wizard("", false).run(config.DefaultConfig(), initOptions{provider: "anthropic", apiKeyEnv: "SCRIPT_KEY", model: "claude-opus-4-6"})
+	if err != nil {
+		t.Fatalf("non-interactive run error = %v", err)
+	}
+	want = initSettings{provider: config.ProviderAnthropic, apiKey: "sk-ant-good-key-1234", model: "claude-opus-4-6"}
+	if settings != want {
+		t.Fatalf("non-interactive run = %+v, want %+v", settings, want)
+	}
+}
@@ -0,0 +1,657 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/jobs"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/schedule"
+	"github.com/A2gent/brute/internal/scheduler"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/speechcache"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+	"github.com/A2gent/brute/internal/tools/integrationtools"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// newJobsCmd builds `aagent jobs`, which manages recurring jobs directly in
+// the store, without a running server.
+func newJobsCmd() *cobra.Command {
+	jobsCmd := &cobra.Command{
+		Use:   "jobs",
+		Short: "Manage recurring jobs",
+		Long: `Manage recurring jobs directly in the local store. Jobs are referenced by
+ID, unique ID prefix or exact name.`,
+	}
+	jobsCmd.PersistentFlags().Bool("json", false, "Print JSON instead of text")
+
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List jobs with their schedule and last/next run",
+		Args:  cobra.NoArgs,
+		RunE:  listJobs,
+	})
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a job",
+		Long: `Create a job. The schedule is parsed without an LLM: intervals ("every 15
+minutes"), calendar phrases ("every monday 9am", "weekdays at 8:30"), one-time
+runs ("tomorrow at 9am", "in 2 hours") and 5-field cron expressions.`,
+		Args: cobra.NoArgs,
+		RunE: createJob,
+	}
+	createCmd.Flags().String("name", "", "Job name (required)")
+	createCmd.Flags().String("schedule", "", "When the job runs, e.g. \"every monday 9am\" (required)")
+	createCmd.Flags().String("prompt", "", "Task instructions for the agent")
+	createCmd.Flags().String("prompt-file", "", "Read the task instructions from this file at every run instead of --prompt")
+	createCmd.Flags().String("timezone", "", "IANA timezone the schedule is read in (default: local)")
+	createCmd.Flags().Bool("disabled", false, "Create the job disabled")
+	createCmd.Flags().Bool("dry-run", false, "Only show how the schedule is read and its next runs; create nothing")
+	jobsCmd.AddCommand(createCmd)
+
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "show <job>",
+		Short: "Show a job and its recent executions",
+		Args:  cobra.ExactArgs(1),
+		RunE:  showJob,
+	})
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "delete <job>",
+		Short: "Delete a job and its executions",
+		Args:  cobra.ExactArgs(1),
+		RunE:  deleteJob,
+	})
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "run <job>",
+		Short: "Run a job now and follow its progress",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runJobNow,
+	})
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "enable <job>",
+		Short: "Enable a job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setJobEnabled(cmd, args[0], true)
+		},
+	})
+	jobsCmd.AddCommand(&cobra.Command{
+		Use:   "disable <job>",
+		Short: "Disable a job",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return setJobEnabled(cmd, args[0], false)
+		},
+	})
+	for _, sub := range jobsCmd.Commands() {
+		sub.SilenceUsage = true
+		sub.SilenceErrors = true
+	}
+	return jobsCmd
+}
+
+// jobJSON is the --json form of a job.
+type jobJSON struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Schedule        string          `json:"schedule"`
+	ScheduleCron    string          `json:"schedule_cron,omitempty"`
+	ScheduleSummary string          `json:"schedule_summary"`
+	RunAt           *time.Time      `json:"run_at,omitempty"`
+	Timezone        string          `json:"timezone"`
+	TaskPrompt      string          `json:"task_prompt"`
+	TaskPromptFile  string          `json:"task_prompt_file,omitempty"`
+	Enabled         bool            `json:"enabled"`
+	LastRunAt       *time.Time      `json:"last_run_at,omitempty"`
+	NextRunAt       *time.Time      `json:"next_run_at,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	Executions      []executionJSON `json:"executions,omitempty"`
+}
+
+// executionJSON is the --json form of a job execution.
+type executionJSON struct {
+	ID         string     `json:"id"`
+	SessionID  string     `json:"session_id,omitempty"`
+	Status     string     `json:"status"`
+	Output     string     `json:"output,omitempty"`
+	OutputPath string     `json:"output_path,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func toJobJSON(job *storage.RecurringJob) jobJSON {
+	return jobJSON{
+		ID:              job.ID,
+		Name:            job.Name,
+		Schedule:        job.ScheduleHuman,
+		ScheduleCron:    job.ScheduleCron,
+		ScheduleSummary: jobScheduleSummary(job),
+		RunAt:           job.RunAt,
+		Timezone:        job.Timezone,
+		TaskPrompt:      job.TaskPrompt,
+		TaskPromptFile:  job.TaskPromptFile,
+		Enabled:         job.Enabled,
+		LastRunAt:       job.LastRunAt,
+		NextRunAt:       job.NextRunAt,
+		CreatedAt:       job.CreatedAt,
+	}
+}
+
+func toExecutionJSON(exec *storage.JobExecution) executionJSON {
+	return executionJSON{
+		ID:         exec.ID,
+		SessionID:  exec.SessionID,
+		Status:     exec.Status,
+		Output:     exec.Output,
+		OutputPath: exec.OutputPath,
+		Error:      exec.Error,
+		StartedAt:  exec.StartedAt,
+		FinishedAt: exec.FinishedAt,
+	}
+}
+
+func jobScheduleSummary(job *storage.RecurringJob) string {
+	if jobs.IsOneShot(job) {
+		return schedule.DescribeOnce(*job.RunAt)
+	}
+	return schedule.Describe(job.ScheduleCron)
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func wantsJSON(cmd *cobra.Command) bool {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	return asJSON
+}
+
+// openJobStore loads the configuration and opens the store jobs live in.
+func openJobStore() (*config.Config, storage.Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return cfg, store, nil
+}
+
+// findJob resolves a job by ID, unique ID prefix or exact name.
+func findJob(store storage.Store, ref string) (*storage.RecurringJob, error) {
+	ref = strings.TrimSpace(ref)
+	if job, err := store.GetJob(ref); err == nil {
+		return job, nil
+	}
+	all, err := store.ListJobs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list jobs: %w", err)
+	}
+	var matches []*storage.RecurringJob
+	for _, job := range all {
+		if job.Name == ref || strings.HasPrefix(job.ID, ref) {
+			matches = append(matches, job)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("job %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return nil, fmt.Errorf("%q matches %d jobs; use the full ID", ref, len(matches))
+	}
+}
+
+func formatJobTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+func listJobs(cmd *cobra.Command, args []string) error {
+	_, store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	all, err := store.ListJobs()
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %w", err)
+	}
+	if wantsJSON(cmd) {
+		out := make([]jobJSON, len(all))
+		for i, job := range all {
+			out[i] = toJobJSON(job)
+		}
+		return printJSON(out)
+	}
+
+	if len(all) == 0 {
+		fmt.Println("No jobs found")
+		return nil
+	}
+	fmt.Printf("%-30s  %-30s  %-7s  %-16s  %-16s  %-8s\n", "Name", "Schedule", "Enabled", "Last run", "Next run", "ID")
+	fmt.Println(strings.Repeat("-", 116))
+	for _, job := range all {
+		name := job.Name
+		if runes := []rune(name); len(runes) > 30 {
+			name = string(runes[:27]) + "..."
+		}
+		summary := jobScheduleSummary(job)
+		if runes := []rune(summary); len(runes) > 30 {
+			summary = string(runes[:27]) + "..."
+		}
+		enabled := "no"
+		if job.Enabled {
+			enabled = "yes"
+		}
+		fmt.Printf("%-30s  %-30s  %-7s  %-16s  %-16s  %-8s\n", name, summary, enabled, formatJobTime(job.LastRunAt), formatJobTime(job.NextRunAt), job.ID[:8])
+	}
+	return nil
+}
+
+func createJob(cmd *cobra.Command, args []string) error {
+	name, _ := cmd.Flags().GetString("name")
+	scheduleText, _ := cmd.Flags().GetString("schedule")
+	prompt, _ := cmd.Flags().GetString("prompt")
+	promptFile, _ := cmd.Flags().GetString("prompt-file")
+	timezone, _ := cmd.Flags().GetString("timezone")
+	disabled, _ := cmd.Flags().GetBool("disabled")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	name = strings.TrimSpace(name)
+	scheduleText = strings.TrimSpace(scheduleText)
+	prompt = strings.TrimSpace(prompt)
+	promptFile = strings.TrimSpace(promptFile)
+	if scheduleText == "" {
+		return fmt.Errorf("--schedule is required")
+	}
+	if !dryRun {
+		if name == "" {
+			return fmt.Errorf("--name is required")
+		}
+		if (prompt == "") == (promptFile == "") {
+			return fmt.Errorf("exactly one of --prompt and --prompt-file is required")
+		}
+	}
+
+	if strings.TrimSpace(timezone) == "" {
+		timezone = schedule.DefaultTimezone()
+	}
+	loc, err := schedule.LoadLocation(timezone)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	parsed, err := schedule.ParseAt(scheduleText, now.In(loc))
+	if err != nil {
+		return fmt.Errorf("failed to parse schedule: %w", err)
+	}
+	if dryRun {
+		return previewSchedule(cmd, parsed, loc.String(), now)
+	}
+
+	_, store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	job := &storage.RecurringJob{
+		ID:               uuid.New().String(),
+		Name:             name,
+		ScheduleHuman:    scheduleText,
+		TaskPrompt:       prompt,
+		TaskPromptSource: jobs.TaskPromptSourceText,
+		Timezone:         loc.String(),
+		Enabled:          !disabled,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if promptFile != "" {
+		absFile, err := filepath.Abs(promptFile)
+		if err != nil {
+			return fmt.Errorf("invalid --prompt-file: %w", err)
+		}
+		job.TaskPromptSource = jobs.TaskPromptSourceFile
+		job.TaskPromptFile = absFile
+		job.TaskPrompt = jobs.BuildTaskPromptForFile(absFile)
+	}
+	if err := jobs.ApplySchedule(job, parsed, now); err != nil {
+		return fmt.Errorf("failed to calculate next run: %w", err)
+	}
+	if err := store.SaveJob(job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	if wantsJSON(cmd) {
+		return printJSON(toJobJSON(job))
+	}
+	fmt.Printf("Created job %s (%s)\n", job.Name, job.ID)
+	fmt.Printf("Schedule: %s\n", parsed.Description)
+	fmt.Printf("Next run: %s\n", formatJobTime(job.NextRunAt))
+	return nil
+}
+
+// schedulePreviewJSON is the --json form of `jobs create --dry-run`; it
+// matches the body of POST /jobs/preview.
+type schedulePreviewJSON struct {
+	ScheduleCron    string      `json:"schedule_cron"`
+	ScheduleSummary string      `json:"schedule_summary"`
+	RunAt           *time.Time  `json:"run_at,omitempty"`
+	Timezone        string      `json:"timezone"`
+	NextRuns        []time.Time `json:"next_runs"`
+}
+
+// previewSchedule prints what a parsed schedule resolves to and its next
+// runs, the same preview POST /jobs/preview returns.
+func previewSchedule(cmd *cobra.Command, parsed schedule.Schedule, timezone string, now time.Time) error {
+	runs, err := jobs.PreviewRuns(parsed, timezone, now, 5)
+	if err != nil {
+		return fmt.Errorf("failed to calculate next runs: %w", err)
+	}
+	if wantsJSON(cmd) {
+		out := schedulePreviewJSON{
+			ScheduleCron:    parsed.Cron,
+			ScheduleSummary: parsed.Description,
+			Timezone:        timezone,
+			NextRuns:        append([]time.Time{}, runs...),
+		}
+		if !parsed.RunAt.IsZero() {
+			out.RunAt = &parsed.RunAt
+		}
+		return printJSON(out)
+	}
+
+	fmt.Printf("Schedule: %s\n", parsed.Description)
+	if parsed.Cron != "" {
+		fmt.Printf("Cron:     %s\n", parsed.Cron)
+	}
+	fmt.Printf("Timezone: %s\n", timezone)
+	fmt.Println("Next runs:")
+	for _, run := range runs {
+		fmt.Printf("  %s\n", run.Format("Mon 2006-01-02 15:04 MST"))
+	}
+	return nil
+}
+
+func showJob(cmd *cobra.Command, args []string) error {
+	_, store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	job, err := findJob(store, args[0])
+	if err != nil {
+		return err
+	}
+	executions, err := store.ListJobExecutions(job.ID, 10)
+	if err != nil {
+		return fmt.Errorf("failed to list executions: %w", err)
+	}
+
+	if wantsJSON(cmd) {
+		out := toJobJSON(job)
+		for _, exec := range executions {
+			out.Executions = append(out.Executions, toExecutionJSON(exec))
+		}
+		return printJSON(out)
+	}
+
+	fmt.Printf("Name:      %s\n", job.Name)
+	fmt.Printf("ID:        %s\n", job.ID)
+	fmt.Printf("Schedule:  %s (%s)\n", job.ScheduleHuman, jobScheduleSummary(job))
+	fmt.Printf("Timezone:  %s\n", job.Timezone)
+	fmt.Printf("Enabled:   %t\n", job.Enabled)
+	fmt.Printf("Last run:  %s\n", formatJobTime(job.LastRunAt))
+	fmt.Printf("Next run:  %s\n", formatJobTime(job.NextRunAt))
+	if job.TaskPromptFile != "" {
+		fmt.Printf("Prompt:    from %s\n", job.TaskPromptFile)
+	} else {
+		fmt.Printf("Prompt:    %s\n", job.TaskPrompt)
+	}
+	if len(executions) == 0 {
+		fmt.Println("\nNo executions yet")
+		return nil
+	}
+	fmt.Printf("\n%-16s  %-9s  %-8s  %s\n", "Started", "Status", "Session", "Error")
+	for _, exec := range executions {
+		sessionID := exec.SessionID
+		if len(sessionID) > 8 {
+			sessionID = sessionID[:8]
+		}
+		fmt.Printf("%-16s  %-9s  %-8s  %s\n", exec.StartedAt.Local().Format("2006-01-02 15:04"), exec.Status, sessionID, exec.Error)
+	}
+	return nil
+}
+
+func deleteJob(cmd *cobra.Command, args []string) error {
+	_, store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	job, err := findJob(store, args[0])
+	if err != nil {
+		return err
+	}
+	if settings, err := store.GetSettings(); err == nil && strings.TrimSpace(settings[jobs.ThinkingJobIDSettingKey]) == job.ID {
+		return fmt.Errorf("job %s is managed by Thinking settings and cannot be deleted directly", job.Name)
+	}
+	if err := store.DeleteJob(job.ID); err != nil {
+		return fmt.Errorf("failed to delete job: %w", err)
+	}
+
+	if wantsJSON(cmd) {
+		return printJSON(map[string]string{"deleted": job.ID})
+	}
+	fmt.Printf("Deleted job %s (%s)\n", job.Name, job.ID)
+	return nil
+}
+
+func setJobEnabled(cmd *cobra.Command, ref string, enabled bool) error {
+	_, store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	job, err := findJob(store, ref)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	job.Enabled = enabled
+	if enabled {
+		// A job that was off may have missed its next run; start from now.
+		next, err := jobs.NextRun(job, now)
+		if err != nil {
+			return fmt.Errorf("failed to calculate next run: %w", err)
+		}
+		if next == nil {
+			return fmt.Errorf("job %s already ran once and has no future run", job.Name)
+		}
+		job.NextRunAt = next
+	}
+	job.UpdatedAt = now
+	if err := store.SaveJob(job); err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+
+	if wantsJSON(cmd) {
+		return printJSON(toJobJSON(job))
+	}
+	state := "Disabled"
+	if enabled {
+		state = "Enabled"
+	}
+	fmt.Printf("%s job %s (next run: %s)\n", state, job.Name, formatJobTime(job.NextRunAt))
+	return nil
+}
+
+// runJobNow executes a job in this process and follows its session until
+// the execution finishes.
+func runJobNow(cmd *cobra.Command, args []string) error {
+	homeDir, _ := os.UserHomeDir()
+	godotenv.Load(".env")
+	godotenv.Load(filepath.Join(homeDir, ".env"))
+
+	cfg, store, err := openJobStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	job, err := findJob(store, args[0])
+	if err != nil {
+		return err
+	}
+
+	if err := logging.InitWithOptions(cfg.DataPath, loggingOptions(cfg)); err != nil {
+		return fmt.Errorf("failed to initialize logging: %w", err)
+	}
+	defer logging.Close()
+	logConfigWarnings(cfg)
+	if settings, err := store.GetSettings(); err == nil {
+		applySettingsToEnv(settings)
+	}
+	applyProviderEnvOverrides(cfg)
+	applyToolsConfigToEnv(cfg)
+
+	llmClient, err := initLLMClient(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize LLM client: %w", err)
+	}
+	toolManager := tools.NewManager(cfg.WorkDir)
+	toolManager.SetTimeoutPolicy(toolTimeoutPolicy(cfg))
+	toolManager.SetApprovalPolicy(cfg.Tools.Approvals())
+	toolManager.SetRedactionPolicy(toolRedactionPolicy(cfg, store))
+	clipStore := speechcache.New(0)
+	defer clipStore.Stop()
+	integrationtools.Register(toolManager, store, clipStore)
+	sessionManager := session.NewManager(store)
+	toolManager.RegisterToolOutputTool(sessionManager)
+	toolManager.RegisterMemoryTool(store)
+	toolManager.EnableUndo(tools.NewBackupStore(cfg.DataPath))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	jobScheduler := scheduler.NewScheduler(store, sessionManager, llmClient, toolManager, cfg)
+	startedAt := time.Now()
+	type runResult struct {
+		exec *storage.JobExecution
+		err  error
+	}
+	done := make(chan runResult, 1)
+	go func() {
+		exec, err := jobScheduler.Runner().RunNow(ctx, job)
+		done <- runResult{exec, err}
+	}()
+
+	asJSON := wantsJSON(cmd)
+	progress := os.Stdout
+	if asJSON {
+		progress = os.Stderr
+	}
+	fmt.Fprintf(progress, "Running job %s...\n", job.Name)
+
+	tail := &sessionTail{store: store, sessions: sessionManager}
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	var result runResult
+	for waiting := true; waiting; {
+		select {
+		case result = <-done:
+			waiting = false
+		case <-ticker.C:
+		}
+		tail.follow(job.ID, startedAt, progress)
+	}
+	if result.err != nil {
+		return result.err
+	}
+
+	exec := result.exec
+	if asJSON {
+		if err := printJSON(toExecutionJSON(exec)); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Execution %s finished: %s\n", exec.ID, exec.Status)
+		if exec.Output != "" {
+			fmt.Println(exec.Output)
+		}
+		if exec.OutputPath != "" {
+			fmt.Printf("Full output: %s\n", exec.OutputPath)
+		}
+		if exec.Error != "" {
+			fmt.Printf("Error: %s\n", exec.Error)
+		}
+	}
+	if exec.Status != "success" {
+		return &exitCodeError{code: exitRunFailed}
+	}
+	return nil
+}
+
+// sessionTail prints the messages a running job adds to its session.
+type sessionTail struct {
+	store     storage.Store
+	sessions  *session.Manager
+	sessionID string
+	printed   int
+}
+
+func (t *sessionTail) follow(jobID string, startedAt time.Time, out *os.File) {
+	if t.sessionID == "" {
+		executions, err := t.store.ListJobExecutions(jobID, 1)
+		if err != nil || len(executions) == 0 || executions[0].StartedAt.Before(startedAt) || executions[0].SessionID == "" {
+			return
+		}
+		t.sessionID = executions[0].SessionID
+		fmt.Fprintf(out, "Execution %s started (session %s)\n", executions[0].ID, t.sessionID)
+	}
+	sess, err := t.sessions.Get(t.sessionID)
+	if err != nil {
+		return
+	}
+	for ; t.printed < len(sess.Messages); t.printed++ {
+		msg := sess.Messages[t.printed]
+		switch {
+		case msg.Role == "assistant" && len(msg.ToolCalls) > 0:
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(out, "  -> %s\n", call.Name)
+			}
+		case msg.Role == "assistant" && strings.TrimSpace(msg.Content) != "":
+			line := strings.TrimSpace(strings.SplitN(strings.TrimSpace(msg.Content), "\n", 2)[0])
+			if runes := []rune(line); len(runes) > 100 {
+				line = string(runes[:97]) + "..."
+			}
+			fmt.Fprintf(out, "  %s\n", line)
+		}
+	}
+}
@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/agents"
 	"github.com/A2gent/brute/internal/config"
 	httpserver "github.com/A2gent/brute/internal/http"
 	"github.com/A2gent/brute/internal/llm"
@@ -18,6 +22,7 @@ import (
 	"github.com/A2gent/brute/internal/llm/autorouter"
 	"github.com/A2gent/brute/internal/llm/fallback"
 	"github.com/A2gent/brute/internal/llm/lmstudio"
+	"github.com/A2gent/brute/internal/llm/reqlog"
 	"github.com/A2gent/brute/internal/logging"
 	"github.com/A2gent/brute/internal/scheduler"
 	"github.com/A2gent/brute/internal/session"
@@ -25,6 +30,7 @@ import (
 	"github.com/A2gent/brute/internal/storage"
 	"github.com/A2gent/brute/internal/tools"
 	"github.com/A2gent/brute/internal/tools/integrationtools"
+	"github.com/A2gent/brute/internal/tracing"
 	"github.com/A2gent/brute/internal/tui"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/joho/godotenv"
@@ -33,10 +39,20 @@ import (
 
 var (
 	modelFlag    string
+	providerFlag string
 	agentFlag    string
 	continueFlag string
+	forkFlag     bool
+	fileFlag     string
 	verboseFlag  bool
 	portFlag     int
+	noTUIFlag    bool
+	maxStepsFlag int
+	outputFlag   string
+
+	noSchedulerFlag bool
+	workdirFlag     string
+	gracePeriodFlag time.Duration
 )
 
 func main() {
@@ -49,20 +65,69 @@ Starts both the HTTP API server and the TUI interface simultaneously.`,
 		RunE: runAgentWithServer,
 	}
 
-	rootCmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Override default model")
-	rootCmd.Flags().StringVarP(&agentFlag, "agent", "a", "build", "Select agent type (build, plan)")
-	rootCmd.Flags().StringVarP(&continueFlag, "continue", "c", "", "Resume previous session by ID")
+	rootCmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Override default model; provider/model also selects the provider")
+	rootCmd.Flags().StringVar(&providerFlag, "provider", "", "LLM provider for this run: kimi, anthropic, gemini, openai, openrouter, lmstudio or ollama (default: active provider from config)")
+	rootCmd.Flags().StringVar(&workdirFlag, "workdir", "", "Working directory for agent tools (default: work_dir from config)")
+	rootCmd.Flags().StringVarP(&agentFlag, "agent", "a", "build", "Select agent type (see 'aagent agents list')")
+	rootCmd.Flags().StringVarP(&continueFlag, "continue", "c", "", "Resume a session by ID, or the most recent one when no ID is given")
+	rootCmd.Flags().Lookup("continue").NoOptDefVal = continueLast
+	rootCmd.Flags().BoolVar(&forkFlag, "fork", false, "With --continue, resume a fork of the session instead of the original")
+	rootCmd.Flags().StringVarP(&fileFlag, "file", "f", "", "Read the task description from a file")
 	rootCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Verbose output")
 	rootCmd.Flags().IntVarP(&portFlag, "port", "p", 0, "HTTP API server port (0 = random available port)")
 
-	// Server mode subcommand (HTTP API only, no TUI)
-	serverCmd := &cobra.Command{
-		Use:   "server",
-		Short: "Run HTTP API server only",
-		RunE:  runServer,
+	// Serve subcommand (HTTP API and scheduler, no TUI)
+	serveCmd := &cobra.Command{
+		Use:     "serve",
+		Aliases: []string{"server"},
+		Short:   "Run the HTTP API server and job scheduler without the TUI",
+		Long: `Run the HTTP API server and the recurring job scheduler in the foreground,
+logging to stdout as well as the log file. On SIGINT or SIGTERM new requests are
+refused and in-flight agent runs get --grace-period to finish before they are
+cancelled.`,
+		RunE: runServer,
+	}
+	serveCmd.Flags().IntVarP(&portFlag, "port", "p", 0, "HTTP API server port (0 = random available port)")
+	serveCmd.Flags().BoolVar(&noSchedulerFlag, "no-scheduler", false, "Do not run recurring jobs")
+	serveCmd.Flags().StringVar(&workdirFlag, "workdir", "", "Working directory for agent tools (default: work_dir from config)")
+	serveCmd.Flags().DurationVar(&gracePeriodFlag, "grace-period", 30*time.Second, "How long to wait for in-flight runs on shutdown")
+	rootCmd.AddCommand(serveCmd)
+
+	// Run subcommand (single task, optionally without the TUI for CI/scripts)
+	runCmd := &cobra.Command{
+		Use:   "run [task]",
+		Short: "Run a single task without the HTTP server",
+		Long: `Run a single task. With --no-tui the agent runs headless: progress is written
+to stderr, the final answer (or a JSON summary with --output json) to stdout, and
+the exit code is 0 when the run completed, 1 when it failed and 2 when it stopped
+at the step limit. Without a task argument the task is read from stdin.`,
+		Args:          cobra.ArbitraryArgs,
+		RunE:          runAgent,
+		SilenceUsage:  true,
+		SilenceErrors: true,
 	}
-	serverCmd.Flags().IntVarP(&portFlag, "port", "p", 0, "HTTP API server port (0 = random available port)")
-	rootCmd.AddCommand(serverCmd)
+	runCmd.Flags().StringVarP(&modelFlag, "model", "m", "", "Override default model; provider/model also selects the provider")
+	runCmd.Flags().StringVar(&providerFlag, "provider", "", "LLM provider for this run: kimi, anthropic, gemini, openai, openrouter, lmstudio or ollama (default: active provider from config)")
+	runCmd.Flags().StringVar(&workdirFlag, "workdir", "", "Working directory for agent tools (default: work_dir from config)")
+	runCmd.Flags().StringVarP(&agentFlag, "agent", "a", "build", "Select agent type (see 'aagent agents list')")
+	runCmd.Flags().StringVarP(&continueFlag, "continue", "c", "", "Resume a session by ID, or the most recent one when no ID is given")
+	runCmd.Flags().Lookup("continue").NoOptDefVal = continueLast
+	runCmd.Flags().BoolVar(&forkFlag, "fork", false, "With --continue, resume a fork of the session instead of the original")
+	runCmd.Flags().StringVarP(&fileFlag, "file", "f", "", "Read the task description from a file")
+	runCmd.Flags().BoolVar(&noTUIFlag, "no-tui", false, "Run headless and exit when the task ends")
+	runCmd.Flags().IntVar(&maxStepsFlag, "max-steps", 0, "Maximum agent steps (default: max_steps from config)")
+	runCmd.Flags().StringVarP(&outputFlag, "output", "o", "text", "Headless output format: text or json")
+	rootCmd.AddCommand(runCmd)
+
+	// Continue subcommand (pick a recent session to resume)
+	continueCmd := &cobra.Command{
+		Use:   "continue",
+		Short: "Pick a recent session and resume it in the TUI",
+		Args:  cobra.NoArgs,
+		RunE:  pickAndContinueSession,
+	}
+	continueCmd.Flags().IntP("limit", "n", 10, "Number of recent sessions to choose from")
+	rootCmd.AddCommand(continueCmd)
 
 	// Session management subcommand
 	sessionCmd := &cobra.Command{
@@ -72,13 +137,89 @@ Starts both the HTTP API server and the TUI interface simultaneously.`,
 
 	sessionListCmd := &cobra.Command{
 		Use:   "list",
-		Short: "List all sessions",
+		Short: "List sessions, newest first",
 		RunE:  listSessions,
 	}
+	sessionListCmd.Flags().IntP("limit", "n", storage.DefaultSessionPageSize, "Maximum number of sessions to show")
+	sessionListCmd.Flags().Int("offset", 0, "Number of sessions to skip")
+	sessionListCmd.Flags().String("status", "", "Only show sessions with this status")
+	sessionListCmd.Flags().String("agent", "", "Only show sessions for this agent ID")
+	sessionListCmd.Flags().String("created-after", "", "Only show sessions created after this time (RFC3339 or YYYY-MM-DD)")
+
+	sessionPruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete old job sessions and their executions",
+		Long: `Delete sessions last updated before --older-than, together with their
+messages and job executions. Only job sessions are pruned unless --all is set.
+Running, queued and input_required sessions are never deleted.`,
+		RunE: pruneSessions,
+	}
+	sessionPruneCmd.Flags().String("older-than", "", "Minimum age of sessions to delete, e.g. 30d, 2w, 72h (default: retention.max_session_age)")
+	sessionPruneCmd.Flags().Int("keep-last-per-job", -1, "Newest sessions to keep per job (default: retention.keep_last_per_job)")
+	sessionPruneCmd.Flags().Bool("all", false, "Also prune interactive (non-job) sessions")
+	sessionPruneCmd.Flags().Bool("dry-run", false, "Print what would be deleted without deleting")
+
+	sessionShowCmd := &cobra.Command{
+		Use:   "show <id>",
+		Short: "Print a session transcript",
+		Long: `Print a session transcript with role-prefixed turns and one line per tool
+call. The ID may be any prefix that matches a single session.`,
+		Args: cobra.ExactArgs(1),
+		RunE: showSession,
+	}
+
+	sessionDeleteCmd := &cobra.Command{
+		Use:   "delete [id...]",
+		Short: "Delete sessions and their messages",
+		RunE:  deleteSessions,
+	}
+	sessionDeleteCmd.Flags().Bool("all-completed", false, "Delete every completed session")
+	sessionDeleteCmd.Flags().BoolP("yes", "y", false, "Do not ask for confirmation")
+
+	sessionExportCmd := &cobra.Command{
+		Use:   "export <id>",
+		Short: "Export a session transcript as Markdown",
+		Args:  cobra.ExactArgs(1),
+		RunE:  exportSession,
+	}
+	sessionExportCmd.Flags().StringP("output", "o", "", "File to write (default: stdout)")
+
+	sessionDebugCmd := &cobra.Command{
+		Use:   "debug <id>",
+		Short: "Print the last LLM request logged for a session",
+		Long: `Print the most recent request sent to the LLM for a session, with the
+response or error it got, as JSON. Requests are only logged while
+llm.log_requests is enabled (or AAGENT_LLM_LOG_REQUESTS=true); secrets are
+scrubbed before they are written.`,
+		Args: cobra.ExactArgs(1),
+		RunE: debugSession,
+	}
+	sessionDebugCmd.Flags().IntP("count", "n", 1, "Number of most recent requests to print")
 
 	sessionCmd.AddCommand(sessionListCmd)
+	sessionCmd.AddCommand(sessionShowCmd)
+	sessionCmd.AddCommand(sessionDeleteCmd)
+	sessionCmd.AddCommand(sessionExportCmd)
+	sessionCmd.AddCommand(sessionPruneCmd)
+	sessionCmd.AddCommand(sessionDebugCmd)
 	rootCmd.AddCommand(sessionCmd)
 
+	// Agent types subcommand
+	agentsCmd := &cobra.Command{
+		Use:   "agents",
+		Short: "Manage agent types",
+	}
+	agentsCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List built-in and configured agent types",
+		RunE:  listAgents,
+	})
+	rootCmd.AddCommand(agentsCmd)
+	rootCmd.AddCommand(newJobsCmd())
+	rootCmd.AddCommand(newToolsCmd())
+	rootCmd.AddCommand(newConfigCmd())
+	rootCmd.AddCommand(newInitCmd())
+
 	// Logs subcommand
 	logsCmd := &cobra.Command{
 		Use:   "logs",
@@ -90,12 +231,58 @@ Starts both the HTTP API server and the TUI interface simultaneously.`,
 	rootCmd.AddCommand(logsCmd)
 
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			if exitErr.message != "" {
+				fmt.Fprintln(os.Stderr, exitErr.message)
+			}
+			os.Exit(exitErr.code)
+		}
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
 
+// resumeSession loads the session named by --continue (the most recently
+// updated interactive session for "last"), forking it first when --fork is
+// set so the original conversation is left untouched.
+func resumeSession(sessionManager *session.Manager) (*session.Session, error) {
+	if continueFlag == continueLast {
+		recent, err := recentInteractiveSessions(sessionManager, 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the most recent session: %w", err)
+		}
+		if len(recent) == 0 {
+			return nil, fmt.Errorf("no session to continue")
+		}
+		continueFlag = recent[0].ID
+	}
+
+	if forkFlag {
+		sess, err := sessionManager.Fork(continueFlag, -1)
+		if err != nil {
+			logging.Error("Failed to fork session %s: %v", continueFlag, err)
+			return nil, fmt.Errorf("failed to fork session: %w", err)
+		}
+		logging.LogSession("forked", sess.ID, fmt.Sprintf("parent=%s messages=%d", continueFlag, len(sess.Messages)))
+		return sess, nil
+	}
+
+	sess, err := sessionManager.Get(continueFlag)
+	if err != nil {
+		logging.Error("Failed to resume session %s: %v", continueFlag, err)
+		return nil, fmt.Errorf("failed to resume session: %w", err)
+	}
+	logging.LogSession("resumed", sess.ID, fmt.Sprintf("agent=%s messages=%d", sess.AgentID, len(sess.Messages)))
+	return sess, nil
+}
+
 func runAgentWithServer(cmd *cobra.Command, args []string) error {
+	args = takeContinueArg(args)
+	if forkFlag && continueFlag == "" {
+		return fmt.Errorf("--fork requires --continue")
+	}
+
 	// Load .env files from common locations (ignore errors if not found)
 	homeDir, _ := os.UserHomeDir()
 	godotenv.Load(".env")                                  // Current directory
@@ -107,22 +294,49 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := applyProviderFlags(cfg); err != nil {
+		return err
+	}
+	if err := applyWorkDirFlag(cfg); err != nil {
+		return err
+	}
+	applyServeConfig(cmd, cfg)
 
 	// Initialize logging
-	if err := logging.Init(cfg.DataPath); err != nil {
+	if err := logging.InitWithOptions(cfg.DataPath, loggingOptions(cfg)); err != nil {
 		return fmt.Errorf("failed to initialize logging: %w", err)
 	}
 	defer logging.Close()
+	logConfigWarnings(cfg)
 
 	logging.Info("Starting aagent with HTTP server and TUI")
 
-	// Override model if specified
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		logging.Warn("Tracing disabled: %v", err)
+	} else if tracing.Enabled() {
+		logging.Info("OpenTelemetry tracing enabled")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}()
+
+	agentDef, err := resolveAgentFlag(cfg)
+	if err != nil {
+		return err
+	}
+
+	// Override model if specified; --model wins over the agent's model
 	if modelFlag != "" {
 		cfg.DefaultModel = modelFlag
+	} else if agentDef.Model != "" {
+		cfg.DefaultModel = agentDef.Model
 	}
 
 	// Initialize storage
-	store, err := storage.NewSQLiteStore(cfg.DataPath)
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -133,6 +347,7 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 		logging.Warn("Failed to load persisted settings: %v", err)
 	}
 	applyProviderEnvOverrides(cfg)
+	applyToolsConfigToEnv(cfg)
 
 	// Initialize LLM client based on config
 	llmClient, err := initLLMClient(cfg)
@@ -141,11 +356,17 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 		logging.Warn("LLM client initialization failed: %v (use /provider to configure)", err)
 		// Create a placeholder client that will be replaced when provider is configured
 		llmClient = anthropic.NewClientWithBaseURL("", cfg.DefaultModel, "https://api.kimi.com/coding/v1")
+	} else {
+		checkModelFlag(llmClient)
 	}
 
 	// Initialize tool manager
 	toolManager := tools.NewManager(cfg.WorkDir)
+	toolManager.SetTimeoutPolicy(toolTimeoutPolicy(cfg))
+	toolManager.SetApprovalPolicy(cfg.Tools.Approvals())
+	toolManager.SetRedactionPolicy(toolRedactionPolicy(cfg, store))
 	clipStore := speechcache.New(0)
+	defer clipStore.Stop()
 	integrationtools.Register(toolManager, store, clipStore)
 
 	// Initialize session manager
@@ -155,28 +376,38 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// The embedded server always accepts the local token so existing
+	// single-user setups keep working next to any configured tokens.
+	if err := addLocalAPIToken(cfg); err != nil {
+		return err
+	}
 	server := httpserver.NewServer(cfg, llmClient, toolManager, sessionManager, store, clipStore, portFlag)
 	go func() {
 		logging.Info("Starting HTTP server on port %d", portFlag)
-		if err := server.Run(ctx); err != nil && err.Error() != "http: Server closed" {
+		if err := server.Run(ctx); err != nil {
 			logging.Error("HTTP server error: %v", err)
 		}
 	}()
 
 	// Start scheduler for recurring jobs
 	jobScheduler := scheduler.NewScheduler(store, sessionManager, llmClient, toolManager, cfg)
-	jobScheduler.Start(ctx)
+	server.AddRunCanceller(jobScheduler.Runner())
+	server.SetJobRunner(jobScheduler.Runner())
+	if noSchedulerFlag {
+		logging.Info("Scheduler disabled (scheduler.disabled)")
+		jobScheduler.Bind(ctx)
+	} else {
+		jobScheduler.Start(ctx)
+	}
 	defer jobScheduler.Stop()
 
 	// Create or resume session for TUI
 	var sess *session.Session
 	if continueFlag != "" {
-		sess, err = sessionManager.Get(continueFlag)
+		sess, err = resumeSession(sessionManager)
 		if err != nil {
-			logging.Error("Failed to resume session %s: %v", continueFlag, err)
-			return fmt.Errorf("failed to resume session: %w", err)
+			return err
 		}
-		logging.LogSession("resumed", sess.ID, fmt.Sprintf("agent=%s messages=%d", sess.AgentID, len(sess.Messages)))
 	} else {
 		// Start with an in-memory session to avoid polluting the sessions list
 		// before the user actually sends a message in TUI.
@@ -184,10 +415,12 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 		logging.LogSession("initialized", sess.ID, fmt.Sprintf("agent=%s in-memory", agentFlag))
 	}
 
-	// Get initial task from args if provided
-	var initialTask string
-	if len(args) > 0 {
-		initialTask = args[0]
+	// Get initial task from args, --file or piped stdin if provided
+	initialTask, err := readInitialTask(args)
+	if err != nil {
+		return err
+	}
+	if initialTask != "" {
 		sess.AddUserMessage(initialTask)
 
 		// CLI task counts as first user input, so persist the session now.
@@ -209,9 +442,16 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 		Name:          agentFlag,
 		Model:         cfg.DefaultModel,
 		MaxSteps:      cfg.MaxSteps,
+		MaxTokens:     cfg.MaxTokens,
+		MaxDuration:   cfg.MaxRunDuration(),
+		StopSequences: cfg.StopSequences,
 		Temperature:   cfg.Temperature,
 		ContextWindow: contextWindow,
 	}
+	if agentDef.Temperature != nil {
+		agentConfig.Temperature = *agentDef.Temperature
+	}
+	agentDef.Apply(&agentConfig)
 
 	// Create TUI model
 	tuiModel := tui.New(
@@ -241,7 +481,25 @@ func runAgentWithServer(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runAgent runs a single task in the TUI, or headless with --no-tui, without
+// starting the HTTP server.
 func runAgent(cmd *cobra.Command, args []string) error {
+	args = takeContinueArg(args)
+	if forkFlag && continueFlag == "" {
+		return fmt.Errorf("--fork requires --continue")
+	}
+	if outputFlag != "text" && outputFlag != "json" {
+		return fmt.Errorf("--output must be text or json")
+	}
+
+	initialTask, err := readInitialTask(args)
+	if err != nil {
+		return err
+	}
+	if noTUIFlag && initialTask == "" {
+		return fmt.Errorf("a task is required: pass it as an argument, with --file or on stdin")
+	}
+
 	// Load .env files from common locations (ignore errors if not found)
 	homeDir, _ := os.UserHomeDir()
 	godotenv.Load(".env")                                  // Current directory
@@ -253,32 +511,36 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := applyProviderFlags(cfg); err != nil {
+		return err
+	}
+	if err := applyWorkDirFlag(cfg); err != nil {
+		return err
+	}
 
 	// Initialize logging
-	if err := logging.Init(cfg.DataPath); err != nil {
+	if err := logging.InitWithOptions(cfg.DataPath, loggingOptions(cfg)); err != nil {
 		return fmt.Errorf("failed to initialize logging: %w", err)
 	}
 	defer logging.Close()
+	logConfigWarnings(cfg)
 
-	logging.Info("Starting aagent")
+	logging.Info("Starting aagent run (headless=%t)", noTUIFlag)
 
-	// Override model if specified
-	if modelFlag != "" {
-		cfg.DefaultModel = modelFlag
+	agentDef, err := resolveAgentFlag(cfg)
+	if err != nil {
+		return err
 	}
 
-	// Get API key (support both KIMI_API_KEY and ANTHROPIC_API_KEY)
-	apiKey := os.Getenv("KIMI_API_KEY")
-	if apiKey == "" {
-		apiKey = os.Getenv("ANTHROPIC_API_KEY")
-	}
-	if apiKey == "" {
-		logging.Error("KIMI_API_KEY or ANTHROPIC_API_KEY not set")
-		return fmt.Errorf("KIMI_API_KEY or ANTHROPIC_API_KEY environment variable is required")
+	// Override model if specified; --model wins over the agent's model
+	if modelFlag != "" {
+		cfg.DefaultModel = modelFlag
+	} else if agentDef.Model != "" {
+		cfg.DefaultModel = agentDef.Model
 	}
 
 	// Initialize storage
-	store, err := storage.NewSQLiteStore(cfg.DataPath)
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -289,24 +551,38 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		logging.Warn("Failed to load persisted settings: %v", err)
 	}
 	applyProviderEnvOverrides(cfg)
+	applyToolsConfigToEnv(cfg)
 
-	// Initialize LLM client
-	// Use Kimi Code API (Anthropic-compatible) at https://api.kimi.com/coding/v1
-	var llmClient llm.Client
-	baseURL := os.Getenv("ANTHROPIC_BASE_URL")
-	if baseURL == "" {
-		baseURL = "https://api.kimi.com/coding/v1" // Default to Kimi Code API
+	llmClient, err := initLLMClient(cfg)
+	if err != nil {
+		if noTUIFlag {
+			return fmt.Errorf("failed to initialize LLM client: %w", err)
+		}
+		logging.Warn("LLM client initialization failed: %v (use /provider to configure)", err)
+		llmClient = anthropic.NewClientWithBaseURL("", cfg.DefaultModel, "https://api.kimi.com/coding/v1")
+	} else {
+		checkModelFlag(llmClient)
 	}
-	logging.Info("Using LLM API: %s model=%s", baseURL, cfg.DefaultModel)
-	llmClient = anthropic.NewClientWithBaseURL(apiKey, cfg.DefaultModel, baseURL)
 
 	// Initialize tool manager
 	toolManager := tools.NewManager(cfg.WorkDir)
+	toolManager.SetTimeoutPolicy(toolTimeoutPolicy(cfg))
+	toolManager.SetApprovalPolicy(cfg.Tools.Approvals())
+	toolManager.SetRedactionPolicy(toolRedactionPolicy(cfg, store))
 	clipStore := speechcache.New(0)
+	defer clipStore.Stop()
 	integrationtools.Register(toolManager, store, clipStore)
 
 	// Initialize session manager
 	sessionManager := session.NewManager(store)
+	// Nobody can answer a question during a headless run, so the agent
+	// decides on its own there.
+	if !noTUIFlag {
+		toolManager.RegisterQuestionTool(sessionManager)
+	}
+	toolManager.RegisterToolOutputTool(sessionManager)
+	toolManager.RegisterMemoryTool(store)
+	toolManager.EnableUndo(tools.NewBackupStore(cfg.DataPath))
 	if settings, err2 := store.GetSettings(); err2 == nil {
 		folder := strings.TrimSpace(settings["AAGENT_SESSIONS_FOLDER"])
 		if folder == "" {
@@ -317,12 +593,10 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	// Create or resume session
 	var sess *session.Session
 	if continueFlag != "" {
-		sess, err = sessionManager.Get(continueFlag)
+		sess, err = resumeSession(sessionManager)
 		if err != nil {
-			logging.Error("Failed to resume session %s: %v", continueFlag, err)
-			return fmt.Errorf("failed to resume session: %w", err)
+			return err
 		}
-		logging.LogSession("resumed", sess.ID, fmt.Sprintf("agent=%s messages=%d", sess.AgentID, len(sess.Messages)))
 	} else {
 		sess, err = sessionManager.Create(agentFlag)
 		if err != nil {
@@ -332,11 +606,7 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		logging.LogSession("created", sess.ID, fmt.Sprintf("agent=%s", agentFlag))
 	}
 
-	// Get initial task from args if provided
-	var initialTask string
-	if len(args) > 0 {
-		initialTask = args[0]
-		// Add the initial task to the session
+	if initialTask != "" {
 		sess.AddUserMessage(initialTask)
 	}
 
@@ -349,9 +619,26 @@ func runAgent(cmd *cobra.Command, args []string) error {
 		Name:          agentFlag,
 		Model:         cfg.DefaultModel,
 		MaxSteps:      cfg.MaxSteps,
+		MaxTokens:     cfg.MaxTokens,
+		MaxDuration:   cfg.MaxRunDuration(),
+		StopSequences: cfg.StopSequences,
 		Temperature:   cfg.Temperature,
 		ContextWindow: contextWindow,
 	}
+	if agentDef.Temperature != nil {
+		agentConfig.Temperature = *agentDef.Temperature
+	}
+	agentDef.Apply(&agentConfig)
+	if maxStepsFlag > 0 {
+		agentConfig.MaxSteps = maxStepsFlag
+	}
+
+	if noTUIFlag {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		ag := agent.New(agentConfig, llmClient, toolManager, sessionManager)
+		return runHeadless(ctx, ag, sess, initialTask, outputFlag, os.Stdout, os.Stderr)
+	}
 
 	// Create TUI model
 	tuiModel := tui.New(
@@ -378,6 +665,8 @@ func runAgent(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runServer runs the HTTP API server and, unless --no-scheduler is set, the
+// job scheduler until SIGINT or SIGTERM.
 func runServer(cmd *cobra.Command, args []string) error {
 	// Load .env files from common locations (ignore errors if not found)
 	homeDir, _ := os.UserHomeDir()
@@ -390,17 +679,36 @@ func runServer(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := applyWorkDirFlag(cfg); err != nil {
+		return err
+	}
+	applyServeConfig(cmd, cfg)
 
-	// Initialize logging
-	if err := logging.Init(cfg.DataPath); err != nil {
+	// Initialize logging; a service manager collects stdout.
+	logOpts := loggingOptions(cfg)
+	logOpts.Console = os.Stdout
+	if err := logging.InitWithOptions(cfg.DataPath, logOpts); err != nil {
 		return fmt.Errorf("failed to initialize logging: %w", err)
 	}
 	defer logging.Close()
+	logConfigWarnings(cfg)
+
+	logging.Info("Starting aagent HTTP server (workdir=%s scheduler=%t)", cfg.WorkDir, !noSchedulerFlag)
 
-	logging.Info("Starting aagent HTTP server")
+	shutdownTracing, err := tracing.Init(context.Background(), cfg.Tracing)
+	if err != nil {
+		logging.Warn("Tracing disabled: %v", err)
+	} else if tracing.Enabled() {
+		logging.Info("OpenTelemetry tracing enabled")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		shutdownTracing(ctx)
+	}()
 
 	// Initialize storage
-	store, err := storage.NewSQLiteStore(cfg.DataPath)
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
@@ -411,6 +719,7 @@ func runServer(cmd *cobra.Command, args []string) error {
 		logging.Warn("Failed to load persisted settings: %v", err)
 	}
 	applyProviderEnvOverrides(cfg)
+	applyToolsConfigToEnv(cfg)
 
 	// Initialize LLM client. Do not fail server startup if credentials are not configured yet.
 	llmClient, err := initLLMClient(cfg)
@@ -422,41 +731,155 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	// Initialize tool manager
 	toolManager := tools.NewManager(cfg.WorkDir)
+	toolManager.SetTimeoutPolicy(toolTimeoutPolicy(cfg))
+	toolManager.SetApprovalPolicy(cfg.Tools.Approvals())
+	toolManager.SetRedactionPolicy(toolRedactionPolicy(cfg, store))
 	clipStore := speechcache.New(0)
+	defer clipStore.Stop()
 	integrationtools.Register(toolManager, store, clipStore)
 
 	// Initialize session manager
 	sessionManager := session.NewManager(store)
 
-	// Create HTTP server
+	// Create HTTP server. Without configured tokens fall back to the local token.
+	if len(cfg.Server.APITokens) == 0 {
+		if err := addLocalAPIToken(cfg); err != nil {
+			return err
+		}
+		fmt.Printf("No API tokens configured; using local token from %s\n", httpserver.LocalAPITokenPath(cfg.DataPath))
+	}
 	server := httpserver.NewServer(cfg, llmClient, toolManager, sessionManager, store, clipStore, portFlag)
+	server.SetShutdownGracePeriod(gracePeriodFlag)
 
-	// Setup graceful shutdown
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	// The signal context stops the HTTP server; job runs get their own
+	// context so they can finish within the grace period.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
 
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	// Jobs can be run on demand even when the scheduler loop is disabled.
+	jobScheduler := scheduler.NewScheduler(store, sessionManager, llmClient, toolManager, cfg)
+	server.AddRunCanceller(jobScheduler.Runner())
+	server.SetJobRunner(jobScheduler.Runner())
+	if noSchedulerFlag {
+		logging.Info("Scheduler disabled (--no-scheduler)")
+		jobScheduler.Bind(jobsCtx)
+	} else {
+		jobScheduler.Start(jobsCtx)
+	}
 
+	serverErr := make(chan error, 1)
 	go func() {
-		<-sigChan
-		logging.Info("Received shutdown signal")
-		cancel()
+		serverErr <- server.Run(ctx)
 	}()
 
-	// Start scheduler for recurring jobs
-	jobScheduler := scheduler.NewScheduler(store, sessionManager, llmClient, toolManager, cfg)
-	jobScheduler.Start(ctx)
-	defer jobScheduler.Stop()
+	select {
+	case err := <-serverErr:
+		// The listener failed before any shutdown signal.
+		cancelJobs()
+		jobScheduler.Stop()
+		if err != nil {
+			return fmt.Errorf("server error: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+	logging.Info("Received shutdown signal, waiting up to %s for in-flight runs", gracePeriodFlag)
+
+	stopped := make(chan struct{})
+	go func() {
+		jobScheduler.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(gracePeriodFlag):
+		logging.Warn("Grace period elapsed, cancelling running jobs")
+		cancelJobs()
+		<-stopped
+	}
 
-	// Run server
-	if err := server.Run(ctx); err != nil && err.Error() != "http: Server closed" {
+	if err := <-serverErr; err != nil {
 		return fmt.Errorf("server error: %w", err)
 	}
+	logging.Info("Shutdown complete")
+	return nil
+}
 
+// resolveWorkDir returns the absolute path of an existing directory.
+// applyWorkDirFlag points cfg at --workdir for this invocation.
+func applyWorkDirFlag(cfg *config.Config) error {
+	if workdirFlag == "" {
+		return nil
+	}
+	workDir, err := resolveWorkDir(workdirFlag)
+	if err != nil {
+		return err
+	}
+	cfg.WorkDir = workDir
 	return nil
 }
 
+// applyServeConfig takes --port, --no-scheduler and --grace-period from
+// http.port and scheduler.* when they are not given on the command line.
+func applyServeConfig(cmd *cobra.Command, cfg *config.Config) {
+	flags := cmd.Flags()
+	if !flags.Changed("port") && cfg.HTTP.Port > 0 {
+		portFlag = cfg.HTTP.Port
+	}
+	if !flags.Changed("no-scheduler") && cfg.Scheduler.Disabled {
+		noSchedulerFlag = true
+	}
+	if !flags.Changed("grace-period") && cfg.Scheduler.GracePeriodSeconds > 0 {
+		gracePeriodFlag = time.Duration(cfg.Scheduler.GracePeriodSeconds) * time.Second
+	}
+}
+
+// logConfigWarnings logs the config keys Load did not recognize, typically
+// typos, once logging is set up.
+func logConfigWarnings(cfg *config.Config) {
+	for _, key := range cfg.UnknownKeys {
+		logging.Warn("Unknown config key %s", key)
+	}
+}
+
+func resolveWorkDir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid workdir %q: %w", path, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return "", fmt.Errorf("invalid workdir: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("workdir %s is not a directory", abs)
+	}
+	return abs, nil
+}
+
+// addLocalAPIToken loads or mints the local API token and accepts it on the
+// HTTP server.
+func addLocalAPIToken(cfg *config.Config) error {
+	token, err := httpserver.EnsureLocalAPIToken(cfg.DataPath)
+	if err != nil {
+		return err
+	}
+	cfg.Server.APITokens = append(cfg.Server.APITokens, token)
+	logging.Info("Local API token stored in %s", httpserver.LocalAPITokenPath(cfg.DataPath))
+	return nil
+}
+
+func loggingOptions(cfg *config.Config) logging.Options {
+	return logging.Options{
+		Level:      cfg.Logging.Level,
+		Format:     cfg.Logging.Format,
+		MaxSizeMB:  cfg.Logging.MaxSizeMB,
+		MaxBackups: cfg.Logging.MaxBackups,
+	}
+}
+
 func applySettingsToEnv(settings map[string]string) {
 	for key, value := range settings {
 		k := strings.TrimSpace(key)
@@ -473,6 +896,69 @@ func applySettingsToEnv(settings map[string]string) {
 	}
 }
 
+// applyToolsConfigToEnv exposes the tools.max_result_bytes,
+// tools.summarize_large_results, tools.max_parallel, tools.cache_results,
+// tools.sandbox_roots, tools.camera.*, prompt.* and env config to agents and
+// tool managers unless already set.
+func applyToolsConfigToEnv(cfg *config.Config) {
+	if cfg == nil {
+		return
+	}
+	settings := map[string]string{}
+	for name, value := range cfg.Env {
+		settings[name] = value
+	}
+	if cfg.Tools.MaxResultBytes != 0 {
+		settings["AAGENT_TOOL_RESULT_MAX_BYTES"] = strconv.Itoa(cfg.Tools.MaxResultBytes)
+	}
+	if cfg.Tools.SummarizeLargeResults {
+		settings["AAGENT_SUMMARIZE_LARGE_RESULTS"] = "true"
+	}
+	if cfg.Tools.MaxParallel > 0 {
+		settings["AAGENT_TOOL_MAX_PARALLEL"] = strconv.Itoa(cfg.Tools.MaxParallel)
+	}
+	if cfg.Tools.CacheResults {
+		settings["AAGENT_TOOL_RESULT_CACHE"] = "true"
+	}
+	if len(cfg.Tools.SandboxRoots) > 0 {
+		settings["AAGENT_TOOLS_SANDBOX_ROOTS"] = strings.Join(cfg.Tools.SandboxRoots, ",")
+	}
+	if cfg.Tools.Camera.OutputDir != "" {
+		settings["AAGENT_CAMERA_OUTPUT_DIR"] = cfg.Tools.Camera.OutputDir
+	}
+	if cfg.Tools.Camera.Index > 0 {
+		settings["AAGENT_CAMERA_INDEX"] = strconv.Itoa(cfg.Tools.Camera.Index)
+	}
+	if cfg.Prompt.DisableProjectContext {
+		settings["AAGENT_PROJECT_CONTEXT"] = "false"
+	}
+	if cfg.Prompt.DisableGitContext {
+		settings["AAGENT_PROJECT_CONTEXT_GIT"] = "false"
+	}
+	applySettingsToEnv(settings)
+}
+
+// toolTimeoutPolicy converts tools.timeout_seconds and tools.tool_timeouts.
+func toolTimeoutPolicy(cfg *config.Config) tools.TimeoutPolicy {
+	policy := tools.TimeoutPolicy{Default: time.Duration(cfg.Tools.TimeoutSeconds) * time.Second}
+	if len(cfg.Tools.ToolTimeouts) > 0 {
+		policy.PerTool = make(map[string]time.Duration, len(cfg.Tools.ToolTimeouts))
+		for name, seconds := range cfg.Tools.ToolTimeouts {
+			policy.PerTool[strings.TrimSpace(name)] = time.Duration(seconds) * time.Second
+		}
+	}
+	return policy
+}
+
+// toolRedactionPolicy removes the secrets aagent knows about from tool
+// results, unless cfg turns redaction off.
+func toolRedactionPolicy(cfg *config.Config, store storage.Store) tools.RedactionPolicy {
+	return tools.RedactionPolicy{
+		Disabled: cfg.Tools.DisableRedaction,
+		Secrets:  reqlog.Secrets(cfg, store),
+	}
+}
+
 func applyProviderEnvOverrides(cfg *config.Config) {
 	if cfg == nil {
 		return
@@ -500,22 +986,19 @@ func viewLogs(cmd *cobra.Command, args []string) error {
 
 	logDir := cfg.DataPath + "/logs"
 
-	// Find the most recent log file
-	entries, err := os.ReadDir(logDir)
-	if err != nil {
-		return fmt.Errorf("failed to read log directory: %w", err)
-	}
-
-	if len(entries) == 0 {
-		fmt.Println("No log files found")
-		return nil
-	}
-
-	// Get the most recent log file
-	var latestLog string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			latestLog = logDir + "/" + entry.Name()
+	// Prefer the active rotating log; fall back to the newest legacy
+	// per-day file from older releases.
+	latestLog := logging.LogFilePath(cfg.DataPath)
+	if _, err := os.Stat(latestLog); err != nil {
+		latestLog = ""
+		entries, err := os.ReadDir(logDir)
+		if err != nil {
+			return fmt.Errorf("failed to read log directory: %w", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasPrefix(entry.Name(), "aagent_") {
+				latestLog = logDir + "/" + entry.Name()
+			}
 		}
 	}
 
@@ -551,13 +1034,42 @@ func listSessions(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
-	store, err := storage.NewSQLiteStore(cfg.DataPath)
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
 	if err != nil {
 		return fmt.Errorf("failed to initialize storage: %w", err)
 	}
 	defer store.Close()
 
-	sessions, err := store.ListSessions()
+	limit, _ := cmd.Flags().GetInt("limit")
+	offset, _ := cmd.Flags().GetInt("offset")
+	status, _ := cmd.Flags().GetString("status")
+	agentID, _ := cmd.Flags().GetString("agent")
+	createdAfterRaw, _ := cmd.Flags().GetString("created-after")
+	if limit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	if offset < 0 {
+		return fmt.Errorf("--offset must not be negative")
+	}
+
+	filter := storage.SessionFilter{
+		Status:  status,
+		AgentID: agentID,
+		Limit:   limit,
+		Offset:  offset,
+	}
+	if createdAfterRaw = strings.TrimSpace(createdAfterRaw); createdAfterRaw != "" {
+		createdAfter, err := time.Parse(time.RFC3339, createdAfterRaw)
+		if err != nil {
+			createdAfter, err = time.ParseInLocation("2006-01-02", createdAfterRaw, time.Local)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid --created-after %q: use RFC3339 or YYYY-MM-DD", createdAfterRaw)
+		}
+		filter.CreatedAfter = &createdAfter
+	}
+
+	sessions, total, err := store.ListSessionsPage(filter)
 	if err != nil {
 		return fmt.Errorf("failed to list sessions: %w", err)
 	}
@@ -567,41 +1079,146 @@ func listSessions(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	fmt.Printf("%-8s  %-20s  %-10s  %-30s\n", "ID", "Created", "Status", "Title")
-	fmt.Println(strings.Repeat("-", 80))
+	fmt.Printf("%-50s  %-20s  %-10s  %-8s\n", "Title", "Created", "Status", "ID")
+	fmt.Println(strings.Repeat("-", 94))
 	for _, s := range sessions {
 		title := s.Title
 		if title == "" {
 			title = "(no title)"
 		}
-		if len(title) > 30 {
-			title = title[:27] + "..."
+		if runes := []rune(title); len(runes) > 50 {
+			title = string(runes[:47]) + "..."
 		}
-		fmt.Printf("%-8s  %-20s  %-10s  %-30s\n", s.ID[:8], s.CreatedAt.Format("2006-01-02 15:04:05"), s.Status, title)
+		fmt.Printf("%-50s  %-20s  %-10s  %-8s\n", title, s.CreatedAt.Format("2006-01-02 15:04:05"), s.Status, s.ID[:8])
+	}
+	if shown := offset + len(sessions); shown < total || offset > 0 {
+		fmt.Printf("\nShowing %d-%d of %d sessions\n", offset+1, shown, total)
 	}
 
 	return nil
 }
 
-// initLLMClient initializes the LLM client based on config and environment
-func initLLMClient(cfg *config.Config) (llm.Client, error) {
-	resolveEnvKeys := func(providerType config.ProviderType) []string {
-		switch providerType {
-		case config.ProviderKimi:
-			return []string{"KIMI_API_KEY"}
-		case config.ProviderAnthropic:
-			return []string{"ANTHROPIC_API_KEY"}
-		case config.ProviderOpenRouter:
-			return []string{"OPENROUTER_API_KEY"}
-		case config.ProviderGoogle:
-			return []string{"GOOGLE_API_KEY", "GEMINI_API_KEY"}
-		case config.ProviderOpenAI:
-			return []string{"OPENAI_API_KEY"}
-		default:
-			return nil
+// resolveAgentFlag looks up the --agent type in the agents registry.
+func resolveAgentFlag(cfg *config.Config) (*agents.Definition, error) {
+	registry, err := agents.Load(cfg, cfg.WorkDir)
+	if err != nil {
+		logging.Warn("Failed to load some agent definitions: %v", err)
+	}
+	if !registry.Has(agentFlag) {
+		return nil, fmt.Errorf("unknown agent type %q (see 'aagent agents list')", agentFlag)
+	}
+	return registry.Resolve(agentFlag), nil
+}
+
+func listAgents(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	registry, err := agents.Load(cfg, cfg.WorkDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	fmt.Printf("%-12s  %-50s  %s\n", "Name", "Description", "Source")
+	fmt.Println(strings.Repeat("-", 94))
+	for _, def := range registry.List() {
+		description := def.Description
+		if runes := []rune(description); len(runes) > 50 {
+			description = string(runes[:47]) + "..."
 		}
+		fmt.Printf("%-12s  %-50s  %s\n", def.Name, description, def.Source)
+	}
+
+	return nil
+}
+
+func pruneSessions(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	olderThanRaw, _ := cmd.Flags().GetString("older-than")
+	keepLast, _ := cmd.Flags().GetInt("keep-last-per-job")
+	includeAll, _ := cmd.Flags().GetBool("all")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+	var maxAge time.Duration
+	if strings.TrimSpace(olderThanRaw) != "" {
+		maxAge, err = config.ParseAge(olderThanRaw)
+	} else {
+		maxAge, err = cfg.Retention.MaxAge()
+	}
+	if err != nil {
+		return fmt.Errorf("invalid --older-than: %w", err)
+	}
+	if maxAge <= 0 {
+		return fmt.Errorf("--older-than is required when retention.max_session_age is not configured")
+	}
+	if keepLast < 0 {
+		keepLast = cfg.Retention.KeepLastPerJob
+	}
+
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	defer store.Close()
+
+	purged, err := scheduler.PurgeSessions(store, time.Now().Add(-maxAge), nil, storage.PurgeOptions{
+		KeepLastPerJob: keepLast,
+		IncludeNonJob:  includeAll,
+		DryRun:         dryRun,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to prune sessions: %w", err)
 	}
 
+	if len(purged) == 0 {
+		fmt.Println("No sessions to prune")
+		return nil
+	}
+	for _, s := range purged {
+		title := s.Title
+		if title == "" {
+			title = "(no title)"
+		}
+		if runes := []rune(title); len(runes) > 50 {
+			title = string(runes[:47]) + "..."
+		}
+		fmt.Printf("%-50s  %-20s  %-10s  %s\n", title, s.UpdatedAt.Format("2006-01-02 15:04:05"), s.Status, s.ID)
+	}
+	if dryRun {
+		fmt.Printf("\nWould delete %d session(s)\n", len(purged))
+	} else {
+		fmt.Printf("\nDeleted %d session(s)\n", len(purged))
+	}
+	return nil
+}
+
+// initLLMClient initializes the LLM client based on config and environment
+// providerKeyEnv returns the environment variables read for the API key of
+// a provider that has none configured.
+func providerKeyEnv(providerType config.ProviderType) []string {
+	switch providerType {
+	case config.ProviderKimi:
+		return []string{"KIMI_API_KEY"}
+	case config.ProviderAnthropic:
+		return []string{"ANTHROPIC_API_KEY"}
+	case config.ProviderOpenRouter:
+		return []string{"OPENROUTER_API_KEY"}
+	case config.ProviderGoogle:
+		return []string{"GOOGLE_API_KEY", "GEMINI_API_KEY"}
+	case config.ProviderOpenAI:
+		return []string{"OPENAI_API_KEY"}
+	default:
+		return nil
+	}
+}
+
+func initLLMClient(cfg *config.Config) (llm.Client, error) {
 	createDirectClient := func(providerType config.ProviderType, modelOverride string) (llm.Client, string, error) {
 		providerDef := config.GetProviderDefinition(providerType)
 		if providerDef == nil || providerType == config.ProviderFallback || providerType == config.ProviderAutoRouter {
@@ -611,7 +1228,7 @@ func initLLMClient(cfg *config.Config) (llm.Client, error) {
 		provider := cfg.Providers[string(providerType)]
 		apiKey := strings.TrimSpace(provider.APIKey)
 		if apiKey == "" {
-			for _, envKey := range resolveEnvKeys(providerType) {
+			for _, envKey := range providerKeyEnv(providerType) {
 				apiKey = strings.TrimSpace(os.Getenv(envKey))
 				if apiKey != "" {
 					break
@@ -650,11 +1267,11 @@ func initLLMClient(cfg *config.Config) (llm.Client, error) {
 		}
 
 		if providerDef.RequiresKey && apiKey == "" {
-			envKeys := resolveEnvKeys(providerType)
+			envKeys := providerKeyEnv(providerType)
 			if len(envKeys) == 0 {
-				return nil, "", fmt.Errorf("API key required for %s", providerDef.DisplayName)
+				return nil, "", fmt.Errorf("API key required for %s: run `aagent init`", providerDef.DisplayName)
 			}
-			return nil, "", fmt.Errorf("API key required for %s (set %s or use /provider)", providerDef.DisplayName, strings.Join(envKeys, " or "))
+			return nil, "", fmt.Errorf("API key required for %s: run `aagent init`, set %s, or choose another provider with --provider or /provider", providerDef.DisplayName, strings.Join(envKeys, " or "))
 		}
 
 		logging.Info("Using LLM provider: %s API: %s model=%s", providerType, baseURL, model)
@@ -745,8 +1362,32 @@ func initLLMClient(cfg *config.Config) (llm.Client, error) {
 
 	providerRef := config.NormalizeProviderRef(cfg.ActiveProvider)
 	if providerRef == string(config.ProviderAutoRouter) {
-		return autorouter.New(cfg, createClientForProvider), nil
+		return reqlog.WrapFromConfig(autorouter.New(cfg, createClientForProvider), cfg, nil), nil
+	}
+	client, model, err := createClientForProvider(providerRef, "")
+	if err != nil {
+		return nil, err
+	}
+	if model == "" {
+		return reqlog.WrapFromConfig(client, cfg, nil), nil
+	}
+
+	// A direct provider gets the configured fallback_models behind it.
+	chain, chainErr := cfg.FallbackModelNodes(config.ProviderType(providerRef), model)
+	if chainErr != nil {
+		logging.Warn("Ignoring fallback_models entries: %v", chainErr)
+	}
+	nodes := []fallback.Node{{Name: providerRef, Model: model, Client: client}}
+	for _, node := range chain {
+		nodeClient, _, err := createDirectClient(config.ProviderType(node.Provider), node.Model)
+		if err != nil {
+			logging.Warn("Fallback model %s/%s is not available: %v", node.Provider, node.Model, err)
+			continue
+		}
+		nodes = append(nodes, fallback.Node{Name: node.Provider, Model: node.Model, Client: nodeClient})
+	}
+	if len(nodes) == 1 {
+		return reqlog.WrapFromConfig(client, cfg, nil), nil
 	}
-	client, _, err := createClientForProvider(providerRef, "")
-	return client, err
+	return reqlog.WrapFromConfig(fallback.NewClient(nodes), cfg, nil), nil
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/logging"
+)
+
+// providerAliases maps --provider names that are not provider IDs. Ollama
+// serves an OpenAI-compatible API, so it runs through the LM Studio client.
+var providerAliases = map[string]config.ProviderType{
+	"gemini": config.ProviderGoogle,
+	"claude": config.ProviderAnthropic,
+	"ollama": config.ProviderLMStudio,
+}
+
+const defaultOllamaBaseURL = "http://localhost:11434/v1"
+
+// resolveProviderName maps a --provider value or --model prefix to a
+// provider type.
+func resolveProviderName(name string) (config.ProviderType, bool) {
+	normalized := config.NormalizeProviderRef(name)
+	if providerType, ok := providerAliases[normalized]; ok {
+		return providerType, true
+	}
+	providerType := config.ProviderType(normalized)
+	if config.GetProviderDefinition(providerType) == nil {
+		return "", false
+	}
+	return providerType, true
+}
+
+func providerFlagNames() []string {
+	names := make([]string, 0, len(providerAliases)+8)
+	for alias := range providerAliases {
+		names = append(names, alias)
+	}
+	for _, def := range config.SupportedProviders() {
+		names = append(names, string(def.Type))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseProviderFlags returns the provider name (empty to keep the
+// configured provider) and model selected by --provider and --model. Without
+// --provider a model written as "provider/model" selects the provider too,
+// unless the active provider is OpenRouter, whose model IDs have that form.
+func parseProviderFlags(providerName, model, activeProvider string) (string, string, error) {
+	providerName = config.NormalizeProviderRef(providerName)
+	model = strings.TrimSpace(model)
+	if providerName == "" {
+		prefix, rest, found := strings.Cut(model, "/")
+		if !found || rest == "" || config.NormalizeProviderRef(activeProvider) == string(config.ProviderOpenRouter) {
+			return "", model, nil
+		}
+		if _, ok := resolveProviderName(prefix); !ok {
+			return "", model, nil
+		}
+		return config.NormalizeProviderRef(prefix), rest, nil
+	}
+
+	if _, ok := resolveProviderName(providerName); !ok {
+		return "", "", fmt.Errorf("unknown provider %q (use one of: %s)", providerName, strings.Join(providerFlagNames(), ", "))
+	}
+	return providerName, model, nil
+}
+
+// applyProviderFlags switches cfg to the provider and model chosen on the
+// command line for this invocation. It leaves the bare model in modelFlag.
+func applyProviderFlags(cfg *config.Config) error {
+	providerName, model, err := parseProviderFlags(providerFlag, modelFlag, cfg.ActiveProvider)
+	if err != nil {
+		return err
+	}
+	modelFlag = model
+	if cfg.Providers == nil {
+		cfg.Providers = make(map[string]config.Provider)
+	}
+
+	if providerName != "" {
+		providerType, _ := resolveProviderName(providerName)
+		cfg.ActiveProvider = string(providerType)
+		if providerName == "ollama" {
+			provider := cfg.Providers[string(providerType)]
+			provider.Name = string(providerType)
+			provider.BaseURL = ollamaBaseURL()
+			cfg.Providers[string(providerType)] = provider
+		}
+	}
+	if model != "" {
+		ref := config.NormalizeProviderRef(cfg.ActiveProvider)
+		provider := cfg.Providers[ref]
+		provider.Name = ref
+		provider.Model = model
+		cfg.Providers[ref] = provider
+	}
+	return nil
+}
+
+// ollamaBaseURL returns the OpenAI-compatible endpoint of the Ollama server
+// named by OLLAMA_HOST, or the local default.
+func ollamaBaseURL() string {
+	host := strings.TrimSpace(os.Getenv("OLLAMA_HOST"))
+	if host == "" {
+		return defaultOllamaBaseURL
+	}
+	if !strings.Contains(host, "://") {
+		host = "http://" + host
+	}
+	return strings.TrimRight(host, "/") + "/v1"
+}
+
+// checkModelFlag warns when --model is not in the provider's model list.
+// Lists lag behind new releases and some providers serve unlisted aliases,
+// so it never fails the run.
+func checkModelFlag(client llm.Client) {
+	if modelFlag == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	models, err := llm.ListModelIDs(ctx, client)
+	if err != nil || len(models) == 0 || slices.Contains(models, modelFlag) {
+		return
+	}
+	warning := fmt.Sprintf("model %q is not in the provider's model list", modelFlag)
+	if similar := similarModels(models, modelFlag, 5); len(similar) > 0 {
+		warning += " (similar: " + strings.Join(similar, ", ") + ")"
+	}
+	logging.Warn("%s", warning)
+	fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+}
+
+// similarModels returns up to limit models whose IDs contain model or are
+// contained in it, ignoring case.
+func similarModels(models []string, model string, limit int) []string {
+	needle := strings.ToLower(model)
+	var similar []string
+	for _, candidate := range models {
+		lower := strings.ToLower(candidate)
+		if strings.Contains(lower, needle) || strings.Contains(needle, lower) {
+			similar = append(similar, candidate)
+			if len(similar) == limit {
+				break
+			}
+		}
+	}
+	return similar
+}
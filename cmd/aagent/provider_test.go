@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestParseProviderFlags(t *testing.T) {
+	tests := []struct {
+		name         string
+		provider     string
+		model        string
+		active       string
+		wantProvider string
+		wantModel    string
+		wantErr      bool
+	}{
+		{name: "nothing", active: "kimi"},
+		{name: "plain model", model: "kimi-k2.5", active: "kimi", wantModel: "kimi-k2.5"},
+		{name: "provider flag", provider: "Anthropic", model: "claude-sonnet-4-5", active: "kimi", wantProvider: "anthropic", wantModel: "claude-sonnet-4-5"},
+		{name: "shorthand", model: "anthropic/claude-sonnet-4-5", active: "kimi", wantProvider: "anthropic", wantModel: "claude-sonnet-4-5"},
+		{name: "alias shorthand", model: "gemini/gemini-2.5-pro", active: "kimi", wantProvider: "gemini", wantModel: "gemini-2.5-pro"},
+		{name: "ollama model with tag", model: "ollama/qwen2.5-coder:7b", active: "kimi", wantProvider: "ollama", wantModel: "qwen2.5-coder:7b"},
+		{name: "openrouter keeps slash", model: "anthropic/claude-sonnet-4-5", active: "openrouter", wantModel: "anthropic/claude-sonnet-4-5"},
+		{name: "unknown prefix", model: "meta-llama/llama-3", active: "lmstudio", wantModel: "meta-llama/llama-3"},
+		{name: "provider flag keeps slash", provider: "openrouter", model: "anthropic/claude-sonnet-4-5", active: "kimi", wantProvider: "openrouter", wantModel: "anthropic/claude-sonnet-4-5"},
+		{name: "unknown provider", provider: "acme", active: "kimi", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, model, err := parseProviderFlags(tt.provider, tt.model, tt.active)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if provider != tt.wantProvider || model != tt.wantModel {
+				t.Errorf("got (%q, %q), want (%q, %q)", provider, model, tt.wantProvider, tt.wantModel)
+			}
+		})
+	}
+}
+
+func TestSimilarModels(t *testing.T) {
+	models := []string{"claude-sonnet-4-5", "claude-sonnet-4-5-20250929", "claude-opus-4-6", "gpt-4.1"}
+	got := similarModels(models, "Claude-Sonnet", 5)
+	if len(got) != 2 || got[0] != "claude-sonnet-4-5" || got[1] != "claude-sonnet-4-5-20250929" {
+		t.Errorf("similarModels() = %v", got)
+	}
+	if got := similarModels(models, "claude", 1); len(got) != 1 {
+		t.Errorf("limit not applied: %v", got)
+	}
+	if got := similarModels(models, "gemini-2.5-pro", 5); len(got) != 0 {
+		t.Errorf("expected no matches, got %v", got)
+	}
+}
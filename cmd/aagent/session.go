@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/jobs"
+	"github.com/A2gent/brute/internal/llm/reqlog"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/transcript"
+	"github.com/charmbracelet/x/term"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+)
+
+// continueLast is the value of a bare --continue: resume the most recently
+// updated interactive session.
+const continueLast = "last"
+
+// nonInteractiveAgentIDs are agents that only run jobs; their sessions are
+// never offered for resuming.
+var nonInteractiveAgentIDs = []string{jobs.DefaultAgentID, "scheduler"}
+
+// takeContinueArg handles "-c <id>": with a bare --continue pflag leaves the
+// ID as the first positional argument, so a leading session UUID is taken
+// back as the --continue value.
+func takeContinueArg(args []string) []string {
+	if continueFlag != continueLast || len(args) == 0 {
+		return args
+	}
+	if _, err := uuid.Parse(args[0]); err != nil {
+		return args
+	}
+	continueFlag = args[0]
+	return args[1:]
+}
+
+// recentInteractiveSessions returns up to limit non-job sessions, most
+// recently updated first.
+func recentInteractiveSessions(sessionManager *session.Manager, limit int) ([]*session.Session, error) {
+	sessions, _, err := sessionManager.ListPage(storage.SessionFilter{
+		ExcludeAgentIDs: nonInteractiveAgentIDs,
+		OrderByUpdated:  true,
+		Limit:           limit,
+	})
+	return sessions, err
+}
+
+// pickAndContinueSession lists recent sessions, asks which one to resume and
+// starts the TUI on it.
+func pickAndContinueSession(cmd *cobra.Command, args []string) error {
+	limit, _ := cmd.Flags().GetInt("limit")
+	if limit <= 0 {
+		return fmt.Errorf("--limit must be positive")
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	recent, err := recentInteractiveSessions(session.NewManager(store), limit)
+	store.Close()
+	if err != nil {
+		return fmt.Errorf("failed to list sessions: %w", err)
+	}
+	if len(recent) == 0 {
+		return fmt.Errorf("no session to continue")
+	}
+
+	for i, s := range recent {
+		title := s.Title
+		if title == "" {
+			title = "(no title)"
+		}
+		if runes := []rune(title); len(runes) > 50 {
+			title = string(runes[:47]) + "..."
+		}
+		fmt.Printf("%3d  %-50s  %-16s  %-10s  %s\n", i+1, title, s.UpdatedAt.Local().Format("2006-01-02 15:04"), s.Status, s.ID[:8])
+	}
+	fmt.Printf("\nSession to continue [1-%d, default 1]: ", len(recent))
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	choice := 1
+	if answer = strings.TrimSpace(answer); answer != "" {
+		if _, err := fmt.Sscanf(answer, "%d", &choice); err != nil || choice < 1 || choice > len(recent) {
+			return fmt.Errorf("invalid choice %q", answer)
+		}
+	}
+
+	continueFlag = recent[choice-1].ID
+	return runAgentWithServer(cmd, nil)
+}
+
+// openSessionStore loads the configuration and opens the session store.
+func openSessionStore() (storage.Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return store, nil
+}
+
+// resolveSessionID expands ref to a full session ID. Like git, it accepts
+// any prefix that matches exactly one session.
+func resolveSessionID(store storage.Store, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", fmt.Errorf("session ID is required")
+	}
+	if _, err := store.GetSession(ref); err == nil {
+		return ref, nil
+	}
+	all, _, err := store.ListSessionsPage(storage.SessionFilter{Limit: -1})
+	if err != nil {
+		return "", fmt.Errorf("failed to list sessions: %w", err)
+	}
+	var matches []string
+	for _, s := range all {
+		if strings.HasPrefix(s.ID, ref) {
+			matches = append(matches, s.ID)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("session %q not found", ref)
+	case 1:
+		return matches[0], nil
+	default:
+		return "", fmt.Errorf("session prefix %q is ambiguous (%d matches)", ref, len(matches))
+	}
+}
+
+func terminalWidth() int {
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 0 {
+		return width
+	}
+	return 0
+}
+
+func showSession(cmd *cobra.Command, args []string) error {
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	id, err := resolveSessionID(store, args[0])
+	if err != nil {
+		return err
+	}
+	sess, err := session.NewManager(store).Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+	fmt.Print(transcript.Text(sess, terminalWidth()))
+	return nil
+}
+
+func debugSession(cmd *cobra.Command, args []string) error {
+	count, _ := cmd.Flags().GetInt("count")
+	if count <= 0 {
+		return fmt.Errorf("--count must be positive")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	id, err := resolveSessionID(store, args[0])
+	store.Close()
+	if err != nil {
+		return err
+	}
+
+	entries, err := reqlog.LastEntries(reqlog.Dir(cfg), id, count)
+	if err != nil {
+		return fmt.Errorf("failed to read request log: %w", err)
+	}
+	if len(entries) == 0 {
+		hint := ""
+		if !cfg.LLM.LogRequests {
+			hint = "; enable llm.log_requests in the config to record them"
+		}
+		return fmt.Errorf("no LLM requests logged for session %s%s", id, hint)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func exportSession(cmd *cobra.Command, args []string) error {
+	outputPath, _ := cmd.Flags().GetString("output")
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	id, err := resolveSessionID(store, args[0])
+	if err != nil {
+		return err
+	}
+	sess, err := session.NewManager(store).Get(id)
+	if err != nil {
+		return fmt.Errorf("failed to load session: %w", err)
+	}
+
+	markdown := transcript.Markdown(sess)
+	if outputPath == "" || outputPath == "-" {
+		fmt.Print(markdown)
+		return nil
+	}
+	if err := os.WriteFile(outputPath, []byte(markdown), 0o644); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	fmt.Printf("Exported session %s to %s\n", sess.ID, outputPath)
+	return nil
+}
+
+func deleteSessions(cmd *cobra.Command, args []string) error {
+	allCompleted, _ := cmd.Flags().GetBool("all-completed")
+	yes, _ := cmd.Flags().GetBool("yes")
+	if len(args) == 0 && !allCompleted {
+		return fmt.Errorf("pass session IDs or --all-completed")
+	}
+
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var targets []*storage.Session
+	seen := make(map[string]struct{})
+	for _, ref := range args {
+		id, err := resolveSessionID(store, ref)
+		if err != nil {
+			return err
+		}
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		sess, err := store.GetSession(id)
+		if err != nil {
+			return fmt.Errorf("failed to load session %s: %w", id, err)
+		}
+		if sess.Status == string(session.StatusRunning) {
+			return fmt.Errorf("session %s is running; cancel it before deleting", id)
+		}
+		seen[id] = struct{}{}
+		targets = append(targets, sess)
+	}
+	if allCompleted {
+		completed, _, err := store.ListSessionsPage(storage.SessionFilter{Status: string(session.StatusCompleted), Limit: -1})
+		if err != nil {
+			return fmt.Errorf("failed to list sessions: %w", err)
+		}
+		for _, sess := range completed {
+			if _, dup := seen[sess.ID]; !dup {
+				seen[sess.ID] = struct{}{}
+				targets = append(targets, sess)
+			}
+		}
+	}
+
+	if len(targets) == 0 {
+		fmt.Println("No sessions to delete")
+		return nil
+	}
+	for _, s := range targets {
+		title := s.Title
+		if title == "" {
+			title = "(no title)"
+		}
+		if runes := []rune(title); len(runes) > 50 {
+			title = string(runes[:47]) + "..."
+		}
+		fmt.Printf("%-50s  %-10s  %s\n", title, s.Status, s.ID)
+	}
+
+	if !yes {
+		if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return fmt.Errorf("refusing to delete without confirmation; pass --yes")
+		}
+		fmt.Printf("\nDelete %d session(s)? [y/N] ", len(targets))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+
+	for _, s := range targets {
+		if err := store.DeleteSession(s.ID); err != nil {
+			return fmt.Errorf("failed to delete session %s: %w", s.ID, err)
+		}
+	}
+	fmt.Printf("Deleted %d session(s)\n", len(targets))
+	return nil
+}
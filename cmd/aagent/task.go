@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// readTaskFromStdin returns piped standard input, or "" when stdin is a
+// terminal.
+func readTaskFromStdin() (string, error) {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice != 0 {
+		return "", nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read task from stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// readInitialTask assembles the initial task from the positional arguments,
+// the --file flag and piped stdin.
+func readInitialTask(args []string) (string, error) {
+	var fileContent string
+	if path := strings.TrimSpace(fileFlag); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read --file: %w", err)
+		}
+		fileContent = string(data)
+	}
+	piped, err := readTaskFromStdin()
+	if err != nil {
+		return "", err
+	}
+	return buildInitialTask(args, fileContent, piped), nil
+}
+
+// buildInitialTask joins the task words and the --file contents into the
+// task. Piped input is appended below it as context, or is the task itself
+// when nothing else was given.
+func buildInitialTask(args []string, fileContent, piped string) string {
+	var parts []string
+	if words := strings.TrimSpace(strings.Join(args, " ")); words != "" {
+		parts = append(parts, words)
+	}
+	if fileContent = strings.TrimSpace(fileContent); fileContent != "" {
+		parts = append(parts, fileContent)
+	}
+	if piped = strings.TrimSpace(piped); piped != "" {
+		if len(parts) == 0 {
+			return piped
+		}
+		fence := "```"
+		for strings.Contains(piped, fence) {
+			fence += "`"
+		}
+		parts = append(parts, "Input from stdin:\n"+fence+"\n"+piped+"\n"+fence)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestBuildInitialTask(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		file  string
+		piped string
+		want  string
+	}{
+		{name: "empty"},
+		{name: "joins words", args: []string{"fix", "the", "failing", "tests"}, want: "fix the failing tests"},
+		{name: "quoted arg", args: []string{"review this diff"}, want: "review this diff"},
+		{name: "file only", file: "Refactor the parser.\n", want: "Refactor the parser."},
+		{name: "args and file", args: []string{"follow", "these", "steps"}, file: "1. build\n2. test\n", want: "follow these steps\n\n1. build\n2. test"},
+		{name: "piped only is the task", piped: "summarize the logs\n", want: "summarize the logs"},
+		{name: "piped is context", args: []string{"review this diff"}, piped: "+added line\n", want: "review this diff\n\nInput from stdin:\n```\n+added line\n```"},
+		{name: "file and piped", file: "Explain:", piped: "stack trace", want: "Explain:\n\nInput from stdin:\n```\nstack trace\n```"},
+		{name: "piped backticks", args: []string{"check"}, piped: "```go\nx\n```", want: "check\n\nInput from stdin:\n````\n```go\nx\n```\n````"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildInitialTask(tt.args, tt.file, tt.piped); got != tt.want {
+				t.Errorf("buildInitialTask() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/A2gent/brute/internal/agents"
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/speechcache"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+	"github.com/A2gent/brute/internal/tools/integrationtools"
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// newToolsCmd builds `aagent tools`, which lists the registered tools and
+// runs one directly, without the model, to reproduce tool bugs.
+func newToolsCmd() *cobra.Command {
+	toolsCmd := &cobra.Command{
+		Use:   "tools",
+		Short: "List and run agent tools",
+	}
+	toolsCmd.PersistentFlags().StringVar(&workdirFlag, "workdir", "", "Working directory for the tools (default: work_dir from config)")
+
+	toolsCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List registered tools and their required parameters",
+		Args:  cobra.NoArgs,
+		RunE:  listTools,
+	})
+
+	runCmd := &cobra.Command{
+		Use:   "run <name>",
+		Short: "Run a tool with JSON parameters and print its result",
+		Long: `Run a tool through the tool manager, exactly as the agent would, and print
+its result. Tools that can change files or run commands (anything outside the
+read-only set used by the plan agent) require --yes.`,
+		Example: `  aagent tools run read --params '{"path":"main.go"}'
+  aagent tools run bash --params '{"command":"go test ./..."}' --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: runTool,
+	}
+	runCmd.Flags().String("params", "{}", "Tool parameters as a JSON object")
+	runCmd.Flags().BoolP("yes", "y", false, "Allow tools that can change files or run commands")
+	runCmd.Flags().Bool("json", false, "Print the result as JSON")
+	toolsCmd.AddCommand(runCmd)
+
+	for _, sub := range toolsCmd.Commands() {
+		sub.SilenceUsage = true
+		sub.SilenceErrors = true
+	}
+	return toolsCmd
+}
+
+// loadToolManager builds the tool manager the agent gets, minus tools that
+// need a live session such as question.
+func loadToolManager() (*tools.Manager, func(), error) {
+	homeDir, _ := os.UserHomeDir()
+	godotenv.Load(".env")
+	godotenv.Load(filepath.Join(homeDir, ".env"))
+
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := applyWorkDirFlag(cfg); err != nil {
+		return nil, nil, err
+	}
+	store, err := storage.Open(cfg.Storage.Driver, cfg.Storage.DSN, cfg.DataPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	if settings, err := store.GetSettings(); err == nil {
+		applySettingsToEnv(settings)
+	}
+	applyToolsConfigToEnv(cfg)
+
+	toolManager := tools.NewManager(cfg.WorkDir)
+	toolManager.SetTimeoutPolicy(toolTimeoutPolicy(cfg))
+	toolManager.SetApprovalPolicy(cfg.Tools.Approvals())
+	toolManager.SetRedactionPolicy(toolRedactionPolicy(cfg, store))
+	clipStore := speechcache.New(0)
+	integrationtools.Register(toolManager, store, clipStore)
+	toolManager.RegisterToolOutputTool(session.NewManager(store))
+	toolManager.RegisterMemoryTool(store)
+	toolManager.EnableUndo(tools.NewBackupStore(cfg.DataPath))
+
+	cleanup := func() {
+		clipStore.Stop()
+		store.Close()
+	}
+	return toolManager, cleanup, nil
+}
+
+// requiredParams returns the required parameter names of a JSON schema.
+func requiredParams(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []interface{}:
+		names := make([]string, 0, len(required))
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func listTools(cmd *cobra.Command, args []string) error {
+	toolManager, cleanup, err := loadToolManager()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	defs := toolManager.GetDefinitions()
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+
+	fmt.Printf("%-28s  %-60s  %s\n", "Name", "Description", "Required")
+	fmt.Println(strings.Repeat("-", 110))
+	for _, def := range defs {
+		description := strings.TrimSpace(def.Description)
+		if first, _, found := strings.Cut(description, "\n"); found {
+			description = strings.TrimSpace(first)
+		}
+		if runes := []rune(description); len(runes) > 60 {
+			description = string(runes[:57]) + "..."
+		}
+		required := strings.Join(requiredParams(def.InputSchema), ", ")
+		if required == "" {
+			required = "-"
+		}
+		fmt.Printf("%-28s  %-60s  %s\n", def.Name, description, required)
+	}
+	return nil
+}
+
+// toolIsReadOnly reports whether name is one of the tools the read-only
+// agents may use.
+func toolIsReadOnly(name string) bool {
+	for _, readOnly := range agents.ReadOnlyTools {
+		if readOnly == name {
+			return true
+		}
+	}
+	return false
+}
+
+func runTool(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	rawParams, _ := cmd.Flags().GetString("params")
+	yes, _ := cmd.Flags().GetBool("yes")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	var params map[string]interface{}
+	if err := json.Unmarshal([]byte(rawParams), &params); err != nil {
+		return fmt.Errorf("--params must be a JSON object: %w", err)
+	}
+	if !yes && !toolIsReadOnly(name) {
+		return fmt.Errorf("tool %s can change files or run commands; pass --yes to run it", name)
+	}
+
+	toolManager, cleanup, err := loadToolManager()
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if _, ok := toolManager.Get(name); !ok {
+		return fmt.Errorf("tool not found: %s (see 'aagent tools list')", name)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	result, err := toolManager.Execute(ctx, name, json.RawMessage(rawParams))
+	if err != nil {
+		return fmt.Errorf("tool %s failed: %w", name, err)
+	}
+
+	if asJSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("Success: %t\n", result.Success)
+		if result.Output != "" {
+			fmt.Printf("Output:\n%s\n", strings.TrimRight(result.Output, "\n"))
+		}
+		if result.Error != "" {
+			fmt.Printf("Error: %s\n", result.Error)
+		}
+		if len(result.Metadata) > 0 {
+			metadata, _ := json.MarshalIndent(result.Metadata, "", "  ")
+			fmt.Printf("Metadata: %s\n", metadata)
+		}
+	}
+	if !result.Success {
+		return &exitCodeError{code: exitRunFailed}
+	}
+	return nil
+}
@@ -217,11 +217,13 @@ type Status struct {
 // ---- Payload types ----
 
 // InboundPayload is the JSON inside an inbound task's AgentRequest.Payload.
-// The calling agent (another brute instance) populates this.
+// The calling agent (another brute instance) populates this. TaskID, when set,
+// continues that inbound session instead of resolving one by conversation.
 type InboundPayload struct {
 	A2AVersion     string                 `json:"a2a_version,omitempty"`
 	MessageID      string                 `json:"message_id,omitempty"`
 	ConversationID string                 `json:"conversation_id,omitempty"`
+	TaskID         string                 `json:"task_id,omitempty"`
 	Sender         *A2AParty              `json:"sender,omitempty"`
 	Recipient      *A2AParty              `json:"recipient,omitempty"`
 	Content        []A2AContentPart       `json:"content,omitempty"`
@@ -63,8 +63,24 @@ func NewInboundHandler(
 	}
 }
 
+// RunObserver receives progress while HandleWithEvents runs. Both hooks are
+// optional and are called on the goroutine running the agent loop.
+type RunObserver struct {
+	// OnSession is called once the session has been resolved and saved,
+	// before the agent loop starts.
+	OnSession func(sess *session.Session)
+	// OnEvent receives every agent event emitted during the run.
+	OnEvent func(ev agent.Event)
+}
+
 // Handle implements Handler. Blocks until the agent loop finishes.
 func (h *InboundHandler) Handle(ctx context.Context, req *AgentRequest) ([]byte, error) {
+	return h.HandleWithEvents(ctx, req, RunObserver{})
+}
+
+// HandleWithEvents behaves like Handle but reports the resolved session and
+// agent events to obs while the run progresses.
+func (h *InboundHandler) HandleWithEvents(ctx context.Context, req *AgentRequest, obs RunObserver) ([]byte, error) {
 	// 1. Decode the task payload.
 	var p InboundPayload
 	if err := json.Unmarshal(req.Payload, &p); err != nil {
@@ -142,6 +158,9 @@ func (h *InboundHandler) Handle(ctx context.Context, req *AgentRequest) ([]byte,
 	if err := h.sessionManager.Save(sess); err != nil {
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
+	if obs.OnSession != nil {
+		obs.OnSession(sess)
+	}
 
 	// 4. Build an agent scoped to this session and run the loop.
 	toolManager := h.toolManagerFactory(sess)
@@ -150,13 +169,13 @@ func (h *InboundHandler) Handle(ctx context.Context, req *AgentRequest) ([]byte,
 		return nil, fmt.Errorf("failed to configure inbound execution target: %w", err)
 	}
 
-	result, _, runErr := ag.Run(ctx, sess, strings.TrimSpace(p.Task))
+	result, _, runErr := ag.RunWithEvents(ctx, sess, strings.TrimSpace(p.Task), obs.OnEvent)
 	if runErr != nil {
 		return nil, fmt.Errorf("agent run failed: %w", runErr)
 	}
 	if sess.Status == session.StatusInputRequired {
 		if question, qErr := h.sessionManager.GetPendingQuestion(sess.ID); qErr == nil && question != nil {
-			text := renderPendingQuestion(question)
+			text := RenderPendingQuestion(question)
 			if strings.TrimSpace(text) != "" {
 				responseImages := []A2AImage(nil)
 				content := BuildA2AContent(text, responseImages)
@@ -204,6 +223,17 @@ func (h *InboundHandler) Handle(ctx context.Context, req *AgentRequest) ([]byte,
 var _ Handler = (*InboundHandler)(nil)
 
 func (h *InboundHandler) resolveSession(p InboundPayload) (*session.Session, error) {
+	if taskID := strings.TrimSpace(p.TaskID); taskID != "" {
+		sess, err := h.sessionManager.Get(taskID)
+		if err != nil || sess == nil {
+			return nil, fmt.Errorf("unknown task %q", taskID)
+		}
+		if inbound, _ := sess.Metadata[MetaA2AInbound].(bool); !inbound {
+			return nil, fmt.Errorf("unknown task %q", taskID)
+		}
+		return sess, nil
+	}
+
 	conversationID := strings.TrimSpace(p.ConversationID)
 	sourceAgentID := strings.TrimSpace(p.SourceAgentID)
 	if conversationID == "" || sourceAgentID == "" {
@@ -318,7 +348,9 @@ func sessionImagesToA2A(images []session.ImageAttachment) []A2AImage {
 	return out
 }
 
-func renderPendingQuestion(q *session.QuestionData) string {
+// RenderPendingQuestion formats a pending question, its options and whether a
+// free-form answer is accepted as plain text for a remote agent.
+func RenderPendingQuestion(q *session.QuestionData) string {
 	if q == nil {
 		return ""
 	}
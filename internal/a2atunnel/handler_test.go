@@ -125,3 +125,46 @@ func TestResolveSessionByConversationContinuity(t *testing.T) {
 		t.Fatalf("expected different session for different conversation, got same %s", third.ID)
 	}
 }
+
+func TestResolveSessionByTaskID(t *testing.T) {
+	t.Parallel()
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new sqlite store: %v", err)
+	}
+	defer store.Close()
+
+	manager := session.NewManager(store)
+	handler := &InboundHandler{
+		agentID:        "brute",
+		sessionManager: manager,
+	}
+
+	inbound, err := manager.Create("brute")
+	if err != nil {
+		t.Fatalf("create inbound session: %v", err)
+	}
+	inbound.Metadata[MetaA2AInbound] = true
+	if err := manager.Save(inbound); err != nil {
+		t.Fatalf("save inbound session: %v", err)
+	}
+	local, err := manager.Create("build")
+	if err != nil {
+		t.Fatalf("create local session: %v", err)
+	}
+
+	got, err := handler.resolveSession(InboundPayload{Task: "answer", TaskID: inbound.ID})
+	if err != nil {
+		t.Fatalf("resolveSession by task id failed: %v", err)
+	}
+	if got.ID != inbound.ID {
+		t.Fatalf("expected task %s, got %s", inbound.ID, got.ID)
+	}
+	if _, err := handler.resolveSession(InboundPayload{Task: "answer", TaskID: local.ID}); err == nil {
+		t.Fatal("expected non-A2A session to be rejected as a task")
+	}
+	if _, err := handler.resolveSession(InboundPayload{Task: "answer", TaskID: "missing"}); err == nil {
+		t.Fatal("expected unknown task id to fail")
+	}
+}
@@ -14,7 +14,10 @@ import (
 	"github.com/A2gent/brute/internal/logging"
 	"github.com/A2gent/brute/internal/session"
 	"github.com/A2gent/brute/internal/tools"
+	"github.com/A2gent/brute/internal/tracing"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Config holds agent configuration
@@ -28,6 +31,42 @@ type Config struct {
 	ContextWindow            int
 	CompactionTriggerPercent float64
 	CompactionPrompt         string
+	// Tool-call loop detection: after RepeatNudgeThreshold identical steps
+	// (default 3), or RepeatFailureNudgeThreshold identical failing steps
+	// (default 2), the model is told to change approach; after
+	// RepeatStopThreshold identical steps (default 6) the run stops.
+	// Negative values disable a check.
+	RepeatNudgeThreshold        int
+	RepeatFailureNudgeThreshold int
+	RepeatStopThreshold         int
+	// Tool results larger than MaxToolResultBytes (default 32 KB, negative
+	// disables) are cut to head and tail and the full output is stored for
+	// read_tool_output. SummarizeLargeResults adds a short model summary.
+	MaxToolResultBytes    int
+	SummarizeLargeResults bool
+	// CacheToolResults answers repeated calls of idempotent tools, such as
+	// read and glob, from the results of the run until a tool that may
+	// change something runs.
+	CacheToolResults bool
+	// AllowedTools, when non-empty, is the only set of tools the agent sees
+	// and may call; DeniedTools are always withheld. Agent types set both
+	// through the agents registry.
+	AllowedTools []string
+	DeniedTools  []string
+	// SkillsPrompt lists the markdown skills loaded for the run and is
+	// appended after the system prompt.
+	SkillsPrompt string
+	// MaxTokens caps each model response (default 4096); providers clamp it
+	// to their own maximum. StopSequences end a response early.
+	MaxTokens     int
+	StopSequences []string
+	// ResponseSchema, when set, is the JSON Schema the final answer must
+	// match. A non-matching answer is retried once with the validation error.
+	ResponseSchema json.RawMessage
+	// MaxDuration bounds the wall-clock time of one run; zero means no
+	// limit. A run that reaches it is paused with MetadataTimedOut set and
+	// returns its last answer with ErrMaxDurationExceeded.
+	MaxDuration time.Duration
 }
 
 // Agent represents an AI agent that can execute tasks
@@ -47,6 +86,16 @@ const (
 	EventToolExecuting  EventType = "tool_executing"
 	EventToolCompleted  EventType = "tool_completed"
 	EventProviderTrace  EventType = "provider_trace"
+	// Per-call events are emitted from each call of a step as it starts and
+	// finishes, between the step's EventToolExecuting and EventToolCompleted.
+	EventToolCallStarted  EventType = "tool_call_started"
+	EventToolCallFinished EventType = "tool_call_finished"
+	// EventStepLimitReached is emitted when the run stops at MaxSteps
+	// without a final answer.
+	EventStepLimitReached EventType = "step_limit_reached"
+	// EventUsageUpdated is emitted after every model response with the
+	// session's token totals.
+	EventUsageUpdated EventType = "usage_updated"
 )
 
 const (
@@ -60,8 +109,11 @@ const (
 	metadataTotalInputTokens     = "total_input_tokens"
 	metadataTotalOutputTokens    = "total_output_tokens"
 	metadataCurrentContextTokens = "current_context_tokens"
+	metadataBilledInputTokens    = "billed_input_tokens"
+	metadataProviderUsage        = "provider_usage"
 	metadataCompactionCount      = "compaction_count"
 	metadataLastCompactionAt     = "last_compaction_at"
+	metadataToolCacheHits        = "tool_cache_hits"
 	messageMetadataCompaction    = "context_compaction"
 	defaultCompactionTriggerPct  = 80.0
 	defaultCompactionPrompt      = `You are compacting a coding-agent conversation because context usage is high.
@@ -92,9 +144,25 @@ type Event struct {
 	Type       EventType
 	Step       int
 	Delta      string
-	ToolCalls  []ToolCallEvent  // Populated for EventToolExecuting
-	ToolResult *ToolResultEvent // Populated for EventToolCompleted (single result)
+	ToolCalls  []ToolCallEvent  // Populated for EventToolExecuting (whole step) and EventToolCallStarted (single call)
+	ToolResult *ToolResultEvent // Populated for EventToolCallFinished (single result)
 	Provider   *ProviderTraceEvent
+	Usage      *UsageEvent // Populated for EventUsageUpdated
+}
+
+// UsageEvent reports the tokens of one model response and the session's
+// totals after it.
+type UsageEvent struct {
+	Step              llm.TokenUsage
+	TotalInputTokens  int // new input tokens over the session, as in total_input_tokens
+	TotalOutputTokens int
+	// BilledInputTokens sums the input of every request, which providers
+	// bill in full even when most of it is repeated history.
+	BilledInputTokens int
+	ContextTokens     int
+	// ToolCacheHits counts the tool calls of the session answered from the
+	// result cache, as in tool_cache_hits.
+	ToolCacheHits int
 }
 
 // ToolCallEvent represents a tool call being executed.
@@ -102,15 +170,19 @@ type ToolCallEvent struct {
 	ID               string
 	Name             string
 	Input            string // JSON string
+	InputPreview     string // Short "key=value, ..." rendering of Input
 	ThoughtSignature string
 }
 
 // ToolResultEvent represents the result of a tool execution.
 type ToolResultEvent struct {
-	ToolCallID string
-	Name       string
-	Content    string
-	IsError    bool
+	ToolCallID   string
+	Name         string
+	InputPreview string
+	Content      string
+	IsError      bool
+	Duration     time.Duration
+	OutputBytes  int // Size of Content, before any truncation for the model
 }
 
 type ProviderTraceEvent struct {
@@ -143,6 +215,9 @@ func New(config Config, llmClient llm.Client, toolManager *tools.Manager, sessio
 	if appendPrompt != "" && !systemPromptExplicit {
 		config.SystemPrompt = strings.TrimSpace(config.SystemPrompt) + "\n\n" + appendPrompt
 	}
+	if skillsPrompt := strings.TrimSpace(config.SkillsPrompt); skillsPrompt != "" {
+		config.SystemPrompt = strings.TrimSpace(config.SystemPrompt) + "\n\n" + skillsPrompt
+	}
 
 	return &Agent{
 		config:         config,
@@ -160,14 +235,30 @@ func (a *Agent) Run(ctx context.Context, sess *session.Session, task string) (st
 
 // RunWithEvents executes the agent and emits streaming events when available.
 func (a *Agent) RunWithEvents(ctx context.Context, sess *session.Session, task string, onEvent func(Event)) (string, llm.TokenUsage, error) {
-	logging.Info("Agent run started: session=%s", sess.ID)
+	ctx = logging.WithSessionID(ctx, sess.ID)
+	logging.InfoContext(ctx, "Agent run started: session=%s", sess.ID)
+	ctx, span := tracing.Start(ctx, "agent.run",
+		attribute.String("session.id", sess.ID),
+		attribute.String("agent.name", a.config.Name),
+		attribute.String("llm.model", a.config.Model),
+	)
+	if a.config.MaxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, a.config.MaxDuration, errMaxDuration)
+		defer cancel()
+	}
 	// Note: User message is already added by the TUI before calling Run
 	// Run the agentic loop
 	result, usage, err := a.loop(ctx, sess, onEvent)
+	span.SetAttributes(
+		attribute.Int("llm.usage.input_tokens", usage.InputTokens),
+		attribute.Int("llm.usage.output_tokens", usage.OutputTokens),
+	)
+	tracing.End(span, err)
 	if err != nil {
-		logging.Error("Agent run failed: %v", err)
+		logging.ErrorContext(ctx, "Agent run failed: %v", err)
 	} else {
-		logging.Info("Agent run completed: total_input=%d total_output=%d", usage.InputTokens, usage.OutputTokens)
+		logging.InfoContext(ctx, "Agent run completed: total_input=%d total_output=%d", usage.InputTokens, usage.OutputTokens)
 	}
 	return result, usage, err
 }
@@ -180,40 +271,76 @@ func (a *Agent) loop(ctx context.Context, sess *session.Session, onEvent func(Ev
 
 	// Add session ID to context for tools that need it (e.g., question tool)
 	ctx = context.WithValue(ctx, "session_id", sess.ID)
+	ctx = tools.WithToolAccess(ctx, a.toolAccess())
+	// write refuses to overwrite files this run has not read
+	ctx = tools.WithReadRecord(ctx, tools.NewReadRecord())
+	if a.cachesToolResults() {
+		cache := tools.NewResultCache()
+		ctx = tools.WithResultCache(ctx, cache)
+		defer func() {
+			if hits := cache.Hits(); hits > 0 {
+				logging.InfoContext(ctx, "Tool result cache answered %d call(s) of session %s", hits, sess.ID)
+			}
+		}()
+	}
 
 	// Clean up incomplete tool calls before starting
 	a.cleanupIncompleteToolCalls(sess)
+	a.refreshProjectContext(ctx, sess)
+	delete(sess.Metadata, MetadataTimedOut)
+	loops := newLoopDetector(a.config)
+	schemaRetried := false
+
+	// Each iteration gets its own span; the previous one is ended when the
+	// next step starts or the loop returns.
+	var stepSpan trace.Span
+	defer func() {
+		if stepSpan != nil {
+			stepSpan.End()
+		}
+	}()
+	runCtx := ctx
 
 	for {
 		// Check context - distinguish between user cancellation and timeouts
 		if ctx.Err() != nil {
+			if timedOut(ctx) {
+				return a.stopTimedOut(ctx, sess, totalUsage)
+			}
 			if errors.Is(ctx.Err(), context.Canceled) {
 				// Explicit user cancellation (e.g., user clicked cancel, closed browser)
 				// Pause immediately - user wants to stop
-				logging.Info("User cancelled session %s", sess.ID)
+				logging.InfoContext(ctx, "User cancelled session %s", sess.ID)
 				sess.SetStatus(session.StatusPaused)
 				a.sessionManager.Save(sess)
 				return "", totalUsage, ctx.Err()
 			}
 			// For context.DeadlineExceeded, we continue and let the agent see tool errors
 			// The agent can then decide whether to retry or give up
-			logging.Info("Context deadline exceeded for session %s, continuing to let agent handle errors", sess.ID)
+			logging.InfoContext(ctx, "Context deadline exceeded for session %s, continuing to let agent handle errors", sess.ID)
 		}
 
 		// Check step limit
 		if step >= a.config.MaxSteps {
+			if onEvent != nil {
+				onEvent(Event{Type: EventStepLimitReached, Step: step})
+			}
 			sess.SetStatus(session.StatusCompleted)
 			a.sessionManager.Save(sess)
 			return a.getLastAssistantContent(sess), totalUsage, nil
 		}
 
 		step++
-		logging.Debug("Agent step %d/%d", step, a.config.MaxSteps)
+		if stepSpan != nil {
+			stepSpan.End()
+		}
+		ctx, stepSpan = tracing.Start(logging.WithStep(runCtx, step), "agent.step", attribute.Int("agent.step", step))
+		logging.DebugContext(ctx, "Agent step %d/%d", step, a.config.MaxSteps)
 
 		// Compact conversation before the next normal step once threshold is reached.
 		compactionUsage, compacted, err := a.maybeCompactContext(ctx, sess, step)
 		if err != nil {
-			logging.Warn("Context compaction failed (continuing without compaction): %v", err)
+			logging.WarnContext(ctx, "Context compaction failed (continuing without compaction): %v", err)
 		} else if compacted {
 			totalUsage.InputTokens += compactionUsage.InputTokens
 			totalUsage.OutputTokens += compactionUsage.OutputTokens
@@ -225,6 +352,16 @@ func (a *Agent) loop(ctx context.Context, sess *session.Session, onEvent func(Ev
 		// Call LLM (streaming when supported)
 		response, err := a.callLLM(ctx, request, step, onEvent)
 		if err != nil {
+			if timedOut(ctx) {
+				return a.stopTimedOut(ctx, sess, totalUsage)
+			}
+			if errors.Is(ctx.Err(), context.Canceled) {
+				// Cancelled mid-request: keep what we have and pause like the check above.
+				logging.InfoContext(ctx, "User cancelled session %s during LLM call", sess.ID)
+				sess.SetStatus(session.StatusPaused)
+				a.sessionManager.Save(sess)
+				return "", totalUsage, ctx.Err()
+			}
 			sess.SetStatus(session.StatusFailed)
 			a.sessionManager.Save(sess)
 			return "", totalUsage, fmt.Errorf("LLM error: %w", err)
@@ -234,15 +371,48 @@ func (a *Agent) loop(ctx context.Context, sess *session.Session, onEvent func(Ev
 		totalUsage.InputTokens += response.Usage.InputTokens
 		totalUsage.OutputTokens += response.Usage.OutputTokens
 		a.addTokenUsageMetadata(sess, response.Usage)
+		if response.Provider != "" {
+			logging.InfoContext(ctx, "Step %d served by %s", step, servedByLabel(response))
+			addProviderUsageMetadata(sess, response)
+		}
+		if onEvent != nil {
+			onEvent(Event{Type: EventUsageUpdated, Step: step, Usage: sessionUsageEvent(sess, response.Usage)})
+		}
 
 		// Check if we have tool calls
 		if len(response.ToolCalls) == 0 {
 			// No tool calls - agent is done
 			finalContent := strings.TrimSpace(response.Content)
-			if finalContent == "" {
+			if finalContent == "" && len(a.config.ResponseSchema) == 0 {
 				finalContent = a.fallbackAssistantContentFromRecentTools(sess)
 			}
+			var schemaErr error
+			if len(a.config.ResponseSchema) > 0 {
+				var structured string
+				structured, schemaErr = llm.ValidateJSON(a.config.ResponseSchema, finalContent)
+				if schemaErr == nil {
+					finalContent = structured
+				}
+			}
 			sess.AddAssistantMessageWithImagesAndMetadata(finalContent, llmImagesToSession(response.Images), nil, nil)
+			if schemaErr != nil && !schemaRetried {
+				schemaRetried = true
+				logging.WarnContext(ctx, "Final answer does not match the response schema, retrying: %v", schemaErr)
+				sess.AddUserMessage(fmt.Sprintf(schemaRetryMessage, schemaErr))
+				a.sessionManager.Save(sess)
+				if onEvent != nil {
+					onEvent(Event{Type: EventStepCompleted, Step: step})
+				}
+				continue
+			}
+			if schemaErr != nil {
+				sess.SetStatus(session.StatusFailed)
+				a.sessionManager.Save(sess)
+				if onEvent != nil {
+					onEvent(Event{Type: EventStepCompleted, Step: step})
+				}
+				return finalContent, totalUsage, fmt.Errorf("%w: %v", ErrResponseSchemaMismatch, schemaErr)
+			}
 			sess.SetStatus(session.StatusCompleted)
 			a.sessionManager.Save(sess)
 			if onEvent != nil {
@@ -275,26 +445,35 @@ func (a *Agent) loop(ctx context.Context, sess *session.Session, onEvent func(Ev
 		if onEvent != nil {
 			toolCallEvents := make([]ToolCallEvent, len(response.ToolCalls))
 			for i, tc := range response.ToolCalls {
-				toolCallEvents[i] = ToolCallEvent{
-					ID:               tc.ID,
-					Name:             tc.Name,
-					Input:            tc.Input,
-					ThoughtSignature: tc.ThoughtSignature,
-				}
+				toolCallEvents[i] = newToolCallEvent(tc)
 			}
 			onEvent(Event{Type: EventToolExecuting, Step: step, ToolCalls: toolCallEvents})
 		}
-		toolResults := a.toolManager.ExecuteParallel(ctx, response.ToolCalls)
+		toolResults := a.toolManager.ExecuteParallelWithHooks(ctx, response.ToolCalls, toolCallHooks(step, response.ToolCalls, onEvent))
+		addToolCacheHitsMetadata(sess, toolResults)
+
+		loopAction := loops.observe(response.ToolCalls, toolResults)
+		if loopAction == loopNudge {
+			logging.WarnContext(ctx, "Repeated tool call detected (%d in a row), nudging the model", loops.repeats)
+			appendLoopNudge(toolResults, loops.repeats)
+		}
+
+		summaryUsage := a.capToolResults(ctx, sess, toolResults)
+		totalUsage.InputTokens += summaryUsage.InputTokens
+		totalUsage.OutputTokens += summaryUsage.OutputTokens
 
 		// Convert results
 		sessionResults := make([]session.ToolResult, len(toolResults))
 		for i, tr := range toolResults {
 			sessionResults[i] = session.ToolResult{
-				ToolCallID: tr.ToolCallID,
-				Content:    tr.Content,
-				IsError:    tr.IsError,
-				Metadata:   tr.Metadata,
-				Name:       tr.Name,
+				ToolCallID:  tr.ToolCallID,
+				Content:     tr.Content,
+				IsError:     tr.IsError,
+				Metadata:    tr.Metadata,
+				Name:        tr.Name,
+				StartedAt:   tr.StartedAt,
+				DurationMs:  tr.DurationMs,
+				OutputBytes: tr.OutputBytes,
 			}
 		}
 
@@ -305,25 +484,44 @@ func (a *Agent) loop(ctx context.Context, sess *session.Session, onEvent func(Ev
 		// Also sync any fields that tools may have updated (e.g., task_progress)
 		// IMPORTANT: Do this BEFORE Save() so we can detect status changes made by tools
 		freshSess, reloadErr := a.sessionManager.Get(sess.ID)
+		awaitingInput := awaitsUserInput(toolResults)
 		if reloadErr == nil {
 			// Sync task_progress from DB (may have been updated by session_task_progress tool)
 			sess.TaskProgress = freshSess.TaskProgress
 
 			if freshSess.Status == session.StatusInputRequired {
-				logging.Info("Session %s requires user input (detected after tool execution), pausing", sess.ID)
+				awaitingInput = true
 				// Keep caller-visible session state in sync with DB state set by tools.
 				sess.Status = freshSess.Status
 				sess.Metadata = freshSess.Metadata
 				sess.TaskProgress = freshSess.TaskProgress
 				sess.UpdatedAt = freshSess.UpdatedAt
-				// Don't save the local sess changes - use the fresh one with input_required status
-				if onEvent != nil {
-					onEvent(Event{Type: EventToolCompleted, Step: step})
-					onEvent(Event{Type: EventStepCompleted, Step: step})
-				}
-				return "", totalUsage, nil
 			}
 		}
+		// A pending question ends the run; the answer starts a new one.
+		if awaitingInput {
+			logging.InfoContext(ctx, "Session %s requires user input (detected after tool execution), pausing", sess.ID)
+			sess.Status = session.StatusInputRequired
+			// Don't save the local sess changes - use the fresh one with input_required status
+			if onEvent != nil {
+				onEvent(Event{Type: EventToolCompleted, Step: step})
+				onEvent(Event{Type: EventStepCompleted, Step: step})
+			}
+			return "", totalUsage, nil
+		}
+
+		if loopAction == loopStop {
+			logging.WarnContext(ctx, "Stopping run: tool call repeated %d times in a row", loops.repeats)
+			finalContent := fmt.Sprintf(loopStopMessage, loops.repeats)
+			sess.AddAssistantMessage(finalContent, nil)
+			sess.SetStatus(session.StatusCompleted)
+			a.sessionManager.Save(sess)
+			if onEvent != nil {
+				onEvent(Event{Type: EventToolCompleted, Step: step})
+				onEvent(Event{Type: EventStepCompleted, Step: step})
+			}
+			return finalContent, totalUsage, nil
+		}
 
 		// Save session after each step
 		if err := a.sessionManager.Save(sess); err != nil {
@@ -338,6 +536,66 @@ func (a *Agent) loop(ctx context.Context, sess *session.Session, onEvent func(Ev
 	}
 }
 
+// ErrMaxDurationExceeded is returned, with the last assistant content and
+// the usage so far, when a run is stopped at Config.MaxDuration.
+var ErrMaxDurationExceeded = errors.New("agent run exceeded its maximum duration")
+
+// MetadataTimedOut is the session metadata key set to true when the last
+// run was stopped at Config.MaxDuration.
+const MetadataTimedOut = "timed_out"
+
+// errMaxDuration is the cause of the run deadline, which tells it apart from
+// deadlines set by callers.
+var errMaxDuration = errors.New("maximum run duration reached")
+
+// timedOut reports whether ctx ended at the run's MaxDuration.
+func timedOut(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errMaxDuration)
+}
+
+// stopTimedOut pauses a run that reached MaxDuration, keeping its messages
+// so that it can be resumed, and flags the session as timed out.
+func (a *Agent) stopTimedOut(ctx context.Context, sess *session.Session, usage llm.TokenUsage) (string, llm.TokenUsage, error) {
+	logging.WarnContext(ctx, "Session %s reached its maximum run duration of %s", sess.ID, a.config.MaxDuration)
+	if sess.Metadata == nil {
+		sess.Metadata = map[string]interface{}{}
+	}
+	sess.Metadata[MetadataTimedOut] = true
+	sess.SetStatus(session.StatusPaused)
+	a.sessionManager.Save(sess)
+	return a.getLastAssistantContent(sess), usage, fmt.Errorf("%w (%s)", ErrMaxDurationExceeded, a.config.MaxDuration)
+}
+
+// ErrResponseSchemaMismatch is returned when the final answer still does not
+// match Config.ResponseSchema after the retry.
+var ErrResponseSchemaMismatch = errors.New("final answer does not match the response schema")
+
+// schemaRetryMessage asks the model to fix a final answer that failed
+// Config.ResponseSchema; %v is the validation error.
+const schemaRetryMessage = "Your final answer does not match the required JSON schema: %v\n\nReply again with only the corrected JSON."
+
+// responseFormat returns the structured output format for requests, if any.
+func (a *Agent) responseFormat() *llm.ResponseFormat {
+	if len(a.config.ResponseSchema) == 0 {
+		return nil
+	}
+	return &llm.ResponseFormat{Schema: a.config.ResponseSchema}
+}
+
+// awaitsUserInput reports whether a tool asked the user a question during
+// this step.
+func awaitsUserInput(results []llm.ToolResult) bool {
+	for _, result := range results {
+		if result.IsError {
+			continue
+		}
+		if awaiting, _ := result.Metadata[tools.AwaitingInputMetadataKey].(bool); awaiting {
+			return true
+		}
+	}
+	return false
+}
+
 func (a *Agent) fallbackAssistantContentFromRecentTools(sess *session.Session) string {
 	if sess == nil {
 		return "I finished tool execution but produced no final text response."
@@ -364,6 +622,24 @@ func (a *Agent) fallbackAssistantContentFromRecentTools(sess *session.Session) s
 }
 
 func (a *Agent) callLLM(ctx context.Context, request *llm.ChatRequest, step int, onEvent func(Event)) (*llm.ChatResponse, error) {
+	ctx, span := tracing.Start(ctx, "llm.chat",
+		attribute.String("llm.model", request.Model),
+		attribute.Int("llm.messages", len(request.Messages)),
+		attribute.Int("agent.step", step),
+	)
+	response, err := a.chat(ctx, request, step, onEvent)
+	if response != nil {
+		span.SetAttributes(
+			attribute.Int("llm.usage.input_tokens", response.Usage.InputTokens),
+			attribute.Int("llm.usage.output_tokens", response.Usage.OutputTokens),
+			attribute.Int("llm.tool_calls", len(response.ToolCalls)),
+		)
+	}
+	tracing.End(span, err)
+	return response, err
+}
+
+func (a *Agent) chat(ctx context.Context, request *llm.ChatRequest, step int, onEvent func(Event)) (*llm.ChatResponse, error) {
 	// When no event sink is provided, use non-streaming Chat.
 	// This avoids "partial stream emitted" fallback lock-in and lets fallback chains
 	// seamlessly move to the next provider on retryable failures.
@@ -415,6 +691,7 @@ func (a *Agent) buildRequest(sess *session.Session) *llm.ChatRequest {
 	// Convert session messages to LLM messages
 	activeMessages := a.getActiveConversationMessages(sess)
 	messages := make([]llm.Message, 0, len(activeMessages))
+	vision := llm.SupportsVision(a.config.Model)
 
 	for _, m := range activeMessages {
 		msg := llm.Message{
@@ -447,6 +724,11 @@ func (a *Agent) buildRequest(sess *session.Session) *llm.ChatRequest {
 					Metadata:   tr.Metadata,
 					Name:       tr.Name,
 				}
+				// Images a tool produced, such as a camera photo, reach
+				// vision models as image parts of the tool turn.
+				if image, ok := llm.InlineImageFromMetadata(tr.Metadata); ok && vision {
+					msg.Parts = append(msg.Parts, image)
+				}
 			}
 		}
 
@@ -454,11 +736,14 @@ func (a *Agent) buildRequest(sess *session.Session) *llm.ChatRequest {
 	}
 
 	return &llm.ChatRequest{
-		Model:        a.config.Model,
-		Messages:     messages,
-		Tools:        a.toolManager.GetDefinitions(),
-		Temperature:  a.config.Temperature,
-		SystemPrompt: a.config.SystemPrompt,
+		Model:          a.config.Model,
+		Messages:       messages,
+		Tools:          tools.FilterDefinitions(a.toolManager.GetDefinitions(), a.toolAccess()),
+		Temperature:    a.config.Temperature,
+		MaxTokens:      a.maxTokens(),
+		SystemPrompt:   a.systemPrompt(sess),
+		StopSequences:  a.config.StopSequences,
+		ResponseFormat: a.responseFormat(),
 	}
 }
 
@@ -510,6 +795,16 @@ func (a *Agent) buildCompactionRequest(sess *session.Session, prompt string) *ll
 	}
 }
 
+// defaultMaxTokens caps model responses when Config.MaxTokens is unset.
+const defaultMaxTokens = 4096
+
+func (a *Agent) maxTokens() int {
+	if a.config.MaxTokens > 0 {
+		return a.config.MaxTokens
+	}
+	return defaultMaxTokens
+}
+
 // getLastAssistantContent returns the content of the last assistant message
 func (a *Agent) getLastAssistantContent(sess *session.Session) string {
 	for i := len(sess.Messages) - 1; i >= 0; i-- {
@@ -520,31 +815,72 @@ func (a *Agent) getLastAssistantContent(sess *session.Session) string {
 	return ""
 }
 
-// cleanupIncompleteToolCalls removes assistant messages with tool calls that don't have corresponding tool results
-// This can happen when the user interrupts a tool execution
+// interruptedToolResultContent is the synthetic result recorded for tool
+// calls whose execution never finished.
+const interruptedToolResultContent = "tool execution was interrupted before completion"
+
+// cleanupIncompleteToolCalls records an error result for every tool call that
+// has none, e.g. when the user interrupted a tool execution, so the
+// conversation stays valid for the provider without losing what the model was
+// doing. A call without an ID cannot be answered; if such a call also has no
+// tool message after it, the assistant message is removed instead.
 func (a *Agent) cleanupIncompleteToolCalls(sess *session.Session) {
 	if len(sess.Messages) == 0 {
 		return
 	}
 
-	// Find the last assistant message with tool calls
 	for i := len(sess.Messages) - 1; i >= 0; i-- {
 		msg := sess.Messages[i]
+		if msg.Role != "assistant" || len(msg.ToolCalls) == 0 {
+			continue
+		}
 
-		if msg.Role == "assistant" && len(msg.ToolCalls) > 0 {
-			// Check if there's a following tool message with results
-			hasResults := false
-			if i+1 < len(sess.Messages) && sess.Messages[i+1].Role == "tool" {
-				hasResults = true
+		hasResults := i+1 < len(sess.Messages) && sess.Messages[i+1].Role == "tool"
+		answered := make(map[string]bool)
+		if hasResults {
+			for _, result := range sess.Messages[i+1].ToolResults {
+				answered[result.ToolCallID] = true
 			}
+		}
 
-			if !hasResults {
-				// Remove this incomplete assistant message
-				logging.Warn("Removing incomplete tool call message (no results)")
-				sess.Messages = append(sess.Messages[:i], sess.Messages[i+1:]...)
-				// Continue checking in case there are more
+		var missing []session.ToolResult
+		unanswerable := false
+		for _, tc := range msg.ToolCalls {
+			if answered[tc.ID] {
 				continue
 			}
+			if tc.ID == "" {
+				unanswerable = true
+				continue
+			}
+			missing = append(missing, session.ToolResult{
+				ToolCallID: tc.ID,
+				Name:       tc.Name,
+				Content:    interruptedToolResultContent,
+				IsError:    true,
+				Metadata:   map[string]interface{}{"interrupted": true},
+			})
+		}
+
+		switch {
+		case unanswerable && !hasResults:
+			logging.Warn("Removing incomplete tool call message (tool call without ID)")
+			sess.Messages = append(sess.Messages[:i], sess.Messages[i+1:]...)
+		case len(missing) == 0:
+			// Every call has a result.
+		case hasResults:
+			logging.Warn("Recording %d interrupted tool call(s) as errors", len(missing))
+			sess.Messages[i+1].ToolResults = append(sess.Messages[i+1].ToolResults, missing...)
+		default:
+			logging.Warn("Recording %d interrupted tool call(s) as errors", len(missing))
+			// Storage orders messages by timestamp, so keep the results right after the call.
+			results := session.Message{
+				ID:          uuid.New().String(),
+				Role:        "tool",
+				ToolResults: missing,
+				Timestamp:   msg.Timestamp.Add(time.Microsecond),
+			}
+			sess.Messages = append(sess.Messages[:i+1], append([]session.Message{results}, sess.Messages[i+1:]...)...)
 		}
 	}
 }
@@ -564,6 +900,28 @@ func (a *Agent) maybeCompactContext(ctx context.Context, sess *session.Session,
 	if usagePercent < cfg.TriggerPercent {
 		return llm.TokenUsage{}, false, nil
 	}
+	return a.compactContext(ctx, sess, cfg, step, true)
+}
+
+// Compact summarizes the session's conversation now, whatever its context
+// usage, as the automatic compaction does once the trigger is reached. It
+// reports false when there was nothing to summarize.
+func (a *Agent) Compact(ctx context.Context, sess *session.Session) (llm.TokenUsage, bool, error) {
+	if sess == nil {
+		return llm.TokenUsage{}, false, nil
+	}
+	cfg := a.resolveCompactionConfig()
+	if !cfg.Enabled {
+		cfg.Prompt = a.resolveCompactionPrompt()
+	}
+	return a.compactContext(ctx, sess, cfg, 0, false)
+}
+
+// compactContext replaces all but the latest messages with a summary. Within
+// a run (continueRun) a synthetic user message asks the model to carry on
+// when no user prompt is pending.
+func (a *Agent) compactContext(ctx context.Context, sess *session.Session, cfg compactionConfig, step int, continueRun bool) (llm.TokenUsage, bool, error) {
+	currentTokens := metadataFloat(sess.Metadata, metadataCurrentContextTokens)
 
 	// If the latest message is a user prompt awaiting the next response, keep it after compaction.
 	var pendingUser *session.Message
@@ -689,7 +1047,7 @@ func (a *Agent) maybeCompactContext(ctx context.Context, sess *session.Session,
 
 	if pendingUser != nil {
 		sess.AddMessage(*pendingUser)
-	} else {
+	} else if continueRun {
 		// Add a synthetic user message to prompt the agent to continue working.
 		// Without this, the LLM may interpret the compaction summary as a final response
 		// and return without tool calls, causing premature completion.
@@ -818,6 +1176,15 @@ func (a *Agent) resolveCompactionConfig() compactionConfig {
 		trigger = 100
 	}
 
+	return compactionConfig{
+		Enabled:        true,
+		ContextWindow:  contextWindow,
+		TriggerPercent: trigger,
+		Prompt:         a.resolveCompactionPrompt(),
+	}
+}
+
+func (a *Agent) resolveCompactionPrompt() string {
 	prompt := strings.TrimSpace(a.config.CompactionPrompt)
 	if envPrompt := strings.TrimSpace(os.Getenv(envCompactionPrompt)); envPrompt != "" {
 		prompt = envPrompt
@@ -825,13 +1192,7 @@ func (a *Agent) resolveCompactionConfig() compactionConfig {
 	if prompt == "" {
 		prompt = defaultCompactionPrompt
 	}
-
-	return compactionConfig{
-		Enabled:        true,
-		ContextWindow:  contextWindow,
-		TriggerPercent: trigger,
-		Prompt:         prompt,
-	}
+	return prompt
 }
 
 func (a *Agent) addTokenUsageMetadata(sess *session.Session, usage llm.TokenUsage) {
@@ -844,6 +1205,7 @@ func (a *Agent) addTokenUsageMetadata(sess *session.Session, usage llm.TokenUsag
 
 	// OutputTokens are new tokens generated in this step - accumulate them
 	metadataSetFloat(sess, metadataTotalOutputTokens, metadataFloat(sess.Metadata, metadataTotalOutputTokens)+float64(usage.OutputTokens))
+	metadataSetFloat(sess, metadataBilledInputTokens, metadataFloat(sess.Metadata, metadataBilledInputTokens)+float64(usage.InputTokens))
 
 	// InputTokens from API represent the FULL context size (system prompt + all history)
 	// NOT incremental tokens, so we should NOT accumulate them.
@@ -864,6 +1226,50 @@ func (a *Agent) addTokenUsageMetadata(sess *session.Session, usage llm.TokenUsag
 	}
 }
 
+// sessionUsageEvent snapshots the session's token metadata after step.
+func sessionUsageEvent(sess *session.Session, step llm.TokenUsage) *UsageEvent {
+	return &UsageEvent{
+		Step:              step,
+		TotalInputTokens:  int(metadataFloat(sess.Metadata, metadataTotalInputTokens)),
+		TotalOutputTokens: int(metadataFloat(sess.Metadata, metadataTotalOutputTokens)),
+		BilledInputTokens: int(metadataFloat(sess.Metadata, metadataBilledInputTokens)),
+		ContextTokens:     int(metadataFloat(sess.Metadata, metadataCurrentContextTokens)),
+		ToolCacheHits:     int(metadataFloat(sess.Metadata, metadataToolCacheHits)),
+	}
+}
+
+func servedByLabel(response *llm.ChatResponse) string {
+	if response.Model == "" {
+		return response.Provider
+	}
+	return response.Provider + "/" + response.Model
+}
+
+// addProviderUsageMetadata tallies tokens and steps per provider/model under
+// provider_usage when a fallback chain reports which provider served a step.
+func addProviderUsageMetadata(sess *session.Session, response *llm.ChatResponse) {
+	if sess == nil {
+		return
+	}
+	if sess.Metadata == nil {
+		sess.Metadata = map[string]interface{}{}
+	}
+	usage, _ := sess.Metadata[metadataProviderUsage].(map[string]interface{})
+	if usage == nil {
+		usage = map[string]interface{}{}
+	}
+	label := servedByLabel(response)
+	entry, _ := usage[label].(map[string]interface{})
+	if entry == nil {
+		entry = map[string]interface{}{}
+	}
+	entry["input_tokens"] = metadataFloat(entry, "input_tokens") + float64(response.Usage.InputTokens)
+	entry["output_tokens"] = metadataFloat(entry, "output_tokens") + float64(response.Usage.OutputTokens)
+	entry["steps"] = metadataFloat(entry, "steps") + 1
+	usage[label] = entry
+	sess.Metadata[metadataProviderUsage] = usage
+}
+
 func metadataFloat(metadata map[string]interface{}, key string) float64 {
 	if metadata == nil {
 		return 0
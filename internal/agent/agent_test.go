@@ -2,12 +2,17 @@ package agent
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/A2gent/brute/internal/llm"
 	"github.com/A2gent/brute/internal/session"
 	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
 )
 
 // MockLLM is a mock implementation of llm.Client
@@ -152,3 +157,380 @@ func TestMaybeCompactContext(t *testing.T) {
 		}
 	}
 }
+
+// countingLLM always returns the same response and counts calls.
+type countingLLM struct {
+	calls    int
+	response *llm.ChatResponse
+}
+
+func (c *countingLLM) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	c.calls++
+	return c.response, nil
+}
+
+func TestCompactOnDemand(t *testing.T) {
+	mockLLM := &MockLLM{Response: &llm.ChatResponse{Content: "Summary of the chat"}}
+	// No context window: automatic compaction is off, manual compaction still works.
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5}, mockLLM)
+	sess.AddUserMessage("Hello")
+	sess.AddAssistantMessage("Hi there", nil)
+	sess.AddUserMessage("Refactor the parser")
+	sess.AddAssistantMessage("Done", nil)
+
+	_, compacted, err := a.Compact(context.Background(), sess)
+	if err != nil || !compacted {
+		t.Fatalf("Compact = %v, %v", compacted, err)
+	}
+	if mockLLM.CapturedRequest == nil || !strings.Contains(mockLLM.CapturedRequest.Messages[0].Content, "Hi there") {
+		t.Fatalf("expected the conversation to be summarized, got %+v", mockLLM.CapturedRequest)
+	}
+	last := sess.Messages[len(sess.Messages)-1]
+	if last.Role == "user" {
+		t.Fatalf("manual compaction must not queue a continuation prompt, got %q", last.Content)
+	}
+	var summaries int
+	for _, msg := range sess.Messages {
+		if isCompactionMessage(msg) {
+			summaries++
+		}
+	}
+	if summaries != 1 {
+		t.Fatalf("expected one compaction summary, got %d", summaries)
+	}
+}
+
+func TestLoopStopsWhenQuestionIsPending(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	sm := session.NewManager(store)
+	toolManager := tools.NewManager(t.TempDir())
+	toolManager.RegisterQuestionTool(sm)
+
+	client := &countingLLM{response: &llm.ChatResponse{
+		ToolCalls: []llm.ToolCall{{
+			ID:    "call-1",
+			Name:  tools.QuestionToolName,
+			Input: `{"question":"Overwrite the file?","options":[{"label":"Yes","description":"Replace it"},{"label":"No","description":"Keep it"}]}`,
+		}},
+	}}
+	a := New(Config{MaxSteps: 5}, client, toolManager, sm)
+
+	sess, err := sm.Create("test-agent")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.AddUserMessage("Write the report")
+
+	if _, _, err := a.Run(context.Background(), sess, "Write the report"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if client.calls != 1 {
+		t.Fatalf("expected the run to stop after the question, got %d LLM calls", client.calls)
+	}
+	if sess.Status != session.StatusInputRequired {
+		t.Fatalf("expected status %s, got %s", session.StatusInputRequired, sess.Status)
+	}
+
+	question, err := sm.GetPendingQuestion(sess.ID)
+	if err != nil {
+		t.Fatalf("GetPendingQuestion: %v", err)
+	}
+	if question == nil || question.Question != "Overwrite the file?" {
+		t.Fatalf("unexpected pending question: %#v", question)
+	}
+}
+
+// blockingLLM waits for the request context to end.
+type blockingLLM struct {
+	started chan struct{}
+}
+
+func (b *blockingLLM) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestLoopPausesWhenCancelledDuringLLMCall(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	sm := session.NewManager(store)
+	client := &blockingLLM{started: make(chan struct{})}
+	a := New(Config{MaxSteps: 5}, client, tools.NewManager(t.TempDir()), sm)
+
+	sess, err := sm.Create("test-agent")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.AddUserMessage("Summarise the logs")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-client.started
+		cancel()
+	}()
+	if _, _, err := a.Run(ctx, sess, "Summarise the logs"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Run error = %v, want context.Canceled", err)
+	}
+
+	stored, err := sm.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Status != session.StatusPaused {
+		t.Fatalf("expected status %s, got %s", session.StatusPaused, stored.Status)
+	}
+	if len(stored.Messages) == 0 || stored.Messages[0].Content != "Summarise the logs" {
+		t.Fatalf("partial messages were not saved: %#v", stored.Messages)
+	}
+}
+
+// stallingLLM answers with first, then waits for the request context to end.
+type stallingLLM struct {
+	first *llm.ChatResponse
+	calls int
+}
+
+func (s *stallingLLM) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	s.calls++
+	if s.calls == 1 {
+		return s.first, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestRunStopsAtMaxDuration(t *testing.T) {
+	first := globCall("call-1", `{"pattern":"*.yaml"}`)
+	first.Content = "Looking for the config file."
+	first.Usage = llm.TokenUsage{InputTokens: 120, OutputTokens: 12}
+	a, sm, sess := newLoopTestAgent(t, Config{MaxSteps: 5, MaxDuration: 50 * time.Millisecond}, &stallingLLM{first: first})
+
+	content, usage, err := a.Run(context.Background(), sess, "")
+	if !errors.Is(err, ErrMaxDurationExceeded) {
+		t.Fatalf("Run error = %v, want ErrMaxDurationExceeded", err)
+	}
+	if content != first.Content || usage.InputTokens != 120 || usage.OutputTokens != 12 {
+		t.Fatalf("Run = %q, %+v; want the partial answer and usage", content, usage)
+	}
+
+	stored, err := sm.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if stored.Status != session.StatusPaused || stored.Metadata[MetadataTimedOut] != true {
+		t.Fatalf("status = %s, timed_out = %v", stored.Status, stored.Metadata[MetadataTimedOut])
+	}
+
+	// A caller's own deadline is not reported as a timed out run.
+	a, _, sess = newLoopTestAgent(t, Config{MaxSteps: 5}, &stallingLLM{first: first})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, _, err := a.Run(ctx, sess, ""); err == nil || errors.Is(err, ErrMaxDurationExceeded) {
+		t.Fatalf("Run error = %v, want an LLM error", err)
+	}
+}
+
+func TestCleanupIncompleteToolCallsSynthesizesResults(t *testing.T) {
+	a := &Agent{}
+
+	t.Run("dangling calls get error results", func(t *testing.T) {
+		sess := session.New("test-agent")
+		sess.AddUserMessage("List the files and read the README")
+		sess.AddAssistantMessage("I'll look around first.", []session.ToolCall{
+			{ID: "call-1", Name: "glob"},
+			{ID: "call-2", Name: "read"},
+		})
+
+		a.cleanupIncompleteToolCalls(sess)
+
+		if len(sess.Messages) != 3 {
+			t.Fatalf("expected 3 messages, got %d", len(sess.Messages))
+		}
+		if sess.Messages[1].Content != "I'll look around first." {
+			t.Fatalf("assistant message was not preserved: %#v", sess.Messages[1])
+		}
+		results := sess.Messages[2]
+		if results.Role != "tool" || len(results.ToolResults) != 2 {
+			t.Fatalf("expected a tool message with 2 results, got %#v", results)
+		}
+		for i, want := range []string{"call-1", "call-2"} {
+			r := results.ToolResults[i]
+			if r.ToolCallID != want || !r.IsError || r.Content != interruptedToolResultContent {
+				t.Fatalf("unexpected synthetic result %d: %#v", i, r)
+			}
+		}
+		if !results.Timestamp.After(sess.Messages[1].Timestamp) {
+			t.Fatal("synthetic results must sort after the tool calls")
+		}
+	})
+
+	t.Run("partial results are completed", func(t *testing.T) {
+		sess := session.New("test-agent")
+		sess.AddUserMessage("Run both")
+		sess.AddAssistantMessage("", []session.ToolCall{
+			{ID: "call-1", Name: "bash"},
+			{ID: "call-2", Name: "bash"},
+		})
+		sess.AddToolResult([]session.ToolResult{{ToolCallID: "call-1", Content: "ok"}})
+		sess.AddUserMessage("Continue")
+
+		a.cleanupIncompleteToolCalls(sess)
+
+		if len(sess.Messages) != 4 {
+			t.Fatalf("expected 4 messages, got %d", len(sess.Messages))
+		}
+		results := sess.Messages[2].ToolResults
+		if len(results) != 2 || results[0].Content != "ok" || results[1].ToolCallID != "call-2" || !results[1].IsError {
+			t.Fatalf("unexpected tool results: %#v", results)
+		}
+	})
+
+	t.Run("calls without IDs are removed", func(t *testing.T) {
+		sess := session.New("test-agent")
+		sess.AddUserMessage("Do it")
+		sess.AddAssistantMessage("", []session.ToolCall{{Name: "bash"}})
+
+		a.cleanupIncompleteToolCalls(sess)
+
+		if len(sess.Messages) != 1 || sess.Messages[0].Role != "user" {
+			t.Fatalf("expected only the user message to remain, got %#v", sess.Messages)
+		}
+	})
+}
+
+func TestLoopEmitsStepLimitReached(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	sm := session.NewManager(store)
+	client := &countingLLM{response: &llm.ChatResponse{
+		ToolCalls: []llm.ToolCall{{ID: "call-1", Name: "glob", Input: `{"pattern":"*.md"}`}},
+	}}
+	a := New(Config{MaxSteps: 2}, client, tools.NewManager(t.TempDir()), sm)
+
+	sess, err := sm.Create("test-agent")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.AddUserMessage("List the docs")
+
+	limitStep := 0
+	onEvent := func(event Event) {
+		if event.Type == EventStepLimitReached {
+			limitStep = event.Step
+		}
+	}
+	if _, _, err := a.RunWithEvents(context.Background(), sess, "List the docs", onEvent); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if client.calls != 2 || limitStep != 2 {
+		t.Fatalf("expected the run to stop at step 2, got %d LLM calls and limit event at step %d", client.calls, limitStep)
+	}
+}
+
+func TestBuildRequestUsesMaxTokensAndStopSequences(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{{Content: "done"}}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5}, client)
+	if got := a.buildRequest(sess).MaxTokens; got != defaultMaxTokens {
+		t.Fatalf("default max tokens = %d, want %d", got, defaultMaxTokens)
+	}
+
+	a, _, sess = newLoopTestAgent(t, Config{MaxSteps: 5, MaxTokens: 1024, StopSequences: []string{"END"}}, client)
+	request := a.buildRequest(sess)
+	if request.MaxTokens != 1024 || len(request.StopSequences) != 1 || request.StopSequences[0] != "END" {
+		t.Fatalf("unexpected request limits: max_tokens=%d stop=%q", request.MaxTokens, request.StopSequences)
+	}
+}
+
+func TestProviderUsageMetadata(t *testing.T) {
+	first := globCall("call-1", `{"pattern":"*.yaml"}`)
+	first.Provider, first.Model = "kimi", "kimi-k2"
+	first.Usage = llm.TokenUsage{InputTokens: 100, OutputTokens: 10}
+	second := &llm.ChatResponse{Content: "done", Provider: "anthropic", Model: "claude-sonnet-4-5", Usage: llm.TokenUsage{InputTokens: 150, OutputTokens: 5}}
+	client := &scriptedLLM{responses: []*llm.ChatResponse{first, second}}
+	a, sm, sess := newLoopTestAgent(t, Config{MaxSteps: 5}, client)
+
+	if _, _, err := a.Run(context.Background(), sess, ""); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	reloaded, err := sm.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	addProviderUsageMetadata(reloaded, second)
+
+	usage, _ := reloaded.Metadata[metadataProviderUsage].(map[string]interface{})
+	kimi, _ := usage["kimi/kimi-k2"].(map[string]interface{})
+	claude, _ := usage["anthropic/claude-sonnet-4-5"].(map[string]interface{})
+	if metadataFloat(kimi, "steps") != 1 || metadataFloat(kimi, "input_tokens") != 100 {
+		t.Fatalf("kimi usage = %v", kimi)
+	}
+	if metadataFloat(claude, "steps") != 2 || metadataFloat(claude, "output_tokens") != 10 {
+		t.Fatalf("anthropic usage = %v", claude)
+	}
+}
+
+func TestUsageEventsReportSessionTotals(t *testing.T) {
+	first := globCall("call-1", `{"pattern":"*.yaml"}`)
+	first.Usage = llm.TokenUsage{InputTokens: 100, OutputTokens: 10}
+	second := &llm.ChatResponse{Content: "done", Usage: llm.TokenUsage{InputTokens: 150, OutputTokens: 5}}
+	client := &scriptedLLM{responses: []*llm.ChatResponse{first, second}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5}, client)
+
+	var usage []*UsageEvent
+	_, _, err := a.RunWithEvents(context.Background(), sess, "", func(ev Event) {
+		if ev.Type == EventUsageUpdated {
+			usage = append(usage, ev.Usage)
+		}
+	})
+	if err != nil {
+		t.Fatalf("RunWithEvents: %v", err)
+	}
+	if len(usage) != 2 {
+		t.Fatalf("expected a usage event per step, got %d", len(usage))
+	}
+	last := usage[1]
+	if last.Step.InputTokens != 150 || last.TotalOutputTokens != 15 || last.BilledInputTokens != 250 || last.ContextTokens != 150 {
+		t.Fatalf("unexpected usage %+v", last)
+	}
+	if last.TotalInputTokens != 150 {
+		t.Fatalf("total input should count only new tokens, got %d", last.TotalInputTokens)
+	}
+}
+
+func TestBuildRequestAttachesToolImagesForVisionModels(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{{Content: "done"}}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5, Model: "claude-sonnet-4-5"}, client)
+	sess.AddAssistantMessage("", []session.ToolCall{{ID: "call-1", Name: "take_camera_photo", Input: json.RawMessage(`{}`)}})
+	sess.AddToolResult([]session.ToolResult{{
+		ToolCallID: "call-1",
+		Content:    `{"path":"/tmp/photo.jpg"}`,
+		Metadata: map[string]interface{}{
+			"image_inline": map[string]interface{}{"path": "/tmp/photo.jpg", "media_type": "image/jpeg"},
+		},
+	}})
+
+	messages := a.buildRequest(sess).Messages
+	toolMsg := messages[len(messages)-1]
+	if len(toolMsg.Parts) != 1 || toolMsg.Parts[0].Path != "/tmp/photo.jpg" {
+		t.Fatalf("expected the photo as an image part, got %+v", toolMsg.Parts)
+	}
+
+	a.config.Model = "deepseek-chat"
+	if parts := a.buildRequest(sess).Messages[len(messages)-1].Parts; len(parts) != 0 {
+		t.Fatalf("text-only model got image parts %+v", parts)
+	}
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+// Tool-call loop detection defaults. A step repeats the previous one when it
+// issues exactly the same tool calls with the same (normalized) inputs.
+const (
+	defaultRepeatNudgeThreshold        = 3
+	defaultRepeatFailureNudgeThreshold = 2
+	defaultRepeatStopThreshold         = 6
+)
+
+const loopNudgeMessage = "[loop detected] You have repeated this exact tool call %d times in a row. Repeating it will not give a different result; change your approach, use different arguments, or explain what is blocking you."
+
+const loopStopMessage = "Stopped: the same tool call was repeated %d times in a row without progress."
+
+type loopAction int
+
+const (
+	loopContinue loopAction = iota
+	loopNudge
+	loopStop
+)
+
+// loopDetector counts consecutive steps that issue identical tool calls.
+type loopDetector struct {
+	nudgeAfter        int
+	failureNudgeAfter int
+	stopAfter         int
+
+	lastSignature string
+	repeats       int
+	failures      int
+	nudged        bool
+}
+
+func newLoopDetector(cfg Config) *loopDetector {
+	d := &loopDetector{
+		nudgeAfter:        cfg.RepeatNudgeThreshold,
+		failureNudgeAfter: cfg.RepeatFailureNudgeThreshold,
+		stopAfter:         cfg.RepeatStopThreshold,
+	}
+	if d.nudgeAfter == 0 {
+		d.nudgeAfter = defaultRepeatNudgeThreshold
+	}
+	if d.failureNudgeAfter == 0 {
+		d.failureNudgeAfter = defaultRepeatFailureNudgeThreshold
+	}
+	if d.stopAfter == 0 {
+		d.stopAfter = defaultRepeatStopThreshold
+	}
+	return d
+}
+
+// observe records one step and reports whether the run should be nudged or
+// stopped. Negative thresholds disable the corresponding check.
+func (d *loopDetector) observe(calls []llm.ToolCall, results []llm.ToolResult) loopAction {
+	signature := toolCallsSignature(calls)
+	failed := allToolResultsFailed(results)
+
+	if signature != d.lastSignature {
+		d.lastSignature = signature
+		d.repeats = 1
+		d.failures = 0
+		d.nudged = false
+	} else {
+		d.repeats++
+	}
+	if failed {
+		d.failures++
+	} else {
+		d.failures = 0
+	}
+
+	if d.stopAfter > 0 && d.repeats >= d.stopAfter {
+		return loopStop
+	}
+	if d.nudged {
+		return loopContinue
+	}
+	if (d.nudgeAfter > 0 && d.repeats >= d.nudgeAfter) ||
+		(d.failureNudgeAfter > 0 && d.failures >= d.failureNudgeAfter) {
+		d.nudged = true
+		return loopNudge
+	}
+	return loopContinue
+}
+
+// toolCallsSignature hashes the tool names and normalized inputs of a step,
+// independent of call order.
+func toolCallsSignature(calls []llm.ToolCall) string {
+	parts := make([]string, 0, len(calls))
+	for _, call := range calls {
+		parts = append(parts, call.Name+"\x00"+normalizeToolInput(call.Input))
+	}
+	sort.Strings(parts)
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x01")))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeToolInput re-encodes JSON input so key order and whitespace do not
+// hide a repeated call.
+func normalizeToolInput(input string) string {
+	trimmed := strings.TrimSpace(input)
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(trimmed), &decoded); err != nil {
+		return trimmed
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return trimmed
+	}
+	return string(encoded)
+}
+
+func allToolResultsFailed(results []llm.ToolResult) bool {
+	if len(results) == 0 {
+		return false
+	}
+	for _, result := range results {
+		if !result.IsError {
+			return false
+		}
+	}
+	return true
+}
+
+// appendLoopNudge adds the nudge to the step's tool results so it reaches the
+// model without adding a message that some providers would reject.
+func appendLoopNudge(results []llm.ToolResult, repeats int) {
+	nudge := fmt.Sprintf(loopNudgeMessage, repeats)
+	for i := range results {
+		results[i].Content = strings.TrimRight(results[i].Content, "\n") + "\n\n" + nudge
+	}
+}
@@ -0,0 +1,132 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+// scriptedLLM returns its responses in order and repeats the last one.
+type scriptedLLM struct {
+	responses []*llm.ChatResponse
+	requests  []*llm.ChatRequest
+}
+
+func (s *scriptedLLM) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	s.requests = append(s.requests, request)
+	idx := len(s.requests) - 1
+	if idx >= len(s.responses) {
+		idx = len(s.responses) - 1
+	}
+	return s.responses[idx], nil
+}
+
+func globCall(id, input string) *llm.ChatResponse {
+	return &llm.ChatResponse{ToolCalls: []llm.ToolCall{{ID: id, Name: "glob", Input: input}}}
+}
+
+func newLoopTestAgent(t *testing.T, cfg Config, client llm.Client) (*Agent, *session.Manager, *session.Session) {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	sm := session.NewManager(store)
+	sess, err := sm.Create("test-agent")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.AddUserMessage("Find the config file")
+	return New(cfg, client, tools.NewManager(t.TempDir()), sm), sm, sess
+}
+
+func TestLoopDetectionNudgesThenStops(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		globCall("call-1", `{"pattern": "*.yaml"}`),
+		globCall("call-2", `{"pattern":"*.yaml"}`),
+		globCall("call-3", "{\n  \"pattern\": \"*.yaml\"\n}"),
+		globCall("call-4", `{"pattern":"*.yaml"}`),
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 20, RepeatNudgeThreshold: 3, RepeatStopThreshold: 4}, client)
+
+	output, _, err := a.Run(context.Background(), sess, "Find the config file")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(client.requests) != 4 {
+		t.Fatalf("expected the run to stop after 4 LLM calls, got %d", len(client.requests))
+	}
+	if !strings.Contains(output, "repeated 4 times") {
+		t.Fatalf("unexpected final output %q", output)
+	}
+	if sess.Status != session.StatusCompleted {
+		t.Fatalf("expected status completed, got %s", sess.Status)
+	}
+
+	// The third identical call carries the nudge into the fourth request.
+	var nudges int
+	for _, msg := range sess.Messages {
+		for _, result := range msg.ToolResults {
+			if strings.Contains(result.Content, "[loop detected]") {
+				nudges++
+			}
+		}
+	}
+	if nudges != 1 {
+		t.Fatalf("expected exactly one nudge, got %d", nudges)
+	}
+}
+
+func TestLoopDetectionResetsOnDifferentCall(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		globCall("call-1", `{"pattern":"*.yaml"}`),
+		globCall("call-2", `{"pattern":"*.yaml"}`),
+		globCall("call-3", `{"pattern":"*.json"}`),
+		globCall("call-4", `{"pattern":"*.json"}`),
+		{Content: "No config file found."},
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 20, RepeatNudgeThreshold: 3, RepeatStopThreshold: 3}, client)
+
+	output, _, err := a.Run(context.Background(), sess, "Find the config file")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != "No config file found." {
+		t.Fatalf("unexpected output %q", output)
+	}
+	for _, msg := range sess.Messages {
+		for _, result := range msg.ToolResults {
+			if strings.Contains(result.Content, "[loop detected]") {
+				t.Fatalf("unexpected nudge in %q", result.Content)
+			}
+		}
+	}
+}
+
+func TestLoopDetectorFailingCalls(t *testing.T) {
+	d := newLoopDetector(Config{RepeatNudgeThreshold: -1, RepeatStopThreshold: -1})
+	calls := []llm.ToolCall{{Name: "bash", Input: `{"command":"make"}`}}
+	failed := []llm.ToolResult{{IsError: true}}
+
+	if got := d.observe(calls, failed); got != loopContinue {
+		t.Fatalf("first failure: got %v", got)
+	}
+	if got := d.observe(calls, failed); got != loopNudge {
+		t.Fatalf("second identical failure should nudge, got %v", got)
+	}
+	if got := d.observe(calls, failed); got != loopContinue {
+		t.Fatalf("nudge should be sent once per streak, got %v", got)
+	}
+	for i := 0; i < 20; i++ {
+		if got := d.observe(calls, failed); got == loopStop {
+			t.Fatal("stop threshold is disabled")
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+)
+
+const (
+	envProjectContext    = "AAGENT_PROJECT_CONTEXT"
+	envProjectContextGit = "AAGENT_PROJECT_CONTEXT_GIT"
+)
+
+const (
+	// projectInstructionsMaxBytes caps how much of the instructions file is
+	// put into every request.
+	projectInstructionsMaxBytes = 16 * 1024
+	projectGitProbeTimeout      = 2 * time.Second
+
+	projectContextMetadataKey      = "project_context"
+	projectContextFileMetadataKey  = "project_context_file"
+	projectContextMTimeMetadataKey = "project_context_mtime"
+)
+
+// projectInstructionFiles are looked up in the work directory in order; the
+// first one found is used.
+var projectInstructionFiles = []string{
+	"AGENTS.md",
+	filepath.Join(".aagent", "instructions.md"),
+}
+
+// refreshProjectContext builds the project context preamble for sess and
+// caches it in the session metadata. The cached preamble is reused until the
+// instructions file changes, so the prompt stays stable across runs.
+func (a *Agent) refreshProjectContext(ctx context.Context, sess *session.Session) {
+	if sess == nil || a.toolManager == nil || !envEnabled(envProjectContext) {
+		return
+	}
+	workDir := strings.TrimSpace(a.toolManager.WorkDir())
+	if workDir == "" {
+		return
+	}
+	if abs, err := filepath.Abs(workDir); err == nil {
+		workDir = abs
+	}
+
+	file, modTime := findProjectInstructions(workDir)
+	mtime := ""
+	if file != "" {
+		mtime = modTime.UTC().Format(time.RFC3339Nano)
+	}
+	if sess.Metadata == nil {
+		sess.Metadata = make(map[string]interface{})
+	}
+	if cached, ok := sess.Metadata[projectContextMetadataKey].(string); ok && cached != "" {
+		cachedFile, _ := sess.Metadata[projectContextFileMetadataKey].(string)
+		cachedMTime, _ := sess.Metadata[projectContextMTimeMetadataKey].(string)
+		if cachedFile == file && cachedMTime == mtime {
+			return
+		}
+	}
+
+	sess.Metadata[projectContextMetadataKey] = buildProjectContext(ctx, workDir, file, envEnabled(envProjectContextGit))
+	sess.Metadata[projectContextFileMetadataKey] = file
+	sess.Metadata[projectContextMTimeMetadataKey] = mtime
+	logging.DebugContext(ctx, "Built project context for %s (instructions=%q)", workDir, file)
+}
+
+// systemPrompt returns the configured system prompt followed by the session's
+// project context, if any.
+func (a *Agent) systemPrompt(sess *session.Session) string {
+	if sess == nil {
+		return a.config.SystemPrompt
+	}
+	preamble, _ := sess.Metadata[projectContextMetadataKey].(string)
+	if preamble == "" {
+		return a.config.SystemPrompt
+	}
+	return strings.TrimSpace(a.config.SystemPrompt) + "\n\n" + preamble
+}
+
+func findProjectInstructions(workDir string) (string, time.Time) {
+	for _, name := range projectInstructionFiles {
+		path := filepath.Join(workDir, name)
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().IsRegular() {
+			return path, info.ModTime()
+		}
+	}
+	return "", time.Time{}
+}
+
+func buildProjectContext(ctx context.Context, workDir, instructionsFile string, probeGit bool) string {
+	var b strings.Builder
+	b.WriteString("## Project context\n")
+	fmt.Fprintf(&b, "- Working directory: %s\n", workDir)
+	fmt.Fprintf(&b, "- OS: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+	if probeGit {
+		if branch, changed, ok := probeGitState(ctx, workDir); ok {
+			fmt.Fprintf(&b, "- Git branch: %s (%d changed files)\n", branch, changed)
+		}
+	}
+
+	if instructionsFile != "" {
+		data, err := os.ReadFile(instructionsFile)
+		if err != nil {
+			logging.WarnContext(ctx, "Failed to read project instructions %s: %v", instructionsFile, err)
+		} else if content := strings.TrimSpace(string(data)); content != "" {
+			if len(content) > projectInstructionsMaxBytes {
+				content = content[:runeStartIndex(content, projectInstructionsMaxBytes)] +
+					fmt.Sprintf("\n[instructions truncated at %d of %d bytes]", projectInstructionsMaxBytes, len(data))
+			}
+			rel, relErr := filepath.Rel(workDir, instructionsFile)
+			if relErr != nil {
+				rel = instructionsFile
+			}
+			fmt.Fprintf(&b, "\n### Project instructions (%s)\n%s\n", rel, content)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// probeGitState returns the current branch and the number of changed files
+// in workDir. ok is false when workDir is not a git checkout or git is
+// unavailable.
+func probeGitState(ctx context.Context, workDir string) (branch string, changed int, ok bool) {
+	ctx, cancel := context.WithTimeout(ctx, projectGitProbeTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "git", "-C", workDir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", 0, false
+	}
+	branch = strings.TrimSpace(string(out))
+
+	out, err = exec.CommandContext(ctx, "git", "-C", workDir, "status", "--porcelain").Output()
+	if err != nil {
+		return branch, 0, true
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			changed++
+		}
+	}
+	return branch, changed, true
+}
+
+// envEnabled reports whether a feature switch is on; unset or unparsable
+// values count as on.
+func envEnabled(key string) bool {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err != nil || enabled
+}
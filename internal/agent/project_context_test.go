@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+func TestProjectContextIsAppendedAndCachedUntilInstructionsChange(t *testing.T) {
+	t.Setenv(envProjectContextGit, "false")
+	workDir := t.TempDir()
+	instructions := filepath.Join(workDir, "AGENTS.md")
+	if err := os.WriteFile(instructions, []byte("Always run go vet."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	modTime := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(instructions, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	client := &scriptedLLM{responses: []*llm.ChatResponse{{Content: "done"}}}
+	_, sm, sess := newLoopTestAgent(t, Config{}, client)
+	a := New(Config{SystemPrompt: "Base prompt.", MaxSteps: 5}, client, tools.NewManager(workDir), sm)
+
+	if _, _, err := a.Run(context.Background(), sess, "Check the project"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	prompt := client.requests[0].SystemPrompt
+	if !strings.HasPrefix(prompt, "Base prompt.\n\n## Project context") {
+		t.Fatalf("project context should follow the configured prompt, got %q", prompt)
+	}
+	for _, want := range []string{"Working directory: " + workDir, "OS: ", "(AGENTS.md)\nAlways run go vet."} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("prompt is missing %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Contains(prompt, "Git branch") {
+		t.Fatal("git probing should be disabled")
+	}
+
+	// Same mtime: the cached preamble is kept.
+	if err := os.WriteFile(instructions, []byte("Never run go vet."), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(instructions, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshProjectContext(context.Background(), sess)
+	if !strings.Contains(a.systemPrompt(sess), "Always run go vet.") {
+		t.Fatal("preamble should be cached while the instructions file is unchanged")
+	}
+
+	modTime = modTime.Add(time.Minute)
+	if err := os.Chtimes(instructions, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	a.refreshProjectContext(context.Background(), sess)
+	if !strings.Contains(a.systemPrompt(sess), "Never run go vet.") {
+		t.Fatal("preamble should be rebuilt after the instructions file changed")
+	}
+}
+
+func TestProjectContextCanBeDisabled(t *testing.T) {
+	t.Setenv(envProjectContext, "false")
+	client := &scriptedLLM{responses: []*llm.ChatResponse{{Content: "done"}}}
+	_, sm, sess := newLoopTestAgent(t, Config{}, client)
+	a := New(Config{SystemPrompt: "Base prompt.", MaxSteps: 5}, client, tools.NewManager(t.TempDir()), sm)
+
+	if _, _, err := a.Run(context.Background(), sess, "Check the project"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if got := client.requests[0].SystemPrompt; got != "Base prompt." {
+		t.Fatalf("system prompt should be unchanged, got %q", got)
+	}
+}
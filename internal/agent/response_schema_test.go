@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/session"
+)
+
+const outdatedSchema = `{"type":"array","items":{"type":"object","properties":{"name":{"type":"string"}},"required":["name"]}}`
+
+func TestResponseSchemaRetriesOnceWithValidationError(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		{Content: "cobra and chi are outdated."},
+		{Content: "```json\n[{\"name\":\"cobra\"},{\"name\":\"chi\"}]\n```"},
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5, ResponseSchema: json.RawMessage(outdatedSchema)}, client)
+
+	output, _, err := a.Run(context.Background(), sess, "List outdated dependencies")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if output != `[{"name":"cobra"},{"name":"chi"}]` {
+		t.Fatalf("unexpected output %q", output)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("expected one retry, got %d requests", len(client.requests))
+	}
+	for _, request := range client.requests {
+		if request.ResponseFormat == nil || string(request.ResponseFormat.Schema) != outdatedSchema {
+			t.Fatalf("request is missing the response format: %+v", request.ResponseFormat)
+		}
+	}
+	retry := client.requests[1].Messages
+	last := retry[len(retry)-1]
+	if last.Role != "user" || !strings.Contains(last.Content, "not valid JSON") {
+		t.Fatalf("retry should carry the validation error, got %+v", last)
+	}
+	if sess.Status != session.StatusCompleted {
+		t.Fatalf("expected status completed, got %s", sess.Status)
+	}
+}
+
+func TestResponseSchemaFailsAfterRetry(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		{Content: `{"name":"cobra"}`},
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5, ResponseSchema: json.RawMessage(outdatedSchema)}, client)
+
+	_, _, err := a.Run(context.Background(), sess, "List outdated dependencies")
+	if !errors.Is(err, ErrResponseSchemaMismatch) || !strings.Contains(err.Error(), "expected array") {
+		t.Fatalf("expected a schema mismatch, got %v", err)
+	}
+	if len(client.requests) != 2 {
+		t.Fatalf("expected exactly one retry, got %d requests", len(client.requests))
+	}
+	if sess.Status != session.StatusFailed {
+		t.Fatalf("expected status failed, got %s", sess.Status)
+	}
+}
@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+const (
+	maxGeneratedTitleLength = 60
+	titlePromptInputLimit   = 1000
+)
+
+// GenerateTitle asks the model for a short session title summarizing the
+// first request. It uses a single small completion without tools.
+func GenerateTitle(ctx context.Context, client llm.Client, model string, prompt string) (string, error) {
+	prompt = strings.TrimSpace(prompt)
+	if prompt == "" {
+		return "", fmt.Errorf("prompt is empty")
+	}
+	if runes := []rune(prompt); len(runes) > titlePromptInputLimit {
+		prompt = string(runes[:titlePromptInputLimit])
+	}
+
+	resp, err := client.Chat(ctx, &llm.ChatRequest{
+		Model: model,
+		Messages: []llm.Message{
+			{
+				Role:    "user",
+				Content: "Summarize this request in 6 words or fewer. Reply with the title only, no quotes or punctuation at the end.\n\n" + prompt,
+			},
+		},
+		MaxTokens:   32,
+		Temperature: 0.2,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	title := sanitizeGeneratedTitle(resp.Content)
+	if title == "" {
+		return "", fmt.Errorf("model returned an empty title")
+	}
+	return title, nil
+}
+
+func sanitizeGeneratedTitle(raw string) string {
+	title := strings.TrimSpace(raw)
+	if idx := strings.IndexByte(title, '\n'); idx >= 0 {
+		title = strings.TrimSpace(title[:idx])
+	}
+	title = strings.TrimPrefix(title, "Title:")
+	title = strings.Trim(strings.TrimSpace(title), "\"'`*#. ")
+	title = strings.Join(strings.Fields(title), " ")
+	if runes := []rune(title); len(runes) > maxGeneratedTitleLength {
+		title = string(runes[:maxGeneratedTitleLength-3]) + "..."
+	}
+	return title
+}
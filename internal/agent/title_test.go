@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestGenerateTitle(t *testing.T) {
+	mock := &MockLLM{Response: &llm.ChatResponse{Content: "\"Fix flaky scheduler tests.\"\nextra"}}
+	title, err := GenerateTitle(context.Background(), mock, "small-model", "The scheduler tests fail randomly, please fix")
+	if err != nil {
+		t.Fatalf("GenerateTitle: %v", err)
+	}
+	if title != "Fix flaky scheduler tests" {
+		t.Fatalf("unexpected title %q", title)
+	}
+	if mock.CapturedRequest.Model != "small-model" || len(mock.CapturedRequest.Tools) != 0 {
+		t.Fatalf("unexpected request: %+v", mock.CapturedRequest)
+	}
+	if !strings.Contains(mock.CapturedRequest.Messages[0].Content, "scheduler tests fail") {
+		t.Fatal("prompt should include the user request")
+	}
+}
+
+func TestGenerateTitleRejectsEmpty(t *testing.T) {
+	mock := &MockLLM{Response: &llm.ChatResponse{Content: "  \"\"  "}}
+	if _, err := GenerateTitle(context.Background(), mock, "", "hello"); err == nil {
+		t.Fatal("expected error for empty title")
+	}
+}
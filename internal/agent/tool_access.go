@@ -0,0 +1,9 @@
+package agent
+
+import (
+	"github.com/A2gent/brute/internal/config"
+)
+
+func (a *Agent) toolAccess() config.ToolAccess {
+	return config.ToolAccess{Allowed: a.config.AllowedTools, Denied: a.config.DeniedTools}
+}
@@ -0,0 +1,49 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestAllowedToolsLimitOfferedAndRunTools(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "call-1", Name: "bash", Input: `{"command":"rm -rf build"}`}}},
+		{Content: "done"},
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{Name: "plan", MaxSteps: 5, AllowedTools: []string{"read", "glob", "grep"}}, client)
+
+	if _, _, err := a.Run(context.Background(), sess, "Plan the cleanup"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	for _, def := range client.requests[0].Tools {
+		switch def.Name {
+		case "bash", "write", "edit":
+			t.Fatalf("plan agent should not be offered %s", def.Name)
+		}
+	}
+	var offeredRead bool
+	for _, def := range client.requests[0].Tools {
+		if def.Name == "read" {
+			offeredRead = true
+		}
+	}
+	if !offeredRead {
+		t.Fatal("plan agent should be offered read")
+	}
+
+	var result *llm.ToolResult
+	for _, msg := range client.requests[1].Messages {
+		for i := range msg.ToolResults {
+			if msg.ToolResults[i].ToolCallID == "call-1" {
+				result = &msg.ToolResults[i]
+			}
+		}
+	}
+	if result == nil || !result.IsError || !strings.Contains(result.Content, "tool bash is not available to this agent") {
+		t.Fatalf("bash call should be refused, got %+v", result)
+	}
+}
@@ -0,0 +1,117 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+const (
+	toolInputPreviewMaxLen = 80
+	toolInputValueMaxLen   = 40
+	previewEllipsis        = "…"
+)
+
+func newToolCallEvent(tc llm.ToolCall) ToolCallEvent {
+	return ToolCallEvent{
+		ID:               tc.ID,
+		Name:             tc.Name,
+		Input:            tc.Input,
+		InputPreview:     ToolInputPreview(tc.Input),
+		ThoughtSignature: tc.ThoughtSignature,
+	}
+}
+
+// toolCallHooks turns per-call progress from the tool manager into events.
+// Calls run concurrently, so emission is serialized for onEvent.
+func toolCallHooks(step int, calls []llm.ToolCall, onEvent func(Event)) tools.ExecuteHooks {
+	if onEvent == nil {
+		return tools.ExecuteHooks{}
+	}
+	previews := make(map[string]string, len(calls))
+	for _, tc := range calls {
+		previews[tc.ID] = ToolInputPreview(tc.Input)
+	}
+
+	var mu sync.Mutex
+	return tools.ExecuteHooks{
+		OnStart: func(tc llm.ToolCall) {
+			mu.Lock()
+			defer mu.Unlock()
+			onEvent(Event{Type: EventToolCallStarted, Step: step, ToolCalls: []ToolCallEvent{newToolCallEvent(tc)}})
+		},
+		OnDone: func(tr llm.ToolResult, duration time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			onEvent(Event{Type: EventToolCallFinished, Step: step, ToolResult: &ToolResultEvent{
+				ToolCallID:   tr.ToolCallID,
+				Name:         tr.Name,
+				InputPreview: previews[tr.ToolCallID],
+				Content:      tr.Content,
+				IsError:      tr.IsError,
+				Duration:     duration,
+				OutputBytes:  tr.OutputBytes,
+			}})
+		},
+	}
+}
+
+// ToolInputPreview renders JSON tool input as "key=value, ..." with keys in
+// sorted order and long values shortened, for one-line progress displays.
+func ToolInputPreview(input string) string {
+	trimmed := strings.TrimSpace(input)
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(trimmed), &fields); err != nil {
+		return shortenPreview(strings.Join(strings.Fields(trimmed), " "), toolInputPreviewMaxLen)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		var value string
+		switch v := fields[key].(type) {
+		case string:
+			value = v
+		default:
+			encoded, _ := json.Marshal(v)
+			value = string(encoded)
+		}
+		value = strings.Join(strings.Fields(value), " ")
+		parts = append(parts, key+"="+shortenPreview(value, toolInputValueMaxLen))
+	}
+	return shortenPreview(strings.Join(parts, ", "), toolInputPreviewMaxLen)
+}
+
+func shortenPreview(s string, maxLen int) string {
+	if utf8.RuneCountInString(s) <= maxLen {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:maxLen-1]) + previewEllipsis
+}
+
+// FormatToolCallProgress renders a tool call as "grep(pattern=...)", followed
+// by the duration and outcome once the call has finished.
+func FormatToolCallProgress(name, inputPreview string, result *ToolResultEvent) string {
+	label := fmt.Sprintf("%s(%s)", name, inputPreview)
+	if result == nil {
+		return "running " + label + " " + previewEllipsis
+	}
+	status := "ok"
+	if result.IsError {
+		status = "failed"
+	}
+	return fmt.Sprintf("%s %.1fs %s", label, result.Duration.Seconds(), status)
+}
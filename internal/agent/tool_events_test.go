@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestRunEmitsPerCallToolEvents(t *testing.T) {
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		{ToolCalls: []llm.ToolCall{
+			{ID: "call-1", Name: "glob", Input: `{"pattern":"*.yaml"}`},
+			{ID: "call-2", Name: "missing_tool", Input: `{}`},
+		}},
+		{Content: "done"},
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5}, client)
+
+	var mu sync.Mutex
+	started := map[string]ToolCallEvent{}
+	finished := map[string]ToolResultEvent{}
+	_, _, err := a.RunWithEvents(context.Background(), sess, "Find the config file", func(ev Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch ev.Type {
+		case EventToolCallStarted:
+			if len(ev.ToolCalls) != 1 {
+				t.Errorf("started event should carry one call, got %d", len(ev.ToolCalls))
+				return
+			}
+			started[ev.ToolCalls[0].ID] = ev.ToolCalls[0]
+		case EventToolCallFinished:
+			if ev.ToolResult == nil {
+				t.Error("finished event without a result")
+				return
+			}
+			finished[ev.ToolResult.ToolCallID] = *ev.ToolResult
+		}
+	})
+	if err != nil {
+		t.Fatalf("RunWithEvents: %v", err)
+	}
+
+	if len(started) != 2 || len(finished) != 2 {
+		t.Fatalf("expected start and finish events for both calls, got %d/%d", len(started), len(finished))
+	}
+	if got := started["call-1"]; got.Name != "glob" || got.InputPreview != "pattern=*.yaml" {
+		t.Fatalf("unexpected started event %+v", got)
+	}
+	if got := finished["call-1"]; got.IsError || got.InputPreview != "pattern=*.yaml" {
+		t.Fatalf("unexpected finished event %+v", got)
+	}
+	if got := finished["call-2"]; !got.IsError {
+		t.Fatalf("unknown tool should finish with an error: %+v", got)
+	}
+}
+
+func TestToolInputPreview(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{`{"pattern":"TODO","path":"internal"}`, "path=internal, pattern=TODO"},
+		{`{"command":"go   test\n./..."}`, "command=go test ./..."},
+		{`{"limit":10,"recursive":true}`, "limit=10, recursive=true"},
+		{`not json`, "not json"},
+		{`{"content":"` + strings.Repeat("a", 100) + `"}`, "content=" + strings.Repeat("a", 39) + "…"},
+	}
+	for _, tt := range tests {
+		if got := ToolInputPreview(tt.input); got != tt.want {
+			t.Errorf("ToolInputPreview(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFormatToolCallProgress(t *testing.T) {
+	if got := FormatToolCallProgress("grep", "pattern=TODO", nil); got != "running grep(pattern=TODO) …" {
+		t.Fatalf("running format = %q", got)
+	}
+	done := &ToolResultEvent{Duration: 1200 * time.Millisecond}
+	if got := FormatToolCallProgress("grep", "pattern=TODO", done); got != "grep(pattern=TODO) 1.2s ok" {
+		t.Fatalf("finished format = %q", got)
+	}
+	done.IsError = true
+	if got := FormatToolCallProgress("grep", "pattern=TODO", done); !strings.HasSuffix(got, "failed") {
+		t.Fatalf("failed format = %q", got)
+	}
+}
@@ -0,0 +1,184 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+const (
+	envToolResultMaxBytes        = "AAGENT_TOOL_RESULT_MAX_BYTES"
+	envSummarizeLargeToolResults = "AAGENT_SUMMARIZE_LARGE_RESULTS"
+	envToolResultCache           = "AAGENT_TOOL_RESULT_CACHE"
+)
+
+const (
+	defaultMaxToolResultBytes = 32 * 1024
+	// toolResultSummaryInputBytes bounds how much output is sent to the
+	// summary call, keeping it cheap regardless of the original size.
+	toolResultSummaryInputBytes = 64 * 1024
+	toolResultSummaryMaxTokens  = 512
+	toolResultSummaryPrompt     = "Summarize the following tool output for an agent that cannot see it in full. Keep file paths, identifiers, error messages, counts and anything that looks like a result. Answer in at most 10 short lines."
+)
+
+// capToolResults shortens results larger than the configured limit to their
+// head and tail. The full output is stored so the model can page through it
+// with the read_tool_output tool.
+func (a *Agent) capToolResults(ctx context.Context, sess *session.Session, results []llm.ToolResult) llm.TokenUsage {
+	usage := llm.TokenUsage{}
+	maxBytes, summarize := a.resolveToolResultLimits()
+	if maxBytes <= 0 {
+		return usage
+	}
+
+	for i := range results {
+		content := results[i].Content
+		if len(content) <= maxBytes {
+			continue
+		}
+
+		outputID := ""
+		if a.sessionManager != nil && sess != nil {
+			id, err := a.sessionManager.SaveToolOutput(sess.ID, results[i].ToolCallID, results[i].Name, content)
+			if err != nil {
+				logging.WarnContext(ctx, "Failed to store full output of tool %s: %v", results[i].Name, err)
+			} else {
+				outputID = id
+			}
+		}
+
+		summary := ""
+		if summarize {
+			var summaryUsage llm.TokenUsage
+			summary, summaryUsage = a.summarizeToolResult(ctx, results[i].Name, content)
+			usage.InputTokens += summaryUsage.InputTokens
+			usage.OutputTokens += summaryUsage.OutputTokens
+		}
+
+		results[i].Content = truncateToolResult(content, maxBytes, outputID, summary)
+		logging.InfoContext(ctx, "Truncated %s result from %d to %d bytes (tool_output_id=%s)", results[i].Name, len(content), len(results[i].Content), outputID)
+	}
+	return usage
+}
+
+func (a *Agent) resolveToolResultLimits() (int, bool) {
+	maxBytes := a.config.MaxToolResultBytes
+	if envMax := strings.TrimSpace(os.Getenv(envToolResultMaxBytes)); envMax != "" {
+		if parsed, err := strconv.Atoi(envMax); err == nil {
+			maxBytes = parsed
+		}
+	}
+	if maxBytes == 0 {
+		maxBytes = defaultMaxToolResultBytes
+	}
+
+	summarize := a.config.SummarizeLargeResults
+	if envSummarize := strings.TrimSpace(os.Getenv(envSummarizeLargeToolResults)); envSummarize != "" {
+		if parsed, err := strconv.ParseBool(envSummarize); err == nil {
+			summarize = parsed
+		}
+	}
+	return maxBytes, summarize
+}
+
+// cachesToolResults reports whether runs keep a tool result cache, from
+// CacheToolResults or AAGENT_TOOL_RESULT_CACHE.
+func (a *Agent) cachesToolResults() bool {
+	if a.toolManager == nil {
+		return false
+	}
+	enabled := a.config.CacheToolResults
+	if envCache := strings.TrimSpace(os.Getenv(envToolResultCache)); envCache != "" {
+		if parsed, err := strconv.ParseBool(envCache); err == nil {
+			enabled = parsed
+		}
+	}
+	return enabled
+}
+
+// addToolCacheHitsMetadata adds the results answered from the tool result
+// cache to the session's tool_cache_hits.
+func addToolCacheHitsMetadata(sess *session.Session, results []llm.ToolResult) {
+	hits := 0
+	for _, result := range results {
+		if cached, _ := result.Metadata[tools.CachedMetadataKey].(bool); cached {
+			hits++
+		}
+	}
+	if hits > 0 {
+		metadataSetFloat(sess, metadataToolCacheHits, metadataFloat(sess.Metadata, metadataToolCacheHits)+float64(hits))
+	}
+}
+
+// truncateToolResult keeps the first and last part of content, split evenly
+// within maxBytes, around a marker that says how much was left out and where
+// the full output can be read.
+func truncateToolResult(content string, maxBytes int, outputID, summary string) string {
+	half := maxBytes / 2
+	head := content[:runeStartIndex(content, half)]
+	tail := content[runeStartIndex(content, len(content)-half):]
+	omitted := len(content) - len(head) - len(tail)
+
+	var sb strings.Builder
+	sb.WriteString(head)
+	sb.WriteString(fmt.Sprintf("\n\n[output truncated: %d of %d bytes omitted", omitted, len(content)))
+	if outputID != "" {
+		sb.WriteString(fmt.Sprintf("; full output stored as tool_output_id=%s, use read_tool_output with offset %d to read the omitted part", outputID, len(head)))
+	}
+	sb.WriteString("]\n")
+	if summary != "" {
+		sb.WriteString("[summary of the full output]\n")
+		sb.WriteString(summary)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+	sb.WriteString(tail)
+	return sb.String()
+}
+
+// runeStartIndex moves index n back to the start of a UTF-8 rune.
+func runeStartIndex(s string, n int) int {
+	if n >= len(s) {
+		return len(s)
+	}
+	if n < 0 {
+		return 0
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return n
+}
+
+// summarizeToolResult asks the model for a short summary of a large output.
+// Failures are logged and produce no summary; the truncated output is still
+// usable on its own.
+func (a *Agent) summarizeToolResult(ctx context.Context, toolName, content string) (string, llm.TokenUsage) {
+	input := content
+	if len(input) > toolResultSummaryInputBytes {
+		input = input[:runeStartIndex(input, toolResultSummaryInputBytes)]
+	}
+	request := &llm.ChatRequest{
+		Model:        a.config.Model,
+		SystemPrompt: toolResultSummaryPrompt,
+		Messages: []llm.Message{{
+			Role:    "user",
+			Content: fmt.Sprintf("Output of tool %s:\n\n%s", toolName, input),
+		}},
+		MaxTokens: toolResultSummaryMaxTokens,
+	}
+	response, err := a.llmClient.Chat(ctx, request)
+	if err != nil {
+		logging.WarnContext(ctx, "Failed to summarize %s result: %v", toolName, err)
+		return "", llm.TokenUsage{}
+	}
+	return strings.TrimSpace(response.Content), response.Usage
+}
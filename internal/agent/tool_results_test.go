@@ -0,0 +1,138 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+// bigOutputTool returns a fixed large output.
+type bigOutputTool struct {
+	output string
+}
+
+func (b *bigOutputTool) Name() string        { return "big_output" }
+func (b *bigOutputTool) Description() string { return "Returns a large output" }
+func (b *bigOutputTool) Schema() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+}
+func (b *bigOutputTool) Execute(ctx context.Context, params json.RawMessage) (*tools.Result, error) {
+	return &tools.Result{Success: true, Output: b.output}, nil
+}
+
+var toolOutputIDPattern = regexp.MustCompile(`tool_output_id=([0-9a-f-]+)`)
+
+func TestLargeToolResultIsTruncatedAndStored(t *testing.T) {
+	t.Setenv(envToolResultMaxBytes, "")
+	t.Setenv(envSummarizeLargeToolResults, "")
+
+	full := "HEAD-MARK\n" + strings.Repeat("é middle line\n", 2000) + "TAIL-MARK"
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "call-1", Name: "big_output", Input: `{}`}}},
+		{Content: "done"},
+	}}
+	a, sm, sess := newLoopTestAgent(t, Config{MaxSteps: 5, MaxToolResultBytes: 1024}, client)
+	a.toolManager.Register(&bigOutputTool{output: full})
+
+	if _, _, err := a.Run(context.Background(), sess, "Run it"); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	var content string
+	for _, msg := range sess.Messages {
+		for _, result := range msg.ToolResults {
+			if result.ToolCallID == "call-1" {
+				content = result.Content
+			}
+		}
+	}
+	if !strings.HasPrefix(content, "HEAD-MARK") || !strings.HasSuffix(content, "TAIL-MARK") {
+		t.Fatalf("truncated result should keep head and tail, got %q...", content[:40])
+	}
+	if len(content) > 1024+512 {
+		t.Fatalf("truncated result is %d bytes, want about 1024", len(content))
+	}
+	if !strings.Contains(content, "[output truncated:") {
+		t.Fatalf("missing truncation marker in %q", content)
+	}
+	match := toolOutputIDPattern.FindStringSubmatch(content)
+	if match == nil {
+		t.Fatalf("missing tool_output_id in %q", content)
+	}
+
+	stored, err := sm.GetToolOutput(match[1])
+	if err != nil {
+		t.Fatalf("GetToolOutput: %v", err)
+	}
+	if stored.Content != full || stored.SessionID != sess.ID || stored.ToolName != "big_output" {
+		t.Fatalf("unexpected stored output: session=%s tool=%s len=%d", stored.SessionID, stored.ToolName, len(stored.Content))
+	}
+}
+
+func TestLargeToolResultSummaryWhenEnabled(t *testing.T) {
+	t.Setenv(envToolResultMaxBytes, "1024")
+	t.Setenv(envSummarizeLargeToolResults, "true")
+
+	client := &scriptedLLM{responses: []*llm.ChatResponse{
+		{ToolCalls: []llm.ToolCall{{ID: "call-1", Name: "big_output", Input: `{}`}}},
+		{Content: "3 failing tests in pkg/foo", Usage: llm.TokenUsage{InputTokens: 100, OutputTokens: 10}},
+		{Content: "done"},
+	}}
+	a, _, sess := newLoopTestAgent(t, Config{MaxSteps: 5}, client)
+	a.toolManager.Register(&bigOutputTool{output: strings.Repeat("x", 10000)})
+
+	_, usage, err := a.Run(context.Background(), sess, "Run it")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if len(client.requests) != 3 {
+		t.Fatalf("expected a summary request between the two steps, got %d requests", len(client.requests))
+	}
+	summaryRequest := client.requests[1]
+	if len(summaryRequest.Tools) != 0 || summaryRequest.MaxTokens != toolResultSummaryMaxTokens {
+		t.Fatalf("summary request should be a small call without tools: %+v", summaryRequest)
+	}
+	if usage.OutputTokens < 10 {
+		t.Fatalf("summary usage should count toward the run, got %+v", usage)
+	}
+
+	final := client.requests[2]
+	var sawSummary bool
+	for _, msg := range final.Messages {
+		for _, result := range msg.ToolResults {
+			if strings.Contains(result.Content, "3 failing tests in pkg/foo") {
+				sawSummary = true
+			}
+		}
+	}
+	if !sawSummary {
+		t.Fatal("summary should be included in the truncated tool result")
+	}
+}
+
+func TestSmallToolResultIsUnchanged(t *testing.T) {
+	a := &Agent{config: Config{MaxToolResultBytes: 1024}}
+	t.Setenv(envToolResultMaxBytes, "")
+	results := []llm.ToolResult{{ToolCallID: "call-1", Name: "glob", Content: "a.go\nb.go"}}
+	a.capToolResults(context.Background(), nil, results)
+	if results[0].Content != "a.go\nb.go" {
+		t.Fatalf("small result changed: %q", results[0].Content)
+	}
+}
+
+func TestTruncateToolResultKeepsRuneBoundaries(t *testing.T) {
+	content := strings.Repeat("日本語", 100)
+	truncated := truncateToolResult(content, 64, "", "")
+	head, _, _ := strings.Cut(truncated, "\n\n[output truncated")
+	if !strings.HasPrefix(content, head) || len(head) > 32 {
+		t.Fatalf("unexpected head %q", head)
+	}
+	if strings.ContainsRune(truncated, '�') {
+		t.Fatal("truncation split a rune")
+	}
+}
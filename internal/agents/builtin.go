@@ -0,0 +1,119 @@
+package agents
+
+import (
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+// ReadOnlyTools can inspect the workspace and the web but not change
+// anything. The plan and explore agents are limited to them.
+var ReadOnlyTools = []string{
+	"read",
+	"glob",
+	"grep",
+	"find_files",
+	"filter",
+	"fetch_url",
+	"brave_search_query",
+	"exa_search",
+	tools.QuestionToolName,
+	"session_task_progress",
+	tools.ToolOutputToolName,
+}
+
+func builtInDefinitions() []*Definition {
+	return []*Definition{
+		{
+			Name:        "build",
+			Description: "Default agent with access to every tool",
+		},
+		{
+			Name:         "plan",
+			Description:  "Read-only agent that investigates and proposes a plan",
+			SystemPrompt: agent.DefaultSystemPrompt() + "\n\n" + planAgentPrompt,
+			MaxTokens:    2048,
+			AllowedTools: ReadOnlyTools,
+		},
+		{
+			Name:         "general",
+			Description:  "General-purpose agent for research and multi-step tasks",
+			SystemPrompt: generalAgentPrompt,
+		},
+		{
+			Name:         "explore",
+			Description:  "Fast read-only agent for codebase exploration",
+			SystemPrompt: exploreAgentPrompt,
+			MaxSteps:     15,
+			MaxTokens:    2048,
+			AllowedTools: ReadOnlyTools,
+		},
+		{
+			Name:         "developer",
+			Description:  "Code implementation and debugging",
+			SystemPrompt: developerAgentPrompt,
+		},
+		{
+			Name:         "tester",
+			Description:  "Code review and test writing",
+			SystemPrompt: testerAgentPrompt,
+		},
+		{
+			Name:         "docs",
+			Description:  "Documentation generation",
+			SystemPrompt: docsAgentPrompt,
+		},
+	}
+}
+
+const planAgentPrompt = `You are in planning mode. Investigate the codebase with the read-only tools available and do not modify any files.
+
+Finish with a concrete, step-by-step plan: the files to change, what to change in each, and how to verify the result.`
+
+const generalAgentPrompt = `You are a general-purpose sub-agent. Your task is to complete the specific task assigned to you and return a clear, concise result.
+
+Focus on:
+- Completing the assigned task thoroughly
+- Using tools efficiently
+- Returning useful results to the parent agent
+
+Be direct and efficient. Complete the task and summarize your findings.`
+
+const exploreAgentPrompt = `You are a fast exploration agent. Your task is to quickly find information in the codebase.
+
+Focus on:
+- Using glob to find files by pattern
+- Using grep to search file contents
+- Using read to examine specific files
+- Providing clear, organized summaries
+
+Do NOT modify any files. Be fast and thorough in your exploration.`
+
+const developerAgentPrompt = `You are a development agent. Your task is to implement code changes.
+
+Focus on:
+- Reading existing code to understand context
+- Making minimal, targeted changes
+- Following existing code style
+- Testing changes with bash commands when possible
+
+Implement the requested changes efficiently and verify they work.`
+
+const testerAgentPrompt = `You are a testing and review agent. Your task is to review code and write tests.
+
+Focus on:
+- Analyzing code for potential issues
+- Identifying edge cases
+- Writing comprehensive tests
+- Providing actionable feedback
+
+Be thorough but constructive in your review.`
+
+const docsAgentPrompt = `You are a documentation agent. Your task is to create or update documentation.
+
+Focus on:
+- Clear, concise explanations
+- Proper formatting (Markdown)
+- Code examples where helpful
+- Keeping docs up-to-date with code
+
+Write documentation that helps users understand and use the code effectively.`
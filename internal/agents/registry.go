@@ -0,0 +1,239 @@
+// Package agents holds the agent type registry: the built-in agent types plus
+// definitions loaded from YAML files.
+package agents
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/config"
+	"gopkg.in/yaml.v2"
+)
+
+// SourceBuiltIn marks definitions that ship with aagent.
+const SourceBuiltIn = "built-in"
+
+// Definition describes an agent type. Zero values mean "use the default":
+// no system prompt keeps the standard prompt, no model keeps the configured
+// model, and so on.
+type Definition struct {
+	Name          string   `yaml:"name"`
+	Description   string   `yaml:"description"`
+	SystemPrompt  string   `yaml:"system_prompt"`
+	Model         string   `yaml:"model"`
+	Temperature   *float64 `yaml:"temperature"`
+	MaxSteps      int      `yaml:"max_steps"`
+	MaxTokens     int      `yaml:"max_tokens"`
+	MaxRunMinutes int      `yaml:"max_run_minutes"`
+	AllowedTools  []string `yaml:"allowed_tools"`
+	DeniedTools   []string `yaml:"denied_tools"`
+
+	// Source is the file the definition was loaded from, or SourceBuiltIn.
+	Source string `yaml:"-"`
+}
+
+// Apply copies the definition's system prompt, step, response token and
+// duration limits and tool access into cfg. A system prompt already set on cfg is kept. Model and
+// temperature are left to the caller, which knows whether a per-session
+// choice should win.
+func (d *Definition) Apply(cfg *agent.Config) {
+	if cfg.SystemPrompt == "" {
+		cfg.SystemPrompt = d.SystemPrompt
+	}
+	if cfg.Description == "" {
+		cfg.Description = d.Description
+	}
+	if d.MaxSteps > 0 {
+		cfg.MaxSteps = d.MaxSteps
+	}
+	if d.MaxTokens > 0 {
+		cfg.MaxTokens = d.MaxTokens
+	}
+	if d.MaxRunMinutes > 0 {
+		cfg.MaxDuration = time.Duration(d.MaxRunMinutes) * time.Minute
+	}
+	cfg.AllowedTools = d.AllowedTools
+	cfg.DeniedTools = d.DeniedTools
+}
+
+// Registry resolves agent type names to definitions.
+type Registry struct {
+	defs      map[string]*Definition
+	overrides map[string]config.ToolAccess
+}
+
+// NewRegistry returns a registry holding only the built-in agent types.
+func NewRegistry() *Registry {
+	r := &Registry{defs: make(map[string]*Definition)}
+	for _, def := range builtInDefinitions() {
+		def.Source = SourceBuiltIn
+		r.Add(def)
+	}
+	return r
+}
+
+// UserDir returns the directory for user-wide agent definitions.
+func UserDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".config", "aagent", "agents")
+}
+
+// ProjectDir returns the directory for agent definitions of the project at
+// workDir.
+func ProjectDir(workDir string) string {
+	return filepath.Join(workDir, ".aagent", "agents")
+}
+
+// Load builds the registry from the built-in agent types, then the user
+// directory, then the project directory of workDir; later definitions
+// override earlier ones field by field. Tool access set under tools.agents in
+// cfg takes precedence over all of them. Files that cannot be parsed are
+// skipped and reported in the returned error, which comes with a usable
+// registry.
+func Load(cfg *config.Config, workDir string) (*Registry, error) {
+	r := NewRegistry()
+	if cfg != nil {
+		r.overrides = cfg.Tools.Agents
+	}
+
+	dirs := []string{UserDir()}
+	if strings.TrimSpace(workDir) != "" {
+		dirs = append(dirs, ProjectDir(workDir))
+	}
+	var errs []error
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if err := r.LoadDir(dir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return r, errors.Join(errs...)
+}
+
+// LoadDir reads every *.yaml and *.yml file in dir. A missing directory is
+// not an error.
+func (r *Registry) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read agents directory %s: %w", dir, err)
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		def, err := loadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if def.Name == "" {
+			def.Name = strings.TrimSuffix(entry.Name(), ext)
+		}
+		r.Add(def)
+	}
+	return errors.Join(errs...)
+}
+
+func loadFile(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read agent definition %s: %w", path, err)
+	}
+	var def Definition
+	if err := yaml.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse agent definition %s: %w", path, err)
+	}
+	def.Name = strings.TrimSpace(def.Name)
+	def.Source = path
+	return &def, nil
+}
+
+// Add registers def, merging it over any definition of the same name.
+func (r *Registry) Add(def *Definition) {
+	existing, ok := r.defs[def.Name]
+	if !ok {
+		copied := *def
+		r.defs[def.Name] = &copied
+		return
+	}
+	if def.Description != "" {
+		existing.Description = def.Description
+	}
+	if def.SystemPrompt != "" {
+		existing.SystemPrompt = def.SystemPrompt
+	}
+	if def.Model != "" {
+		existing.Model = def.Model
+	}
+	if def.Temperature != nil {
+		existing.Temperature = def.Temperature
+	}
+	if def.MaxSteps > 0 {
+		existing.MaxSteps = def.MaxSteps
+	}
+	if def.MaxTokens > 0 {
+		existing.MaxTokens = def.MaxTokens
+	}
+	if def.MaxRunMinutes > 0 {
+		existing.MaxRunMinutes = def.MaxRunMinutes
+	}
+	if def.AllowedTools != nil || def.DeniedTools != nil {
+		existing.AllowedTools = def.AllowedTools
+		existing.DeniedTools = def.DeniedTools
+	}
+	existing.Source = def.Source
+}
+
+// Has reports whether name is a known agent type.
+func (r *Registry) Has(name string) bool {
+	_, ok := r.defs[name]
+	return ok
+}
+
+// Resolve returns the definition of an agent type. Unknown names resolve to
+// an empty definition, which leaves every default in place. The result is a
+// copy and may be modified.
+func (r *Registry) Resolve(name string) *Definition {
+	def := &Definition{Name: name}
+	if known, ok := r.defs[name]; ok {
+		*def = *known
+	}
+	if access, ok := r.overrides[name]; ok {
+		def.AllowedTools = access.Allowed
+		def.DeniedTools = access.Denied
+	}
+	return def
+}
+
+// List returns all definitions sorted by name.
+func (r *Registry) List() []*Definition {
+	names := make([]string, 0, len(r.defs))
+	for name := range r.defs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	defs := make([]*Definition, 0, len(names))
+	for _, name := range names {
+		defs = append(defs, r.Resolve(name))
+	}
+	return defs
+}
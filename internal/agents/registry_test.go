@@ -0,0 +1,128 @@
+package agents
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/config"
+)
+
+func writeDefinition(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestBuiltInDefinitions(t *testing.T) {
+	r := NewRegistry()
+	for _, name := range []string{"build", "plan", "explore", "developer", "tester", "docs"} {
+		if !r.Has(name) {
+			t.Fatalf("missing built-in agent %s", name)
+		}
+	}
+	if def := r.Resolve("build"); len(def.AllowedTools) != 0 || len(def.DeniedTools) != 0 || def.SystemPrompt != "" {
+		t.Fatalf("build agent should keep every default, got %+v", def)
+	}
+	if def := r.Resolve("plan"); len(def.AllowedTools) == 0 || !strings.Contains(def.SystemPrompt, "planning mode") {
+		t.Fatalf("plan agent should be read-only with a planning prompt, got %+v", def)
+	}
+	if def := r.Resolve("unknown"); def.Name != "unknown" || def.SystemPrompt != "" {
+		t.Fatalf("unknown agents should resolve to an empty definition, got %+v", def)
+	}
+}
+
+func TestLoadMergesUserAndProjectDefinitions(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	workDir := t.TempDir()
+
+	writeDefinition(t, filepath.Join(home, ".config", "aagent", "agents"), "reviewer.yaml", `
+description: Reviews diffs
+system_prompt: You review code.
+model: small-model
+temperature: 0.2
+max_steps: 8
+allowed_tools: [read, grep]
+`)
+	writeDefinition(t, filepath.Join(home, ".config", "aagent", "agents"), "plan.yml", "model: planner-model\n")
+	writeDefinition(t, ProjectDir(workDir), "review.yaml", "name: reviewer\ndescription: Reviews this project\n")
+	writeDefinition(t, ProjectDir(workDir), "broken.yaml", "name: [oops\n")
+
+	r, err := Load(nil, workDir)
+	if err == nil || !strings.Contains(err.Error(), "broken.yaml") {
+		t.Fatalf("expected an error naming the broken file, got %v", err)
+	}
+
+	reviewer := r.Resolve("reviewer")
+	if reviewer.Description != "Reviews this project" || reviewer.SystemPrompt != "You review code." {
+		t.Fatalf("project definition should override the user one field by field, got %+v", reviewer)
+	}
+	if reviewer.Model != "small-model" || reviewer.Temperature == nil || *reviewer.Temperature != 0.2 || reviewer.MaxSteps != 8 {
+		t.Fatalf("unexpected reviewer settings %+v", reviewer)
+	}
+	if reviewer.Source != filepath.Join(ProjectDir(workDir), "review.yaml") {
+		t.Fatalf("unexpected source %q", reviewer.Source)
+	}
+
+	plan := r.Resolve("plan")
+	if plan.Model != "planner-model" || len(plan.AllowedTools) == 0 {
+		t.Fatalf("user file should only change the plan model, got %+v", plan)
+	}
+}
+
+func TestConfigToolAccessOverridesDefinitions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	cfg := &config.Config{Tools: config.ToolsConfig{Agents: map[string]config.ToolAccess{
+		"plan":  {Denied: []string{"bash"}},
+		"build": {Denied: []string{"record_audio_tool"}},
+	}}}
+	r, err := Load(cfg, "")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if def := r.Resolve("plan"); len(def.AllowedTools) != 0 || def.DeniedTools[0] != "bash" {
+		t.Fatalf("config should replace the plan tools, got %+v", def)
+	}
+	if def := r.Resolve("build"); def.DeniedTools[0] != "record_audio_tool" {
+		t.Fatalf("config should restrict build, got %+v", def)
+	}
+}
+
+func TestApplyKeepsExplicitSystemPrompt(t *testing.T) {
+	def := NewRegistry().Resolve("explore")
+
+	cfg := agent.Config{MaxSteps: 50, SystemPrompt: "composed"}
+	def.Apply(&cfg)
+	if cfg.SystemPrompt != "composed" {
+		t.Fatalf("explicit prompt was replaced: %q", cfg.SystemPrompt)
+	}
+	if cfg.MaxSteps != 15 || len(cfg.AllowedTools) == 0 {
+		t.Fatalf("explore limits were not applied: %+v", cfg)
+	}
+
+	cfg = agent.Config{}
+	def.Apply(&cfg)
+	if cfg.SystemPrompt != def.SystemPrompt {
+		t.Fatal("empty prompt should be filled from the definition")
+	}
+}
+
+func TestApplyMaxTokens(t *testing.T) {
+	cfg := agent.Config{MaxTokens: 8192}
+	NewRegistry().Resolve("build").Apply(&cfg)
+	if cfg.MaxTokens != 8192 {
+		t.Fatalf("build should keep the configured max_tokens, got %d", cfg.MaxTokens)
+	}
+	NewRegistry().Resolve("plan").Apply(&cfg)
+	if cfg.MaxTokens != 2048 {
+		t.Fatalf("plan should cap max_tokens at 2048, got %d", cfg.MaxTokens)
+	}
+}
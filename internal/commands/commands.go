@@ -1,6 +1,11 @@
 // Package commands provides slash command handling for the TUI
 package commands
 
+import (
+	"sort"
+	"strings"
+)
+
 // Command represents a slash command
 type Command struct {
 	Name        string
@@ -42,9 +47,25 @@ func NewRegistry() *Registry {
 				Description: "Select model for current provider",
 				Aliases:     []string{"m"},
 			},
+			{
+				Name:        "model",
+				Description: "Pick a model for this session (/model <name> pins, /model default unpins)",
+			},
+			{
+				Name:        "compact",
+				Description: "Summarize the conversation to free context",
+			},
+			{
+				Name:        "export",
+				Description: "Write the transcript as Markdown (/export [path])",
+			},
+			{
+				Name:        "skills",
+				Description: "Show the markdown skills loaded for this session",
+			},
 			{
 				Name:        "clear",
-				Description: "Clear current conversation",
+				Description: "Start a fresh session (the current one is kept)",
 				Aliases:     []string{"c"},
 			},
 			{
@@ -81,24 +102,68 @@ func (r *Registry) FindCommand(input string) *Command {
 	return nil
 }
 
-// FilterCommands returns commands matching the given prefix
-func (r *Registry) FilterCommands(prefix string) []Command {
-	if prefix == "" {
+// FilterCommands returns the commands matching what has been typed after
+// the slash, best match first: name or alias prefixes, then substrings, then
+// fuzzy matches whose letters appear in order. Arguments after the command
+// name are ignored.
+func (r *Registry) FilterCommands(input string) []Command {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
 		return r.commands
 	}
+	query := strings.ToLower(fields[0])
 
-	var matches []Command
+	type match struct {
+		cmd   Command
+		score int
+	}
+	var matches []match
 	for _, cmd := range r.commands {
-		if len(cmd.Name) >= len(prefix) && cmd.Name[:len(prefix)] == prefix {
-			matches = append(matches, cmd)
-			continue
-		}
+		best := matchScore(cmd.Name, query)
 		for _, alias := range cmd.Aliases {
-			if len(alias) >= len(prefix) && alias[:len(prefix)] == prefix {
-				matches = append(matches, cmd)
-				break
+			if score := matchScore(alias, query); score > best {
+				best = score
 			}
 		}
+		if best > 0 {
+			matches = append(matches, match{cmd, best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	result := make([]Command, len(matches))
+	for i, m := range matches {
+		result[i] = m.cmd
+	}
+	return result
+}
+
+// matchScore rates how well name matches query; 0 means no match.
+func matchScore(name, query string) int {
+	name = strings.ToLower(name)
+	switch {
+	case name == query:
+		return 4
+	case strings.HasPrefix(name, query):
+		return 3
+	case strings.Contains(name, query):
+		return 2
+	case isSubsequence(query, name):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// isSubsequence reports whether the letters of query appear in s in order.
+func isSubsequence(query, s string) bool {
+	rest := s
+	for _, r := range query {
+		i := strings.IndexRune(rest, r)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(string(r)):]
 	}
-	return matches
+	return true
 }
@@ -0,0 +1,32 @@
+package commands
+
+import "testing"
+
+func TestFilterCommandsRanksAndFuzzyMatches(t *testing.T) {
+	r := NewRegistry()
+
+	got := r.FilterCommands("mod")
+	if len(got) < 2 || (got[0].Name != "models" && got[0].Name != "model") {
+		t.Fatalf("expected model commands first, got %v", names(got))
+	}
+	if got := r.FilterCommands("cmpct"); len(got) != 1 || got[0].Name != "compact" {
+		t.Fatalf("expected a fuzzy match for compact, got %v", names(got))
+	}
+	if got := r.FilterCommands("export notes.md"); len(got) == 0 || got[0].Name != "export" {
+		t.Fatalf("expected arguments to be ignored, got %v", names(got))
+	}
+	if got := r.FilterCommands("zzz"); len(got) != 0 {
+		t.Fatalf("expected no matches, got %v", names(got))
+	}
+	if got := r.FilterCommands(""); len(got) != len(r.GetCommands()) {
+		t.Fatalf("expected every command for an empty filter, got %d", len(got))
+	}
+}
+
+func names(cmds []Command) []string {
+	out := make([]string, len(cmds))
+	for i, cmd := range cmds {
+		out[i] = cmd.Name
+	}
+	return out
+}
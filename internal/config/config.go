@@ -2,23 +2,272 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Config holds the application configuration
 type Config struct {
-	DefaultModel       string              `json:"default_model"`
-	ActiveProvider     string              `json:"active_provider"` // Provider reference: built-in provider or named fallback aggregate
-	MaxSteps           int                 `json:"max_steps"`
-	Temperature        float64             `json:"temperature"`
-	LLMRetries         int                 `json:"llm_retries"` // Number of retries per LLM provider on transient errors (default 3)
-	DataPath           string              `json:"data_path"`
-	WorkDir            string              `json:"work_dir"`
-	Providers          map[string]Provider `json:"providers"`
-	FallbackAggregates []FallbackAggregate `json:"fallback_aggregates,omitempty"`
-	Tools              ToolsConfig         `json:"tools"`
+	DefaultModel       string                `json:"default_model"`
+	ActiveProvider     string                `json:"active_provider"` // Provider reference: built-in provider or named fallback aggregate
+	MaxSteps           int                   `json:"max_steps"`
+	MaxRunMinutes      int                   `json:"max_run_minutes,omitempty"` // Wall-clock limit of one agent run; 0 means no limit
+	Temperature        float64               `json:"temperature"`
+	MaxTokens          int                   `json:"max_tokens,omitempty"`     // Cap on each model response (default 4096)
+	StopSequences      []string              `json:"stop_sequences,omitempty"` // Strings that end a model response
+	LLMRetries         int                   `json:"llm_retries"`              // Number of retries per LLM provider on transient errors (default 3)
+	DataPath           string                `json:"data_path"`
+	WorkDir            string                `json:"work_dir"`
+	Providers          map[string]Provider   `json:"providers"`
+	FallbackAggregates []FallbackAggregate   `json:"fallback_aggregates,omitempty"`
+	FallbackModels     []string              `json:"fallback_models,omitempty"` // "provider/model" entries tried in order when the active provider fails
+	Pricing            map[string]ModelPrice `json:"pricing,omitempty"`         // USD per million tokens by model name or prefix, for cost estimates
+	Tools              ToolsConfig           `json:"tools"`
+	Retention          RetentionConfig       `json:"retention,omitempty"`
+	Jobs               JobsConfig            `json:"jobs,omitempty"`
+	Storage            StorageConfig         `json:"storage,omitempty"`
+	Server             ServerConfig          `json:"server,omitempty"`
+	HTTP               HTTPConfig            `json:"http,omitempty"`
+	Tracing            TracingConfig         `json:"tracing,omitempty"`
+	Logging            LoggingConfig         `json:"logging,omitempty"`
+	LLM                LLMConfig             `json:"llm,omitempty"`
+	Prompt             PromptConfig          `json:"prompt,omitempty"`
+	A2A                A2AConfig             `json:"a2a,omitempty"`
+	Scheduler          SchedulerConfig       `json:"scheduler,omitempty"`
+	// Env sets environment-driven settings that have no key of their own,
+	// such as ELEVENLABS_VOICE_ID; variables set in the process win.
+	Env map[string]string `json:"env,omitempty"`
+
+	// Files lists the config files Load read, in the order applied.
+	Files []string `json:"-"`
+	// UnknownKeys lists keys of those files that no setting takes, as
+	// "<file>: <key path>".
+	UnknownKeys []string `json:"-"`
+}
+
+// SchedulerConfig controls the recurring job scheduler of `aagent serve` and
+// the TUI. The --no-scheduler and --grace-period flags override it.
+type SchedulerConfig struct {
+	Disabled           bool `json:"disabled,omitempty"`             // only run jobs on demand
+	GracePeriodSeconds int  `json:"grace_period_seconds,omitempty"` // wait for in-flight runs on shutdown (default 30)
+}
+
+// A2AConfig sets the identity advertised in the A2A agent card. Empty fields
+// fall back to the AAGENT_NAME setting, built-in defaults and the request host.
+type A2AConfig struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version,omitempty"`
+	PublicURL   string `json:"public_url,omitempty"` // external base URL, e.g. "https://agent.example.com" behind a reverse proxy
+}
+
+// PromptConfig controls the project context (AGENTS.md or
+// .aagent/instructions.md, git state, OS and work directory) appended to the
+// system prompt.
+type PromptConfig struct {
+	DisableProjectContext bool `json:"disable_project_context,omitempty"`
+	DisableGitContext     bool `json:"disable_git_context,omitempty"` // skip git probing, e.g. for non-repo directories
+}
+
+// LoggingConfig controls the structured log file under DataPath/logs.
+type LoggingConfig struct {
+	Level      string `json:"level,omitempty"`       // debug (default), info, warn, error
+	Format     string `json:"format,omitempty"`      // json (default) or text
+	MaxSizeMB  int    `json:"max_size_mb,omitempty"` // rotate after this size (default 20)
+	MaxBackups int    `json:"max_backups,omitempty"` // rotated files kept (default 5)
+}
+
+// LLMConfig controls debug logging of provider traffic. With LogRequests set,
+// every request and response of a session is written, with secrets scrubbed,
+// to DataPath/llm-logs/<session>.jsonl.
+type LLMConfig struct {
+	LogRequests        bool `json:"log_requests,omitempty"`
+	LogMaxContentChars int  `json:"log_max_content_chars,omitempty"` // truncate message contents (0 keeps them whole)
+	LogMaxSizeMB       int  `json:"log_max_size_mb,omitempty"`       // rotate a session's log after this size (default 10)
+}
+
+// TracingConfig enables OpenTelemetry tracing over OTLP/HTTP. Tracing is off
+// when Endpoint is empty and no OTEL_EXPORTER_OTLP_* endpoint is set.
+type TracingConfig struct {
+	Endpoint    string  `json:"endpoint,omitempty"`     // e.g. "http://localhost:4318"
+	ServiceName string  `json:"service_name,omitempty"` // defaults to "aagent"
+	SampleRatio float64 `json:"sample_ratio,omitempty"` // 0 or 1 samples every run
+}
+
+// DefaultHTTPBindAddress keeps the API reachable from this machine only.
+const DefaultHTTPBindAddress = "127.0.0.1"
+
+// DefaultAllowedOrigins lists the browser origins accepted when none are configured.
+var DefaultAllowedOrigins = []string{
+	"http://localhost",
+	"http://localhost:*",
+	"http://127.0.0.1",
+	"http://127.0.0.1:*",
+}
+
+// HTTPConfig controls where the API listens and which browser origins may call it.
+// Use bind_address "0.0.0.0" and allowed_origins ["*"] for the old wide-open behavior.
+//
+// TLS is served with tls_cert_file/tls_key_file, or with certificates obtained
+// from Let's Encrypt for autocert_domain. unix_socket listens on a socket path
+// instead of TCP. trust_proxy_headers honors X-Forwarded-For, -Proto and -Host
+// set by a reverse proxy in front of the server.
+type HTTPConfig struct {
+	Port              int      `json:"port,omitempty"` // used when --port is not given; 0 picks a free port
+	BindAddress       string   `json:"bind_address,omitempty"`
+	AllowedOrigins    []string `json:"allowed_origins,omitempty"`
+	UnixSocket        string   `json:"unix_socket,omitempty"`
+	TLSCertFile       string   `json:"tls_cert_file,omitempty"`
+	TLSKeyFile        string   `json:"tls_key_file,omitempty"`
+	AutocertDomain    string   `json:"autocert_domain,omitempty"`
+	TrustProxyHeaders bool     `json:"trust_proxy_headers,omitempty"`
+}
+
+// TLSEnabled reports whether the server serves HTTPS.
+func (h HTTPConfig) TLSEnabled() bool {
+	return strings.TrimSpace(h.TLSCertFile) != "" || strings.TrimSpace(h.TLSKeyFile) != "" || strings.TrimSpace(h.AutocertDomain) != ""
+}
+
+// EffectiveBindAddress returns the configured bind address or the loopback default.
+func (h HTTPConfig) EffectiveBindAddress() string {
+	if addr := strings.TrimSpace(h.BindAddress); addr != "" {
+		return addr
+	}
+	return DefaultHTTPBindAddress
+}
+
+// EffectiveAllowedOrigins returns the configured origins or the localhost defaults.
+func (h HTTPConfig) EffectiveAllowedOrigins() []string {
+	var origins []string
+	for _, origin := range h.AllowedOrigins {
+		if origin = strings.TrimSpace(origin); origin != "" {
+			origins = append(origins, origin)
+		}
+	}
+	if len(origins) == 0 {
+		return append([]string(nil), DefaultAllowedOrigins...)
+	}
+	return origins
+}
+
+// AllowsAnyOrigin reports whether the effective origins include "*".
+func (h HTTPConfig) AllowsAnyOrigin() bool {
+	for _, origin := range h.EffectiveAllowedOrigins() {
+		if origin == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// ServerConfig controls access to the HTTP API.
+type ServerConfig struct {
+	APITokens []APIToken `json:"api_tokens,omitempty"`
+}
+
+// APIToken is a named bearer token accepted by the HTTP API. Naming tokens
+// lets a single client be revoked by removing its entry.
+type APIToken struct {
+	Name  string `json:"name"`
+	Token string `json:"token"`
+}
+
+// ParseAPITokens parses AAGENT_API_TOKENS: a comma-separated list of
+// "name:token" pairs. Entries without a name are named "env-<n>".
+func ParseAPITokens(raw string) []APIToken {
+	var tokens []APIToken
+	for i, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, found := strings.Cut(entry, ":")
+		if !found {
+			name, token = "", name
+		}
+		name, token = strings.TrimSpace(name), strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+		if name == "" {
+			name = fmt.Sprintf("env-%d", i+1)
+		}
+		tokens = append(tokens, APIToken{Name: name, Token: token})
+	}
+	return tokens
+}
+
+// StorageConfig selects the session/job database backend.
+type StorageConfig struct {
+	Driver string `json:"driver,omitempty"` // "sqlite" (default, stored in DataPath) or "postgres"
+	DSN    string `json:"dsn,omitempty"`    // Connection string for postgres
+}
+
+// DefaultJobOutputSummaryChars is how much of a job run's output its
+// execution record keeps unless JobsConfig sets otherwise.
+const DefaultJobOutputSummaryChars = 10000
+
+// JobsConfig controls how job runs are recorded.
+type JobsConfig struct {
+	OutputSummaryChars int `json:"output_summary_chars,omitempty"` // output kept on the execution record (default 10000); the full text goes to DataPath/executions
+}
+
+// SummaryChars returns OutputSummaryChars, or the default when it is unset.
+func (j JobsConfig) SummaryChars() int {
+	if j.OutputSummaryChars > 0 {
+		return j.OutputSummaryChars
+	}
+	return DefaultJobOutputSummaryChars
+}
+
+// RetentionConfig controls automatic cleanup of old job sessions.
+type RetentionConfig struct {
+	MaxSessionAge  string `json:"max_session_age,omitempty"`   // e.g. "30d" or "72h"; empty disables cleanup
+	KeepLastPerJob int    `json:"keep_last_per_job,omitempty"` // Newest sessions per job kept regardless of age
+}
+
+// MaxAge parses MaxSessionAge. A zero duration means retention is disabled.
+func (r RetentionConfig) MaxAge() (time.Duration, error) {
+	if strings.TrimSpace(r.MaxSessionAge) == "" {
+		return 0, nil
+	}
+	return ParseAge(r.MaxSessionAge)
+}
+
+// ParseAge parses a positive age such as "30d", "2w" or any time.ParseDuration value.
+func ParseAge(raw string) (time.Duration, error) {
+	value := strings.TrimSpace(strings.ToLower(raw))
+	if value == "" {
+		return 0, fmt.Errorf("age is empty")
+	}
+	var age time.Duration
+	if unit := value[len(value)-1]; unit == 'd' || unit == 'w' {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", raw)
+		}
+		age = time.Duration(n) * 24 * time.Hour
+		if unit == 'w' {
+			age *= 7
+		}
+	} else {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q", raw)
+		}
+		age = parsed
+	}
+	if age <= 0 {
+		return 0, fmt.Errorf("age must be positive: %q", raw)
+	}
+	return age, nil
 }
 
 // Provider configuration for LLM providers
@@ -187,6 +436,66 @@ type ToolsConfig struct {
 	Glob  string `json:"glob"`
 	Grep  string `json:"grep"`
 	Task  string `json:"task"`
+
+	// Results larger than MaxResultBytes (default 32768, negative disables)
+	// are truncated; the full output stays readable via read_tool_output.
+	MaxResultBytes        int  `json:"max_result_bytes,omitempty"`
+	SummarizeLargeResults bool `json:"summarize_large_results,omitempty"`
+	// MaxParallel caps concurrent tool calls within one step (default 4).
+	MaxParallel int `json:"max_parallel,omitempty"`
+	// CacheResults reuses the results of read-only tools within a run
+	// until a tool that may change files runs (off by default).
+	CacheResults bool `json:"cache_results,omitempty"`
+	// TimeoutSeconds limits each tool call (default 300, negative disables);
+	// ToolTimeouts overrides it by tool name, including for bash.
+	TimeoutSeconds int            `json:"timeout_seconds,omitempty"`
+	ToolTimeouts   map[string]int `json:"tool_timeouts,omitempty"`
+	// DisableRedaction keeps API keys and tokens found in tool results,
+	// which are otherwise replaced before the result is stored or sent.
+	DisableRedaction bool `json:"disable_redaction,omitempty"`
+	// SandboxRoots confines the file tools to the work directory and these
+	// directories; relative ones are taken from the work directory. Empty
+	// leaves them unconfined. bash is never confined.
+	SandboxRoots []string `json:"sandbox_roots,omitempty"`
+	// Camera sets the defaults of take_camera_photo.
+	Camera CameraConfig `json:"camera,omitempty"`
+	// Agents overrides the built-in tool access of an agent type by name
+	// (e.g. "plan", "explore", "job-runner").
+	Agents map[string]ToolAccess `json:"agents,omitempty"`
+}
+
+// Approvals returns the approval policy ("allow", "deny" or "ask") of each
+// tool that has one, keyed by tool name.
+func (t ToolsConfig) Approvals() map[string]string {
+	policy := make(map[string]string)
+	for name, value := range map[string]string{
+		"bash":  t.Bash,
+		"read":  t.Read,
+		"write": t.Write,
+		"edit":  t.Edit,
+		"glob":  t.Glob,
+		"grep":  t.Grep,
+		"task":  t.Task,
+	} {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			policy[name] = value
+		}
+	}
+	return policy
+}
+
+// CameraConfig sets where take_camera_photo saves photos and which camera
+// it uses when a call does not say.
+type CameraConfig struct {
+	OutputDir string `json:"output_dir,omitempty"` // default /tmp
+	Index     int    `json:"index,omitempty"`      // default 1
+}
+
+// ToolAccess restricts the tools an agent can see and call. An empty Allowed
+// list permits every tool not in Denied.
+type ToolAccess struct {
+	Allowed []string `json:"allowed,omitempty"`
+	Denied  []string `json:"denied,omitempty"`
 }
 
 // DefaultConfig returns the default configuration
@@ -222,6 +531,15 @@ func resolveDataPath() string {
 	return filepath.Join(homeDir, ".local", "share", "aagent")
 }
 
+// MaxRunDuration returns MaxRunMinutes as a duration, zero when runs are
+// not limited.
+func (c *Config) MaxRunDuration() time.Duration {
+	if c.MaxRunMinutes <= 0 {
+		return 0
+	}
+	return time.Duration(c.MaxRunMinutes) * time.Minute
+}
+
 // GetActiveProvider returns the configuration for the currently active provider
 func (c *Config) GetActiveProvider() *Provider {
 	if p, ok := c.Providers[c.ActiveProvider]; ok {
@@ -249,42 +567,49 @@ func GetConfigPath() string {
 	return filepath.Join(resolveDataPath(), "config.json")
 }
 
-// Load loads configuration from file and environment
+// Load loads the configuration: defaults, then the files of ConfigFiles,
+// then the environment variables of EnvVars. Keys of the files that no
+// setting takes are kept in UnknownKeys; values that cannot work, such as
+// an unknown approval policy, fail the load.
 func Load() (*Config, error) {
 	cfg := DefaultConfig()
 
-	// Override with environment variables
-	if provider := NormalizeProviderRef(os.Getenv("AAGENT_PROVIDER")); provider != "" {
-		cfg.ActiveProvider = provider
-	}
-	if model := os.Getenv("AAGENT_MODEL"); model != "" {
-		cfg.DefaultModel = model
-	}
-	if dataPath := os.Getenv("AAGENT_DATA_PATH"); dataPath != "" {
-		cfg.DataPath = dataPath
+	merged := map[string]interface{}{}
+	for _, path := range ConfigFiles() {
+		tree, err := ReadConfigFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range unknownKeys(reflect.TypeOf(Config{}), tree, "") {
+			cfg.UnknownKeys = append(cfg.UnknownKeys, path+": "+key)
+		}
+		mergeTrees(merged, tree)
+		cfg.Files = append(cfg.Files, path)
 	}
-	if retriesStr := os.Getenv("AAGENT_LLM_RETRIES"); retriesStr != "" {
-		if retries, err := strconv.Atoi(retriesStr); err == nil && retries >= 0 {
-			cfg.LLMRetries = retries
+	if len(merged) > 0 {
+		data, err := json.Marshal(merged)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("invalid config in %s: %w", strings.Join(cfg.Files, ", "), err)
 		}
 	}
 
-	// Try to load from config file. Prefer single-folder location next to DB
-	// while retaining legacy paths for backward compatibility.
-	homeDir, _ := os.UserHomeDir()
-	configPaths := []string{
-		GetConfigPath(),
-		".aagent/config.json",
-		filepath.Join(homeDir, ".config", "aagent", "config.json"),
+	// Environment variables override the files, so container deployments
+	// can always override them.
+	if err := applyEnv(cfg); err != nil {
+		return nil, fmt.Errorf("invalid environment: %w", err)
 	}
+	cfg.ActiveProvider = NormalizeProviderRef(cfg.ActiveProvider)
 
-	for _, path := range configPaths {
-		if data, err := os.ReadFile(path); err == nil {
-			if err := json.Unmarshal(data, cfg); err != nil {
-				return nil, err
-			}
-			break
-		}
+	// Environment tokens are added to, not replaced by, tokens from the config file.
+	if raw := os.Getenv("AAGENT_API_TOKENS"); raw != "" {
+		cfg.Server.APITokens = append(cfg.Server.APITokens, ParseAPITokens(raw)...)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
 
 	// Ensure data directory exists
@@ -295,6 +620,104 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// SecretValues returns the credentials known to the configuration: provider
+// API keys and OAuth tokens, server API tokens, and environment variables
+// named like *_API_KEY, *_TOKEN or *_SECRET.
+func (c *Config) SecretValues() []string {
+	var secrets []string
+	add := func(value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			secrets = append(secrets, value)
+		}
+	}
+	for _, provider := range c.Providers {
+		add(provider.APIKey)
+		if provider.OAuth != nil {
+			add(provider.OAuth.AccessToken)
+			add(provider.OAuth.RefreshToken)
+		}
+	}
+	for _, token := range c.Server.APITokens {
+		add(token.Token)
+	}
+	for name, value := range c.Env {
+		if secretName(name) {
+			add(value)
+		}
+	}
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		if secretName(name) {
+			add(value)
+		}
+	}
+	return secrets
+}
+
+// secretName reports whether an environment variable named name holds a
+// credential, going by its suffix.
+func secretName(name string) bool {
+	name = strings.ToUpper(name)
+	return strings.HasSuffix(name, "_API_KEY") || strings.HasSuffix(name, "_TOKEN") || strings.HasSuffix(name, "_SECRET")
+}
+
+// Masked returns a copy of c for display with its credentials masked:
+// provider keys and OAuth tokens, server API tokens, the storage DSN
+// password and env entries named like secrets keep only their last four
+// characters.
+func (c *Config) Masked() *Config {
+	masked := *c
+	masked.Providers = make(map[string]Provider, len(c.Providers))
+	for name, provider := range c.Providers {
+		provider.APIKey = MaskSecret(provider.APIKey)
+		if provider.OAuth != nil {
+			oauth := *provider.OAuth
+			oauth.AccessToken = MaskSecret(oauth.AccessToken)
+			oauth.RefreshToken = MaskSecret(oauth.RefreshToken)
+			provider.OAuth = &oauth
+		}
+		masked.Providers[name] = provider
+	}
+	masked.Server.APITokens = make([]APIToken, len(c.Server.APITokens))
+	for i, token := range c.Server.APITokens {
+		masked.Server.APITokens[i] = APIToken{Name: token.Name, Token: MaskSecret(token.Token)}
+	}
+	masked.Storage.DSN = maskDSN(c.Storage.DSN)
+	if c.Env != nil {
+		masked.Env = make(map[string]string, len(c.Env))
+		for name, value := range c.Env {
+			if secretName(name) {
+				value = MaskSecret(value)
+			}
+			masked.Env[name] = value
+		}
+	}
+	return &masked
+}
+
+// MaskSecret hides value but for its last four characters, or whole when it
+// is too short for those to give it away.
+func MaskSecret(value string) string {
+	if value == "" {
+		return ""
+	}
+	if len(value) < 12 {
+		return "••••"
+	}
+	return "••••" + value[len(value)-4:]
+}
+
+// dsnPassword matches the password of a key=value connection string.
+var dsnPassword = regexp.MustCompile(`(?i)(password\s*=\s*)('[^']*'|\S+)`)
+
+// maskDSN hides the password of a URL or key=value connection string.
+func maskDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}xxxxx")
+}
+
 // Save saves configuration to file
 func (c *Config) Save(path string) error {
 	dir := filepath.Dir(path)
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	cases := map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"72h": 72 * time.Hour,
+	}
+	for raw, want := range cases {
+		got, err := ParseAge(raw)
+		if err != nil || got != want {
+			t.Fatalf("ParseAge(%q) = %v, %v; want %v", raw, got, err, want)
+		}
+	}
+	for _, raw := range []string{"", "0d", "-1d", "abc", "d"} {
+		if _, err := ParseAge(raw); err == nil {
+			t.Fatalf("ParseAge(%q) should fail", raw)
+		}
+	}
+}
+
+func TestParseAPITokens(t *testing.T) {
+	got := ParseAPITokens(" web:abc123 , bare-token,empty:, phone:xyz")
+	want := []APIToken{
+		{Name: "web", Token: "abc123"},
+		{Name: "env-2", Token: "bare-token"},
+		{Name: "phone", Token: "xyz"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("ParseAPITokens returned %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("token %d = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHTTPConfigDefaults(t *testing.T) {
+	var h HTTPConfig
+	if got := h.EffectiveBindAddress(); got != DefaultHTTPBindAddress {
+		t.Fatalf("default bind address = %q", got)
+	}
+	if h.AllowsAnyOrigin() || len(h.EffectiveAllowedOrigins()) != len(DefaultAllowedOrigins) {
+		t.Fatalf("default origins = %v", h.EffectiveAllowedOrigins())
+	}
+
+	open := HTTPConfig{BindAddress: "0.0.0.0", AllowedOrigins: []string{" * "}}
+	if open.EffectiveBindAddress() != "0.0.0.0" || !open.AllowsAnyOrigin() {
+		t.Fatalf("explicit wide-open config not honoured: %+v", open)
+	}
+}
+
+func TestFallbackModelNodes(t *testing.T) {
+	cfg := &Config{
+		Providers: map[string]Provider{
+			string(ProviderAnthropic): {Model: "claude-sonnet-4-5"},
+		},
+		FallbackModels: []string{"kimi/kimi-k2", "anthropic", "Kimi/kimi-k2", "google/gemini-2.5-pro", "nope/x", "fallback_chain"},
+	}
+
+	nodes, err := cfg.FallbackModelNodes(ProviderGoogle, "gemini-2.5-pro")
+	want := []FallbackChainNode{
+		{Provider: "kimi", Model: "kimi-k2"},
+		{Provider: "anthropic", Model: "claude-sonnet-4-5"},
+	}
+	if len(nodes) != len(want) {
+		t.Fatalf("nodes = %+v, want %+v", nodes, want)
+	}
+	for i := range want {
+		if nodes[i] != want[i] {
+			t.Fatalf("node %d = %+v, want %+v", i, nodes[i], want[i])
+		}
+	}
+	if err == nil || !strings.Contains(err.Error(), `"nope/x"`) || !strings.Contains(err.Error(), `"fallback_chain"`) {
+		t.Fatalf("expected errors for the invalid entries, got %v", err)
+	}
+}
+
+func TestPriceFor(t *testing.T) {
+	cfg := &Config{Pricing: map[string]ModelPrice{
+		"claude-sonnet-4":   {InputPerMillion: 3, OutputPerMillion: 15},
+		"claude-sonnet-4-5": {InputPerMillion: 4, OutputPerMillion: 20},
+		"kimi-k2":           {InputPerMillion: 0.6, OutputPerMillion: 2.5},
+	}}
+
+	price, ok := cfg.PriceFor("claude-sonnet-4-5-20250929")
+	if !ok || price.InputPerMillion != 4 {
+		t.Fatalf("expected the longest prefix to win, got %+v (ok=%v)", price, ok)
+	}
+	if price, ok := cfg.PriceFor("Kimi-K2"); !ok || price.OutputPerMillion != 2.5 {
+		t.Fatalf("expected a case-insensitive exact match, got %+v (ok=%v)", price, ok)
+	}
+	if _, ok := cfg.PriceFor("gpt-4o"); ok {
+		t.Fatal("expected no price for an unlisted model")
+	}
+	if cost := price.Cost(1_000_000, 100_000); cost != 6 {
+		t.Fatalf("Cost = %v, want 6", cost)
+	}
+}
+
+func TestLoadMergesFilesAndEnvironment(t *testing.T) {
+	home := t.TempDir()
+	project := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("AAGENT_DATA_PATH", filepath.Join(home, "data"))
+	t.Setenv("AAGENT_CONFIG_PATH", "")
+	t.Chdir(project)
+
+	writeFile := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(home, ".config", "aagent", "config.yaml"), `
+default_model: user-model
+providers:
+  anthropic:
+    api_key: sk-ant-user-key-0001
+    model: claude-user
+tools:
+  timeout_seconds: 60
+  timout: 5
+http:
+  port: 8080
+`)
+	writeFile(filepath.Join(project, ".aagent", "config.yaml"), `
+providers:
+  anthropic:
+    model: claude-project
+tools:
+  sandbox_roots: [docs]
+`)
+	t.Setenv("AAGENT_HTTP_PORT", "9090")
+	t.Setenv("AAGENT_MODEL", "env-model")
+	t.Setenv("AAGENT_TOOLS_CAMERA_INDEX", "2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(cfg.Files) != 2 || !strings.HasPrefix(cfg.Files[1], ProjectConfigDir) {
+		t.Fatalf("files = %v, want the user and project config", cfg.Files)
+	}
+	anthropic := cfg.Providers["anthropic"]
+	if anthropic.APIKey != "sk-ant-user-key-0001" || anthropic.Model != "claude-project" {
+		t.Errorf("anthropic = %+v, want the user key with the project model", anthropic)
+	}
+	if cfg.Tools.TimeoutSeconds != 60 || len(cfg.Tools.SandboxRoots) != 1 || cfg.Tools.Camera.Index != 2 {
+		t.Errorf("tools = %+v", cfg.Tools)
+	}
+	if cfg.HTTP.Port != 9090 || cfg.DefaultModel != "env-model" {
+		t.Errorf("environment did not override the files: port %d, model %q", cfg.HTTP.Port, cfg.DefaultModel)
+	}
+	if len(cfg.UnknownKeys) != 1 || !strings.HasSuffix(cfg.UnknownKeys[0], ": tools.timout") {
+		t.Errorf("unknown keys = %v", cfg.UnknownKeys)
+	}
+
+	t.Setenv("AAGENT_TOOLS_MAX_PARALLEL", "many")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "AAGENT_TOOLS_MAX_PARALLEL") {
+		t.Fatalf("expected an error for the invalid variable, got %v", err)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("default config invalid: %v", err)
+	}
+	cfg.Tools.Bash = "sometimes"
+	cfg.HTTP.Port = 70000
+	cfg.Retention.MaxSessionAge = "soon"
+	cfg.Env = map[string]string{"NOT VALID": "x"}
+	err := cfg.Validate()
+	for _, key := range []string{"tools.bash", "http.port", "retention.max_session_age", "env.NOT VALID"} {
+		if err == nil || !strings.Contains(err.Error(), key+":") {
+			t.Errorf("expected an error for %s, got %v", key, err)
+		}
+	}
+}
+
+func TestMasked(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Providers["openai"] = Provider{APIKey: "sk-proj-abcdefghijkl1234", OAuth: &OAuthConfig{AccessToken: "short"}}
+	cfg.Server.APITokens = []APIToken{{Name: "web", Token: "token-value-5678"}}
+	cfg.Storage.DSN = "postgres://aagent:hunter22@db/aagent"
+	cfg.Env = map[string]string{"ELEVENLABS_API_KEY": "el-secret-value-9999", "ELEVENLABS_VOICE_ID": "voice"}
+
+	masked := cfg.Masked()
+	out := fmt.Sprintf("%+v %+v %+v", masked.Providers, *masked.Providers["openai"].OAuth, masked)
+	for _, secret := range []string{"sk-proj-abcdefghijkl", "short", "token-value", "hunter22", "el-secret-value"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("masked config shows %q", secret)
+		}
+	}
+	if masked.Providers["openai"].APIKey != "••••1234" || masked.Env["ELEVENLABS_VOICE_ID"] != "voice" {
+		t.Errorf("masked = %+v", masked)
+	}
+	if cfg.Providers["openai"].APIKey != "sk-proj-abcdefghijkl1234" || cfg.Providers["openai"].OAuth.AccessToken != "short" {
+		t.Error("Masked changed the original config")
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix starts the environment variable that overrides each config key:
+// the key's path, upper-cased and joined with underscores, follows it, e.g.
+// AAGENT_TOOLS_TIMEOUT_SECONDS for tools.timeout_seconds and
+// AAGENT_PROVIDERS_ANTHROPIC_API_KEY for providers.anthropic.api_key.
+// Lists take comma-separated values.
+const EnvPrefix = "AAGENT_"
+
+// envAliases are older names still read for some keys. The variable named
+// after the key wins when both are set.
+var envAliases = map[string]string{
+	"AAGENT_ACTIVE_PROVIDER":  "AAGENT_PROVIDER",
+	"AAGENT_DEFAULT_MODEL":    "AAGENT_MODEL",
+	"AAGENT_LOGGING_LEVEL":    "AAGENT_LOG_LEVEL",
+	"AAGENT_LOGGING_FORMAT":   "AAGENT_LOG_FORMAT",
+	"AAGENT_TRACING_ENDPOINT": "AAGENT_OTLP_ENDPOINT",
+}
+
+// EnvVar is the environment variable overriding one config key.
+type EnvVar struct {
+	Name  string // e.g. AAGENT_HTTP_PORT
+	Key   string // e.g. http.port
+	Alias string // older name, if any
+}
+
+// EnvVars lists the variables Load reads, in the order of the config keys.
+// Keys holding mappings or lists of objects, such as tools.tool_timeouts or
+// server.api_tokens, have none; providers get one per supported provider.
+func EnvVars() []EnvVar {
+	var vars []EnvVar
+	walkEnvFields(reflect.TypeOf(Config{}), nil, func(path []string, _ []int) {
+		name := envName(path)
+		vars = append(vars, EnvVar{Name: name, Key: strings.Join(path, "."), Alias: envAliases[name]})
+	})
+	return vars
+}
+
+// envName returns the variable overriding the key at path.
+func envName(path []string) string {
+	name := strings.ToUpper(strings.Join(path, "_"))
+	return EnvPrefix + strings.Map(func(r rune) rune {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// lookupEnv returns the value of name, or of its older alias.
+func lookupEnv(name string) (string, bool) {
+	if value, ok := os.LookupEnv(name); ok && strings.TrimSpace(value) != "" {
+		return value, true
+	}
+	if alias := envAliases[name]; alias != "" {
+		if value, ok := os.LookupEnv(alias); ok && strings.TrimSpace(value) != "" {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// walkEnvFields calls fn with the key path and field index of every field of
+// struct type t that a variable can set. The providers map is expanded to
+// the supported providers, whose fields are indexed within Provider.
+func walkEnvFields(t reflect.Type, path []string, fn func(path []string, index []int)) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+		fieldPath := append(append([]string(nil), path...), name)
+		switch {
+		case field.Type == reflect.TypeOf(map[string]Provider{}):
+			for _, def := range SupportedProviders() {
+				providerPath := append(append([]string(nil), fieldPath...), string(def.Type))
+				walkEnvFields(reflect.TypeOf(Provider{}), providerPath, func(path []string, index []int) {
+					// The name is the map key
+					if path[len(path)-1] != "name" {
+						fn(path, index)
+					}
+				})
+			}
+		case field.Type.Kind() == reflect.Struct:
+			walkEnvFields(field.Type, fieldPath, func(path []string, index []int) {
+				fn(path, append([]int{i}, index...))
+			})
+		case envSettable(field.Type):
+			fn(fieldPath, []int{i})
+		}
+	}
+}
+
+// envSettable reports whether a variable can hold a value of type t.
+func envSettable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// applyEnv sets the keys of cfg whose variable is set.
+func applyEnv(cfg *Config) error {
+	var errs []error
+	root := reflect.ValueOf(cfg).Elem()
+	walkEnvFields(root.Type(), nil, func(path []string, index []int) {
+		name := envName(path)
+		raw, ok := lookupEnv(name)
+		if !ok {
+			return
+		}
+		if path[0] == "providers" {
+			// Map values are not addressable: set a copy and store it back.
+			providerName := path[1]
+			provider := cfg.Providers[providerName]
+			if err := setEnvValue(reflect.ValueOf(&provider).Elem().FieldByIndex(index), raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			provider.Name = providerName
+			if cfg.Providers == nil {
+				cfg.Providers = make(map[string]Provider)
+			}
+			cfg.Providers[providerName] = provider
+			return
+		}
+		if err := setEnvValue(root.FieldByIndex(index), raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// setEnvValue parses raw into field.
+func setEnvValue(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(raw)
+	case reflect.Bool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", raw)
+		}
+		field.SetBool(value)
+	case reflect.Int, reflect.Int64:
+		value, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", raw)
+		}
+		field.SetInt(value)
+	case reflect.Float64:
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", raw)
+		}
+		field.SetFloat(value)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	}
+	return nil
+}
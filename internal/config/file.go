@@ -0,0 +1,220 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// configFileNames are the names a config file is looked for under, in order.
+// YAML and JSON files use the same keys.
+var configFileNames = []string{"config.yaml", "config.yml", "config.json"}
+
+// ProjectConfigDir holds the project config, relative to the current directory.
+const ProjectConfigDir = ".aagent"
+
+// UserConfigDir returns ~/.config/aagent, where the user config lives.
+func UserConfigDir() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".config", "aagent")
+}
+
+// ConfigFiles returns the config files Load reads, in the order they are
+// applied: the config aagent saves (GetConfigPath), the user config in
+// UserConfigDir and the project config in ProjectConfigDir. Later files
+// override the keys they set.
+func ConfigFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(path string) {
+		if path == "" {
+			return
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			abs = path
+		}
+		if seen[abs] {
+			return
+		}
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			seen[abs] = true
+			files = append(files, path)
+		}
+	}
+	add(GetConfigPath())
+	add(findConfigFile(UserConfigDir()))
+	add(findConfigFile(ProjectConfigDir))
+	return files
+}
+
+// findConfigFile returns the first config file found in dir, or "".
+func findConfigFile(dir string) string {
+	for _, name := range configFileNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ReadConfigFile parses a YAML or JSON config file into a tree of
+// map[string]interface{}, []interface{} and scalar values.
+func ReadConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tree interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tree = jsonTree(tree)
+	default:
+		if err := json.Unmarshal(data, &tree); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if tree == nil {
+		return map[string]interface{}{}, nil
+	}
+	object, ok := tree.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s: config must be a mapping of keys", path)
+	}
+	return object, nil
+}
+
+// WriteConfigFile writes tree to path as YAML or JSON, going by the
+// extension. The file is readable by the user only, as it may hold API keys.
+func WriteConfigFile(path string, tree map[string]interface{}) error {
+	var data []byte
+	var err error
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(tree)
+	default:
+		data, err = json.MarshalIndent(tree, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := os.Chmod(path, 0600); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(path, data, 0600)
+}
+
+// jsonTree turns the map[interface{}]interface{} values YAML decodes to into
+// map[string]interface{}, so that the tree can be encoded as JSON.
+func jsonTree(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			object[fmt.Sprint(key)] = jsonTree(item)
+		}
+		return object
+	case []interface{}:
+		for i, item := range v {
+			v[i] = jsonTree(item)
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// mergeTrees merges src into dst: mappings are merged key by key, any other
+// value of src replaces the one in dst.
+func mergeTrees(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcObject, srcIsObject := value.(map[string]interface{})
+		dstObject, dstIsObject := dst[key].(map[string]interface{})
+		if srcIsObject && dstIsObject {
+			mergeTrees(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// unknownKeys returns the dotted paths of the keys of tree that no field of
+// type t, matched by its JSON name, takes.
+func unknownKeys(t reflect.Type, tree interface{}, prefix string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var unknown []string
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := tree.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		for key, value := range object {
+			field, ok := fields[strings.ToLower(key)]
+			if !ok {
+				unknown = append(unknown, prefix+key)
+				continue
+			}
+			unknown = append(unknown, unknownKeys(field.Type, value, prefix+key+".")...)
+		}
+	case reflect.Map:
+		if object, ok := tree.(map[string]interface{}); ok {
+			for key, value := range object {
+				unknown = append(unknown, unknownKeys(t.Elem(), value, prefix+key+".")...)
+			}
+		}
+	case reflect.Slice:
+		if items, ok := tree.([]interface{}); ok {
+			for i, item := range items {
+				unknown = append(unknown, unknownKeys(t.Elem(), item, fmt.Sprintf("%s%d.", prefix, i))...)
+			}
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+// jsonFields maps the lower-cased JSON names of the fields of struct type t
+// to the fields, as encoding/json matches keys case-insensitively.
+func jsonFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if name := jsonName(field); name != "" {
+			fields[strings.ToLower(name)] = field
+		}
+	}
+	return fields
+}
+
+// jsonName returns the key of field in JSON, or "" for fields not encoded.
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
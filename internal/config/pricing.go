@@ -0,0 +1,36 @@
+package config
+
+import "strings"
+
+// ModelPrice is what a model costs in USD per million tokens.
+type ModelPrice struct {
+	InputPerMillion  float64 `json:"input_per_million"`
+	OutputPerMillion float64 `json:"output_per_million"`
+}
+
+// Cost estimates the USD cost of the given token counts.
+func (p ModelPrice) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.InputPerMillion + float64(outputTokens)*p.OutputPerMillion) / 1e6
+}
+
+// PriceFor returns the configured price of model. Keys match the model name
+// exactly or as a prefix, so "claude-sonnet-4" also prices dated releases;
+// the longest matching key wins.
+func (c *Config) PriceFor(model string) (ModelPrice, bool) {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if c == nil || name == "" {
+		return ModelPrice{}, false
+	}
+	var best ModelPrice
+	bestLen := -1
+	for key, price := range c.Pricing {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" || !strings.HasPrefix(name, key) {
+			continue
+		}
+		if len(key) > bestLen {
+			best, bestLen = price, len(key)
+		}
+	}
+	return best, bestLen >= 0
+}
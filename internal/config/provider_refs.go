@@ -1,6 +1,8 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"net/url"
 	"regexp"
 	"strings"
@@ -49,3 +51,41 @@ func NormalizeToken(raw string) string {
 	token = strings.Trim(token, "-")
 	return token
 }
+
+// FallbackModelNodes resolves FallbackModels into chain nodes. An entry is
+// "provider/model" or just "provider", which uses the provider's configured
+// model or else its default. Entries naming primary itself are skipped.
+// Invalid entries are reported in the error; the valid nodes are returned
+// regardless.
+func (c *Config) FallbackModelNodes(primary ProviderType, primaryModel string) ([]FallbackChainNode, error) {
+	var nodes []FallbackChainNode
+	var errs []error
+	seen := map[string]bool{string(primary) + "/" + strings.TrimSpace(primaryModel): true}
+	for _, entry := range c.FallbackModels {
+		ref, model, _ := strings.Cut(strings.TrimSpace(entry), "/")
+		providerType := ProviderType(NormalizeProviderRef(ref))
+		def := GetProviderDefinition(providerType)
+		if def == nil || providerType == ProviderFallback || providerType == ProviderAutoRouter {
+			errs = append(errs, fmt.Errorf("fallback model %q: unknown or aggregate provider %q", entry, ref))
+			continue
+		}
+		model = strings.TrimSpace(model)
+		if model == "" {
+			model = strings.TrimSpace(c.Providers[string(providerType)].Model)
+		}
+		if model == "" {
+			model = strings.TrimSpace(def.DefaultModel)
+		}
+		if model == "" {
+			errs = append(errs, fmt.Errorf("fallback model %q: no model given and %s has no default", entry, providerType))
+			continue
+		}
+		key := string(providerType) + "/" + model
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		nodes = append(nodes, FallbackChainNode{Provider: string(providerType), Model: model})
+	}
+	return nodes, errors.Join(errs...)
+}
@@ -0,0 +1,103 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Validate reports the settings of c that cannot work, all at once, by key.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(key string, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	if c.MaxSteps < 0 {
+		invalid("max_steps", "must not be negative, got %d", c.MaxSteps)
+	}
+	if c.LLMRetries < 0 {
+		invalid("llm_retries", "must not be negative, got %d", c.LLMRetries)
+	}
+	if c.Temperature < 0 {
+		invalid("temperature", "must not be negative, got %g", c.Temperature)
+	}
+
+	approvals := c.Tools.Approvals()
+	names := make([]string, 0, len(approvals))
+	for name := range approvals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch approvals[name] {
+		case "allow", "deny", "ask":
+		default:
+			invalid("tools."+name, "must be allow, deny or ask, got %q", approvals[name])
+		}
+	}
+	for i, root := range c.Tools.SandboxRoots {
+		if strings.TrimSpace(root) == "" {
+			invalid(fmt.Sprintf("tools.sandbox_roots.%d", i), "must not be empty")
+		}
+	}
+	if c.Tools.Camera.Index < 0 {
+		invalid("tools.camera.index", "must not be negative, got %d", c.Tools.Camera.Index)
+	}
+
+	if c.HTTP.Port < 0 || c.HTTP.Port > 65535 {
+		invalid("http.port", "must be between 0 and 65535, got %d", c.HTTP.Port)
+	}
+	if c.Scheduler.GracePeriodSeconds < 0 {
+		invalid("scheduler.grace_period_seconds", "must not be negative, got %d", c.Scheduler.GracePeriodSeconds)
+	}
+	if _, err := c.Retention.MaxAge(); err != nil {
+		invalid("retention.max_session_age", "%v", err)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(c.Storage.Driver)) {
+	case "", "sqlite", "postgres", "postgresql", "pgx":
+	default:
+		invalid("storage.driver", "must be sqlite or postgres, got %q", c.Storage.Driver)
+	}
+	if level := strings.ToLower(strings.TrimSpace(c.Logging.Level)); level != "" {
+		switch level {
+		case "debug", "info", "warn", "warning", "error":
+		default:
+			invalid("logging.level", "must be debug, info, warn or error, got %q", c.Logging.Level)
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(c.Logging.Format)) {
+	case "", "json", "text":
+	default:
+		invalid("logging.format", "must be json or text, got %q", c.Logging.Format)
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		invalid("tracing.sample_ratio", "must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+	}
+	if raw := strings.TrimSpace(c.A2A.PublicURL); raw != "" {
+		if u, err := url.Parse(raw); err != nil || u.Scheme == "" || u.Host == "" {
+			invalid("a2a.public_url", "must be an absolute URL, got %q", raw)
+		}
+	}
+	envNames := make([]string, 0, len(c.Env))
+	for name := range c.Env {
+		envNames = append(envNames, name)
+	}
+	sort.Strings(envNames)
+	for _, name := range envNames {
+		if !envNamePattern.MatchString(name) {
+			invalid("env."+name, "is not a valid environment variable name")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+}
+
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
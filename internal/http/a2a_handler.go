@@ -5,42 +5,49 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
+
+	skillsLoader "github.com/A2gent/brute/internal/skills"
 )
 
+const defaultAgentCardDescription = "AI agent for software engineering tasks with tool execution capabilities including file operations, shell commands, web search, browser automation, and integrations."
+
 // handleAgentCard returns the A2A agent card for discovery.
 // This endpoint is served at /.well-known/agent-card.json per A2A spec.
 func (s *Server) handleAgentCard(w http.ResponseWriter, r *http.Request) {
-	port := s.port
-	if port == 0 {
-		port = 8080
+	baseURL := s.requestBaseURL(r)
+	if publicURL := strings.TrimRight(strings.TrimSpace(s.config.A2A.PublicURL), "/"); publicURL != "" {
+		baseURL = publicURL
 	}
 
-	// Get host from request or use localhost as default
-	host := r.Host
-	if host == "" {
-		host = fmt.Sprintf("localhost:%d", port)
+	// Get version from config, environment or use default
+	version := strings.TrimSpace(s.config.A2A.Version)
+	if version == "" {
+		version = os.Getenv("AAGENT_VERSION")
 	}
-
-	scheme := "http"
-	if r.TLS != nil {
-		scheme = "https"
+	if version == "" {
+		version = "0.1.0"
 	}
 
-	baseURL := fmt.Sprintf("%s://%s", scheme, host)
+	settings, err := s.store.GetSettings()
+	if err != nil {
+		settings = map[string]string{}
+	}
 
-	// Get version from environment or use default
-	version := os.Getenv("AAGENT_VERSION")
-	if version == "" {
-		version = "0.1.0"
+	// Get agent name from config, settings or use default
+	agentName := strings.TrimSpace(s.config.A2A.Name)
+	if agentName == "" {
+		agentName = strings.TrimSpace(settings[agentNameSettingKey])
+	}
+	if agentName == "" {
+		agentName = "A2gent"
 	}
 
-	// Get agent name from settings or use default
-	agentName := "A2gent"
-	if settings, err := s.store.GetSettings(); err == nil {
-		if customName := strings.TrimSpace(settings[agentNameSettingKey]); customName != "" {
-			agentName = customName
-		}
+	description := strings.TrimSpace(s.config.A2A.Description)
+	if description == "" {
+		description = defaultAgentCardDescription
 	}
 
 	// Get tools from tool manager
@@ -59,8 +66,13 @@ func (s *Server) handleAgentCard(w http.ResponseWriter, r *http.Request) {
 
 	agentCard := AgentCard{
 		Name:        agentName,
-		Description: "AI agent for software engineering tasks with tool execution capabilities including file operations, shell commands, web search, browser automation, and integrations.",
+		Description: description,
 		SupportedInterfaces: []AgentInterface{
+			{
+				URL:             baseURL + "/a2a/jsonrpc",
+				ProtocolBinding: "JSONRPC",
+				ProtocolVersion: "0.3.0",
+			},
 			{
 				URL:             baseURL + "/a2a/messages/send",
 				ProtocolBinding: "HTTP+JSON",
@@ -70,95 +82,108 @@ func (s *Server) handleAgentCard(w http.ResponseWriter, r *http.Request) {
 		Version:          version,
 		DocumentationURL: "https://github.com/artjom/a2gent",
 		Capabilities: AgentCapabilities{
-			Streaming:         false,
+			Streaming:         true,
 			PushNotifications: false,
 			ExtendedAgentCard: false,
 		},
+		SecuritySchemes: map[string]SecurityScheme{
+			"bearer": {HTTPAuthSecurityScheme: &HTTPAuthSecurityScheme{
+				Description: "API token configured on the agent (server.api_tokens or AAGENT_API_TOKENS)",
+				Scheme:      "Bearer",
+			}},
+		},
+		Security:           []SecurityRequirement{{"bearer": {}}},
 		DefaultInputModes:  []string{"text/plain", "application/json"},
 		DefaultOutputModes: []string{"text/plain", "application/json"},
 		Tools:              agentTools,
-		Skills: []AgentSkill{
-			{
-				ID:          "code-generation",
-				Name:        "Code Generation",
-				Description: "Generate, modify, and refactor code across multiple programming languages with best practices.",
-				Tags:        []string{"coding", "programming", "development", "refactoring"},
-				Examples: []string{
-					"Create a Go function to parse JSON",
-					"Refactor this Python code to use async/await",
-					"Add error handling to this function",
-				},
-				InputModes:  []string{"text/plain", "application/json"},
-				OutputModes: []string{"text/plain", "application/json"},
-			},
-			{
-				ID:          "file-operations",
-				Name:        "File Operations",
-				Description: "Read, write, edit, and manage files in the local filesystem.",
-				Tags:        []string{"files", "filesystem", "io"},
-				Examples: []string{
-					"Read the contents of main.go",
-					"Create a new file called config.yaml",
-					"Update lines 10-20 in server.js",
-				},
-				InputModes:  []string{"text/plain", "application/json"},
-				OutputModes: []string{"text/plain", "application/json"},
-			},
-			{
-				ID:          "shell-execution",
-				Name:        "Shell Command Execution",
-				Description: "Execute shell commands safely in the project environment.",
-				Tags:        []string{"shell", "bash", "commands", "terminal"},
-				Examples: []string{
-					"Run npm install",
-					"Execute go test ./...",
-					"Check git status",
-				},
-				InputModes:  []string{"text/plain"},
-				OutputModes: []string{"text/plain"},
-			},
-			{
-				ID:          "web-search",
-				Name:        "Web Search",
-				Description: "Search the web for information using Brave Search or Exa.ai.",
-				Tags:        []string{"search", "web", "research", "information"},
-				Examples: []string{
-					"Search for Go 1.21 new features",
-					"Find documentation about OAuth2 flow",
-				},
-				InputModes:  []string{"text/plain"},
-				OutputModes: []string{"text/plain", "application/json"},
-			},
-			{
-				ID:          "browser-automation",
-				Name:        "Browser Automation",
-				Description: "Control Chrome browser to navigate websites, extract content, and interact with web pages.",
-				Tags:        []string{"browser", "chrome", "web", "automation", "scraping"},
-				Examples: []string{
-					"Navigate to example.com and get the page content",
-					"Click the login button and fill credentials",
-					"Take a screenshot of the dashboard",
-				},
-				InputModes:  []string{"text/plain", "application/json"},
-				OutputModes: []string{"text/plain", "image/png"},
-			},
-			{
-				ID:          "task-management",
-				Name:        "Task Management",
-				Description: "Create and manage tasks, track progress, and maintain todo lists.",
-				Tags:        []string{"tasks", "todos", "planning", "organization"},
-				Examples: []string{
-					"Create a task to implement user authentication",
-					"Mark the refactoring task as complete",
-					"List all pending tasks",
-				},
-				InputModes:  []string{"text/plain"},
-				OutputModes: []string{"text/plain", "application/json"},
-			},
-		},
+		Skills:             s.agentCardSkills(settings),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(agentCard)
 }
+
+// agentCardSkills advertises what this instance can do, drawn from the same
+// sources as the skills endpoints: enabled built-in tools (integration tools
+// are covered by their integration), enabled integrations and the markdown
+// skills of the configured skills folder.
+func (s *Server) agentCardSkills(settings map[string]string) []AgentSkill {
+	cardSkills := make([]AgentSkill, 0, 32)
+
+	integrationTools := map[string]string{}
+	if manager := s.toolManagerForSession(nil); manager != nil {
+		disabledTools := resolveDisabledToolNames(settings)
+		definitions := manager.GetDefinitions()
+		sort.Slice(definitions, func(i, j int) bool {
+			return strings.ToLower(definitions[i].Name) < strings.ToLower(definitions[j].Name)
+		})
+		for _, definition := range definitions {
+			if _, isIntegrationTool := integrationToolNameSet[definition.Name]; isIntegrationTool {
+				integrationTools[definition.Name] = strings.TrimSpace(definition.Description)
+				continue
+			}
+			if isToolDisabled(definition.Name, disabledTools) {
+				continue
+			}
+			cardSkills = append(cardSkills, AgentSkill{
+				ID:          "tool:" + definition.Name,
+				Name:        definition.Name,
+				Description: strings.TrimSpace(definition.Description),
+				Tags:        []string{"tool"},
+			})
+		}
+	}
+
+	if integrations, err := s.store.ListIntegrations(); err == nil {
+		sort.Slice(integrations, func(i, j int) bool {
+			return strings.ToLower(integrations[i].Provider+"|"+integrations[i].Name) < strings.ToLower(integrations[j].Provider+"|"+integrations[j].Name)
+		})
+		for _, integration := range integrations {
+			if integration == nil || !integration.Enabled {
+				continue
+			}
+			description := fmt.Sprintf("%s integration (%s).", integration.Provider, integration.Mode)
+			for _, toolName := range integrationToolsByProvider[integration.Provider] {
+				if toolDescription := integrationTools[toolName]; toolDescription != "" {
+					description += " " + toolDescription
+				}
+			}
+			cardSkills = append(cardSkills, AgentSkill{
+				ID:          "integration:" + integration.ID,
+				Name:        integration.Name,
+				Description: description,
+				Tags:        []string{"integration", integration.Provider},
+			})
+		}
+	}
+
+	for _, skill := range loadEnabledFolderSkills(settings) {
+		description := strings.TrimSpace(skill.Description)
+		if description == "" {
+			description = skill.Name
+		}
+		cardSkills = append(cardSkills, AgentSkill{
+			ID:          "skill:" + filepath.ToSlash(skill.RelativePath),
+			Name:        skill.Name,
+			Description: description,
+			Tags:        []string{"skill"},
+		})
+	}
+	return cardSkills
+}
+
+// loadEnabledFolderSkills returns the markdown skills of the configured skills
+// folder that are neither disabled in settings nor by their own strategy.
+func loadEnabledFolderSkills(settings map[string]string) []*skillsLoader.Skill {
+	resolvedFolder, err := skillsLoader.ResolveFolder(settings[skillsFolderSettingKey])
+	if err != nil {
+		return nil
+	}
+	disabled := resolveDisabledExternalMarkdownSkillPaths(settings, resolvedFolder)
+	enabled, _, err := skillsLoader.LoadActive(resolvedFolder, disabled, nil)
+	if err != nil {
+		return nil
+	}
+	return enabled
+}
@@ -5,8 +5,10 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 
+	"github.com/A2gent/brute/internal/a2atunnel"
 	"github.com/A2gent/brute/internal/config"
 	"github.com/A2gent/brute/internal/session"
 	"github.com/A2gent/brute/internal/speechcache"
@@ -204,3 +206,192 @@ func TestHandleAgentCard(t *testing.T) {
 		}
 	})
 }
+
+func TestA2ATaskStateMapping(t *testing.T) {
+	cases := map[session.Status]A2ATaskState{
+		session.StatusQueued:        A2ATaskStateSubmitted,
+		session.StatusRunning:       A2ATaskStateWorking,
+		session.StatusInputRequired: A2ATaskStateInputRequired,
+		session.StatusCompleted:     A2ATaskStateCompleted,
+		session.StatusFailed:        A2ATaskStateFailed,
+		session.StatusPaused:        A2ATaskStateCanceled,
+		session.Status("bogus"):     A2ATaskStateUnknown,
+	}
+	for status, want := range cases {
+		if got := a2aTaskState(status); got != want {
+			t.Errorf("a2aTaskState(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func newA2AQuestionSession(t *testing.T, server *Server) *session.Session {
+	t.Helper()
+	sess, err := server.sessionManager.Create("brute")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.Metadata = map[string]interface{}{a2atunnel.MetaA2AInbound: true}
+	sess.AddUserMessage("deploy the service")
+	if err := server.sessionManager.Save(sess); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	err = server.sessionManager.AskQuestion(sess.ID, &session.QuestionData{
+		Question: "Which environment?",
+		Options: []session.QuestionOption{
+			{Label: "staging"},
+			{Label: "production", Description: "live traffic"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to ask question: %v", err)
+	}
+	return sess
+}
+
+func TestA2ATasksGetReportsPendingQuestion(t *testing.T) {
+	server := newA2AJSONRPCTestServer(t)
+	sess := newA2AQuestionSession(t, server)
+
+	rec := postA2AJSONRPC(t, server, `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"`+sess.ID+`"}}`)
+	resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes())
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	var task A2ATask
+	if err := json.Unmarshal(resp.Result, &task); err != nil {
+		t.Fatalf("Failed to decode task: %v", err)
+	}
+	if task.Status.State != A2ATaskStateInputRequired {
+		t.Fatalf("Expected input-required state, got %q", task.Status.State)
+	}
+	if task.Status.Message == nil || len(task.Status.Message.Parts) == 0 {
+		t.Fatal("Expected the pending question as the status message")
+	}
+	text := task.Status.Message.Parts[0].Text
+	for _, want := range []string{"Which environment?", "- staging", "- production: live traffic"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected status message to contain %q, got %q", want, text)
+		}
+	}
+	if len(task.Artifacts) != 0 {
+		t.Errorf("Expected no artifacts while waiting for input, got %+v", task.Artifacts)
+	}
+}
+
+func TestA2AMessageSendWithTaskIDAnswersPendingQuestion(t *testing.T) {
+	server := newA2AJSONRPCTestServer(t)
+	sess := newA2AQuestionSession(t, server)
+
+	// No provider is configured, so the resumed run fails right after the
+	// answer has been recorded.
+	body := `{"jsonrpc":"2.0","id":2,"method":"message/send","params":{"message":{"kind":"message","role":"user","messageId":"m2","taskId":"` + sess.ID + `","parts":[{"kind":"text","text":"staging"}]}}}`
+	rec := postA2AJSONRPC(t, server, body)
+	resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes())
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	var task A2ATask
+	if err := json.Unmarshal(resp.Result, &task); err != nil {
+		t.Fatalf("Failed to decode task: %v", err)
+	}
+	if task.ID != sess.ID {
+		t.Fatalf("Expected the existing task to continue, got %q", task.ID)
+	}
+
+	reloaded, err := server.sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload session: %v", err)
+	}
+	if question := sessionPendingQuestion(reloaded); question != nil {
+		t.Fatalf("Expected pending question to be cleared, got %+v", question)
+	}
+	answered := false
+	for _, msg := range reloaded.Messages {
+		if msg.Role == "user" && msg.Content == "staging" {
+			answered = true
+		}
+	}
+	if !answered {
+		t.Fatal("Expected the answer to be recorded as a user message")
+	}
+
+	rec = postA2AJSONRPC(t, server, `{"jsonrpc":"2.0","id":3,"method":"message/send","params":{"message":{"role":"user","taskId":"missing","parts":[{"kind":"text","text":"hi"}]}}}`)
+	if resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes()); resp.Error == nil || resp.Error.Code != a2aErrTaskNotFound {
+		t.Fatalf("Expected unknown taskId to be rejected, got %+v", resp.Error)
+	}
+}
+
+func TestHandleAgentCardIdentityAndSkills(t *testing.T) {
+	tempDir := t.TempDir()
+	store, err := storage.NewSQLiteStore(tempDir)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	defer store.Close()
+
+	integration := &storage.Integration{
+		ID:       "int-telegram",
+		Provider: "telegram",
+		Name:     "Ops Telegram",
+		Mode:     "notify_only",
+		Enabled:  true,
+		Config:   map[string]string{},
+	}
+	if err := store.SaveIntegration(integration); err != nil {
+		t.Fatalf("Failed to save integration: %v", err)
+	}
+
+	cfg := &config.Config{A2A: config.A2AConfig{
+		Name:        "Build Bot",
+		Description: "Builds things",
+		Version:     "2.3.4",
+		PublicURL:   "https://agents.example.com/bot/",
+	}}
+	server := NewServer(cfg, nil, tools.NewManager(tempDir), session.NewManager(store), store, speechcache.New(0), 0)
+
+	fetchCard := func() AgentCard {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/.well-known/agent-card.json", nil)
+		req.Host = "127.0.0.1:8080"
+		rec := httptest.NewRecorder()
+		server.handleAgentCard(rec, req)
+		var card AgentCard
+		if err := json.Unmarshal(rec.Body.Bytes(), &card); err != nil {
+			t.Fatalf("Failed to parse agent card: %v", err)
+		}
+		return card
+	}
+	hasSkill := func(card AgentCard, id string) bool {
+		for _, skill := range card.Skills {
+			if skill.ID == id {
+				return true
+			}
+		}
+		return false
+	}
+
+	card := fetchCard()
+	if card.Name != "Build Bot" || card.Description != "Builds things" || card.Version != "2.3.4" {
+		t.Errorf("Expected configured identity, got name=%q description=%q version=%q", card.Name, card.Description, card.Version)
+	}
+	if got := card.SupportedInterfaces[0].URL; got != "https://agents.example.com/bot/a2a/jsonrpc" {
+		t.Errorf("Expected public_url to override the request host, got %q", got)
+	}
+	if !hasSkill(card, "integration:int-telegram") {
+		t.Errorf("Expected enabled integration to be advertised, got %+v", card.Skills)
+	}
+	if hasSkill(card, "tool:telegram_send_message") {
+		t.Error("Expected integration tools to be covered by their integration skill")
+	}
+	if !hasSkill(card, "tool:bash") {
+		t.Errorf("Expected built-in tools to be advertised, got %+v", card.Skills)
+	}
+
+	integration.Enabled = false
+	if err := store.SaveIntegration(integration); err != nil {
+		t.Fatalf("Failed to disable integration: %v", err)
+	}
+	if hasSkill(fetchCard(), "integration:int-telegram") {
+		t.Error("Expected disabled integration to be removed from the card")
+	}
+}
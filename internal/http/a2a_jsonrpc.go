@@ -0,0 +1,549 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/a2atunnel"
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/google/uuid"
+)
+
+// A2A JSON-RPC binding. Tasks map one-to-one onto inbound A2A sessions: the
+// task ID is the session ID and the context ID is the A2A conversation ID
+// (falling back to the session ID), so tasks/get works for any task created
+// by message/send or message/stream.
+
+const (
+	a2aJSONRPCVersion = "2.0"
+
+	a2aMethodMessageSend   = "message/send"
+	a2aMethodMessageStream = "message/stream"
+	a2aMethodTasksGet      = "tasks/get"
+
+	a2aErrParse          = -32700
+	a2aErrInvalidRequest = -32600
+	a2aErrMethodNotFound = -32601
+	a2aErrInvalidParams  = -32602
+	a2aErrInternal       = -32603
+	a2aErrTaskNotFound   = -32001
+)
+
+type a2aJSONRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type a2aJSONRPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type a2aJSONRPCResponse struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *a2aJSONRPCError `json:"error,omitempty"`
+}
+
+type a2aMessageSendParams struct {
+	Message  A2AMessage             `json:"message"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+type a2aTaskQueryParams struct {
+	ID            string `json:"id"`
+	HistoryLength *int   `json:"historyLength,omitempty"`
+}
+
+func (s *Server) handleA2AJSONRPC(w http.ResponseWriter, r *http.Request) {
+	rawBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.a2aJSONRPCError(w, nil, a2aErrParse, "invalid request body: "+err.Error())
+		return
+	}
+
+	var req a2aJSONRPCRequest
+	if err := json.Unmarshal(rawBody, &req); err != nil {
+		s.a2aJSONRPCError(w, nil, a2aErrParse, "parse error: "+err.Error())
+		return
+	}
+	if len(req.ID) == 0 {
+		req.ID = json.RawMessage("null")
+	}
+	if req.JSONRPC != a2aJSONRPCVersion || strings.TrimSpace(req.Method) == "" {
+		s.a2aJSONRPCError(w, req.ID, a2aErrInvalidRequest, "invalid JSON-RPC request")
+		return
+	}
+
+	switch req.Method {
+	case a2aMethodMessageSend:
+		s.handleA2ARPCMessageSend(w, r, req)
+	case a2aMethodMessageStream:
+		s.handleA2ARPCMessageStream(w, r, req)
+	case a2aMethodTasksGet:
+		s.handleA2ARPCTasksGet(w, req)
+	default:
+		s.a2aJSONRPCError(w, req.ID, a2aErrMethodNotFound, "method not found: "+req.Method)
+	}
+}
+
+func (s *Server) handleA2ARPCMessageSend(w http.ResponseWriter, r *http.Request, req a2aJSONRPCRequest) {
+	payload, err := decodeA2AMessageSendParams(req.Params)
+	if err != nil {
+		s.a2aJSONRPCError(w, req.ID, a2aErrInvalidParams, err.Error())
+		return
+	}
+	if payload.TaskID != "" && s.lookupA2ATask(payload.TaskID) == nil {
+		s.a2aJSONRPCError(w, req.ID, a2aErrTaskNotFound, "task not found: "+payload.TaskID)
+		return
+	}
+
+	var sess *session.Session
+	task, err := s.runA2ATask(r.Context(), payload, a2atunnel.RunObserver{
+		OnSession: func(resolved *session.Session) { sess = resolved },
+	})
+	if err != nil && sess == nil {
+		s.a2aJSONRPCError(w, req.ID, a2aErrInternal, err.Error())
+		return
+	}
+	if task == nil {
+		task = s.a2aTaskFromSession(sess, nil)
+	}
+	s.jsonResponse(w, http.StatusOK, a2aJSONRPCResponse{JSONRPC: a2aJSONRPCVersion, ID: req.ID, Result: task})
+}
+
+func (s *Server) handleA2ARPCMessageStream(w http.ResponseWriter, r *http.Request, req a2aJSONRPCRequest) {
+	payload, err := decodeA2AMessageSendParams(req.Params)
+	if err != nil {
+		s.a2aJSONRPCError(w, req.ID, a2aErrInvalidParams, err.Error())
+		return
+	}
+	if payload.TaskID != "" && s.lookupA2ATask(payload.TaskID) == nil {
+		s.a2aJSONRPCError(w, req.ID, a2aErrTaskNotFound, "task not found: "+payload.TaskID)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.a2aJSONRPCError(w, req.ID, a2aErrInternal, "streaming not supported")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	writeEvent := func(result interface{}) {
+		body, err := json.Marshal(a2aJSONRPCResponse{JSONRPC: a2aJSONRPCVersion, ID: req.ID, Result: result})
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", body); err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+
+	var sess *session.Session
+	var taskID, contextID string
+	statusUpdate := func(state A2ATaskState, text string, final bool) A2ATaskStatusUpdateEvent {
+		status := A2ATaskStatus{State: state, Timestamp: time.Now().UTC().Format(time.RFC3339)}
+		if strings.TrimSpace(text) != "" {
+			status.Message = a2aAgentMessage(taskID, contextID, text)
+		}
+		return A2ATaskStatusUpdateEvent{
+			Kind:      "status-update",
+			TaskID:    taskID,
+			ContextID: contextID,
+			Status:    status,
+			Final:     final,
+		}
+	}
+
+	task, runErr := s.runA2ATask(r.Context(), payload, a2atunnel.RunObserver{
+		OnSession: func(resolved *session.Session) {
+			sess = resolved
+			taskID = resolved.ID
+			contextID = a2aContextID(resolved)
+			submitted := s.a2aTaskFromSession(resolved, nil)
+			submitted.Status.State = A2ATaskStateSubmitted
+			writeEvent(submitted)
+			writeEvent(statusUpdate(A2ATaskStateWorking, "", false))
+		},
+		OnEvent: func(ev agent.Event) {
+			switch ev.Type {
+			case agent.EventToolCallFinished:
+				if ev.ToolResult == nil {
+					return
+				}
+				writeEvent(statusUpdate(A2ATaskStateWorking, describeA2AToolResult(ev.ToolResult), false))
+			case agent.EventStepCompleted:
+				if sess == nil {
+					return
+				}
+				// Narration from a tool-using step is reported as progress;
+				// the final answer becomes an artifact once the run ends.
+				for i := len(sess.Messages) - 1; i >= 0; i-- {
+					msg := sess.Messages[i]
+					if msg.Role != "assistant" {
+						continue
+					}
+					if len(msg.ToolCalls) > 0 && strings.TrimSpace(msg.Content) != "" {
+						writeEvent(statusUpdate(A2ATaskStateWorking, msg.Content, false))
+					}
+					break
+				}
+			}
+		},
+	})
+	if sess == nil {
+		message := "task could not be started"
+		if runErr != nil {
+			message = runErr.Error()
+		}
+		writeEvent(A2ATaskStatusUpdateEvent{
+			Kind:   "status-update",
+			Status: A2ATaskStatus{State: A2ATaskStateFailed, Message: a2aAgentMessage("", "", message)},
+			Final:  true,
+		})
+		return
+	}
+	if task == nil {
+		task = s.a2aTaskFromSession(sess, nil)
+	}
+	for _, artifact := range task.Artifacts {
+		writeEvent(A2ATaskArtifactUpdateEvent{
+			Kind:      "artifact-update",
+			TaskID:    taskID,
+			ContextID: contextID,
+			Artifact:  artifact,
+			LastChunk: true,
+		})
+	}
+	final := statusUpdate(task.Status.State, "", true)
+	final.Status.Message = task.Status.Message
+	writeEvent(final)
+}
+
+func (s *Server) handleA2ARPCTasksGet(w http.ResponseWriter, req a2aJSONRPCRequest) {
+	var params a2aTaskQueryParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || strings.TrimSpace(params.ID) == "" {
+		s.a2aJSONRPCError(w, req.ID, a2aErrInvalidParams, "tasks/get requires params.id")
+		return
+	}
+	sess := s.lookupA2ATask(params.ID)
+	if sess == nil {
+		s.a2aJSONRPCError(w, req.ID, a2aErrTaskNotFound, "task not found: "+params.ID)
+		return
+	}
+	s.jsonResponse(w, http.StatusOK, a2aJSONRPCResponse{
+		JSONRPC: a2aJSONRPCVersion,
+		ID:      req.ID,
+		Result:  s.a2aTaskFromSession(sess, params.HistoryLength),
+	})
+}
+
+// lookupA2ATask returns the inbound A2A session backing a task ID, or nil.
+func (s *Server) lookupA2ATask(taskID string) *session.Session {
+	sess, err := s.sessionManager.Get(strings.TrimSpace(taskID))
+	if err != nil || sess == nil || !isA2AInboundSession(sess) {
+		return nil
+	}
+	return sess
+}
+
+// runA2ATask runs an inbound A2A message through the same handler as the
+// tunnel and the REST bridge. The returned task reflects the session after
+// the run; it is nil only when the session could not be reloaded.
+func (s *Server) runA2ATask(ctx context.Context, payload a2atunnel.InboundPayload, obs a2atunnel.RunObserver) (*A2ATask, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode canonical payload: %w", err)
+	}
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	var sessionID, runID string
+	onSession := obs.OnSession
+	obs.OnSession = func(sess *session.Session) {
+		sessionID = sess.ID
+		runID = s.registerActiveSessionRun(sess.ID, cancelRun)
+		if onSession != nil {
+			onSession(sess)
+		}
+	}
+	defer func() {
+		if runID != "" {
+			s.unregisterActiveSessionRun(sessionID, runID)
+		}
+	}()
+
+	handler := a2atunnel.NewInboundHandler(
+		"brute",
+		s.sessionManager,
+		s.makeA2AAgentFactory(),
+		s.toolManagerForSession,
+		s.getA2AInboundProjectID,
+		s.getA2AInboundSubAgentID,
+	)
+	_, runErr := handler.HandleWithEvents(runCtx, &a2atunnel.AgentRequest{
+		Kind:      a2atunnel.KindTask,
+		RequestID: payload.MessageID,
+		Payload:   raw,
+	}, obs)
+	if sessionID == "" {
+		return nil, runErr
+	}
+
+	sess, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload session: %w", err)
+	}
+	if runErr != nil && sess.Status == session.StatusRunning {
+		// The run failed before the agent loop could record an outcome.
+		sess.AddAssistantMessage(fmt.Sprintf("Request failed: %s", runErr.Error()), nil)
+		sess.SetStatus(session.StatusFailed)
+		if err := s.sessionManager.Save(sess); err != nil {
+			logging.Warn("Failed to persist failed A2A task %s: %v", sess.ID, err)
+		}
+	}
+	return s.a2aTaskFromSession(sess, nil), runErr
+}
+
+// decodeA2AMessageSendParams converts MessageSendParams into the canonical
+// inbound payload understood by a2atunnel.InboundHandler. A message carrying
+// a taskId continues that task; if it is waiting for input, the text answers
+// the pending question and the run resumes.
+func decodeA2AMessageSendParams(raw json.RawMessage) (a2atunnel.InboundPayload, error) {
+	var params a2aMessageSendParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return a2atunnel.InboundPayload{}, fmt.Errorf("invalid params: %w", err)
+	}
+	msg := params.Message
+	content := a2aPartsToContent(msg.Parts)
+	if len(content) == 0 {
+		return a2atunnel.InboundPayload{}, fmt.Errorf("params.message.parts must contain text or image content")
+	}
+
+	payload := a2atunnel.InboundPayload{
+		A2AVersion:     a2atunnel.A2ABridgeVersion,
+		MessageID:      strings.TrimSpace(msg.MessageID),
+		ConversationID: strings.TrimSpace(msg.ContextID),
+		TaskID:         strings.TrimSpace(msg.TaskID),
+		Content:        content,
+		Metadata:       params.Metadata,
+	}
+	payload.Task, payload.Images = a2atunnel.LegacyFromA2AContent(content)
+	if payload.MessageID == "" {
+		payload.MessageID = uuid.NewString()
+	}
+	if sender, ok := msg.Metadata["sender"].(map[string]interface{}); ok {
+		payload.SourceAgentID, _ = sender["agent_id"].(string)
+		payload.SourceAgentName, _ = sender["name"].(string)
+	}
+	return payload, nil
+}
+
+func a2aPartsToContent(parts []A2APart) []a2atunnel.A2AContentPart {
+	content := make([]a2atunnel.A2AContentPart, 0, len(parts))
+	for _, part := range parts {
+		switch part.Kind {
+		case "text":
+			if strings.TrimSpace(part.Text) != "" {
+				content = append(content, a2atunnel.A2AContentPart{Type: a2atunnel.A2AContentTypeText, Text: part.Text})
+			}
+		case "file":
+			if part.File == nil || !strings.HasPrefix(part.File.MimeType, "image/") {
+				continue
+			}
+			if uri := strings.TrimSpace(part.File.URI); uri != "" {
+				content = append(content, a2atunnel.A2AContentPart{Type: a2atunnel.A2AContentTypeImageURL, URL: uri, Name: part.File.Name})
+			} else if data := strings.TrimSpace(part.File.Bytes); data != "" {
+				content = append(content, a2atunnel.A2AContentPart{
+					Type:      a2atunnel.A2AContentTypeImageBase64,
+					Data:      data,
+					MediaType: part.File.MimeType,
+					Name:      part.File.Name,
+				})
+			}
+		}
+	}
+	return content
+}
+
+func sessionMessageToA2AParts(msg session.Message) []A2APart {
+	parts := make([]A2APart, 0, len(msg.Images)+1)
+	if text := strings.TrimSpace(msg.Content); text != "" {
+		parts = append(parts, A2APart{Kind: "text", Text: text})
+	}
+	for _, img := range msg.Images {
+		file := &A2AFile{Name: img.Name, MimeType: img.MediaType, URI: img.URL}
+		if file.URI == "" {
+			file.Bytes = img.DataBase64
+		}
+		if file.MimeType == "" {
+			file.MimeType = "image/png"
+		}
+		parts = append(parts, A2APart{Kind: "file", File: file})
+	}
+	return parts
+}
+
+// a2aTaskFromSession renders an inbound A2A session as an A2A task. History
+// holds user and assistant turns; historyLength, when set, keeps only the most
+// recent entries. The latest assistant reply of a completed task becomes the
+// "result" artifact, and an input-required task carries its pending question
+// as the status message.
+func (s *Server) a2aTaskFromSession(sess *session.Session, historyLength *int) *A2ATask {
+	contextID := a2aContextID(sess)
+	task := &A2ATask{
+		Kind:      "task",
+		ID:        sess.ID,
+		ContextID: contextID,
+		Status: A2ATaskStatus{
+			State:     a2aTaskState(sess.Status),
+			Timestamp: sess.UpdatedAt.UTC().Format(time.RFC3339),
+		},
+	}
+
+	var lastReply *session.Message
+	for i := range sess.Messages {
+		msg := sess.Messages[i]
+		if msg.Role != "user" && msg.Role != "assistant" {
+			continue
+		}
+		parts := sessionMessageToA2AParts(msg)
+		if len(parts) == 0 {
+			continue
+		}
+		role := "user"
+		if msg.Role == "assistant" {
+			role = "agent"
+			lastReply = &sess.Messages[i]
+		}
+		task.History = append(task.History, A2AMessage{
+			Kind:      "message",
+			Role:      role,
+			Parts:     parts,
+			MessageID: msg.ID,
+			TaskID:    sess.ID,
+			ContextID: contextID,
+		})
+	}
+	if historyLength != nil && *historyLength >= 0 && len(task.History) > *historyLength {
+		task.History = task.History[len(task.History)-*historyLength:]
+	}
+
+	if task.Status.State == A2ATaskStateInputRequired {
+		if question := sessionPendingQuestion(sess); question != nil {
+			task.Status.Message = a2aAgentMessage(sess.ID, contextID, a2atunnel.RenderPendingQuestion(question))
+		}
+	}
+	if lastReply != nil {
+		switch task.Status.State {
+		case A2ATaskStateCompleted:
+			task.Artifacts = []A2AArtifact{{
+				ArtifactID: lastReply.ID,
+				Name:       "result",
+				Parts:      sessionMessageToA2AParts(*lastReply),
+			}}
+		case A2ATaskStateFailed:
+			task.Status.Message = a2aAgentMessage(sess.ID, contextID, lastReply.Content)
+		}
+	}
+	return task
+}
+
+// a2aTaskState maps a session status onto the A2A task lifecycle:
+//
+//	queued         -> submitted
+//	running        -> working
+//	input_required -> input-required (answer with message/send and its taskId)
+//	completed      -> completed
+//	failed         -> failed
+//	paused         -> canceled
+//
+// Sessions are only paused when a run is canceled, and a paused A2A task is
+// not resumed on its own, so canceled is the closest terminal state; a later
+// message with the same taskId still continues the session.
+func a2aTaskState(status session.Status) A2ATaskState {
+	switch status {
+	case session.StatusQueued:
+		return A2ATaskStateSubmitted
+	case session.StatusRunning:
+		return A2ATaskStateWorking
+	case session.StatusInputRequired:
+		return A2ATaskStateInputRequired
+	case session.StatusCompleted:
+		return A2ATaskStateCompleted
+	case session.StatusPaused:
+		return A2ATaskStateCanceled
+	case session.StatusFailed:
+		return A2ATaskStateFailed
+	default:
+		return A2ATaskStateUnknown
+	}
+}
+
+func a2aContextID(sess *session.Session) string {
+	if sess.Metadata != nil {
+		if conversationID, ok := sess.Metadata[a2atunnel.MetaA2AConversationID].(string); ok && strings.TrimSpace(conversationID) != "" {
+			return strings.TrimSpace(conversationID)
+		}
+	}
+	return sess.ID
+}
+
+func a2aAgentMessage(taskID, contextID, text string) *A2AMessage {
+	return &A2AMessage{
+		Kind:      "message",
+		Role:      "agent",
+		Parts:     []A2APart{{Kind: "text", Text: strings.TrimSpace(text)}},
+		MessageID: uuid.NewString(),
+		TaskID:    taskID,
+		ContextID: contextID,
+	}
+}
+
+func describeA2AToolResult(result *agent.ToolResultEvent) string {
+	outcome := "completed"
+	if result.IsError {
+		outcome = "failed"
+	}
+	text := fmt.Sprintf("Tool %s %s in %dms", result.Name, outcome, result.Duration.Milliseconds())
+	if preview := strings.TrimSpace(result.InputPreview); preview != "" {
+		text = fmt.Sprintf("Tool %s (%s) %s in %dms", result.Name, preview, outcome, result.Duration.Milliseconds())
+	}
+	return text
+}
+
+func isA2AInboundSession(sess *session.Session) bool {
+	if sess.Metadata == nil {
+		return false
+	}
+	inbound, _ := sess.Metadata[a2atunnel.MetaA2AInbound].(bool)
+	return inbound
+}
+
+func (s *Server) a2aJSONRPCError(w http.ResponseWriter, id json.RawMessage, code int, message string) {
+	logging.Error("A2A JSON-RPC error: %d - %s", code, message)
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	s.jsonResponse(w, http.StatusOK, a2aJSONRPCResponse{
+		JSONRPC: a2aJSONRPCVersion,
+		ID:      id,
+		Error:   &a2aJSONRPCError{Code: code, Message: message},
+	})
+}
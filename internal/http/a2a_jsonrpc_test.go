@@ -0,0 +1,185 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/a2atunnel"
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/speechcache"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+func newA2AJSONRPCTestServer(t *testing.T) *Server {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	cfg := &config.Config{DataPath: t.TempDir()}
+	return NewServer(cfg, nil, tools.NewManager(t.TempDir()), session.NewManager(store), store, speechcache.New(0), 0)
+}
+
+func postA2AJSONRPC(t *testing.T, server *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/a2a/jsonrpc", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.handleA2AJSONRPC(rec, req)
+	return rec
+}
+
+type a2aTestRPCResponse struct {
+	ID     json.RawMessage  `json:"id"`
+	Result json.RawMessage  `json:"result"`
+	Error  *a2aJSONRPCError `json:"error"`
+}
+
+func decodeA2ATestRPCResponse(t *testing.T, raw []byte) a2aTestRPCResponse {
+	t.Helper()
+	var resp a2aTestRPCResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		t.Fatalf("Failed to decode JSON-RPC response %q: %v", raw, err)
+	}
+	return resp
+}
+
+func TestA2AJSONRPCErrors(t *testing.T) {
+	server := newA2AJSONRPCTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{name: "parse error", body: "{", code: a2aErrParse},
+		{name: "missing version", body: `{"id":1,"method":"tasks/get"}`, code: a2aErrInvalidRequest},
+		{name: "unknown method", body: `{"jsonrpc":"2.0","id":1,"method":"tasks/resubscribe"}`, code: a2aErrMethodNotFound},
+		{name: "empty message", body: `{"jsonrpc":"2.0","id":1,"method":"message/send","params":{"message":{"role":"user","parts":[]}}}`, code: a2aErrInvalidParams},
+		{name: "unknown task", body: `{"jsonrpc":"2.0","id":1,"method":"tasks/get","params":{"id":"missing"}}`, code: a2aErrTaskNotFound},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := postA2AJSONRPC(t, server, tc.body)
+			resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes())
+			if resp.Error == nil || resp.Error.Code != tc.code {
+				t.Fatalf("Expected error code %d, got %+v", tc.code, resp.Error)
+			}
+		})
+	}
+}
+
+func TestA2AJSONRPCTasksGetReturnsInboundSession(t *testing.T) {
+	server := newA2AJSONRPCTestServer(t)
+
+	sess, err := server.sessionManager.Create("brute")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.Metadata = map[string]interface{}{
+		a2atunnel.MetaA2AInbound:        true,
+		a2atunnel.MetaA2AConversationID: "ctx-1",
+	}
+	sess.AddUserMessage("first question")
+	sess.AddAssistantMessage("first answer", nil)
+	sess.AddUserMessage("second question")
+	sess.AddAssistantMessage("second answer", nil)
+	sess.SetStatus(session.StatusCompleted)
+	if err := server.sessionManager.Save(sess); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+
+	rec := postA2AJSONRPC(t, server, `{"jsonrpc":"2.0","id":"q1","method":"tasks/get","params":{"id":"`+sess.ID+`","historyLength":2}}`)
+	resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes())
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	if string(resp.ID) != `"q1"` {
+		t.Fatalf("Expected request id to be echoed, got %s", resp.ID)
+	}
+	var task A2ATask
+	if err := json.Unmarshal(resp.Result, &task); err != nil {
+		t.Fatalf("Failed to decode task: %v", err)
+	}
+	if task.ID != sess.ID || task.ContextID != "ctx-1" {
+		t.Fatalf("Unexpected task identity: id=%q context=%q", task.ID, task.ContextID)
+	}
+	if task.Status.State != A2ATaskStateCompleted {
+		t.Fatalf("Expected completed state, got %q", task.Status.State)
+	}
+	if len(task.History) != 2 || task.History[0].Role != "user" || task.History[1].Role != "agent" {
+		t.Fatalf("Expected the last user/agent turn in history, got %+v", task.History)
+	}
+	if len(task.Artifacts) != 1 || task.Artifacts[0].Parts[0].Text != "second answer" {
+		t.Fatalf("Expected the final answer as the result artifact, got %+v", task.Artifacts)
+	}
+
+	plain, err := server.sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	rec = postA2AJSONRPC(t, server, `{"jsonrpc":"2.0","id":2,"method":"tasks/get","params":{"id":"`+plain.ID+`"}}`)
+	if resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes()); resp.Error == nil || resp.Error.Code != a2aErrTaskNotFound {
+		t.Fatalf("Expected non-A2A session to be hidden, got %+v", resp.Error)
+	}
+}
+
+func TestA2AJSONRPCMessageStreamReportsTaskLifecycle(t *testing.T) {
+	server := newA2AJSONRPCTestServer(t)
+
+	// No provider is configured, so the run fails after the task is created.
+	body := `{"jsonrpc":"2.0","id":7,"method":"message/stream","params":{"message":{"kind":"message","role":"user","messageId":"m1","contextId":"ctx-9","parts":[{"kind":"text","text":"hello"}]}}}`
+	rec := postA2AJSONRPC(t, server, body)
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected SSE content type, got %q", ct)
+	}
+
+	var results []map[string]interface{}
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		resp := decodeA2ATestRPCResponse(t, []byte(strings.TrimPrefix(line, "data: ")))
+		if string(resp.ID) != "7" {
+			t.Fatalf("Expected request id on every event, got %s", resp.ID)
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			t.Fatalf("Failed to decode event: %v", err)
+		}
+		results = append(results, result)
+	}
+	if len(results) < 3 {
+		t.Fatalf("Expected task, working and final events, got %d", len(results))
+	}
+	if results[0]["kind"] != "task" || results[0]["contextId"] != "ctx-9" {
+		t.Fatalf("Expected initial task event, got %+v", results[0])
+	}
+	taskID, _ := results[0]["id"].(string)
+	last := results[len(results)-1]
+	status, _ := last["status"].(map[string]interface{})
+	if last["kind"] != "status-update" || last["final"] != true || status["state"] != string(A2ATaskStateFailed) {
+		t.Fatalf("Expected final failed status update, got %+v", last)
+	}
+
+	rec = postA2AJSONRPC(t, server, `{"jsonrpc":"2.0","id":8,"method":"tasks/get","params":{"id":"`+taskID+`"}}`)
+	resp := decodeA2ATestRPCResponse(t, rec.Body.Bytes())
+	if resp.Error != nil {
+		t.Fatalf("Expected streamed task to be retrievable, got %+v", resp.Error)
+	}
+	var task A2ATask
+	if err := json.Unmarshal(resp.Result, &task); err != nil {
+		t.Fatalf("Failed to decode task: %v", err)
+	}
+	if task.Status.State != A2ATaskStateFailed || task.Status.Message == nil {
+		t.Fatalf("Expected failed task with a reason, got %+v", task.Status)
+	}
+}
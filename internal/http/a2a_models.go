@@ -154,3 +154,91 @@ type AgentCardSignature struct {
 	Signature string                 `json:"signature"`
 	Header    map[string]interface{} `json:"header,omitempty"`
 }
+
+// A2A task and message structures used by the JSON-RPC binding.
+
+// A2ATaskState is the lifecycle state of an A2A task.
+type A2ATaskState string
+
+const (
+	A2ATaskStateSubmitted     A2ATaskState = "submitted"
+	A2ATaskStateWorking       A2ATaskState = "working"
+	A2ATaskStateInputRequired A2ATaskState = "input-required"
+	A2ATaskStateCompleted     A2ATaskState = "completed"
+	A2ATaskStateCanceled      A2ATaskState = "canceled"
+	A2ATaskStateFailed        A2ATaskState = "failed"
+	A2ATaskStateUnknown       A2ATaskState = "unknown"
+)
+
+// A2APart is a single piece of message or artifact content.
+// Kind is "text", "file" or "data".
+type A2APart struct {
+	Kind     string                 `json:"kind"`
+	Text     string                 `json:"text,omitempty"`
+	File     *A2AFile               `json:"file,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// A2AFile carries file content either inline (Bytes, base64) or by URI.
+type A2AFile struct {
+	Name     string `json:"name,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Bytes    string `json:"bytes,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+// A2AMessage is one communication turn between a client and the agent.
+type A2AMessage struct {
+	Kind      string                 `json:"kind"`
+	Role      string                 `json:"role"`
+	Parts     []A2APart              `json:"parts"`
+	MessageID string                 `json:"messageId"`
+	TaskID    string                 `json:"taskId,omitempty"`
+	ContextID string                 `json:"contextId,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// A2ATaskStatus is the current state of a task with an optional agent message.
+type A2ATaskStatus struct {
+	State     A2ATaskState `json:"state"`
+	Message   *A2AMessage  `json:"message,omitempty"`
+	Timestamp string       `json:"timestamp,omitempty"`
+}
+
+// A2AArtifact is an output produced by a task.
+type A2AArtifact struct {
+	ArtifactID string    `json:"artifactId"`
+	Name       string    `json:"name,omitempty"`
+	Parts      []A2APart `json:"parts"`
+}
+
+// A2ATask is the stateful unit of work returned by message/send and tasks/get.
+type A2ATask struct {
+	Kind      string                 `json:"kind"`
+	ID        string                 `json:"id"`
+	ContextID string                 `json:"contextId"`
+	Status    A2ATaskStatus          `json:"status"`
+	Artifacts []A2AArtifact          `json:"artifacts,omitempty"`
+	History   []A2AMessage           `json:"history,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// A2ATaskStatusUpdateEvent is streamed by message/stream when a task's status changes.
+type A2ATaskStatusUpdateEvent struct {
+	Kind      string        `json:"kind"`
+	TaskID    string        `json:"taskId"`
+	ContextID string        `json:"contextId"`
+	Status    A2ATaskStatus `json:"status"`
+	Final     bool          `json:"final"`
+}
+
+// A2ATaskArtifactUpdateEvent is streamed by message/stream when an artifact is produced.
+type A2ATaskArtifactUpdateEvent struct {
+	Kind      string      `json:"kind"`
+	TaskID    string      `json:"taskId"`
+	ContextID string      `json:"contextId"`
+	Artifact  A2AArtifact `json:"artifact"`
+	Append    bool        `json:"append,omitempty"`
+	LastChunk bool        `json:"lastChunk,omitempty"`
+}
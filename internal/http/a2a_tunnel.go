@@ -218,14 +218,19 @@ func (s *Server) makeA2AAgentFactory() a2atunnel.AgentRunnerBuilder {
 			}
 		}
 
+		agentDef := s.agentDefinition(sess, "brute-a2a")
 		cfg := agent.Config{
 			Name:          "brute-a2a",
 			Model:         target.Model,
 			SystemPrompt:  s.buildSystemPromptForA2ASession(sess),
 			MaxSteps:      s.config.MaxSteps,
-			Temperature:   s.config.Temperature,
+			MaxTokens:     s.config.MaxTokens,
+			MaxDuration:   s.config.MaxRunDuration(),
+			StopSequences: s.config.StopSequences,
+			Temperature:   s.resolveSessionTemperature(sess, agentDef),
 			ContextWindow: target.ContextWindow,
 		}
+		agentDef.Apply(&cfg)
 		return agent.New(cfg, target.Client, toolManager, s.sessionManager), nil
 	}
 }
@@ -0,0 +1,283 @@
+package http
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxAttachmentBytes bounds the size of one uploaded file.
+	maxAttachmentBytes = 20 << 20
+	// maxInlineImageBytes bounds the images that are also sent to the model
+	// as image parts; larger ones are only referenced by path.
+	maxInlineImageBytes = 5 << 20
+)
+
+// attachmentMediaTypes lists the content types accepted for upload.
+var attachmentMediaTypes = map[string]bool{
+	"image/png":        true,
+	"image/jpeg":       true,
+	"image/gif":        true,
+	"image/webp":       true,
+	"application/pdf":  true,
+	"application/json": true,
+	"text/plain":       true,
+	"text/markdown":    true,
+	"text/csv":         true,
+}
+
+// AttachmentResponse describes a file uploaded to a session.
+type AttachmentResponse struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Name      string    `json:"name"`
+	MediaType string    `json:"media_type"`
+	Size      int64     `json:"size"`
+	Path      string    `json:"path"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func attachmentToResponse(att *storage.Attachment) AttachmentResponse {
+	return AttachmentResponse{
+		ID:        att.ID,
+		SessionID: att.SessionID,
+		Name:      att.Name,
+		MediaType: att.MediaType,
+		Size:      att.Size,
+		Path:      att.Path,
+		URL:       "/attachments/" + att.ID,
+		CreatedAt: att.CreatedAt,
+	}
+}
+
+// handleUploadAttachment stores the "file" field of a multipart upload under
+// the session's attachment folder and notes it in the conversation, so the
+// agent can open it with the read tool. Images small enough are attached to
+// that message for vision models as well. Uploads are refused while a run
+// works on the session, as its next save would drop the note.
+func (s *Server) handleUploadAttachment(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+	if _, err := s.sessionManager.Get(sessionID); err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+	if s.sessionRunning(sessionID) {
+		s.errorResponse(w, http.StatusConflict, "Session has an active run; upload the file when it finishes")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxAttachmentBytes+1<<20)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Expected a multipart/form-data body: "+err.Error())
+		return
+	}
+	var part io.ReadCloser
+	var name, declaredType string
+	for {
+		p, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Failed to read upload: "+err.Error())
+			return
+		}
+		if p.FormName() == "file" {
+			part, name, declaredType = p, p.FileName(), p.Header.Get("Content-Type")
+			break
+		}
+		p.Close()
+	}
+	if part == nil {
+		s.errorResponse(w, http.StatusBadRequest, "file field is required")
+		return
+	}
+	defer part.Close()
+
+	// Sniff the first bytes when the client did not say what it sends.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		s.errorResponse(w, http.StatusBadRequest, "Failed to read upload: "+err.Error())
+		return
+	}
+	head = head[:n]
+	mediaType := attachmentMediaType(declaredType, head)
+	if !attachmentMediaTypes[mediaType] {
+		s.errorResponse(w, http.StatusUnsupportedMediaType, fmt.Sprintf("Unsupported attachment type %q", mediaType))
+		return
+	}
+
+	id := uuid.New().String()
+	name = attachmentFileName(name, id)
+	dir, err := filepath.Abs(storage.AttachmentDir(s.config.DataPath, sessionID))
+	if err == nil {
+		err = os.MkdirAll(dir, 0o755)
+	}
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to create attachment folder: "+err.Error())
+		return
+	}
+	path := filepath.Join(dir, id[:8]+"-"+name)
+	size, err := writeAttachmentFile(path, io.MultiReader(bytes.NewReader(head), part))
+	if err != nil {
+		status := http.StatusInternalServerError
+		var tooLarge *http.MaxBytesError
+		if errors.Is(err, errAttachmentTooLarge) || errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		s.errorResponse(w, status, "Failed to store attachment: "+err.Error())
+		return
+	}
+
+	att := &storage.Attachment{
+		ID:        id,
+		SessionID: sessionID,
+		Name:      name,
+		MediaType: mediaType,
+		Size:      size,
+		Path:      path,
+		CreatedAt: time.Now(),
+	}
+	if err := s.store.SaveAttachment(att); err != nil {
+		os.Remove(path)
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to save attachment: "+err.Error())
+		return
+	}
+
+	var images []session.ImageAttachment
+	if strings.HasPrefix(mediaType, "image/") && size <= maxInlineImageBytes {
+		if data, err := os.ReadFile(path); err == nil {
+			images = append(images, session.ImageAttachment{
+				Name:       name,
+				MediaType:  mediaType,
+				DataBase64: base64.StdEncoding.EncodeToString(data),
+			})
+		}
+	}
+	note := fmt.Sprintf("Attached file %s (%s, %s) at %s. Use the read tool to open it.", name, mediaType, formatAttachmentSize(size), path)
+	if _, err := s.sessionManager.Update(sessionID, func(sess *session.Session) error {
+		sess.AddUserMessageWithImages(note, images)
+		return nil
+	}); err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to note attachment in session: "+err.Error())
+		return
+	}
+
+	s.jsonResponse(w, http.StatusCreated, attachmentToResponse(att))
+}
+
+// handleListAttachments lists the files uploaded to a session.
+func (s *Server) handleListAttachments(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+	if _, err := s.sessionManager.Get(sessionID); err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+	attachments, err := s.store.ListAttachments(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to list attachments: "+err.Error())
+		return
+	}
+	resp := make([]AttachmentResponse, len(attachments))
+	for i, att := range attachments {
+		resp[i] = attachmentToResponse(att)
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
+
+// handleGetAttachment serves the bytes of an attachment with its stored
+// content type.
+func (s *Server) handleGetAttachment(w http.ResponseWriter, r *http.Request) {
+	att, err := s.store.GetAttachment(chi.URLParam(r, "attachmentID"))
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, err.Error())
+		return
+	}
+	f, err := os.Open(att.Path)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Attachment file is missing")
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", att.MediaType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": att.Name}))
+	http.ServeContent(w, r, att.Name, att.CreatedAt, f)
+}
+
+var errAttachmentTooLarge = fmt.Errorf("attachment exceeds %d MB", maxAttachmentBytes>>20)
+
+// writeAttachmentFile copies src to path, removing the file again when it
+// fails or grows past maxAttachmentBytes.
+func writeAttachmentFile(path string, src io.Reader) (int64, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return 0, err
+	}
+	size, err := io.Copy(f, io.LimitReader(src, maxAttachmentBytes+1))
+	if err == nil && size > maxAttachmentBytes {
+		err = errAttachmentTooLarge
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return 0, err
+	}
+	return size, nil
+}
+
+// attachmentMediaType returns the declared content type without parameters,
+// or the sniffed one when none or only a generic one was given.
+func attachmentMediaType(declared string, head []byte) string {
+	mediaType, _, err := mime.ParseMediaType(declared)
+	if err != nil || mediaType == "" || mediaType == "application/octet-stream" {
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+	}
+	return strings.ToLower(mediaType)
+}
+
+// attachmentFileName reduces an uploaded file name to a safe base name.
+func attachmentFileName(name, id string) string {
+	name = filepath.Base(strings.ReplaceAll(strings.TrimSpace(name), "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`/:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if strings.Trim(name, ". ") == "" {
+		return "attachment-" + id[:8]
+	}
+	return name
+}
+
+func formatAttachmentSize(size int64) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(size)/(1<<20))
+	case size >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(size)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", size)
+	}
+}
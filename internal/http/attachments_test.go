@@ -0,0 +1,151 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+)
+
+func uploadAttachment(t *testing.T, s *Server, sessionID, name, contentType string, data []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", `form-data; name="file"; filename="`+name+`"`)
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	part, err := mw.CreatePart(header)
+	if err != nil {
+		t.Fatalf("CreatePart: %v", err)
+	}
+	part.Write(data)
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/sessions/"+sessionID+"/attachments", &body)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAttachmentUploadListServeAndDelete(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	server.config.DataPath = t.TempDir()
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	rec := uploadAttachment(t, server, sess.ID, "../notes.csv", "text/csv", []byte("a,b\n1,2\n"))
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("upload: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var att AttachmentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &att); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if att.Name != "notes.csv" || att.MediaType != "text/csv" || att.Size != 8 {
+		t.Fatalf("unexpected attachment %+v", att)
+	}
+	if data, err := os.ReadFile(att.Path); err != nil || string(data) != "a,b\n1,2\n" {
+		t.Fatalf("stored file: %q, %v", data, err)
+	}
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+	if rec := uploadAttachment(t, server, sess.ID, "shot.png", "", png); rec.Code != http.StatusCreated {
+		t.Fatalf("sniffed upload: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if rec := uploadAttachment(t, server, sess.ID, "page.html", "text/html", []byte("<script></script>")); rec.Code != http.StatusUnsupportedMediaType {
+		t.Fatalf("disallowed type: status %d", rec.Code)
+	}
+
+	stored, err := sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(stored.Messages) != 2 || !strings.Contains(stored.Messages[0].Content, att.Path) {
+		t.Fatalf("upload should note the attachment path, got %+v", stored.Messages)
+	}
+	if images := stored.Messages[1].Images; len(images) != 1 || images[0].MediaType != "image/png" {
+		t.Fatalf("image upload should be attached as an image, got %+v", images)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/attachments", "")
+	var list []AttachmentResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil || len(list) != 2 || list[0].ID != att.ID {
+		t.Fatalf("list: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/attachments/"+att.ID, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "a,b\n1,2\n" || rec.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("serve: status %d type %q body=%q", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	if rec := serveAuthorized(server, http.MethodDelete, "/sessions/"+sess.ID, ""); rec.Code != http.StatusNoContent {
+		t.Fatalf("delete session: status %d", rec.Code)
+	}
+	if _, err := os.Stat(att.Path); !os.IsNotExist(err) {
+		t.Fatalf("attachment file should be deleted with the session, stat err=%v", err)
+	}
+	if rec := serveAuthorized(server, http.MethodGet, "/attachments/"+att.ID, ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("serve after delete: status %d", rec.Code)
+	}
+}
+
+func TestAttachmentUploadDuringRunIsRefused(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	server.config.DataPath = t.TempDir()
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// The run holds its own copy of the session and saves it when it stops.
+	running, err := sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	_, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	runID := server.registerActiveSessionRun(sess.ID, cancel)
+
+	if rec := uploadAttachment(t, server, sess.ID, "notes.txt", "text/plain", []byte("hi")); rec.Code != http.StatusConflict {
+		t.Fatalf("upload during run: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	running.AddAssistantMessage("done", nil)
+	if err := sessionManager.Save(running); err != nil {
+		t.Fatalf("run save: %v", err)
+	}
+	server.unregisterActiveSessionRun(sess.ID, runID)
+
+	external := &fakeRunCanceller{sessions: map[string]bool{sess.ID: true}}
+	server.AddRunCanceller(external)
+	if rec := uploadAttachment(t, server, sess.ID, "notes.txt", "text/plain", []byte("hi")); rec.Code != http.StatusConflict {
+		t.Fatalf("upload during job run: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	attachments, err := server.store.ListAttachments(sess.ID)
+	if err != nil || len(attachments) != 0 {
+		t.Fatalf("refused uploads should store nothing, got %v, %v", attachments, err)
+	}
+	delete(external.sessions, sess.ID)
+
+	if rec := uploadAttachment(t, server, sess.ID, "notes.txt", "text/plain", []byte("hi")); rec.Code != http.StatusCreated {
+		t.Fatalf("upload after run: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	stored, err := sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(stored.Messages) != 2 || stored.Messages[0].Content != "done" || !strings.Contains(stored.Messages[1].Content, "notes.txt") {
+		t.Fatalf("session should keep the run's message and the note, got %+v", stored.Messages)
+	}
+}
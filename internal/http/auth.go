@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/http/webui"
+)
+
+// LocalAPITokenName is the name of the token minted for the local user.
+const LocalAPITokenName = "local"
+
+// localAPITokenFile stores the minted local token inside the data directory.
+const localAPITokenFile = "api-token"
+
+type apiTokenContextKey struct{}
+
+// publicPaths are served without authentication. Slack callbacks carry a
+// request signature instead, which handleSlackEvents verifies.
+var publicPaths = map[string]bool{
+	"/health":                      true,
+	"/health/live":                 true,
+	"/health/ready":                true,
+	"/.well-known/agent-card.json": true,
+	slackEventsPath:                true,
+}
+
+// isWebUIPath reports whether path is a page or asset of the embedded web
+// UI. They hold no data, and the UI asks for a token before calling the API.
+func isWebUIPath(path string) bool {
+	return webui.Enabled && (path == "/" || strings.HasPrefix(path, webui.AssetPrefix))
+}
+
+// requireAPIToken rejects requests without a valid bearer token. Browsers
+// cannot set headers on EventSource or <audio> requests, so an access_token
+// query parameter is accepted as well.
+func (s *Server) requireAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if publicPaths[r.URL.Path] || isWebUIPath(r.URL.Path) || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		presented := bearerToken(r)
+		if presented == "" {
+			s.unauthorized(w, "Missing API token")
+			return
+		}
+		var tokens []config.APIToken
+		if s.config != nil {
+			tokens = s.config.Server.APITokens
+		}
+		name, ok := matchAPIToken(tokens, presented)
+		if !ok {
+			s.unauthorized(w, "Invalid API token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiTokenContextKey{}, name)))
+	})
+}
+
+func (s *Server) unauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="aagent"`)
+	s.jsonResponse(w, http.StatusUnauthorized, map[string]string{"error": message})
+}
+
+// apiTokenName returns the name of the token that authenticated the request.
+func apiTokenName(r *http.Request) string {
+	name, _ := r.Context().Value(apiTokenContextKey{}).(string)
+	return name
+}
+
+func bearerToken(r *http.Request) string {
+	if header := strings.TrimSpace(r.Header.Get("Authorization")); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return strings.TrimSpace(r.URL.Query().Get("access_token"))
+}
+
+// matchAPIToken compares against every configured token in constant time.
+func matchAPIToken(tokens []config.APIToken, presented string) (string, bool) {
+	matched := ""
+	for _, token := range tokens {
+		if token.Token == "" {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token.Token), []byte(presented)) == 1 && matched == "" {
+			matched = token.Name
+		}
+	}
+	return matched, matched != ""
+}
+
+// EnsureLocalAPIToken returns the local token stored in dataPath, creating it
+// on first use so single-user setups work without manual configuration.
+func EnsureLocalAPIToken(dataPath string) (config.APIToken, error) {
+	path := LocalAPITokenPath(dataPath)
+	if data, err := os.ReadFile(path); err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return config.APIToken{Name: LocalAPITokenName, Token: token}, nil
+		}
+	} else if !os.IsNotExist(err) {
+		return config.APIToken{}, fmt.Errorf("failed to read local API token: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return config.APIToken{}, fmt.Errorf("failed to generate local API token: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+	if err := os.MkdirAll(dataPath, 0755); err != nil {
+		return config.APIToken{}, err
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0600); err != nil {
+		return config.APIToken{}, fmt.Errorf("failed to write local API token: %w", err)
+	}
+	return config.APIToken{Name: LocalAPITokenName, Token: token}, nil
+}
+
+// LocalAPITokenPath returns where EnsureLocalAPIToken stores the token.
+func LocalAPITokenPath(dataPath string) string {
+	return filepath.Join(dataPath, localAPITokenFile)
+}
@@ -0,0 +1,87 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+)
+
+func TestRequireAPIToken(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.Config{Server: config.ServerConfig{APITokens: []config.APIToken{
+		{Name: "web", Token: "web-secret"},
+		{Name: "phone", Token: "phone-secret"},
+	}}}
+	s := &Server{config: cfg}
+	var seenName string
+	handler := s.requireAPIToken(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seenName = apiTokenName(r)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	cases := []struct {
+		name     string
+		path     string
+		header   string
+		wantCode int
+		wantName string
+	}{
+		{name: "health is public", path: "/health", wantCode: http.StatusNoContent},
+		{name: "agent card is public", path: "/.well-known/agent-card.json", wantCode: http.StatusNoContent},
+		{name: "missing token", path: "/sessions", wantCode: http.StatusUnauthorized},
+		{name: "wrong token", path: "/sessions", header: "Bearer nope", wantCode: http.StatusUnauthorized},
+		{name: "wrong scheme", path: "/sessions", header: "Basic phone-secret", wantCode: http.StatusUnauthorized},
+		{name: "named token", path: "/sessions", header: "Bearer phone-secret", wantCode: http.StatusNoContent, wantName: "phone"},
+		{name: "query token", path: "/speech/clips/1?access_token=web-secret", wantCode: http.StatusNoContent, wantName: "web"},
+	}
+	for _, tc := range cases {
+		seenName = ""
+		req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		if tc.header != "" {
+			req.Header.Set("Authorization", tc.header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != tc.wantCode {
+			t.Fatalf("%s: status = %d, want %d", tc.name, rec.Code, tc.wantCode)
+		}
+		if tc.wantCode == http.StatusUnauthorized && !strings.Contains(rec.Body.String(), `"error"`) {
+			t.Fatalf("%s: expected JSON error body, got %s", tc.name, rec.Body.String())
+		}
+		if seenName != tc.wantName {
+			t.Fatalf("%s: token name = %q, want %q", tc.name, seenName, tc.wantName)
+		}
+	}
+}
+
+func TestEnsureLocalAPITokenIsStable(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	first, err := EnsureLocalAPIToken(dir)
+	if err != nil {
+		t.Fatalf("EnsureLocalAPIToken: %v", err)
+	}
+	if first.Name != LocalAPITokenName || len(first.Token) != 64 {
+		t.Fatalf("unexpected local token %+v", first)
+	}
+	info, err := os.Stat(LocalAPITokenPath(dir))
+	if err != nil {
+		t.Fatalf("stat token file: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+	second, err := EnsureLocalAPIToken(dir)
+	if err != nil {
+		t.Fatalf("EnsureLocalAPIToken again: %v", err)
+	}
+	if second != first {
+		t.Fatalf("local token changed between calls: %+v vs %+v", first, second)
+	}
+}
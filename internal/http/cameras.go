@@ -1,7 +1,11 @@
 package http
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/A2gent/brute/internal/tools"
 )
@@ -20,3 +24,21 @@ func (s *Server) handleListCameraDevices(w http.ResponseWriter, r *http.Request)
 		Cameras: cameras,
 	})
 }
+
+// validateCameraIndexSetting rejects a default camera index that does not map to
+// a listed device. Hosts where listing itself fails are not blocked.
+func validateCameraIndexSetting(ctx context.Context, settings map[string]string) error {
+	raw := strings.TrimSpace(settings[tools.CameraIndexKey])
+	if raw == "" {
+		return nil
+	}
+	index, err := strconv.Atoi(raw)
+	if err != nil || index <= 0 {
+		return fmt.Errorf("%s must be a positive integer, got %q", tools.CameraIndexKey, raw)
+	}
+	devices, err := tools.ListCameraDevices(ctx)
+	if err != nil {
+		return nil
+	}
+	return tools.ValidateCameraIndex(devices, index)
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/logging"
+)
+
+// schedulerStaleAfter is how long the scheduling loop, which ticks every
+// minute, may go without a tick before readiness fails.
+const schedulerStaleAfter = 3 * time.Minute
+
+// Readiness check outcomes.
+const (
+	healthOK       = "ok"
+	healthFailed   = "failed"
+	healthDisabled = "disabled"
+)
+
+// schedulerMonitor is implemented by job runners that run a scheduling
+// loop, such as the scheduler.
+type schedulerMonitor interface {
+	LastTick() (time.Time, bool)
+	ActiveRuns() int
+}
+
+// HealthCheck is the outcome of one readiness check.
+type HealthCheck struct {
+	Status     string     `json:"status"` // ok, failed or disabled
+	Critical   bool       `json:"critical"`
+	Error      string     `json:"error,omitempty"`
+	LastTickAt *time.Time `json:"last_tick_at,omitempty"`
+	Provider   string     `json:"provider,omitempty"`
+}
+
+// ReadinessResponse is the body of GET /health/ready.
+type ReadinessResponse struct {
+	Status     string                 `json:"status"` // ok or unavailable
+	Checks     map[string]HealthCheck `json:"checks"`
+	ActiveRuns int                    `json:"active_runs"`
+}
+
+// handleHealthLive reports that the process serves requests, without
+// touching any dependency.
+func (s *Server) handleHealthLive(w http.ResponseWriter, r *http.Request) {
+	s.jsonResponse(w, http.StatusOK, map[string]string{"status": healthOK})
+}
+
+// handleHealthReady checks the database with a rolled back write and the
+// liveness of the scheduling loop, and counts active runs. provider=true
+// also lists the active provider's models, cached like GET /models. It
+// answers 503 when a critical check fails.
+func (s *Server) handleHealthReady(w http.ResponseWriter, r *http.Request) {
+	resp := ReadinessResponse{Status: healthOK, Checks: map[string]HealthCheck{}}
+
+	database := HealthCheck{Status: healthOK, Critical: true}
+	if err := s.store.CheckWritable(); err != nil {
+		database.Status = healthFailed
+		database.Error = err.Error()
+	}
+	resp.Checks["database"] = database
+
+	s.activeRunsMu.Lock()
+	resp.ActiveRuns = len(s.activeRuns)
+	monitor, _ := s.jobRunner.(schedulerMonitor)
+	s.activeRunsMu.Unlock()
+
+	sched := HealthCheck{Status: healthDisabled}
+	if monitor != nil {
+		resp.ActiveRuns += monitor.ActiveRuns()
+		if lastTick, running := monitor.LastTick(); running {
+			sched.Critical = true
+			sched.Status = healthOK
+			sched.LastTickAt = &lastTick
+			if time.Since(lastTick) > schedulerStaleAfter {
+				sched.Status = healthFailed
+				sched.Error = "no scheduler tick since " + lastTick.Format(time.RFC3339)
+			}
+		}
+	}
+	resp.Checks["scheduler"] = sched
+
+	if checkProvider, _ := strconv.ParseBool(r.URL.Query().Get("provider")); checkProvider {
+		resp.Checks["provider"] = s.checkActiveProvider(r)
+	}
+
+	status := http.StatusOK
+	for name, check := range resp.Checks {
+		if check.Critical && check.Status == healthFailed {
+			resp.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+			logging.WarnContext(r.Context(), "Readiness check %s failed: %s", name, check.Error)
+		}
+	}
+	s.jsonResponse(w, status, resp)
+}
+
+// checkActiveProvider lists the models of the active provider, reusing the
+// GET /models cache so that frequent probes do not call the provider.
+func (s *Server) checkActiveProvider(r *http.Request) HealthCheck {
+	providerType := config.ProviderType(config.NormalizeProviderRef(s.config.ActiveProvider))
+	check := HealthCheck{Status: healthOK, Critical: true, Provider: string(providerType)}
+	if providerType == "" {
+		check.Status = healthFailed
+		check.Error = "no active provider is configured"
+		return check
+	}
+
+	entry, ok := s.modelLists.get(providerType, time.Now())
+	if !ok {
+		entry = s.fetchProviderModels(r.Context(), providerType)
+		if r.Context().Err() == nil {
+			s.modelLists.put(providerType, entry)
+		}
+	}
+	if entry.err != "" {
+		check.Status = healthFailed
+		check.Error = entry.err
+	}
+	return check
+}
@@ -0,0 +1,69 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// fakeScheduler is a job runner whose loop last ticked at lastTick.
+type fakeScheduler struct {
+	lastTick time.Time
+	active   int
+}
+
+func (f *fakeScheduler) StartRun(context.Context, *storage.RecurringJob) (*storage.JobExecution, error) {
+	return nil, errNoJobRunner
+}
+
+func (f *fakeScheduler) LastTick() (time.Time, bool) { return f.lastTick, true }
+func (f *fakeScheduler) ActiveRuns() int             { return f.active }
+
+func getReadiness(t *testing.T, server *Server) (int, ReadinessResponse) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+	var resp ReadinessResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v body=%s", err, rec.Body.String())
+	}
+	return rec.Code, resp
+}
+
+func TestHealthReadiness(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health/live", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("live without a token: status %d", rec.Code)
+	}
+
+	code, resp := getReadiness(t, server)
+	if code != http.StatusOK || resp.Checks["database"].Status != healthOK || resp.Checks["scheduler"].Status != healthDisabled {
+		t.Fatalf("expected ready without a scheduler, got %d %+v", code, resp)
+	}
+
+	sched := &fakeScheduler{lastTick: time.Now(), active: 2}
+	server.SetJobRunner(sched)
+	code, resp = getReadiness(t, server)
+	if code != http.StatusOK || resp.ActiveRuns != 2 || resp.Checks["scheduler"].LastTickAt == nil {
+		t.Fatalf("expected ready with a ticking scheduler, got %d %+v", code, resp)
+	}
+
+	sched.lastTick = time.Now().Add(-time.Hour)
+	if code, resp = getReadiness(t, server); code != http.StatusServiceUnavailable || resp.Checks["scheduler"].Status != healthFailed {
+		t.Fatalf("expected a stalled scheduler to fail readiness, got %d %+v", code, resp)
+	}
+
+	sched.lastTick = time.Now()
+	server.store.Close()
+	if code, resp = getReadiness(t, server); code != http.StatusServiceUnavailable || resp.Checks["database"].Error == "" {
+		t.Fatalf("expected an unwritable database to fail readiness, got %d %+v", code, resp)
+	}
+}
@@ -5,9 +5,11 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -22,6 +24,7 @@ import (
 	"github.com/A2gent/brute/internal/agent"
 	"github.com/A2gent/brute/internal/config"
 	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/notify"
 	"github.com/A2gent/brute/internal/session"
 	"github.com/A2gent/brute/internal/storage"
 	"github.com/A2gent/brute/internal/tools/integrationtools"
@@ -35,6 +38,7 @@ var supportedIntegrationProviders = map[string]struct{}{
 	"discord":         {},
 	"whatsapp":        {},
 	"webhook":         {},
+	"email":           {},
 	"x":               {},
 	"elevenlabs":      {},
 	"google_calendar": {},
@@ -55,6 +59,7 @@ var requiredConfigFields = map[string][]string{
 	"discord":         {"bot_token", "channel_id"},
 	"whatsapp":        {"access_token", "phone_number_id", "recipient"},
 	"webhook":         {"url"},
+	"email":           {"smtp_host", "smtp_port", "username", "password", "from", "to"},
 	"x":               {"api_key", "api_secret", "access_token", "access_token_secret"},
 	"elevenlabs":      {"api_key"},
 	"google_calendar": {"client_id", "client_secret", "refresh_token"},
@@ -107,6 +112,10 @@ type TelegramChatCandidate struct {
 }
 
 const telegramLastUpdateIDConfigKey = "last_update_id"
+
+// telegramDetachedMetadataKey marks a session /new replaced; it keeps its
+// chat metadata but inbound messages no longer continue it.
+const telegramDetachedMetadataKey = "telegram_detached"
 const telegramNextPollAtConfigKey = "next_poll_at_unix"
 const telegramSyncedMessageCountMetadataKey = "telegram_synced_message_count"
 const myMindProjectName = "My Mind"
@@ -229,9 +238,10 @@ func (s *Server) handleListIntegrations(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	reveal := s.revealSecretsRequested(r)
 	resp := make([]IntegrationResponse, len(integrations))
 	for i, integration := range integrations {
-		resp[i] = integrationToResponse(integration)
+		resp[i] = integrationToResponse(integration, reveal)
 	}
 
 	s.jsonResponse(w, http.StatusOK, resp)
@@ -261,7 +271,7 @@ func (s *Server) handleCreateIntegration(w http.ResponseWriter, r *http.Request)
 	}
 
 	s.reconcileA2ATunnelAfterIntegrationSave(integration.Provider)
-	s.jsonResponse(w, http.StatusCreated, integrationToResponse(integration))
+	s.jsonResponse(w, http.StatusCreated, integrationToResponse(integration, false))
 }
 
 func (s *Server) handleGetIntegration(w http.ResponseWriter, r *http.Request) {
@@ -273,7 +283,7 @@ func (s *Server) handleGetIntegration(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.jsonResponse(w, http.StatusOK, integrationToResponse(integration))
+	s.jsonResponse(w, http.StatusOK, integrationToResponse(integration, s.revealSecretsRequested(r)))
 }
 
 func (s *Server) handleUpdateIntegration(w http.ResponseWriter, r *http.Request) {
@@ -291,6 +301,10 @@ func (s *Server) handleUpdateIntegration(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// Edit forms send back the masked values they were given; keep the stored
+	// secret for any field the user did not change.
+	restoreMaskedSecrets(req.Config, existing.Config)
+
 	next, err := newIntegrationFromRequest(req)
 	if err != nil {
 		s.errorResponse(w, http.StatusBadRequest, err.Error())
@@ -307,7 +321,7 @@ func (s *Server) handleUpdateIntegration(w http.ResponseWriter, r *http.Request)
 	}
 
 	s.reconcileA2ATunnelAfterIntegrationSave(next.Provider)
-	s.jsonResponse(w, http.StatusOK, integrationToResponse(next))
+	s.jsonResponse(w, http.StatusOK, integrationToResponse(next, false))
 }
 
 func (s *Server) handleDeleteIntegration(w http.ResponseWriter, r *http.Request) {
@@ -352,6 +366,21 @@ func (s *Server) handleTestIntegration(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if integration.Provider == "email" {
+		// send_test_message=true also mails a test message to the recipients.
+		sendTestMessage, _ := strconv.ParseBool(r.URL.Query().Get("send_test_message"))
+		if err := notify.CheckEmail(r.Context(), integration.Config, sendTestMessage); err != nil {
+			s.jsonResponse(w, http.StatusBadGateway, IntegrationTestResponse{Success: false, Message: err.Error()})
+			return
+		}
+		message := "Connected and authenticated with the SMTP server."
+		if sendTestMessage {
+			message = "Test email sent to " + integration.Config["to"] + "."
+		}
+		s.jsonResponse(w, http.StatusOK, IntegrationTestResponse{Success: true, Message: message})
+		return
+	}
+
 	s.jsonResponse(w, http.StatusOK, IntegrationTestResponse{Success: true, Message: "Configuration is valid. Live provider connectivity checks are not yet implemented."})
 }
 
@@ -683,9 +712,9 @@ func (s *Server) processTelegramDuplexIntegrations(ctx context.Context) {
 			}
 			messageChatID := strconv.FormatInt(message.Chat.ID, 10)
 			chatType := strings.ToLower(strings.TrimSpace(message.Chat.Type))
-			if chatType != "group" && chatType != "supergroup" {
+			if !telegramChatAllowed(integration, messageChatID, chatType) {
 				logging.Debug(
-					"Telegram update skipped for integration %s: chat type filter (chat=%s type=%s update=%d)",
+					"Telegram update skipped for integration %s: unknown chat (chat=%s type=%s update=%d)",
 					integration.ID,
 					messageChatID,
 					chatType,
@@ -1611,18 +1640,33 @@ func (s *Server) handleTelegramInboundMessage(
 	userImages []session.ImageAttachment,
 	userMessageMetadata map[string]interface{},
 ) (*telegramInboundResponse, error) {
+	chatID := strconv.FormatInt(chat.ID, 10)
+	scopeKey := telegramSessionScopeKey(integration, chatID, threadID)
+	persistent := telegramChatKeepsSession(integration, chat, threadID)
+
+	switch telegramCommand(userMessage) {
+	case "/new":
+		return &telegramInboundResponse{reply: s.detachTelegramSession(integration, chatID, scopeKey, threadID, persistent)}, nil
+	case "/status":
+		return &telegramInboundResponse{reply: s.telegramSessionStatus(integration, chatID, scopeKey, threadID, persistent)}, nil
+	}
 	if handled, reply := handleTelegramSlashCommand(userMessage); handled {
 		return &telegramInboundResponse{reply: reply}, nil
 	}
 
-	chatID := strconv.FormatInt(chat.ID, 10)
-	scopeKey := telegramSessionScopeKey(integration, chatID, threadID)
+	// A job run that sent its question to this chat takes the reply
+	if sess, err := s.findJobQuestionSession(integration.ID, chatID, ""); err != nil {
+		return nil, err
+	} else if sess != nil {
+		return &telegramInboundResponse{reply: s.answerJobQuestion(sess, userMessage, "Telegram"), sessionID: sess.ID}, nil
+	}
 
-	// For general chat (threadID == 0), always create new session
-	// For topics (threadID > 0), reuse existing session
+	// A group's general chat (threadID == 0) starts a new session, and a
+	// topic, unless every message should land in one session per chat.
+	// Topics, private chats and session_scope=chat reuse their session.
 	var sess *session.Session
 	var err error
-	if threadID == 0 {
+	if !persistent {
 		logging.Info("General chat message (threadID=0), forcing new session creation")
 		sess = nil // Force new session creation
 	} else {
@@ -1667,7 +1711,7 @@ func (s *Server) handleTelegramInboundMessage(
 		logging.Info("Telegram session evaluation: scope=%q threadID=%d scope_not_chat=%v threadID_zero=%v will_create_topic=%v",
 			scope, threadID, scope != "chat", threadID == 0, threadID == 0 && scope != "chat")
 
-		if threadID == 0 && scope != "chat" {
+		if !persistent {
 			botToken := strings.TrimSpace(integration.Config["bot_token"])
 			if botToken != "" {
 				topicName := telegramTopicNameForSession(sess, userMessage)
@@ -1726,18 +1770,23 @@ func (s *Server) handleTelegramInboundMessage(
 		return nil, fmt.Errorf("provider configuration error: %w", err)
 	}
 
+	agentDef := s.agentDefinition(sess, sess.AgentID)
 	agentConfig := agent.Config{
 		Name:          sess.AgentID,
 		Model:         target.Model,
 		SystemPrompt:  s.buildSystemPromptForSession(sess),
 		MaxSteps:      s.config.MaxSteps,
-		Temperature:   s.config.Temperature,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   s.resolveSessionTemperature(sess, agentDef),
 		ContextWindow: target.ContextWindow,
 	}
+	agentDef.Apply(&agentConfig)
 	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
 
 	response, _, err := ag.Run(ctx, sess, llmUserMessage)
-	if err != nil {
+	if err != nil && !errors.Is(err, agent.ErrMaxDurationExceeded) {
 		sess.AddAssistantMessage(fmt.Sprintf("Request failed: %s", err.Error()), nil)
 		sess.SetStatus(session.StatusFailed)
 		_ = s.sessionManager.Save(sess)
@@ -1757,22 +1806,105 @@ func handleTelegramSlashCommand(text string) (bool, string) {
 		return false, ""
 	}
 
-	parts := strings.Fields(trimmed)
-	if len(parts) == 0 {
+	switch telegramCommand(trimmed) {
+	case "":
 		return true, "Send a normal text message to start an agent task."
+	case "/start", "/help":
+		return true, "Telegram connected. Send a normal text message in this chat/topic to run an agent task. /new starts a fresh session, /status shows the current one."
+	default:
+		return true, "Command received. Send a normal text message to run an agent task."
 	}
+}
 
+// telegramCommand returns the lowercased bot command a message starts with,
+// without any @botname suffix, or "" when it is not a command.
+func telegramCommand(text string) string {
+	parts := strings.Fields(strings.TrimSpace(text))
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "/") {
+		return ""
+	}
 	cmd := strings.ToLower(parts[0])
 	if at := strings.IndexByte(cmd, '@'); at >= 0 {
 		cmd = cmd[:at]
 	}
+	if cmd == "/" {
+		return ""
+	}
+	return cmd
+}
 
-	switch cmd {
-	case "/start", "/help":
-		return true, "Telegram connected. Send a normal text message in this chat/topic to run an agent task."
-	default:
-		return true, "Command received. Send a normal text message to run an agent task."
+// telegramChatAllowed reports whether inbound messages from a chat are
+// handled. With allowed_chat_ids or default_chat_id configured only those
+// chats are; otherwise any group is, as before chats could be listed.
+func telegramChatAllowed(integration *storage.Integration, chatID string, chatType string) bool {
+	allowed := telegramAllowedChatIDs(integration)
+	if len(allowed) > 0 {
+		return allowed[chatID]
 	}
+	return chatType == "group" || chatType == "supergroup"
+}
+
+func telegramAllowedChatIDs(integration *storage.Integration) map[string]bool {
+	allowed := make(map[string]bool)
+	for _, key := range []string{"allowed_chat_ids", "default_chat_id"} {
+		for _, id := range strings.Split(integration.Config[key], ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				allowed[id] = true
+			}
+		}
+	}
+	return allowed
+}
+
+// telegramChatKeepsSession reports whether messages continue the chat's
+// existing session rather than each starting a new one.
+func telegramChatKeepsSession(integration *storage.Integration, chat telegramChatPayload, threadID int64) bool {
+	if threadID > 0 || strings.EqualFold(strings.TrimSpace(chat.Type), "private") {
+		return true
+	}
+	return strings.ToLower(strings.TrimSpace(integration.Config["session_scope"])) == "chat"
+}
+
+// detachTelegramSession handles /new: the chat's current session keeps its
+// history but is no longer continued, so the next message starts afresh.
+func (s *Server) detachTelegramSession(integration *storage.Integration, chatID string, scopeKey string, threadID int64, persistent bool) string {
+	if !persistent {
+		return "Every message in this chat already starts a new session."
+	}
+	sess, err := s.findTelegramSession(integration.ID, chatID, scopeKey, threadID)
+	if err != nil {
+		logging.Warn("Telegram /new failed for integration %s: %v", integration.ID, err)
+		return "Could not look up the current session. Please try again."
+	}
+	if sess == nil {
+		return "No session yet. Your next message starts one."
+	}
+	sess.Metadata[telegramDetachedMetadataKey] = true
+	if err := s.sessionManager.Save(sess); err != nil {
+		logging.Warn("Telegram /new failed to detach session %s: %v", sess.ID, err)
+		return "Could not start a new session. Please try again."
+	}
+	return fmt.Sprintf("Started a new session. Your next message begins a fresh conversation (previous session: %s).", sess.ID)
+}
+
+// telegramSessionStatus handles /status.
+func (s *Server) telegramSessionStatus(integration *storage.Integration, chatID string, scopeKey string, threadID int64, persistent bool) string {
+	if !persistent {
+		return "Each message in this chat starts a new session (set session_scope=chat to keep one)."
+	}
+	sess, err := s.findTelegramSession(integration.ID, chatID, scopeKey, threadID)
+	if err != nil {
+		logging.Warn("Telegram /status failed for integration %s: %v", integration.ID, err)
+		return "Could not look up the current session. Please try again."
+	}
+	if sess == nil {
+		return "No session yet. Send a message to start one."
+	}
+	title := strings.TrimSpace(sess.Title)
+	if title == "" {
+		title = "untitled"
+	}
+	return fmt.Sprintf("Session %s (%s): %s, %d messages, last active %s.", sess.ID, title, sess.Status, len(sess.Messages), sess.UpdatedAt.Format("2006-01-02 15:04 MST"))
 }
 
 func (s *Server) findTelegramSession(integrationID string, chatID string, scopeKey string, threadID int64) (*session.Session, error) {
@@ -1793,6 +1925,9 @@ func (s *Server) findTelegramSession(integrationID string, chatID string, scopeK
 		if metadataString(sess.Metadata["telegram_chat_id"]) != chatID {
 			continue
 		}
+		if detached, _ := sess.Metadata[telegramDetachedMetadataKey].(bool); detached {
+			continue
+		}
 		if scopeKey != "" {
 			existingScope := metadataString(sess.Metadata["telegram_scope_key"])
 			if existingScope != "" && existingScope != scopeKey {
@@ -2933,6 +3068,9 @@ func validateIntegration(integration storage.Integration) error {
 	if integration.Provider == "webhook" && integration.Mode == "duplex" {
 		return fmt.Errorf("webhook currently supports notify_only mode")
 	}
+	if integration.Provider == "email" && integration.Mode == "duplex" {
+		return fmt.Errorf("email currently supports notify_only mode")
+	}
 	if integration.Provider == "x" && integration.Mode == "duplex" {
 		return fmt.Errorf("x currently supports notify_only mode")
 	}
@@ -2961,11 +3099,22 @@ func validateIntegration(integration storage.Integration) error {
 			return fmt.Errorf("unsupported a2_registry transport: %s", transport)
 		}
 	}
+	if integration.Provider == "email" {
+		if err := notify.ValidateEmailConfig(integration.Config); err != nil {
+			return err
+		}
+	}
+	if integration.Provider == "slack" && integration.Mode == "duplex" && strings.TrimSpace(integration.Config["signing_secret"]) == "" {
+		return fmt.Errorf("missing required config field: signing_secret (needed to verify Slack events)")
+	}
 	if integration.Provider == "webhook" {
 		url := strings.ToLower(strings.TrimSpace(integration.Config["url"]))
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			return fmt.Errorf("webhook url must start with http:// or https://")
 		}
+		if err := notify.ValidateEvents(integration.Config["events"]); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -2983,9 +3132,14 @@ func trimConfig(config map[string]string) map[string]string {
 	return out
 }
 
-func integrationToResponse(integration *storage.Integration) IntegrationResponse {
+// integrationToResponse converts a stored integration for the API. Secret
+// config values are masked unless reveal is set.
+func integrationToResponse(integration *storage.Integration, reveal bool) IntegrationResponse {
 	configCopy := make(map[string]string, len(integration.Config))
 	for key, value := range integration.Config {
+		if !reveal && storage.IsSecretConfigKey(key) {
+			value = maskSecretValue(value)
+		}
 		configCopy[key] = value
 	}
 
@@ -3001,6 +3155,54 @@ func integrationToResponse(integration *storage.Integration) IntegrationResponse
 	}
 }
 
+// secretMask prefixes masked config values in API responses.
+const secretMask = "••••"
+
+// maskSecretValue hides a secret, keeping the last four characters of longer
+// values so users can tell credentials apart.
+func maskSecretValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	runes := []rune(value)
+	if len(runes) <= 8 {
+		return secretMask
+	}
+	return secretMask + string(runes[len(runes)-4:])
+}
+
+// restoreMaskedSecrets replaces submitted values that are exactly the mask of
+// the stored value with the stored value.
+func restoreMaskedSecrets(submitted, stored map[string]string) {
+	for key, value := range submitted {
+		if !storage.IsSecretConfigKey(key) || !strings.HasPrefix(value, secretMask) {
+			continue
+		}
+		if current, ok := stored[key]; ok && maskSecretValue(current) == value {
+			submitted[key] = current
+		}
+	}
+}
+
+// revealSecretsRequested reports whether the caller asked for unmasked secrets
+// with ?reveal=true and is allowed to see them: the request must carry a
+// valid API token or come from a loopback client.
+func (s *Server) revealSecretsRequested(r *http.Request) bool {
+	if reveal, _ := strconv.ParseBool(r.URL.Query().Get("reveal")); !reveal {
+		return false
+	}
+	return apiTokenName(r) != "" || isLoopbackRequest(r)
+}
+
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 func defaultIntegrationName(provider string) string {
 	switch provider {
 	case "telegram":
@@ -3013,6 +3215,8 @@ func defaultIntegrationName(provider string) string {
 		return "WhatsApp"
 	case "webhook":
 		return "Webhook"
+	case "email":
+		return "Email"
 	case "x":
 		return "X"
 	case "google_calendar":
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"testing"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestValidateIntegrationEmail(t *testing.T) {
+	t.Parallel()
+
+	config := func(overrides map[string]string) map[string]string {
+		cfg := map[string]string{
+			"smtp_host": "smtp.example.com",
+			"smtp_port": "587",
+			"username":  "bot",
+			"password":  "secret",
+			"from":      "A2gent <bot@example.com>",
+			"to":        "ops@example.com",
+		}
+		for key, value := range overrides {
+			cfg[key] = value
+		}
+		return cfg
+	}
+
+	if err := validateIntegration(storage.Integration{Provider: "email", Mode: "notify_only", Config: config(nil)}); err != nil {
+		t.Fatalf("expected valid integration, got %v", err)
+	}
+	for name, integration := range map[string]storage.Integration{
+		"duplex":       {Provider: "email", Mode: "duplex", Config: config(nil)},
+		"missing host": {Provider: "email", Mode: "notify_only", Config: config(map[string]string{"smtp_host": ""})},
+		"bad port":     {Provider: "email", Mode: "notify_only", Config: config(map[string]string{"smtp_port": "abc"})},
+		"bad to":       {Provider: "email", Mode: "notify_only", Config: config(map[string]string{"to": "ops"})},
+	} {
+		if err := validateIntegration(integration); err == nil {
+			t.Fatalf("%s: expected validation error", name)
+		}
+	}
+}
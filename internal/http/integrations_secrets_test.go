@@ -0,0 +1,78 @@
+package http
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestIntegrationResponseMasksSecrets(t *testing.T) {
+	t.Parallel()
+
+	integration := &storage.Integration{
+		Provider: "telegram",
+		Config: map[string]string{
+			"bot_token": "123456:ABCDEFGH1234",
+			"api_key":   "short",
+			"chat_id":   "42",
+		},
+	}
+
+	masked := integrationToResponse(integration, false)
+	if got := masked.Config["bot_token"]; got != "••••1234" {
+		t.Fatalf("bot_token masked as %q", got)
+	}
+	if got := masked.Config["api_key"]; got != "••••" {
+		t.Fatalf("short api_key masked as %q", got)
+	}
+	if got := masked.Config["chat_id"]; got != "42" {
+		t.Fatalf("non-secret chat_id should be unchanged, got %q", got)
+	}
+	if integration.Config["bot_token"] != "123456:ABCDEFGH1234" {
+		t.Fatal("masking must not modify the stored integration")
+	}
+
+	revealed := integrationToResponse(integration, true)
+	if got := revealed.Config["bot_token"]; got != "123456:ABCDEFGH1234" {
+		t.Fatalf("revealed bot_token = %q", got)
+	}
+}
+
+func TestRestoreMaskedSecretsKeepsStoredValues(t *testing.T) {
+	t.Parallel()
+
+	stored := map[string]string{"bot_token": "123456:ABCDEFGH1234", "chat_id": "42"}
+	submitted := map[string]string{"bot_token": "••••1234", "chat_id": "43"}
+	restoreMaskedSecrets(submitted, stored)
+	if submitted["bot_token"] != "123456:ABCDEFGH1234" || submitted["chat_id"] != "43" {
+		t.Fatalf("unexpected restored config: %v", submitted)
+	}
+
+	replaced := map[string]string{"bot_token": "new-token-value"}
+	restoreMaskedSecrets(replaced, stored)
+	if replaced["bot_token"] != "new-token-value" {
+		t.Fatalf("new secret must not be replaced, got %q", replaced["bot_token"])
+	}
+}
+
+func TestRevealSecretsRequiresLoopback(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{}
+	local := httptest.NewRequest("GET", "/integrations/x?reveal=true", nil)
+	local.RemoteAddr = "127.0.0.1:5000"
+	if !s.revealSecretsRequested(local) {
+		t.Fatal("loopback reveal should be allowed")
+	}
+	remote := httptest.NewRequest("GET", "/integrations/x?reveal=true", nil)
+	remote.RemoteAddr = "203.0.113.7:5000"
+	if s.revealSecretsRequested(remote) {
+		t.Fatal("remote reveal must be refused")
+	}
+	plain := httptest.NewRequest("GET", "/integrations/x", nil)
+	plain.RemoteAddr = "127.0.0.1:5000"
+	if s.revealSecretsRequested(plain) {
+		t.Fatal("reveal must be opt-in")
+	}
+}
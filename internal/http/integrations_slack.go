@@ -0,0 +1,293 @@
+package http
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/notify"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// slackEventsPath receives Events API callbacks and slash commands. Slack
+// cannot send an API token, so requests are authenticated by their signature.
+const slackEventsPath = "/integrations/slack/events"
+
+const (
+	// slackRequestMaxAge rejects replayed requests, as Slack recommends.
+	slackRequestMaxAge  = 5 * time.Minute
+	slackMaxRequestBody = 1 << 20
+)
+
+var slackMentionPattern = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+type slackEventEnvelope struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type     string `json:"type"`
+		User     string `json:"user"`
+		BotID    string `json:"bot_id"`
+		Text     string `json:"text"`
+		Channel  string `json:"channel"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+// handleSlackEvents answers Slack within its three second deadline and runs
+// the agent afterwards, posting the final answer in the message's thread.
+func (s *Server) handleSlackEvents(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, slackMaxRequestBody))
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	integration := s.slackIntegrationForRequest(r.Header, body, time.Now())
+	if integration == nil {
+		s.errorResponse(w, http.StatusUnauthorized, "Invalid Slack signature")
+		return
+	}
+
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		s.handleSlackSlashCommand(w, integration, body)
+		return
+	}
+
+	var envelope slackEventEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid Slack event payload")
+		return
+	}
+	switch envelope.Type {
+	case "url_verification":
+		s.jsonResponse(w, http.StatusOK, map[string]string{"challenge": envelope.Challenge})
+		return
+	case "event_callback":
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	event := envelope.Event
+	// Slack retries callbacks it thinks timed out; the first delivery is
+	// already being answered. Bot messages would make the agent talk to itself.
+	if r.Header.Get("X-Slack-Retry-Num") != "" || event.Type != "app_mention" || event.BotID != "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	threadTS := event.ThreadTS
+	if threadTS == "" {
+		threadTS = event.TS
+	}
+	text := strings.TrimSpace(slackMentionPattern.ReplaceAllString(event.Text, ""))
+	w.WriteHeader(http.StatusOK)
+	if text == "" {
+		return
+	}
+	go s.answerSlackMessage(context.Background(), integration, event.Channel, threadTS, text)
+}
+
+// handleSlackSlashCommand acknowledges the command, then posts the question
+// to the channel and answers it in that message's thread.
+func (s *Server) handleSlackSlashCommand(w http.ResponseWriter, integration *storage.Integration, body []byte) {
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid Slack command payload")
+		return
+	}
+	command := form.Get("command")
+	text := strings.TrimSpace(form.Get("text"))
+	channel := form.Get("channel_id")
+	if text == "" {
+		s.jsonResponse(w, http.StatusOK, map[string]string{"response_type": "ephemeral", "text": fmt.Sprintf("Usage: %s <task for the agent>", command)})
+		return
+	}
+	s.jsonResponse(w, http.StatusOK, map[string]string{"response_type": "ephemeral", "text": "Working on it…"})
+
+	go func() {
+		ctx := context.Background()
+		question := text
+		if user := form.Get("user_id"); user != "" {
+			question = fmt.Sprintf("<@%s> asked: %s", user, text)
+		}
+		threadTS, err := notify.PostSlackMessage(ctx, integration.Config, channel, "", question)
+		if err != nil {
+			logging.Warn("Slack command %s could not be posted to %s: %v", command, channel, err)
+			return
+		}
+		s.answerSlackMessage(ctx, integration, channel, threadTS, text)
+	}()
+}
+
+// answerSlackMessage runs the agent in the session bound to the Slack thread
+// and posts the reply, or the failure, in that thread.
+func (s *Server) answerSlackMessage(ctx context.Context, integration *storage.Integration, channel string, threadTS string, text string) {
+	reply, err := s.runSlackSession(ctx, integration, channel, threadTS, text)
+	if err != nil {
+		logging.Warn("Slack request in %s/%s failed: %v", channel, threadTS, err)
+		reply = "I couldn't process that request. " + truncateRunes(err.Error(), 350)
+	}
+	if strings.TrimSpace(reply) == "" {
+		reply = "Done."
+	}
+	for _, part := range splitTelegramText(reply, notify.SlackMaxMessageRunes) {
+		if _, err := notify.PostSlackMessage(ctx, integration.Config, channel, threadTS, part); err != nil {
+			logging.Warn("Failed to post Slack reply in %s/%s: %v", channel, threadTS, err)
+			return
+		}
+	}
+}
+
+func (s *Server) runSlackSession(ctx context.Context, integration *storage.Integration, channel string, threadTS string, text string) (string, error) {
+	// Replies in the thread of a job run's question answer it
+	if sess, err := s.findJobQuestionSession(integration.ID, channel, threadTS); err != nil {
+		return "", err
+	} else if sess != nil {
+		return s.answerJobQuestion(sess, text, "Slack"), nil
+	}
+
+	sess, err := s.findSlackSession(integration.ID, channel, threadTS)
+	if err != nil {
+		return "", err
+	}
+	if sess == nil {
+		sess, err = s.sessionManager.Create("build")
+		if err != nil {
+			return "", fmt.Errorf("failed to create Slack session: %w", err)
+		}
+		if sess.Metadata == nil {
+			sess.Metadata = map[string]interface{}{}
+		}
+		providerType := config.NormalizeProviderRef(strings.TrimSpace(s.config.ActiveProvider))
+		autoCfg := s.config.Providers[string(config.ProviderAutoRouter)]
+		if s.autoRouterConfigured(autoCfg) {
+			providerType = string(config.ProviderAutoRouter)
+		}
+		sess.Metadata["provider"] = providerType
+		sess.Metadata["model"] = s.resolveModelForProvider(config.ProviderType(providerType))
+		sess.Metadata["integration_provider"] = "slack"
+		sess.Metadata["integration_id"] = integration.ID
+		sess.Metadata["slack_channel"] = channel
+		sess.Metadata["slack_thread_ts"] = threadTS
+		if err := s.sessionManager.Save(sess); err != nil {
+			logging.Warn("Failed to persist new Slack session metadata: %v", err)
+		}
+	}
+
+	sess.AddUserMessage(text)
+	providerType := s.resolveSessionProviderType(sess)
+	model := s.resolveSessionModel(sess, providerType)
+	target, err := s.resolveExecutionTarget(ctx, providerType, model, text, sess)
+	if err != nil {
+		sess.AddAssistantMessage(fmt.Sprintf("Unable to start request: %s", err.Error()), nil)
+		sess.SetStatus(session.StatusFailed)
+		_ = s.sessionManager.Save(sess)
+		return "", fmt.Errorf("provider configuration error: %w", err)
+	}
+
+	agentDef := s.agentDefinition(sess, sess.AgentID)
+	agentConfig := agent.Config{
+		Name:          sess.AgentID,
+		Model:         target.Model,
+		SystemPrompt:  s.buildSystemPromptForSession(sess),
+		MaxSteps:      s.config.MaxSteps,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   s.resolveSessionTemperature(sess, agentDef),
+		ContextWindow: target.ContextWindow,
+	}
+	agentDef.Apply(&agentConfig)
+	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
+
+	runCtx, cancelRun := context.WithCancel(ctx)
+	runID := s.registerActiveSessionRun(sess.ID, cancelRun)
+	defer func() {
+		cancelRun()
+		s.unregisterActiveSessionRun(sess.ID, runID)
+	}()
+	response, _, err := ag.Run(runCtx, sess, text)
+	if err != nil && !errors.Is(err, agent.ErrMaxDurationExceeded) {
+		sess.AddAssistantMessage(fmt.Sprintf("Request failed: %s", err.Error()), nil)
+		sess.SetStatus(session.StatusFailed)
+		_ = s.sessionManager.Save(sess)
+		return "", fmt.Errorf("agent run failed: %w", err)
+	}
+	return response, nil
+}
+
+// findSlackSession returns the session started in a Slack thread, if any.
+func (s *Server) findSlackSession(integrationID string, channel string, threadTS string) (*session.Session, error) {
+	sessions, err := s.sessionManager.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, sess := range sessions {
+		if metadataString(sess.Metadata["integration_provider"]) != "slack" ||
+			metadataString(sess.Metadata["integration_id"]) != integrationID ||
+			metadataString(sess.Metadata["slack_channel"]) != channel ||
+			metadataString(sess.Metadata["slack_thread_ts"]) != threadTS {
+			continue
+		}
+		return s.sessionManager.Get(sess.ID)
+	}
+	return nil, nil
+}
+
+// slackIntegrationForRequest returns the enabled duplex Slack integration
+// whose signing secret produced the request signature.
+func (s *Server) slackIntegrationForRequest(header http.Header, body []byte, now time.Time) *storage.Integration {
+	integrations, err := s.store.ListIntegrations()
+	if err != nil {
+		logging.Warn("Failed to list integrations for Slack request: %v", err)
+		return nil
+	}
+	for _, integration := range integrations {
+		if integration.Provider != "slack" || integration.Mode != "duplex" || !integration.Enabled {
+			continue
+		}
+		if verifySlackSignature(integration.Config["signing_secret"], header, body, now) {
+			return integration
+		}
+	}
+	return nil
+}
+
+// verifySlackSignature checks the v0 request signature Slack computes over
+// the timestamp and raw body with the app's signing secret.
+func verifySlackSignature(secret string, header http.Header, body []byte, now time.Time) bool {
+	secret = strings.TrimSpace(secret)
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	signature := header.Get("X-Slack-Signature")
+	if secret == "" || timestamp == "" || signature == "" {
+		return false
+	}
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > slackRequestMaxAge || age < -slackRequestMaxAge {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func signedSlackRequest(secret, contentType, body string) *http.Request {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + body))
+	req := httptest.NewRequest(http.MethodPost, slackEventsPath, strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("X-Slack-Request-Timestamp", timestamp)
+	req.Header.Set("X-Slack-Signature", "v0="+hex.EncodeToString(mac.Sum(nil)))
+	return req
+}
+
+func TestSlackEvents(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	posted := make(chan map[string]interface{}, 4)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&payload)
+		posted <- payload
+		_, _ = w.Write([]byte(`{"ok":true,"ts":"1700000000.000200"}`))
+	}))
+	defer api.Close()
+
+	now := time.Now()
+	if err := server.store.SaveIntegration(&storage.Integration{ID: "slack", Provider: "slack", Name: "Slack", Mode: "duplex", Enabled: true, Config: map[string]string{"bot_token": "xoxb-1", "channel_id": "C1", "signing_secret": "shh", "api_base_url": api.URL}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveIntegration: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, signedSlackRequest("wrong", "application/json", `{"type":"url_verification","challenge":"abc"}`))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected a bad signature to be rejected, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, signedSlackRequest("shh", "application/json", `{"type":"url_verification","challenge":"abc"}`))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"challenge":"abc"`) {
+		t.Fatalf("url_verification: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, signedSlackRequest("shh", "application/x-www-form-urlencoded", "command=%2Fagent&text=&channel_id=C1"))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Usage: /agent") {
+		t.Fatalf("empty slash command: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	event := `{"type":"event_callback","event":{"type":"app_mention","user":"U1","text":"<@UBOT> summarize today","channel":"C9","ts":"1700000000.000100"}}`
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, signedSlackRequest("shh", "application/json", event))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("app_mention: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	select {
+	case payload := <-posted:
+		if payload["channel"] != "C9" || payload["thread_ts"] != "1700000000.000100" {
+			t.Fatalf("expected a reply in the mention's thread, got %+v", payload)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("no reply was posted for the mention")
+	}
+	sess, err := server.findSlackSession("slack", "C9", "1700000000.000100")
+	if err != nil || sess == nil {
+		t.Fatalf("expected a session bound to the thread, got %v, %v", sess, err)
+	}
+	if len(sess.Messages) == 0 || sess.Messages[0].Content != "summarize today" {
+		t.Fatalf("unexpected session messages %+v", sess.Messages)
+	}
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestTelegramCommand(t *testing.T) {
+	tests := map[string]string{
+		"/new":             "/new",
+		"/Status@my_bot":   "/status",
+		"  /new please":    "/new",
+		"hello /new":       "",
+		"/":                "",
+		"plain text":       "",
+		"/help@other_bot ": "/help",
+	}
+	for text, want := range tests {
+		if got := telegramCommand(text); got != want {
+			t.Fatalf("telegramCommand(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
+
+func TestTelegramChatAllowed(t *testing.T) {
+	unconfigured := &storage.Integration{Config: map[string]string{}}
+	if !telegramChatAllowed(unconfigured, "-100", "supergroup") || telegramChatAllowed(unconfigured, "42", "private") {
+		t.Fatal("without configured chats only groups should be handled")
+	}
+
+	configured := &storage.Integration{Config: map[string]string{"default_chat_id": "42", "allowed_chat_ids": "-100, -200"}}
+	for chatID, want := range map[string]bool{"42": true, "-100": true, "-200": true, "-300": false, "7": false} {
+		if got := telegramChatAllowed(configured, chatID, "group"); got != want {
+			t.Fatalf("telegramChatAllowed(%s) = %v, want %v", chatID, got, want)
+		}
+	}
+}
+
+func TestTelegramSessionCommands(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	integration := &storage.Integration{ID: "tg", Provider: "telegram", Mode: "duplex", Enabled: true, Config: map[string]string{"default_chat_id": "42"}}
+	chat := telegramChatPayload{ID: 42, Type: "private"}
+	send := func(text string) string {
+		t.Helper()
+		result, err := server.handleTelegramInboundMessage(context.Background(), integration, chat, 0, text, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		return result.reply
+	}
+
+	if reply := send("/status"); !strings.Contains(reply, "No session yet") {
+		t.Fatalf("unexpected /status reply %q", reply)
+	}
+
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	sess.Metadata["integration_provider"] = "telegram"
+	sess.Metadata["integration_id"] = integration.ID
+	sess.Metadata["telegram_chat_id"] = "42"
+	sess.Metadata["telegram_scope_key"] = "42"
+	sess.AddUserMessage("hello")
+	if err := sessionManager.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	if reply := send("/status@my_bot"); !strings.Contains(reply, sess.ID) || !strings.Contains(reply, "1 messages") {
+		t.Fatalf("unexpected /status reply %q", reply)
+	}
+	if reply := send("/new"); !strings.Contains(reply, sess.ID) {
+		t.Fatalf("unexpected /new reply %q", reply)
+	}
+	if found, err := server.findTelegramSession(integration.ID, "42", "42", 0); err != nil || found != nil {
+		t.Fatalf("expected the session to be detached, got %v, %v", found, err)
+	}
+	if reply := send("/status"); !strings.Contains(reply, "No session yet") {
+		t.Fatalf("unexpected /status reply after /new %q", reply)
+	}
+
+	groupChat := telegramChatPayload{ID: -100, Type: "supergroup"}
+	result, err := server.handleTelegramInboundMessage(context.Background(), integration, groupChat, 0, "/new", nil, nil)
+	if err != nil || !strings.Contains(result.reply, "already starts a new session") {
+		t.Fatalf("unexpected group /new reply %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,547 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/jobs"
+	"github.com/A2gent/brute/internal/schedule"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestJobTimezones(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	server, _ := newQuestionTestServer(t)
+
+	decode := func(body []byte) JobResponse {
+		t.Helper()
+		var resp JobResponse
+		if err := json.Unmarshal(body, &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"standup","schedule_text":"every weekday at 8:30am","task_prompt":"summarize","timezone":"America/New_York","enabled":true}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	job := decode(rec.Body.Bytes())
+	if job.Timezone != "America/New_York" || job.ScheduleCron != "30 8 * * 1-5" || job.ScheduleSummary != "every weekday at 08:30" {
+		t.Fatalf("unexpected job %+v", job)
+	}
+	if job.NextRunAt == nil {
+		t.Fatal("expected a next run")
+	}
+	next := job.NextRunAt.In(newYork)
+	if next.Hour() != 8 || next.Minute() != 30 || next.Weekday() == time.Saturday || next.Weekday() == time.Sunday {
+		t.Fatalf("next run %s is not a weekday at 08:30 in New York", next)
+	}
+
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"timezone":"Asia/Tokyo"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	if updated := decode(rec.Body.Bytes()); updated.Timezone != "Asia/Tokyo" || updated.NextRunAt.In(tokyo).Hour() != 8 {
+		t.Fatalf("next run not moved to Tokyo time: %+v", updated)
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"local","schedule_text":"every day at noon","task_prompt":"x"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create without timezone: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if got := decode(rec.Body.Bytes()).Timezone; got != schedule.DefaultTimezone() {
+		t.Fatalf("expected the server timezone %q, got %q", schedule.DefaultTimezone(), got)
+	}
+
+	for _, req := range []struct{ method, path, body string }{
+		{http.MethodPost, "/jobs", `{"name":"bad","schedule_text":"every day at noon","task_prompt":"x","timezone":"Moon/Base"}`},
+		{http.MethodPut, "/jobs/" + job.ID, `{"timezone":"Moon/Base"}`},
+	} {
+		rec := serveAuthorized(server, req.method, req.path, req.body)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "Europe/London") {
+			t.Fatalf("%s %s: expected a helpful 400, got %d body=%s", req.method, req.path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestJobSchedulePreview(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	rec := serveAuthorized(server, http.MethodPost, "/jobs/preview", `{"schedule_text":"every weekday at 8:30am","timezone":"UTC"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("preview: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var preview JobSchedulePreviewResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &preview); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if preview.ScheduleCron != "30 8 * * 1-5" || preview.ScheduleSummary != "every weekday at 08:30" || preview.Timezone != "UTC" || len(preview.NextRuns) != 5 {
+		t.Fatalf("unexpected preview %+v", preview)
+	}
+	for i, run := range preview.NextRuns {
+		if run.Hour() != 8 || run.Minute() != 30 || run.Weekday() == time.Saturday || run.Weekday() == time.Sunday {
+			t.Fatalf("run %d at %s is not a weekday at 08:30 UTC", i, run)
+		}
+		if i > 0 && !run.After(preview.NextRuns[i-1]) {
+			t.Fatalf("runs are not increasing: %v", preview.NextRuns)
+		}
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/jobs/preview", `{"schedule_text":"in 2 hours"}`)
+	if err := json.Unmarshal(rec.Body.Bytes(), &preview); err != nil || preview.RunAt == nil || len(preview.NextRuns) != 1 {
+		t.Fatalf("one-time preview: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/jobs/preview", `{"schedule_text":"0 25 * * *"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "above maximum (23)") {
+		t.Fatalf("bad cron: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if jobs, err := server.store.ListJobs(); err != nil || len(jobs) != 0 {
+		t.Fatalf("preview must not create jobs, got %d (%v)", len(jobs), err)
+	}
+}
+
+func TestJobRunSettings(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"report","schedule_text":"every day at noon","task_prompt":"x"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var job JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if job.TimeoutMinutes != 30 || job.AgentID != "job-runner" || job.Model != "" || job.FailOnQuestion {
+		t.Fatalf("expected default run settings, got %+v", job)
+	}
+
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"timeout_minutes":90,"model":"claude-haiku","agent_id":"explore","fail_on_question":true}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	stored, err := server.store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if stored.TimeoutMinutes != 90 || stored.Model != "claude-haiku" || stored.AgentID != "explore" || !stored.FailOnQuestion {
+		t.Fatalf("run settings not stored: %+v", stored)
+	}
+
+	for _, body := range []string{
+		`{"timeout_minutes":0}`,
+		`{"timeout_minutes":1441}`,
+		`{"agent_id":"no-such-agent"}`,
+	} {
+		rec := serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d body=%s", body, rec.Code, rec.Body.String())
+		}
+	}
+	rec = serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"bad","schedule_text":"every day at noon","task_prompt":"x","agent_id":"no-such-agent"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "explore") {
+		t.Fatalf("expected unknown agent to list alternatives, got %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestJobResponseSchema(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"deps","schedule_text":"every day at noon","task_prompt":"x","response_schema":{"type": "array", "items": {"type": "string"}}}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var job JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if string(job.ResponseSchema) != `{"type":"array","items":{"type":"string"}}` {
+		t.Fatalf("unexpected response_schema %s", job.ResponseSchema)
+	}
+
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"response_schema":"[1]"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("invalid schema: expected 400, got %d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"response_schema":""}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("clear: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	stored, err := server.store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if stored.ResponseSchema != "" {
+		t.Fatalf("schema was not cleared: %q", stored.ResponseSchema)
+	}
+}
+
+func TestOneShotJob(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"reminder","schedule_text":"tomorrow at 9am","task_prompt":"x","timezone":"UTC","enabled":true}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var job JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1)
+	want := time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 9, 0, 0, 0, time.UTC)
+	if job.ScheduleCron != "" || job.RunAt == nil || !job.RunAt.Equal(want) || job.NextRunAt == nil || !job.NextRunAt.Equal(want) {
+		t.Fatalf("expected a one-shot job at %s, got %+v", want, job)
+	}
+	if !strings.HasPrefix(job.ScheduleSummary, "once on ") {
+		t.Fatalf("unexpected summary %q", job.ScheduleSummary)
+	}
+
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"schedule_text":"every day at 9am"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	stored, err := server.store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if stored.ScheduleCron != "0 9 * * *" || stored.RunAt != nil {
+		t.Fatalf("expected the job to become recurring, got %+v", stored)
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"late","schedule_text":"2001-01-01 at 09:00","task_prompt":"x"}`)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "in the past") {
+		t.Fatalf("expected a past run time to be rejected, got %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestJobNotifySettings(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	now := time.Now()
+	for _, integration := range []*storage.Integration{
+		{ID: "hook", Provider: "webhook", Name: "Webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": "https://example.com/hook"}, CreatedAt: now, UpdatedAt: now},
+		{ID: "discord", Provider: "discord", Name: "Discord", Mode: "notify_only", Enabled: true, Config: map[string]string{"bot_token": "x", "channel_id": "c"}, CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := server.store.SaveIntegration(integration); err != nil {
+			t.Fatalf("SaveIntegration: %v", err)
+		}
+	}
+
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"report","schedule_text":"every day at noon","task_prompt":"x","notify_on":"Failure","notify_integration_ids":["hook"," hook "]}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var job JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if job.NotifyOn != "failure" || len(job.NotifyTargets) != 1 || job.NotifyTargets[0] != "hook" {
+		t.Fatalf("unexpected notification settings %+v", job)
+	}
+
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"notify_on":"always"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	stored, err := server.store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if stored.NotifyOn != "always" || len(stored.NotifyTargets) != 1 {
+		t.Fatalf("notification settings not stored: %+v", stored)
+	}
+
+	for _, body := range []string{
+		`{"notify_on":"sometimes"}`,
+		`{"notify_integration_ids":["missing"]}`,
+		`{"notify_integration_ids":["discord"]}`,
+		`{"notify_integration_ids":[]}`,
+	} {
+		rec := serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, body)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d body=%s", body, rec.Code, rec.Body.String())
+		}
+	}
+
+	rec = serveAuthorized(server, http.MethodPut, "/jobs/"+job.ID, `{"notify_on":"","notify_integration_ids":[]}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("turning notifications off: status %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+// fakeJobRunner records a running execution and leaves finishing it to the
+// test.
+type fakeJobRunner struct {
+	store   storage.Store
+	running bool
+}
+
+func (f *fakeJobRunner) StartRun(ctx context.Context, job *storage.RecurringJob) (*storage.JobExecution, error) {
+	if f.running {
+		return nil, fmt.Errorf("%w: %s", jobs.ErrAlreadyRunning, job.Name)
+	}
+	f.running = true
+	exec := &storage.JobExecution{ID: "exec-1", JobID: job.ID, Status: "running", StartedAt: time.Now()}
+	return exec, f.store.SaveJobExecution(exec)
+}
+
+func TestRunJobNowReturnsRunningExecution(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"report","schedule_text":"every day at noon","task_prompt":"x"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var job JobResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/jobs/"+job.ID+"/run", "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("run without a runner: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	server.SetJobRunner(&fakeJobRunner{store: server.store})
+	rec = serveAuthorized(server, http.MethodPost, "/jobs/"+job.ID+"/run", "")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("run: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var started JobExecutionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &started); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if started.ID == "" || started.Status != "running" {
+		t.Fatalf("expected a running execution, got %+v", started)
+	}
+	rec = serveAuthorized(server, http.MethodPost, "/jobs/"+job.ID+"/run", "")
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("run while running: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	execPath := "/jobs/" + job.ID + "/executions/" + started.ID
+	finishedAt := time.Now()
+	if err := server.store.SaveJobExecution(&storage.JobExecution{ID: started.ID, JobID: job.ID, Status: "success", Output: "done", StartedAt: started.StartedAt, FinishedAt: &finishedAt}); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+	rec = serveAuthorized(server, http.MethodGet, execPath, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get execution: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var polled JobExecutionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &polled); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if polled.Status != "success" || polled.Output != "done" || polled.FinishedAt == nil {
+		t.Fatalf("expected the finished execution, got %+v", polled)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/jobs/other-job/executions/"+started.ID, "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("execution of another job: status %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestJobExecutionFullOutput(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	server.config.DataPath = t.TempDir()
+	job := &storage.RecurringJob{ID: "job-1", Name: "weekly", ScheduleHuman: "every monday", ScheduleCron: "0 9 * * 1", TaskPrompt: "summarize", TaskPromptSource: "text", Enabled: true}
+	if err := server.store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+
+	full := strings.Repeat("commit ", 20) + "é"
+	finishedAt := time.Now()
+	exec := &storage.JobExecution{ID: "exec-1", JobID: job.ID, Status: "success", StartedAt: finishedAt.Add(-time.Minute), FinishedAt: &finishedAt}
+	if err := jobs.RecordOutput(server.config.DataPath, exec, full, len(full)-1); err != nil {
+		t.Fatalf("RecordOutput: %v", err)
+	}
+	if err := server.store.SaveJobExecution(exec); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/jobs/job-1/executions/exec-1", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("get execution: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp JobExecutionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !strings.HasSuffix(resp.Output, "(truncated)") || strings.Contains(resp.Output, "é") {
+		t.Fatalf("expected a summary cut before the last character, got %q", resp.Output)
+	}
+	if resp.OutputURL != "/jobs/job-1/executions/exec-1/output" {
+		t.Fatalf("unexpected output_url %q", resp.OutputURL)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, resp.OutputURL, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != full {
+		t.Fatalf("full output: status %d body=%q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	// Executions recorded before output files existed serve their summary
+	legacy := &storage.JobExecution{ID: "exec-0", JobID: job.ID, Status: "success", Output: "short", StartedAt: finishedAt, FinishedAt: &finishedAt}
+	if err := server.store.SaveJobExecution(legacy); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+	rec = serveAuthorized(server, http.MethodGet, "/jobs/job-1/executions/exec-0/output", "")
+	if rec.Code != http.StatusOK || rec.Body.String() != "short" {
+		t.Fatalf("legacy output: status %d body=%q", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPruneJobExecutionsEndpoint(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	rec := serveAuthorized(server, http.MethodPost, "/jobs", `{"name":"hourly","schedule_text":"every hour","task_prompt":"x","keep_executions":0}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("keep_executions 0: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	job := &storage.RecurringJob{ID: "job-1", Name: "hourly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "x", TaskPromptSource: "text", Enabled: true}
+	if err := server.store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	now := time.Now()
+	for i, status := range []string{"success", "failed", "success", "running"} {
+		exec := &storage.JobExecution{ID: fmt.Sprintf("exec-%d", i), JobID: job.ID, Status: status, StartedAt: now.Add(-time.Duration(4-i) * time.Minute)}
+		if err := server.store.SaveJobExecution(exec); err != nil {
+			t.Fatalf("SaveJobExecution: %v", err)
+		}
+	}
+
+	rec = serveAuthorized(server, http.MethodDelete, "/jobs/job-1/executions?keep=-1", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative keep: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = serveAuthorized(server, http.MethodDelete, "/jobs/job-1/executions?keep=0", "")
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"deleted":3`) {
+		t.Fatalf("prune: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if executions, _ := server.store.ListJobExecutions(job.ID, 10); len(executions) != 1 || executions[0].Status != "running" {
+		t.Fatalf("expected only the running execution to remain, got %+v", executions)
+	}
+	rec = serveAuthorized(server, http.MethodDelete, "/jobs/missing/executions", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing job: status %d body=%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestGetSessionExecution(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	exec := &storage.JobExecution{ID: "exec-1", JobID: "job-1", Status: "running", StartedAt: time.Now()}
+	if err := server.store.SaveJobExecution(exec); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+	sess, err := sessionManager.CreateWithJob("job-runner", "job-1", exec.ID)
+	if err != nil {
+		t.Fatalf("CreateWithJob: %v", err)
+	}
+	exec.SessionID = sess.ID
+	if err := server.store.SaveJobExecution(exec); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID, "")
+	var resp SessionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.JobID != "job-1" || resp.ExecutionID != exec.ID {
+		t.Fatalf("expected the session to name its job and execution, got status %d: %s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/execution", "")
+	var got JobExecutionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || rec.Code != http.StatusOK || got.ID != exec.ID || got.SessionID != sess.ID {
+		t.Fatalf("GET execution: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	plain, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	for _, path := range []string{"/sessions/" + plain.ID + "/execution", "/sessions/missing/execution"} {
+		if rec := serveAuthorized(server, http.MethodGet, path, ""); rec.Code != http.StatusNotFound {
+			t.Fatalf("GET %s: status %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestJobQuestionAnsweredFromChat(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	server.config.DataPath = t.TempDir()
+	job := &storage.RecurringJob{ID: "job-1", Name: "deploy", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "deploy", TaskPromptSource: "text", Enabled: true}
+	if err := server.store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	exec := &storage.JobExecution{ID: "exec-1", JobID: job.ID, Status: jobs.StatusWaitingInput, StartedAt: time.Now()}
+	sess, err := sessionManager.CreateWithJob("job-runner", job.ID, exec.ID)
+	if err != nil {
+		t.Fatalf("CreateWithJob: %v", err)
+	}
+	exec.SessionID = sess.ID
+	if err := server.store.SaveJobExecution(exec); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+	question := &session.QuestionData{Question: "Deploy now?", Options: []session.QuestionOption{{Label: "Yes"}, {Label: "No"}}}
+	if err := sessionManager.AskQuestion(sess.ID, question); err != nil {
+		t.Fatalf("AskQuestion: %v", err)
+	}
+	// What sending the question through a duplex Telegram integration leaves
+	sess, _ = sessionManager.Get(sess.ID)
+	sess.Metadata[jobs.QuestionIntegrationMetadataKey] = "tg"
+	sess.Metadata[jobs.QuestionChatMetadataKey] = "42"
+	sess.Metadata[jobs.QuestionThreadMetadataKey] = ""
+	if err := sessionManager.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	integration := &storage.Integration{ID: "tg", Provider: "telegram", Mode: "duplex", Enabled: true, Config: map[string]string{"default_chat_id": "42"}}
+	send := func(text string) string {
+		t.Helper()
+		result, err := server.handleTelegramInboundMessage(context.Background(), integration, telegramChatPayload{ID: 42, Type: "private"}, 0, text, nil, nil)
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		return result.reply
+	}
+	if reply := send("3"); !strings.Contains(reply, "Invalid answer") {
+		t.Fatalf("unexpected reply to an unknown option %q", reply)
+	}
+	if reply := send("2"); !strings.Contains(reply, "Answer recorded (No)") {
+		t.Fatalf("unexpected reply to the answer %q", reply)
+	}
+
+	// Without a provider the resumed run fails, which finishes the execution
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stored, err := server.store.GetJobExecution(exec.ID)
+		if err != nil {
+			t.Fatalf("GetJobExecution: %v", err)
+		}
+		if stored.FinishedAt != nil {
+			if stored.Status != "failed" {
+				t.Fatalf("unexpected finished execution %+v", stored)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("execution did not finish after the answer: %+v", stored)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	answered, err := sessionManager.Get(sess.ID)
+	if err != nil || len(answered.Messages) == 0 || answered.Messages[0].Content != "No" {
+		t.Fatalf("expected the answer to be recorded in the session: %+v, %v", answered, err)
+	}
+}
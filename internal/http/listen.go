@@ -0,0 +1,136 @@
+package http
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/go-chi/chi/v5/middleware"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// autocertCacheDir holds the Let's Encrypt account and certificates inside
+// the data directory.
+const autocertCacheDir = "autocert"
+
+// listen opens the listener described by http config: a Unix socket when
+// unix_socket is set, the bind address and port otherwise, wrapped in TLS
+// when certificates are configured. It returns the listener and the URL it
+// serves.
+func (s *Server) listen(httpCfg config.HTTPConfig) (net.Listener, string, error) {
+	var listener net.Listener
+	var where string
+	if socket := strings.TrimSpace(httpCfg.UnixSocket); socket != "" {
+		// A socket left behind by a crash would make Listen fail
+		if err := os.Remove(socket); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, "", fmt.Errorf("failed to remove stale socket %s: %w", socket, err)
+		}
+		l, err := net.Listen("unix", socket)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := os.Chmod(socket, 0o600); err != nil {
+			l.Close()
+			return nil, "", err
+		}
+		listener, where = l, "unix:"+socket
+	} else {
+		addr := net.JoinHostPort(httpCfg.EffectiveBindAddress(), strconv.Itoa(s.port))
+		l, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, "", err
+		}
+		if tcpAddr, ok := l.Addr().(*net.TCPAddr); ok {
+			s.port = tcpAddr.Port
+		}
+		listener, where = l, l.Addr().String()
+	}
+
+	if !httpCfg.TLSEnabled() {
+		return listener, "http://" + where, nil
+	}
+	tlsConfig, err := s.serverTLSConfig(httpCfg)
+	if err != nil {
+		listener.Close()
+		return nil, "", err
+	}
+	return tls.NewListener(listener, tlsConfig), "https://" + where, nil
+}
+
+// serverTLSConfig loads tls_cert_file and tls_key_file, or sets up
+// certificates from Let's Encrypt for autocert_domain. Autocert answers the
+// TLS-ALPN challenge itself, so the server must be reachable on port 443.
+func (s *Server) serverTLSConfig(httpCfg config.HTTPConfig) (*tls.Config, error) {
+	certFile := strings.TrimSpace(httpCfg.TLSCertFile)
+	keyFile := strings.TrimSpace(httpCfg.TLSKeyFile)
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("http.tls_cert_file and http.tls_key_file must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		return &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}, nil
+	}
+
+	manager := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(strings.TrimSpace(httpCfg.AutocertDomain)),
+		Cache:      autocert.DirCache(filepath.Join(s.config.DataPath, autocertCacheDir)),
+	}
+	tlsConfig := manager.TLSConfig()
+	tlsConfig.MinVersion = tls.VersionTLS12
+	return tlsConfig, nil
+}
+
+// proxyHeadersMiddleware takes the client address from X-Forwarded-For or
+// X-Real-IP when http.trust_proxy_headers is set. Only enable it behind a
+// proxy that sets these headers, since clients can send them too.
+func (s *Server) proxyHeadersMiddleware(next http.Handler) http.Handler {
+	if !s.httpConfig().TrustProxyHeaders {
+		return next
+	}
+	return middleware.RealIP(next)
+}
+
+// requestBaseURL returns the scheme and host clients used to reach the
+// server, from X-Forwarded-Proto and X-Forwarded-Host when proxy headers are
+// trusted.
+func (s *Server) requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+	if s.httpConfig().TrustProxyHeaders {
+		if proto := firstForwardedValue(r.Header.Get("X-Forwarded-Proto")); proto == "http" || proto == "https" {
+			scheme = proto
+		}
+		if forwardedHost := firstForwardedValue(r.Header.Get("X-Forwarded-Host")); forwardedHost != "" {
+			host = forwardedHost
+		}
+	}
+	if host == "" {
+		port := s.port
+		if port == 0 {
+			port = 8080
+		}
+		host = fmt.Sprintf("localhost:%d", port)
+	}
+	return scheme + "://" + host
+}
+
+// firstForwardedValue returns the first entry of a comma-separated
+// forwarding header, which is the one closest to the client.
+func firstForwardedValue(header string) string {
+	value, _, _ := strings.Cut(header, ",")
+	return strings.ToLower(strings.TrimSpace(value))
+}
@@ -0,0 +1,75 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunOnUnixSocketShutsDownCleanly(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	socket := filepath.Join(t.TempDir(), "aagent.sock")
+	server.config.HTTP.UnixSocket = socket
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", socket)
+		},
+	}}
+	var resp *http.Response
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		if resp, err = client.Get("http://aagent/health/live"); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		t.Fatalf("GET over the socket: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("unexpected status %d", resp.StatusCode)
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("expected a clean shutdown to return nil, got %v", err)
+		}
+	case <-time.After(15 * time.Second):
+		t.Fatal("Run did not return after shutdown")
+	}
+}
+
+func TestRunRejectsIncompleteTLSConfig(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	server.config.HTTP.TLSCertFile = "cert.pem"
+	if err := server.Run(context.Background()); err == nil {
+		t.Fatal("expected an error when tls_key_file is missing")
+	}
+}
+
+func TestRequestBaseURLHonorsTrustedProxyHeaders(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/.well-known/agent-card.json", nil)
+	req.Host = "10.0.0.5:8080"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "agent.example.com, proxy.internal")
+
+	if got := server.requestBaseURL(req); got != "http://10.0.0.5:8080" {
+		t.Fatalf("untrusted proxy headers must be ignored, got %s", got)
+	}
+	server.config.HTTP.TrustProxyHeaders = true
+	if got := server.requestBaseURL(req); got != "https://agent.example.com" {
+		t.Fatalf("expected the forwarded URL, got %s", got)
+	}
+}
@@ -0,0 +1,729 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+const (
+	// mcpToolNamePrefix namespaces bridged tools as mcp_<server>_<tool>.
+	mcpToolNamePrefix = "mcp_"
+	// mcpMaxToolNameLength is the tool name limit most LLM providers enforce.
+	mcpMaxToolNameLength = 64
+	mcpSessionIDHeader   = "Mcp-Session-Id"
+)
+
+var mcpToolNameUnsafe = regexp.MustCompile(`[^a-z0-9_]+`)
+
+// mcpClient is a connected MCP session.
+type mcpClient interface {
+	call(ctx context.Context, method string, params interface{}) (map[string]interface{}, error)
+	notify(ctx context.Context, method string, params interface{}) error
+	alive() bool
+	close()
+}
+
+// mcpBridge keeps a session open to every enabled MCP server and registers
+// the tools they expose into the server's tool manager.
+type mcpBridge struct {
+	store   storage.Store
+	manager *tools.Manager
+
+	syncMu sync.Mutex
+	mu     sync.Mutex
+	conns  map[string]*mcpConnection
+}
+
+type mcpConnection struct {
+	serverID    string
+	cfg         *mcpServerConfig
+	fingerprint string
+	tools       []*mcpTool
+	listings    mcpListingCache
+
+	mu           sync.Mutex
+	client       mcpClient
+	capabilities map[string]interface{}
+}
+
+func newMCPBridge(store storage.Store, manager *tools.Manager) *mcpBridge {
+	return &mcpBridge{store: store, manager: manager, conns: make(map[string]*mcpConnection)}
+}
+
+// refreshMCPTools reconciles bridged tools with the stored MCP servers in the
+// background, so configuration changes apply without a restart.
+func (s *Server) refreshMCPTools() {
+	if s.mcpTools == nil {
+		return
+	}
+	go s.mcpTools.sync(context.Background())
+}
+
+// sync connects to new or changed enabled servers and drops the tools of
+// servers that were removed or disabled.
+func (b *mcpBridge) sync(ctx context.Context) {
+	b.syncMu.Lock()
+	defer b.syncMu.Unlock()
+
+	servers, err := b.store.ListMCPServers()
+	if err != nil {
+		logging.Warn("Failed to list MCP servers for tool bridge: %v", err)
+		return
+	}
+	wanted := make(map[string]bool, len(servers))
+	for _, server := range servers {
+		if server == nil || !server.Enabled {
+			continue
+		}
+		wanted[server.ID] = true
+		cfg, err := decodeMCPServerConfig(server)
+		if err != nil {
+			logging.Warn("Skipping MCP server %s: %v", server.Name, err)
+			b.disconnect(server.ID)
+			continue
+		}
+		fingerprint, _ := json.Marshal(cfg)
+		b.mu.Lock()
+		existing := b.conns[server.ID]
+		b.mu.Unlock()
+		if existing != nil && existing.fingerprint == string(fingerprint) {
+			continue
+		}
+		b.disconnect(server.ID)
+		conn := &mcpConnection{serverID: server.ID, cfg: cfg, fingerprint: string(fingerprint)}
+		if err := b.connect(ctx, conn); err != nil {
+			logging.Warn("Failed to load tools from MCP server %s: %v", cfg.Name, err)
+			continue
+		}
+		logging.Info("Registered %d tools from MCP server %s", len(conn.tools), cfg.Name)
+	}
+
+	b.mu.Lock()
+	stale := make([]string, 0)
+	for id := range b.conns {
+		if !wanted[id] {
+			stale = append(stale, id)
+		}
+	}
+	b.mu.Unlock()
+	for _, id := range stale {
+		b.disconnect(id)
+	}
+}
+
+// connect starts the session, lists the tools and registers them.
+func (b *mcpBridge) connect(ctx context.Context, conn *mcpConnection) error {
+	client, capabilities, err := startMCPClient(ctx, conn.cfg)
+	if err != nil {
+		return err
+	}
+	listed, err := listMCPTools(ctx, client, conn.cfg)
+	if err != nil {
+		client.close()
+		return err
+	}
+	conn.client = client
+	conn.capabilities = capabilities
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, def := range listed {
+		if def.Name == "" {
+			continue
+		}
+		tool := &mcpTool{
+			bridge:      b,
+			serverID:    conn.serverID,
+			name:        mcpToolName(conn.cfg.Name, def.Name),
+			remoteName:  def.Name,
+			description: strings.TrimSpace(fmt.Sprintf("[MCP %s] %s", conn.cfg.Name, def.Description)),
+			schema:      mcpToolSchema(def.InputSchema),
+		}
+		if _, taken := b.manager.Get(tool.name); taken {
+			logging.Warn("Skipping MCP tool %s from %s: a tool with that name is already registered", def.Name, conn.cfg.Name)
+			continue
+		}
+		b.manager.Register(tool)
+		conn.tools = append(conn.tools, tool)
+	}
+	b.conns[conn.serverID] = conn
+	return nil
+}
+
+// disconnect unregisters the server's tools and ends its session.
+func (b *mcpBridge) disconnect(serverID string) {
+	b.mu.Lock()
+	conn := b.conns[serverID]
+	delete(b.conns, serverID)
+	b.mu.Unlock()
+	if conn == nil {
+		return
+	}
+	for _, tool := range conn.tools {
+		b.manager.Unregister(tool.name)
+	}
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if conn.client != nil {
+		conn.client.close()
+		conn.client = nil
+	}
+}
+
+// close ends every MCP session.
+func (b *mcpBridge) close() {
+	b.mu.Lock()
+	ids := make([]string, 0, len(b.conns))
+	for id := range b.conns {
+		ids = append(ids, id)
+	}
+	b.mu.Unlock()
+	for _, id := range ids {
+		b.disconnect(id)
+	}
+}
+
+// register adds the bridged tools to another manager, e.g. one built for a
+// project work directory.
+func (b *mcpBridge) register(manager *tools.Manager) {
+	if b == nil || manager == nil || manager == b.manager {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		for _, tool := range conn.tools {
+			manager.Register(tool)
+		}
+	}
+}
+
+// session returns a live client for the server, restarting it when the
+// process died or the connection was lost.
+func (b *mcpBridge) session(ctx context.Context, serverID string) (mcpClient, *mcpServerConfig, error) {
+	b.mu.Lock()
+	conn := b.conns[serverID]
+	b.mu.Unlock()
+	if conn == nil {
+		return nil, nil, fmt.Errorf("MCP server is no longer enabled")
+	}
+
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+	if conn.client != nil && conn.client.alive() {
+		return conn.client, conn.cfg, nil
+	}
+	if conn.client != nil {
+		conn.client.close()
+		conn.client = nil
+	}
+	logging.Info("Reconnecting to MCP server %s", conn.cfg.Name)
+	client, capabilities, err := startMCPClient(ctx, conn.cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reconnect to MCP server %s: %w", conn.cfg.Name, err)
+	}
+	conn.client = client
+	conn.capabilities = capabilities
+	return client, conn.cfg, nil
+}
+
+// mcpTool forwards calls to a tool exposed by an MCP server.
+type mcpTool struct {
+	bridge      *mcpBridge
+	serverID    string
+	name        string
+	remoteName  string
+	description string
+	schema      map[string]interface{}
+}
+
+func (t *mcpTool) Name() string                   { return t.name }
+func (t *mcpTool) Description() string            { return t.description }
+func (t *mcpTool) Schema() map[string]interface{} { return t.schema }
+
+func (t *mcpTool) Execute(ctx context.Context, params json.RawMessage) (*tools.Result, error) {
+	arguments := map[string]interface{}{}
+	if len(bytes.TrimSpace(params)) > 0 {
+		if err := json.Unmarshal(params, &arguments); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+	client, cfg, err := t.bridge.session(ctx, t.serverID)
+	if err != nil {
+		return &tools.Result{Success: false, Error: err.Error()}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+	resp, err := client.call(ctx, "tools/call", map[string]interface{}{
+		"name":      t.remoteName,
+		"arguments": arguments,
+	})
+	if err != nil {
+		return &tools.Result{Success: false, Error: fmt.Sprintf("MCP server %s: %v", cfg.Name, err)}, nil
+	}
+
+	result := mapFromAny(resp["result"])
+	output := mcpContentText(result["content"])
+	if isError, _ := result["isError"].(bool); isError {
+		return &tools.Result{Success: false, Output: output, Error: "MCP tool reported an error"}, nil
+	}
+	res := &tools.Result{Success: true, Output: output}
+	if structured := mapFromAny(result["structuredContent"]); len(structured) > 0 {
+		res.Metadata = map[string]interface{}{"structured_content": structured}
+	}
+	return res, nil
+}
+
+var _ tools.Tool = (*mcpTool)(nil)
+
+// mcpToolName builds a namespaced name that is valid for LLM providers.
+func mcpToolName(serverName, toolName string) string {
+	clean := func(value string) string {
+		value = mcpToolNameUnsafe.ReplaceAllString(strings.ToLower(value), "_")
+		return strings.Trim(value, "_")
+	}
+	name := mcpToolNamePrefix + clean(serverName) + "_" + clean(toolName)
+	if len(name) > mcpMaxToolNameLength {
+		name = name[:mcpMaxToolNameLength]
+	}
+	return name
+}
+
+// mcpToolSchema adapts an MCP input schema to a tool parameter schema:
+// always an object with properties, without the $schema marker some
+// providers reject.
+func mcpToolSchema(input map[string]interface{}) map[string]interface{} {
+	schema := make(map[string]interface{}, len(input)+2)
+	for key, value := range input {
+		if key == "$schema" {
+			continue
+		}
+		schema[key] = value
+	}
+	schema["type"] = "object"
+	if _, ok := schema["properties"].(map[string]interface{}); !ok {
+		schema["properties"] = map[string]interface{}{}
+	}
+	return schema
+}
+
+// mcpContentText flattens tools/call content blocks into text output.
+func mcpContentText(content interface{}) string {
+	items, _ := content.([]interface{})
+	parts := make([]string, 0, len(items))
+	for _, item := range items {
+		block := mapFromAny(item)
+		switch kind := asString(block["type"]); kind {
+		case "text":
+			parts = append(parts, asString(block["text"]))
+		case "resource":
+			resource := mapFromAny(block["resource"])
+			if text := asString(resource["text"]); text != "" {
+				parts = append(parts, text)
+			} else {
+				parts = append(parts, fmt.Sprintf("[resource %s]", asString(resource["uri"])))
+			}
+		default:
+			parts = append(parts, fmt.Sprintf("[%s content]", kind))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
+
+// startMCPClient connects and completes the initialize handshake, returning
+// the capabilities the server announced.
+func startMCPClient(ctx context.Context, cfg *mcpServerConfig) (mcpClient, map[string]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	if cfg.Transport == mcpTransportHTTP {
+		client := &mcpHTTPClient{cfg: cfg, client: &http.Client{}}
+		capabilities, err := initializeMCPClient(ctx, client)
+		if err != nil {
+			return nil, nil, err
+		}
+		return client, capabilities, nil
+	}
+
+	client, err := startMCPStdioClient(cfg, true)
+	if err != nil {
+		return nil, nil, err
+	}
+	capabilities, err := initializeMCPClient(ctx, client)
+	if err != nil {
+		client.close()
+		if !client.framingMismatch() {
+			return nil, nil, err
+		}
+		// Same fallback as the MCP server test: the server wants
+		// line-delimited JSON rather than Content-Length framing.
+		client, err = startMCPStdioClient(cfg, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if capabilities, err = initializeMCPClient(ctx, client); err != nil {
+			client.close()
+			return nil, nil, err
+		}
+	}
+	return client, capabilities, nil
+}
+
+func initializeMCPClient(ctx context.Context, client mcpClient) (map[string]interface{}, error) {
+	resp, err := client.call(ctx, "initialize", map[string]interface{}{
+		"protocolVersion": mcpProtocolVersion,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo": map[string]interface{}{
+			"name":    "aagent",
+			"version": "1.0.0",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("initialize failed: %w", err)
+	}
+	if err := client.notify(ctx, "notifications/initialized", map[string]interface{}{}); err != nil {
+		logging.Debug("MCP initialized notification failed: %v", err)
+	}
+	return mapFromAny(mapFromAny(resp["result"])["capabilities"]), nil
+}
+
+// listMCPTools reads every page of tools/list.
+func listMCPTools(ctx context.Context, client mcpClient, cfg *mcpServerConfig) ([]MCPToolResponse, error) {
+	items, err := listMCPItems(ctx, client, cfg, "tools/list", "tools")
+	if err != nil {
+		return nil, err
+	}
+	return mcpToolsFromToolsListResult(map[string]interface{}{"tools": items}), nil
+}
+
+// listMCPItems follows nextCursor through every page of a list method and
+// returns the entries found under key.
+func listMCPItems(ctx context.Context, client mcpClient, cfg *mcpServerConfig, method string, key string) ([]interface{}, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+
+	var all []interface{}
+	cursor := ""
+	for {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		resp, err := client.call(ctx, method, params)
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %w", method, err)
+		}
+		result := mapFromAny(resp["result"])
+		items, _ := result[key].([]interface{})
+		all = append(all, items...)
+		cursor = strings.TrimSpace(asString(result["nextCursor"]))
+		if cursor == "" {
+			return all, nil
+		}
+	}
+}
+
+// mcpStdioClient talks JSON-RPC to a long-running MCP server process.
+type mcpStdioClient struct {
+	cmd       *exec.Cmd
+	stdin     io.WriteCloser
+	framing   bool
+	collector *mcpLogCollector
+	nextID    atomic.Int64
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	pending map[string]chan map[string]interface{}
+	done    chan struct{}
+	err     error
+}
+
+func startMCPStdioClient(cfg *mcpServerConfig, framing bool) (*mcpStdioClient, error) {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	if cfg.Cwd != "" {
+		cmd.Dir = cfg.Cwd
+	}
+	cmd.Env = mcpCommandEnv(cfg)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdout pipe: %w", err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stderr pipe: %w", err)
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stdin pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start MCP server command: %w", err)
+	}
+
+	c := &mcpStdioClient{
+		cmd:       cmd,
+		stdin:     stdin,
+		framing:   framing,
+		collector: &mcpLogCollector{},
+		pending:   make(map[string]chan map[string]interface{}),
+		done:      make(chan struct{}),
+	}
+	stderrDone := make(chan struct{})
+	go func() {
+		defer close(stderrDone)
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			c.collector.add("stderr: %s", scanner.Text())
+			logging.Debug("MCP %s stderr: %s", cfg.Name, scanner.Text())
+		}
+	}()
+	go c.readLoop(stdout, stderrDone)
+	return c, nil
+}
+
+func (c *mcpStdioClient) readLoop(stdout io.Reader, stderrDone <-chan struct{}) {
+	reader := bufio.NewReader(stdout)
+	for {
+		msg, err := readMCPMessage(reader, c.collector)
+		if err != nil {
+			c.mu.Lock()
+			c.err = fmt.Errorf("MCP server connection closed: %w", err)
+			c.pending = map[string]chan map[string]interface{}{}
+			c.mu.Unlock()
+			close(c.done)
+			<-stderrDone
+			_ = c.cmd.Wait()
+			return
+		}
+		id, hasID := msg["id"]
+		method, isRequest := msg["method"].(string)
+		switch {
+		case isRequest && hasID:
+			c.answerServerRequest(id, method)
+		case hasID:
+			key := fmt.Sprintf("%v", id)
+			c.mu.Lock()
+			ch := c.pending[key]
+			delete(c.pending, key)
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- msg
+			}
+		}
+	}
+}
+
+// answerServerRequest replies to requests the server sends us. Only ping is
+// supported; the client advertises no other capabilities.
+func (c *mcpStdioClient) answerServerRequest(id interface{}, method string) {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": id}
+	if method == "ping" {
+		reply["result"] = map[string]interface{}{}
+	} else {
+		reply["error"] = map[string]interface{}{"code": -32601, "message": "method not supported: " + method}
+	}
+	_ = c.write(reply)
+}
+
+func (c *mcpStdioClient) write(payload map[string]interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.framing {
+		return writeMCPFramedMessage(c.stdin, body)
+	}
+	return writeMCPLineMessage(c.stdin, body)
+}
+
+func (c *mcpStdioClient) call(ctx context.Context, method string, params interface{}) (map[string]interface{}, error) {
+	id := strconv.FormatInt(c.nextID.Add(1), 10)
+	ch := make(chan map[string]interface{}, 1)
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+	select {
+	case <-ctx.Done():
+		return nil, fmt.Errorf("timeout while waiting for %s response", method)
+	case <-c.done:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return nil, c.err
+	case msg := <-ch:
+		if rpcErr := mapFromAny(msg["error"]); len(rpcErr) > 0 {
+			return nil, fmt.Errorf("MCP error for %q: %v", method, rpcErr)
+		}
+		return msg, nil
+	}
+}
+
+func (c *mcpStdioClient) notify(_ context.Context, method string, params interface{}) error {
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (c *mcpStdioClient) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *mcpStdioClient) close() {
+	_ = c.stdin.Close()
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+}
+
+// framingMismatch reports whether the server complained about Content-Length
+// framing on stderr.
+func (c *mcpStdioClient) framingMismatch() bool {
+	logText := strings.ToLower(strings.Join(c.collector.list(), "\n"))
+	return strings.Contains(logText, "invalid json") && strings.Contains(logText, "content-length")
+}
+
+// mcpHTTPClient talks JSON-RPC over streamable HTTP, keeping the session ID
+// the server assigns.
+type mcpHTTPClient struct {
+	cfg    *mcpServerConfig
+	client *http.Client
+	nextID atomic.Int64
+
+	mu        sync.Mutex
+	sessionID string
+}
+
+func (c *mcpHTTPClient) call(ctx context.Context, method string, params interface{}) (map[string]interface{}, error) {
+	id := strconv.FormatInt(c.nextID.Add(1), 10)
+	msg, err := c.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}, id)
+	if err != nil {
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	if rpcErr := mapFromAny(msg["error"]); len(rpcErr) > 0 {
+		return nil, fmt.Errorf("MCP error for %q: %v", method, rpcErr)
+	}
+	return msg, nil
+}
+
+func (c *mcpHTTPClient) notify(ctx context.Context, method string, params interface{}) error {
+	_, err := c.post(ctx, map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}, "")
+	return err
+}
+
+// alive is always true: every call is its own request.
+func (c *mcpHTTPClient) alive() bool { return true }
+
+func (c *mcpHTTPClient) close() {}
+
+func (c *mcpHTTPClient) post(ctx context.Context, payload map[string]interface{}, id string) (map[string]interface{}, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	for key, value := range c.cfg.Headers {
+		req.Header.Set(key, value)
+	}
+	c.mu.Lock()
+	if c.sessionID != "" {
+		req.Header.Set(mcpSessionIDHeader, c.sessionID)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if sessionID := resp.Header.Get(mcpSessionIDHeader); sessionID != "" {
+		c.mu.Lock()
+		c.sessionID = sessionID
+		c.mu.Unlock()
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("HTTP status %d", resp.StatusCode)
+	}
+	if id == "" {
+		return nil, nil
+	}
+
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 2*1024*1024)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data:")
+			if !ok {
+				continue
+			}
+			var msg map[string]interface{}
+			if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &msg); err != nil {
+				continue
+			}
+			if fmt.Sprintf("%v", msg["id"]) == id {
+				return msg, nil
+			}
+		}
+		return nil, fmt.Errorf("event stream ended without a response")
+	}
+
+	var msg map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 2*1024*1024)).Decode(&msg); err != nil {
+		return nil, fmt.Errorf("failed to decode MCP response: %w", err)
+	}
+	return msg, nil
+}
+
+// mcpCommandEnv is the process environment plus the server's env settings.
+func mcpCommandEnv(cfg *mcpServerConfig) []string {
+	env := append([]string{}, os.Environ()...)
+	keys := make([]string, 0, len(cfg.Env))
+	for key := range cfg.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, key+"="+cfg.Env[key])
+	}
+	return env
+}
@@ -0,0 +1,217 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+const mcpHelperEnv = "AAGENT_MCP_HELPER_PROCESS"
+
+// TestMCPBridgeHelperProcess is not a real test: the bridge test starts the
+// test binary with it as a minimal stdio MCP server.
+func TestMCPBridgeHelperProcess(t *testing.T) {
+	if os.Getenv(mcpHelperEnv) != "1" {
+		return
+	}
+	reader := bufio.NewReader(os.Stdin)
+	reply := func(id interface{}, result map[string]interface{}) {
+		body, _ := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": id, "result": result})
+		fmt.Fprintf(os.Stdout, "%s\n", body)
+	}
+	for {
+		msg, err := readMCPMessage(reader, &mcpLogCollector{})
+		if err != nil {
+			os.Exit(0)
+		}
+		params := mapFromAny(msg["params"])
+		switch msg["method"] {
+		case "initialize":
+			reply(msg["id"], map[string]interface{}{"serverInfo": map[string]interface{}{"name": "helper"}, "capabilities": map[string]interface{}{
+				"tools":     map[string]interface{}{},
+				"resources": map[string]interface{}{},
+				"prompts":   map[string]interface{}{},
+			}})
+		case "resources/list":
+			reply(msg["id"], map[string]interface{}{"resources": []interface{}{
+				map[string]interface{}{"uri": "db://schema", "name": "Schema", "mimeType": "text/plain", "size": 31},
+				map[string]interface{}{"uri": "db://dump", "name": "Dump"},
+			}})
+		case "resources/read":
+			text := "CREATE TABLE users (id INT);"
+			if params["uri"] == "db://dump" {
+				text = strings.Repeat("x", mcpResourceMaxBytes+10)
+			}
+			reply(msg["id"], map[string]interface{}{"contents": []interface{}{map[string]interface{}{"uri": params["uri"], "mimeType": "text/plain", "text": text}}})
+		case "prompts/list":
+			reply(msg["id"], map[string]interface{}{"prompts": []interface{}{map[string]interface{}{
+				"name": "review", "description": "Review a file",
+				"arguments": []interface{}{map[string]interface{}{"name": "file", "required": true}},
+			}}})
+		case "prompts/get":
+			file := asString(mapFromAny(params["arguments"])["file"])
+			reply(msg["id"], map[string]interface{}{"messages": []interface{}{map[string]interface{}{"role": "user", "content": map[string]interface{}{"type": "text", "text": "Review " + file}}}})
+		case "tools/list":
+			reply(msg["id"], map[string]interface{}{"tools": []interface{}{
+				map[string]interface{}{"name": "echo", "description": "Echo text", "inputSchema": map[string]interface{}{
+					"$schema":    "http://json-schema.org/draft-07/schema#",
+					"type":       "object",
+					"properties": map[string]interface{}{"text": map[string]interface{}{"type": "string"}},
+				}},
+				map[string]interface{}{"name": "crash"},
+			}})
+		case "tools/call":
+			if params["name"] == "crash" {
+				os.Exit(1)
+			}
+			text := asString(mapFromAny(params["arguments"])["text"])
+			reply(msg["id"], map[string]interface{}{"content": []interface{}{map[string]interface{}{"type": "text", "text": "echo: " + text}}})
+		}
+	}
+}
+
+func saveMCPHelperServer(t *testing.T, server *Server) *storage.MCPServer {
+	t.Helper()
+	now := time.Now()
+	mcpServer := &storage.MCPServer{
+		ID:        "helper",
+		Name:      "Helper",
+		Transport: mcpTransportStdio,
+		Enabled:   true,
+		Config: encodeMCPServerConfig(&mcpServerConfig{
+			Command:        os.Args[0],
+			Args:           []string{"-test.run=^TestMCPBridgeHelperProcess$"},
+			Env:            map[string]string{mcpHelperEnv: "1"},
+			TimeoutSeconds: 10,
+		}),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := server.store.SaveMCPServer(mcpServer); err != nil {
+		t.Fatalf("SaveMCPServer: %v", err)
+	}
+	server.mcpTools.sync(context.Background())
+	return mcpServer
+}
+
+func TestMCPBridge(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	t.Cleanup(server.mcpTools.close)
+	mcpServer := saveMCPHelperServer(t, server)
+
+	echo, ok := server.toolManager.Get("mcp_helper_echo")
+	if !ok {
+		t.Fatal("expected mcp_helper_echo to be registered")
+	}
+	if _, ok := echo.Schema()["$schema"]; ok || echo.Schema()["type"] != "object" {
+		t.Fatalf("unexpected schema %+v", echo.Schema())
+	}
+	if crash, ok := server.toolManager.Get("mcp_helper_crash"); !ok || crash.Schema()["properties"] == nil {
+		t.Fatal("expected mcp_helper_crash with an empty properties schema")
+	}
+
+	call := func(name, params string) (bool, string) {
+		res, err := server.toolManager.Execute(context.Background(), name, json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("Execute %s: %v", name, err)
+		}
+		return res.Success, res.Output + res.Error
+	}
+	if ok, out := call("mcp_helper_echo", `{"text":"hi"}`); !ok || out != "echo: hi" {
+		t.Fatalf("echo: success=%v output=%q", ok, out)
+	}
+	if ok, _ := call("mcp_helper_crash", `{}`); ok {
+		t.Fatal("expected the call that killed the server to fail")
+	}
+	if ok, out := call("mcp_helper_echo", `{"text":"again"}`); !ok || out != "echo: again" {
+		t.Fatalf("echo after restart: success=%v output=%q", ok, out)
+	}
+
+	mcpServer.Enabled = false
+	if err := server.store.SaveMCPServer(mcpServer); err != nil {
+		t.Fatalf("SaveMCPServer: %v", err)
+	}
+	server.mcpTools.sync(context.Background())
+	if _, ok := server.toolManager.Get("mcp_helper_echo"); ok {
+		t.Fatal("expected tools of a disabled server to be removed")
+	}
+}
+
+func TestMCPToolName(t *testing.T) {
+	if got := mcpToolName("fetch", "fetch"); got != "mcp_fetch_fetch" {
+		t.Fatalf("got %q", got)
+	}
+	if got := mcpToolName("Chrome DevTools", "take-screenshot"); got != "mcp_chrome_devtools_take_screenshot" {
+		t.Fatalf("got %q", got)
+	}
+	if got := mcpToolName(strings.Repeat("x", 80), "tool"); len(got) != mcpMaxToolNameLength {
+		t.Fatalf("expected the name to be cut to %d characters, got %d", mcpMaxToolNameLength, len(got))
+	}
+}
+
+func TestMCPResourcesAndPrompts(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	t.Cleanup(server.mcpTools.close)
+	saveMCPHelperServer(t, server)
+
+	run := func(params string) *tools.Result {
+		res, err := server.toolManager.Execute(context.Background(), readMCPResourceToolName, json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		return res
+	}
+	if res := run(`{"action":"list"}`); !res.Success || !strings.Contains(res.Output, `"uri": "db://schema"`) || !strings.Contains(res.Output, `"mime_type": "text/plain"`) {
+		t.Fatalf("unexpected listing %+v", res)
+	}
+	if res := run(`{"action":"read","uri":"db://schema"}`); !res.Success || !strings.Contains(res.Output, "CREATE TABLE users") {
+		t.Fatalf("unexpected resource %+v", res)
+	}
+	if res := run(`{"action":"read","server":"helper","uri":"db://dump"}`); !res.Success || !strings.Contains(res.Output, fmt.Sprintf("[truncated: showing the first %d", mcpResourceMaxBytes)) {
+		t.Fatalf("expected a truncated resource, got %d bytes", len(res.Output))
+	}
+	if res := run(`{"action":"read","uri":"db://unknown"}`); res.Success {
+		t.Fatal("expected an unlisted URI without server to fail")
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/skills/builtin", "")
+	var skills BuiltInSkillResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &skills); err != nil {
+		t.Fatalf("decode skills: %v", err)
+	}
+	var prompt *BuiltInSkill
+	for i := range skills.Skills {
+		if skills.Skills[i].Kind == "mcp_prompt" {
+			prompt = &skills.Skills[i]
+		}
+	}
+	if prompt == nil || prompt.Name != "Helper/review" || len(prompt.Arguments) != 1 || !prompt.Arguments[0].Required {
+		t.Fatalf("expected the helper prompt among skills, got %+v", skills.Skills)
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/sessions", fmt.Sprintf(`{"queued":true,"task":"Focus on errors.","mcp_prompt":{"id":%q,"arguments":{"file":"main.go"}}}`, prompt.ID))
+	if rec.Code != http.StatusCreated && rec.Code != http.StatusOK {
+		t.Fatalf("create session: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var created CreateSessionResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &created)
+	sess, err := sessionManager.Get(created.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(sess.Messages) == 0 || sess.Messages[0].Content != "Review main.go\n\nFocus on errors." {
+		t.Fatalf("unexpected seeded messages %+v", sess.Messages)
+	}
+	if rec := serveAuthorized(server, http.MethodPost, "/sessions", `{"mcp_prompt":{"id":"mcp_prompt:missing:review"}}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected an unknown prompt to be rejected, got %d", rec.Code)
+	}
+}
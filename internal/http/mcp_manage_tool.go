@@ -192,6 +192,8 @@ func (t *mcpManageTool) handleAdd(p mcpManageParams) (*tools.Result, error) {
 		return &tools.Result{Success: false, Error: "failed to save MCP server: " + err.Error()}, nil
 	}
 
+	t.server.refreshMCPTools()
+
 	payload := map[string]interface{}{
 		"action":  "add",
 		"created": created,
@@ -250,6 +252,10 @@ func (t *mcpManageTool) handleRemove(p mcpManageParams) (*tools.Result, error) {
 		}
 	}
 
+	if removed {
+		t.server.refreshMCPTools()
+	}
+
 	payload := map[string]interface{}{
 		"action":  "remove",
 		"removed": removed,
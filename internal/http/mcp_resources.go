@@ -0,0 +1,411 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+const (
+	readMCPResourceToolName = "read_mcp_resource"
+	// mcpListingTTL keeps resource and prompt listings briefly so the agent
+	// and the skills API do not ask every server on each request.
+	mcpListingTTL = time.Minute
+	// mcpResourceMaxBytes caps the text of a fetched resource.
+	mcpResourceMaxBytes  = 64 * 1024
+	mcpPromptSkillPrefix = "mcp_prompt:"
+)
+
+type mcpResourceInfo struct {
+	Server      string `json:"server"`
+	URI         string `json:"uri"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mime_type,omitempty"`
+	Size        int64  `json:"size,omitempty"`
+}
+
+type mcpPromptInfo struct {
+	ServerID    string
+	Server      string
+	Name        string
+	Description string
+	Arguments   []MCPPromptArgument
+}
+
+// MCPPromptArgument describes an argument of a server-provided prompt.
+type MCPPromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// MCPPromptRef selects a server-provided prompt, as listed by /skills/builtin
+// with kind "mcp_prompt", and fills in its arguments.
+type MCPPromptRef struct {
+	ID        string            `json:"id"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+type mcpListingCache struct {
+	mu          sync.Mutex
+	resources   []mcpResourceInfo
+	resourcesAt time.Time
+	prompts     []mcpPromptInfo
+	promptsAt   time.Time
+}
+
+// supports reports whether the server announced the capability.
+func (c *mcpConnection) supports(capability string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.capabilities[capability]
+	return ok
+}
+
+// connections returns the connected servers ordered by name.
+func (b *mcpBridge) connections() []*mcpConnection {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	conns := make([]*mcpConnection, 0, len(b.conns))
+	for _, conn := range b.conns {
+		conns = append(conns, conn)
+	}
+	b.mu.Unlock()
+	sort.Slice(conns, func(i, j int) bool {
+		return strings.ToLower(conns[i].cfg.Name) < strings.ToLower(conns[j].cfg.Name)
+	})
+	return conns
+}
+
+// connectionNamed finds a connected server by name or ID.
+func (b *mcpBridge) connectionNamed(name string) *mcpConnection {
+	name = strings.TrimSpace(name)
+	for _, conn := range b.connections() {
+		if conn.serverID == name || strings.EqualFold(conn.cfg.Name, name) {
+			return conn
+		}
+	}
+	return nil
+}
+
+// listResources returns the resources of every server that offers them.
+// Servers that fail are logged and skipped.
+func (b *mcpBridge) listResources(ctx context.Context) []mcpResourceInfo {
+	var all []mcpResourceInfo
+	for _, conn := range b.connections() {
+		resources, err := b.serverResources(ctx, conn)
+		if err != nil {
+			logging.Warn("Failed to list resources of MCP server %s: %v", conn.cfg.Name, err)
+			continue
+		}
+		all = append(all, resources...)
+	}
+	return all
+}
+
+func (b *mcpBridge) serverResources(ctx context.Context, conn *mcpConnection) ([]mcpResourceInfo, error) {
+	if !conn.supports("resources") {
+		return nil, nil
+	}
+	conn.listings.mu.Lock()
+	defer conn.listings.mu.Unlock()
+	if time.Since(conn.listings.resourcesAt) < mcpListingTTL {
+		return conn.listings.resources, nil
+	}
+
+	client, cfg, err := b.session(ctx, conn.serverID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := listMCPItems(ctx, client, cfg, "resources/list", "resources")
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]mcpResourceInfo, 0, len(items))
+	for _, item := range items {
+		entry := mapFromAny(item)
+		uri := strings.TrimSpace(asString(entry["uri"]))
+		if uri == "" {
+			continue
+		}
+		size, _ := entry["size"].(float64)
+		resources = append(resources, mcpResourceInfo{
+			Server:      cfg.Name,
+			URI:         uri,
+			Name:        strings.TrimSpace(asString(entry["name"])),
+			Description: strings.TrimSpace(asString(entry["description"])),
+			MimeType:    strings.TrimSpace(asString(entry["mimeType"])),
+			Size:        int64(size),
+		})
+	}
+	conn.listings.resources = resources
+	conn.listings.resourcesAt = time.Now()
+	return resources, nil
+}
+
+// readResource fetches a resource. Without a server name the server is the
+// one that lists the URI.
+func (b *mcpBridge) readResource(ctx context.Context, server string, uri string) (string, error) {
+	var conn *mcpConnection
+	if strings.TrimSpace(server) != "" {
+		if conn = b.connectionNamed(server); conn == nil {
+			return "", fmt.Errorf("MCP server %q is not connected", server)
+		}
+	} else {
+		for _, candidate := range b.connections() {
+			resources, err := b.serverResources(ctx, candidate)
+			if err != nil {
+				continue
+			}
+			for _, resource := range resources {
+				if resource.URI == uri {
+					conn = candidate
+					break
+				}
+			}
+			if conn != nil {
+				break
+			}
+		}
+		if conn == nil {
+			return "", fmt.Errorf("no MCP server lists %s; pass server to read it anyway", uri)
+		}
+	}
+
+	client, cfg, err := b.session(ctx, conn.serverID)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+	resp, err := client.call(ctx, "resources/read", map[string]interface{}{"uri": uri})
+	if err != nil {
+		return "", fmt.Errorf("MCP server %s: %w", cfg.Name, err)
+	}
+
+	contents, _ := mapFromAny(resp["result"])["contents"].([]interface{})
+	parts := make([]string, 0, len(contents))
+	for _, item := range contents {
+		content := mapFromAny(item)
+		header := fmt.Sprintf("--- %s", asString(content["uri"]))
+		if mimeType := asString(content["mimeType"]); mimeType != "" {
+			header += " (" + mimeType + ")"
+		}
+		body := asString(content["text"])
+		if blob := asString(content["blob"]); body == "" && blob != "" {
+			body = fmt.Sprintf("[binary content, %d bytes base64-encoded, not shown]", len(blob))
+		} else if len(body) > mcpResourceMaxBytes {
+			body = fmt.Sprintf("%s\n[truncated: showing the first %d of %d bytes]", strings.ToValidUTF8(body[:mcpResourceMaxBytes], ""), mcpResourceMaxBytes, len(body))
+		}
+		parts = append(parts, header+" ---\n"+body)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("MCP server %s returned no content for %s", cfg.Name, uri)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// listPrompts returns the prompts of every server that offers them.
+func (b *mcpBridge) listPrompts(ctx context.Context) []mcpPromptInfo {
+	var all []mcpPromptInfo
+	for _, conn := range b.connections() {
+		prompts, err := b.serverPrompts(ctx, conn)
+		if err != nil {
+			logging.Warn("Failed to list prompts of MCP server %s: %v", conn.cfg.Name, err)
+			continue
+		}
+		all = append(all, prompts...)
+	}
+	return all
+}
+
+func (b *mcpBridge) serverPrompts(ctx context.Context, conn *mcpConnection) ([]mcpPromptInfo, error) {
+	if !conn.supports("prompts") {
+		return nil, nil
+	}
+	conn.listings.mu.Lock()
+	defer conn.listings.mu.Unlock()
+	if time.Since(conn.listings.promptsAt) < mcpListingTTL {
+		return conn.listings.prompts, nil
+	}
+
+	client, cfg, err := b.session(ctx, conn.serverID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := listMCPItems(ctx, client, cfg, "prompts/list", "prompts")
+	if err != nil {
+		return nil, err
+	}
+	prompts := make([]mcpPromptInfo, 0, len(items))
+	for _, item := range items {
+		entry := mapFromAny(item)
+		name := strings.TrimSpace(asString(entry["name"]))
+		if name == "" {
+			continue
+		}
+		prompt := mcpPromptInfo{ServerID: conn.serverID, Server: cfg.Name, Name: name, Description: strings.TrimSpace(asString(entry["description"]))}
+		rawArgs, _ := entry["arguments"].([]interface{})
+		for _, rawArg := range rawArgs {
+			arg := mapFromAny(rawArg)
+			required, _ := arg["required"].(bool)
+			prompt.Arguments = append(prompt.Arguments, MCPPromptArgument{
+				Name:        asString(arg["name"]),
+				Description: asString(arg["description"]),
+				Required:    required,
+			})
+		}
+		prompts = append(prompts, prompt)
+	}
+	conn.listings.prompts = prompts
+	conn.listings.promptsAt = time.Now()
+	return prompts, nil
+}
+
+// mcpPromptSkillID identifies a prompt in the skills API.
+func mcpPromptSkillID(serverID string, name string) string {
+	return mcpPromptSkillPrefix + serverID + ":" + name
+}
+
+// renderPrompt fetches the prompt with prompts/get and flattens its messages
+// into text that can seed a session.
+func (b *mcpBridge) renderPrompt(ctx context.Context, ref MCPPromptRef) (string, error) {
+	serverID, name, ok := strings.Cut(strings.TrimPrefix(ref.ID, mcpPromptSkillPrefix), ":")
+	if !ok || !strings.HasPrefix(ref.ID, mcpPromptSkillPrefix) || name == "" {
+		return "", fmt.Errorf("invalid MCP prompt id %q", ref.ID)
+	}
+	if b == nil || b.connectionNamed(serverID) == nil {
+		return "", fmt.Errorf("MCP server %s is not connected", serverID)
+	}
+	client, cfg, err := b.session(ctx, serverID)
+	if err != nil {
+		return "", err
+	}
+	arguments := ref.Arguments
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	defer cancel()
+	resp, err := client.call(ctx, "prompts/get", map[string]interface{}{"name": name, "arguments": arguments})
+	if err != nil {
+		return "", fmt.Errorf("MCP server %s: %w", cfg.Name, err)
+	}
+
+	messages, _ := mapFromAny(resp["result"])["messages"].([]interface{})
+	parts := make([]string, 0, len(messages))
+	for _, item := range messages {
+		message := mapFromAny(item)
+		text := mcpContentText([]interface{}{message["content"]})
+		if role := asString(message["role"]); role != "" && role != "user" {
+			text = role + ": " + text
+		}
+		parts = append(parts, text)
+	}
+	if len(parts) == 0 {
+		return "", fmt.Errorf("MCP prompt %s returned no messages", name)
+	}
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// readMCPResourceTool lists and reads resources of the connected MCP servers.
+type readMCPResourceTool struct {
+	server *Server
+}
+
+type readMCPResourceParams struct {
+	Action string `json:"action"`
+	Server string `json:"server,omitempty"`
+	URI    string `json:"uri,omitempty"`
+}
+
+func newReadMCPResourceTool(server *Server) *readMCPResourceTool {
+	return &readMCPResourceTool{server: server}
+}
+
+func (t *readMCPResourceTool) Name() string {
+	return readMCPResourceToolName
+}
+
+func (t *readMCPResourceTool) Description() string {
+	return `List or read resources (documents such as database schemas or files) exposed by enabled MCP servers.
+Actions:
+- list: list resources with server, URI, MIME type and size
+- read: fetch a resource by uri (text is capped at 64 KB)`
+}
+
+func (t *readMCPResourceTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"description": "Operation to perform",
+				"enum":        []string{"list", "read"},
+			},
+			"server": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional MCP server name. Limits list to one server; for read it is only needed when no server lists the URI.",
+			},
+			"uri": map[string]interface{}{
+				"type":        "string",
+				"description": "Required for action=read. Resource URI from action=list.",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *readMCPResourceTool) Execute(ctx context.Context, params json.RawMessage) (*tools.Result, error) {
+	var p readMCPResourceParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	bridge := t.server.mcpTools
+
+	switch strings.ToLower(strings.TrimSpace(p.Action)) {
+	case "list":
+		resources := bridge.listResources(ctx)
+		if server := strings.TrimSpace(p.Server); server != "" {
+			filtered := make([]mcpResourceInfo, 0, len(resources))
+			for _, resource := range resources {
+				if strings.EqualFold(resource.Server, server) {
+					filtered = append(filtered, resource)
+				}
+			}
+			resources = filtered
+		}
+		if resources == nil {
+			resources = []mcpResourceInfo{}
+		}
+		return jsonToolOutput(map[string]interface{}{
+			"action":    "list",
+			"count":     len(resources),
+			"resources": resources,
+		})
+	case "read":
+		uri := strings.TrimSpace(p.URI)
+		if uri == "" {
+			return &tools.Result{Success: false, Error: "uri is required for action=read"}, nil
+		}
+		text, err := bridge.readResource(ctx, p.Server, uri)
+		if err != nil {
+			return &tools.Result{Success: false, Error: err.Error()}, nil
+		}
+		return &tools.Result{Success: true, Output: text}, nil
+	default:
+		return &tools.Result{Success: false, Error: "invalid action; expected one of: list, read"}, nil
+	}
+}
+
+var _ tools.Tool = (*readMCPResourceTool)(nil)
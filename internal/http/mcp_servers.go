@@ -8,9 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"os/exec"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -175,6 +173,7 @@ func (s *Server) handleCreateMCPServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.refreshMCPTools()
 	s.jsonResponse(w, http.StatusCreated, mcpServerToResponse(server))
 }
 
@@ -224,6 +223,7 @@ func (s *Server) handleUpdateMCPServer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.refreshMCPTools()
 	s.jsonResponse(w, http.StatusOK, mcpServerToResponse(next))
 }
 
@@ -233,6 +233,7 @@ func (s *Server) handleDeleteMCPServer(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, http.StatusInternalServerError, "Failed to delete MCP server: "+err.Error())
 		return
 	}
+	s.refreshMCPTools()
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -680,17 +681,7 @@ func (s *Server) testMCPStdioOnce(ctx context.Context, cfg *mcpServerConfig, col
 	if cfg.Cwd != "" {
 		cmd.Dir = cfg.Cwd
 	}
-	cmd.Env = append([]string{}, os.Environ()...)
-	if len(cfg.Env) > 0 {
-		keys := make([]string, 0, len(cfg.Env))
-		for key := range cfg.Env {
-			keys = append(keys, key)
-		}
-		sort.Strings(keys)
-		for _, key := range keys {
-			cmd.Env = append(cmd.Env, key+"="+cfg.Env[key])
-		}
-	}
+	cmd.Env = mcpCommandEnv(cfg)
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
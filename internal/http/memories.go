@@ -0,0 +1,64 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// MemoryResponse is a memory stored by the memory tool.
+type MemoryResponse struct {
+	Scope     string    `json:"scope"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// memoryScopeFromQuery reads the scope ("global" or "job:<id>") from the
+// scope or job_id query parameter.
+func memoryScopeFromQuery(r *http.Request) string {
+	if jobID := strings.TrimSpace(r.URL.Query().Get("job_id")); jobID != "" {
+		return storage.MemoryJobScope(jobID)
+	}
+	return strings.TrimSpace(r.URL.Query().Get("scope"))
+}
+
+// handleListMemories lists memories, optionally limited to one scope.
+func (s *Server) handleListMemories(w http.ResponseWriter, r *http.Request) {
+	memories, err := s.store.ListMemories(memoryScopeFromQuery(r))
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to list memories: "+err.Error())
+		return
+	}
+
+	resp := make([]MemoryResponse, len(memories))
+	for i, mem := range memories {
+		resp[i] = MemoryResponse{
+			Scope:     mem.Scope,
+			Key:       mem.Key,
+			Value:     mem.Value,
+			CreatedAt: mem.CreatedAt,
+			UpdatedAt: mem.UpdatedAt,
+		}
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
+
+// handleDeleteMemories deletes one memory when key is given, otherwise every
+// memory of the scope.
+func (s *Server) handleDeleteMemories(w http.ResponseWriter, r *http.Request) {
+	scope := memoryScopeFromQuery(r)
+	if scope == "" {
+		s.errorResponse(w, http.StatusBadRequest, "scope or job_id is required")
+		return
+	}
+	key := strings.TrimSpace(r.URL.Query().Get("key"))
+	if err := s.store.DeleteMemories(scope, key); err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to delete memories: "+err.Error())
+		return
+	}
+	s.jsonResponse(w, http.StatusOK, map[string]bool{"deleted": true})
+}
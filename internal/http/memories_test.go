@@ -0,0 +1,60 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestMemoriesEndpoints(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, mem := range []*storage.Memory{
+		{Scope: storage.MemoryJobScope("job-1"), Key: "a", Value: "1", CreatedAt: now, UpdatedAt: now},
+		{Scope: storage.MemoryJobScope("job-1"), Key: "b", Value: "2", CreatedAt: now, UpdatedAt: now},
+		{Scope: storage.MemoryScopeGlobal, Key: "c", Value: "3", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := server.store.SaveMemory(mem); err != nil {
+			t.Fatalf("SaveMemory: %v", err)
+		}
+	}
+
+	list := func(query string) []MemoryResponse {
+		t.Helper()
+		rec := serveAuthorized(server, http.MethodGet, "/memories"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("list: status %d body=%s", rec.Code, rec.Body.String())
+		}
+		var resp []MemoryResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	if got := list(""); len(got) != 3 {
+		t.Fatalf("expected 3 memories, got %+v", got)
+	}
+	if got := list("?job_id=job-1"); len(got) != 2 || got[0].Key != "a" || got[0].Value != "1" {
+		t.Fatalf("unexpected job memories %+v", got)
+	}
+
+	if rec := serveAuthorized(server, http.MethodDelete, "/memories", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("delete without scope: status %d", rec.Code)
+	}
+	if rec := serveAuthorized(server, http.MethodDelete, "/memories?job_id=job-1&key=a", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete key: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if got := list("?scope=job:job-1"); len(got) != 1 || got[0].Key != "b" {
+		t.Fatalf("only b should remain, got %+v", got)
+	}
+	if rec := serveAuthorized(server, http.MethodDelete, "/memories?scope=job:job-1", ""); rec.Code != http.StatusOK {
+		t.Fatalf("delete scope: status %d", rec.Code)
+	}
+	if got := list(""); len(got) != 1 || got[0].Scope != storage.MemoryScopeGlobal {
+		t.Fatalf("only the global memory should remain, got %+v", got)
+	}
+}
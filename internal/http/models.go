@@ -0,0 +1,173 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/llm"
+)
+
+// modelListTTL is how long GET /models reuses a provider's model list before
+// asking the provider again.
+const modelListTTL = 5 * time.Minute
+
+// ModelEntry is one model offered by a provider.
+type ModelEntry struct {
+	ID      string `json:"id"`
+	Default bool   `json:"default,omitempty"`
+}
+
+// ProviderModels is the model list of one provider in GET /models.
+type ProviderModels struct {
+	Provider     string       `json:"provider"`
+	DisplayName  string       `json:"display_name"`
+	Active       bool         `json:"active,omitempty"`
+	DefaultModel string       `json:"default_model,omitempty"`
+	Models       []ModelEntry `json:"models"`
+	Error        string       `json:"error,omitempty"`
+	FetchedAt    time.Time    `json:"fetched_at"`
+}
+
+// ListModelsResponse is the body of GET /models.
+type ListModelsResponse struct {
+	Providers []ProviderModels `json:"providers"`
+}
+
+type cachedModelList struct {
+	ids       []string
+	err       string
+	fetchedAt time.Time
+}
+
+// modelListCache keeps provider model lists for modelListTTL. Failures are
+// cached too so an unreachable provider is not retried on every request.
+type modelListCache struct {
+	mu      sync.Mutex
+	entries map[config.ProviderType]cachedModelList
+}
+
+func (c *modelListCache) get(providerType config.ProviderType, now time.Time) (cachedModelList, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[providerType]
+	if !ok || now.Sub(entry.fetchedAt) > modelListTTL {
+		return cachedModelList{}, false
+	}
+	return entry, true
+}
+
+func (c *modelListCache) put(providerType config.ProviderType, entry cachedModelList) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[config.ProviderType]cachedModelList)
+	}
+	c.entries[providerType] = entry
+}
+
+// reset drops every cached list, after provider settings change.
+func (c *modelListCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = nil
+}
+
+// modelListProviders returns the direct providers whose models GET /models
+// lists; aggregates and the router have no models of their own.
+func modelListProviders() []config.ProviderDefinition {
+	var defs []config.ProviderDefinition
+	for _, def := range config.SupportedProviders() {
+		if def.Type == config.ProviderFallback || def.Type == config.ProviderAutoRouter {
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+// fetchProviderModels asks one provider for its models through its client.
+func (s *Server) fetchProviderModels(ctx context.Context, providerType config.ProviderType) cachedModelList {
+	entry := cachedModelList{fetchedAt: time.Now()}
+	client, err := s.createBaseLLMClient(providerType, "")
+	if err != nil {
+		entry.err = err.Error()
+		return entry
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	ids, err := llm.ListModelIDs(ctx, client)
+	if err != nil {
+		entry.err = err.Error()
+		return entry
+	}
+	entry.ids = ids
+	return entry
+}
+
+// handleListModels lists the models of every provider, tagged with the
+// provider and with each provider's configured model flagged as default.
+// Query parameters: provider limits the list to one provider, refresh=true
+// bypasses the cache.
+func (s *Server) handleListModels(w http.ResponseWriter, r *http.Request) {
+	defs := modelListProviders()
+	if ref := config.NormalizeProviderRef(r.URL.Query().Get("provider")); ref != "" {
+		var selected []config.ProviderDefinition
+		for _, def := range defs {
+			if string(def.Type) == ref {
+				selected = append(selected, def)
+			}
+		}
+		if len(selected) == 0 {
+			s.errorResponse(w, http.StatusBadRequest, "Unknown provider: "+ref)
+			return
+		}
+		defs = selected
+	}
+	refresh, _ := strconv.ParseBool(r.URL.Query().Get("refresh"))
+
+	now := time.Now()
+	entries := make([]cachedModelList, len(defs))
+	var wg sync.WaitGroup
+	for i, def := range defs {
+		if !refresh {
+			if entry, ok := s.modelLists.get(def.Type, now); ok {
+				entries[i] = entry
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(i int, providerType config.ProviderType) {
+			defer wg.Done()
+			entry := s.fetchProviderModels(r.Context(), providerType)
+			if r.Context().Err() == nil {
+				s.modelLists.put(providerType, entry)
+			}
+			entries[i] = entry
+		}(i, def.Type)
+	}
+	wg.Wait()
+
+	active := config.NormalizeProviderRef(s.config.ActiveProvider)
+	resp := ListModelsResponse{Providers: make([]ProviderModels, 0, len(defs))}
+	for i, def := range defs {
+		defaultModel := s.resolveModelForProvider(def.Type)
+		models := make([]ModelEntry, 0, len(entries[i].ids))
+		for _, id := range entries[i].ids {
+			models = append(models, ModelEntry{ID: id, Default: id == defaultModel})
+		}
+		resp.Providers = append(resp.Providers, ProviderModels{
+			Provider:     string(def.Type),
+			DisplayName:  def.DisplayName,
+			Active:       string(def.Type) == active,
+			DefaultModel: defaultModel,
+			Models:       models,
+			Error:        entries[i].err,
+			FetchedAt:    entries[i].fetchedAt,
+		})
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
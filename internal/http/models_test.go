@@ -0,0 +1,75 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+)
+
+func TestListModelsEndpointCachesProviderLists(t *testing.T) {
+	t.Setenv("A2GENT_PARENT_PROXY_URL", "")
+	t.Setenv("LM_STUDIO_BASE_URL", "")
+	t.Setenv("LMSTUDIO_BASE_URL", "")
+
+	var hits atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path != "/v1/models" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[{"id":"qwen3-coder"},{"id":"llama-3.3"}]}`))
+	}))
+	defer upstream.Close()
+
+	server, _ := newQuestionTestServer(t)
+	server.config.ActiveProvider = string(config.ProviderLMStudio)
+	server.config.Providers = map[string]config.Provider{
+		string(config.ProviderLMStudio): {Name: "lmstudio", BaseURL: upstream.URL + "/v1", Model: "llama-3.3"},
+	}
+
+	list := func(query string) ListModelsResponse {
+		t.Helper()
+		rec := serveAuthorized(server, http.MethodGet, "/models"+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET /models%s: status %d body=%s", query, rec.Code, rec.Body.String())
+		}
+		var resp ListModelsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	resp := list("?provider=lmstudio")
+	if len(resp.Providers) != 1 {
+		t.Fatalf("expected one provider, got %+v", resp.Providers)
+	}
+	got := resp.Providers[0]
+	if got.Provider != "lmstudio" || !got.Active || got.Error != "" {
+		t.Fatalf("unexpected provider entry %+v", got)
+	}
+	want := []ModelEntry{{ID: "qwen3-coder"}, {ID: "llama-3.3", Default: true}}
+	if len(got.Models) != len(want) || got.Models[0] != want[0] || got.Models[1] != want[1] {
+		t.Fatalf("models = %+v, want %+v", got.Models, want)
+	}
+
+	list("?provider=lmstudio")
+	if n := hits.Load(); n != 1 {
+		t.Fatalf("expected the cached list to be reused, upstream hit %d times", n)
+	}
+	list("?provider=lmstudio&refresh=true")
+	if n := hits.Load(); n != 2 {
+		t.Fatalf("expected refresh to bypass the cache, upstream hit %d times", n)
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/models?provider=nope", "")
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("unknown provider: status %d", rec.Code)
+	}
+}
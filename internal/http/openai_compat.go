@@ -0,0 +1,345 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/google/uuid"
+)
+
+// chatCompletionSessionHeader names the session a chat completion runs in.
+// It is also set on every response so clients can continue the session.
+const chatCompletionSessionHeader = "X-Session-ID"
+
+// ChatCompletionRequest is the OpenAI chat-completions request body.
+// Sampling options are ignored, and so is the model: the session's provider
+// and model run the request, and the response names the model that did.
+type ChatCompletionRequest struct {
+	Model     string                  `json:"model"`
+	Messages  []ChatCompletionMessage `json:"messages"`
+	Stream    bool                    `json:"stream"`
+	User      string                  `json:"user,omitempty"`
+	Tools     json.RawMessage         `json:"tools,omitempty"`
+	Functions json.RawMessage         `json:"functions,omitempty"`
+}
+
+// ChatCompletionMessage is one message of a chat completion. Content is a
+// string or, in requests, an array of text and image_url parts.
+type ChatCompletionMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// ChatCompletionResponse is a chat.completion or chat.completion.chunk object.
+type ChatCompletionResponse struct {
+	ID      string                 `json:"id"`
+	Object  string                 `json:"object"`
+	Created int64                  `json:"created"`
+	Model   string                 `json:"model"`
+	Choices []ChatCompletionChoice `json:"choices"`
+	Usage   *ChatCompletionUsage   `json:"usage,omitempty"`
+}
+
+// ChatCompletionChoice holds the message of a completion, or the delta of a
+// streamed chunk.
+type ChatCompletionChoice struct {
+	Index        int                         `json:"index"`
+	Message      *ChatCompletionReplyMessage `json:"message,omitempty"`
+	Delta        *ChatCompletionReplyMessage `json:"delta,omitempty"`
+	FinishReason *string                     `json:"finish_reason"`
+}
+
+// ChatCompletionReplyMessage is the assistant message of a response.
+type ChatCompletionReplyMessage struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionUsage reports the tokens the whole agent run used.
+type ChatCompletionUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func chatCompletionUsage(usage llm.TokenUsage) *ChatCompletionUsage {
+	return &ChatCompletionUsage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      usage.InputTokens + usage.OutputTokens,
+	}
+}
+
+// chatCompletionText flattens message content into text and images.
+func chatCompletionText(raw json.RawMessage) (string, []MessageImagePayload, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil, nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil, nil
+	}
+	var parts []struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		ImageURL struct {
+			URL string `json:"url"`
+		} `json:"image_url"`
+	}
+	if err := json.Unmarshal(raw, &parts); err != nil {
+		return "", nil, fmt.Errorf("content must be a string or an array of parts")
+	}
+	var texts []string
+	var images []MessageImagePayload
+	for _, part := range parts {
+		switch part.Type {
+		case "text":
+			texts = append(texts, part.Text)
+		case "image_url":
+			if part.ImageURL.URL != "" {
+				images = append(images, MessageImagePayload{URL: part.ImageURL.URL})
+			}
+		}
+	}
+	return strings.Join(texts, "\n"), images, nil
+}
+
+// chatCompletionError writes an error in the OpenAI error shape.
+func (s *Server) chatCompletionError(w http.ResponseWriter, status int, errType, message string) {
+	s.jsonResponse(w, status, map[string]interface{}{
+		"error": map[string]string{"message": message, "type": errType},
+	})
+}
+
+// chatCompletionSession returns the session a request runs in. The session
+// header must name an existing session; the user field continues a session
+// when it names one and otherwise starts a new one, since clients also use
+// it for end-user IDs. A new session is seeded with the earlier turns of the
+// request, while an existing one already holds them.
+func (s *Server) chatCompletionSession(r *http.Request, req *ChatCompletionRequest) (*session.Session, bool, error) {
+	if id := strings.TrimSpace(r.Header.Get(chatCompletionSessionHeader)); id != "" {
+		sess, err := s.sessionManager.Get(id)
+		if err != nil {
+			return nil, false, fmt.Errorf("session not found: %s", id)
+		}
+		return sess, false, nil
+	}
+	if id := strings.TrimSpace(req.User); id != "" {
+		if sess, err := s.sessionManager.Get(id); err == nil {
+			return sess, false, nil
+		}
+	}
+	sess, err := s.sessionManager.Create("build")
+	return sess, true, err
+}
+
+// handleChatCompletions serves the OpenAI chat-completions API on top of an
+// agent run, for tools that only speak that API. The last message must come
+// from the user; it is the prompt of the run. Tools in the request are
+// ignored: the agent uses its own.
+func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.chatCompletionError(w, http.StatusBadRequest, "invalid_request_error", "Invalid request body: "+err.Error())
+		return
+	}
+	if len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != "user" {
+		s.chatCompletionError(w, http.StatusBadRequest, "invalid_request_error", "messages must end with a user message")
+		return
+	}
+	prompt, rawImages, err := chatCompletionText(req.Messages[len(req.Messages)-1].Content)
+	if err != nil {
+		s.chatCompletionError(w, http.StatusBadRequest, "invalid_request_error", "Invalid last message: "+err.Error())
+		return
+	}
+	images, err := normalizeIncomingImages(rawImages)
+	if err != nil {
+		s.chatCompletionError(w, http.StatusBadRequest, "invalid_request_error", "Invalid images: "+err.Error())
+		return
+	}
+	if strings.TrimSpace(prompt) == "" && len(images) == 0 {
+		s.chatCompletionError(w, http.StatusBadRequest, "invalid_request_error", "The last user message is empty")
+		return
+	}
+	if hasJSONValue(req.Tools) || hasJSONValue(req.Functions) {
+		logging.Warn("Chat completion request defines its own tools; ignoring them in favour of the agent's tools")
+		w.Header().Set("Warning", `299 - "tool definitions in the request are ignored"`)
+	}
+
+	sess, created, err := s.chatCompletionSession(r, &req)
+	if err != nil {
+		s.chatCompletionError(w, http.StatusNotFound, "invalid_request_error", err.Error())
+		return
+	}
+	defer s.queueTelegramSessionMessageSync(sess.ID)
+	if created {
+		for _, msg := range req.Messages[:len(req.Messages)-1] {
+			text, _, err := chatCompletionText(msg.Content)
+			if err != nil {
+				continue
+			}
+			switch msg.Role {
+			case "user":
+				sess.AddUserMessage(text)
+			case "assistant":
+				sess.AddAssistantMessage(text, nil)
+			default:
+				// System and tool messages are left out: the agent
+				// brings its own system prompt and tools.
+			}
+		}
+	}
+	sess.AddUserMessageWithImages(prompt, images)
+	sess.SetStatus(session.StatusRunning)
+	if err := s.sessionManager.Save(sess); err != nil {
+		s.chatCompletionError(w, http.StatusInternalServerError, "server_error", "Failed to update session: "+err.Error())
+		return
+	}
+	w.Header().Set(chatCompletionSessionHeader, sess.ID)
+
+	runCtx, cancelRun := context.WithCancel(r.Context())
+	runID := s.registerActiveSessionRun(sess.ID, cancelRun)
+	defer func() {
+		cancelRun()
+		s.unregisterActiveSessionRun(sess.ID, runID)
+	}()
+
+	providerType := s.resolveSessionProviderType(sess)
+	model := s.resolveSessionModel(sess, providerType)
+	target, err := s.resolveExecutionTarget(runCtx, providerType, model, messageForRouting(prompt, len(images)), sess)
+	if err != nil {
+		sess.AddAssistantMessage(fmt.Sprintf("Unable to start request: %s", err.Error()), nil)
+		sess.SetStatus(session.StatusFailed)
+		s.sessionManager.Save(sess)
+		s.chatCompletionError(w, http.StatusBadRequest, "invalid_request_error", "Provider configuration error: "+err.Error())
+		return
+	}
+	if setSessionRoutedProviderAndModel(sess, providerType, target.ProviderType, target.Model) {
+		if err := s.sessionManager.Save(sess); err != nil {
+			logging.Warn("Failed to persist session routed target metadata: %v", err)
+		}
+	}
+
+	agentDef := s.agentDefinition(sess, sess.AgentID)
+	agentConfig := agent.Config{
+		Name:          sess.AgentID,
+		Model:         target.Model,
+		SystemPrompt:  s.buildSystemPromptForSession(sess),
+		MaxSteps:      s.config.MaxSteps,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   s.resolveSessionTemperature(sess, agentDef),
+		ContextWindow: target.ContextWindow,
+	}
+	agentDef.Apply(&agentConfig)
+	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
+
+	completion := ChatCompletionResponse{
+		ID:      "chatcmpl-" + uuid.New().String(),
+		Created: time.Now().Unix(),
+		Model:   target.Model,
+	}
+
+	var writeChunk func(choice ChatCompletionChoice, usage *ChatCompletionUsage) bool
+	if req.Stream {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			s.chatCompletionError(w, http.StatusInternalServerError, "server_error", "Streaming is not supported by the server")
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		writeChunk = func(choice ChatCompletionChoice, usage *ChatCompletionUsage) bool {
+			chunk := completion
+			chunk.Object = "chat.completion.chunk"
+			chunk.Choices = []ChatCompletionChoice{choice}
+			chunk.Usage = usage
+			data, err := json.Marshal(chunk)
+			if err != nil {
+				return false
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return false
+			}
+			flusher.Flush()
+			return true
+		}
+		if !writeChunk(ChatCompletionChoice{Delta: &ChatCompletionReplyMessage{Role: "assistant"}}, nil) {
+			return
+		}
+	}
+
+	content, usage, err := ag.RunWithEvents(runCtx, sess, prompt, func(ev agent.Event) {
+		switch ev.Type {
+		case agent.EventAssistantDelta:
+			if writeChunk != nil && ev.Delta != "" {
+				_ = writeChunk(ChatCompletionChoice{Delta: &ChatCompletionReplyMessage{Content: ev.Delta}}, nil)
+			}
+		case agent.EventProviderTrace:
+			if ev.Provider != nil {
+				s.applyProviderTraceToSession(sess, target.ProviderType, ev.Provider)
+			}
+		}
+	})
+	timedOut := errors.Is(err, agent.ErrMaxDurationExceeded)
+	if err != nil && !timedOut {
+		status, errType, message := http.StatusInternalServerError, "server_error", ""
+		if isCancellationError(err) {
+			sess.SetStatus(session.StatusPaused)
+			status, errType, message = http.StatusConflict, "request_canceled", "Request was canceled before completion"
+		} else {
+			adaptedErr := s.adaptProviderErrorMessage(target.ProviderType, err)
+			sess.AddAssistantMessage(fmt.Sprintf("Request failed: %s", adaptedErr.Error()), nil)
+			sess.SetStatus(session.StatusFailed)
+			message = "Agent error: " + adaptedErr.Error()
+		}
+		s.sessionManager.Save(sess)
+		if writeChunk == nil {
+			s.chatCompletionError(w, status, errType, message)
+			return
+		}
+		// The status line is already sent; report the error in-band.
+		data, _ := json.Marshal(map[string]interface{}{"error": map[string]string{"message": message, "type": errType}})
+		fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", data)
+		return
+	}
+
+	s.maybeGenerateSessionTitle(sess, target.Client, target.Model)
+
+	stop := "stop"
+	if timedOut {
+		// The run stopped at max_run_minutes with a partial answer.
+		stop = "length"
+	}
+	if writeChunk != nil {
+		if writeChunk(ChatCompletionChoice{Delta: &ChatCompletionReplyMessage{}, FinishReason: &stop}, chatCompletionUsage(usage)) {
+			fmt.Fprint(w, "data: [DONE]\n\n")
+		}
+		return
+	}
+	completion.Object = "chat.completion"
+	completion.Choices = []ChatCompletionChoice{{
+		Message:      &ChatCompletionReplyMessage{Role: "assistant", Content: content},
+		FinishReason: &stop,
+	}}
+	completion.Usage = chatCompletionUsage(usage)
+	s.jsonResponse(w, http.StatusOK, completion)
+}
+
+// hasJSONValue reports whether raw holds something other than null or an
+// empty array.
+func hasJSONValue(raw json.RawMessage) bool {
+	trimmed := strings.TrimSpace(string(raw))
+	return trimmed != "" && trimmed != "null" && trimmed != "[]"
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+)
+
+// newChatCompletionTestServer points the server at a fake LM Studio that
+// answers every request with "Hi there", streamed or not.
+func newChatCompletionTestServer(t *testing.T) *Server {
+	t.Helper()
+	t.Setenv("A2GENT_PARENT_PROXY_URL", "")
+	t.Setenv("LM_STUDIO_BASE_URL", "")
+	t.Setenv("LMSTUDIO_BASE_URL", "")
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if !body.Stream {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"choices":[{"message":{"role":"assistant","content":"Hi there"},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":3,"total_tokens":15}}`)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"choices":[{"delta":{"content":"Hi "}}]}`,
+			`{"choices":[{"delta":{"content":"there"}}]}`,
+			`{"choices":[{"delta":{},"finish_reason":"stop"}],"usage":{"prompt_tokens":12,"completion_tokens":3}}`,
+			`[DONE]`,
+		} {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+	}))
+	t.Cleanup(upstream.Close)
+
+	server, _ := newQuestionTestServer(t)
+	server.config.ActiveProvider = string(config.ProviderLMStudio)
+	server.config.Providers = map[string]config.Provider{
+		string(config.ProviderLMStudio): {Name: "lmstudio", BaseURL: upstream.URL + "/v1", Model: "local-model"},
+	}
+	return server
+}
+
+func TestChatCompletionsCreatesSessionFromHistory(t *testing.T) {
+	server := newChatCompletionTestServer(t)
+	rec := serveAuthorized(server, http.MethodPost, "/v1/chat/completions", `{
+		"model": "gpt-4o",
+		"messages": [
+			{"role": "system", "content": "Be brief."},
+			{"role": "user", "content": "hello"},
+			{"role": "assistant", "content": "hi"},
+			{"role": "user", "content": [{"type": "text", "text": "how are you?"}]}
+		],
+		"tools": [{"type": "function", "function": {"name": "client_tool"}}]
+	}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp ChatCompletionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Object != "chat.completion" || resp.Model != "local-model" || len(resp.Choices) != 1 {
+		t.Fatalf("unexpected completion %+v", resp)
+	}
+	if got := resp.Choices[0]; got.Message == nil || got.Message.Content != "Hi there" || got.FinishReason == nil || *got.FinishReason != "stop" {
+		t.Fatalf("unexpected choice %+v", got)
+	}
+	if resp.Usage == nil || resp.Usage.PromptTokens != 12 || resp.Usage.CompletionTokens != 3 || resp.Usage.TotalTokens != 15 {
+		t.Fatalf("usage should come from the run, got %+v", resp.Usage)
+	}
+	if rec.Header().Get("Warning") == "" {
+		t.Fatal("client tools should be ignored with a warning")
+	}
+
+	sess, err := server.sessionManager.Get(rec.Header().Get(chatCompletionSessionHeader))
+	if err != nil {
+		t.Fatalf("session header should name the new session: %v", err)
+	}
+	var turns []string
+	for _, msg := range sess.Messages {
+		turns = append(turns, msg.Role+":"+msg.Content)
+	}
+	want := "user:hello|assistant:hi|user:how are you?|assistant:Hi there"
+	if strings.Join(turns, "|") != want {
+		t.Fatalf("session turns = %q, want %q", strings.Join(turns, "|"), want)
+	}
+}
+
+func TestChatCompletionsStreamsIntoExistingSession(t *testing.T) {
+	server := newChatCompletionTestServer(t)
+	sess, err := server.sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{"stream":true,"messages":[{"role":"user","content":"earlier"},{"role":"user","content":"hello"}]}`))
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set(chatCompletionSessionHeader, sess.ID)
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status %d type %q body=%s", rec.Code, rec.Header().Get("Content-Type"), rec.Body.String())
+	}
+
+	var content strings.Builder
+	var last ChatCompletionResponse
+	done := false
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+		var chunk ChatCompletionResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("decode chunk %q: %v", data, err)
+		}
+		if chunk.Object != "chat.completion.chunk" || len(chunk.Choices) != 1 {
+			t.Fatalf("unexpected chunk %s", data)
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		last = chunk
+	}
+	if !done || content.String() != "Hi there" {
+		t.Fatalf("streamed %q (done=%v)", content.String(), done)
+	}
+	if last.Choices[0].FinishReason == nil || last.Usage == nil || last.Usage.TotalTokens != 15 {
+		t.Fatalf("last chunk should finish with usage, got %+v", last)
+	}
+
+	stored, err := server.sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if len(stored.Messages) != 2 || stored.Messages[0].Content != "hello" {
+		t.Fatalf("an existing session should only get the last message, got %+v", stored.Messages)
+	}
+}
+
+func TestChatCompletionsRejectsBadRequests(t *testing.T) {
+	server := newChatCompletionTestServer(t)
+	for name, tc := range map[string]struct {
+		body, session string
+		status        int
+	}{
+		"no user message last": {`{"messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`, "", http.StatusBadRequest},
+		"unknown session":      {`{"messages":[{"role":"user","content":"hi"}]}`, "missing", http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tc.body))
+		req.Header.Set("Authorization", "Bearer test-token")
+		if tc.session != "" {
+			req.Header.Set(chatCompletionSessionHeader, tc.session)
+		}
+		rec := httptest.NewRecorder()
+		server.router.ServeHTTP(rec, req)
+		if rec.Code != tc.status || !strings.Contains(rec.Body.String(), `"error"`) {
+			t.Errorf("%s: status %d body=%s", name, rec.Code, rec.Body.String())
+		}
+	}
+}
@@ -30,6 +30,7 @@ type recurringJobsParams struct {
 	TaskPromptSource string `json:"task_prompt_source,omitempty"` // "text" | "file"
 	TaskPromptFile   string `json:"task_prompt_file,omitempty"`
 	LLMProvider      string `json:"llm_provider,omitempty"`
+	Timezone         string `json:"timezone,omitempty"`
 	Enabled          *bool  `json:"enabled,omitempty"`
 
 	// delete, run_now
@@ -48,9 +49,9 @@ func (t *recurringJobsTool) Description() string {
 	return `Manage recurring jobs end-to-end.
 Actions:
 - list: list recurring jobs
-- create: create a new recurring job from natural-language schedule and task prompt
+- create: create a new job from a natural-language schedule and task prompt; one-time schedules such as "tomorrow at 9am" run once and then disable the job
 - delete: delete an existing recurring job by id
-- run_now: trigger immediate execution of a recurring job by id`
+- run_now: start a recurring job by id in the background; the returned execution is still running`
 }
 
 func (t *recurringJobsTool) Schema() map[string]interface{} {
@@ -72,7 +73,7 @@ func (t *recurringJobsTool) Schema() map[string]interface{} {
 			},
 			"schedule_text": map[string]interface{}{
 				"type":        "string",
-				"description": "Required for action=create. Human schedule text (example: every weekday at 9am).",
+				"description": "Required for action=create. Human schedule text (examples: every weekday at 9am, tomorrow at 9am).",
 			},
 			"task_prompt": map[string]interface{}{
 				"type":        "string",
@@ -91,6 +92,10 @@ func (t *recurringJobsTool) Schema() map[string]interface{} {
 				"type":        "string",
 				"description": "Optional for action=create. Provider override for this job.",
 			},
+			"timezone": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional for action=create. IANA timezone the schedule is read in (example: Europe/Berlin). Defaults to the server's timezone.",
+			},
 			"enabled": map[string]interface{}{
 				"type":        "boolean",
 				"description": "Optional for action=create. Defaults to true.",
@@ -183,7 +188,12 @@ func (t *recurringJobsTool) handleCreate(ctx context.Context, p recurringJobsPar
 		}
 	}
 
-	cronExpr, err := t.server.parseScheduleToCron(ctx, scheduleText)
+	timezone, err := normalizeJobTimezone(p.Timezone)
+	if err != nil {
+		return &tools.Result{Success: false, Error: err.Error()}, nil
+	}
+
+	parsed, err := t.server.parseSchedule(ctx, scheduleText, timezone)
 	if err != nil {
 		return &tools.Result{Success: false, Error: "failed to parse schedule: " + err.Error()}, nil
 	}
@@ -198,18 +208,18 @@ func (t *recurringJobsTool) handleCreate(ctx context.Context, p recurringJobsPar
 		ID:               uuid.New().String(),
 		Name:             name,
 		ScheduleHuman:    scheduleText,
-		ScheduleCron:     cronExpr,
 		TaskPrompt:       taskPrompt,
 		TaskPromptSource: taskPromptSource,
 		TaskPromptFile:   taskPromptFile,
 		LLMProvider:      llmProvider,
+		Timezone:         timezone,
 		Enabled:          enabled,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
 
-	if nextRun, err := t.server.calculateNextRun(cronExpr, now); err == nil {
-		job.NextRunAt = &nextRun
+	if err := jobs.ApplySchedule(job, parsed, now); err != nil {
+		return &tools.Result{Success: false, Error: "failed to schedule job: " + err.Error()}, nil
 	}
 
 	if err := t.server.store.SaveJob(job); err != nil {
@@ -259,9 +269,9 @@ func (t *recurringJobsTool) handleRunNow(ctx context.Context, p recurringJobsPar
 		return &tools.Result{Success: false, Error: "job not found: " + err.Error()}, nil
 	}
 
-	exec, err := t.server.executeJob(ctx, job)
+	exec, err := t.server.startJobRun(ctx, job)
 	if err != nil {
-		return &tools.Result{Success: false, Error: "failed to execute job: " + err.Error()}, nil
+		return &tools.Result{Success: false, Error: "failed to start job: " + err.Error()}, nil
 	}
 
 	payload := map[string]interface{}{
@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/google/uuid"
+)
+
+// requestIDHeader carries the request ID in both directions.
+const requestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds request IDs taken from clients.
+const maxRequestIDLength = 128
+
+// ErrorResponse is the body of every API error. Error keeps the message
+// field clients already read; Code names the HTTP status and RequestID
+// matches the X-Request-ID header and the request_id of the server logs.
+type ErrorResponse struct {
+	Error     string `json:"error"`
+	Code      string `json:"code"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// requestIDMiddleware gives each request an ID, reusing the X-Request-ID set
+// by a reverse proxy when it is usable. The ID is echoed in the response
+// header and carried by the request context, so that logging calls made with
+// it record request_id.
+func requestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := sanitizeRequestID(r.Header.Get(requestIDHeader))
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		logging.DebugContext(ctx, "HTTP %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// sanitizeRequestID returns id when it is short and made of visible ASCII,
+// and "" otherwise, so that client IDs cannot forge log lines.
+func sanitizeRequestID(id string) string {
+	id = strings.TrimSpace(id)
+	if len(id) > maxRequestIDLength {
+		return ""
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return ""
+		}
+	}
+	return id
+}
+
+// errorCode turns an HTTP status into the snake_case code of ErrorResponse,
+// such as not_found.
+func errorCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	text = strings.NewReplacer(" ", "_", "-", "_", "'", "").Replace(text)
+	return strings.ToLower(text)
+}
@@ -0,0 +1,36 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIDOnErrorResponses(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/jobs/missing", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set(requestIDHeader, "proxy-42")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound || rec.Header().Get(requestIDHeader) != "proxy-42" {
+		t.Fatalf("expected the proxy request ID echoed, got %d %q", rec.Code, rec.Header().Get(requestIDHeader))
+	}
+	var resp ErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Error == "" || resp.Code != "not_found" || resp.RequestID != "proxy-42" {
+		t.Fatalf("unexpected error body %+v", resp)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/health/live", nil)
+	req.Header.Set(requestIDHeader, "bad\nid")
+	rec = httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if id := rec.Header().Get(requestIDHeader); id == "" || id == "bad\nid" {
+		t.Fatalf("expected a generated request ID in place of an unsafe one, got %q", id)
+	}
+}
@@ -1,13 +1,16 @@
 package http
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -21,7 +24,9 @@ import (
 
 	"github.com/A2gent/brute/internal/a2atunnel"
 	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/agents"
 	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/http/webui"
 	"github.com/A2gent/brute/internal/jobs"
 	"github.com/A2gent/brute/internal/llm"
 	"github.com/A2gent/brute/internal/llm/anthropic"
@@ -29,19 +34,21 @@ import (
 	"github.com/A2gent/brute/internal/llm/gemini"
 	"github.com/A2gent/brute/internal/llm/lmstudio"
 	"github.com/A2gent/brute/internal/llm/openaicodex"
+	"github.com/A2gent/brute/internal/llm/reqlog"
 	"github.com/A2gent/brute/internal/llm/retry"
 	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/schedule"
 	"github.com/A2gent/brute/internal/session"
 	skillsLoader "github.com/A2gent/brute/internal/skills"
 	"github.com/A2gent/brute/internal/speechcache"
 	"github.com/A2gent/brute/internal/storage"
 	"github.com/A2gent/brute/internal/tools"
 	"github.com/A2gent/brute/internal/tools/integrationtools"
+	"github.com/A2gent/brute/internal/transcript"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 	"github.com/google/uuid"
-	"github.com/robfig/cron/v3"
 )
 
 // Server represents the HTTP API server
@@ -54,8 +61,16 @@ type Server struct {
 	router         chi.Router
 	port           int
 	speechClips    *speechcache.Store
+	backups        *tools.BackupStore
 	activeRunsMu   sync.Mutex
 	activeRuns     map[string]map[string]context.CancelFunc
+	runCancellers  []RunCanceller
+	jobRunner      JobRunner
+	mcpTools       *mcpBridge
+	modelLists     modelListCache
+	// shutdownGrace bounds how long Run waits for in-flight requests and
+	// agent runs after its context ends.
+	shutdownGrace time.Duration
 
 	// A2A gRPC tunnel (managed by a2a_tunnel.go)
 	tunnelMu     sync.Mutex
@@ -142,6 +157,9 @@ func (s *Server) toolManagerForSession(sess *session.Session) *tools.Manager {
 		manager = s.toolManager.Clone()
 	} else {
 		manager = tools.NewManager(workDir)
+		manager.SetTimeoutPolicy(s.toolManager.TimeoutPolicy())
+		manager.SetApprovalPolicy(s.toolManager.ApprovalPolicy())
+		manager.SetRedactionPolicy(s.toolManager.RedactionPolicy())
 		integrationtools.Register(manager, s.store, s.speechClips)
 		s.registerServerBackedTools(manager)
 	}
@@ -157,8 +175,9 @@ func (s *Server) toolManagerForSession(sess *session.Session) *tools.Manager {
 			allowed[strings.TrimSpace(name)] = struct{}{}
 		}
 		// Always allow essential tools
-		allowed["question"] = struct{}{}
+		allowed[tools.QuestionToolName] = struct{}{}
 		allowed["session_task_progress"] = struct{}{}
+		allowed[tools.ToolOutputToolName] = struct{}{}
 
 		for _, def := range manager.GetDefinitions() {
 			if _, ok := allowed[def.Name]; !ok {
@@ -178,15 +197,21 @@ func (s *Server) registerServerBackedTools(manager *tools.Manager) {
 	logging.Debug("Registering server-backed tools...")
 	manager.Register(newRecurringJobsTool(s))
 	manager.Register(newMCPManageTool(s))
+	manager.Register(newReadMCPResourceTool(s))
 	manager.Register(newDelegateToSubAgentTool(s))
 	manager.RegisterQuestionTool(s.sessionManager)
 	manager.RegisterSessionTaskProgressTool(s.sessionManager)
+	manager.RegisterToolOutputTool(s.sessionManager)
+	manager.RegisterMemoryTool(s.store)
+	if s.backups != nil {
+		manager.EnableUndo(s.backups)
+	}
+	s.mcpTools.register(manager)
 	logging.Debug("Server-backed tools registered. Total tools: %d", len(manager.GetDefinitions()))
 }
 
-const thinkingJobIDSettingKey = "A2GENT_THINKING_JOB_ID"
-const thinkingProjectID = "project-thinking"
-const thinkingProjectName = "Thinking"
+const thinkingJobIDSettingKey = jobs.ThinkingJobIDSettingKey
+const thinkingProjectID = jobs.ThinkingProjectID
 const llmProviderProxyEnabledSettingKey = "A2GENT_LLM_PROVIDER_PROXY_ENABLED"
 const thinkingSourceSettingKey = "A2GENT_THINKING_SOURCE"
 const thinkingTextSettingKey = "A2GENT_THINKING_TEXT"
@@ -197,13 +222,12 @@ const builtInToolsInstructionBlockType = "builtin_tools"
 const integrationSkillsInstructionBlockType = "integration_skills"
 const externalMarkdownSkillsInstructionBlockType = "external_markdown_skills"
 const mcpServersInstructionBlockType = "mcp_servers"
-const skillsFolderSettingKey = "AAGENT_SKILLS_FOLDER"
-const externalMarkdownDisabledSkillsSettingKey = "A2GENT_EXTERNAL_MARKDOWN_DISABLED_SKILLS"
+const skillsFolderSettingKey = skillsLoader.FolderSettingKey
+const externalMarkdownDisabledSkillsSettingKey = skillsLoader.DisabledSettingKey
 const disabledToolsSettingKey = "A2GENT_DISABLED_TOOLS"
 const defaultDynamicInstructionFile = "AGENTS.md"
 const maxDynamicInstructionBytes = 32 * 1024
 const sessionSystemPromptSnapshotMetadataKey = "system_prompt_snapshot"
-const thinkingRunTaskPrompt = "Run the Thinking routine.\n\nReview the current project state, execute the most valuable next step, and summarize outcomes."
 
 // NewServer creates a new HTTP server instance
 func NewServer(
@@ -228,6 +252,9 @@ func NewServer(
 		speechClips:    speechClips,
 		activeRuns:     make(map[string]map[string]context.CancelFunc),
 	}
+	if cfg != nil && cfg.DataPath != "" {
+		s.backups = tools.NewBackupStore(cfg.DataPath)
+	}
 
 	// Apply persisted sessions-folder setting to JSONL writer,
 	// falling back to <DataPath>/sessions alongside the SQLite database.
@@ -239,37 +266,65 @@ func NewServer(
 		sessionManager.SetJSONLFolder(folder)
 	}
 
+	sessionManager.SetStatusHook(s.publishSessionStatus)
+	s.mcpTools = newMCPBridge(store, toolManager)
 	s.registerServerBackedTools(s.toolManager)
 	s.setupRoutes()
 	return s
 }
 
+// httpConfig returns the listener/CORS settings, tolerating a nil config.
+func (s *Server) httpConfig() config.HTTPConfig {
+	if s.config == nil {
+		return config.HTTPConfig{}
+	}
+	return s.config.HTTP
+}
+
 // setupRoutes configures all API routes
 func (s *Server) setupRoutes() {
 	r := chi.NewRouter()
 
 	// Middleware (no logger to avoid polluting TUI output)
+	r.Use(s.proxyHeadersMiddleware)
+	r.Use(requestIDMiddleware)
+	r.Use(tracingMiddleware)
 	r.Use(middleware.Recoverer)
+	// Compresses JSON and text; event streams are left alone
+	r.Use(middleware.Compress(5))
 	r.Use(middleware.Timeout(5 * time.Minute))
 
-	// CORS configuration - allow all origins for flexibility
+	// CORS configuration from http.allowed_origins (localhost only by default)
+	httpCfg := s.httpConfig()
 	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
-		AllowCredentials: false, // Must be false when AllowedOrigins is "*"
+		AllowedOrigins:   httpCfg.EffectiveAllowedOrigins(),
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", requestIDHeader},
+		ExposedHeaders:   []string{"Link", sessionTotalCountHeader, requestIDHeader},
+		AllowCredentials: !httpCfg.AllowsAnyOrigin(), // Must be false when AllowedOrigins is "*"
 		MaxAge:           300,
 	}))
 
+	// Bearer-token auth for everything except /health and the agent card.
+	r.Use(s.requireAPIToken)
+
 	// Health check
 	r.Get("/health", s.handleHealth)
+	r.Get("/health/live", s.handleHealthLive)
+	r.Get("/health/ready", s.handleHealthReady)
+
+	// Dashboard, when built with -tags webui
+	if ui := webui.Handler(); ui != nil {
+		r.Get("/", ui.ServeHTTP)
+		r.Handle(webui.AssetPrefix+"*", http.StripPrefix(strings.TrimSuffix(webui.AssetPrefix, "/"), ui))
+	}
 
 	// A2A Agent Card (Well-Known URI per A2A spec)
 	r.Get("/.well-known/agent-card.json", s.handleAgentCard)
 
 	// A2A outbound chat (local session -> remote agent via tunnel).
 	r.Route("/a2a", func(r chi.Router) {
+		r.Post("/jsonrpc", s.handleA2AJSONRPC)
 		r.Post("/messages/send", s.handleA2AMessageSend)
 		r.Post("/messages/send/stream", s.handleA2AMessageSendStream)
 		r.Post("/outbound/sessions", s.handleCreateA2AOutboundSession)
@@ -282,6 +337,9 @@ func (s *Server) setupRoutes() {
 	r.Put("/settings", s.handleUpdateSettings)
 	r.Post("/settings/instruction-estimate", s.handleEstimateInstructionPrompt)
 
+	// Models of every provider, cached briefly.
+	r.Get("/models", s.handleListModels)
+
 	// OpenAI-compatible proxy to this agent's configured providers.
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/models", s.handleLLMProxyModels)
@@ -324,6 +382,7 @@ func (s *Server) setupRoutes() {
 		r.Get("/", s.handleListIntegrations)
 		r.Post("/", s.handleCreateIntegration)
 		r.Post("/telegram/chat-ids", s.handleDiscoverTelegramChats)
+		r.Post("/slack/events", s.handleSlackEvents)
 		// A2A tunnel status endpoints (no integrationID in path)
 		r.Get("/a2_registry/tunnel-status", s.handleA2ATunnelStatus)
 		r.Get("/a2_registry/tunnel-status/stream", s.handleA2ATunnelStatusStream)
@@ -340,6 +399,7 @@ func (s *Server) setupRoutes() {
 		r.Put("/{integrationID}", s.handleUpdateIntegration)
 		r.Delete("/{integrationID}", s.handleDeleteIntegration)
 		r.Post("/{integrationID}/test", s.handleTestIntegration)
+		r.Get("/{integrationID}/deliveries", s.handleListIntegrationDeliveries)
 	})
 
 	// MCP server registry and diagnostics
@@ -383,8 +443,14 @@ func (s *Server) setupRoutes() {
 		r.Get("/", s.handleListSessions)
 		r.Post("/", s.handleCreateSession)
 		r.Get("/{sessionID}", s.handleGetSession)
+		r.Get("/{sessionID}/export", s.handleExportSession)
+		r.Get("/{sessionID}/execution", s.handleGetSessionExecution)
+		r.Get("/{sessionID}/messages/{index}", s.handleGetSessionMessage)
+		r.Patch("/{sessionID}", s.handleUpdateSession)
+		r.Post("/{sessionID}/fork", s.handleForkSession)
 		r.Delete("/{sessionID}", s.handleDeleteSession)
 		r.Post("/{sessionID}/cancel", s.handleCancelSession)
+		r.Post("/{sessionID}/undo", s.handleUndoSessionEdit)
 		r.Put("/{sessionID}/project", s.handleUpdateSessionProject)
 		r.Put("/{sessionID}/provider", s.handleUpdateSessionProvider)
 		r.Post("/{sessionID}/chat", s.handleChat)
@@ -392,9 +458,18 @@ func (s *Server) setupRoutes() {
 		r.Get("/{sessionID}/question", s.handleGetPendingQuestion)
 		r.Post("/{sessionID}/answer", s.handleAnswerQuestion)
 		r.Post("/{sessionID}/start", s.handleStartSession)
+		r.Get("/{sessionID}/progress", s.handleGetSessionProgress)
 		r.Get("/{sessionID}/task-progress", s.handleGetTaskProgress)
+		r.Post("/{sessionID}/attachments", s.handleUploadAttachment)
+		r.Get("/{sessionID}/attachments", s.handleListAttachments)
 	})
 
+	// Files uploaded to sessions
+	r.Get("/attachments/{attachmentID}", s.handleGetAttachment)
+
+	// OpenAI-compatible chat completions backed by agent runs
+	r.Post("/v1/chat/completions", s.handleChatCompletions)
+
 	// Projects endpoints (optional grouping for sessions)
 	r.Route("/projects", func(r chi.Router) {
 		r.Get("/", s.handleListProjects)
@@ -429,14 +504,24 @@ func (s *Server) setupRoutes() {
 	r.Route("/jobs", func(r chi.Router) {
 		r.Get("/", s.handleListJobs)
 		r.Post("/", s.handleCreateJob)
+		r.Post("/preview", s.handlePreviewJobSchedule)
 		r.Get("/{jobID}", s.handleGetJob)
 		r.Put("/{jobID}", s.handleUpdateJob)
 		r.Delete("/{jobID}", s.handleDeleteJob)
 		r.Post("/{jobID}/run", s.handleRunJobNow)
 		r.Get("/{jobID}/executions", s.handleListJobExecutions)
+		r.Delete("/{jobID}/executions", s.handlePruneJobExecutions)
+		r.Get("/{jobID}/executions/{execID}", s.handleGetJobExecution)
+		r.Get("/{jobID}/executions/{execID}/output", s.handleGetJobExecutionOutput)
 		r.Get("/{jobID}/sessions", s.handleListJobSessions)
 	})
 
+	// Agent memories written by the memory tool
+	r.Route("/memories", func(r chi.Router) {
+		r.Get("/", s.handleListMemories)
+		r.Delete("/", s.handleDeleteMemories)
+	})
+
 	// My Mind filesystem endpoints
 	r.Route("/mind", func(r chi.Router) {
 		r.Get("/config", s.handleGetMindConfig)
@@ -475,58 +560,128 @@ func (s *Server) setupRoutes() {
 		r.Get("/registry/search", s.handleSearchRegistry)
 		r.Post("/registry/install", s.handleInstallSkill)
 		r.Delete("/delete", s.handleDeleteSkill)
+		r.Post("/selection", s.handleSelectSkills)
 	})
 
 	s.router = r
 }
 
-// Run starts the HTTP server
+// Run starts the HTTP server and serves until ctx ends. It returns nil after
+// a clean shutdown and the error otherwise.
 func (s *Server) Run(ctx context.Context) error {
-	addr := fmt.Sprintf("0.0.0.0:%d", s.port)
-	listener, err := net.Listen("tcp", addr)
+	httpCfg := s.httpConfig()
+	listener, serverURL, err := s.listen(httpCfg)
 	if err != nil {
 		return err
 	}
-	if tcpAddr, ok := listener.Addr().(*net.TCPAddr); ok {
-		s.port = tcpAddr.Port
+	origins := strings.Join(httpCfg.EffectiveAllowedOrigins(), ", ")
+	logging.Info("Starting HTTP server on %s (allowed origins: %s)", serverURL, origins)
+	reach := "local connections only"
+	if ip := net.ParseIP(httpCfg.EffectiveBindAddress()); strings.TrimSpace(httpCfg.UnixSocket) == "" && (ip == nil || !ip.IsLoopback()) {
+		reach = "reachable from other hosts"
 	}
-	logging.Info("Starting HTTP server on %s", listener.Addr().String())
-	fmt.Printf("HTTP API server running on http://0.0.0.0:%d (accessible from any host)\n", s.port)
+	fmt.Printf("HTTP API server running on %s (%s; allowed origins: %s)\n", serverURL, reach, origins)
 
 	go s.runTelegramDuplexLoop(ctx)
 	go s.runA2ATunnelIfConfigured()
+	go s.mcpTools.sync(ctx)
 
 	server := &http.Server{
-		Addr:    addr,
 		Handler: s.router,
 	}
 
-	// Handle graceful shutdown
+	// Handle graceful shutdown: stop accepting requests, let in-flight
+	// requests and agent runs finish within the grace period, then cancel
+	// what is left.
+	shutdownDone := make(chan struct{})
 	go func() {
+		defer close(shutdownDone)
 		<-ctx.Done()
-		logging.Info("Shutting down HTTP server...")
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		grace := s.shutdownGrace
+		if grace <= 0 {
+			grace = defaultShutdownGrace
+		}
+		logging.Info("Shutting down HTTP server (grace period %s)...", grace)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), grace)
 		defer cancel()
 		server.Shutdown(shutdownCtx)
+		if remaining := s.waitForActiveRuns(shutdownCtx); remaining > 0 {
+			logging.Warn("Cancelling %d agent run(s) still active after the grace period", remaining)
+			s.cancelAllActiveRuns()
+		}
+		s.mcpTools.close()
 	}()
 
-	return server.Serve(listener)
+	err = server.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-shutdownDone
+		return nil
+	}
+	return err
+}
+
+// defaultShutdownGrace is used when SetShutdownGracePeriod was not called.
+const defaultShutdownGrace = 10 * time.Second
+
+// SetShutdownGracePeriod sets how long Run waits for in-flight requests and
+// agent runs when its context ends.
+func (s *Server) SetShutdownGracePeriod(d time.Duration) {
+	s.shutdownGrace = d
+}
+
+// waitForActiveRuns waits until no agent runs started by the server are
+// active or ctx ends, and returns the number of sessions still running.
+func (s *Server) waitForActiveRuns(ctx context.Context) int {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		s.activeRunsMu.Lock()
+		remaining := len(s.activeRuns)
+		s.activeRunsMu.Unlock()
+		if remaining == 0 {
+			return 0
+		}
+		select {
+		case <-ctx.Done():
+			return remaining
+		case <-ticker.C:
+		}
+	}
+}
+
+// cancelAllActiveRuns cancels every agent run started by the server.
+func (s *Server) cancelAllActiveRuns() {
+	s.activeRunsMu.Lock()
+	sessionIDs := make([]string, 0, len(s.activeRuns))
+	for sessionID := range s.activeRuns {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	s.activeRunsMu.Unlock()
+	for _, sessionID := range sessionIDs {
+		s.cancelActiveSessionRuns(sessionID)
+	}
 }
 
 // --- Request/Response types ---
 
 // CreateSessionRequest represents a request to create a new session
 type CreateSessionRequest struct {
-	AgentID    string                `json:"agent_id"`
-	Task       string                `json:"task,omitempty"`
-	Images     []MessageImagePayload `json:"images,omitempty"`
-	ParentID   string                `json:"parent_id,omitempty"`
-	LinkType   string                `json:"link_type,omitempty"`
-	Provider   string                `json:"provider,omitempty"`
-	Model      string                `json:"model,omitempty"`
-	ProjectID  string                `json:"project_id,omitempty"`
-	SubAgentID string                `json:"sub_agent_id,omitempty"` // Optional sub-agent to use for this session
-	Queued     bool                  `json:"queued,omitempty"`       // If true, create session without starting it
+	AgentID     string                `json:"agent_id"`
+	Title       string                `json:"title,omitempty"`
+	Task        string                `json:"task,omitempty"`
+	Images      []MessageImagePayload `json:"images,omitempty"`
+	ParentID    string                `json:"parent_id,omitempty"`
+	LinkType    string                `json:"link_type,omitempty"`
+	Provider    string                `json:"provider,omitempty"`
+	Model       string                `json:"model,omitempty"`
+	Temperature *float64              `json:"temperature,omitempty"` // Optional per-session temperature override
+	ProjectID   string                `json:"project_id,omitempty"`
+	SubAgentID  string                `json:"sub_agent_id,omitempty"` // Optional sub-agent to use for this session
+	Queued      bool                  `json:"queued,omitempty"`       // If true, create session without starting it
+
+	// MCPPrompt renders a server-provided prompt into Task, ahead of any
+	// text already there.
+	MCPPrompt *MCPPromptRef `json:"mcp_prompt,omitempty"`
 }
 
 // CreateSessionResponse represents a response after creating a session
@@ -549,8 +704,11 @@ type SessionResponse struct {
 	ParentID             string                       `json:"parent_id,omitempty"`
 	LinkType             string                       `json:"link_type,omitempty"`
 	ProjectID            string                       `json:"project_id,omitempty"`
+	JobID                string                       `json:"job_id,omitempty"`       // recurring job whose run the session is
+	ExecutionID          string                       `json:"execution_id,omitempty"` // that run; see GET /sessions/{id}/execution
 	Provider             string                       `json:"provider,omitempty"`
 	Model                string                       `json:"model,omitempty"`
+	Temperature          *float64                     `json:"temperature,omitempty"`
 	RoutedProvider       string                       `json:"routed_provider,omitempty"`
 	RoutedModel          string                       `json:"routed_model,omitempty"`
 	Title                string                       `json:"title"`
@@ -561,10 +719,14 @@ type SessionResponse struct {
 	CurrentContextTokens int                          `json:"current_context_tokens"`
 	ModelContextWindow   int                          `json:"model_context_window"`
 	TaskProgress         string                       `json:"task_progress,omitempty"`
+	PendingQuestion      *session.QuestionData        `json:"pending_question,omitempty"`
 	ProviderFailures     []ProviderFailurePayload     `json:"provider_failures,omitempty"`
 	CreatedAt            time.Time                    `json:"created_at"`
 	UpdatedAt            time.Time                    `json:"updated_at"`
 	Messages             []MessageResponse            `json:"messages"`
+	MessageCount         int                          `json:"message_count"`        // messages in the session; Messages may hold a page of them
+	MessageOffset        int                          `json:"message_offset"`       // index of Messages[0] in the session
+	HasImages            bool                         `json:"has_images,omitempty"` // some message carries an image, for thumbnails
 	SystemPromptSnapshot *SystemPromptSnapshotPayload `json:"system_prompt_snapshot,omitempty"`
 	// A2A outbound fields — set for sessions used to contact remote agents.
 	A2AOutbound        bool   `json:"a2a_outbound,omitempty"`
@@ -615,6 +777,7 @@ type MessageResponse struct {
 	Timestamp    time.Time              `json:"timestamp"`
 	InputTokens  int                    `json:"input_tokens,omitempty"`
 	OutputTokens int                    `json:"output_tokens,omitempty"`
+	HasImages    bool                   `json:"has_images,omitempty"` // attached images or images returned by tools
 }
 
 type MessageImagePayload struct {
@@ -641,12 +804,23 @@ type ToolResultResponse struct {
 	IsError    bool                   `json:"is_error"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	Name       string                 `json:"name,omitempty"` // Tool name (required by Gemini)
+	// Set when Content is a preview; GET /sessions/{id}/messages/{index}
+	// returns the full result.
+	Truncated    bool `json:"truncated,omitempty"`
+	ContentBytes int  `json:"content_bytes,omitempty"`
+	// When the call started, how long it ran and how large its output was
+	// before it was cut down for the model; zero for older sessions.
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	OutputBytes int       `json:"output_bytes,omitempty"`
 }
 
 // ChatRequest represents a chat message request
 type ChatRequest struct {
 	Message string                `json:"message"`
 	Images  []MessageImagePayload `json:"images,omitempty"`
+	// ResponseSchema, when set, is the JSON Schema the final answer must match.
+	ResponseSchema json.RawMessage `json:"response_schema,omitempty"`
 }
 
 // ChatResponse represents a chat response
@@ -655,6 +829,9 @@ type ChatResponse struct {
 	Messages []MessageResponse `json:"messages"`
 	Status   string            `json:"status"`
 	Usage    UsageResponse     `json:"usage"`
+	// TimedOut is set when the run stopped at max_run_minutes; the session
+	// is paused with the answer so far in Content.
+	TimedOut bool `json:"timed_out,omitempty"`
 }
 
 type ChatStreamEvent struct {
@@ -669,6 +846,7 @@ type ChatStreamEvent struct {
 	ToolResult *StreamToolResultEvent `json:"tool_result,omitempty"`
 	Provider   *StreamProviderEvent   `json:"provider,omitempty"`
 	Step       int                    `json:"step,omitempty"`
+	TimedOut   bool                   `json:"timed_out,omitempty"`
 }
 
 // StreamToolCallEvent represents a tool call in a stream event.
@@ -676,15 +854,18 @@ type StreamToolCallEvent struct {
 	ID               string          `json:"id"`
 	Name             string          `json:"name"`
 	Input            json.RawMessage `json:"input"`
+	InputPreview     string          `json:"input_preview,omitempty"`
 	ThoughtSignature string          `json:"thought_signature,omitempty"`
 }
 
 // StreamToolResultEvent represents a tool result in a stream event.
 type StreamToolResultEvent struct {
-	ToolCallID string `json:"tool_call_id"`
-	Name       string `json:"name"`
-	Content    string `json:"content"`
-	IsError    bool   `json:"is_error"`
+	ToolCallID   string `json:"tool_call_id"`
+	Name         string `json:"name"`
+	InputPreview string `json:"input_preview,omitempty"`
+	Content      string `json:"content"`
+	IsError      bool   `json:"is_error"`
+	DurationMs   int64  `json:"duration_ms"`
 }
 
 type StreamProviderEvent struct {
@@ -716,6 +897,7 @@ type SessionListItem struct {
 	ProjectID          string    `json:"project_id,omitempty"`
 	Provider           string    `json:"provider,omitempty"`
 	Model              string    `json:"model,omitempty"`
+	Temperature        *float64  `json:"temperature,omitempty"`
 	RoutedProvider     string    `json:"routed_provider,omitempty"`
 	RoutedModel        string    `json:"routed_model,omitempty"`
 	Title              string    `json:"title"`
@@ -725,6 +907,10 @@ type SessionListItem struct {
 	OutputTokens       int       `json:"output_tokens"`
 	RunDurationSeconds int64     `json:"run_duration_seconds"`
 	TaskProgress       string    `json:"task_progress,omitempty"`
+	// ProgressPct is the share of checked task_progress items; unset without a checklist.
+	ProgressPct *int `json:"progress_pct,omitempty"`
+	// PendingQuestion is set while the session waits on a question answer.
+	PendingQuestion *session.QuestionData `json:"pending_question,omitempty"`
 	CreatedAt          time.Time `json:"created_at"`
 	UpdatedAt          time.Time `json:"updated_at"`
 	// A2A inbound fields — only set for sessions created from A2A tunnel requests.
@@ -770,7 +956,35 @@ type CreateJobRequest struct {
 	TaskPromptSource string `json:"task_prompt_source,omitempty"` // "text" | "file"
 	TaskPromptFile   string `json:"task_prompt_file,omitempty"`
 	LLMProvider      string `json:"llm_provider,omitempty"`
+	Timezone         string `json:"timezone,omitempty"` // IANA zone; defaults to the server's
+	TimeoutMinutes   *int   `json:"timeout_minutes,omitempty"`
+	KeepExecutions   *int   `json:"keep_executions,omitempty"` // executions pruning keeps; default 50
+	Model            string `json:"model,omitempty"`
+	AgentID          string `json:"agent_id,omitempty"`
 	Enabled          bool   `json:"enabled"`
+	FailOnQuestion   bool   `json:"fail_on_question,omitempty"` // fail runs that ask instead of waiting for an answer
+
+	// JSON Schema the final answer of each run must match
+	ResponseSchema json.RawMessage `json:"response_schema,omitempty"`
+
+	// Integrations told about finished runs, and when: failure|success|always
+	NotifyOn             string   `json:"notify_on,omitempty"`
+	NotifyIntegrationIDs []string `json:"notify_integration_ids,omitempty"`
+}
+
+// JobSchedulePreviewRequest asks what a schedule resolves to
+type JobSchedulePreviewRequest struct {
+	ScheduleText string `json:"schedule_text"` // Natural language schedule or cron expression
+	Timezone     string `json:"timezone,omitempty"`
+}
+
+// JobSchedulePreviewResponse is a resolved schedule and its upcoming runs
+type JobSchedulePreviewResponse struct {
+	ScheduleCron    string      `json:"schedule_cron"`
+	ScheduleSummary string      `json:"schedule_summary"`
+	RunAt           *time.Time  `json:"run_at,omitempty"`
+	Timezone        string      `json:"timezone"`
+	NextRuns        []time.Time `json:"next_runs"`
 }
 
 // UpdateJobRequest represents a request to update a recurring job
@@ -781,7 +995,19 @@ type UpdateJobRequest struct {
 	TaskPromptSource string  `json:"task_prompt_source,omitempty"` // "text" | "file"
 	TaskPromptFile   string  `json:"task_prompt_file,omitempty"`
 	LLMProvider      *string `json:"llm_provider,omitempty"`
+	Timezone         *string `json:"timezone,omitempty"`
+	TimeoutMinutes   *int    `json:"timeout_minutes,omitempty"`
+	KeepExecutions   *int    `json:"keep_executions,omitempty"`
+	Model            *string `json:"model,omitempty"`
+	AgentID          *string `json:"agent_id,omitempty"`
 	Enabled          *bool   `json:"enabled,omitempty"`
+	FailOnQuestion   *bool   `json:"fail_on_question,omitempty"`
+
+	NotifyOn             *string   `json:"notify_on,omitempty"`
+	NotifyIntegrationIDs *[]string `json:"notify_integration_ids,omitempty"`
+
+	// An empty string clears the schema
+	ResponseSchema *json.RawMessage `json:"response_schema,omitempty"`
 }
 
 // JobResponse represents a recurring job response
@@ -790,15 +1016,27 @@ type JobResponse struct {
 	Name             string     `json:"name"`
 	ScheduleHuman    string     `json:"schedule_human"`
 	ScheduleCron     string     `json:"schedule_cron"`
+	ScheduleSummary  string     `json:"schedule_summary"`
+	RunAt            *time.Time `json:"run_at,omitempty"`
 	TaskPrompt       string     `json:"task_prompt"`
 	TaskPromptSource string     `json:"task_prompt_source"`
 	TaskPromptFile   string     `json:"task_prompt_file,omitempty"`
 	LLMProvider      string     `json:"llm_provider,omitempty"`
+	Timezone         string     `json:"timezone"`
+	TimeoutMinutes   int        `json:"timeout_minutes"`
+	KeepExecutions   int        `json:"keep_executions"`
+	Model            string     `json:"model,omitempty"`
+	AgentID          string     `json:"agent_id"`
+	NotifyOn         string     `json:"notify_on,omitempty"`
+	NotifyTargets    []string   `json:"notify_integration_ids"`
+	FailOnQuestion   bool       `json:"fail_on_question"`
 	Enabled          bool       `json:"enabled"`
 	LastRunAt        *time.Time `json:"last_run_at,omitempty"`
 	NextRunAt        *time.Time `json:"next_run_at,omitempty"`
 	CreatedAt        time.Time  `json:"created_at"`
 	UpdatedAt        time.Time  `json:"updated_at"`
+
+	ResponseSchema json.RawMessage `json:"response_schema,omitempty"`
 }
 
 // JobExecutionResponse represents a job execution response
@@ -808,6 +1046,7 @@ type JobExecutionResponse struct {
 	SessionID  string     `json:"session_id,omitempty"`
 	Status     string     `json:"status"`
 	Output     string     `json:"output,omitempty"`
+	OutputURL  string     `json:"output_url,omitempty"` // full output, of which Output may be a summary
 	Error      string     `json:"error,omitempty"`
 	StartedAt  time.Time  `json:"started_at"`
 	FinishedAt *time.Time `json:"finished_at,omitempty"`
@@ -869,6 +1108,20 @@ type ListProviderModelsResponse struct {
 	Models []string `json:"models"`
 }
 
+// UpdateSessionRequest is the PATCH body for editable session fields. An empty
+// model and a null temperature clear the session's overrides.
+type UpdateSessionRequest struct {
+	Title       *string         `json:"title,omitempty"`
+	Model       *string         `json:"model,omitempty"`
+	Temperature json.RawMessage `json:"temperature,omitempty"`
+}
+
+// ForkSessionRequest selects how much history a fork copies. A nil
+// MessageIndex copies the whole conversation.
+type ForkSessionRequest struct {
+	MessageIndex *int `json:"message_index,omitempty"`
+}
+
 type UpdateSessionProjectRequest struct {
 	ProjectID *string `json:"project_id"`
 }
@@ -907,6 +1160,7 @@ const defaultAgentName = "A2gent"
 const (
 	sessionLinkTypeReview       = "review"
 	sessionLinkTypeContinuation = "continuation"
+	sessionLinkTypeFork         = session.LinkTypeFork
 )
 
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -965,6 +1219,12 @@ func (s *Server) handleUpdateSettings(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, http.StatusInternalServerError, "Failed to load existing settings: "+err.Error())
 		return
 	}
+	if strings.TrimSpace(oldSettings[tools.CameraIndexKey]) != strings.TrimSpace(req.Settings[tools.CameraIndexKey]) {
+		if err := validateCameraIndexSetting(r.Context(), req.Settings); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Invalid camera setting: "+err.Error())
+			return
+		}
+	}
 
 	if err := s.store.SaveSettings(req.Settings); err != nil {
 		s.errorResponse(w, http.StatusInternalServerError, "Failed to save settings: "+err.Error())
@@ -1278,6 +1538,7 @@ func (s *Server) handleUpdateProvider(w http.ResponseWriter, r *http.Request) {
 	}
 
 	s.config.SetProvider(providerType, provider)
+	s.modelLists.reset()
 
 	if req.Active != nil && *req.Active {
 		s.config.ActiveProvider = string(providerType)
@@ -1463,17 +1724,8 @@ func (s *Server) handleListOpenAIModels(w http.ResponseWriter, r *http.Request)
 }
 
 func (s *Server) handleListOpenAICodexModels(w http.ResponseWriter, r *http.Request) {
-	// Codex OAuth does not expose a public models listing endpoint like standard OpenAI API.
-	// Return a curated list of known Codex-capable models instead.
-	s.jsonResponse(w, http.StatusOK, ListProviderModelsResponse{
-		Models: []string{
-			"gpt-5.3-codex",
-			"gpt-5.2-codex",
-			"gpt-5.1-codex",
-			"gpt-5.1-codex-max",
-			"gpt-5.1-codex-mini",
-		},
-	})
+	models, _ := openaicodex.NewClient("", "", "").ListModelIDs(r.Context())
+	s.jsonResponse(w, http.StatusOK, ListProviderModelsResponse{Models: models})
 }
 
 func (s *Server) handleListOpenRouterModels(w http.ResponseWriter, r *http.Request) {
@@ -1578,22 +1830,72 @@ func (s *Server) handleListOpenAICompatibleModels(w http.ResponseWriter, r *http
 	s.jsonResponse(w, http.StatusOK, ListProviderModelsResponse{Models: modelIDs})
 }
 
+// sessionTotalCountHeader carries the number of sessions matching a list
+// query before limit/offset are applied.
+const sessionTotalCountHeader = "X-Total-Count"
+
+// maxSessionPageSize caps the limit accepted by GET /sessions.
+const maxSessionPageSize = 1000
+
+// parseSessionListFilter reads the GET /sessions query parameters:
+// limit, offset, status, agent (or agent_id), created_after and a2a_inbound.
+func parseSessionListFilter(query url.Values) (storage.SessionFilter, error) {
+	filter := storage.SessionFilter{
+		Status:  strings.TrimSpace(query.Get("status")),
+		AgentID: strings.TrimSpace(query.Get("agent")),
+	}
+	if filter.AgentID == "" {
+		filter.AgentID = strings.TrimSpace(query.Get("agent_id"))
+	}
+
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit <= 0 || limit > maxSessionPageSize {
+			return filter, fmt.Errorf("limit must be between 1 and %d", maxSessionPageSize)
+		}
+		filter.Limit = limit
+	}
+	if raw := strings.TrimSpace(query.Get("offset")); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return filter, fmt.Errorf("offset must be a non-negative integer")
+		}
+		filter.Offset = offset
+	}
+	if raw := strings.TrimSpace(query.Get("created_after")); raw != "" {
+		createdAfter, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			createdAfter, err = time.ParseInLocation("2006-01-02", raw, time.Local)
+		}
+		if err != nil {
+			return filter, fmt.Errorf("created_after must be RFC3339 or YYYY-MM-DD")
+		}
+		filter.CreatedAfter = &createdAfter
+	}
+	// ?a2a_inbound=true returns only A2A-originated sessions.
+	if query.Get("a2a_inbound") == "true" {
+		filter.MetadataFlags = append(filter.MetadataFlags, a2atunnel.MetaA2AInbound)
+	}
+	return filter, nil
+}
+
 func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
-	sessions, err := s.sessionManager.List()
+	filter, err := parseSessionListFilter(r.URL.Query())
 	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to list sessions: "+err.Error())
+		s.errorResponse(w, http.StatusBadRequest, "Invalid query: "+err.Error())
 		return
 	}
 
-	// Optional filter: ?a2a_inbound=true returns only A2A-originated sessions.
-	filterA2A := r.URL.Query().Get("a2a_inbound") == "true"
+	sessions, total, err := s.sessionManager.ListPage(filter)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to list sessions: "+err.Error())
+		return
+	}
+	w.Header().Set(sessionTotalCountHeader, strconv.Itoa(total))
 
 	items := make([]SessionListItem, 0, len(sessions))
 	for _, sess := range sessions {
 		isInbound, sourceAgentID, sourceAgentName := sessionA2AMeta(sess)
-		if filterA2A && !isInbound {
-			continue
-		}
 		parentID := ""
 		if sess.ParentID != nil {
 			parentID = *sess.ParentID
@@ -1613,6 +1915,7 @@ func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
 			ProjectID:          projectID,
 			Provider:           provider,
 			Model:              model,
+			Temperature:        sessionTemperature(sess),
 			RoutedProvider:     routedProvider,
 			RoutedModel:        routedModel,
 			Title:              sess.Title,
@@ -1622,6 +1925,8 @@ func (s *Server) handleListSessions(w http.ResponseWriter, r *http.Request) {
 			OutputTokens:       outputTokens,
 			RunDurationSeconds: sessionRunDurationSeconds(sess.CreatedAt, sess.UpdatedAt, string(sess.Status)),
 			TaskProgress:       sess.TaskProgress,
+			ProgressPct:        taskProgressPct(sess.TaskProgress),
+			PendingQuestion:    sessionPendingQuestion(sess),
 			CreatedAt:          sess.CreatedAt,
 			UpdatedAt:          sess.UpdatedAt,
 			A2AInbound:         isInbound,
@@ -1670,6 +1975,12 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, http.StatusBadRequest, "Invalid images payload: "+imagesErr.Error())
 		return
 	}
+	if req.Temperature != nil {
+		if err := validateTemperature(*req.Temperature); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 	req.ProjectID = strings.TrimSpace(req.ProjectID)
 	if req.ProjectID != "" {
 		if _, err := s.store.GetProject(req.ProjectID); err != nil {
@@ -1677,6 +1988,17 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	if req.MCPPrompt != nil {
+		prompt, err := s.mcpTools.renderPrompt(r.Context(), *req.MCPPrompt)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "Failed to load MCP prompt: "+err.Error())
+			return
+		}
+		if task := strings.TrimSpace(req.Task); task != "" {
+			prompt += "\n\n" + task
+		}
+		req.Task = prompt
+	}
 
 	// Create session based on queued flag
 	var sess *session.Session
@@ -1703,6 +2025,10 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if title := strings.TrimSpace(req.Title); title != "" {
+		sess.SetTitleWithSource(title, session.TitleSourceManual)
+	}
+
 	// If an initial task/images are provided, add them as the first message.
 	if req.Task != "" || len(images) > 0 {
 		sess.AddUserMessageWithImages(req.Task, images)
@@ -1718,7 +2044,7 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 			}
 		}
 		if err := s.sessionManager.Save(sess); err != nil {
-			logging.Error("Failed to save session with initial task: %v", err)
+			logging.ErrorContext(r.Context(), "Failed to save session with initial task: %v", err)
 		}
 	}
 
@@ -1757,14 +2083,15 @@ func (s *Server) handleCreateSession(w http.ResponseWriter, r *http.Request) {
 		sess.Metadata["link_type"] = req.LinkType
 	}
 	sess.Metadata["provider"] = providerType
-	sess.Metadata["model"] = model
+	sess.SetModel(model)
+	sess.SetTemperature(req.Temperature)
 	if err := s.sessionManager.Save(sess); err != nil {
-		logging.Warn("Failed to persist session provider metadata: %v", err)
+		logging.WarnContext(r.Context(), "Failed to persist session provider metadata: %v", err)
 	}
 	if req.ProjectID != "" {
 		sess.ProjectID = &req.ProjectID
 		if err := s.sessionManager.Save(sess); err != nil {
-			logging.Warn("Failed to persist session project metadata: %v", err)
+			logging.WarnContext(r.Context(), "Failed to persist session project metadata: %v", err)
 		}
 	}
 	_ = s.ensureSessionSystemPromptSnapshot(sess)
@@ -1820,6 +2147,129 @@ func (s *Server) handleStartSession(w http.ResponseWriter, r *http.Request) {
 	s.jsonResponse(w, http.StatusOK, s.sessionToResponse(sess))
 }
 
+func (s *Server) handleUpdateSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	var req UpdateSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Title == nil && req.Model == nil && len(req.Temperature) == 0 {
+		s.errorResponse(w, http.StatusBadRequest, "No updatable fields provided")
+		return
+	}
+
+	var title string
+	if req.Title != nil {
+		title = strings.TrimSpace(*req.Title)
+		if title == "" {
+			s.errorResponse(w, http.StatusBadRequest, "title must not be empty")
+			return
+		}
+	}
+	var temperature *float64
+	if len(req.Temperature) > 0 && string(req.Temperature) != "null" {
+		var value float64
+		if err := json.Unmarshal(req.Temperature, &value); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, "temperature must be a number or null")
+			return
+		}
+		if err := validateTemperature(value); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		temperature = &value
+	}
+
+	if _, err := s.sessionManager.Get(sessionID); err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+	sess, err := s.sessionManager.Update(sessionID, func(sess *session.Session) error {
+		if req.Title != nil {
+			sess.SetTitleWithSource(title, session.TitleSourceManual)
+		}
+		if req.Model != nil {
+			sess.SetModel(*req.Model)
+			// A pinned model replaces whatever the router picked last time.
+			delete(sess.Metadata, "routed_provider")
+			delete(sess.Metadata, "routed_model")
+		}
+		if len(req.Temperature) > 0 {
+			sess.SetTemperature(temperature)
+		}
+		return nil
+	})
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to update session: "+err.Error())
+		return
+	}
+	if req.Title != nil {
+		logging.LogSession("renamed", sess.ID, title)
+	}
+
+	s.jsonResponse(w, http.StatusOK, s.sessionToResponse(sess))
+}
+
+func (s *Server) handleForkSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	var req ForkSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	parent, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+
+	atIndex := -1
+	if req.MessageIndex != nil {
+		atIndex = *req.MessageIndex
+		if atIndex < 0 || atIndex > len(parent.Messages) {
+			s.errorResponse(w, http.StatusBadRequest, fmt.Sprintf("message_index must be between 0 and %d", len(parent.Messages)))
+			return
+		}
+	}
+
+	fork, err := s.sessionManager.Fork(parent.ID, atIndex)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to fork session: "+err.Error())
+		return
+	}
+	logging.LogSession("forked", fork.ID, "parent="+parent.ID)
+
+	s.jsonResponse(w, http.StatusCreated, s.sessionToResponse(fork))
+}
+
+// maybeGenerateSessionTitle summarizes the first request into a title in the
+// background once a run completes, unless a title was already chosen.
+func (s *Server) maybeGenerateSessionTitle(sess *session.Session, client llm.Client, model string) {
+	if sess == nil || client == nil || !sess.NeedsGeneratedTitle() {
+		return
+	}
+	prompt := sess.FirstUserMessage()
+	if strings.TrimSpace(prompt) == "" {
+		return
+	}
+	go func(sessionID string) {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		title, err := agent.GenerateTitle(ctx, client, model, prompt)
+		if err != nil {
+			logging.Debug("Session title generation skipped for %s: %v", sessionID, err)
+			return
+		}
+		if _, err := s.sessionManager.ApplyGeneratedTitle(sessionID, title); err != nil {
+			logging.Warn("Failed to save generated title for session %s: %v", sessionID, err)
+		}
+	}(sess.ID)
+}
+
 func (s *Server) handleUpdateSessionProject(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionID")
 
@@ -1921,11 +2371,38 @@ func (s *Server) handleGetSession(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	start, end, err := parseMessagePage(r.URL.Query(), len(sess.Messages))
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
 	_ = s.ensureSessionSystemPromptSnapshot(sess)
 	resp := s.sessionToResponse(sess)
+	resp.Messages = resp.Messages[start:end]
+	resp.MessageOffset = start
+	if include, err := strconv.ParseBool(r.URL.Query().Get("include_tool_results")); err == nil && !include {
+		previewToolResults(resp.Messages)
+	}
 	s.jsonResponse(w, http.StatusOK, resp)
 }
 
+// handleExportSession returns the session transcript as a Markdown download.
+func (s *Server) handleExportSession(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	sess, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "session-"+sess.ID+".md"))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(transcript.Markdown(sess)))
+}
+
 func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionID")
 
@@ -1936,7 +2413,7 @@ func (s *Server) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
 		cleanupCtx, cleanupCancel := context.WithTimeout(r.Context(), 20*time.Second)
 		defer cleanupCancel()
 		if cleanupErr := s.deleteTelegramTopicForSession(cleanupCtx, sess); cleanupErr != nil {
-			logging.Warn("Telegram topic cleanup failed for session %s: %s", sessionID, sanitizeTelegramError(cleanupErr))
+			logging.WarnContext(r.Context(), "Telegram topic cleanup failed for session %s: %s", sessionID, sanitizeTelegramError(cleanupErr))
 		}
 	}
 
@@ -1998,7 +2475,7 @@ func (s *Server) handleGetTaskProgress(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse statistics from progress
-	stats := parseTaskProgressStats(progress)
+	stats := tools.ParseTaskStats(progress)
 
 	s.jsonResponse(w, http.StatusOK, map[string]interface{}{
 		"content":         progress,
@@ -2008,63 +2485,176 @@ func (s *Server) handleGetTaskProgress(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-func parseTaskProgressStats(content string) struct {
-	Total       int
-	Completed   int
-	ProgressPct int
-} {
-	lines := strings.Split(content, "\n")
-	total := 0
-	completed := 0
+// SessionProgressResponse is the task checklist of a session with its parsed stats.
+type SessionProgressResponse struct {
+	SessionID   string `json:"session_id"`
+	Content     string `json:"content"`
+	Total       int    `json:"total"`
+	Completed   int    `json:"completed"`
+	ProgressPct int    `json:"progress_pct"`
+}
 
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmed, "[ ]") {
-			total++
-		} else if strings.HasPrefix(trimmed, "[x]") || strings.HasPrefix(trimmed, "[X]") {
-			total++
-			completed++
-		}
-	}
+func (s *Server) handleGetSessionProgress(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
 
-	pct := 0
-	if total > 0 {
-		pct = (completed * 100) / total
+	progress, err := s.sessionManager.GetSessionTaskProgress(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Failed to get task progress: "+err.Error())
+		return
 	}
 
-	return struct {
-		Total       int
-		Completed   int
-		ProgressPct int
-	}{
-		Total:       total,
-		Completed:   completed,
-		ProgressPct: pct,
+	stats := tools.ParseTaskStats(progress)
+	s.jsonResponse(w, http.StatusOK, SessionProgressResponse{
+		SessionID:   sessionID,
+		Content:     progress,
+		Total:       stats.Total,
+		Completed:   stats.Completed,
+		ProgressPct: stats.ProgressPct,
+	})
+}
+
+// taskProgressPct returns the checklist completion for list views, or nil
+// when the session has no checklist items.
+func taskProgressPct(progress string) *int {
+	stats := tools.ParseTaskStats(progress)
+	if stats.Total == 0 {
+		return nil
 	}
+	return &stats.ProgressPct
 }
 
 func (s *Server) handleAnswerQuestion(w http.ResponseWriter, r *http.Request) {
 	sessionID := chi.URLParam(r, "sessionID")
 
 	var req struct {
-		Answer string `json:"answer"`
+		Answer  string   `json:"answer"`
+		Answers []string `json:"answers"`
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.errorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
 		return
 	}
 
-	if req.Answer == "" {
-		s.errorResponse(w, http.StatusBadRequest, "answer is required")
+	answers := req.Answers
+	if len(answers) == 0 {
+		answers = []string{req.Answer}
+	}
+
+	question, err := s.sessionManager.GetPendingQuestion(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Failed to get question: "+err.Error())
+		return
+	}
+	if question == nil {
+		s.errorResponse(w, http.StatusConflict, "Session is not waiting for an answer")
+		return
+	}
+	answer, err := question.ResolveAnswer(answers)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid answer: "+err.Error())
 		return
 	}
 
-	if err := s.sessionManager.AnswerQuestion(sessionID, req.Answer); err != nil {
+	if err := s.sessionManager.AnswerQuestion(sessionID, answer); err != nil {
 		s.errorResponse(w, http.StatusInternalServerError, "Failed to answer question: "+err.Error())
 		return
 	}
+	logging.LogSession("answered", sessionID, "via HTTP")
+
+	// Continue the conversation in the background; clients follow progress
+	// by polling the session.
+	go s.resumeSessionAfterAnswer(sessionID, answer)
+
+	s.jsonResponse(w, http.StatusOK, map[string]interface{}{
+		"status":         "ok",
+		"answer":         answer,
+		"session_status": string(session.StatusRunning),
+	})
+}
+
+// resumeSessionAfterAnswer runs the agent again once a pending question has
+// been answered. The run is registered so /sessions/{id}/cancel can stop it.
+// A job run waiting on the answer carries on and settles its execution.
+func (s *Server) resumeSessionAfterAnswer(sessionID, answer string) {
+	defer s.queueTelegramSessionMessageSync(sessionID)
+
+	sess, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		logging.Error("Failed to reload session %s after answer: %v", sessionID, err)
+		return
+	}
+	var output string
+	if exec := s.resumeJobExecution(sess); exec != nil {
+		defer func() { s.settleJobExecution(context.Background(), exec, sess, output, err) }()
+	}
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	runID := s.registerActiveSessionRun(sessionID, cancelRun)
+	defer func() {
+		cancelRun()
+		s.unregisterActiveSessionRun(sessionID, runID)
+	}()
+
+	providerType := s.resolveSessionProviderType(sess)
+	model := s.resolveSessionModel(sess, providerType)
+	target, err := s.resolveExecutionTarget(runCtx, providerType, model, answer, sess)
+	if err != nil {
+		sess.AddAssistantMessage(fmt.Sprintf("Unable to resume after answer: %s", err.Error()), nil)
+		sess.SetStatus(session.StatusFailed)
+		s.sessionManager.Save(sess)
+		return
+	}
+	if setSessionRoutedProviderAndModel(sess, providerType, target.ProviderType, target.Model) {
+		if err := s.sessionManager.Save(sess); err != nil {
+			logging.Warn("Failed to persist session routed target metadata: %v", err)
+		}
+	}
+
+	agentDef := s.agentDefinition(sess, sess.AgentID)
+	agentConfig := agent.Config{
+		Name:          sess.AgentID,
+		Model:         target.Model,
+		SystemPrompt:  s.buildSystemPromptForSession(sess),
+		MaxSteps:      s.config.MaxSteps,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   s.resolveSessionTemperature(sess, agentDef),
+		ContextWindow: target.ContextWindow,
+	}
+	agentDef.Apply(&agentConfig)
+	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
+
+	output, _, err = ag.RunWithEvents(runCtx, sess, answer, func(ev agent.Event) {
+		if ev.Type == agent.EventProviderTrace && ev.Provider != nil {
+			s.applyProviderTraceToSession(sess, target.ProviderType, ev.Provider)
+		}
+	})
+	if errors.Is(err, agent.ErrMaxDurationExceeded) {
+		logging.Warn("Resumed run for session %s timed out: %v", sessionID, err)
+		return
+	}
+	if err != nil {
+		if isCancellationError(err) {
+			sess.SetStatus(session.StatusPaused)
+			_ = s.sessionManager.Save(sess)
+			return
+		}
+		adaptedErr := s.adaptProviderErrorMessage(target.ProviderType, err)
+		sess.AddAssistantMessage(fmt.Sprintf("Request failed: %s", adaptedErr.Error()), nil)
+		sess.SetStatus(session.StatusFailed)
+		s.sessionManager.Save(sess)
+		logging.Error("Resumed run for session %s failed: %v", sessionID, adaptedErr)
+	}
+}
 
-	s.jsonResponse(w, http.StatusOK, map[string]interface{}{"status": "ok"})
+// sessionPendingQuestion returns the question a session is blocked on, if any.
+func sessionPendingQuestion(sess *session.Session) *session.QuestionData {
+	question, err := sess.PendingQuestion()
+	if err != nil {
+		return nil
+	}
+	return question
 }
 
 func (s *Server) registerActiveSessionRun(sessionID string, cancel context.CancelFunc) string {
@@ -2095,24 +2685,91 @@ func (s *Server) unregisterActiveSessionRun(sessionID, runID string) {
 	}
 }
 
-func (s *Server) cancelActiveSessionRuns(sessionID string) int {
+// RunCanceller stops agent runs that are started outside the HTTP server,
+// such as scheduled jobs.
+type RunCanceller interface {
+	CancelSession(sessionID string) bool
+	SessionRunning(sessionID string) bool
+}
+
+// AddRunCanceller makes /sessions/{id}/cancel reach runs owned by c.
+func (s *Server) AddRunCanceller(c RunCanceller) {
+	if c == nil {
+		return
+	}
 	s.activeRunsMu.Lock()
-	runs, ok := s.activeRuns[sessionID]
-	if !ok || len(runs) == 0 {
-		s.activeRunsMu.Unlock()
-		return 0
+	s.runCancellers = append(s.runCancellers, c)
+	s.activeRunsMu.Unlock()
+}
+
+// JobRunner starts job runs on behalf of the API, so that jobs run now and
+// jobs run on schedule go through the same code.
+type JobRunner interface {
+	StartRun(ctx context.Context, job *storage.RecurringJob) (*storage.JobExecution, error)
+}
+
+var errNoJobRunner = errors.New("no job runner is configured")
+
+// SetJobRunner makes /jobs/{id}/run and the recurring jobs tool start runs
+// through r.
+func (s *Server) SetJobRunner(r JobRunner) {
+	s.activeRunsMu.Lock()
+	s.jobRunner = r
+	s.activeRunsMu.Unlock()
+}
+
+// startJobRun starts a run of job that outlives ctx and returns its
+// execution, which is still running.
+func (s *Server) startJobRun(ctx context.Context, job *storage.RecurringJob) (*storage.JobExecution, error) {
+	s.activeRunsMu.Lock()
+	runner := s.jobRunner
+	s.activeRunsMu.Unlock()
+	if runner == nil {
+		return nil, errNoJobRunner
 	}
+	return runner.StartRun(ctx, job)
+}
+
+// sessionRunning reports whether an agent run, the server's or an external
+// one, is working on the session and will save it when it stops.
+func (s *Server) sessionRunning(sessionID string) bool {
+	s.activeRunsMu.Lock()
+	running := len(s.activeRuns[sessionID]) > 0
+	cancellers := append([]RunCanceller(nil), s.runCancellers...)
+	s.activeRunsMu.Unlock()
+
+	if running {
+		return true
+	}
+	for _, c := range cancellers {
+		if c.SessionRunning(sessionID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) cancelActiveSessionRuns(sessionID string) int {
+	s.activeRunsMu.Lock()
+	runs := s.activeRuns[sessionID]
 	cancels := make([]context.CancelFunc, 0, len(runs))
 	for _, cancel := range runs {
 		cancels = append(cancels, cancel)
 	}
 	delete(s.activeRuns, sessionID)
+	cancellers := append([]RunCanceller(nil), s.runCancellers...)
 	s.activeRunsMu.Unlock()
 
 	for _, cancel := range cancels {
 		cancel()
 	}
-	return len(cancels)
+	cancelled := len(cancels)
+	for _, c := range cancellers {
+		if c.CancelSession(sessionID) {
+			cancelled++
+		}
+	}
+	return cancelled
 }
 
 func (s *Server) applyProviderTraceToSession(sess *session.Session, targetProvider config.ProviderType, trace *agent.ProviderTraceEvent) {
@@ -2179,6 +2836,12 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, http.StatusBadRequest, "Message or images are required")
 		return
 	}
+	if len(req.ResponseSchema) > 0 {
+		if err := llm.CheckSchema(req.ResponseSchema); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	// Get the session
 	sess, err := s.sessionManager.Get(sessionID)
@@ -2216,19 +2879,26 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 	}
 	if setSessionRoutedProviderAndModel(sess, providerType, target.ProviderType, target.Model) {
 		if err := s.sessionManager.Save(sess); err != nil {
-			logging.Warn("Failed to persist session routed target metadata: %v", err)
+			logging.WarnContext(r.Context(), "Failed to persist session routed target metadata: %v", err)
 		}
 	}
 
 	// Create agent config
+	agentDef := s.agentDefinition(sess, sess.AgentID)
 	agentConfig := agent.Config{
 		Name:          sess.AgentID,
 		Model:         target.Model,
 		SystemPrompt:  s.buildSystemPromptForSession(sess),
 		MaxSteps:      s.config.MaxSteps,
-		Temperature:   s.config.Temperature,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   s.resolveSessionTemperature(sess, agentDef),
 		ContextWindow: target.ContextWindow,
+
+		ResponseSchema: req.ResponseSchema,
 	}
+	agentDef.Apply(&agentConfig)
 
 	// Create agent instance
 	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
@@ -2239,7 +2909,9 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 			s.applyProviderTraceToSession(sess, target.ProviderType, ev.Provider)
 		}
 	})
-	if err != nil {
+	// A timed out run is paused by the agent and answers with what it has.
+	timedOut := errors.Is(err, agent.ErrMaxDurationExceeded)
+	if err != nil && !timedOut {
 		if isCancellationError(err) {
 			sess.SetStatus(session.StatusPaused)
 			_ = s.sessionManager.Save(sess)
@@ -2255,6 +2927,8 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.maybeGenerateSessionTitle(sess, target.Client, target.Model)
+
 	// Build response with updated messages
 	resp := ChatResponse{
 		Content:  content,
@@ -2264,6 +2938,7 @@ func (s *Server) handleChat(w http.ResponseWriter, r *http.Request) {
 			InputTokens:  usage.InputTokens,
 			OutputTokens: usage.OutputTokens,
 		},
+		TimedOut: timedOut,
 	}
 
 	s.jsonResponse(w, http.StatusOK, resp)
@@ -2288,6 +2963,12 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 		s.errorResponse(w, http.StatusBadRequest, "Message or images are required")
 		return
 	}
+	if len(req.ResponseSchema) > 0 {
+		if err := llm.CheckSchema(req.ResponseSchema); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	sess, err := s.sessionManager.Get(sessionID)
 	if err != nil {
@@ -2333,7 +3014,7 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 	}
 	if setSessionRoutedProviderAndModel(sess, providerType, target.ProviderType, target.Model) {
 		if err := s.sessionManager.Save(sess); err != nil {
-			logging.Warn("Failed to persist session routed target metadata: %v", err)
+			logging.WarnContext(r.Context(), "Failed to persist session routed target metadata: %v", err)
 		}
 	}
 
@@ -2359,14 +3040,21 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	agentDef := s.agentDefinition(sess, sess.AgentID)
 	agentConfig := agent.Config{
 		Name:          sess.AgentID,
 		Model:         target.Model,
 		SystemPrompt:  s.buildSystemPromptForSession(sess),
 		MaxSteps:      s.config.MaxSteps,
-		Temperature:   s.config.Temperature,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   s.resolveSessionTemperature(sess, agentDef),
 		ContextWindow: target.ContextWindow,
+
+		ResponseSchema: req.ResponseSchema,
 	}
+	agentDef.Apply(&agentConfig)
 	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
 
 	content, usage, err := ag.RunWithEvents(runCtx, sess, req.Message, func(ev agent.Event) {
@@ -2376,21 +3064,38 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 				Type:  "assistant_delta",
 				Delta: ev.Delta,
 			})
-		case agent.EventToolExecuting:
+		case agent.EventToolExecuting, agent.EventToolCallStarted:
 			toolCalls := make([]StreamToolCallEvent, len(ev.ToolCalls))
 			for i, tc := range ev.ToolCalls {
 				toolCalls[i] = StreamToolCallEvent{
 					ID:               tc.ID,
 					Name:             tc.Name,
 					Input:            json.RawMessage(tc.Input),
+					InputPreview:     tc.InputPreview,
 					ThoughtSignature: tc.ThoughtSignature,
 				}
 			}
 			_ = writeEvent(ChatStreamEvent{
-				Type:      "tool_executing",
+				Type:      string(ev.Type),
 				Step:      ev.Step,
 				ToolCalls: toolCalls,
 			})
+		case agent.EventToolCallFinished:
+			if ev.ToolResult == nil {
+				return
+			}
+			// Results arrive in full with the step's tool_completed messages.
+			_ = writeEvent(ChatStreamEvent{
+				Type: string(ev.Type),
+				Step: ev.Step,
+				ToolResult: &StreamToolResultEvent{
+					ToolCallID:   ev.ToolResult.ToolCallID,
+					Name:         ev.ToolResult.Name,
+					InputPreview: ev.ToolResult.InputPreview,
+					IsError:      ev.ToolResult.IsError,
+					DurationMs:   ev.ToolResult.Duration.Milliseconds(),
+				},
+			})
 		case agent.EventToolCompleted:
 			// Send updated messages after tool execution
 			freshSess, err := s.sessionManager.Get(sess.ID)
@@ -2432,7 +3137,8 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 		}
 	})
 
-	if err != nil {
+	timedOut := errors.Is(err, agent.ErrMaxDurationExceeded)
+	if err != nil && !timedOut {
 		if isCancellationError(err) {
 			sess.SetStatus(session.StatusPaused)
 			s.sessionManager.Save(sess)
@@ -2455,6 +3161,8 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.maybeGenerateSessionTitle(sess, target.Client, target.Model)
+
 	_ = writeEvent(ChatStreamEvent{
 		Type:     "done",
 		Content:  content,
@@ -2464,6 +3172,7 @@ func (s *Server) handleChatStream(w http.ResponseWriter, r *http.Request) {
 			InputTokens:  usage.InputTokens,
 			OutputTokens: usage.OutputTokens,
 		},
+		TimedOut: timedOut,
 	})
 }
 
@@ -2522,8 +3231,14 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Parse natural language schedule to cron using the agent
-	cronExpr, err := s.parseScheduleToCron(r.Context(), req.ScheduleText)
+	timezone, err := normalizeJobTimezone(req.Timezone)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Parse natural language schedule to cron or a one-time run
+	parsed, err := s.parseSchedule(r.Context(), req.ScheduleText, timezone)
 	if err != nil {
 		s.errorResponse(w, http.StatusBadRequest, "Failed to parse schedule: "+err.Error())
 		return
@@ -2534,20 +3249,36 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		ID:               uuid.New().String(),
 		Name:             req.Name,
 		ScheduleHuman:    req.ScheduleText,
-		ScheduleCron:     cronExpr,
 		TaskPrompt:       taskPrompt,
 		TaskPromptSource: taskPromptSource,
 		TaskPromptFile:   taskPromptFile,
 		LLMProvider:      llmProvider,
+		Timezone:         timezone,
 		Enabled:          req.Enabled,
+		FailOnQuestion:   req.FailOnQuestion,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
+	if err := s.applyJobRunSettings(job, req.TimeoutMinutes, &req.Model, &req.AgentID); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := applyJobKeepExecutions(job, req.KeepExecutions); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.applyJobNotifySettings(job, &req.NotifyOn, &req.NotifyIntegrationIDs); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := applyJobResponseSchema(job, req.ResponseSchema); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	// Calculate next run time
-	nextRun, err := s.calculateNextRun(cronExpr, now)
-	if err == nil {
-		job.NextRunAt = &nextRun
+	if err := jobs.ApplySchedule(job, parsed, now); err != nil {
+		logging.WarnContext(r.Context(), "Failed to calculate next run for job %s: %v", job.Name, err)
 	}
 
 	if err := s.store.SaveJob(job); err != nil {
@@ -2555,10 +3286,59 @@ func (s *Server) handleCreateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logging.Info("Created recurring job: %s (%s)", job.Name, job.ID)
+	logging.InfoContext(r.Context(), "Created recurring job: %s (%s)", job.Name, job.ID)
 	s.jsonResponse(w, http.StatusCreated, s.jobToResponse(job))
 }
 
+// jobPreviewRuns is how many upcoming runs a schedule preview lists.
+const jobPreviewRuns = 5
+
+// handlePreviewJobSchedule parses a schedule the way job creation does and
+// returns the result with its next runs, without creating anything.
+func (s *Server) handlePreviewJobSchedule(w http.ResponseWriter, r *http.Request) {
+	var req JobSchedulePreviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+	scheduleText := strings.TrimSpace(req.ScheduleText)
+	if scheduleText == "" {
+		s.errorResponse(w, http.StatusBadRequest, "Schedule text is required")
+		return
+	}
+	timezone, err := normalizeJobTimezone(req.Timezone)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	parsed, err := s.parseSchedule(r.Context(), scheduleText, timezone)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Failed to parse schedule: "+err.Error())
+		return
+	}
+	runs, err := jobs.PreviewRuns(parsed, timezone, time.Now(), jobPreviewRuns)
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Failed to calculate next runs: "+err.Error())
+		return
+	}
+
+	resp := JobSchedulePreviewResponse{
+		ScheduleCron:    parsed.Cron,
+		ScheduleSummary: parsed.Description,
+		Timezone:        timezone,
+		NextRuns:        runs,
+	}
+	if !parsed.RunAt.IsZero() {
+		runAt := parsed.RunAt
+		resp.RunAt = &runAt
+	}
+	if resp.NextRuns == nil {
+		resp.NextRuns = []time.Time{}
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
+
 func (s *Server) handleGetJob(w http.ResponseWriter, r *http.Request) {
 	jobID := chi.URLParam(r, "jobID")
 
@@ -2593,6 +3373,9 @@ func (s *Server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	if req.Enabled != nil {
 		job.Enabled = *req.Enabled
 	}
+	if req.FailOnQuestion != nil {
+		job.FailOnQuestion = *req.FailOnQuestion
+	}
 	if req.LLMProvider != nil {
 		llmProvider := normalizeJobLLMProvider(*req.LLMProvider)
 		if llmProvider != "" {
@@ -2629,20 +3412,50 @@ func (s *Server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 	job.TaskPromptFile = strings.TrimSpace(taskPromptFile)
 	job.TaskPrompt = strings.TrimSpace(taskPrompt)
 
-	// Re-parse schedule if changed
+	if err := s.applyJobRunSettings(job, req.TimeoutMinutes, req.Model, req.AgentID); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := applyJobKeepExecutions(job, req.KeepExecutions); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.applyJobNotifySettings(job, req.NotifyOn, req.NotifyIntegrationIDs); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ResponseSchema != nil {
+		if err := applyJobResponseSchema(job, *req.ResponseSchema); err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	timezoneChanged := false
+	if req.Timezone != nil {
+		timezone, err := normalizeJobTimezone(*req.Timezone)
+		if err != nil {
+			s.errorResponse(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		timezoneChanged = timezone != job.Timezone
+		job.Timezone = timezone
+	}
+
+	// Re-parse schedule if changed, otherwise recalculate the next run
 	if req.ScheduleText != "" && req.ScheduleText != job.ScheduleHuman {
-		cronExpr, err := s.parseScheduleToCron(r.Context(), req.ScheduleText)
+		parsed, err := s.parseSchedule(r.Context(), req.ScheduleText, job.Timezone)
 		if err != nil {
 			s.errorResponse(w, http.StatusBadRequest, "Failed to parse schedule: "+err.Error())
 			return
 		}
 		job.ScheduleHuman = req.ScheduleText
-		job.ScheduleCron = cronExpr
-
-		// Recalculate next run time
-		nextRun, err := s.calculateNextRun(cronExpr, time.Now())
-		if err == nil {
-			job.NextRunAt = &nextRun
+		if err := jobs.ApplySchedule(job, parsed, time.Now()); err != nil {
+			logging.WarnContext(r.Context(), "Failed to calculate next run for job %s: %v", job.ID, err)
+		}
+	} else if timezoneChanged {
+		if next, err := jobs.NextRun(job, time.Now()); err == nil {
+			job.NextRunAt = next
 		}
 	}
 
@@ -2653,7 +3466,7 @@ func (s *Server) handleUpdateJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logging.Info("Updated recurring job: %s (%s)", job.Name, job.ID)
+	logging.InfoContext(r.Context(), "Updated recurring job: %s (%s)", job.Name, job.ID)
 	s.jsonResponse(w, http.StatusOK, s.jobToResponse(job))
 }
 
@@ -2675,7 +3488,7 @@ func (s *Server) handleDeleteJob(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	logging.Info("Deleted recurring job: %s", jobID)
+	logging.InfoContext(r.Context(), "Deleted recurring job: %s", jobID)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -2700,302 +3513,236 @@ func (s *Server) handleRunJobNow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Execute the job immediately (in a goroutine so we don't block)
-	exec, err := s.executeJob(r.Context(), job)
+	exec, err := s.startJobRun(r.Context(), job)
 	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to execute job: "+err.Error())
-		return
-	}
-
-	s.jsonResponse(w, http.StatusOK, s.executionToResponse(exec))
-}
-
-func (s *Server) handleListJobExecutions(w http.ResponseWriter, r *http.Request) {
-	jobID := chi.URLParam(r, "jobID")
-
-	// Get limit from query params, default to 20
-	limit := 20
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
-			limit = l
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, jobs.ErrAlreadyRunning):
+			status = http.StatusConflict
+		case errors.Is(err, errNoJobRunner):
+			status = http.StatusServiceUnavailable
 		}
-	}
-
-	executions, err := s.store.ListJobExecutions(jobID, limit)
-	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to list executions: "+err.Error())
+		s.errorResponse(w, status, "Failed to start job: "+err.Error())
 		return
 	}
 
-	resp := make([]JobExecutionResponse, len(executions))
-	for i, exec := range executions {
-		resp[i] = s.executionToResponse(exec)
-	}
-
-	s.jsonResponse(w, http.StatusOK, resp)
+	// The run continues in the background; poll the execution for its outcome
+	s.jsonResponse(w, http.StatusAccepted, s.executionToResponse(exec))
 }
 
-func (s *Server) handleListJobSessions(w http.ResponseWriter, r *http.Request) {
-	jobID := chi.URLParam(r, "jobID")
-
-	// First verify the job exists
-	_, err := s.store.GetJob(jobID)
-	if err != nil {
-		s.errorResponse(w, http.StatusNotFound, "Job not found: "+err.Error())
-		return
-	}
-
-	sessions, err := s.store.ListSessionsByJob(jobID)
-	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to list sessions: "+err.Error())
+func (s *Server) handleGetJobExecution(w http.ResponseWriter, r *http.Request) {
+	exec, ok := s.jobExecutionFromRequest(w, r)
+	if !ok {
 		return
 	}
 
-	resp := make([]SessionListItem, len(sessions))
-	for i, sess := range sessions {
-		provider, model := storageSessionProviderAndModel(sess)
-		routedProvider, routedModel := storageSessionRoutedProviderAndModel(sess)
-		projectID := ""
-		if sess.ProjectID != nil {
-			projectID = *sess.ProjectID
-		}
-		resp[i] = SessionListItem{
-			ID:                 sess.ID,
-			AgentID:            sess.AgentID,
-			ProjectID:          projectID,
-			Provider:           provider,
-			Model:              model,
-			RoutedProvider:     routedProvider,
-			RoutedModel:        routedModel,
-			Title:              sess.Title,
-			Status:             sess.Status,
-			TotalTokens:        storageSessionTotalTokens(sess),
-			RunDurationSeconds: sessionRunDurationSeconds(sess.CreatedAt, sess.UpdatedAt, sess.Status),
-			TaskProgress:       sess.TaskProgress,
-			CreatedAt:          sess.CreatedAt,
-			UpdatedAt:          sess.UpdatedAt,
-		}
-	}
-
-	s.jsonResponse(w, http.StatusOK, resp)
+	s.jsonResponse(w, http.StatusOK, s.executionToResponse(exec))
 }
 
-// parseScheduleToCron uses the LLM to convert natural language schedule to cron expression
-func (s *Server) parseScheduleToCron(ctx context.Context, scheduleText string) (string, error) {
-	prompt := fmt.Sprintf(`Convert the following natural language schedule to a standard 5-field cron expression.
-Only respond with the cron expression, nothing else. No explanation, no formatting, just the cron expression.
-
-Schedule: "%s"
-
-Examples:
-- "every day at 7pm" -> "0 19 * * *"
-- "every Monday at 9am" -> "0 9 * * 1"
-- "every hour" -> "0 * * * *"
-- "every weekday at 8:30am" -> "30 8 * * 1-5"
-- "every 15 minutes" -> "*/15 * * * *"
-
-Cron expression:`, scheduleText)
-
-	// Create a minimal session for this parsing task
-	sess, err := s.sessionManager.Create("scheduler")
-	if err != nil {
-		return "", fmt.Errorf("failed to create session: %w", err)
+// handleGetJobExecutionOutput serves the full output of an execution.
+// Executions recorded before outputs were kept in files serve their summary.
+func (s *Server) handleGetJobExecutionOutput(w http.ResponseWriter, r *http.Request) {
+	exec, ok := s.jobExecutionFromRequest(w, r)
+	if !ok {
+		return
 	}
-	defer s.sessionManager.Delete(sess.ID)
 
-	sess.AddUserMessage(prompt)
-
-	providerType := config.ProviderType(config.NormalizeProviderRef(s.config.ActiveProvider))
-	model := s.resolveModelForProvider(providerType)
-	target, err := s.resolveExecutionTarget(ctx, providerType, model, prompt, sess)
-	if err != nil {
-		return "", fmt.Errorf("failed to initialize provider %s: %w", providerType, err)
+	modTime := exec.StartedAt
+	if exec.FinishedAt != nil {
+		modTime = *exec.FinishedAt
 	}
-
-	// Create agent config for parsing
-	agentConfig := agent.Config{
-		Name:          "scheduler",
-		Model:         target.Model,
-		SystemPrompt:  "You convert natural-language schedules into strict 5-field cron expressions.",
-		MaxSteps:      1, // Only need one response
-		Temperature:   0, // Deterministic output
-		ContextWindow: target.ContextWindow,
+	w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if exec.OutputPath == "" {
+		http.ServeContent(w, r, "", modTime, strings.NewReader(exec.Output))
+		return
 	}
-
-	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
-	cronExpr, _, err := ag.Run(ctx, sess, prompt)
+	f, err := os.Open(exec.OutputPath)
 	if err != nil {
-		return "", fmt.Errorf("failed to parse schedule: %w", err)
-	}
-
-	// Clean up the response (trim whitespace)
-	cronExpr = strings.TrimSpace(cronExpr)
-
-	// Basic validation: should have 5 fields
-	fields := strings.Fields(cronExpr)
-	if len(fields) != 5 {
-		return "", fmt.Errorf("invalid cron expression: %s", cronExpr)
+		w.Header().Del("Content-Type")
+		s.errorResponse(w, http.StatusNotFound, "Execution output file is missing")
+		return
 	}
-
-	return cronExpr, nil
+	defer f.Close()
+	http.ServeContent(w, r, "", modTime, f)
 }
 
-// calculateNextRun calculates the next run time based on cron expression
-func (s *Server) calculateNextRun(cronExpr string, after time.Time) (time.Time, error) {
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	schedule, err := parser.Parse(cronExpr)
-	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid cron expression: %w", err)
+// jobExecutionFromRequest loads the execution named by the URL, answering
+// 404 when it does not exist or belongs to another job.
+func (s *Server) jobExecutionFromRequest(w http.ResponseWriter, r *http.Request) (*storage.JobExecution, bool) {
+	exec, err := s.store.GetJobExecution(chi.URLParam(r, "execID"))
+	if err != nil || exec.JobID != chi.URLParam(r, "jobID") {
+		s.errorResponse(w, http.StatusNotFound, "Execution not found")
+		return nil, false
 	}
-	return schedule.Next(after), nil
+	return exec, true
 }
 
-// executeJob runs a job and returns the execution record
-func (s *Server) executeJob(ctx context.Context, job *storage.RecurringJob) (*storage.JobExecution, error) {
-	now := time.Now()
-
-	// Create execution record
-	exec := &storage.JobExecution{
-		ID:        uuid.New().String(),
-		JobID:     job.ID,
-		Status:    "running",
-		StartedAt: now,
-	}
+func (s *Server) handleListJobExecutions(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
 
-	if err := s.store.SaveJobExecution(exec); err != nil {
-		return nil, fmt.Errorf("failed to create execution record: %w", err)
+	// Get limit from query params, default to 20
+	limit := 20
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
 	}
 
-	// Create a session for this job execution
-	sess, err := s.sessionManager.CreateWithJob("job-runner", job.ID)
+	executions, err := s.store.ListJobExecutions(jobID, limit)
 	if err != nil {
-		exec.Status = "failed"
-		exec.Error = "Failed to create session: " + err.Error()
-		finishedAt := time.Now()
-		exec.FinishedAt = &finishedAt
-		s.store.SaveJobExecution(exec)
-		return exec, nil
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to list executions: "+err.Error())
+		return
 	}
-	isThinkingJob := false
-	if thinking, thinkErr := s.isProtectedThinkingJob(job.ID); thinkErr != nil {
-		logging.Warn("Failed to check thinking job for project assignment: %v", thinkErr)
-	} else if thinking {
-		isThinkingJob = true
-		if assignErr := s.assignSessionToThinkingProject(sess); assignErr != nil {
-			logging.Warn("Failed to assign Thinking project for session %s: %v", sess.ID, assignErr)
-		}
+
+	resp := make([]JobExecutionResponse, len(executions))
+	for i, exec := range executions {
+		resp[i] = s.executionToResponse(exec)
 	}
 
-	exec.SessionID = sess.ID
+	s.jsonResponse(w, http.StatusOK, resp)
+}
 
-	providerType := s.resolveJobProviderType(job)
-	model := s.resolveModelForProvider(providerType)
-	sess.Metadata["provider"] = string(providerType)
-	sess.Metadata["model"] = model
-	if err := s.sessionManager.Save(sess); err != nil {
-		logging.Warn("Failed to persist job session provider metadata: %v", err)
-	}
-	_ = s.ensureSessionSystemPromptSnapshot(sess)
+// handlePruneJobExecutions deletes the executions of a job beyond the newest
+// ?keep=N, or the job's keep_executions, together with their sessions.
+// Running executions are kept.
+func (s *Server) handlePruneJobExecutions(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
 
-	effectiveTaskPrompt, resolveErr := jobs.ResolveTaskPrompt(job)
-	if resolveErr != nil {
-		exec.Status = "failed"
-		exec.Error = "Failed to resolve task instructions: " + resolveErr.Error()
-		finishedAt := time.Now()
-		exec.FinishedAt = &finishedAt
-		s.store.SaveJobExecution(exec)
-		return exec, nil
-	}
-	if isThinkingJob {
-		effectiveTaskPrompt = thinkingRunTaskPrompt
+	job, err := s.store.GetJob(jobID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Job not found: "+err.Error())
+		return
 	}
 
-	target, clientErr := s.resolveExecutionTarget(ctx, providerType, model, effectiveTaskPrompt, sess)
-	if clientErr != nil {
-		exec.Status = "failed"
-		exec.Error = "Failed to initialize provider: " + clientErr.Error()
-		finishedAt := time.Now()
-		exec.FinishedAt = &finishedAt
-		s.store.SaveJobExecution(exec)
-		return exec, nil
-	}
-	if setSessionRoutedProviderAndModel(sess, providerType, target.ProviderType, target.Model) {
-		if err := s.sessionManager.Save(sess); err != nil {
-			logging.Warn("Failed to persist job session routed target metadata: %v", err)
+	keep := jobs.KeepExecutions(job)
+	if raw := r.URL.Query().Get("keep"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			s.errorResponse(w, http.StatusBadRequest, "keep must be a non-negative integer")
+			return
 		}
+		keep = n
 	}
 
-	// Run the agent with resolved task prompt
-	agentConfig := agent.Config{
-		Name:          "job-runner",
-		Model:         target.Model,
-		SystemPrompt:  s.buildSystemPromptForSession(sess),
-		MaxSteps:      s.config.MaxSteps,
-		Temperature:   s.config.Temperature,
-		ContextWindow: target.ContextWindow,
+	pruned, err := s.store.PruneJobExecutions(jobID, keep, time.Now())
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to prune executions: "+err.Error())
+		return
 	}
-	ag := agent.New(agentConfig, target.Client, s.toolManagerForSession(sess), s.sessionManager)
-	sess.AddUserMessage(effectiveTaskPrompt)
-	output, _, err := ag.Run(ctx, sess, effectiveTaskPrompt)
 
-	finishedAt := time.Now()
-	exec.FinishedAt = &finishedAt
+	logging.InfoContext(r.Context(), "Pruned %d execution(s) of job %s, keeping the newest %d", len(pruned), jobID, keep)
+	s.jsonResponse(w, http.StatusOK, map[string]int{"deleted": len(pruned), "kept": keep})
+}
 
-	if err != nil {
-		exec.Status = "failed"
-		exec.Error = err.Error()
-	} else {
-		exec.Status = "success"
-		exec.Output = output
-	}
+func (s *Server) handleListJobSessions(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
 
-	// Update execution record
-	if err := s.store.SaveJobExecution(exec); err != nil {
-		logging.Error("Failed to update execution record: %v", err)
+	// First verify the job exists
+	_, err := s.store.GetJob(jobID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Job not found: "+err.Error())
+		return
 	}
 
-	// Update job's last run time and calculate next run
-	job.LastRunAt = &now
-	nextRun, err := s.calculateNextRun(job.ScheduleCron, now)
-	if err == nil {
-		job.NextRunAt = &nextRun
+	sessions, err := s.store.ListSessionsByJob(jobID)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to list sessions: "+err.Error())
+		return
 	}
-	job.UpdatedAt = now
 
-	if err := s.store.SaveJob(job); err != nil {
-		logging.Error("Failed to update job after execution: %v", err)
+	resp := make([]SessionListItem, len(sessions))
+	for i, sess := range sessions {
+		provider, model := storageSessionProviderAndModel(sess)
+		routedProvider, routedModel := storageSessionRoutedProviderAndModel(sess)
+		projectID := ""
+		if sess.ProjectID != nil {
+			projectID = *sess.ProjectID
+		}
+		resp[i] = SessionListItem{
+			ID:                 sess.ID,
+			AgentID:            sess.AgentID,
+			ProjectID:          projectID,
+			Provider:           provider,
+			Model:              model,
+			RoutedProvider:     routedProvider,
+			RoutedModel:        routedModel,
+			Title:              sess.Title,
+			Status:             sess.Status,
+			TotalTokens:        storageSessionTotalTokens(sess),
+			RunDurationSeconds: sessionRunDurationSeconds(sess.CreatedAt, sess.UpdatedAt, sess.Status),
+			TaskProgress:       sess.TaskProgress,
+			ProgressPct:        taskProgressPct(sess.TaskProgress),
+			CreatedAt:          sess.CreatedAt,
+			UpdatedAt:          sess.UpdatedAt,
+		}
 	}
 
-	return exec, nil
+	s.jsonResponse(w, http.StatusOK, resp)
 }
 
-func (s *Server) assignSessionToThinkingProject(sess *session.Session) error {
-	now := time.Now()
-	project := &storage.Project{
-		ID:        thinkingProjectID,
-		Name:      thinkingProjectName,
-		IsSystem:  true,
-		CreatedAt: now,
-		UpdatedAt: now,
+// parseSchedule converts a natural language schedule to a cron expression,
+// or for one-time phrasings such as "tomorrow at 9am" to a run time in
+// timezone. Common forms are parsed locally; the active provider is only
+// asked, in a single tool-free request, when the text is not recognized.
+func (s *Server) parseSchedule(ctx context.Context, scheduleText, timezone string) (schedule.Schedule, error) {
+	loc, err := schedule.LoadLocation(timezone)
+	if err != nil {
+		return schedule.Schedule{}, err
 	}
-	if err := s.store.SaveProject(project); err != nil {
-		return err
+	parsed, err := schedule.ParseAt(scheduleText, time.Now().In(loc))
+	if !errors.Is(err, schedule.ErrUnrecognized) {
+		return parsed, err
+	}
+
+	providerType := config.ProviderType(config.NormalizeProviderRef(s.config.ActiveProvider))
+	model := s.resolveModelForProvider(providerType)
+	target, targetErr := s.resolveExecutionTarget(ctx, providerType, model, scheduleText, nil)
+	if targetErr != nil {
+		// Keep the parser's explanation; the model was only a fallback.
+		return schedule.Schedule{}, fmt.Errorf("%w (no model to fall back on: %v)", err, targetErr)
 	}
-	sess.ProjectID = &project.ID
-	return s.sessionManager.Save(sess)
+	return schedule.ParseWithLLM(ctx, target.Client, target.Model, scheduleText)
 }
 
 // jobToResponse converts a storage job to API response
 func (s *Server) jobToResponse(job *storage.RecurringJob) JobResponse {
+	timezone := job.Timezone
+	if timezone == "" {
+		timezone = schedule.DefaultTimezone()
+	}
+	summary := schedule.Describe(job.ScheduleCron)
+	if jobs.IsOneShot(job) {
+		loc, err := schedule.LoadLocation(job.Timezone)
+		if err != nil {
+			loc = time.Local
+		}
+		summary = schedule.DescribeOnce(job.RunAt.In(loc))
+	}
+	notifyTargets := job.NotifyTargets
+	if notifyTargets == nil {
+		notifyTargets = []string{}
+	}
 	return JobResponse{
 		ID:               job.ID,
 		Name:             job.Name,
 		ScheduleHuman:    job.ScheduleHuman,
 		ScheduleCron:     job.ScheduleCron,
+		ScheduleSummary:  summary,
+		RunAt:            job.RunAt,
 		TaskPrompt:       job.TaskPrompt,
 		TaskPromptSource: jobs.NormalizeTaskPromptSource(job.TaskPromptSource),
 		TaskPromptFile:   strings.TrimSpace(job.TaskPromptFile),
 		LLMProvider:      job.LLMProvider,
+		Timezone:         timezone,
+		TimeoutMinutes:   int(jobs.Timeout(job) / time.Minute),
+		KeepExecutions:   jobs.KeepExecutions(job),
+		Model:            job.Model,
+		AgentID:          jobs.AgentID(job),
+		NotifyOn:         job.NotifyOn,
+		NotifyTargets:    notifyTargets,
+		FailOnQuestion:   job.FailOnQuestion,
+		ResponseSchema:   jobs.ResponseSchema(job),
 		Enabled:          job.Enabled,
 		LastRunAt:        job.LastRunAt,
 		NextRunAt:        job.NextRunAt,
@@ -3006,7 +3753,7 @@ func (s *Server) jobToResponse(job *storage.RecurringJob) JobResponse {
 
 // executionToResponse converts a storage execution to API response
 func (s *Server) executionToResponse(exec *storage.JobExecution) JobExecutionResponse {
-	return JobExecutionResponse{
+	resp := JobExecutionResponse{
 		ID:         exec.ID,
 		JobID:      exec.JobID,
 		SessionID:  exec.SessionID,
@@ -3016,6 +3763,10 @@ func (s *Server) executionToResponse(exec *storage.JobExecution) JobExecutionRes
 		StartedAt:  exec.StartedAt,
 		FinishedAt: exec.FinishedAt,
 	}
+	if exec.OutputPath != "" {
+		resp.OutputURL = "/jobs/" + url.PathEscape(exec.JobID) + "/executions/" + url.PathEscape(exec.ID) + "/output"
+	}
+	return resp
 }
 
 // --- Helper methods ---
@@ -3029,6 +3780,10 @@ func (s *Server) sessionToResponse(sess *session.Session) SessionResponse {
 	if sess.ProjectID != nil {
 		projectID = *sess.ProjectID
 	}
+	jobID := ""
+	if sess.JobID != nil {
+		jobID = *sess.JobID
+	}
 	provider, model := sessionProviderAndModel(sess)
 	routedProvider, routedModel := sessionRoutedProviderAndModel(sess)
 	snapshot := sessionSystemPromptSnapshot(sess)
@@ -3066,8 +3821,11 @@ func (s *Server) sessionToResponse(sess *session.Session) SessionResponse {
 		ParentID:             parentID,
 		LinkType:             sessionLinkType(sess),
 		ProjectID:            projectID,
+		JobID:                jobID,
+		ExecutionID:          sess.ExecutionID(),
 		Provider:             provider,
 		Model:                model,
+		Temperature:          sessionTemperature(sess),
 		RoutedProvider:       routedProvider,
 		RoutedModel:          routedModel,
 		Title:                sess.Title,
@@ -3078,10 +3836,13 @@ func (s *Server) sessionToResponse(sess *session.Session) SessionResponse {
 		CurrentContextTokens: currentContextTokens,
 		ModelContextWindow:   modelContextWindow,
 		TaskProgress:         sess.TaskProgress,
+		PendingQuestion:      sessionPendingQuestion(sess),
 		ProviderFailures:     sessionProviderFailures(sess.Metadata),
 		CreatedAt:            sess.CreatedAt,
 		UpdatedAt:            sess.UpdatedAt,
 		Messages:             s.messagesToResponse(sess.Messages),
+		MessageCount:         len(sess.Messages),
+		HasImages:            sessionHasImages(sess.Messages),
 		SystemPromptSnapshot: snapshotPayload,
 		A2AOutbound:          isOutbound,
 		A2ATargetAgentID:     targetAgentID,
@@ -3098,6 +3859,7 @@ func (s *Server) messagesToResponse(messages []session.Message) []MessageRespons
 			Images:    sessionImagesToPayload(m.Images),
 			Metadata:  m.Metadata,
 			Timestamp: m.Timestamp,
+			HasImages: messageHasImages(m),
 		}
 
 		if len(m.ToolCalls) > 0 {
@@ -3116,11 +3878,14 @@ func (s *Server) messagesToResponse(messages []session.Message) []MessageRespons
 			msg.ToolResults = make([]ToolResultResponse, len(m.ToolResults))
 			for j, tr := range m.ToolResults {
 				msg.ToolResults[j] = ToolResultResponse{
-					ToolCallID: tr.ToolCallID,
-					Content:    tr.Content,
-					IsError:    tr.IsError,
-					Metadata:   tr.Metadata,
-					Name:       tr.Name,
+					ToolCallID:  tr.ToolCallID,
+					Content:     tr.Content,
+					IsError:     tr.IsError,
+					Metadata:    tr.Metadata,
+					Name:        tr.Name,
+					StartedAt:   tr.StartedAt,
+					DurationMs:  tr.DurationMs,
+					OutputBytes: tr.OutputBytes,
 				}
 			}
 		}
@@ -3130,6 +3895,29 @@ func (s *Server) messagesToResponse(messages []session.Message) []MessageRespons
 	return resp
 }
 
+// messageHasImages reports whether a message has attached images or tool
+// results that returned one.
+func messageHasImages(m session.Message) bool {
+	if len(m.Images) > 0 {
+		return true
+	}
+	for _, tr := range m.ToolResults {
+		if _, ok := llm.InlineImageFromMetadata(tr.Metadata); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func sessionHasImages(messages []session.Message) bool {
+	for _, m := range messages {
+		if messageHasImages(m) {
+			return true
+		}
+	}
+	return false
+}
+
 func normalizeIncomingImages(images []MessageImagePayload) ([]session.ImageAttachment, error) {
 	if len(images) == 0 {
 		return nil, nil
@@ -3233,8 +4021,10 @@ func (s *Server) jsonResponse(w http.ResponseWriter, status int, data interface{
 }
 
 func (s *Server) errorResponse(w http.ResponseWriter, status int, message string) {
-	logging.Error("HTTP error: %d - %s", status, message)
-	s.jsonResponse(w, status, map[string]string{"error": message})
+	// requestIDMiddleware has already set the ID on the response
+	requestID := w.Header().Get(requestIDHeader)
+	logging.ErrorContext(logging.WithRequestID(context.Background(), requestID), "HTTP error: %d - %s", status, message)
+	s.jsonResponse(w, status, ErrorResponse{Error: message, Code: errorCode(status), RequestID: requestID})
 }
 
 func (s *Server) handleListProjects(w http.ResponseWriter, r *http.Request) {
@@ -3525,6 +4315,9 @@ func (s *Server) composeSystemPromptSnapshotWithSettings(sess *session.Session,
 	}
 
 	basePrompt := strings.TrimSpace(os.Getenv("AAGENT_SYSTEM_PROMPT"))
+	if basePrompt == "" && sess != nil {
+		basePrompt = strings.TrimSpace(s.agentDefinition(sess, sess.AgentID).SystemPrompt)
+	}
 	if basePrompt == "" {
 		if builtInToolsEnabled {
 			basePrompt = agent.DefaultSystemPrompt()
@@ -3577,7 +4370,7 @@ func (s *Server) composeSystemPromptSnapshotWithSettings(sess *session.Session,
 			blockSnapshot.EstimatedTokens = estimateTokensApprox(section)
 			appendSections = append(appendSections, section)
 		case externalMarkdownSkillsInstructionBlockType:
-			section, estimatedTokens, resolveErr := s.resolveExternalMarkdownSkillsSection(settings, sectionNumber)
+			section, estimatedTokens, resolveErr := s.resolveExternalMarkdownSkillsSection(sess, settings, sectionNumber)
 			blockSnapshot.ResolvedContent = section
 			blockSnapshot.Error = resolveErr
 			if section == "" {
@@ -4210,7 +5003,7 @@ Guidelines:
 			blockSnapshot.EstimatedTokens = estimateTokensApprox(section)
 			appendSections = append(appendSections, section)
 		case externalMarkdownSkillsInstructionBlockType:
-			section, estimatedTokens, resolveErr := s.resolveExternalMarkdownSkillsSection(settings, sectionNumber)
+			section, estimatedTokens, resolveErr := s.resolveExternalMarkdownSkillsSection(sess, settings, sectionNumber)
 			blockSnapshot.ResolvedContent = section
 			blockSnapshot.Error = resolveErr
 			if section == "" {
@@ -4362,110 +5155,32 @@ func (s *Server) resolveMCPServersSection(blockNumber int) (string, int, string)
 	return strings.Join(lines, "\n"), totalTokens, ""
 }
 
-func (s *Server) resolveExternalMarkdownSkillsSection(settings map[string]string, blockNumber int) (string, int, string) {
-	folder := strings.TrimSpace(settings[skillsFolderSettingKey])
-	if folder == "" {
-		return "", 0, "Skills folder is not configured."
-	}
-
-	resolvedFolder, err := filepath.Abs(folder)
+func (s *Server) resolveExternalMarkdownSkillsSection(sess *session.Session, settings map[string]string, blockNumber int) (string, int, string) {
+	resolvedFolder, err := skillsLoader.ResolveFolder(settings[skillsFolderSettingKey])
 	if err != nil {
-		return "", 0, "Invalid skills folder path."
+		return "", 0, capitalizeSkillsError(err)
 	}
 
-	info, err := os.Stat(resolvedFolder)
-	if err != nil {
-		return "", 0, "Skills folder is not accessible: " + err.Error()
-	}
-	if !info.IsDir() {
-		return "", 0, "Skills folder path is not a directory."
-	}
-
-	// Load configuration
-	config, configErr := skillsLoader.LoadConfig(resolvedFolder)
-	if configErr != nil {
-		// Use default config on error
-		config = skillsLoader.DefaultConfig()
+	var selected []string
+	if sess != nil {
+		selected = skillsLoader.SessionSelection(sess.Metadata)
 	}
-
-	// Load all skills with configuration
-	allSkills, loadErr := skillsLoader.LoadSkillsFromDirectory(resolvedFolder, config)
+	disabledSkills := resolveDisabledExternalMarkdownSkillPaths(settings, resolvedFolder)
+	activeSkills, config, loadErr := skillsLoader.LoadActive(resolvedFolder, disabledSkills, selected)
 	if loadErr != nil {
 		return "", 0, "Failed to load skills: " + loadErr.Error()
 	}
-
-	if len(allSkills) == 0 {
+	if len(activeSkills) == 0 {
 		return "", 0, "No markdown skills discovered in configured skills folder."
 	}
 
-	disabledSkills := resolveDisabledExternalMarkdownSkillPaths(settings, resolvedFolder)
-
-	// Separate skills by strategy
-	alwaysSkills := make([]*skillsLoader.Skill, 0)
-	onDemandSkills := make([]*skillsLoader.Skill, 0)
-
-	for _, skill := range allSkills {
-		if _, isDisabled := disabledSkills[filepath.Clean(strings.TrimSpace(skill.Path))]; isDisabled {
-			continue
-		}
-		if skill.Strategy == skillsLoader.StrategyDisabled {
-			continue
-		}
-		if skill.Strategy == skillsLoader.StrategyAlways {
-			alwaysSkills = append(alwaysSkills, skill)
-		} else {
-			onDemandSkills = append(onDemandSkills, skill)
-		}
-	}
-
-	// Sort by priority (lower = higher priority)
-	sort.Slice(alwaysSkills, func(i, j int) bool {
-		return alwaysSkills[i].Priority < alwaysSkills[j].Priority
-	})
-	sort.Slice(onDemandSkills, func(i, j int) bool {
-		return strings.ToLower(onDemandSkills[i].Name) < strings.ToLower(onDemandSkills[j].Name)
-	})
-
-	// Build prompt sections
 	var builder strings.Builder
-	totalEstimatedTokens := 0
-
 	builder.WriteString(fmt.Sprintf("Instruction block %d (external markdown skills):\n", blockNumber))
 	builder.WriteString(fmt.Sprintf("Connected skills folder: %s\n\n", resolvedFolder))
+	body := skillsLoader.PromptSection(activeSkills, maxDynamicInstructionBytes)
+	builder.WriteString(body)
+	totalEstimatedTokens := estimateTokensApprox(body)
 
-	// 1. Always-loaded skills (full content)
-	if len(alwaysSkills) > 0 {
-		builder.WriteString("Loaded skills (always available):\n\n")
-		for _, skill := range alwaysSkills {
-			content := skill.Body
-			if len(content) > maxDynamicInstructionBytes {
-				content = content[:maxDynamicInstructionBytes] + "\n\n[truncated]"
-			}
-
-			section := fmt.Sprintf("Instructions from: %s\n%s\n\n", skill.RelativePath, content)
-			tokens := estimateTokensApprox(section)
-			totalEstimatedTokens += tokens
-			builder.WriteString(section)
-		}
-	}
-
-	// 2. On-demand skills (name + description only)
-	if len(onDemandSkills) > 0 {
-		builder.WriteString("Available skills (use Read tool to load):\n\n")
-		for _, skill := range onDemandSkills {
-			var line string
-			if skill.Description != "" {
-				line = fmt.Sprintf("- %s: %s [%s]\n", skill.Name, skill.Description, skill.RelativePath)
-			} else {
-				line = fmt.Sprintf("- %s [%s]\n", skill.Name, skill.RelativePath)
-			}
-			tokens := estimateTokensApprox(line)
-			totalEstimatedTokens += tokens
-			builder.WriteString(line)
-		}
-	}
-
-	// 3. Token budget warning
 	if config.MaxAutoLoadTokens > 0 && totalEstimatedTokens > config.MaxAutoLoadTokens {
 		warningMsg := fmt.Sprintf(
 			"\n⚠️  Warning: Auto-loaded skills exceed token budget (%d > %d)\n",
@@ -4478,39 +5193,21 @@ func (s *Server) resolveExternalMarkdownSkillsSection(settings map[string]string
 	return builder.String(), totalEstimatedTokens, ""
 }
 
-func resolveDisabledExternalMarkdownSkillPaths(settings map[string]string, skillsFolder string) map[string]struct{} {
-	disabled := make(map[string]struct{})
-	if settings == nil {
-		return disabled
-	}
-
-	raw := strings.TrimSpace(settings[externalMarkdownDisabledSkillsSettingKey])
-	if raw == "" {
-		return disabled
-	}
-
-	entries := make([]string, 0)
-	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
-		entries = strings.FieldsFunc(raw, func(r rune) bool {
-			return r == ',' || r == '\n'
-		})
+// capitalizeSkillsError renders a skills folder error as an instruction block
+// error message.
+func capitalizeSkillsError(err error) string {
+	message := err.Error()
+	if message == "" {
+		return message
 	}
+	return strings.ToUpper(message[:1]) + message[1:] + "."
+}
 
-	for _, entry := range entries {
-		candidate := strings.TrimSpace(entry)
-		if candidate == "" {
-			continue
-		}
-		if !filepath.IsAbs(candidate) {
-			candidate = filepath.Join(skillsFolder, candidate)
-		}
-		resolved, err := filepath.Abs(candidate)
-		if err != nil {
-			continue
-		}
-		disabled[filepath.Clean(resolved)] = struct{}{}
+func resolveDisabledExternalMarkdownSkillPaths(settings map[string]string, skillsFolder string) map[string]struct{} {
+	if settings == nil {
+		return make(map[string]struct{})
 	}
-	return disabled
+	return skillsLoader.ParsePathList(settings[externalMarkdownDisabledSkillsSettingKey], skillsFolder)
 }
 
 func syncSettingsToEnv(previous map[string]string, next map[string]string) {
@@ -4566,7 +5263,7 @@ func normalizeSessionLinkType(raw string) (string, error) {
 		return "", nil
 	}
 	switch normalized {
-	case sessionLinkTypeReview, sessionLinkTypeContinuation:
+	case sessionLinkTypeReview, sessionLinkTypeContinuation, sessionLinkTypeFork:
 		return normalized, nil
 	default:
 		return "", fmt.Errorf("invalid link_type: %s", raw)
@@ -4982,6 +5679,118 @@ func normalizeJobLLMProvider(raw string) string {
 	return config.NormalizeProviderRef(raw)
 }
 
+// applyJobRunSettings validates and sets a job's timeout, model and agent
+// type. Nil values leave the current setting; empty ones restore the default.
+func (s *Server) applyJobRunSettings(job *storage.RecurringJob, timeoutMinutes *int, model, agentID *string) error {
+	if timeoutMinutes != nil {
+		if err := jobs.ValidateTimeoutMinutes(*timeoutMinutes); err != nil {
+			return err
+		}
+		job.TimeoutMinutes = *timeoutMinutes
+	}
+	if model != nil {
+		job.Model = strings.TrimSpace(*model)
+	}
+	if agentID != nil {
+		id := strings.TrimSpace(*agentID)
+		if id != "" && id != jobs.DefaultAgentID {
+			registry, err := agents.Load(s.config, s.config.WorkDir)
+			if err != nil {
+				logging.Warn("Failed to load some agent definitions: %v", err)
+			}
+			if !registry.Has(id) {
+				names := []string{jobs.DefaultAgentID}
+				for _, def := range registry.List() {
+					names = append(names, def.Name)
+				}
+				return fmt.Errorf("unknown agent_id %q (available: %s)", id, strings.Join(names, ", "))
+			}
+		}
+		if id == jobs.DefaultAgentID {
+			id = ""
+		}
+		job.AgentID = id
+	}
+	return nil
+}
+
+// applyJobNotifySettings validates and sets when a job announces finished
+// runs and through which integrations. Nil values leave the current setting.
+func (s *Server) applyJobNotifySettings(job *storage.RecurringJob, notifyOn *string, integrationIDs *[]string) error {
+	if notifyOn != nil {
+		value, err := jobs.NormalizeNotifyOn(*notifyOn)
+		if err != nil {
+			return err
+		}
+		job.NotifyOn = value
+	}
+	if integrationIDs != nil {
+		var ids []string
+		seen := make(map[string]bool)
+		for _, id := range *integrationIDs {
+			id = strings.TrimSpace(id)
+			if id != "" && !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		if err := jobs.ValidateNotifyTargets(s.store, ids); err != nil {
+			return err
+		}
+		job.NotifyTargets = ids
+	}
+	if job.NotifyOn != "" && len(job.NotifyTargets) == 0 {
+		return fmt.Errorf("notify_on requires at least one notify_integration_ids entry")
+	}
+	return nil
+}
+
+// applyJobKeepExecutions validates and sets how many executions pruning
+// keeps for a job. A nil value leaves the current setting.
+func applyJobKeepExecutions(job *storage.RecurringJob, keep *int) error {
+	if keep == nil {
+		return nil
+	}
+	if err := jobs.ValidateKeepExecutions(*keep); err != nil {
+		return err
+	}
+	job.KeepExecutions = *keep
+	return nil
+}
+
+// applyJobResponseSchema validates and sets the JSON Schema a job's answers
+// must match. An empty value, "" or null clears it.
+func applyJobResponseSchema(job *storage.RecurringJob, schema json.RawMessage) error {
+	switch strings.TrimSpace(string(schema)) {
+	case "", `""`, "null":
+		job.ResponseSchema = ""
+		return nil
+	}
+	if err := llm.CheckSchema(schema); err != nil {
+		return err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, schema); err != nil {
+		return err
+	}
+	job.ResponseSchema = compact.String()
+	return nil
+}
+
+// normalizeJobTimezone validates a job timezone, defaulting to the server's
+// zone so the schedule keeps its meaning if the server later moves.
+func normalizeJobTimezone(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return schedule.DefaultTimezone(), nil
+	}
+	loc, err := schedule.LoadLocation(raw)
+	if err != nil {
+		return "", err
+	}
+	return loc.String(), nil
+}
+
 func (s *Server) resolveJobProviderType(job *storage.RecurringJob) config.ProviderType {
 	if job != nil {
 		provider := normalizeJobLLMProvider(job.LLMProvider)
@@ -5030,6 +5839,47 @@ func (s *Server) resolveSessionModel(sess *session.Session, providerType config.
 	return s.resolveModelForProvider(providerType)
 }
 
+// resolveSessionTemperature returns the session's temperature override, the
+// agent type's temperature, or the configured default, in that order.
+func (s *Server) resolveSessionTemperature(sess *session.Session, agentDef *agents.Definition) float64 {
+	if temperature := sessionTemperature(sess); temperature != nil {
+		return *temperature
+	}
+	if agentDef != nil && agentDef.Temperature != nil {
+		return *agentDef.Temperature
+	}
+	return s.config.Temperature
+}
+
+// agentDefinition resolves an agent type through the agents registry,
+// including definitions in the session's project. Definitions are read on
+// every run so edits apply without a restart. The model of a definition is
+// not applied here: sessions pick their provider and model before the run.
+func (s *Server) agentDefinition(sess *session.Session, name string) *agents.Definition {
+	registry, err := agents.Load(s.config, s.resolveSessionWorkDir(sess))
+	if err != nil {
+		logging.Warn("Failed to load some agent definitions: %v", err)
+	}
+	return registry.Resolve(name)
+}
+
+func sessionTemperature(sess *session.Session) *float64 {
+	if sess == nil {
+		return nil
+	}
+	if temperature, ok := sess.Temperature(); ok {
+		return &temperature
+	}
+	return nil
+}
+
+func validateTemperature(temperature float64) error {
+	if math.IsNaN(temperature) || temperature < 0 || temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	return nil
+}
+
 func (s *Server) resolveContextWindowForProvider(providerType config.ProviderType) int {
 	if providerType == config.ProviderAutoRouter {
 		return 0
@@ -5058,6 +5908,14 @@ func (s *Server) resolveContextWindowForProvider(providerType config.ProviderTyp
 }
 
 func (s *Server) createLLMClient(providerType config.ProviderType, model string, sess *session.Session) (llm.Client, error) {
+	client, err := s.createProviderClient(providerType, model, sess)
+	if err != nil {
+		return nil, err
+	}
+	return reqlog.WrapFromConfig(client, s.config, s.store), nil
+}
+
+func (s *Server) createProviderClient(providerType config.ProviderType, model string, sess *session.Session) (llm.Client, error) {
 	if providerType == config.ProviderAutoRouter {
 		return nil, fmt.Errorf("automatic router requires dynamic prompt routing")
 	}
@@ -5068,6 +5926,9 @@ func (s *Server) createLLMClient(providerType config.ProviderType, model string,
 	if err != nil {
 		return nil, err
 	}
+	if chained := s.withFallbackModels(providerType, model, client); chained != nil {
+		return chained, nil
+	}
 	retries := s.config.LLMRetries
 	if retries <= 0 {
 		retries = retry.DefaultMaxRetries
@@ -5075,6 +5936,36 @@ func (s *Server) createLLMClient(providerType config.ProviderType, model string,
 	return retry.Wrap(client, retry.WithMaxRetries(retries)), nil
 }
 
+// withFallbackModels puts client at the head of a chain over the configured
+// fallback_models. It returns nil when no usable fallback model is set.
+func (s *Server) withFallbackModels(providerType config.ProviderType, model string, client llm.Client) llm.Client {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		model = s.resolveModelForProvider(providerType)
+	}
+	chain, err := s.config.FallbackModelNodes(providerType, model)
+	if err != nil {
+		logging.Warn("Ignoring fallback_models entries: %v", err)
+	}
+	nodes := []fallback.Node{{Name: string(providerType), Model: model, Client: client}}
+	for _, node := range chain {
+		nodeClient, err := s.createBaseLLMClient(config.ProviderType(node.Provider), node.Model)
+		if err != nil {
+			logging.Warn("Fallback model %s/%s is not available: %v", node.Provider, node.Model, err)
+			continue
+		}
+		nodes = append(nodes, fallback.Node{Name: node.Provider, Model: node.Model, Client: nodeClient})
+	}
+	if len(nodes) == 1 {
+		return nil
+	}
+	retries := s.config.LLMRetries
+	if retries <= 0 {
+		retries = fallback.DefaultMaxRetries
+	}
+	return fallback.NewClient(nodes, fallback.WithMaxRetries(retries))
+}
+
 func (s *Server) createBaseLLMClient(providerType config.ProviderType, model string) (llm.Client, error) {
 	def := config.GetProviderDefinition(providerType)
 	if def == nil {
@@ -5571,15 +6462,15 @@ func (s *Server) handleBrowserChromeLaunch(w http.ResponseWriter, r *http.Reques
 	// This is the same path used by browser_chrome.go tool
 	chromeAgentDir := filepath.Join(home, "Library", "Application Support", "Google", "ChromeAgent")
 
-	logging.Info("Using ChromeAgent directory: %s", chromeAgentDir)
+	logging.InfoContext(r.Context(), "Using ChromeAgent directory: %s", chromeAgentDir)
 
 	// Create directory if it doesn't exist
 	profileExists := false
 	if _, err := os.Stat(chromeAgentDir); err == nil {
 		profileExists = true
-		logging.Info("ChromeAgent directory already exists")
+		logging.InfoContext(r.Context(), "ChromeAgent directory already exists")
 	} else {
-		logging.Info("Creating ChromeAgent directory: %s", chromeAgentDir)
+		logging.InfoContext(r.Context(), "Creating ChromeAgent directory: %s", chromeAgentDir)
 		if err := os.MkdirAll(chromeAgentDir, 0755); err != nil {
 			http.Error(w, fmt.Sprintf(`{"error": "failed to create ChromeAgent directory: %s"}`, err.Error()), http.StatusInternalServerError)
 			return
@@ -5596,7 +6487,7 @@ func (s *Server) handleBrowserChromeLaunch(w http.ResponseWriter, r *http.Reques
 		"--no-default-browser-check",
 	}
 
-	logging.Info("Launching Chrome with user-data-dir: %s", chromeAgentDir)
+	logging.InfoContext(r.Context(), "Launching Chrome with user-data-dir: %s", chromeAgentDir)
 
 	cmd := exec.Command(chromePath, args...)
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
@@ -5606,7 +6497,7 @@ func (s *Server) handleBrowserChromeLaunch(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	logging.Info("Chrome launched with ChromeAgent profile, PID: %d", cmd.Process.Pid)
+	logging.InfoContext(r.Context(), "Chrome launched with ChromeAgent profile, PID: %d", cmd.Process.Pid)
 
 	message := "Chrome opened with agent profile. Log in to websites here - the agent will use these sessions."
 	if !profileExists {
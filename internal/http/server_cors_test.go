@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+)
+
+func TestCORSHonoursAllowedOrigins(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name            string
+		origins         []string
+		origin          string
+		wantAllowOrigin string
+		wantCredentials string
+	}{
+		{name: "default allows localhost", origin: "http://localhost:5173", wantAllowOrigin: "http://localhost:5173", wantCredentials: "true"},
+		{name: "default rejects remote", origin: "https://evil.example", wantAllowOrigin: ""},
+		{name: "explicit list", origins: []string{"https://ui.example"}, origin: "https://ui.example", wantAllowOrigin: "https://ui.example", wantCredentials: "true"},
+		{name: "wildcard disables credentials", origins: []string{"*"}, origin: "https://any.example", wantAllowOrigin: "*", wantCredentials: ""},
+	}
+	for _, tc := range cases {
+		s := &Server{config: &config.Config{HTTP: config.HTTPConfig{AllowedOrigins: tc.origins}}}
+		s.setupRoutes()
+
+		req := httptest.NewRequest(http.MethodGet, "/health", nil)
+		req.Header.Set("Origin", tc.origin)
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != tc.wantAllowOrigin {
+			t.Fatalf("%s: Access-Control-Allow-Origin = %q, want %q", tc.name, got, tc.wantAllowOrigin)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Credentials"); got != tc.wantCredentials {
+			t.Fatalf("%s: Access-Control-Allow-Credentials = %q, want %q", tc.name, got, tc.wantCredentials)
+		}
+	}
+}
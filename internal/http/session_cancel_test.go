@@ -0,0 +1,104 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+)
+
+type fakeRunCanceller struct {
+	sessions map[string]bool
+}
+
+func (f *fakeRunCanceller) CancelSession(sessionID string) bool {
+	ok := f.sessions[sessionID]
+	delete(f.sessions, sessionID)
+	return ok
+}
+
+func (f *fakeRunCanceller) SessionRunning(sessionID string) bool {
+	return f.sessions[sessionID]
+}
+
+func TestCancelSessionStopsServerAndExternalRuns(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := sessionManager.SetSessionStatus(sess.ID, string(session.StatusRunning)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+
+	runCtx, cancelRun := context.WithCancel(context.Background())
+	defer cancelRun()
+	server.registerActiveSessionRun(sess.ID, cancelRun)
+	server.AddRunCanceller(&fakeRunCanceller{sessions: map[string]bool{sess.ID: true}})
+
+	rec := serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/cancel", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("cancel: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp struct {
+		CancelledRuns int    `json:"cancelled_runs"`
+		SessionStatus string `json:"session_status"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.CancelledRuns != 2 {
+		t.Fatalf("cancelled_runs = %d, want 2", resp.CancelledRuns)
+	}
+	if resp.SessionStatus != string(session.StatusPaused) {
+		t.Fatalf("session_status = %q, want paused", resp.SessionStatus)
+	}
+	if runCtx.Err() == nil {
+		t.Fatal("server run context was not cancelled")
+	}
+
+	// Nothing left to cancel the second time.
+	rec = serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/cancel", "")
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.CancelledRuns != 0 {
+		t.Fatalf("second cancel: cancelled_runs = %d, want 0", resp.CancelledRuns)
+	}
+}
+
+func TestWaitForActiveRunsHonoursGracePeriod(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+
+	finishedCtx, finish := context.WithCancel(context.Background())
+	defer finish()
+	runID := server.registerActiveSessionRun("finishing", finish)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		server.unregisterActiveSessionRun("finishing", runID)
+	}()
+	waitCtx, cancelWait := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancelWait()
+	if remaining := server.waitForActiveRuns(waitCtx); remaining != 0 {
+		t.Fatalf("remaining = %d, want the finished run to be awaited", remaining)
+	}
+	if finishedCtx.Err() != nil {
+		t.Fatal("a run that finished in time was cancelled")
+	}
+
+	stuckCtx, cancelStuck := context.WithCancel(context.Background())
+	defer cancelStuck()
+	server.registerActiveSessionRun("stuck", cancelStuck)
+	shortCtx, cancelShort := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelShort()
+	if remaining := server.waitForActiveRuns(shortCtx); remaining != 1 {
+		t.Fatalf("remaining = %d, want 1", remaining)
+	}
+	server.cancelAllActiveRuns()
+	if stuckCtx.Err() == nil {
+		t.Fatal("run still active after the grace period was not cancelled")
+	}
+}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/A2gent/brute/internal/jobs"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/go-chi/chi/v5"
+)
+
+// handleGetSessionExecution returns the job execution a session runs, the
+// other direction of GET /jobs/{id}/sessions. Sessions not started by a job
+// run, or started before runs recorded their execution, answer 404.
+func (s *Server) handleGetSessionExecution(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	sess, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+	execID := sess.ExecutionID()
+	if execID == "" {
+		s.errorResponse(w, http.StatusNotFound, "Session was not started by a job run")
+		return
+	}
+	exec, err := s.store.GetJobExecution(execID)
+	if err != nil || (exec.SessionID != "" && exec.SessionID != sess.ID) {
+		s.errorResponse(w, http.StatusNotFound, "Execution not found")
+		return
+	}
+	s.jsonResponse(w, http.StatusOK, s.executionToResponse(exec))
+}
+
+// jobExecutions returns the keeper of job execution records, configured like
+// the scheduler's.
+func (s *Server) jobExecutions() jobs.Executions {
+	return jobs.Executions{
+		Store:        s.store,
+		Sessions:     s.sessionManager,
+		DataPath:     s.config.DataPath,
+		SummaryChars: s.config.Jobs.SummaryChars(),
+	}
+}
+
+// resumeJobExecution marks the job execution waiting on an answer in sess as
+// running again and returns it, or nil when sess is not such a job run.
+func (s *Server) resumeJobExecution(sess *session.Session) *storage.JobExecution {
+	execID := sess.ExecutionID()
+	if execID == "" {
+		return nil
+	}
+	exec, err := s.store.GetJobExecution(execID)
+	if err != nil || exec.Status != jobs.StatusWaitingInput || exec.SessionID != sess.ID {
+		return nil
+	}
+	exec.Status = "running"
+	if err := s.store.SaveJobExecution(exec); err != nil {
+		logging.Warn("Failed to mark execution %s running again: %v", exec.ID, err)
+	}
+	return exec
+}
+
+// settleJobExecution records the outcome of a job run resumed by an answer
+// and announces it once the execution finishes.
+func (s *Server) settleJobExecution(ctx context.Context, exec *storage.JobExecution, sess *session.Session, output string, err error) {
+	records := s.jobExecutions()
+	job, getErr := s.store.GetJob(exec.JobID)
+	if getErr != nil {
+		logging.Warn("Job %s of execution %s not found: %v", exec.JobID, exec.ID, getErr)
+		records.Finish(ctx, exec, output, err)
+		return
+	}
+	if records.Settle(ctx, job, exec, sess, output, err) {
+		go jobs.NotifyResult(context.WithoutCancel(ctx), s.store, job, exec)
+	}
+}
+
+// findJobQuestionSession returns the job-run session whose question was
+// sent to the chat, and for Slack the thread, a reply arrived in.
+func (s *Server) findJobQuestionSession(integrationID string, chatID string, threadTS string) (*session.Session, error) {
+	waiting, err := s.store.ListWaitingJobExecutions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list waiting job executions: %w", err)
+	}
+	for _, exec := range waiting {
+		sess, err := s.sessionManager.Get(exec.SessionID)
+		if err != nil || sess.Status != session.StatusInputRequired ||
+			metadataString(sess.Metadata[jobs.QuestionIntegrationMetadataKey]) != integrationID ||
+			metadataString(sess.Metadata[jobs.QuestionChatMetadataKey]) != chatID ||
+			metadataString(sess.Metadata[jobs.QuestionThreadMetadataKey]) != threadTS {
+			continue
+		}
+		return sess, nil
+	}
+	return nil, nil
+}
+
+// answerJobQuestion answers the question a job run waits on with a chat reply
+// and resumes the run in the background. It returns what to tell the chat.
+func (s *Server) answerJobQuestion(sess *session.Session, reply string, via string) string {
+	question := sessionPendingQuestion(sess)
+	if question == nil {
+		return "This job is no longer waiting for an answer."
+	}
+	answer, err := question.ResolveAnswer(jobs.ReplyAnswers(question, reply))
+	if err != nil {
+		return "Invalid answer: " + err.Error() + ". Reply with one of the listed options."
+	}
+	if err := s.sessionManager.AnswerQuestion(sess.ID, answer); err != nil {
+		return "Could not record the answer: " + err.Error()
+	}
+	logging.LogSession("answered", sess.ID, "via "+via)
+
+	go s.resumeSessionAfterAnswer(sess.ID, answer)
+	return fmt.Sprintf("Answer recorded (%s). The job is running again.", answer)
+}
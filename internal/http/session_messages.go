@@ -0,0 +1,87 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	// defaultSessionMessageLimit is how many of the latest messages GET
+	// /sessions/{id} returns when message_limit is not set.
+	defaultSessionMessageLimit = 100
+	// toolResultPreviewChars bounds tool result content returned with
+	// include_tool_results=false.
+	toolResultPreviewChars = 500
+)
+
+// parseMessagePage reads message_offset and message_limit for a session of
+// total messages and returns the [start, end) range to return. Without an
+// offset the page holds the latest messages; message_limit=0 returns all of
+// them from the offset on.
+func parseMessagePage(q url.Values, total int) (int, int, error) {
+	limit := defaultSessionMessageLimit
+	if raw := q.Get("message_limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("message_limit must be a non-negative integer")
+		}
+		limit = n
+	}
+
+	start := 0
+	if raw := q.Get("message_offset"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return 0, 0, errors.New("message_offset must be a non-negative integer")
+		}
+		start = min(n, total)
+	} else if limit > 0 {
+		start = max(total-limit, 0)
+	}
+
+	end := total
+	if limit > 0 {
+		end = min(start+limit, total)
+	}
+	return start, end, nil
+}
+
+// previewToolResults replaces tool result content longer than
+// toolResultPreviewChars with its beginning, recording the full size.
+func previewToolResults(messages []MessageResponse) {
+	for i := range messages {
+		for j := range messages[i].ToolResults {
+			tr := &messages[i].ToolResults[j]
+			if len(tr.Content) <= toolResultPreviewChars {
+				continue
+			}
+			tr.ContentBytes = len(tr.Content)
+			tr.Content = truncateRunes(tr.Content, toolResultPreviewChars)
+			tr.Truncated = true
+		}
+	}
+}
+
+// handleGetSessionMessage returns one message of a session in full, by its
+// index, for clients that loaded a page with include_tool_results=false.
+func (s *Server) handleGetSessionMessage(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	sess, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+
+	index, err := strconv.Atoi(chi.URLParam(r, "index"))
+	if err != nil || index < 0 || index >= len(sess.Messages) {
+		s.errorResponse(w, http.StatusNotFound, "Message not found: "+chi.URLParam(r, "index"))
+		return
+	}
+
+	s.jsonResponse(w, http.StatusOK, s.messagesToResponse(sess.Messages[index : index+1])[0])
+}
@@ -0,0 +1,104 @@
+package http
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+)
+
+func TestGetSessionPagesMessages(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	longOutput := strings.Repeat("line of output\n", 100)
+	for i := 0; i < 150; i++ {
+		sess.Messages = append(sess.Messages, session.Message{ID: fmt.Sprintf("msg-%d", i), Role: "user", Content: fmt.Sprintf("message %d", i)})
+	}
+	startedAt := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	sess.Messages[149] = session.Message{ID: "msg-149", Role: "tool", ToolResults: []session.ToolResult{
+		{ToolCallID: "call-1", Content: longOutput, StartedAt: startedAt, DurationMs: 1500, OutputBytes: 2 * len(longOutput)},
+	}}
+	if err := sessionManager.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	get := func(query string) SessionResponse {
+		t.Helper()
+		rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+query, "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status %d body=%s", query, rec.Code, rec.Body.String())
+		}
+		var resp SessionResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp
+	}
+
+	resp := get("")
+	if resp.MessageCount != 150 || resp.MessageOffset != 50 || len(resp.Messages) != 100 || resp.Messages[0].Content != "message 50" {
+		t.Fatalf("expected the latest 100 messages, got count=%d offset=%d len=%d", resp.MessageCount, resp.MessageOffset, len(resp.Messages))
+	}
+	if resp.Messages[99].ToolResults[0].Content != longOutput {
+		t.Fatal("tool results are returned in full by default")
+	}
+	if got := resp.Messages[99].ToolResults[0]; !got.StartedAt.Equal(startedAt) || got.DurationMs != 1500 || got.OutputBytes != 2*len(longOutput) {
+		t.Fatalf("tool result timing was not kept: %+v", got)
+	}
+
+	resp = get("?message_offset=10&message_limit=5")
+	if resp.MessageOffset != 10 || len(resp.Messages) != 5 || resp.Messages[0].Content != "message 10" {
+		t.Fatalf("unexpected page: offset=%d len=%d", resp.MessageOffset, len(resp.Messages))
+	}
+	if resp = get("?message_limit=0"); len(resp.Messages) != 150 {
+		t.Fatalf("message_limit=0 should return every message, got %d", len(resp.Messages))
+	}
+
+	resp = get("?include_tool_results=false")
+	result := resp.Messages[99].ToolResults[0]
+	if !result.Truncated || result.ContentBytes != len(longOutput) || len(result.Content) > toolResultPreviewChars {
+		t.Fatalf("expected a preview of the tool result, got %+v", result)
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/messages/149", "")
+	var full MessageResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &full); err != nil || full.ToolResults[0].Content != longOutput {
+		t.Fatalf("expected the full message, got status %d: %v", rec.Code, err)
+	}
+	if rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/messages/150", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("out of range message: status %d", rec.Code)
+	}
+	if rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"?message_limit=-1", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("negative limit: status %d", rec.Code)
+	}
+}
+
+func TestJSONResponsesAreGzipped(t *testing.T) {
+	server, _ := newQuestionTestServer(t)
+	req := httptest.NewRequest(http.MethodGet, "/sessions", nil)
+	req.Header.Set("Authorization", "Bearer test-token")
+	req.Header.Set("Accept-Encoding", "gzip")
+	rec := httptest.NewRecorder()
+	server.router.ServeHTTP(rec, req)
+	if rec.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected a gzip response, got headers %v", rec.Header())
+	}
+	zr, err := gzip.NewReader(rec.Body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	body, err := io.ReadAll(zr)
+	if err != nil || !json.Valid(body) {
+		t.Fatalf("expected JSON inside the gzip stream, got %q, %v", body, err)
+	}
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSessionProgressEndpoint(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	checklist := "- [x] Read the spec\n- [ ] Write the code\n- [x] Add tests\n- [ ] Ship it"
+	if err := sessionManager.SetSessionTaskProgress(sess.ID, checklist); err != nil {
+		t.Fatalf("SetSessionTaskProgress: %v", err)
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/progress", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("progress: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var progress SessionProgressResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &progress); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if progress.Content != checklist || progress.Total != 4 || progress.Completed != 2 || progress.ProgressPct != 50 {
+		t.Fatalf("unexpected progress: %+v", progress)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("list: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var list []SessionListItem
+	if err := json.Unmarshal(rec.Body.Bytes(), &list); err != nil {
+		t.Fatalf("decode list: %v body=%s", err, rec.Body.String())
+	}
+	if len(list) != 1 || list[0].ProgressPct == nil || *list[0].ProgressPct != 50 {
+		t.Fatalf("unexpected list progress: %+v", list)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions/missing/progress", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing session: status %d", rec.Code)
+	}
+}
+
+func TestSessionExportEndpoint(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	sess.Title = "Release notes"
+	sess.AddUserMessage("Draft the release notes")
+	sess.AddAssistantMessage("Here they are.", nil)
+	if err := sessionManager.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rec := serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/export", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("export: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "# Release notes") || !strings.Contains(body, "## User\n\nDraft the release notes") {
+		t.Fatalf("unexpected export:\n%s", body)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions/missing/export", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("missing session: status %d", rec.Code)
+	}
+}
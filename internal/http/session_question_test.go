@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/speechcache"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tools"
+)
+
+func newQuestionTestServer(t *testing.T) (*Server, *session.Manager) {
+	t.Helper()
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	cfg := &config.Config{DataPath: t.TempDir(), Server: config.ServerConfig{APITokens: []config.APIToken{{Name: "test", Token: "test-token"}}}}
+	sessionManager := session.NewManager(store)
+	server := NewServer(cfg, nil, tools.NewManager("."), sessionManager, store, speechcache.New(0), 0)
+	return server, sessionManager
+}
+
+func serveAuthorized(s *Server, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer test-token")
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPendingQuestionEndpoints(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	rec := serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/answer", `{"answer":"Yes"}`)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("answer without pending question: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	question := &session.QuestionData{
+		Question: "Deploy now?",
+		Options:  []session.QuestionOption{{Label: "Yes"}, {Label: "No"}},
+	}
+	if err := sessionManager.SetPendingQuestion(sess.ID, question); err != nil {
+		t.Fatalf("SetPendingQuestion: %v", err)
+	}
+	if err := sessionManager.SetSessionStatus(sess.ID, string(session.StatusInputRequired)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID+"/question", "")
+	var got struct {
+		Question *session.QuestionData `json:"question"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.Question == nil || got.Question.Question != "Deploy now?" {
+		t.Fatalf("GET question: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAuthorized(server, http.MethodGet, "/sessions/"+sess.ID, "")
+	var detail SessionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &detail); err != nil || detail.PendingQuestion == nil {
+		t.Fatalf("session response should include pending question: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/answer", `{"answer":"Later"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("answer outside options: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	rec = serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/answer", `{"answers":["Yes","No"]}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("multiple answers for single question: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	if still, _ := sessionManager.GetPendingQuestion(sess.ID); still == nil {
+		t.Fatal("rejected answers must leave the question pending")
+	}
+}
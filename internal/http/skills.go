@@ -60,6 +60,10 @@ type BuiltInSkill struct {
 	Kind        string `json:"kind"`
 	Description string `json:"description"`
 	Enabled     bool   `json:"enabled"`
+
+	// Arguments are set for kind "mcp_prompt"; pass the ID and argument
+	// values as mcp_prompt when creating a session to seed it.
+	Arguments []MCPPromptArgument `json:"arguments,omitempty"`
 }
 
 type BuiltInSkillResponse struct {
@@ -110,6 +114,16 @@ func (s *Server) handleListBuiltInSkills(w http.ResponseWriter, r *http.Request)
 			Enabled:     !isToolDisabled(definition.Name, disabledTools),
 		})
 	}
+	for _, prompt := range s.mcpTools.listPrompts(r.Context()) {
+		skills = append(skills, BuiltInSkill{
+			ID:          mcpPromptSkillID(prompt.ServerID, prompt.Name),
+			Name:        prompt.Server + "/" + prompt.Name,
+			Kind:        "mcp_prompt",
+			Description: prompt.Description,
+			Enabled:     true,
+			Arguments:   prompt.Arguments,
+		})
+	}
 	logging.Debug("handleListBuiltInSkills: Returning %d built-in skills", len(skills))
 
 	s.jsonResponse(w, http.StatusOK, BuiltInSkillResponse{Skills: skills})
@@ -612,3 +626,138 @@ func (s *Server) handleDeleteSkill(w http.ResponseWriter, r *http.Request) {
 		"path":    skillDir,
 	})
 }
+
+// SkillSelectionRequest chooses the active markdown skills. Scope "global"
+// (the default) enables exactly the listed skills for every session; scope
+// "session" overrides the selection for one session. A null skills list
+// restores the default: all skills globally, or the global selection for a
+// session.
+type SkillSelectionRequest struct {
+	Scope     string    `json:"scope,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	Skills    *[]string `json:"skills"`
+}
+
+type SkillSelectionResponse struct {
+	Scope     string      `json:"scope"`
+	SessionID string      `json:"session_id,omitempty"`
+	Active    []SkillFile `json:"active"`
+}
+
+// handleSelectSkills persists which skills are loaded into the system prompt.
+func (s *Server) handleSelectSkills(w http.ResponseWriter, r *http.Request) {
+	var req SkillSelectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	scope := strings.ToLower(strings.TrimSpace(req.Scope))
+	if scope == "" {
+		scope = "global"
+	}
+	if scope != "global" && scope != "session" {
+		s.errorResponse(w, http.StatusBadRequest, "scope must be global or session")
+		return
+	}
+
+	settings, err := s.store.GetSettings()
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to get settings")
+		return
+	}
+	resolvedFolder, err := skills.ResolveFolder(settings[skillsFolderSettingKey])
+	if err != nil {
+		s.errorResponse(w, http.StatusBadRequest, capitalizeSkillsError(err))
+		return
+	}
+	all, err := skills.LoadSkillsFromDirectory(resolvedFolder, skills.DefaultConfig())
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to load skills: "+err.Error())
+		return
+	}
+
+	var selected []string
+	if req.Skills != nil {
+		selected = make([]string, 0, len(*req.Skills))
+		for _, ref := range *req.Skills {
+			skill, err := skills.Find(resolvedFolder, ref)
+			if err != nil {
+				s.errorResponse(w, http.StatusBadRequest, "Unknown skill: "+strings.TrimSpace(ref))
+				return
+			}
+			selected = append(selected, skill.RelativePath)
+		}
+	}
+
+	resp := SkillSelectionResponse{Scope: scope}
+	var sessionSelected []string
+	switch scope {
+	case "global":
+		next := make(map[string]string, len(settings)+1)
+		for key, value := range settings {
+			next[key] = value
+		}
+		delete(next, externalMarkdownDisabledSkillsSettingKey)
+		if selected != nil {
+			keep := make(map[string]struct{}, len(selected))
+			for _, rel := range selected {
+				keep[rel] = struct{}{}
+			}
+			disabled := make([]string, 0, len(all))
+			for _, skill := range all {
+				if _, ok := keep[skill.RelativePath]; !ok {
+					disabled = append(disabled, skill.RelativePath)
+				}
+			}
+			if len(disabled) > 0 {
+				encoded, _ := json.Marshal(disabled)
+				next[externalMarkdownDisabledSkillsSettingKey] = string(encoded)
+			}
+		}
+		if err := s.store.SaveSettings(next); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to save settings: "+err.Error())
+			return
+		}
+		syncSettingsToEnv(settings, next)
+		settings = next
+	case "session":
+		sess, err := s.sessionManager.Get(strings.TrimSpace(req.SessionID))
+		if err != nil {
+			s.errorResponse(w, http.StatusNotFound, "Session not found")
+			return
+		}
+		if sess.Metadata == nil {
+			sess.Metadata = make(map[string]interface{})
+		}
+		if selected != nil {
+			sess.Metadata[skills.SessionSelectionMetadataKey] = selected
+		} else {
+			delete(sess.Metadata, skills.SessionSelectionMetadataKey)
+		}
+		// Recompose the system prompt on the next run with the new selection.
+		delete(sess.Metadata, sessionSystemPromptSnapshotMetadataKey)
+		if err := s.sessionManager.Save(sess); err != nil {
+			s.errorResponse(w, http.StatusInternalServerError, "Failed to save session: "+err.Error())
+			return
+		}
+		resp.SessionID = sess.ID
+		sessionSelected = selected
+	}
+
+	disabled := resolveDisabledExternalMarkdownSkillPaths(settings, resolvedFolder)
+	active, _, err := skills.LoadActive(resolvedFolder, disabled, sessionSelected)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to load skills: "+err.Error())
+		return
+	}
+	resp.Active = make([]SkillFile, 0, len(active))
+	for _, skill := range active {
+		resp.Active = append(resp.Active, SkillFile{
+			Name:         skill.Name,
+			Description:  skill.Description,
+			Path:         skill.Path,
+			RelativePath: skill.RelativePath,
+		})
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	skillsLoader "github.com/A2gent/brute/internal/skills"
+)
+
+func writeTestSkill(t *testing.T, folder, name, description string) {
+	t.Helper()
+	dir := filepath.Join(folder, name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("Failed to create skill dir: %v", err)
+	}
+	content := "---\nname: " + name + "\ndescription: " + description + "\n---\n# " + name + "\n\nSteps for " + name + ".\n"
+	if err := os.WriteFile(filepath.Join(dir, "SKILL.md"), []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write skill: %v", err)
+	}
+}
+
+func postSkillSelection(t *testing.T, server *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/skills/selection", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.handleSelectSkills(rec, req)
+	return rec
+}
+
+func decodeSkillSelection(t *testing.T, rec *httptest.ResponseRecorder) []string {
+	t.Helper()
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp SkillSelectionResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	names := make([]string, 0, len(resp.Active))
+	for _, skill := range resp.Active {
+		names = append(names, skill.Name)
+	}
+	return names
+}
+
+func TestHandleSelectSkills(t *testing.T) {
+	server := newA2AJSONRPCTestServer(t)
+	folder := t.TempDir()
+	writeTestSkill(t, folder, "deploy", "Ship a release")
+	writeTestSkill(t, folder, "review", "Review a pull request")
+	if err := server.store.SaveSettings(map[string]string{skillsFolderSettingKey: folder}); err != nil {
+		t.Fatalf("Failed to save settings: %v", err)
+	}
+	t.Cleanup(func() { os.Unsetenv(externalMarkdownDisabledSkillsSettingKey) })
+
+	if names := decodeSkillSelection(t, postSkillSelection(t, server, `{"skills":["deploy"]}`)); len(names) != 1 || names[0] != "deploy" {
+		t.Fatalf("Expected only deploy to be active globally, got %v", names)
+	}
+	settings, _ := server.store.GetSettings()
+	if !strings.Contains(settings[externalMarkdownDisabledSkillsSettingKey], "review/SKILL.md") {
+		t.Fatalf("Expected review to be disabled in settings, got %q", settings[externalMarkdownDisabledSkillsSettingKey])
+	}
+
+	sess, err := server.sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.Metadata = map[string]interface{}{sessionSystemPromptSnapshotMetadataKey: map[string]interface{}{"combined_prompt": "stale"}}
+	if err := server.sessionManager.Save(sess); err != nil {
+		t.Fatalf("Failed to save session: %v", err)
+	}
+	body := `{"scope":"session","session_id":"` + sess.ID + `","skills":["review/SKILL.md"]}`
+	if names := decodeSkillSelection(t, postSkillSelection(t, server, body)); len(names) != 1 || names[0] != "review" {
+		t.Fatalf("Expected the session selection to override the global one, got %v", names)
+	}
+	stored, err := server.sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Failed to reload session: %v", err)
+	}
+	if _, ok := stored.Metadata[sessionSystemPromptSnapshotMetadataKey]; ok {
+		t.Fatalf("Expected the system prompt snapshot to be cleared")
+	}
+	section, _, _ := server.resolveExternalMarkdownSkillsSection(stored, settings, 1)
+	if !strings.Contains(section, "review") || strings.Contains(section, "deploy") {
+		t.Fatalf("Expected the session prompt to list only review, got %q", section)
+	}
+	if selected := skillsLoader.SessionSelection(stored.Metadata); len(selected) != 1 || selected[0] != "review/SKILL.md" {
+		t.Fatalf("Expected the selection to be stored on the session, got %v", selected)
+	}
+
+	if rec := postSkillSelection(t, server, `{"skills":["missing"]}`); rec.Code != http.StatusBadRequest {
+		t.Fatalf("Expected 400 for an unknown skill, got %d", rec.Code)
+	}
+
+	if names := decodeSkillSelection(t, postSkillSelection(t, server, `{"skills":null}`)); len(names) != 2 {
+		t.Fatalf("Expected a null global selection to enable every skill, got %v", names)
+	}
+}
@@ -1,14 +1,12 @@
 package http
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"sort"
@@ -17,11 +15,13 @@ import (
 	"time"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/speech"
 	"github.com/A2gent/brute/internal/stt/whispercpp"
 )
 
 const (
-	elevenLabsVoicesURL      = "https://api.elevenlabs.io/v1/voices"
 	maxTranscribeAudioBytes  = 25 * 1024 * 1024
 	defaultTranscribeTimeout = 20 * time.Minute
 )
@@ -42,33 +42,17 @@ var recommendedPiperVoices = []string{
 }
 
 type speechCompletionRequest struct {
-	Text string `json:"text"`
+	Text     string  `json:"text"`
+	Provider string  `json:"provider,omitempty"`
+	VoiceID  string  `json:"voice_id,omitempty"`
+	ModelID  string  `json:"model_id,omitempty"`
+	Speed    float64 `json:"speed,omitempty"`
 }
 
 type speechTranscribeResponse struct {
 	Text string `json:"text"`
 }
 
-type elevenLabsVoice struct {
-	VoiceID    string `json:"voice_id"`
-	Name       string `json:"name"`
-	PreviewURL string `json:"preview_url,omitempty"`
-}
-
-type elevenLabsVoicesResponse struct {
-	Voices []elevenLabsVoice `json:"voices"`
-}
-
-type elevenLabsTTSRequest struct {
-	Text          string                  `json:"text"`
-	ModelID       string                  `json:"model_id"`
-	VoiceSettings elevenLabsVoiceSettings `json:"voice_settings,omitempty"`
-}
-
-type elevenLabsVoiceSettings struct {
-	Speed float64 `json:"speed,omitempty"`
-}
-
 type piperVoiceOption struct {
 	ID        string `json:"id"`
 	Installed bool   `json:"installed"`
@@ -76,40 +60,29 @@ type piperVoiceOption struct {
 }
 
 func (s *Server) handleListSpeechVoices(w http.ResponseWriter, r *http.Request) {
-	apiKey := s.resolveElevenLabsAPIKey()
-	if apiKey == "" {
-		s.errorResponse(w, http.StatusBadRequest, "ElevenLabs API key is not configured. Add an enabled ElevenLabs integration in Integrations.")
-		return
-	}
-
-	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, elevenLabsVoicesURL, nil)
-	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to build ElevenLabs request: "+err.Error())
+	providers := s.speechProviders()
+	if len(providers) == 0 {
+		s.errorResponse(w, http.StatusBadRequest, "No TTS provider is configured. Add an enabled ElevenLabs integration in Integrations or set OPENAI_TTS_API_KEY.")
 		return
 	}
-	req.Header.Set("xi-api-key", apiKey)
-	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		s.errorResponse(w, http.StatusBadGateway, "Failed to fetch ElevenLabs voices: "+err.Error())
-		return
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		s.proxyElevenLabsError(w, resp, "Failed to fetch ElevenLabs voices")
-		return
+	voices := make([]speech.Voice, 0)
+	var lastErr error
+	for _, provider := range providers {
+		providerVoices, err := provider.ListVoices(r.Context())
+		if err != nil {
+			logging.Warn("Failed to list %s voices: %v", provider.Name(), err)
+			lastErr = err
+			continue
+		}
+		voices = append(voices, providerVoices...)
 	}
-
-	var payload elevenLabsVoicesResponse
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		s.errorResponse(w, http.StatusBadGateway, "Failed to decode ElevenLabs voices response: "+err.Error())
+	if len(voices) == 0 && lastErr != nil {
+		s.speechProviderError(w, lastErr, "Failed to fetch voices")
 		return
 	}
 
-	s.jsonResponse(w, http.StatusOK, payload.Voices)
+	s.jsonResponse(w, http.StatusOK, voices)
 }
 
 func (s *Server) handleListPiperVoices(w http.ResponseWriter, r *http.Request) {
@@ -174,17 +147,6 @@ func (s *Server) handleListPiperVoices(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleCompletionSpeech(w http.ResponseWriter, r *http.Request) {
-	apiKey := s.resolveElevenLabsAPIKey()
-	voiceID := strings.TrimSpace(os.Getenv("ELEVENLABS_VOICE_ID"))
-	if apiKey == "" {
-		s.errorResponse(w, http.StatusBadRequest, "ElevenLabs API key is not configured. Add an enabled ElevenLabs integration in Integrations.")
-		return
-	}
-	if voiceID == "" {
-		s.errorResponse(w, http.StatusBadRequest, "ELEVENLABS_VOICE_ID is not configured")
-		return
-	}
-
 	var reqBody speechCompletionRequest
 	if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
 		s.errorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
@@ -196,53 +158,32 @@ func (s *Server) handleCompletionSpeech(w http.ResponseWriter, r *http.Request)
 		s.errorResponse(w, http.StatusBadRequest, "text is required")
 		return
 	}
-
-	ttsReq := elevenLabsTTSRequest{
-		Text:    text,
-		ModelID: "eleven_multilingual_v2",
-	}
-	if speedRaw := strings.TrimSpace(os.Getenv("ELEVENLABS_SPEED")); speedRaw != "" {
-		if speed, err := strconv.ParseFloat(speedRaw, 64); err == nil && speed > 0 {
-			ttsReq.VoiceSettings = elevenLabsVoiceSettings{Speed: speed}
-		}
-	}
-
-	jsonBody, err := json.Marshal(ttsReq)
-	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to build ElevenLabs request payload: "+err.Error())
+	if reqBody.Speed < 0 {
+		s.errorResponse(w, http.StatusBadRequest, "speed must be > 0")
 		return
 	}
 
-	ttsURL := fmt.Sprintf("https://api.elevenlabs.io/v1/text-to-speech/%s", url.PathEscape(voiceID))
-	req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, ttsURL, bytes.NewReader(jsonBody))
+	provider, err := s.resolveSpeechProvider(reqBody.Provider)
 	if err != nil {
-		s.errorResponse(w, http.StatusInternalServerError, "Failed to build ElevenLabs request: "+err.Error())
+		s.errorResponse(w, http.StatusBadRequest, err.Error())
 		return
 	}
-	req.Header.Set("xi-api-key", apiKey)
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Accept", "audio/mpeg")
 
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
+	audio, err := provider.Synthesize(r.Context(), speech.Request{
+		Text:    text,
+		VoiceID: reqBody.VoiceID,
+		ModelID: reqBody.ModelID,
+		Speed:   reqBody.Speed,
+	})
 	if err != nil {
-		s.errorResponse(w, http.StatusBadGateway, "Failed to call ElevenLabs: "+err.Error())
+		s.speechProviderError(w, err, "Speech playback failed")
 		return
 	}
-	defer resp.Body.Close()
+	defer audio.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		s.proxyElevenLabsError(w, resp, "ElevenLabs playback failed")
-		return
-	}
-
-	contentType := strings.TrimSpace(resp.Header.Get("Content-Type"))
-	if contentType == "" {
-		contentType = "audio/mpeg"
-	}
-	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Type", audio.ContentType)
 	w.WriteHeader(http.StatusOK)
-	if _, err := io.Copy(w, resp.Body); err != nil {
+	if _, err := io.Copy(w, audio.Body); err != nil {
 		// Client may disconnect mid-stream; nothing actionable for handler.
 		return
 	}
@@ -259,17 +200,18 @@ func (s *Server) handleGetSpeechClip(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	contentType, payload, ok := s.speechClips.Load(clipID)
+	contentType, payload, ok := s.speechClips.Open(clipID)
 	if !ok {
 		s.errorResponse(w, http.StatusNotFound, "Speech clip not found or expired")
 		return
 	}
+	defer payload.Close()
 	if contentType == "" {
 		contentType = "audio/mpeg"
 	}
 	w.Header().Set("Content-Type", contentType)
 	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write(payload); err != nil {
+	if _, err := io.Copy(w, payload); err != nil {
 		return
 	}
 }
@@ -378,13 +320,70 @@ func (s *Server) resolveElevenLabsAPIKey() string {
 	return strings.TrimSpace(os.Getenv("ELEVENLABS_API_KEY"))
 }
 
-func (s *Server) proxyElevenLabsError(w http.ResponseWriter, upstream *http.Response, fallback string) {
-	body, _ := io.ReadAll(io.LimitReader(upstream.Body, 8192))
-	statusDetail := strings.TrimSpace(string(body))
-	if statusDetail == "" {
-		statusDetail = upstream.Status
+// speechProviders returns the configured TTS providers, ElevenLabs first so
+// existing setups keep their default.
+func (s *Server) speechProviders() []speech.Provider {
+	providers := make([]speech.Provider, 0, 2)
+	if apiKey := s.resolveElevenLabsAPIKey(); apiKey != "" {
+		speed := 0.0
+		if speedRaw := strings.TrimSpace(os.Getenv("ELEVENLABS_SPEED")); speedRaw != "" {
+			if parsed, err := strconv.ParseFloat(speedRaw, 64); err == nil && parsed > 0 {
+				speed = parsed
+			}
+		}
+		providers = append(providers, speech.NewElevenLabs(apiKey, os.Getenv("ELEVENLABS_VOICE_ID"), speed))
+	}
+	if apiKey, baseURL := s.resolveOpenAITTSCredentials(); apiKey != "" || baseURL != "" {
+		providers = append(providers, speech.NewOpenAI(apiKey, baseURL, os.Getenv("OPENAI_TTS_VOICE"), os.Getenv("OPENAI_TTS_MODEL")))
+	}
+	return providers
+}
+
+func (s *Server) resolveSpeechProvider(name string) (speech.Provider, error) {
+	providers := s.speechProviders()
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		if len(providers) == 0 {
+			return nil, fmt.Errorf("No TTS provider is configured. Add an enabled ElevenLabs integration in Integrations or set OPENAI_TTS_API_KEY.")
+		}
+		return providers[0], nil
+	}
+	for _, provider := range providers {
+		if provider.Name() == name {
+			return provider, nil
+		}
+	}
+	return nil, fmt.Errorf("TTS provider %q is not configured", name)
+}
+
+// resolveOpenAITTSCredentials prefers dedicated OPENAI_TTS_* settings and falls
+// back to the OpenAI LLM provider key. A base URL alone enables keyless
+// OpenAI-compatible servers.
+func (s *Server) resolveOpenAITTSCredentials() (string, string) {
+	apiKey := strings.TrimSpace(os.Getenv("OPENAI_TTS_API_KEY"))
+	baseURL := strings.TrimSpace(os.Getenv("OPENAI_TTS_BASE_URL"))
+	if apiKey == "" && baseURL == "" {
+		if s.config != nil {
+			apiKey = strings.TrimSpace(s.config.Providers[string(config.ProviderOpenAI)].APIKey)
+		}
+		if apiKey == "" {
+			apiKey = strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
+		}
+	}
+	return apiKey, baseURL
+}
+
+func (s *Server) speechProviderError(w http.ResponseWriter, err error, fallback string) {
+	var apiErr *speech.APIError
+	if errors.As(err, &apiErr) {
+		detail := apiErr.Body
+		if detail == "" {
+			detail = apiErr.Status
+		}
+		s.errorResponse(w, apiErr.StatusCode, fmt.Sprintf("%s: %s", fallback, detail))
+		return
 	}
-	s.errorResponse(w, upstream.StatusCode, fmt.Sprintf("%s: %s", fallback, statusDetail))
+	s.errorResponse(w, http.StatusBadGateway, fmt.Sprintf("%s: %s", fallback, err.Error()))
 }
 
 func resolveAAgentDataDirForHTTP() string {
@@ -8,6 +8,8 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/A2gent/brute/internal/config"
 )
 
 func TestSpeechTranscribeEndpointReturnsStructuredErrorWhenSTTUnavailable(t *testing.T) {
@@ -30,11 +32,12 @@ func TestSpeechTranscribeEndpointReturnsStructuredErrorWhenSTTUnavailable(t *tes
 		t.Fatalf("closing multipart writer failed: %v", err)
 	}
 
-	s := &Server{}
+	s := &Server{config: &config.Config{Server: config.ServerConfig{APITokens: []config.APIToken{{Name: "test", Token: "test-token"}}}}}
 	s.setupRoutes()
 
 	req := httptest.NewRequest(http.MethodPost, "/speech/transcribe", &body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer test-token")
 	rec := httptest.NewRecorder()
 
 	s.router.ServeHTTP(rec, req)
@@ -161,6 +161,8 @@ func (t *delegateToSubAgentTool) Execute(ctx context.Context, params json.RawMes
 		MaxSteps:      30, // Sub-agents get fewer steps
 		Temperature:   t.server.config.Temperature,
 		ContextWindow: target.ContextWindow,
+		MaxTokens:     t.server.config.MaxTokens,
+		StopSequences: t.server.config.StopSequences,
 	}
 
 	ag := agent.New(agentConfig, target.Client, toolMgr, t.server.sessionManager)
@@ -232,6 +234,9 @@ func (s *Server) buildSubAgentToolManager(sess *session.Session, enabledTools []
 		manager = s.toolManager.Clone()
 	} else {
 		manager = tools.NewManager(workDir)
+		manager.SetTimeoutPolicy(s.toolManager.TimeoutPolicy())
+		manager.SetApprovalPolicy(s.toolManager.ApprovalPolicy())
+		manager.SetRedactionPolicy(s.toolManager.RedactionPolicy())
 		s.registerServerBackedTools(manager)
 	}
 
@@ -242,8 +247,9 @@ func (s *Server) buildSubAgentToolManager(sess *session.Session, enabledTools []
 			allowed[strings.TrimSpace(name)] = struct{}{}
 		}
 		// Also always allow the question tool and task progress
-		allowed["question"] = struct{}{}
+		allowed[tools.QuestionToolName] = struct{}{}
 		allowed["session_task_progress"] = struct{}{}
+		allowed[tools.ToolOutputToolName] = struct{}{}
 
 		for _, def := range manager.GetDefinitions() {
 			if _, ok := allowed[def.Name]; !ok {
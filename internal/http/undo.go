@@ -0,0 +1,69 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+
+	"github.com/A2gent/brute/internal/tools"
+	"github.com/go-chi/chi/v5"
+)
+
+// UndoRequest optionally names the file whose latest change to revert;
+// relative paths are resolved against the session's work directory.
+type UndoRequest struct {
+	Path string `json:"path,omitempty"`
+}
+
+// UndoResponse describes the file change an undo reverted.
+type UndoResponse struct {
+	SessionID string       `json:"session_id"`
+	Reverted  tools.Backup `json:"reverted"`
+	Message   string       `json:"message"`
+}
+
+// handleUndoSessionEdit reverts the latest file change the tools of a
+// session made, like the undo_edit tool.
+func (s *Server) handleUndoSessionEdit(w http.ResponseWriter, r *http.Request) {
+	sessionID := chi.URLParam(r, "sessionID")
+
+	sess, err := s.sessionManager.Get(sessionID)
+	if err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Session not found: "+err.Error())
+		return
+	}
+	if s.backups == nil {
+		s.errorResponse(w, http.StatusServiceUnavailable, "File backups are not enabled")
+		return
+	}
+
+	var req UndoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		s.errorResponse(w, http.StatusBadRequest, "Invalid request body: "+err.Error())
+		return
+	}
+
+	workDir := s.resolveSessionWorkDir(sess)
+	path := req.Path
+	if path != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+
+	backup, err := s.backups.Undo(sessionID, path)
+	if errors.Is(err, tools.ErrNoBackup) {
+		s.errorResponse(w, http.StatusNotFound, "No backed up change to revert")
+		return
+	}
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to undo: "+err.Error())
+		return
+	}
+
+	s.jsonResponse(w, http.StatusOK, UndoResponse{
+		SessionID: sessionID,
+		Reverted:  *backup,
+		Message:   tools.DescribeUndo(backup, workDir),
+	})
+}
@@ -0,0 +1,59 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestUndoEndpointRevertsLatestChange(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	backups := server.backups
+	server.backups = nil
+	rec := serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/undo", "")
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("undo without backups: status %d body=%s", rec.Code, rec.Body.String())
+	}
+
+	workDir := t.TempDir()
+	server.config.WorkDir = workDir
+	server.backups = backups
+	path := filepath.Join(workDir, "main.go")
+	if err := os.WriteFile(path, []byte("before"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := server.backups.Save(sess.ID, path, "edit", "call-1"); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("after"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/undo", `{"path":"main.go"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("undo: status %d body=%s", rec.Code, rec.Body.String())
+	}
+	var resp UndoResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if resp.Reverted.ToolCallID != "call-1" || !strings.Contains(resp.Message, "restored main.go") {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "before" {
+		t.Fatalf("main.go = %q after undo", data)
+	}
+
+	rec = serveAuthorized(server, http.MethodPost, "/sessions/"+sess.ID+"/undo", "")
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("undo with nothing left: status %d body=%s", rec.Code, rec.Body.String())
+	}
+}
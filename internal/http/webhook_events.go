@@ -0,0 +1,94 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/A2gent/brute/internal/notify"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/go-chi/chi/v5"
+)
+
+// sessionStatusEvents maps the session statuses webhooks hear about to
+// their event types.
+var sessionStatusEvents = map[session.Status]string{
+	session.StatusCompleted:     notify.EventSessionCompleted,
+	session.StatusFailed:        notify.EventSessionFailed,
+	session.StatusInputRequired: notify.EventSessionInputRequired,
+}
+
+// WebhookDeliveryResponse is one entry of an integration's delivery log.
+type WebhookDeliveryResponse struct {
+	ID           string    `json:"id"`
+	Event        string    `json:"event"`
+	Status       string    `json:"status"`
+	Attempts     int       `json:"attempts"`
+	ResponseCode int       `json:"response_code,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	Payload      string    `json:"payload"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// publishSessionStatus is the session manager's status hook: it sends
+// webhook events for sessions that finished, failed or wait for input.
+func (s *Server) publishSessionStatus(sess *session.Session, previous session.Status) {
+	eventType, ok := sessionStatusEvents[sess.Status]
+	if !ok {
+		return
+	}
+	inputTokens, outputTokens := sessionInputOutputTokens(sess)
+	fields := map[string]interface{}{
+		"session_id":      sess.ID,
+		"agent_id":        sess.AgentID,
+		"status":          string(sess.Status),
+		"previous_status": string(previous),
+		"title":           sess.Title,
+		"usage": map[string]int{
+			"input_tokens":  inputTokens,
+			"output_tokens": outputTokens,
+			"total_tokens":  inputTokens + outputTokens,
+		},
+	}
+	if sess.JobID != nil {
+		fields["job_id"] = *sess.JobID
+	}
+	event := notify.Event{Type: eventType, Time: sess.UpdatedAt, Fields: fields}
+	go notify.DeliverEvent(context.Background(), s.store, event)
+}
+
+func (s *Server) handleListIntegrationDeliveries(w http.ResponseWriter, r *http.Request) {
+	integrationID := chi.URLParam(r, "integrationID")
+	if _, err := s.store.GetIntegration(integrationID); err != nil {
+		s.errorResponse(w, http.StatusNotFound, "Integration not found: "+err.Error())
+		return
+	}
+
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	deliveries, err := s.store.ListWebhookDeliveries(integrationID, limit)
+	if err != nil {
+		s.errorResponse(w, http.StatusInternalServerError, "Failed to list deliveries: "+err.Error())
+		return
+	}
+	resp := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, d := range deliveries {
+		resp[i] = WebhookDeliveryResponse{
+			ID:           d.ID,
+			Event:        d.Event,
+			Status:       d.Status,
+			Attempts:     d.Attempts,
+			ResponseCode: d.ResponseCode,
+			Error:        d.Error,
+			Payload:      d.Payload,
+			CreatedAt:    d.CreatedAt,
+		}
+	}
+	s.jsonResponse(w, http.StatusOK, resp)
+}
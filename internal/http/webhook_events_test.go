@@ -0,0 +1,73 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestSessionWebhookEvents(t *testing.T) {
+	server, sessionManager := newQuestionTestServer(t)
+	received := make(chan map[string]interface{}, 4)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		_ = json.Unmarshal(body, &payload)
+		received <- payload
+	}))
+	defer hook.Close()
+
+	now := time.Now()
+	if err := server.store.SaveIntegration(&storage.Integration{ID: "hook", Provider: "webhook", Name: "Webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": hook.URL, "events": "session.completed"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveIntegration: %v", err)
+	}
+
+	sess, err := sessionManager.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := sessionManager.SetSessionStatus(sess.ID, string(session.StatusFailed)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+	if err := sessionManager.SetSessionStatus(sess.ID, string(session.StatusCompleted)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+
+	select {
+	case payload := <-received:
+		if payload["event"] != "session.completed" || payload["session_id"] != sess.ID || payload["agent_id"] != "build" || payload["previous_status"] != "failed" || payload["usage"] == nil {
+			t.Fatalf("unexpected payload %+v", payload)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no webhook delivery for the completed session")
+	}
+	select {
+	case payload := <-received:
+		t.Fatalf("unsubscribed event delivered: %+v", payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	var deliveries []WebhookDeliveryResponse
+	for deadline := time.Now().Add(5 * time.Second); len(deliveries) == 0 && time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		rec := serveAuthorized(server, http.MethodGet, "/integrations/hook/deliveries", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("deliveries: status %d body=%s", rec.Code, rec.Body.String())
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &deliveries); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+	}
+	if len(deliveries) != 1 || deliveries[0].Event != "session.completed" || deliveries[0].Status != "delivered" || deliveries[0].Attempts != 1 {
+		t.Fatalf("unexpected delivery log %+v", deliveries)
+	}
+
+	if rec := serveAuthorized(server, http.MethodGet, "/integrations/missing/deliveries", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unknown integration, got %d", rec.Code)
+	}
+}
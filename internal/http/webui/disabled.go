@@ -0,0 +1,14 @@
+//go:build !webui
+
+package webui
+
+import "net/http"
+
+// Enabled reports whether this build includes the web UI.
+const Enabled = false
+
+// Handler returns nil: this build does not include the web UI. Build with
+// -tags webui to embed it.
+func Handler() http.Handler {
+	return nil
+}
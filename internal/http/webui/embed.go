@@ -0,0 +1,24 @@
+//go:build webui
+
+package webui
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+// Enabled reports whether this build includes the web UI.
+const Enabled = true
+
+//go:embed static
+var files embed.FS
+
+// Handler serves the embedded UI files; index.html answers for /.
+func Handler() http.Handler {
+	static, err := fs.Sub(files, "static")
+	if err != nil {
+		panic(err)
+	}
+	return http.FileServer(http.FS(static))
+}
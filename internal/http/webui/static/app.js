@@ -0,0 +1,500 @@
+// aagent dashboard: sessions with streaming chat, recurring jobs and
+// integrations, on top of the HTTP API. No build step and no dependencies.
+"use strict";
+
+const TOKEN_KEY = "aagent.apiToken";
+
+// Config fields each integration provider needs, mirroring the server's
+// requiredConfigFields.
+const INTEGRATION_FIELDS = {
+  telegram: ["bot_token"],
+  slack: ["bot_token", "channel_id"],
+  discord: ["bot_token", "channel_id"],
+  whatsapp: ["access_token", "phone_number_id", "recipient"],
+  webhook: ["url"],
+  email: ["smtp_host", "smtp_port", "username", "password", "from", "to"],
+  x: ["api_key", "api_secret", "access_token", "access_token_secret"],
+  elevenlabs: ["api_key"],
+  google_calendar: ["client_id", "client_secret", "refresh_token"],
+  perplexity: ["api_key"],
+  brave_search: ["api_key"],
+  exa: ["api_key"],
+  a2_registry: ["api_key"],
+};
+
+const view = document.getElementById("view");
+
+// --- helpers -------------------------------------------------------------
+
+function token() {
+  return localStorage.getItem(TOKEN_KEY) || "";
+}
+
+async function api(method, path, body) {
+  const headers = { Accept: "application/json" };
+  if (token()) headers.Authorization = "Bearer " + token();
+  if (body !== undefined) headers["Content-Type"] = "application/json";
+  const resp = await fetch(path, {
+    method,
+    headers,
+    body: body === undefined ? undefined : JSON.stringify(body),
+  });
+  if (!resp.ok) {
+    let message = resp.status + " " + resp.statusText;
+    try {
+      const data = await resp.json();
+      if (data && data.error) message = data.error;
+    } catch (_) {
+      // keep the status line
+    }
+    if (resp.status === 401) message += " (set the API token above)";
+    throw new Error(message);
+  }
+  if (resp.status === 204) return null;
+  return resp.json();
+}
+
+// el builds an element: el("td", {class: "dim"}, "text", childNode).
+function el(tag, attrs, ...children) {
+  const node = document.createElement(tag);
+  for (const [key, value] of Object.entries(attrs || {})) {
+    if (key.startsWith("on")) node.addEventListener(key.slice(2), value);
+    else if (value === true) node.setAttribute(key, "");
+    else if (value !== false && value !== undefined && value !== null) node.setAttribute(key, value);
+  }
+  for (const child of children.flat()) {
+    if (child === null || child === undefined) continue;
+    node.append(child instanceof Node ? child : String(child));
+  }
+  return node;
+}
+
+function statusBadge(status) {
+  return el("span", { class: "status " + (status || "") }, status || "unknown");
+}
+
+function when(value) {
+  if (!value) return "—";
+  return new Date(value).toLocaleString();
+}
+
+let toastTimer;
+function toast(message, isError) {
+  const box = document.getElementById("toast");
+  box.textContent = message;
+  box.className = isError ? "error" : "";
+  box.hidden = false;
+  clearTimeout(toastTimer);
+  toastTimer = setTimeout(() => { box.hidden = true; }, 4000);
+}
+
+function render(...nodes) {
+  view.replaceChildren(...nodes);
+}
+
+async function guarded(fn) {
+  try {
+    await fn();
+  } catch (err) {
+    toast(err.message, true);
+  }
+}
+
+// --- sessions ------------------------------------------------------------
+
+async function showSessions() {
+  const sessions = await api("GET", "/sessions");
+  const rows = (sessions || []).map((s) =>
+    el("tr", { class: "clickable", onclick: () => { location.hash = "#/sessions/" + s.id; } },
+      el("td", {}, s.title || el("span", { class: "dim" }, "(untitled)")),
+      el("td", {}, statusBadge(s.status)),
+      el("td", { class: "dim" }, s.model || ""),
+      el("td", { class: "dim" }, when(s.updated_at))));
+  render(
+    el("div", { class: "toolbar" },
+      el("h2", {}, "Sessions"),
+      el("button", { onclick: () => guarded(newSession) }, "New session")),
+    el("table", {},
+      el("thead", {}, el("tr", {}, el("th", {}, "Title"), el("th", {}, "Status"), el("th", {}, "Model"), el("th", {}, "Updated"))),
+      el("tbody", {}, rows.length ? rows : el("tr", {}, el("td", { colspan: 4, class: "dim" }, "No sessions yet")))));
+}
+
+async function newSession() {
+  const created = await api("POST", "/sessions", { queued: true });
+  location.hash = "#/sessions/" + created.id;
+}
+
+// Sessions load MESSAGE_PAGE messages at a time, latest first, with long
+// tool results cut to a preview.
+const MESSAGE_PAGE = 100;
+
+function sessionPage(id, offset, limit) {
+  let path = "/sessions/" + encodeURIComponent(id) + "?include_tool_results=false";
+  if (offset !== undefined) path += "&message_offset=" + offset + "&message_limit=" + limit;
+  return api("GET", path);
+}
+
+// messageNode renders a message; index locates it in session sessionID so
+// that cut tool results can be fetched in full.
+function messageNode(msg, sessionID, index) {
+  const role = msg.role || "assistant";
+  if (role === "tool") {
+    const text = (msg.tool_results || []).map((r) => (r.is_error ? "✗ " : "✓ ") + (r.content || "")).join("\n");
+    const node = el("div", { class: "message tool" }, el("span", { class: "role" }, "tool results"), text);
+    if (sessionID && (msg.tool_results || []).some((r) => r.truncated)) {
+      node.append(el("a", {
+        href: "#",
+        onclick: (e) => {
+          e.preventDefault();
+          guarded(async () => {
+            const full = await api("GET", "/sessions/" + encodeURIComponent(sessionID) + "/messages/" + index);
+            node.replaceWith(messageNode(full));
+          });
+        },
+      }, " show full output"));
+    }
+    return node;
+  }
+  const calls = (msg.tool_calls || []).map((c) => "→ " + c.name + " " + (c.input_preview || ""));
+  return el("div", { class: "message " + role },
+    el("span", { class: "role" }, role),
+    msg.content || "",
+    calls.length ? el("div", { class: "dim" }, calls.join("\n")) : null);
+}
+
+async function showChat(id) {
+  const sess = await sessionPage(id);
+  const list = el("div", { class: "messages" });
+  const status = el("span", {}, statusBadge(sess.status));
+  const input = el("textarea", { placeholder: "Message (Ctrl+Enter to send)" });
+  const send = el("button", {}, "Send");
+  const stop = el("button", { class: "secondary", disabled: true }, "Stop");
+
+  // offset is the session index of the first message shown
+  let offset = sess.message_offset || 0;
+  const earlier = el("button", { class: "secondary" }, "Load earlier messages");
+  earlier.addEventListener("click", () => guarded(async () => {
+    const start = Math.max(0, offset - MESSAGE_PAGE);
+    const page = await sessionPage(id, start, offset - start);
+    earlier.after(...(page.messages || []).map((m, i) => messageNode(m, id, start + i)));
+    offset = start;
+    earlier.hidden = offset === 0;
+  }));
+
+  const showMessages = (messages, first) => {
+    offset = first;
+    earlier.hidden = offset === 0;
+    list.replaceChildren(earlier, ...(messages || []).map((m, i) => messageNode(m, id, first + i)));
+    list.scrollTop = list.scrollHeight;
+  };
+  // Run events carry every message of the session; show the latest page
+  const showLatest = (messages) => {
+    const first = Math.max(0, messages.length - MESSAGE_PAGE);
+    showMessages(messages.slice(first), first);
+  };
+  showMessages(sess.messages, offset);
+
+  const submit = () => guarded(async () => {
+    const text = input.value.trim();
+    if (!text) return;
+    input.value = "";
+    send.disabled = true;
+    stop.disabled = false;
+    list.append(messageNode({ role: "user", content: text }));
+    const reply = messageNode({ role: "assistant", content: "" });
+    list.append(reply);
+    try {
+      await streamChat(id, text, (event) => {
+        switch (event.type) {
+          case "assistant_delta":
+            reply.append(event.delta || "");
+            list.scrollTop = list.scrollHeight;
+            break;
+          case "tool_completed":
+          case "done":
+            if (event.messages) showLatest(event.messages);
+            break;
+          case "error":
+            toast(event.error || "Run failed", true);
+            break;
+        }
+        if (event.status) status.replaceChildren(statusBadge(event.status));
+      });
+    } finally {
+      send.disabled = false;
+      stop.disabled = true;
+    }
+  });
+
+  send.addEventListener("click", submit);
+  stop.addEventListener("click", () => guarded(() => api("POST", "/sessions/" + encodeURIComponent(id) + "/cancel")));
+  input.addEventListener("keydown", (e) => {
+    if (e.key === "Enter" && (e.ctrlKey || e.metaKey)) submit();
+  });
+
+  render(el("div", { class: "chat" },
+    el("div", { class: "toolbar" },
+      el("a", { href: "#/sessions" }, "← Sessions"),
+      el("h2", {}, sess.title || "(untitled)"),
+      status),
+    list,
+    el("div", { class: "composer" }, input, el("div", {}, send, " ", stop))));
+  input.focus();
+}
+
+// streamChat posts a message and calls onEvent for each line of the
+// newline-delimited JSON stream.
+async function streamChat(id, message, onEvent) {
+  const headers = { "Content-Type": "application/json", Accept: "application/x-ndjson" };
+  if (token()) headers.Authorization = "Bearer " + token();
+  const resp = await fetch("/sessions/" + encodeURIComponent(id) + "/chat/stream", {
+    method: "POST",
+    headers,
+    body: JSON.stringify({ message }),
+  });
+  if (!resp.ok || !resp.body) {
+    let detail = resp.status + " " + resp.statusText;
+    try { detail = (await resp.json()).error || detail; } catch (_) { /* keep status */ }
+    throw new Error(detail);
+  }
+  const reader = resp.body.getReader();
+  const decoder = new TextDecoder();
+  let buffered = "";
+  for (;;) {
+    const { value, done } = await reader.read();
+    if (done) break;
+    buffered += decoder.decode(value, { stream: true });
+    let newline;
+    while ((newline = buffered.indexOf("\n")) >= 0) {
+      const line = buffered.slice(0, newline).trim();
+      buffered = buffered.slice(newline + 1);
+      if (line) onEvent(JSON.parse(line));
+    }
+  }
+  if (buffered.trim()) onEvent(JSON.parse(buffered));
+}
+
+// --- jobs ----------------------------------------------------------------
+
+async function showJobs() {
+  const jobs = await api("GET", "/jobs");
+  const name = el("input", { required: true, placeholder: "Daily report" });
+  const schedule = el("input", { required: true, placeholder: "every weekday at 9am" });
+  const prompt = el("textarea", { required: true, rows: 3, placeholder: "What the agent should do on each run" });
+  // The preview shows how the schedule is read before the job is saved.
+  const preview = el("div", { class: "preview dim" });
+  const previewSchedule = () => guarded(async () => {
+    preview.replaceChildren();
+    if (!schedule.value.trim()) return;
+    try {
+      const res = await api("POST", "/jobs/preview", { schedule_text: schedule.value });
+      preview.replaceChildren(
+        el("div", {}, res.schedule_summary, res.schedule_cron ? " (" + res.schedule_cron + ")" : "", " · " + res.timezone),
+        el("ul", {}, res.next_runs.map((run) => el("li", {}, when(run)))));
+    } catch (err) {
+      preview.replaceChildren(el("span", { class: "error" }, err.message));
+    }
+  });
+  schedule.addEventListener("change", previewSchedule);
+  const form = el("form", { class: "card" },
+    el("label", {}, "Name"), name,
+    el("label", {}, "Schedule"), schedule,
+    el("span", {}), preview,
+    el("label", {}, "Task"), prompt,
+    el("div", { class: "actions" },
+      el("button", { type: "button", class: "secondary", onclick: previewSchedule }, "Preview schedule"),
+      el("button", { type: "submit" }, "Create job")));
+  form.addEventListener("submit", (e) => {
+    e.preventDefault();
+    guarded(async () => {
+      await api("POST", "/jobs", { name: name.value, schedule_text: schedule.value, task_prompt: prompt.value, enabled: true });
+      toast("Job created");
+      await showJobs();
+    });
+  });
+
+  const rows = (jobs || []).map((job) => {
+    const toggle = el("button", {
+      class: "secondary",
+      onclick: () => guarded(async () => {
+        await api("PUT", "/jobs/" + encodeURIComponent(job.id), { enabled: !job.enabled });
+        await showJobs();
+      }),
+    }, job.enabled ? "Disable" : "Enable");
+    const run = el("button", {
+      class: "secondary",
+      onclick: () => guarded(async () => {
+        run.disabled = true;
+        toast("Running " + job.name + "…");
+        try {
+          const started = await api("POST", "/jobs/" + encodeURIComponent(job.id) + "/run");
+          const exec = await waitForExecution(job.id, started);
+          toast(job.name + ": " + exec.status, exec.status === "failed");
+        } finally {
+          run.disabled = false;
+        }
+      }),
+    }, "Run now");
+    return el("tr", {},
+      el("td", {}, el("a", { href: "#/jobs/" + job.id }, job.name)),
+      el("td", {}, job.schedule_summary || job.schedule_human, el("div", { class: "dim" }, job.schedule_cron)),
+      el("td", {}, job.enabled ? statusBadge("enabled") : el("span", { class: "dim" }, "disabled")),
+      el("td", { class: "dim" }, when(job.next_run_at)),
+      el("td", {}, toggle, " ", run));
+  });
+
+  render(
+    el("div", { class: "toolbar" }, el("h2", {}, "Recurring jobs")),
+    form,
+    el("table", {},
+      el("thead", {}, el("tr", {}, el("th", {}, "Name"), el("th", {}, "Schedule"), el("th", {}, "State"), el("th", {}, "Next run"), el("th", {}, ""))),
+      el("tbody", {}, rows.length ? rows : el("tr", {}, el("td", { colspan: 5, class: "dim" }, "No jobs yet")))));
+}
+
+// waitForExecution polls a job run started in the background until it
+// finishes.
+async function waitForExecution(jobID, exec) {
+  const path = "/jobs/" + encodeURIComponent(jobID) + "/executions/" + encodeURIComponent(exec.id);
+  while (exec.status === "running") {
+    await new Promise((resolve) => setTimeout(resolve, 3000));
+    exec = await api("GET", path);
+  }
+  return exec;
+}
+
+async function showJobExecutions(id) {
+  const [job, executions] = await Promise.all([
+    api("GET", "/jobs/" + encodeURIComponent(id)),
+    api("GET", "/jobs/" + encodeURIComponent(id) + "/executions"),
+  ]);
+  const rows = (executions || []).map((exec) =>
+    el("tr", {},
+      el("td", {}, statusBadge(exec.status)),
+      el("td", { class: "dim" }, when(exec.started_at)),
+      el("td", { class: "dim" }, when(exec.finished_at)),
+      el("td", {},
+        exec.session_id ? el("a", { href: "#/sessions/" + exec.session_id }, "session") : null,
+        exec.error ? el("pre", { class: "output" }, exec.error) : null,
+        exec.output ? el("pre", { class: "output" }, exec.output) : null)));
+  render(
+    el("div", { class: "toolbar" },
+      el("a", { href: "#/jobs" }, "← Jobs"),
+      el("h2", {}, job.name + " — executions")),
+    el("p", { class: "dim" }, job.task_prompt),
+    el("table", {},
+      el("thead", {}, el("tr", {}, el("th", {}, "Status"), el("th", {}, "Started"), el("th", {}, "Finished"), el("th", {}, "Result"))),
+      el("tbody", {}, rows.length ? rows : el("tr", {}, el("td", { colspan: 4, class: "dim" }, "No runs yet")))));
+}
+
+// --- integrations --------------------------------------------------------
+
+function integrationForm(existing, onSaved) {
+  const provider = el("select", { disabled: !!existing },
+    Object.keys(INTEGRATION_FIELDS).map((p) => el("option", { value: p, selected: existing && existing.provider === p }, p)));
+  const name = el("input", { required: true, value: existing ? existing.name : "" });
+  const mode = el("select", {},
+    ["notify_only", "duplex"].map((m) => el("option", { value: m, selected: existing && existing.mode === m }, m)));
+  const enabled = el("input", { type: "checkbox", checked: existing ? existing.enabled : true });
+  const fields = el("div", { style: "display: contents" });
+
+  const drawFields = () => {
+    const config = (existing && existing.config) || {};
+    fields.replaceChildren(...INTEGRATION_FIELDS[provider.value].flatMap((key) => [
+      el("label", {}, key),
+      el("input", { name: key, value: config[key] || "", required: true, autocomplete: "off" }),
+    ]));
+  };
+  provider.addEventListener("change", drawFields);
+  drawFields();
+
+  const form = el("form", { class: "card" },
+    el("label", {}, "Provider"), provider,
+    el("label", {}, "Name"), name,
+    el("label", {}, "Mode"), mode,
+    el("label", {}, "Enabled"), el("div", {}, enabled),
+    fields,
+    el("div", { class: "actions" },
+      el("button", { type: "submit" }, existing ? "Save" : "Add integration"),
+      existing ? el("button", { type: "button", class: "secondary", onclick: () => onSaved() }, "Cancel") : null));
+  form.addEventListener("submit", (e) => {
+    e.preventDefault();
+    guarded(async () => {
+      const config = {};
+      for (const input of fields.querySelectorAll("input")) config[input.name] = input.value;
+      const body = { provider: provider.value, name: name.value, mode: mode.value, enabled: enabled.checked, config };
+      if (existing) await api("PUT", "/integrations/" + encodeURIComponent(existing.id), body);
+      else await api("POST", "/integrations", body);
+      toast("Integration saved");
+      onSaved();
+    });
+  });
+  return form;
+}
+
+async function showIntegrations(editing) {
+  const integrations = await api("GET", "/integrations");
+  const reload = () => guarded(() => showIntegrations());
+  const rows = (integrations || []).map((integration) =>
+    el("tr", {},
+      el("td", {}, integration.name),
+      el("td", { class: "dim" }, integration.provider),
+      el("td", { class: "dim" }, integration.mode),
+      el("td", {}, integration.enabled ? statusBadge("enabled") : el("span", { class: "dim" }, "disabled")),
+      el("td", {},
+        el("button", { class: "secondary", onclick: () => guarded(() => showIntegrations(integration)) }, "Edit"), " ",
+        el("button", {
+          class: "secondary",
+          onclick: () => guarded(async () => {
+            const result = await api("POST", "/integrations/" + encodeURIComponent(integration.id) + "/test");
+            toast(result.message || (result.success ? "OK" : "Failed"), !result.success);
+          }),
+        }, "Test"), " ",
+        el("button", {
+          class: "secondary",
+          onclick: () => guarded(async () => {
+            if (!confirm("Delete " + integration.name + "?")) return;
+            await api("DELETE", "/integrations/" + encodeURIComponent(integration.id));
+            await showIntegrations();
+          }),
+        }, "Delete"))));
+  render(
+    el("div", { class: "toolbar" }, el("h2", {}, editing ? "Edit " + editing.name : "Integrations")),
+    integrationForm(editing, reload),
+    el("table", {},
+      el("thead", {}, el("tr", {}, el("th", {}, "Name"), el("th", {}, "Provider"), el("th", {}, "Mode"), el("th", {}, "State"), el("th", {}, ""))),
+      el("tbody", {}, rows.length ? rows : el("tr", {}, el("td", { colspan: 5, class: "dim" }, "No integrations yet")))));
+}
+
+// --- routing -------------------------------------------------------------
+
+const routes = [
+  [/^#\/sessions\/(.+)$/, (id) => showChat(decodeURIComponent(id))],
+  [/^#\/jobs\/(.+)$/, (id) => showJobExecutions(decodeURIComponent(id))],
+  [/^#\/jobs$/, () => showJobs()],
+  [/^#\/integrations$/, () => showIntegrations()],
+  [/^/, () => showSessions()],
+];
+
+function route() {
+  const hash = location.hash || "#/sessions";
+  for (const link of document.querySelectorAll("header nav a")) {
+    link.classList.toggle("active", hash.startsWith(link.getAttribute("href")));
+  }
+  for (const [pattern, handler] of routes) {
+    const match = hash.match(pattern);
+    if (match) {
+      guarded(() => handler(...match.slice(1)));
+      return;
+    }
+  }
+}
+
+document.getElementById("token").value = token();
+document.getElementById("token-form").addEventListener("submit", (e) => {
+  e.preventDefault();
+  localStorage.setItem(TOKEN_KEY, document.getElementById("token").value.trim());
+  toast("Token saved");
+  route();
+});
+window.addEventListener("hashchange", route);
+route();
@@ -0,0 +1,7 @@
+// Package webui holds the single-page dashboard served by the HTTP server
+// at /. The files under static are embedded only in builds with the webui
+// build tag, so minimal builds do not carry them.
+package webui
+
+// AssetPrefix is the path the UI's scripts and styles are served under.
+const AssetPrefix = "/ui/"
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/http/webui"
+)
+
+func TestWebUIServedWithoutToken(t *testing.T) {
+	t.Parallel()
+
+	s := &Server{config: &config.Config{Server: config.ServerConfig{APITokens: []config.APIToken{{Name: "web", Token: "web-secret"}}}}}
+	s.setupRoutes()
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	if !webui.Enabled {
+		if rec := get("/"); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("without the webui tag / should stay behind auth, got %d", rec.Code)
+		}
+		return
+	}
+	if rec := get("/"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "/ui/app.js") {
+		t.Fatalf("GET / = %d %q", rec.Code, rec.Body.String())
+	}
+	if rec := get("/ui/app.js"); rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "streamChat") {
+		t.Fatalf("GET /ui/app.js = %d", rec.Code)
+	}
+	if rec := get("/sessions"); rec.Code != http.StatusUnauthorized {
+		t.Fatalf("API routes must still require a token, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,96 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Executions keeps the execution records of job runs, from the running
+// record saved when a run starts to its outcome.
+type Executions struct {
+	Store storage.Store
+	// Sessions saves the run's session when Settle changes it.
+	Sessions *session.Manager
+	// DataPath is where full outputs are written; see RecordOutput.
+	DataPath string
+	// SummaryChars bounds the output kept on the record itself.
+	SummaryChars int
+}
+
+// Start saves the running execution record of a run of job starting at
+// startedAt.
+func (e Executions) Start(job *storage.RecurringJob, startedAt time.Time) (*storage.JobExecution, error) {
+	exec := &storage.JobExecution{
+		ID:        uuid.New().String(),
+		JobID:     job.ID,
+		Status:    "running",
+		StartedAt: startedAt,
+	}
+	if err := e.Store.SaveJobExecution(exec); err != nil {
+		return nil, err
+	}
+	return exec, nil
+}
+
+// Attach links exec to the session running it and saves the link right away,
+// so that a run that never finishes still leads to its session.
+func (e Executions) Attach(ctx context.Context, exec *storage.JobExecution, sessionID string) {
+	exec.SessionID = sessionID
+	if err := e.Store.SaveJobExecution(exec); err != nil {
+		logging.WarnContext(ctx, "Failed to link execution %s to session %s: %v", exec.ID, sessionID, err)
+	}
+}
+
+// Fail finishes exec as a run that failed before its agent ran.
+func (e Executions) Fail(ctx context.Context, exec *storage.JobExecution, message string) {
+	exec.Status = "failed"
+	exec.Error = message
+	e.finish(ctx, exec)
+}
+
+// Finish records how a run that answered output and err ended. A cancelled
+// run counts as stopped by a user unless ctx, the context the run was
+// started with, is done as well. Successful and timed out runs keep their
+// output; timed out runs leave a paused session that can be resumed.
+func (e Executions) Finish(ctx context.Context, exec *storage.JobExecution, output string, err error) {
+	switch {
+	case errors.Is(err, context.Canceled) && ctx.Err() == nil:
+		logging.InfoContext(ctx, "Job %s cancelled", exec.JobID)
+		exec.Status = "cancelled"
+		exec.Error = "Cancelled by user"
+	case errors.Is(err, agent.ErrMaxDurationExceeded):
+		logging.WarnContext(ctx, "Job %s timed out: %v", exec.JobID, err)
+		exec.Status = "timed_out"
+		exec.Error = err.Error()
+		if err := RecordOutput(e.DataPath, exec, output, e.SummaryChars); err != nil {
+			logging.WarnContext(ctx, "Failed to write partial output of job %s: %v", exec.JobID, err)
+		}
+	case err != nil:
+		logging.ErrorContext(ctx, "Job %s failed: %v", exec.JobID, err)
+		exec.Status = "failed"
+		exec.Error = err.Error()
+	default:
+		logging.InfoContext(ctx, "Job %s completed successfully", exec.JobID)
+		exec.Status = "success"
+		// The record keeps a summary; the full output goes to a file
+		if err := RecordOutput(e.DataPath, exec, output, e.SummaryChars); err != nil {
+			logging.WarnContext(ctx, "Failed to write full output of job %s: %v", exec.JobID, err)
+		}
+	}
+	e.finish(ctx, exec)
+}
+
+func (e Executions) finish(ctx context.Context, exec *storage.JobExecution) {
+	finishedAt := time.Now()
+	exec.FinishedAt = &finishedAt
+	if err := e.Store.SaveJobExecution(exec); err != nil {
+		logging.ErrorContext(ctx, "Failed to update execution record for job %s: %v", exec.JobID, err)
+	}
+}
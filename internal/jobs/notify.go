@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/notify"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// When a job notifies its integrations about a finished run.
+const (
+	NotifyOnFailure = "failure"
+	NotifyOnSuccess = "success"
+	NotifyOnAlways  = "always"
+)
+
+// NormalizeNotifyOn validates a notify_on setting; empty turns notifications off.
+func NormalizeNotifyOn(raw string) (string, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+	switch value {
+	case "", NotifyOnFailure, NotifyOnSuccess, NotifyOnAlways:
+		return value, nil
+	}
+	return "", fmt.Errorf("notify_on must be failure, success or always, got %q", raw)
+}
+
+// ShouldNotify reports whether a run that ended with status is announced.
+// Cancelled runs were stopped by a user, so only "always" reports them;
+// runs that timed out count as failures.
+func ShouldNotify(job *storage.RecurringJob, status string) bool {
+	if job == nil || len(job.NotifyTargets) == 0 {
+		return false
+	}
+	switch job.NotifyOn {
+	case NotifyOnAlways:
+		return true
+	case NotifyOnFailure:
+		return status == "failed" || status == "timed_out"
+	case NotifyOnSuccess:
+		return status == "success"
+	}
+	return false
+}
+
+// ValidateNotifyTargets checks that every integration exists and can send.
+func ValidateNotifyTargets(store storage.Store, ids []string) error {
+	for _, id := range ids {
+		integration, err := store.GetIntegration(id)
+		if err != nil {
+			return fmt.Errorf("notification integration %s not found", id)
+		}
+		if !notify.Supports(integration.Provider) {
+			return fmt.Errorf("integration %s (%s) cannot send notifications; supported providers: %s", id, integration.Provider, strings.Join(notify.SupportedProviders(), ", "))
+		}
+	}
+	return nil
+}
+
+// NotifyResult sends the job.finished event to subscribed webhooks, then
+// announces the execution through the job's own integrations when its
+// notify_on setting asks for it, skipping webhooks that already got the
+// event. Delivery failures are logged only. It blocks while retrying.
+func NotifyResult(ctx context.Context, store storage.Store, job *storage.RecurringJob, exec *storage.JobExecution) {
+	msg := notify.JobMessage(job, exec)
+	delivered := make(map[string]bool)
+	for _, id := range notify.DeliverEvent(ctx, store, notify.Event{Type: notify.EventJobFinished, Fields: msg.Fields}) {
+		delivered[id] = true
+	}
+	if !ShouldNotify(job, exec.Status) {
+		return
+	}
+	for _, id := range job.NotifyTargets {
+		if delivered[id] {
+			continue
+		}
+		integration, err := store.GetIntegration(id)
+		if err != nil {
+			logging.WarnContext(ctx, "Job %s notification skipped: integration %s not found", job.ID, id)
+			continue
+		}
+		if err := notify.Send(ctx, integration, msg); err != nil {
+			logging.WarnContext(ctx, "Job %s notification through %s failed: %v", job.ID, id, err)
+		}
+	}
+}
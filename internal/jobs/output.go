@@ -0,0 +1,48 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// truncatedSuffix marks an execution summary cut short of the full output.
+const truncatedSuffix = "... (truncated)"
+
+// RecordOutput stores the output of a finished run on exec. The full text is
+// written to the execution's output file under dataPath and exec.Output keeps
+// at most limit bytes of it. The summary is set even when writing the file
+// fails.
+func RecordOutput(dataPath string, exec *storage.JobExecution, output string, limit int) error {
+	exec.Output = Summarize(output, limit)
+	if output == "" {
+		return nil
+	}
+	path, err := filepath.Abs(storage.ExecutionOutputPath(dataPath, exec.ID))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(output), 0o644); err != nil {
+		return err
+	}
+	exec.OutputPath = path
+	return nil
+}
+
+// Summarize cuts output to at most limit bytes, on a character boundary,
+// marking it as truncated. A non-positive limit keeps it whole.
+func Summarize(output string, limit int) string {
+	if limit <= 0 || len(output) <= limit {
+		return output
+	}
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return output[:cut] + truncatedSuffix
+}
@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/notify"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// StatusWaitingInput is the status of executions whose agent asked a
+// question. They stay unfinished until an answer resumes the run.
+const StatusWaitingInput = "waiting_input"
+
+// Session metadata binding a job-run session to the chat its question was
+// sent to, so that a reply there answers it. Telegram questions go to the
+// integration's default chat; Slack ones start a thread in its channel.
+const (
+	QuestionIntegrationMetadataKey = "question_integration_id"
+	QuestionChatMetadataKey        = "question_chat_id"
+	QuestionThreadMetadataKey      = "question_thread_ts"
+)
+
+// Settle records how a run of job in sess that answered output and err
+// ended, like Finish, except that a run paused on a question leaves exec
+// waiting_input and sends the question through the job's notification
+// integrations. Jobs set to fail on questions fail such runs, and their
+// session, instead. It reports whether exec finished.
+func (e Executions) Settle(ctx context.Context, job *storage.RecurringJob, exec *storage.JobExecution, sess *session.Session, output string, err error) bool {
+	if err == nil && sess.Status == session.StatusInputRequired {
+		question, _ := sess.PendingQuestion()
+		if question != nil && !job.FailOnQuestion {
+			e.await(ctx, job, exec, sess, question)
+			return false
+		}
+		err = fmt.Errorf("agent asked a question, but the job fails on questions")
+		if question != nil {
+			err = fmt.Errorf("agent asked %q, but the job fails on questions", question.Question)
+		}
+		sess.SetStatus(session.StatusFailed)
+		if saveErr := e.Sessions.Save(sess); saveErr != nil {
+			logging.WarnContext(ctx, "Failed to fail session %s of job %s: %v", sess.ID, job.ID, saveErr)
+		}
+	}
+	e.Finish(ctx, exec, output, err)
+	return true
+}
+
+// await leaves exec waiting for an answer to question and asks it through
+// every integration the job notifies, whatever its notify_on. The first
+// duplex Telegram or Slack integration takes replies.
+func (e Executions) await(ctx context.Context, job *storage.RecurringJob, exec *storage.JobExecution, sess *session.Session, question *session.QuestionData) {
+	logging.InfoContext(ctx, "Job %s is waiting for an answer", job.ID)
+	exec.Status = StatusWaitingInput
+	if err := e.Store.SaveJobExecution(exec); err != nil {
+		logging.ErrorContext(ctx, "Failed to update execution record for job %s: %v", job.ID, err)
+	}
+
+	msg := notify.JobQuestionMessage(job, exec, question)
+	bound := false
+	for _, id := range job.NotifyTargets {
+		integration, err := e.Store.GetIntegration(id)
+		if err != nil {
+			logging.WarnContext(ctx, "Job %s question skipped: integration %s not found", job.ID, id)
+			continue
+		}
+		chatID, threadTS, err := sendQuestion(ctx, integration, msg)
+		if err != nil {
+			logging.WarnContext(ctx, "Job %s question through %s failed: %v", job.ID, id, err)
+			continue
+		}
+		if bound || integration.Mode != "duplex" || chatID == "" {
+			continue
+		}
+		bound = true
+		sess.Metadata[QuestionIntegrationMetadataKey] = integration.ID
+		sess.Metadata[QuestionChatMetadataKey] = chatID
+		sess.Metadata[QuestionThreadMetadataKey] = threadTS
+		if err := e.Sessions.Save(sess); err != nil {
+			logging.WarnContext(ctx, "Failed to bind session %s to the %s chat: %v", sess.ID, id, err)
+		}
+	}
+}
+
+// sendQuestion delivers msg through integration and returns the chat, and
+// for Slack the thread, replies arrive in.
+func sendQuestion(ctx context.Context, integration *storage.Integration, msg notify.Message) (string, string, error) {
+	if integration.Provider == "slack" && integration.Enabled {
+		channel := strings.TrimSpace(integration.Config["channel_id"])
+		threadTS, err := notify.PostSlackMessage(ctx, integration.Config, channel, "", msg.Text)
+		return channel, threadTS, err
+	}
+	if err := notify.Send(ctx, integration, msg); err != nil {
+		return "", "", err
+	}
+	if integration.Provider == "telegram" {
+		return strings.TrimSpace(integration.Config["default_chat_id"]), "", nil
+	}
+	return "", "", nil
+}
+
+// ReplyAnswers turns a chat reply to question into the answers it picks:
+// options may be named by their number in JobQuestionMessage, and
+// questions taking several answers split the reply on commas.
+func ReplyAnswers(question *session.QuestionData, reply string) []string {
+	parts := []string{reply}
+	if question.Multiple {
+		parts = strings.Split(reply, ",")
+	}
+	answers := make([]string, 0, len(parts))
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+		if n, err := strconv.Atoi(part); err == nil && n >= 1 && n <= len(question.Options) {
+			part = question.Options[n-1].Label
+		}
+		answers = append(answers, part)
+	}
+	return answers
+}
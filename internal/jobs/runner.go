@@ -0,0 +1,300 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ThinkingProjectID is the system project that sessions of the Thinking job
+// are filed under.
+const ThinkingProjectID = "project-thinking"
+
+const thinkingProjectName = "Thinking"
+
+// AgentFunc runs the task prompt of job, already added to sess, and returns
+// the agent's final answer.
+type AgentFunc func(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error)
+
+// Runner runs jobs, whether they are due or started on demand: it records
+// each run's execution, gives it a session, runs the agent on the task
+// prompt and reschedules the job. A job runs at most once at a time.
+type Runner struct {
+	records Executions
+	agent   AgentFunc
+
+	mu          sync.Mutex
+	wg          sync.WaitGroup
+	runningJobs map[string]struct{}
+	// lifetime bounds runs started with StartRun; see Bind.
+	lifetime context.Context
+
+	// activeRuns holds the cancel function of each running job, keyed by
+	// session ID, so CancelSession can stop it.
+	activeRunsMu sync.Mutex
+	activeRuns   map[string]context.CancelFunc
+}
+
+// NewRunner returns a runner that keeps execution records in records and
+// runs agent on each job's task prompt.
+func NewRunner(records Executions, agent AgentFunc) *Runner {
+	return &Runner{
+		records:     records,
+		agent:       agent,
+		runningJobs: make(map[string]struct{}),
+		lifetime:    context.Background(),
+		activeRuns:  make(map[string]context.CancelFunc),
+	}
+}
+
+// Bind ties runs started with StartRun to ctx, so that they stop with it.
+func (r *Runner) Bind(ctx context.Context) {
+	r.mu.Lock()
+	r.lifetime = ctx
+	r.mu.Unlock()
+}
+
+// Wait waits for the runs in progress to finish.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// ActiveRuns returns the number of job runs in progress.
+func (r *Runner) ActiveRuns() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.runningJobs)
+}
+
+// CancelSession stops the job run driving sessionID, if any. The agent loop
+// pauses the session and saves the messages produced so far.
+func (r *Runner) CancelSession(sessionID string) bool {
+	r.activeRunsMu.Lock()
+	cancel, ok := r.activeRuns[sessionID]
+	delete(r.activeRuns, sessionID)
+	r.activeRunsMu.Unlock()
+
+	if ok {
+		cancel()
+	}
+	return ok
+}
+
+// SessionRunning reports whether a job run is working on the session.
+func (r *Runner) SessionRunning(sessionID string) bool {
+	r.activeRunsMu.Lock()
+	defer r.activeRunsMu.Unlock()
+	_, ok := r.activeRuns[sessionID]
+	return ok
+}
+
+func (r *Runner) trackRun(sessionID string, cancel context.CancelFunc) {
+	r.activeRunsMu.Lock()
+	r.activeRuns[sessionID] = cancel
+	r.activeRunsMu.Unlock()
+}
+
+func (r *Runner) untrackRun(sessionID string) {
+	r.activeRunsMu.Lock()
+	delete(r.activeRuns, sessionID)
+	r.activeRunsMu.Unlock()
+}
+
+// RunNow executes job immediately and waits for it to finish. It fails when
+// the job is already running.
+func (r *Runner) RunNow(ctx context.Context, job *storage.RecurringJob) (*storage.JobExecution, error) {
+	if _, err := r.claimJob(job); err != nil {
+		return nil, err
+	}
+	defer r.releaseJob(job.ID)
+
+	now := time.Now()
+	defer r.rescheduleAfterAttempt(job, now)
+
+	exec, err := r.records.Start(job, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record execution of job %s: %w", job.Name, err)
+	}
+	r.runExecution(ctx, job, exec)
+	return exec, nil
+}
+
+// StartRun records an execution of job and runs it in the background,
+// returning the execution while it is still running. The run keeps ctx's
+// values but not its cancellation, so a caller that goes away does not
+// abort it; it stops with the context given to Bind instead. It fails when
+// the job is already running.
+func (r *Runner) StartRun(ctx context.Context, job *storage.RecurringJob) (*storage.JobExecution, error) {
+	lifetime, err := r.claimJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	// The run reschedules its own copy, leaving the caller's job untouched
+	run := *job
+	now := time.Now()
+	exec, err := r.records.Start(&run, now)
+	if err != nil {
+		r.rescheduleAfterAttempt(&run, now)
+		r.releaseJob(job.ID)
+		return nil, fmt.Errorf("failed to record execution of job %s: %w", job.Name, err)
+	}
+	started := *exec
+
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(lifetime, cancel)
+	r.wg.Add(1)
+	go func() {
+		defer func() {
+			stop()
+			cancel()
+			r.releaseJob(job.ID)
+			r.wg.Done()
+		}()
+		defer r.rescheduleAfterAttempt(&run, now)
+		r.runExecution(runCtx, &run, exec)
+	}()
+	return &started, nil
+}
+
+// claimJob marks job as running, failing with ErrAlreadyRunning when it
+// already is. It returns the context runs are bound to.
+func (r *Runner) claimJob(job *storage.RecurringJob) (context.Context, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.runningJobs[job.ID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrAlreadyRunning, job.Name)
+	}
+	r.runningJobs[job.ID] = struct{}{}
+	return r.lifetime, nil
+}
+
+func (r *Runner) releaseJob(jobID string) {
+	r.mu.Lock()
+	delete(r.runningJobs, jobID)
+	r.mu.Unlock()
+}
+
+// runExecution runs job and finishes exec with the outcome, unless the agent
+// asked a question and exec waits for the answer.
+func (r *Runner) runExecution(ctx context.Context, job *storage.RecurringJob, exec *storage.JobExecution) {
+	ctx = logging.WithJobID(ctx, job.ID)
+	logging.InfoContext(ctx, "Executing job: %s (%s)", job.Name, job.ID)
+
+	ctx, span := tracing.Start(ctx, "scheduler.job",
+		attribute.String("job.id", job.ID),
+		attribute.String("job.name", job.Name),
+		attribute.String("job.execution_id", exec.ID),
+	)
+	defer func() {
+		span.SetAttributes(
+			attribute.String("job.status", exec.Status),
+			attribute.String("session.id", exec.SessionID),
+		)
+		if exec.Status == "failed" {
+			tracing.Fail(span, exec.Error)
+		}
+		span.End()
+	}()
+
+	// Every return below leaves exec finished or waiting for an answer, so
+	// the outcome is announced however the run ended; runs resumed by an
+	// answer announce theirs when they finish. Shutdown must not cut the
+	// message off, and webhook retries must not hold up the runner.
+	defer func() {
+		if exec.FinishedAt != nil {
+			go NotifyResult(context.WithoutCancel(ctx), r.records.Store, job, exec)
+		}
+	}()
+
+	// Create a session for this job execution
+	sess, err := r.records.Sessions.CreateWithJob(AgentID(job), job.ID, exec.ID)
+	if err != nil {
+		logging.ErrorContext(ctx, "Failed to create session for job %s: %v", job.ID, err)
+		r.records.Fail(ctx, exec, "Failed to create session: "+err.Error())
+		return
+	}
+
+	ctx = logging.WithSessionID(ctx, sess.ID)
+	r.records.Attach(ctx, exec, sess.ID)
+	if thinking, thinkErr := r.isThinkingJob(job.ID); thinkErr != nil {
+		logging.WarnContext(ctx, "Failed to check thinking job for project assignment: %v", thinkErr)
+	} else if thinking {
+		if assignErr := r.assignSessionToThinkingProject(sess); assignErr != nil {
+			logging.WarnContext(ctx, "Failed to assign Thinking project for session %s: %v", sess.ID, assignErr)
+		}
+	}
+
+	effectiveTaskPrompt, resolveErr := ResolveTaskPrompt(job)
+	if resolveErr != nil {
+		logging.ErrorContext(ctx, "Failed to resolve task instructions for job %s: %v", job.ID, resolveErr)
+		r.records.Fail(ctx, exec, "Failed to resolve task instructions: "+resolveErr.Error())
+		return
+	}
+
+	// Create a timeout context for job execution. The job ID scopes the
+	// memory tool to this job, like session_id does for session-bound tools.
+	jobCtx, cancel := context.WithTimeout(context.WithValue(ctx, "job_id", job.ID), Timeout(job))
+	defer cancel()
+	r.trackRun(sess.ID, cancel)
+	defer r.untrackRun(sess.ID)
+
+	sess.AddUserMessage(effectiveTaskPrompt)
+
+	output, err := r.agent(jobCtx, job, sess, effectiveTaskPrompt)
+	r.records.Settle(ctx, job, exec, sess, output, err)
+}
+
+// rescheduleAfterAttempt records that job was attempted at attemptedAt and
+// saves its next run.
+func (r *Runner) rescheduleAfterAttempt(job *storage.RecurringJob, attemptedAt time.Time) {
+	job.LastRunAt = &attemptedAt
+	if err := Reschedule(job, attemptedAt); err != nil {
+		logging.Error("Failed to calculate next run for job %s: %v", job.ID, err)
+	} else if job.NextRunAt == nil {
+		logging.Info("One-shot job %s has run and is now disabled", job.Name)
+	} else {
+		logging.Info("Job %s next run scheduled for: %s", job.Name, job.NextRunAt.Format(time.RFC3339))
+	}
+	job.UpdatedAt = time.Now()
+
+	if err := r.records.Store.SaveJob(job); err != nil {
+		logging.Error("Failed to update job %s after execution attempt: %v", job.ID, err)
+	}
+}
+
+func (r *Runner) isThinkingJob(jobID string) (bool, error) {
+	settings, err := r.records.Store.GetSettings()
+	if err != nil {
+		return false, err
+	}
+	thinkingJobID := strings.TrimSpace(settings[ThinkingJobIDSettingKey])
+	if thinkingJobID == "" {
+		return false, nil
+	}
+	return thinkingJobID == strings.TrimSpace(jobID), nil
+}
+
+func (r *Runner) assignSessionToThinkingProject(sess *session.Session) error {
+	now := time.Now()
+	project := &storage.Project{
+		ID:        ThinkingProjectID,
+		Name:      thinkingProjectName,
+		IsSystem:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := r.records.Store.SaveProject(project); err != nil {
+		return err
+	}
+	sess.ProjectID = &project.ID
+	return r.records.Sessions.Save(sess)
+}
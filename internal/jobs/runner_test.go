@@ -0,0 +1,347 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// fakeStore keeps what a job run records in memory. Methods a run does not
+// use panic through the nil embedded Store.
+type fakeStore struct {
+	storage.Store
+
+	mu           sync.Mutex
+	sessions     map[string]storage.Session
+	executions   map[string]storage.JobExecution
+	jobs         map[string]storage.RecurringJob
+	integrations map[string]*storage.Integration
+	projects     map[string]storage.Project
+	settings     map[string]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		sessions:     make(map[string]storage.Session),
+		executions:   make(map[string]storage.JobExecution),
+		jobs:         make(map[string]storage.RecurringJob),
+		integrations: make(map[string]*storage.Integration),
+		projects:     make(map[string]storage.Project),
+		settings:     make(map[string]string),
+	}
+}
+
+func (f *fakeStore) SaveSession(sess *storage.Session) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sess.Version++
+	stored := *sess
+	stored.Messages = append([]storage.Message(nil), sess.Messages...)
+	stored.Metadata = make(map[string]interface{}, len(sess.Metadata))
+	for k, v := range sess.Metadata {
+		stored.Metadata[k] = v
+	}
+	f.sessions[sess.ID] = stored
+	return nil
+}
+
+func (f *fakeStore) GetSession(id string) (*storage.Session, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sess, ok := f.sessions[id]
+	if !ok {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	return &sess, nil
+}
+
+func (f *fakeStore) SaveJobExecution(exec *storage.JobExecution) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.executions[exec.ID] = *exec
+	return nil
+}
+
+func (f *fakeStore) GetJobExecution(id string) (*storage.JobExecution, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec, ok := f.executions[id]
+	if !ok {
+		return nil, fmt.Errorf("execution %s not found", id)
+	}
+	return &exec, nil
+}
+
+func (f *fakeStore) SaveJob(job *storage.RecurringJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs[job.ID] = *job
+	return nil
+}
+
+func (f *fakeStore) GetJob(id string) (*storage.RecurringJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("job %s not found", id)
+	}
+	return &job, nil
+}
+
+func (f *fakeStore) GetIntegration(id string) (*storage.Integration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	integration, ok := f.integrations[id]
+	if !ok {
+		return nil, fmt.Errorf("integration %s not found", id)
+	}
+	return integration, nil
+}
+
+func (f *fakeStore) ListIntegrations() ([]*storage.Integration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var integrations []*storage.Integration
+	for _, integration := range f.integrations {
+		integrations = append(integrations, integration)
+	}
+	return integrations, nil
+}
+
+func (f *fakeStore) SaveWebhookDelivery(*storage.WebhookDelivery) error {
+	return nil
+}
+
+func (f *fakeStore) GetSettings() (map[string]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	settings := make(map[string]string, len(f.settings))
+	for k, v := range f.settings {
+		settings[k] = v
+	}
+	return settings, nil
+}
+
+func (f *fakeStore) SaveProject(project *storage.Project) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.projects[project.ID] = *project
+	return nil
+}
+
+func newTestRunner(t *testing.T, store *fakeStore, run AgentFunc) (*Runner, *session.Manager) {
+	t.Helper()
+	sessions := session.NewManager(store)
+	records := Executions{Store: store, Sessions: sessions, DataPath: t.TempDir(), SummaryChars: 5}
+	return NewRunner(records, run), sessions
+}
+
+func testJob(id string) *storage.RecurringJob {
+	return &storage.RecurringJob{ID: id, Name: "nightly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "report", TaskPromptSource: "text", Enabled: true}
+}
+
+func TestRunnerRecordsSuccessfulRuns(t *testing.T) {
+	store := newFakeStore()
+	store.settings[ThinkingJobIDSettingKey] = "thinking"
+	runner, sessions := newTestRunner(t, store, func(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return "", errors.New("the run has no timeout")
+		}
+		if ctx.Value("job_id") != job.ID {
+			return "", errors.New("the run is not scoped to its job")
+		}
+		// The execution leads to the session while the run is still going
+		if exec, err := store.GetJobExecution(sess.ExecutionID()); err != nil || exec.SessionID != sess.ID || exec.Status != "running" {
+			return "", fmt.Errorf("session %s is not linked to a running execution: %+v, %v", sess.ID, exec, err)
+		}
+		if len(sess.Messages) != 1 || sess.Messages[0].Content != prompt {
+			return "", fmt.Errorf("the task prompt %q is not in the session: %+v", prompt, sess.Messages)
+		}
+		return "full answer of " + job.ID, nil
+	})
+
+	// Run now waits for the run; start run returns while it is going
+	exec, err := runner.RunNow(context.Background(), testJob("thinking"))
+	if err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	started, err := runner.StartRun(context.Background(), testJob("manual"))
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	runner.Wait()
+
+	for id, execID := range map[string]string{"thinking": exec.ID, "manual": started.ID} {
+		stored, err := store.GetJobExecution(execID)
+		if err != nil || stored.Status != "success" || stored.FinishedAt == nil || stored.Output != "full ... (truncated)" {
+			t.Fatalf("job %s: unexpected execution %+v, %v", id, stored, err)
+		}
+		if data, err := os.ReadFile(stored.OutputPath); err != nil || string(data) != "full answer of "+id {
+			t.Errorf("job %s: full output = %q, %v", id, data, err)
+		}
+		sess, err := sessions.Get(stored.SessionID)
+		if err != nil || sess.JobID == nil || *sess.JobID != id || sess.ExecutionID() != execID {
+			t.Errorf("job %s: expected the execution and its session %q to link each other: %v", id, stored.SessionID, err)
+		}
+		if thinking := sess.ProjectID != nil && *sess.ProjectID == ThinkingProjectID; thinking != (id == "thinking") {
+			t.Errorf("job %s: session project = %v", id, sess.ProjectID)
+		}
+		job, err := store.GetJob(id)
+		if err != nil || job.LastRunAt == nil || job.NextRunAt == nil || !job.NextRunAt.After(time.Now()) {
+			t.Errorf("job %s was not rescheduled: %+v, %v", id, job, err)
+		}
+	}
+	if runner.ActiveRuns() != 0 {
+		t.Fatalf("finished runs should release their jobs, %d still running", runner.ActiveRuns())
+	}
+}
+
+func TestRunnerRefusesRunningJob(t *testing.T) {
+	store := newFakeStore()
+	runner, _ := newTestRunner(t, store, func(context.Context, *storage.RecurringJob, *session.Session, string) (string, error) {
+		return "", nil
+	})
+	job := testJob("job-1")
+	runner.runningJobs[job.ID] = struct{}{}
+
+	if _, err := runner.RunNow(context.Background(), job); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("RunNow error = %v, want ErrAlreadyRunning", err)
+	}
+	if _, err := runner.StartRun(context.Background(), job); !errors.Is(err, ErrAlreadyRunning) {
+		t.Fatalf("StartRun error = %v, want ErrAlreadyRunning", err)
+	}
+	if len(store.executions) != 0 {
+		t.Fatalf("expected no execution to be recorded, got %d", len(store.executions))
+	}
+}
+
+func TestRunnerRecordsFailedRuns(t *testing.T) {
+	store := newFakeStore()
+	var runner *Runner
+	var run func(ctx context.Context, sessionID string) (string, error)
+	runner, _ = newTestRunner(t, store, func(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error) {
+		return run(ctx, sess.ID)
+	})
+
+	tests := []struct {
+		name       string
+		run        func(ctx context.Context, sessionID string) (string, error)
+		wantStatus string
+		wantOutput string
+	}{
+		{"failed", func(context.Context, string) (string, error) { return "", errors.New("boom") }, "failed", ""},
+		{"timed out", func(context.Context, string) (string, error) {
+			return "partial", fmt.Errorf("%w after 1m", agent.ErrMaxDurationExceeded)
+		}, "timed_out", "parti... (truncated)"},
+		{"cancelled", func(ctx context.Context, sessionID string) (string, error) {
+			runner.CancelSession(sessionID)
+			<-ctx.Done()
+			return "", ctx.Err()
+		}, "cancelled", ""},
+	}
+	for _, tt := range tests {
+		run = tt.run
+		exec, err := runner.RunNow(context.Background(), testJob("job-1"))
+		if err != nil {
+			t.Fatalf("%s: RunNow: %v", tt.name, err)
+		}
+		stored, err := store.GetJobExecution(exec.ID)
+		if err != nil || stored.Status != tt.wantStatus || stored.Output != tt.wantOutput || stored.Error == "" || stored.FinishedAt == nil {
+			t.Errorf("%s: unexpected execution %+v, %v", tt.name, stored, err)
+		}
+		if runner.SessionRunning(stored.SessionID) {
+			t.Errorf("%s: the finished run still holds session %s", tt.name, stored.SessionID)
+		}
+	}
+
+	bad := testJob("bad-prompt")
+	bad.TaskPromptSource = TaskPromptSourceFile
+	exec, err := runner.RunNow(context.Background(), bad)
+	if err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	if stored, _ := store.GetJobExecution(exec.ID); stored.Status != "failed" || !strings.Contains(stored.Error, "task instructions") {
+		t.Fatalf("expected the run to fail on its task instructions, got %+v", stored)
+	}
+}
+
+func TestRunnerQuestionsWaitForAnswerUnlessJobFailsOnThem(t *testing.T) {
+	store := newFakeStore()
+	var mu sync.Mutex
+	var sent []string
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Event string `json:"event"`
+			Text  string `json:"text"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		sent = append(sent, payload.Event+": "+payload.Text)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+	store.integrations["hook"] = &storage.Integration{ID: "hook", Provider: "webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": hook.URL, "events": "job.finished"}}
+
+	var sessions *session.Manager
+	var runner *Runner
+	runner, sessions = newTestRunner(t, store, func(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error) {
+		question := &session.QuestionData{Question: "Deploy now?", Options: []session.QuestionOption{{Label: "Yes"}, {Label: "No", Description: "wait for Monday"}}}
+		if err := sessions.AskQuestion(sess.ID, question); err != nil {
+			return "", err
+		}
+		// The agent hands back the session the question tool updated
+		fresh, err := sessions.Get(sess.ID)
+		if err != nil {
+			return "", err
+		}
+		sess.Status, sess.Metadata = fresh.Status, fresh.Metadata
+		return "", nil
+	})
+
+	job := testJob("job-1")
+	job.NotifyOn, job.NotifyTargets = NotifyOnFailure, []string{"hook"}
+	exec, err := runner.RunNow(context.Background(), job)
+	if err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	stored, err := store.GetJobExecution(exec.ID)
+	if err != nil || stored.Status != StatusWaitingInput || stored.FinishedAt != nil {
+		t.Fatalf("expected the execution to wait for an answer, got %+v, %v", stored, err)
+	}
+	if sess, err := sessions.Get(stored.SessionID); err != nil || sess.Status != session.StatusInputRequired {
+		t.Fatalf("expected the session to stay input_required: %+v, %v", sess, err)
+	}
+	mu.Lock()
+	questions := append([]string(nil), sent...)
+	mu.Unlock()
+	if len(questions) != 1 || !strings.HasPrefix(questions[0], "job.input_required: ") ||
+		!strings.Contains(questions[0], "2. No - wait for Monday") || !strings.Contains(questions[0], "/sessions/"+stored.SessionID+"/answer") {
+		t.Fatalf("expected the question to be sent once, got %q", questions)
+	}
+
+	job.FailOnQuestion = true
+	exec, err = runner.RunNow(context.Background(), job)
+	if err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+	stored, err = store.GetJobExecution(exec.ID)
+	if err != nil || stored.Status != "failed" || stored.FinishedAt == nil || !strings.Contains(stored.Error, `"Deploy now?", but the job fails on questions`) {
+		t.Fatalf("expected the execution to fail, got %+v, %v", stored, err)
+	}
+	if sess, err := sessions.Get(stored.SessionID); err != nil || sess.Status != session.StatusFailed {
+		t.Fatalf("expected the session to fail: %+v, %v", sess, err)
+	}
+}
@@ -0,0 +1,85 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/A2gent/brute/internal/schedule"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// IsOneShot reports whether the job runs once at RunAt rather than on a
+// cron schedule.
+func IsOneShot(job *storage.RecurringJob) bool {
+	return job != nil && job.ScheduleCron == "" && job.RunAt != nil
+}
+
+// NextRun returns when the job should next run after after, or nil once a
+// one-shot job's time has passed.
+func NextRun(job *storage.RecurringJob, after time.Time) (*time.Time, error) {
+	if IsOneShot(job) {
+		if !job.RunAt.After(after) {
+			return nil, nil
+		}
+		runAt := *job.RunAt
+		return &runAt, nil
+	}
+	next, err := schedule.NextRun(job.ScheduleCron, job.Timezone, after)
+	if err != nil {
+		return nil, err
+	}
+	return &next, nil
+}
+
+// Reschedule sets the job's next run after a run finished at after. A
+// one-shot job whose time has come is disabled; its executions are kept.
+func Reschedule(job *storage.RecurringJob, after time.Time) error {
+	next, err := NextRun(job, after)
+	if err != nil {
+		return err
+	}
+	job.NextRunAt = next
+	if next == nil {
+		job.Enabled = false
+	}
+	return nil
+}
+
+// ApplySchedule stores a parsed schedule on the job, switching it between
+// recurring and one-shot as needed, and sets its next run.
+func ApplySchedule(job *storage.RecurringJob, parsed schedule.Schedule, now time.Time) error {
+	if parsed.RunAt.IsZero() {
+		job.ScheduleCron = parsed.Cron
+		job.RunAt = nil
+	} else {
+		runAt := parsed.RunAt
+		job.ScheduleCron = ""
+		job.RunAt = &runAt
+	}
+	next, err := NextRun(job, now)
+	if err != nil {
+		return err
+	}
+	job.NextRunAt = next
+	return nil
+}
+
+// PreviewRuns returns up to count upcoming runs of a parsed schedule after
+// now, in timezone. A one-time schedule has at most one.
+func PreviewRuns(parsed schedule.Schedule, timezone string, now time.Time, count int) ([]time.Time, error) {
+	loc, err := schedule.LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	job := &storage.RecurringJob{Timezone: timezone}
+	if err := ApplySchedule(job, parsed, now); err != nil {
+		return nil, err
+	}
+	var runs []time.Time
+	for next := job.NextRunAt; next != nil && len(runs) < count; {
+		runs = append(runs, next.In(loc))
+		if next, err = NextRun(job, *next); err != nil {
+			return nil, err
+		}
+	}
+	return runs, nil
+}
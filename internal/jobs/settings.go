@@ -0,0 +1,82 @@
+package jobs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+const (
+	// DefaultAgentID is the agent type jobs run as unless they pick another.
+	DefaultAgentID = "job-runner"
+	// DefaultTimeout bounds a job run that sets no timeout of its own.
+	DefaultTimeout = 30 * time.Minute
+	// MaxTimeoutMinutes is the longest timeout a job may set (24 hours).
+	MaxTimeoutMinutes = 24 * 60
+	// DefaultKeepExecutions is how many executions pruning keeps for a job
+	// that sets no number of its own.
+	DefaultKeepExecutions = 50
+	// ThinkingJobIDSettingKey names the job managed by the Thinking
+	// settings; it cannot be deleted directly.
+	ThinkingJobIDSettingKey = "A2GENT_THINKING_JOB_ID"
+)
+
+// ErrAlreadyRunning is returned when a job is started while a run of it is
+// still in progress.
+var ErrAlreadyRunning = errors.New("job is already running")
+
+// AgentID returns the agent type the job runs as.
+func AgentID(job *storage.RecurringJob) string {
+	if job != nil {
+		if id := strings.TrimSpace(job.AgentID); id != "" {
+			return id
+		}
+	}
+	return DefaultAgentID
+}
+
+// Timeout returns how long a run of the job may take.
+func Timeout(job *storage.RecurringJob) time.Duration {
+	if job != nil && job.TimeoutMinutes > 0 {
+		return time.Duration(job.TimeoutMinutes) * time.Minute
+	}
+	return DefaultTimeout
+}
+
+// KeepExecutions returns how many of the job's newest executions pruning
+// keeps.
+func KeepExecutions(job *storage.RecurringJob) int {
+	if job != nil && job.KeepExecutions > 0 {
+		return job.KeepExecutions
+	}
+	return DefaultKeepExecutions
+}
+
+// ResponseSchema returns the JSON Schema the job's answers must match, or
+// nil when the job accepts prose.
+func ResponseSchema(job *storage.RecurringJob) json.RawMessage {
+	if job == nil || job.ResponseSchema == "" {
+		return nil
+	}
+	return json.RawMessage(job.ResponseSchema)
+}
+
+// ValidateTimeoutMinutes rejects timeouts outside 1 minute to 24 hours.
+func ValidateTimeoutMinutes(minutes int) error {
+	if minutes < 1 || minutes > MaxTimeoutMinutes {
+		return fmt.Errorf("timeout_minutes must be between 1 and %d (24 hours), got %d", MaxTimeoutMinutes, minutes)
+	}
+	return nil
+}
+
+// ValidateKeepExecutions rejects execution retention below one run.
+func ValidateKeepExecutions(keep int) error {
+	if keep < 1 {
+		return fmt.Errorf("keep_executions must be at least 1, got %d", keep)
+	}
+	return nil
+}
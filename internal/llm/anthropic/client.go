@@ -4,12 +4,10 @@ import (
 	"bufio"
 	"bytes"
 	"context"
-	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -21,6 +19,7 @@ const (
 	defaultBaseURL    = "https://api.anthropic.com/v1"
 	defaultAPIVersion = "2023-06-01"
 	defaultMaxTokens  = 8192
+	maxOutputTokens   = 64000 // largest output of current Claude models
 	// Beta features required for Claude Code compatibility
 	claudeCodeBetaHeader = "claude-code-20250219,interleaved-thinking-2025-05-14,fine-grained-tool-streaming-2025-05-14"
 	// OAuth beta feature
@@ -156,13 +155,15 @@ func (c *Client) validateToolSequence(messages []anthropicMessage) []anthropicMe
 
 // anthropicRequest is the request format for Anthropic API
 type anthropicRequest struct {
-	Model       string             `json:"model"`
-	Messages    []anthropicMessage `json:"messages"`
-	System      string             `json:"system,omitempty"`
-	MaxTokens   int                `json:"max_tokens"`
-	Temperature float64            `json:"temperature,omitempty"`
-	Tools       []anthropicTool    `json:"tools,omitempty"`
-	Stream      bool               `json:"stream,omitempty"`
+	Model         string               `json:"model"`
+	Messages      []anthropicMessage   `json:"messages"`
+	System        string               `json:"system,omitempty"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Temperature   float64              `json:"temperature,omitempty"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -208,12 +209,19 @@ type anthropicError struct {
 }
 
 type anthropicStreamEvent struct {
-	Type  string `json:"type"`
-	Index int    `json:"index"`
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message struct {
+		Usage struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
 	Usage struct {
 		InputTokens  int `json:"input_tokens"`
 		OutputTokens int `json:"output_tokens"`
 	} `json:"usage"`
+	Error        anthropicError `json:"error"`
 	ContentBlock struct {
 		Type string `json:"type"`
 		ID   string `json:"id"`
@@ -318,6 +326,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	if maxTokens == 0 {
 		maxTokens = defaultMaxTokens
 	}
+	maxTokens = llm.ClampMaxTokens("Anthropic", maxTokens, maxOutputTokens)
 
 	// Log request with last message content
 	lastMsg := ""
@@ -329,7 +338,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	// Convert messages
 	messages := make([]anthropicMessage, 0, len(request.Messages))
 	for _, msg := range request.Messages {
-		anthroMsg := c.convertMessage(msg)
+		anthroMsg := c.convertMessage(msg, llm.SupportsVision(model))
 		messages = append(messages, anthroMsg)
 	}
 
@@ -345,14 +354,20 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 			InputSchema: t.InputSchema,
 		})
 	}
+	tools, toolChoice, wrapped, err := addResponseTool(tools, request.ResponseFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	reqBody := anthropicRequest{
-		Model:       model,
-		Messages:    messages,
-		System:      c.transformSystemPrompt(request.SystemPrompt),
-		MaxTokens:   maxTokens,
-		Temperature: request.Temperature,
-		Tools:       tools,
+		Model:         model,
+		Messages:      messages,
+		System:        c.transformSystemPrompt(request.SystemPrompt),
+		MaxTokens:     maxTokens,
+		Temperature:   request.Temperature,
+		Tools:         tools,
+		ToolChoice:    toolChoice,
+		StopSequences: request.StopSequences,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -433,6 +448,9 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 			response.Content += "\n" + textParts[i]
 		}
 	}
+	if request.ResponseFormat != nil {
+		takeResponseTool(response, wrapped)
+	}
 
 	// Log response with content and tool names
 	toolNames := make([]string, len(response.ToolCalls))
@@ -455,6 +473,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	if maxTokens == 0 {
 		maxTokens = defaultMaxTokens
 	}
+	maxTokens = llm.ClampMaxTokens("Anthropic", maxTokens, maxOutputTokens)
 
 	lastMsg := ""
 	if len(request.Messages) > 0 {
@@ -464,7 +483,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 
 	messages := make([]anthropicMessage, 0, len(request.Messages))
 	for _, msg := range request.Messages {
-		messages = append(messages, c.convertMessage(msg))
+		messages = append(messages, c.convertMessage(msg, llm.SupportsVision(model)))
 	}
 
 	// Validate and clean up tool use/result pairs
@@ -478,15 +497,21 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 			InputSchema: t.InputSchema,
 		})
 	}
+	tools, toolChoice, wrapped, err := addResponseTool(tools, request.ResponseFormat)
+	if err != nil {
+		return nil, err
+	}
 
 	reqBody := anthropicRequest{
-		Model:       model,
-		Messages:    messages,
-		System:      c.transformSystemPrompt(request.SystemPrompt),
-		MaxTokens:   maxTokens,
-		Temperature: request.Temperature,
-		Tools:       tools,
-		Stream:      true,
+		Model:         model,
+		Messages:      messages,
+		System:        c.transformSystemPrompt(request.SystemPrompt),
+		MaxTokens:     maxTokens,
+		Temperature:   request.Temperature,
+		Tools:         tools,
+		ToolChoice:    toolChoice,
+		StopSequences: request.StopSequences,
+		Stream:        true,
 	}
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
@@ -521,105 +546,13 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 		return nil, err
 	}
 
-	result := &llm.ChatResponse{}
-	toolByBlockIndex := map[int]int{}
-	currentEvent := ""
-	scanner := bufio.NewScanner(resp.Body)
-	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
-
-	for scanner.Scan() {
-		line := scanner.Text()
-		if line == "" {
-			continue
-		}
-		if strings.HasPrefix(line, "event:") {
-			currentEvent = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
-			continue
-		}
-		if !strings.HasPrefix(line, "data:") {
-			continue
-		}
-
-		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if payload == "" || payload == "[DONE]" {
-			continue
-		}
-
-		if currentEvent == "ping" {
-			continue
-		}
-
-		var ev anthropicStreamEvent
-		if err := json.Unmarshal([]byte(payload), &ev); err != nil {
-			return nil, fmt.Errorf("failed to parse stream event %q: %w", currentEvent, err)
-		}
-
-		switch currentEvent {
-		case "message_start":
-			result.Usage.InputTokens = ev.Usage.InputTokens
-			result.Usage.OutputTokens = ev.Usage.OutputTokens
-			if onEvent != nil {
-				if err := onEvent(llm.StreamEvent{Type: llm.StreamEventUsage, Usage: result.Usage}); err != nil {
-					return nil, err
-				}
-			}
-		case "content_block_start":
-			if ev.ContentBlock.Type == "tool_use" {
-				result.ToolCalls = append(result.ToolCalls, llm.ToolCall{
-					ID:   ev.ContentBlock.ID,
-					Name: ev.ContentBlock.Name,
-				})
-				toolByBlockIndex[ev.Index] = len(result.ToolCalls) - 1
-			}
-		case "content_block_delta":
-			if ev.Delta.Type == "text_delta" && ev.Delta.Text != "" {
-				result.Content += ev.Delta.Text
-				if onEvent != nil {
-					if err := onEvent(llm.StreamEvent{
-						Type:         llm.StreamEventContentDelta,
-						ContentDelta: ev.Delta.Text,
-					}); err != nil {
-						return nil, err
-					}
-				}
-			}
-			if ev.Delta.Type == "input_json_delta" && ev.Delta.PartialJSON != "" {
-				if tcIdx, ok := toolByBlockIndex[ev.Index]; ok {
-					result.ToolCalls[tcIdx].Input += ev.Delta.PartialJSON
-					if onEvent != nil {
-						tc := result.ToolCalls[tcIdx]
-						if err := onEvent(llm.StreamEvent{
-							Type:           llm.StreamEventToolCallDelta,
-							ToolCallIndex:  ev.Index,
-							ToolCallID:     tc.ID,
-							ToolCallName:   tc.Name,
-							ToolInputDelta: ev.Delta.PartialJSON,
-						}); err != nil {
-							return nil, err
-						}
-					}
-				}
-			}
-		case "message_delta":
-			if ev.Delta.StopReason != "" {
-				result.StopReason = ev.Delta.StopReason
-			}
-			if ev.Usage.OutputTokens > 0 || ev.Usage.InputTokens > 0 {
-				if ev.Usage.InputTokens > 0 {
-					result.Usage.InputTokens = ev.Usage.InputTokens
-				}
-				result.Usage.OutputTokens = ev.Usage.OutputTokens
-				if onEvent != nil {
-					if err := onEvent(llm.StreamEvent{Type: llm.StreamEventUsage, Usage: result.Usage}); err != nil {
-						return nil, err
-					}
-				}
-			}
-		}
+	result, err := readStream(resp.Body, onEvent)
+	if err != nil {
+		logging.LogResponse(0, 0, 0, err)
+		return nil, err
 	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("stream read error: %w", err)
+	if request.ResponseFormat != nil {
+		takeResponseTool(result, wrapped)
 	}
 
 	toolNames := make([]string, len(result.ToolCalls))
@@ -630,21 +563,11 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	return result, nil
 }
 
-// convertMessage converts an LLM message to Anthropic format
-func (c *Client) convertMessage(msg llm.Message) anthropicMessage {
-	if msg.Role == "user" && len(msg.Images) > 0 {
-		blocks := make([]contentBlock, 0, len(msg.Images)+1)
-		if strings.TrimSpace(msg.Content) != "" {
-			blocks = append(blocks, contentBlock{
-				Type: "text",
-				Text: msg.Content,
-			})
-		}
-		for _, img := range msg.Images {
-			if block := llmImageToAnthropicBlock(img); block != nil {
-				blocks = append(blocks, *block)
-			}
-		}
+// convertMessage converts an LLM message to Anthropic format. Without vision,
+// images become text placeholders.
+func (c *Client) convertMessage(msg llm.Message, vision bool) anthropicMessage {
+	if msg.Role == "user" && msg.HasImageParts() {
+		blocks := imagePartBlocks(msg.ContentParts(), vision)
 		if len(blocks) == 0 {
 			return anthropicMessage{Role: msg.Role, Content: msg.Content}
 		}
@@ -663,30 +586,17 @@ func (c *Client) convertMessage(msg llm.Message) anthropicMessage {
 				continue // Skip tool results without valid tool call IDs
 			}
 
-			content := any(result.Content)
-			if inline := extractInlineImage(result.Metadata); inline != nil {
-				content = []map[string]interface{}{
-					{
-						"type": "image",
-						"source": map[string]interface{}{
-							"type":       "base64",
-							"media_type": inline.MediaType,
-							"data":       inline.DataBase64,
-						},
-					},
-					{
-						"type": "text",
-						"text": result.Content,
-					},
-				}
-			}
 			blocks = append(blocks, contentBlock{
 				Type:      "tool_result",
 				ToolUseID: result.ToolCallID,
-				Content:   content,
+				Content:   result.Content,
 				IsError:   result.IsError,
 			})
 		}
+		// Images the tools returned follow their results in the same turn.
+		if len(blocks) > 0 && vision {
+			blocks = append(blocks, imagePartBlocks(msg.ImageParts(), true)...)
+		}
 
 		// Only create tool result message if we have valid blocks
 		if len(blocks) > 0 {
@@ -784,6 +694,33 @@ func llmImageToAnthropicBlock(img llm.Image) *contentBlock {
 	}
 }
 
+// imagePartBlocks converts content parts to text and image blocks, or to
+// text placeholders for images when the model has no vision.
+func imagePartBlocks(parts []llm.ContentPart, vision bool) []contentBlock {
+	blocks := make([]contentBlock, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != llm.ContentPartImage {
+			if strings.TrimSpace(part.Text) != "" {
+				blocks = append(blocks, contentBlock{Type: "text", Text: part.Text})
+			}
+			continue
+		}
+		if !vision {
+			blocks = append(blocks, contentBlock{Type: "text", Text: part.Placeholder()})
+			continue
+		}
+		img, err := part.Image()
+		if err != nil {
+			logging.Warn("Skipping image part %s: %v", part.Name, err)
+			continue
+		}
+		if block := llmImageToAnthropicBlock(img); block != nil {
+			blocks = append(blocks, *block)
+		}
+	}
+	return blocks
+}
+
 func contentBlockToImage(block contentBlock) *llm.Image {
 	sourceMap, ok := block.Source.(map[string]interface{})
 	if !ok {
@@ -801,79 +738,163 @@ func contentBlockToImage(block contentBlock) *llm.Image {
 	}
 }
 
-type inlineImage struct {
-	MediaType  string
-	DataBase64 string
+func asString(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
 }
 
-func extractInlineImage(metadata map[string]interface{}) *inlineImage {
-	if len(metadata) == 0 {
-		return nil
-	}
-	rawInline, ok := metadata["image_inline"]
-	if !ok {
-		return nil
-	}
-	inlineMap, ok := rawInline.(map[string]interface{})
-	if !ok {
-		return nil
-	}
-	mediaType, _ := inlineMap["media_type"].(string)
-	dataBase64, _ := inlineMap["data_base64"].(string)
-	mediaType = strings.TrimSpace(mediaType)
-	dataBase64 = strings.TrimSpace(dataBase64)
-	if mediaType == "" {
-		return nil
+// readStream assembles a Messages API event stream into the response the
+// non-streaming Chat would have returned: text blocks joined by newlines and
+// tool inputs re-encoded as compact JSON.
+func readStream(body io.Reader, onEvent func(llm.StreamEvent) error) (*llm.ChatResponse, error) {
+	emit := func(ev llm.StreamEvent) error {
+		if onEvent == nil {
+			return nil
+		}
+		return onEvent(ev)
 	}
-	if dataBase64 == "" {
-		path := strings.TrimSpace(asString(inlineMap["path"]))
-		if path == "" {
-			path = strings.TrimSpace(asString(metadataPath(metadata)))
+
+	result := &llm.ChatResponse{}
+	toolByBlockIndex := map[int]int{}
+	textBlocks := 0
+	currentEvent := ""
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
 		}
-		if path == "" {
-			return nil
+		if strings.HasPrefix(line, "event:") {
+			currentEvent = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			continue
 		}
-		raw, err := os.ReadFile(path)
-		if err != nil || len(raw) == 0 {
-			return nil
+		if !strings.HasPrefix(line, "data:") {
+			continue
 		}
-		maxBytes := int64(2 * 1024 * 1024)
-		if v, ok := inlineMap["max_bytes"].(float64); ok && int64(v) > 0 {
-			maxBytes = int64(v)
+
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "" || payload == "[DONE]" {
+			continue
 		}
-		if int64(len(raw)) > maxBytes {
-			return nil
+
+		var ev anthropicStreamEvent
+		if err := json.Unmarshal([]byte(payload), &ev); err != nil {
+			return nil, fmt.Errorf("failed to parse stream event %q: %w", currentEvent, err)
+		}
+		eventType := ev.Type
+		if eventType == "" {
+			eventType = currentEvent
+		}
+
+		switch eventType {
+		case "ping":
+		case "error":
+			return nil, fmt.Errorf("stream error (%s): %s", ev.Error.Type, ev.Error.Message)
+		case "message_start":
+			result.Usage.InputTokens = ev.Message.Usage.InputTokens
+			result.Usage.OutputTokens = ev.Message.Usage.OutputTokens
+			if err := emit(llm.StreamEvent{Type: llm.StreamEventUsage, Usage: result.Usage}); err != nil {
+				return nil, err
+			}
+		case "content_block_start":
+			switch ev.ContentBlock.Type {
+			case "text":
+				// Chat joins text blocks with a newline.
+				delta := ev.ContentBlock.Text
+				if textBlocks > 0 {
+					delta = "\n" + delta
+				}
+				textBlocks++
+				if delta != "" {
+					result.Content += delta
+					if err := emit(llm.StreamEvent{Type: llm.StreamEventContentDelta, ContentDelta: delta}); err != nil {
+						return nil, err
+					}
+				}
+			case "tool_use":
+				result.ToolCalls = append(result.ToolCalls, llm.ToolCall{
+					ID:   ev.ContentBlock.ID,
+					Name: ev.ContentBlock.Name,
+				})
+				toolByBlockIndex[ev.Index] = len(result.ToolCalls) - 1
+			}
+		case "content_block_delta":
+			if ev.Delta.Type == "text_delta" && ev.Delta.Text != "" {
+				result.Content += ev.Delta.Text
+				if err := emit(llm.StreamEvent{
+					Type:         llm.StreamEventContentDelta,
+					ContentDelta: ev.Delta.Text,
+				}); err != nil {
+					return nil, err
+				}
+			}
+			if ev.Delta.Type == "input_json_delta" && ev.Delta.PartialJSON != "" {
+				if tcIdx, ok := toolByBlockIndex[ev.Index]; ok {
+					result.ToolCalls[tcIdx].Input += ev.Delta.PartialJSON
+					tc := result.ToolCalls[tcIdx]
+					if err := emit(llm.StreamEvent{
+						Type:           llm.StreamEventToolCallDelta,
+						ToolCallIndex:  ev.Index,
+						ToolCallID:     tc.ID,
+						ToolCallName:   tc.Name,
+						ToolInputDelta: ev.Delta.PartialJSON,
+					}); err != nil {
+						return nil, err
+					}
+				}
+			}
+		case "content_block_stop":
+			if tcIdx, ok := toolByBlockIndex[ev.Index]; ok {
+				input, err := compactToolInput(result.ToolCalls[tcIdx].Input)
+				if err != nil {
+					return nil, fmt.Errorf("tool %s: invalid streamed input: %w", result.ToolCalls[tcIdx].Name, err)
+				}
+				result.ToolCalls[tcIdx].Input = input
+			}
+		case "message_delta":
+			if ev.Delta.StopReason != "" {
+				result.StopReason = ev.Delta.StopReason
+			}
+			if ev.Usage.OutputTokens > 0 || ev.Usage.InputTokens > 0 {
+				if ev.Usage.InputTokens > 0 {
+					result.Usage.InputTokens = ev.Usage.InputTokens
+				}
+				result.Usage.OutputTokens = ev.Usage.OutputTokens
+				if err := emit(llm.StreamEvent{Type: llm.StreamEventUsage, Usage: result.Usage}); err != nil {
+					return nil, err
+				}
+			}
 		}
-		dataBase64 = base64.StdEncoding.EncodeToString(raw)
-	}
-	if dataBase64 == "" {
-		return nil
 	}
-	return &inlineImage{
-		MediaType:  mediaType,
-		DataBase64: dataBase64,
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream read error: %w", err)
 	}
+	return result, nil
 }
 
-func metadataPath(metadata map[string]interface{}) string {
-	imageFile, ok := metadata["image_file"]
-	if !ok {
-		return ""
+// compactToolInput re-encodes streamed tool input the way Chat encodes the
+// decoded input object; a tool called without arguments streams nothing.
+func compactToolInput(raw string) (string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return "{}", nil
 	}
-	imageFileMap, ok := imageFile.(map[string]interface{})
-	if !ok {
-		return ""
+	var input any
+	if err := json.Unmarshal([]byte(raw), &input); err != nil {
+		return "", err
 	}
-	return asString(imageFileMap["path"])
-}
-
-func asString(value interface{}) string {
-	if s, ok := value.(string); ok {
-		return s
+	encoded, err := json.Marshal(input)
+	if err != nil {
+		return "", err
 	}
-	return ""
+	return string(encoded), nil
 }
 
 // Ensure Client implements llm.Client
 var _ llm.Client = (*Client)(nil)
 var _ llm.StreamingClient = (*Client)(nil)
+var _ llm.ModelLister = (*Client)(nil)
@@ -0,0 +1,162 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	return data
+}
+
+// newFixtureServer answers streaming requests with streamFixture and the
+// others with messageFixture.
+func newFixtureServer(t *testing.T, messageFixture, streamFixture string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write(readFixture(t, streamFixture))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(readFixture(t, messageFixture))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChatStreamMatchesChat(t *testing.T) {
+	server := newFixtureServer(t, "message_tool_use.json", "stream_tool_use.sse")
+	client := NewClientWithBaseURL("test-key", "claude-sonnet-4-5", server.URL)
+	request := &llm.ChatRequest{Messages: []llm.Message{{Role: "user", Content: "What does main.go do?"}}}
+
+	want, err := client.Chat(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	var deltas []string
+	var toolDeltas strings.Builder
+	got, err := client.ChatStream(context.Background(), request, func(ev llm.StreamEvent) error {
+		switch ev.Type {
+		case llm.StreamEventContentDelta:
+			deltas = append(deltas, ev.ContentDelta)
+		case llm.StreamEventToolCallDelta:
+			toolDeltas.WriteString(ev.ToolInputDelta)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("streamed response differs from Chat:\n got %+v\nwant %+v", got, want)
+	}
+	if want.ToolCalls[0].Input != `{"limit":40,"path":"cmd/aagent/main.go"}` || want.ToolCalls[1].Input != `{}` {
+		t.Fatalf("unexpected tool inputs %+v", want.ToolCalls)
+	}
+	if want.Usage.InputTokens != 472 || want.Usage.OutputTokens != 89 {
+		t.Fatalf("unexpected usage %+v", want.Usage)
+	}
+	if strings.Join(deltas, "") != "I'll read the file first." || len(deltas) != 2 {
+		t.Fatalf("unexpected content deltas %q", deltas)
+	}
+	if toolDeltas.String() != `{"path": "cmd/aagent/main.go", "limit": 40}` {
+		t.Fatalf("unexpected tool input deltas %q", toolDeltas.String())
+	}
+}
+
+func TestReadStreamJoinsTextBlocks(t *testing.T) {
+	stream := strings.Join([]string{
+		`data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"First."}}`,
+		`data: {"type":"content_block_start","index":1,"content_block":{"type":"text","text":""}}`,
+		`data: {"type":"content_block_delta","index":1,"delta":{"type":"text_delta","text":"Second."}}`,
+		`data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":4}}`,
+	}, "\n\n")
+
+	resp, err := readStream(strings.NewReader(stream), nil)
+	if err != nil {
+		t.Fatalf("readStream: %v", err)
+	}
+	if resp.Content != "First.\nSecond." || resp.StopReason != "end_turn" {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestReadStreamReturnsErrorEvent(t *testing.T) {
+	_, err := readStream(strings.NewReader(string(readFixture(t, "stream_error.sse"))), nil)
+	if err == nil || !strings.Contains(err.Error(), "Overloaded") {
+		t.Fatalf("expected the overloaded error, got %v", err)
+	}
+}
+
+func TestChatSendsStopSequencesAndClampsMaxTokens(t *testing.T) {
+	var body anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(readFixture(t, "message_tool_use.json"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", "claude-sonnet-4-5", server.URL)
+	_, err := client.Chat(context.Background(), &llm.ChatRequest{
+		Messages:      []llm.Message{{Role: "user", Content: "Count to ten"}},
+		MaxTokens:     1_000_000,
+		StopSequences: []string{"\n\nHuman:", "DONE"},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if body.MaxTokens != maxOutputTokens {
+		t.Fatalf("max_tokens = %d, want %d", body.MaxTokens, maxOutputTokens)
+	}
+	if !reflect.DeepEqual(body.StopSequences, []string{"\n\nHuman:", "DONE"}) {
+		t.Fatalf("stop_sequences = %q", body.StopSequences)
+	}
+}
+
+func TestConvertMessageAddsToolImagesAfterResults(t *testing.T) {
+	client := NewClientWithBaseURL("test-key", "claude-sonnet-4-5", "http://unused")
+	msg := llm.Message{
+		Role:        "tool",
+		ToolResults: []llm.ToolResult{{ToolCallID: "toolu_1", Content: `{"path":"/tmp/photo.jpg"}`}},
+		Parts:       []llm.ContentPart{{Type: llm.ContentPartImage, MediaType: "image/jpeg", DataBase64: "AAAA"}},
+	}
+
+	blocks, ok := client.convertMessage(msg, true).Content.([]contentBlock)
+	if !ok || len(blocks) != 2 || blocks[0].Type != "tool_result" || blocks[1].Type != "image" {
+		t.Fatalf("unexpected blocks %+v", blocks)
+	}
+
+	user := llm.Message{Role: "user", Content: "What is in the photo?", Images: []llm.Image{{Name: "photo.jpg", MediaType: "image/jpeg", DataBase64: "AAAA"}}}
+	blocks, _ = client.convertMessage(user, false).Content.([]contentBlock)
+	if len(blocks) != 2 || blocks[1].Type != "text" || !strings.Contains(blocks[1].Text, "photo.jpg omitted") {
+		t.Fatalf("expected a text placeholder for a text-only model, got %+v", blocks)
+	}
+	blocks, _ = client.convertMessage(msg, false).Content.([]contentBlock)
+	if len(blocks) != 1 {
+		t.Fatalf("text-only models should get the tool result alone, got %+v", blocks)
+	}
+}
@@ -1,9 +1,12 @@
 package anthropic
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -21,51 +24,63 @@ type ModelsResponse struct {
 
 // ListModels fetches available models from Anthropic API
 func ListModels(apiKey string) ([]string, error) {
-	if apiKey == "" {
-		// Return fallback list if no API key
-		return fallbackModels(), nil
-	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return NewClient(apiKey, "").ListModelIDs(ctx)
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", "https://api.anthropic.com/v1/models", nil)
-	if err != nil {
+// ListModelIDs lists the models served by the client's endpoint. Anthropic's
+// own API falls back to the known models when it cannot be queried; other
+// Anthropic-compatible endpoints return the error.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	models, err := c.FetchModelIDs(ctx)
+	if err != nil && strings.TrimRight(c.baseURL, "/") == defaultBaseURL {
 		return fallbackModels(), nil
 	}
+	return models, err
+}
 
-	req.Header.Set("x-api-key", apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+// FetchModelIDs lists the models served by the client's endpoint without
+// falling back to the known models, so that a rejected key is reported.
+func (c *Client) FetchModelIDs(ctx context.Context) ([]string, error) {
+	if c.apiKey == "" && !c.isUsingOAuth() {
+		return nil, fmt.Errorf("API key is not configured")
+	}
 
-	resp, err := client.Do(req)
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(c.baseURL, "/")+"/models", nil)
 	if err != nil {
-		// If API fails, return fallback list
-		return fallbackModels(), nil
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if err := c.prepareHeaders(req); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		// If not authorized or error, return fallback
-		return fallbackModels(), nil
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fallbackModels(), nil
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	var modelsResp ModelsResponse
 	if err := json.Unmarshal(body, &modelsResp); err != nil {
-		return fallbackModels(), nil
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
 	}
-
 	if len(modelsResp.Data) == 0 {
-		return fallbackModels(), nil
+		return nil, fmt.Errorf("no models returned")
 	}
 
 	models := make([]string, 0, len(modelsResp.Data))
 	for _, model := range modelsResp.Data {
 		models = append(models, model.ID)
 	}
-
 	return models, nil
 }
 
@@ -0,0 +1,83 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+// responseToolName is the tool Claude gives a structured final answer
+// through; the Messages API has no JSON output mode of its own.
+const responseToolName = "final_response"
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+// responseTool returns the tool that carries a structured final answer and
+// whether its schema was wrapped. Tool input must be an object, so any other
+// schema becomes the "value" property of one.
+func responseTool(format *llm.ResponseFormat) (anthropicTool, bool, error) {
+	var schema map[string]interface{}
+	if err := json.Unmarshal(format.Schema, &schema); err != nil || schema == nil {
+		return anthropicTool{}, false, fmt.Errorf("invalid response schema: %v", err)
+	}
+	wrapped := schema["type"] != "object"
+	if wrapped {
+		schema = map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"value": schema},
+			"required":   []string{"value"},
+		}
+	}
+	return anthropicTool{
+		Name:        responseToolName,
+		Description: "Give your final answer by calling this tool once you no longer need other tools. Its input is the answer; do not repeat it as text.",
+		InputSchema: schema,
+	}, wrapped, nil
+}
+
+// addResponseTool appends the response tool for request, forcing it when
+// the model has no other tools to call.
+func addResponseTool(tools []anthropicTool, format *llm.ResponseFormat) ([]anthropicTool, *anthropicToolChoice, bool, error) {
+	if format == nil {
+		return tools, nil, false, nil
+	}
+	tool, wrapped, err := responseTool(format)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	var choice *anthropicToolChoice
+	if len(tools) == 0 {
+		choice = &anthropicToolChoice{Type: "tool", Name: responseToolName}
+	}
+	return append(tools, tool), choice, wrapped, nil
+}
+
+// takeResponseTool moves the input of a final_response call into the
+// response content and drops the call, so callers see a plain answer.
+func takeResponseTool(response *llm.ChatResponse, wrapped bool) {
+	calls := response.ToolCalls[:0]
+	for _, call := range response.ToolCalls {
+		if call.Name != responseToolName {
+			calls = append(calls, call)
+			continue
+		}
+		content := call.Input
+		if wrapped {
+			var input struct {
+				Value json.RawMessage `json:"value"`
+			}
+			if err := json.Unmarshal([]byte(call.Input), &input); err == nil && len(input.Value) > 0 {
+				content = string(input.Value)
+			}
+		}
+		response.Content = content
+	}
+	if len(calls) == 0 {
+		calls = nil
+	}
+	response.ToolCalls = calls
+}
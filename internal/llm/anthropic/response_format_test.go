@@ -0,0 +1,62 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestChatAnswersThroughResponseTool(t *testing.T) {
+	var body anthropicRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(readFixture(t, "message_final_response.json"))
+	}))
+	defer server.Close()
+
+	client := NewClientWithBaseURL("test-key", "claude-sonnet-4-5", server.URL)
+	resp, err := client.Chat(context.Background(), &llm.ChatRequest{
+		Messages:       []llm.Message{{Role: "user", Content: "List outdated dependencies"}},
+		ResponseFormat: &llm.ResponseFormat{Schema: json.RawMessage(`{"type":"array","items":{"type":"object"}}`)},
+	})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	if len(body.Tools) != 1 || body.Tools[0].Name != responseToolName {
+		t.Fatalf("expected only the response tool, got %+v", body.Tools)
+	}
+	if body.Tools[0].InputSchema["type"] != "object" {
+		t.Fatalf("array schema should be wrapped in an object, got %+v", body.Tools[0].InputSchema)
+	}
+	if body.ToolChoice == nil || body.ToolChoice.Type != "tool" || body.ToolChoice.Name != responseToolName {
+		t.Fatalf("the response tool should be forced without other tools, got %+v", body.ToolChoice)
+	}
+	if resp.Content != `[{"latest":"1.10.2","name":"cobra"}]` || len(resp.ToolCalls) != 0 {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
+
+func TestResponseToolIsOptionalBesideOtherTools(t *testing.T) {
+	tools := []anthropicTool{{Name: "read", InputSchema: map[string]interface{}{"type": "object"}}}
+	format := &llm.ResponseFormat{Schema: json.RawMessage(`{"type":"object","properties":{"ok":{"type":"boolean"}}}`)}
+
+	tools, choice, wrapped, err := addResponseTool(tools, format)
+	if err != nil {
+		t.Fatalf("addResponseTool: %v", err)
+	}
+	if len(tools) != 2 || choice != nil || wrapped {
+		t.Fatalf("unexpected tools=%d choice=%+v wrapped=%t", len(tools), choice, wrapped)
+	}
+
+	resp := &llm.ChatResponse{Content: "Done.", ToolCalls: []llm.ToolCall{{ID: "1", Name: responseToolName, Input: `{"ok":true}`}}}
+	takeResponseTool(resp, wrapped)
+	if resp.Content != `{"ok":true}` || resp.ToolCalls != nil {
+		t.Fatalf("unexpected response %+v", resp)
+	}
+}
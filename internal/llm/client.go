@@ -2,6 +2,10 @@ package llm
 
 import (
 	"context"
+	"errors"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
 )
 
 // Client defines the interface for LLM providers
@@ -14,6 +18,25 @@ type StreamingClient interface {
 	ChatStream(ctx context.Context, request *ChatRequest, onEvent func(StreamEvent) error) (*ChatResponse, error)
 }
 
+// ModelLister is implemented by clients that can list the models their
+// provider serves.
+type ModelLister interface {
+	ListModelIDs(ctx context.Context) ([]string, error)
+}
+
+// ErrModelListingUnsupported is returned by ListModelIDs for clients that do
+// not implement ModelLister.
+var ErrModelListingUnsupported = errors.New("provider does not support listing models")
+
+// ListModelIDs lists the models served by client's provider.
+func ListModelIDs(ctx context.Context, client Client) ([]string, error) {
+	lister, ok := client.(ModelLister)
+	if !ok {
+		return nil, ErrModelListingUnsupported
+	}
+	return lister.ListModelIDs(ctx)
+}
+
 // ChatRequest represents a chat completion request
 type ChatRequest struct {
 	Model        string
@@ -22,6 +45,11 @@ type ChatRequest struct {
 	Temperature  float64
 	MaxTokens    int
 	SystemPrompt string
+	// StopSequences end the response when the model generates one of them.
+	StopSequences []string
+	// ResponseFormat, when set, asks for a final answer that is JSON
+	// matching a schema instead of prose.
+	ResponseFormat *ResponseFormat
 }
 
 // Message represents a chat message
@@ -31,6 +59,10 @@ type Message struct {
 	Images      []Image      `json:"images,omitempty"`
 	ToolCalls   []ToolCall   `json:"tool_calls,omitempty"`
 	ToolResults []ToolResult `json:"tool_results,omitempty"`
+	// Parts, when set, is the ordered multimodal content of the message and
+	// takes precedence over Content and Images. On tool messages it holds
+	// images returned by the tools, sent as the following user turn.
+	Parts []ContentPart `json:"parts,omitempty"`
 }
 
 // Image represents an image attachment passed to/from an LLM.
@@ -56,6 +88,11 @@ type ToolResult struct {
 	IsError    bool                   `json:"is_error,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	Name       string                 `json:"name,omitempty"` // Tool name (required by Gemini)
+	// Set by tools.Manager. OutputBytes is the size of the output before it
+	// was truncated to fit the model context.
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	OutputBytes int       `json:"output_bytes,omitempty"`
 }
 
 // ToolDefinition defines a tool for the LLM
@@ -72,6 +109,10 @@ type ChatResponse struct {
 	ToolCalls  []ToolCall
 	Usage      TokenUsage
 	StopReason string
+	// Provider and Model name who served the response when a wrapper such
+	// as a fallback chain picked among providers; empty otherwise.
+	Provider string
+	Model    string
 }
 
 // StreamEventType is the type of a streaming event.
@@ -115,3 +156,23 @@ type TokenUsage struct {
 	InputTokens  int
 	OutputTokens int
 }
+
+// ClampMaxTokens caps a response token limit at the provider's maximum,
+// logging a warning instead of letting the provider reject the request.
+func ClampMaxTokens(provider string, requested, limit int) int {
+	if limit > 0 && requested > limit {
+		logging.Warn("%s: max_tokens %d exceeds the provider maximum, using %d", provider, requested, limit)
+		return limit
+	}
+	return requested
+}
+
+// ClampStopSequences keeps the first limit stop sequences, logging a warning
+// when some are dropped.
+func ClampStopSequences(provider string, stops []string, limit int) []string {
+	if limit > 0 && len(stops) > limit {
+		logging.Warn("%s: %d stop sequences exceed the provider maximum of %d, ignoring the rest", provider, len(stops), limit)
+		return stops[:limit]
+	}
+	return stops
+}
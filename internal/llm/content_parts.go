@@ -0,0 +1,219 @@
+package llm
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Content part types.
+const (
+	ContentPartText  = "text"
+	ContentPartImage = "image"
+)
+
+// DefaultMaxImageBytes caps images read from disk for an image part whose
+// source does not set its own limit.
+const DefaultMaxImageBytes = 2 * 1024 * 1024
+
+// ContentPart is one piece of multimodal message content: text, or an image
+// given inline as base64, as a URL, or as a file read when the request is
+// built.
+type ContentPart struct {
+	Type       string `json:"type"`
+	Text       string `json:"text,omitempty"`
+	Name       string `json:"name,omitempty"`
+	MediaType  string `json:"media_type,omitempty"`
+	DataBase64 string `json:"data_base64,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Path       string `json:"path,omitempty"`
+	MaxBytes   int64  `json:"max_bytes,omitempty"` // limit for Path; 0 uses DefaultMaxImageBytes
+}
+
+// TextPart returns a text content part.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// ImagePart returns an image content part for img.
+func ImagePart(img Image) ContentPart {
+	return ContentPart{
+		Type:       ContentPartImage,
+		Name:       img.Name,
+		MediaType:  img.MediaType,
+		DataBase64: img.DataBase64,
+		URL:        img.URL,
+	}
+}
+
+// Image resolves an image part, reading Path when the part carries neither
+// inline data nor a URL.
+func (p ContentPart) Image() (Image, error) {
+	img := Image{Name: p.Name, MediaType: p.MediaType, DataBase64: strings.TrimSpace(p.DataBase64), URL: strings.TrimSpace(p.URL)}
+	if img.DataBase64 != "" || img.URL != "" {
+		return img, nil
+	}
+	path := strings.TrimSpace(p.Path)
+	if path == "" {
+		return Image{}, fmt.Errorf("image part has no data")
+	}
+	maxBytes := p.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxImageBytes
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return Image{}, err
+	}
+	if info.Size() > maxBytes {
+		return Image{}, fmt.Errorf("image %s is %d bytes, over the %d byte limit", path, info.Size(), maxBytes)
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return Image{}, err
+	}
+	if len(raw) == 0 {
+		return Image{}, fmt.Errorf("image %s is empty", path)
+	}
+	img.DataBase64 = base64.StdEncoding.EncodeToString(raw)
+	return img, nil
+}
+
+// Placeholder describes an image part in text, for models that cannot see it.
+func (p ContentPart) Placeholder() string {
+	label := strings.TrimSpace(p.Name)
+	if label == "" {
+		label = strings.TrimSpace(p.Path)
+	}
+	if label == "" {
+		label = strings.TrimSpace(p.MediaType)
+	}
+	if label == "" {
+		return "[image omitted: this model does not accept images]"
+	}
+	return fmt.Sprintf("[image %s omitted: this model does not accept images]", label)
+}
+
+// ContentParts returns the message content as parts: Parts when set,
+// otherwise Content followed by Images.
+func (m Message) ContentParts() []ContentPart {
+	if len(m.Parts) > 0 {
+		return m.Parts
+	}
+	parts := make([]ContentPart, 0, len(m.Images)+1)
+	if strings.TrimSpace(m.Content) != "" {
+		parts = append(parts, TextPart(m.Content))
+	}
+	for _, img := range m.Images {
+		parts = append(parts, ImagePart(img))
+	}
+	return parts
+}
+
+// HasImageParts reports whether the message carries any image.
+func (m Message) HasImageParts() bool {
+	if len(m.Images) > 0 {
+		return true
+	}
+	for _, part := range m.Parts {
+		if part.Type == ContentPartImage {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageParts returns the image parts of Parts.
+func (m Message) ImageParts() []ContentPart {
+	var images []ContentPart
+	for _, part := range m.Parts {
+		if part.Type == ContentPartImage {
+			images = append(images, part)
+		}
+	}
+	return images
+}
+
+// textOnlyModelMarkers name model families that reject image input.
+var textOnlyModelMarkers = []string{
+	"deepseek",
+	"qwen3-coder",
+	"qwen2.5-coder",
+	"codestral",
+	"gpt-3.5",
+	"gpt-oss",
+	"o1-mini",
+	"o3-mini",
+	"mixtral",
+	"llama-3",
+	"llama3",
+}
+
+// SupportsVision reports, from the model name, whether a model accepts
+// images. Unknown models are assumed to, since current frontier models do.
+func SupportsVision(model string) bool {
+	name := strings.ToLower(strings.TrimSpace(model))
+	if name == "" || strings.Contains(name, "vision") || strings.Contains(name, "-vl") {
+		return true
+	}
+	// Kimi K2 is text-only; K2.5 added vision.
+	if strings.HasPrefix(name, "kimi-k2") && !strings.HasPrefix(name, "kimi-k2.5") {
+		return false
+	}
+	for _, marker := range textOnlyModelMarkers {
+		if strings.Contains(name, marker) {
+			return false
+		}
+	}
+	return true
+}
+
+// InlineImageFromMetadata returns the image a tool attached to its result
+// under the image_inline metadata key: a media type plus base64 data or a
+// file path.
+func InlineImageFromMetadata(metadata map[string]interface{}) (ContentPart, bool) {
+	inline, ok := metadata["image_inline"].(map[string]interface{})
+	if !ok {
+		return ContentPart{}, false
+	}
+	part := ContentPart{Type: ContentPartImage}
+	part.MediaType, _ = inline["media_type"].(string)
+	part.DataBase64, _ = inline["data_base64"].(string)
+	part.Path, _ = inline["path"].(string)
+	part.MediaType = strings.TrimSpace(part.MediaType)
+	part.DataBase64 = strings.TrimSpace(part.DataBase64)
+	part.Path = strings.TrimSpace(part.Path)
+	if part.Path == "" {
+		if file, ok := metadata["image_file"].(map[string]interface{}); ok {
+			path, _ := file["path"].(string)
+			part.Path = strings.TrimSpace(path)
+		}
+	}
+	switch v := inline["max_bytes"].(type) {
+	case int:
+		part.MaxBytes = int64(v)
+	case int64:
+		part.MaxBytes = v
+	case float64:
+		part.MaxBytes = int64(v)
+	}
+	if tool, _ := inline["source_tool"].(string); tool != "" {
+		part.Name = tool
+	}
+	if part.MediaType == "" || (part.DataBase64 == "" && part.Path == "") {
+		return ContentPart{}, false
+	}
+	return part, true
+}
+
+// ToolImagesTurn returns the parts of the user turn that carries a tool
+// message's images to providers whose tool results are text only, or nil
+// when the message has no images.
+func (m Message) ToolImagesTurn() []ContentPart {
+	images := m.ImageParts()
+	if len(images) == 0 {
+		return nil
+	}
+	return append([]ContentPart{TextPart("Images returned by the tool calls above:")}, images...)
+}
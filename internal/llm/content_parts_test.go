@@ -0,0 +1,75 @@
+package llm
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSupportsVision(t *testing.T) {
+	cases := map[string]bool{
+		"":                         true,
+		"claude-sonnet-4-5":        true,
+		"gemini-2.5-flash":         true,
+		"kimi-k2.5":                true,
+		"kimi-k2-turbo-preview":    false,
+		"deepseek-chat":            false,
+		"qwen3-coder":              false,
+		"llama-3.2-11b-vision":     true,
+		"qwen2.5-vl-7b-instruct":   true,
+		"meta-llama/llama-3.3-70b": false,
+		"openai/gpt-oss-120b":      false,
+	}
+	for model, want := range cases {
+		if got := SupportsVision(model); got != want {
+			t.Errorf("SupportsVision(%q) = %t, want %t", model, got, want)
+		}
+	}
+}
+
+func TestInlineImageFromMetadataReadsPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "photo.jpg")
+	if err := os.WriteFile(path, []byte("jpeg-bytes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// max_bytes is a float64 once the metadata has been through JSON.
+	metadata := map[string]interface{}{
+		"image_inline": map[string]interface{}{
+			"path":        path,
+			"media_type":  "image/jpeg",
+			"max_bytes":   float64(1024),
+			"source_tool": "take_camera_photo",
+		},
+	}
+	part, ok := InlineImageFromMetadata(metadata)
+	if !ok || part.Type != ContentPartImage || part.Name != "take_camera_photo" || part.MaxBytes != 1024 {
+		t.Fatalf("unexpected part %+v (ok=%t)", part, ok)
+	}
+	img, err := part.Image()
+	if err != nil {
+		t.Fatalf("Image: %v", err)
+	}
+	if img.DataBase64 != base64.StdEncoding.EncodeToString([]byte("jpeg-bytes")) || img.MediaType != "image/jpeg" {
+		t.Fatalf("unexpected image %+v", img)
+	}
+
+	part.MaxBytes = 4
+	if _, err := part.Image(); err == nil {
+		t.Fatal("expected images over max_bytes to be refused")
+	}
+	if _, ok := InlineImageFromMetadata(map[string]interface{}{"image_file": map[string]interface{}{"path": path}}); ok {
+		t.Fatal("image_file alone is not an inline image")
+	}
+}
+
+func TestContentPartsFallsBackToContentAndImages(t *testing.T) {
+	msg := Message{Role: "user", Content: "what is this?", Images: []Image{{MediaType: "image/png", DataBase64: "AAAA"}}}
+	parts := msg.ContentParts()
+	if len(parts) != 2 || parts[0].Text != "what is this?" || parts[1].Type != ContentPartImage {
+		t.Fatalf("unexpected parts %+v", parts)
+	}
+	if !msg.HasImageParts() || (Message{Role: "tool"}).ToolImagesTurn() != nil {
+		t.Fatal("unexpected image detection")
+	}
+}
@@ -108,7 +108,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 				if i > 0 || attempt > 0 {
 					logging.Warn("Fallback chain recovered on provider %s (position %d, attempt %d)", node.Name, i+1, attempt+1)
 				}
-				return resp, nil
+				return servedBy(resp, node), nil
 			}
 
 			lastErr = err
@@ -208,7 +208,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 						Recovered:   i > 0 || attempt > 0,
 					})
 					c.setCurrentIndex(i)
-					return resp, nil
+					return servedBy(resp, node), nil
 				}
 				lastErr = err
 				reason := enrichTraceReason(ctx, err)
@@ -258,7 +258,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 					Recovered:   i > 0 || attempt > 0,
 				})
 				c.setCurrentIndex(i)
-				return resp, nil
+				return servedBy(resp, node), nil
 			}
 
 			lastErr = err
@@ -351,6 +351,16 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	return nil, fmt.Errorf("all fallback providers failed: %s", strings.Join(failures, " | "))
 }
 
+// servedBy records on resp which node answered, keeping what a nested chain
+// already recorded.
+func servedBy(resp *llm.ChatResponse, node Node) *llm.ChatResponse {
+	if resp != nil && resp.Provider == "" {
+		resp.Provider = node.Name
+		resp.Model = strings.TrimSpace(node.Model)
+	}
+	return resp
+}
+
 func (c *Client) currentIndex() int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -0,0 +1,57 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+type stubClient struct {
+	err    error
+	models []string
+}
+
+func (s *stubClient) Chat(_ context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	s.models = append(s.models, request.Model)
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &llm.ChatResponse{Content: "ok", Usage: llm.TokenUsage{InputTokens: 10, OutputTokens: 2}}, nil
+}
+
+func TestChatFailsOverAndReportsServingProvider(t *testing.T) {
+	primary := &stubClient{err: errors.New("API error (503): overloaded")}
+	backup := &stubClient{}
+	client := NewClient([]Node{
+		{Name: "kimi", Model: "kimi-k2", Client: primary},
+		{Name: "anthropic", Model: "claude-sonnet-4-5", Client: backup},
+	}, WithMaxRetries(0))
+
+	resp, err := client.Chat(context.Background(), &llm.ChatRequest{Model: "kimi-k2"})
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if resp.Provider != "anthropic" || resp.Model != "claude-sonnet-4-5" {
+		t.Fatalf("served by %s/%s, want anthropic/claude-sonnet-4-5", resp.Provider, resp.Model)
+	}
+	if len(backup.models) != 1 || backup.models[0] != "claude-sonnet-4-5" {
+		t.Fatalf("backup saw models %v, want the remapped model", backup.models)
+	}
+}
+
+func TestChatStopsOnNonFallbackableError(t *testing.T) {
+	backup := &stubClient{}
+	client := NewClient([]Node{
+		{Name: "kimi", Model: "kimi-k2", Client: &stubClient{err: errors.New("API error (400): invalid request")}},
+		{Name: "anthropic", Model: "claude-sonnet-4-5", Client: backup},
+	}, WithMaxRetries(0))
+
+	if _, err := client.Chat(context.Background(), &llm.ChatRequest{}); err == nil {
+		t.Fatal("expected the bad request error")
+	}
+	if len(backup.models) != 0 {
+		t.Fatalf("bad request should not fail over, backup called %d times", len(backup.models))
+	}
+}
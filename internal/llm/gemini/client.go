@@ -19,6 +19,8 @@ import (
 const (
 	defaultBaseURL   = "https://generativelanguage.googleapis.com/v1beta/openai"
 	defaultMaxTokens = 4096
+	maxOutputTokens  = 65536 // Gemini 2.5 output limit
+	maxStopSequences = 5
 )
 
 // Client implements the LLM client for Google Gemini (OpenAI-compatible API with Gemini extensions)
@@ -51,7 +53,21 @@ type geminiRequest struct {
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature float64         `json:"temperature,omitempty"`
 	Tools       []geminiTool    `json:"tools,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
 	Stream      bool            `json:"stream,omitempty"`
+
+	ResponseFormat *llm.OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// splitResponseFormat returns the system prompt and response_format for a
+// request. Gemini does not combine a JSON response schema with function
+// calling, so requests that offer tools carry the schema in the system
+// prompt instead.
+func splitResponseFormat(request *llm.ChatRequest) (string, *llm.OpenAIResponseFormat) {
+	if request.ResponseFormat != nil && len(request.Tools) > 0 {
+		return request.ResponseFormat.AppendInstructions(request.SystemPrompt), nil
+	}
+	return request.SystemPrompt, request.ResponseFormat.OpenAI()
 }
 
 type geminiMessage struct {
@@ -128,6 +144,11 @@ type geminiStreamResponse struct {
 		PromptTokens     int `json:"prompt_tokens"`
 		CompletionTokens int `json:"completion_tokens"`
 	} `json:"usage"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error,omitempty"`
 }
 
 // ModelInfo represents a single model from Gemini
@@ -184,19 +205,20 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 
 	maxTokens := defaultMaxTokens
 	if request.MaxTokens > 0 {
-		maxTokens = request.MaxTokens
+		maxTokens = llm.ClampMaxTokens("Gemini", request.MaxTokens, maxOutputTokens)
 	}
 
 	// Build messages
+	systemPrompt, responseFormat := splitResponseFormat(request)
 	var messages []geminiMessage
-	if request.SystemPrompt != "" {
+	if systemPrompt != "" {
 		messages = append(messages, geminiMessage{
 			Role:    "system",
-			Content: request.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
 
-	messages = append(messages, c.convertMessages(request.Messages)...)
+	messages = append(messages, c.convertMessages(request.Messages, llm.SupportsVision(model))...)
 
 	// Convert tools
 	var tools []geminiTool
@@ -216,11 +238,13 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	}
 
 	reqBody := geminiRequest{
-		Model:       model,
-		Messages:    messages,
-		MaxTokens:   maxTokens,
-		Temperature: request.Temperature,
-		Tools:       tools,
+		Model:          model,
+		Messages:       messages,
+		MaxTokens:      maxTokens,
+		Temperature:    request.Temperature,
+		Tools:          tools,
+		Stop:           llm.ClampStopSequences("Gemini", request.StopSequences, maxStopSequences),
+		ResponseFormat: responseFormat,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -309,19 +333,20 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 
 	maxTokens := defaultMaxTokens
 	if request.MaxTokens > 0 {
-		maxTokens = request.MaxTokens
+		maxTokens = llm.ClampMaxTokens("Gemini", request.MaxTokens, maxOutputTokens)
 	}
 
 	// Build messages
+	systemPrompt, responseFormat := splitResponseFormat(request)
 	var messages []geminiMessage
-	if request.SystemPrompt != "" {
+	if systemPrompt != "" {
 		messages = append(messages, geminiMessage{
 			Role:    "system",
-			Content: request.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
 
-	messages = append(messages, c.convertMessages(request.Messages)...)
+	messages = append(messages, c.convertMessages(request.Messages, llm.SupportsVision(model))...)
 
 	// Convert tools
 	var tools []geminiTool
@@ -341,12 +366,14 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	}
 
 	reqBody := geminiRequest{
-		Model:       model,
-		Messages:    messages,
-		MaxTokens:   maxTokens,
-		Temperature: request.Temperature,
-		Tools:       tools,
-		Stream:      true,
+		Model:          model,
+		Messages:       messages,
+		MaxTokens:      maxTokens,
+		Temperature:    request.Temperature,
+		Tools:          tools,
+		Stop:           llm.ClampStopSequences("Gemini", request.StopSequences, maxStopSequences),
+		ResponseFormat: responseFormat,
+		Stream:         true,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -377,98 +404,10 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 		return nil, err
 	}
 
-	result := &llm.ChatResponse{}
-	toolByIndex := map[int]int{}
-	scanner := bufio.NewScanner(resp.Body)
-	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if !strings.HasPrefix(line, "data:") {
-			continue
-		}
-		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
-		if payload == "" {
-			continue
-		}
-		if payload == "[DONE]" {
-			break
-		}
-
-		var chunk geminiStreamResponse
-		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
-			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
-		}
-
-		if chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0 {
-			result.Usage = llm.TokenUsage{
-				InputTokens:  chunk.Usage.PromptTokens,
-				OutputTokens: chunk.Usage.CompletionTokens,
-			}
-			if onEvent != nil {
-				if err := onEvent(llm.StreamEvent{Type: llm.StreamEventUsage, Usage: result.Usage}); err != nil {
-					return nil, err
-				}
-			}
-		}
-
-		for _, choice := range chunk.Choices {
-			if choice.Delta.Content != "" {
-				result.Content += choice.Delta.Content
-				if onEvent != nil {
-					if err := onEvent(llm.StreamEvent{
-						Type:         llm.StreamEventContentDelta,
-						ContentDelta: choice.Delta.Content,
-					}); err != nil {
-						return nil, err
-					}
-				}
-			}
-
-			for _, tc := range choice.Delta.ToolCalls {
-				idx, ok := toolByIndex[tc.Index]
-				if !ok {
-					result.ToolCalls = append(result.ToolCalls, llm.ToolCall{})
-					idx = len(result.ToolCalls) - 1
-					toolByIndex[tc.Index] = idx
-				}
-				if tc.ID != "" {
-					result.ToolCalls[idx].ID = tc.ID
-				}
-				if tc.Function.Name != "" {
-					result.ToolCalls[idx].Name = tc.Function.Name
-				}
-				if tc.Function.Arguments != "" {
-					result.ToolCalls[idx].Input = mergeToolArguments(result.ToolCalls[idx].Input, tc.Function.Arguments)
-				}
-				sigDelta := tc.Function.ThoughtSignature
-				if sigDelta == "" && tc.ExtraContent != nil && tc.ExtraContent.Google.ThoughtSignature != "" {
-					sigDelta = tc.ExtraContent.Google.ThoughtSignature
-				}
-				if sigDelta != "" {
-					result.ToolCalls[idx].ThoughtSignature += sigDelta
-				}
-				if onEvent != nil {
-					if err := onEvent(llm.StreamEvent{
-						Type:           llm.StreamEventToolCallDelta,
-						ToolCallIndex:  tc.Index,
-						ToolCallID:     tc.ID,
-						ToolCallName:   tc.Function.Name,
-						ToolInputDelta: tc.Function.Arguments,
-					}); err != nil {
-						return nil, err
-					}
-				}
-			}
-
-			if choice.FinishReason != "" {
-				result.StopReason = choice.FinishReason
-			}
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("stream read error: %w", err)
+	result, err := readStream(resp.Body, onEvent)
+	if err != nil {
+		logging.LogResponse(0, 0, 0, err)
+		return nil, err
 	}
 
 	toolNames := make([]string, len(result.ToolCalls))
@@ -481,7 +420,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 }
 
 // convertMessage converts an LLM message to Gemini format
-func (c *Client) convertMessage(msg llm.Message) []geminiMessage {
+func (c *Client) convertMessage(msg llm.Message, vision bool) []geminiMessage {
 	if msg.Role == "tool" {
 		// Tool results in Gemini format
 		var messages []geminiMessage
@@ -493,6 +432,9 @@ func (c *Client) convertMessage(msg llm.Message) []geminiMessage {
 				Name:       result.Name, // Required by Gemini
 			})
 		}
+		if turn := msg.ToolImagesTurn(); vision && turn != nil {
+			messages = append(messages, geminiMessage{Role: "user", Content: buildGeminiUserContent(turn, true)})
+		}
 		return messages
 	}
 
@@ -543,10 +485,10 @@ func (c *Client) convertMessage(msg llm.Message) []geminiMessage {
 	}
 
 	// Simple text message
-	if msg.Role == "user" && len(msg.Images) > 0 {
+	if msg.Role == "user" && msg.HasImageParts() {
 		return []geminiMessage{{
 			Role:    msg.Role,
-			Content: buildGeminiUserContent(msg.Content, msg.Images),
+			Content: buildGeminiUserContent(msg.ContentParts(), vision),
 		}}
 	}
 	return []geminiMessage{{
@@ -555,30 +497,48 @@ func (c *Client) convertMessage(msg llm.Message) []geminiMessage {
 	}}
 }
 
-func buildGeminiUserContent(text string, images []llm.Image) []map[string]interface{} {
-	parts := make([]map[string]interface{}, 0, len(images)+1)
-	if strings.TrimSpace(text) != "" {
-		parts = append(parts, map[string]interface{}{
-			"type": "text",
-			"text": text,
-		})
-	}
-	for _, img := range images {
-		url := strings.TrimSpace(img.URL)
+// buildGeminiUserContent converts content parts to chat-completions content. Models
+// without vision get plain text with a placeholder for each image.
+func buildGeminiUserContent(parts []llm.ContentPart, vision bool) any {
+	if !vision {
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part.Type == llm.ContentPartImage {
+				texts = append(texts, part.Placeholder())
+			} else if strings.TrimSpace(part.Text) != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	out := make([]map[string]interface{}, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != llm.ContentPartImage {
+			if strings.TrimSpace(part.Text) != "" {
+				out = append(out, map[string]interface{}{
+					"type": "text",
+					"text": part.Text,
+				})
+			}
+			continue
+		}
+		img, err := part.Image()
+		url := img.URL
 		if url == "" {
 			url = img.DataURL()
 		}
-		if url == "" {
+		if err != nil || url == "" {
+			logging.Warn("Skipping image part %s: %v", part.Name, err)
 			continue
 		}
-		parts = append(parts, map[string]interface{}{
+		out = append(out, map[string]interface{}{
 			"type": "image_url",
 			"image_url": map[string]interface{}{
 				"url": url,
 			},
 		})
 	}
-	return parts
+	return out
 }
 
 func parseGeminiContent(content any) (string, []llm.Image) {
@@ -631,7 +591,7 @@ func geminiAsString(v interface{}) string {
 	return s
 }
 
-func (c *Client) convertMessages(messages []llm.Message) []geminiMessage {
+func (c *Client) convertMessages(messages []llm.Message, vision bool) []geminiMessage {
 	if len(messages) == 0 {
 		return nil
 	}
@@ -642,7 +602,7 @@ func (c *Client) convertMessages(messages []llm.Message) []geminiMessage {
 	for _, msg := range messages {
 		switch msg.Role {
 		case "assistant":
-			converted := c.convertMessage(msg)
+			converted := c.convertMessage(msg, vision)
 			if len(converted) == 0 {
 				continue
 			}
@@ -671,9 +631,9 @@ func (c *Client) convertMessages(messages []llm.Message) []geminiMessage {
 			}
 			tmp := msg
 			tmp.ToolResults = filtered
-			out = append(out, c.convertMessage(tmp)...)
+			out = append(out, c.convertMessage(tmp, vision)...)
 		default:
-			out = append(out, c.convertMessage(msg)...)
+			out = append(out, c.convertMessage(msg, vision)...)
 		}
 	}
 
@@ -686,13 +646,126 @@ func mergeToolArguments(existing, incoming string) string {
 		return existing
 	}
 	// Some Gemini streaming responses send the full current JSON arguments
-	// snapshot in each chunk, not only a delta.
-	if json.Valid([]byte(trimmedIncoming)) {
+	// snapshot in each chunk, not only a delta. A delta can be valid JSON on
+	// its own too ("\"main.go\"" or a nested object), so only a whole object
+	// that does not continue a partial one replaces what came before.
+	isSnapshot := strings.HasPrefix(trimmedIncoming, "{") && json.Valid([]byte(trimmedIncoming)) &&
+		(existing == "" || json.Valid([]byte(existing)) || strings.HasPrefix(trimmedIncoming, strings.TrimSpace(existing)))
+	if isSnapshot {
 		return trimmedIncoming
 	}
 	return existing + incoming
 }
 
+// readStream assembles an OpenAI-compatible chat completion stream into the
+// response the non-streaming Chat would have returned.
+func readStream(body io.Reader, onEvent func(llm.StreamEvent) error) (*llm.ChatResponse, error) {
+	emit := func(ev llm.StreamEvent) error {
+		if onEvent == nil {
+			return nil
+		}
+		return onEvent(ev)
+	}
+
+	result := &llm.ChatResponse{}
+	toolByIndex := map[int]int{}
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 8*1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		payload := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if payload == "" {
+			continue
+		}
+		if payload == "[DONE]" {
+			break
+		}
+
+		var chunk geminiStreamResponse
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return nil, fmt.Errorf("Gemini stream error (%d %s): %s", chunk.Error.Code, chunk.Error.Status, chunk.Error.Message)
+		}
+
+		if chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0 {
+			result.Usage = llm.TokenUsage{
+				InputTokens:  chunk.Usage.PromptTokens,
+				OutputTokens: chunk.Usage.CompletionTokens,
+			}
+			if err := emit(llm.StreamEvent{Type: llm.StreamEventUsage, Usage: result.Usage}); err != nil {
+				return nil, err
+			}
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				result.Content += choice.Delta.Content
+				if err := emit(llm.StreamEvent{
+					Type:         llm.StreamEventContentDelta,
+					ContentDelta: choice.Delta.Content,
+				}); err != nil {
+					return nil, err
+				}
+			}
+
+			for _, tc := range choice.Delta.ToolCalls {
+				idx, ok := toolByIndex[tc.Index]
+				// Gemini sends parallel calls as separate chunks that may all
+				// carry index 0; a new ID starts a new call.
+				if ok && tc.ID != "" && result.ToolCalls[idx].ID != "" && result.ToolCalls[idx].ID != tc.ID {
+					ok = false
+				}
+				if !ok {
+					result.ToolCalls = append(result.ToolCalls, llm.ToolCall{})
+					idx = len(result.ToolCalls) - 1
+					toolByIndex[tc.Index] = idx
+				}
+				if tc.ID != "" {
+					result.ToolCalls[idx].ID = tc.ID
+				}
+				if tc.Function.Name != "" {
+					result.ToolCalls[idx].Name = tc.Function.Name
+				}
+				if tc.Function.Arguments != "" {
+					result.ToolCalls[idx].Input = mergeToolArguments(result.ToolCalls[idx].Input, tc.Function.Arguments)
+				}
+				sigDelta := tc.Function.ThoughtSignature
+				if sigDelta == "" && tc.ExtraContent != nil && tc.ExtraContent.Google.ThoughtSignature != "" {
+					sigDelta = tc.ExtraContent.Google.ThoughtSignature
+				}
+				if sigDelta != "" {
+					result.ToolCalls[idx].ThoughtSignature += sigDelta
+				}
+				if err := emit(llm.StreamEvent{
+					Type:           llm.StreamEventToolCallDelta,
+					ToolCallIndex:  idx,
+					ToolCallID:     result.ToolCalls[idx].ID,
+					ToolCallName:   result.ToolCalls[idx].Name,
+					ToolInputDelta: tc.Function.Arguments,
+				}); err != nil {
+					return nil, err
+				}
+			}
+
+			if choice.FinishReason != "" {
+				result.StopReason = choice.FinishReason
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("stream read error: %w", err)
+	}
+	return result, nil
+}
+
 // Ensure Client implements llm.Client
 var _ llm.Client = (*Client)(nil)
 var _ llm.StreamingClient = (*Client)(nil)
+var _ llm.ModelLister = (*Client)(nil)
@@ -0,0 +1,117 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestChatStreamMatchesChat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Stream bool `json:"stream"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			serveFixture(t, w, "stream_tool_calls.sse")
+			return
+		}
+		serveFixture(t, w, "chat_tool_calls.json")
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key", "gemini-2.5-flash", server.URL)
+	request := &llm.ChatRequest{Messages: []llm.Message{{Role: "user", Content: "Compare main.go and go.mod"}}}
+
+	want, err := client.Chat(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+
+	var content strings.Builder
+	got, err := client.ChatStream(context.Background(), request, func(ev llm.StreamEvent) error {
+		if ev.Type == llm.StreamEventContentDelta {
+			content.WriteString(ev.ContentDelta)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ChatStream: %v", err)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("streamed response differs from Chat:\n got %+v\nwant %+v", got, want)
+	}
+	if len(got.ToolCalls) != 2 || got.ToolCalls[0].Input != `{"path":"main.go"}` {
+		t.Fatalf("unexpected tool calls %+v", got.ToolCalls)
+	}
+	if content.String() != "Let me look at both files." {
+		t.Fatalf("unexpected content deltas %q", content.String())
+	}
+}
+
+func TestMergeToolArguments(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		incoming string
+		want     string
+	}{
+		{name: "first chunk", incoming: `{"path":`, want: `{"path":`},
+		{name: "string delta", existing: `{"path":`, incoming: `"main.go"`, want: `{"path":"main.go"`},
+		{name: "nested object delta", existing: `{"opts":`, incoming: `{"a":1}`, want: `{"opts":{"a":1}`},
+		{name: "snapshot replaces snapshot", existing: `{"path":"m"}`, incoming: `{"path":"main.go"}`, want: `{"path":"main.go"}`},
+		{name: "snapshot extends partial", existing: `{"path":"ma`, incoming: `{"path":"main.go"}`, want: `{"path":"main.go"}`},
+		{name: "empty delta", existing: `{"a":1}`, incoming: " ", want: `{"a":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergeToolArguments(tt.existing, tt.incoming); got != tt.want {
+				t.Errorf("mergeToolArguments(%q, %q) = %q, want %q", tt.existing, tt.incoming, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadStreamReturnsErrorChunk(t *testing.T) {
+	stream := `data: {"choices":[{"delta":{"content":"Hi"},"index":0}]}
+
+data: {"error":{"code":429,"message":"Resource has been exhausted","status":"RESOURCE_EXHAUSTED"}}
+`
+	_, err := readStream(strings.NewReader(stream), nil)
+	if err == nil || !strings.Contains(err.Error(), "RESOURCE_EXHAUSTED") {
+		t.Fatalf("expected the quota error, got %v", err)
+	}
+}
+
+func TestConvertMessagesSendsToolImagesAsUserTurn(t *testing.T) {
+	client := NewClient("test-key", "gemini-2.5-flash", "http://unused")
+	messages := []llm.Message{
+		{Role: "assistant", ToolCalls: []llm.ToolCall{{ID: "call_1", Name: "take_camera_photo", Input: `{}`, ThoughtSignature: "sig"}}},
+		{
+			Role:        "tool",
+			ToolResults: []llm.ToolResult{{ToolCallID: "call_1", Name: "take_camera_photo", Content: `{"ok":true}`}},
+			Parts:       []llm.ContentPart{{Type: llm.ContentPartImage, MediaType: "image/jpeg", DataBase64: "AAAA"}},
+		},
+	}
+
+	got := client.convertMessages(messages, true)
+	if len(got) != 3 || got[1].Role != "tool" || got[2].Role != "user" {
+		t.Fatalf("unexpected messages %+v", got)
+	}
+	parts, ok := got[2].Content.([]map[string]interface{})
+	if !ok || len(parts) != 2 || parts[1]["type"] != "image_url" {
+		t.Fatalf("unexpected image turn %+v", got[2].Content)
+	}
+
+	if got := client.convertMessages(messages, false); len(got) != 2 {
+		t.Fatalf("text-only models should not get the image turn, got %+v", got)
+	}
+}
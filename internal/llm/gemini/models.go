@@ -6,13 +6,31 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/A2gent/brute/internal/logging"
 )
 
 // ListModels fetches available models from Gemini API with fallback
 func ListModels(apiKey, baseURL string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return ListModelsWithContext(ctx, apiKey, baseURL)
+}
+
+// googleAPIHost serves both the native Generative Language API and its
+// OpenAI-compatible endpoint under /openai.
+const googleAPIHost = "generativelanguage.googleapis.com"
+
+// maxModelPages bounds how many pages of the native models list are read.
+const maxModelPages = 10
+
+// ListModelsWithContext fetches models with context support
+func ListModelsWithContext(ctx context.Context, apiKey, baseURL string) ([]string, error) {
 	if apiKey == "" {
-		// Return fallback list if no API key
 		return fallbackModels(), nil
 	}
 
@@ -21,95 +39,142 @@ func ListModels(apiKey, baseURL string) ([]string, error) {
 	}
 
 	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", baseURL+"/models", nil)
-	if err != nil {
+	endpoint, native := modelsEndpoint(baseURL)
+	var models []string
+	var err error
+	if native {
+		models, err = listNativeModels(ctx, client, endpoint, apiKey)
+	} else {
+		models, err = listOpenAIModels(ctx, client, endpoint, apiKey)
+	}
+	if err != nil || len(models) == 0 {
+		logging.Warn("Gemini model listing failed, using known models: %v", err)
 		return fallbackModels(), nil
 	}
+	return models, nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	resp, err := client.Do(req)
+// modelsEndpoint returns the models URL for baseURL and whether it is the
+// native API. Google's own host is always listed through the native
+// v1beta/models endpoint, which a plain API key authenticates against and
+// which reports the generation methods of each model. Other hosts are native
+// when the base URL ends in an API version (v1, v1beta) and OpenAI-compatible
+// otherwise.
+func modelsEndpoint(baseURL string) (string, bool) {
+	trimmed := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	parsed, err := url.Parse(trimmed)
 	if err != nil {
-		// If API fails, return fallback list
-		return fallbackModels(), nil
+		return trimmed + "/models", false
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		// If not authorized or error, return fallback
-		return fallbackModels(), nil
+	if parsed.Host == googleAPIHost {
+		parsed.Path = strings.TrimSuffix(strings.TrimRight(parsed.Path, "/"), "/openai")
+		if parsed.Path == "" {
+			parsed.Path = "/v1beta"
+		}
+		return parsed.String() + "/models", true
 	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fallbackModels(), nil
+	segment := parsed.Path[strings.LastIndex(parsed.Path, "/")+1:]
+	switch segment {
+	case "v1", "v1beta", "v1alpha":
+		return trimmed + "/models", true
 	}
+	return trimmed + "/models", false
+}
 
-	var modelsResp ModelsResponse
-	if err := json.Unmarshal(body, &modelsResp); err != nil {
-		return fallbackModels(), nil
-	}
+// nativeModelsResponse is a page of the native GET /v1beta/models response.
+type nativeModelsResponse struct {
+	Models []struct {
+		Name                       string   `json:"name"`
+		SupportedGenerationMethods []string `json:"supportedGenerationMethods"`
+	} `json:"models"`
+	NextPageToken string `json:"nextPageToken"`
+}
 
-	if len(modelsResp.Data) == 0 {
-		return fallbackModels(), nil
-	}
+// listNativeModels lists the models that support generateContent, following
+// page tokens. Names are returned without their "models/" prefix.
+func listNativeModels(ctx context.Context, client *http.Client, endpoint, apiKey string) ([]string, error) {
+	var models []string
+	pageToken := ""
+	for page := 0; page < maxModelPages; page++ {
+		query := url.Values{"pageSize": {"1000"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("x-goog-api-key", apiKey)
 
-	models := make([]string, 0, len(modelsResp.Data))
-	for _, model := range modelsResp.Data {
-		models = append(models, model.ID)
-	}
+		var modelsResp nativeModelsResponse
+		if err := getJSON(client, req, &modelsResp); err != nil {
+			return nil, err
+		}
+		for _, model := range modelsResp.Models {
+			if !slices.Contains(model.SupportedGenerationMethods, "generateContent") {
+				continue
+			}
+			if id := strings.TrimPrefix(model.Name, "models/"); id != "" {
+				models = append(models, id)
+			}
+		}
 
+		pageToken = modelsResp.NextPageToken
+		if pageToken == "" {
+			break
+		}
+	}
 	return models, nil
 }
 
-// ListModelsWithContext fetches models with context support
-func ListModelsWithContext(ctx context.Context, apiKey, baseURL string) ([]string, error) {
-	if apiKey == "" {
-		return fallbackModels(), nil
+// listOpenAIModels lists models through the OpenAI-compatible endpoint,
+// which authenticates with a bearer token.
+func listOpenAIModels(ctx context.Context, client *http.Client, endpoint, apiKey string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
 
-	if baseURL == "" {
-		baseURL = defaultBaseURL
+	var modelsResp ModelsResponse
+	if err := getJSON(client, req, &modelsResp); err != nil {
+		return nil, err
 	}
-
-	req, err := http.NewRequestWithContext(ctx, "GET", baseURL+"/models", nil)
-	if err != nil {
-		return fallbackModels(), nil
+	models := make([]string, 0, len(modelsResp.Data))
+	for _, model := range modelsResp.Data {
+		if id := strings.TrimPrefix(model.ID, "models/"); id != "" {
+			models = append(models, id)
+		}
 	}
+	return models, nil
+}
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	client := &http.Client{Timeout: 10 * time.Second}
+func getJSON(client *http.Client, req *http.Request, out interface{}) error {
 	resp, err := client.Do(req)
 	if err != nil {
-		return fallbackModels(), nil
+		return fmt.Errorf("failed to connect to Gemini: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fallbackModels(), nil
-	}
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fallbackModels(), nil
-	}
-
-	var modelsResp ModelsResponse
-	if err := json.Unmarshal(body, &modelsResp); err != nil {
-		return fallbackModels(), nil
+		return fmt.Errorf("failed to read response: %w", err)
 	}
-
-	if len(modelsResp.Data) == 0 {
-		return fallbackModels(), nil
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Gemini returned error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
-
-	models := make([]string, 0, len(modelsResp.Data))
-	for _, model := range modelsResp.Data {
-		models = append(models, model.ID)
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to parse models response: %w", err)
 	}
+	return nil
+}
 
-	return models, nil
+// ListModelIDs lists the models served by the client's endpoint, falling back
+// to the known Gemini models when the endpoint cannot be queried.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	return ListModelsWithContext(ctx, c.apiKey, c.baseURL)
 }
 
 // fallbackModels returns a static list of known Gemini models
@@ -0,0 +1,113 @@
+package gemini
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func serveFixture(t *testing.T, w http.ResponseWriter, name string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func TestModelsEndpoint(t *testing.T) {
+	tests := []struct {
+		baseURL    string
+		wantURL    string
+		wantNative bool
+	}{
+		{"https://generativelanguage.googleapis.com/v1beta/openai", "https://generativelanguage.googleapis.com/v1beta/models", true},
+		{"https://generativelanguage.googleapis.com/v1beta/openai/", "https://generativelanguage.googleapis.com/v1beta/models", true},
+		{"https://generativelanguage.googleapis.com/v1beta", "https://generativelanguage.googleapis.com/v1beta/models", true},
+		{"https://generativelanguage.googleapis.com", "https://generativelanguage.googleapis.com/v1beta/models", true},
+		{"http://localhost:8080/v1beta", "http://localhost:8080/v1beta/models", true},
+		{"http://localhost:8080/v1beta/openai", "http://localhost:8080/v1beta/openai/models", false},
+		{"https://proxy.example.com/providers/google", "https://proxy.example.com/providers/google/models", false},
+	}
+	for _, tt := range tests {
+		gotURL, gotNative := modelsEndpoint(tt.baseURL)
+		if gotURL != tt.wantURL || gotNative != tt.wantNative {
+			t.Errorf("modelsEndpoint(%q) = (%q, %t), want (%q, %t)", tt.baseURL, gotURL, gotNative, tt.wantURL, tt.wantNative)
+		}
+	}
+}
+
+func TestListModelsNativeAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/models" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("x-goog-api-key"); got != "test-key" {
+			http.Error(w, `{"error":{"code":403,"message":"Method doesn't allow unregistered callers"}}`, http.StatusForbidden)
+			return
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("native request sent an Authorization header")
+		}
+		if r.URL.Query().Get("pageToken") == "" {
+			serveFixture(t, w, "native_models.json")
+		} else {
+			serveFixture(t, w, "native_models_page2.json")
+		}
+	}))
+	defer server.Close()
+
+	models, err := ListModelsWithContext(context.Background(), "test-key", server.URL+"/v1beta")
+	if err != nil {
+		t.Fatalf("ListModelsWithContext: %v", err)
+	}
+	want := []string{"gemini-2.5-pro", "gemini-2.5-flash", "gemini-2.0-flash"}
+	if !slices.Equal(models, want) {
+		t.Fatalf("models = %v, want %v", models, want)
+	}
+}
+
+func TestListModelsOpenAICompatibleAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1beta/openai/models" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		serveFixture(t, w, "openai_models.json")
+	}))
+	defer server.Close()
+
+	models, err := ListModelsWithContext(context.Background(), "test-key", server.URL+"/v1beta/openai")
+	if err != nil {
+		t.Fatalf("ListModelsWithContext: %v", err)
+	}
+	want := []string{"gemini-2.5-pro", "gemini-2.5-flash"}
+	if !slices.Equal(models, want) {
+		t.Fatalf("models = %v, want %v", models, want)
+	}
+}
+
+func TestListModelsFallsBackOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	models, err := ListModelsWithContext(context.Background(), "test-key", server.URL+"/v1beta")
+	if err != nil {
+		t.Fatalf("ListModelsWithContext: %v", err)
+	}
+	if !slices.Equal(models, fallbackModels()) {
+		t.Fatalf("expected the known models, got %v", models)
+	}
+}
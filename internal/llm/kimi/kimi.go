@@ -18,6 +18,8 @@ import (
 const (
 	defaultBaseURL = "https://api.moonshot.cn/v1"
 	defaultModel   = "kimi-k2.5"
+	// maxOutputTokens is the largest max_tokens the Kimi API accepts.
+	maxOutputTokens = 32768
 )
 
 // Client implements the LLM client for Kimi K2.5 (Moonshot AI)
@@ -50,8 +52,20 @@ type kimiRequest struct {
 	Temperature float64       `json:"temperature,omitempty"`
 	MaxTokens   int           `json:"max_tokens,omitempty"`
 	Tools       []kimiTool    `json:"tools,omitempty"`
+	Stop        []string      `json:"stop,omitempty"`
 	ToolChoice  string        `json:"tool_choice,omitempty"`
 	Stream      bool          `json:"stream,omitempty"`
+
+	ResponseFormat *llm.OpenAIResponseFormat `json:"response_format,omitempty"`
+}
+
+// jsonMode turns on Kimi's JSON mode for a response format. Kimi does not
+// take a schema, so the schema goes into the system prompt instead.
+func jsonMode(format *llm.ResponseFormat) *llm.OpenAIResponseFormat {
+	if format == nil {
+		return nil
+	}
+	return &llm.OpenAIResponseFormat{Type: "json_object"}
 }
 
 type kimiMessage struct {
@@ -128,6 +142,43 @@ type kimiError struct {
 	} `json:"error"`
 }
 
+// knownModels is returned when the models endpoint cannot be queried.
+var knownModels = []string{"kimi-k2.5", "kimi-k2", "kimi-for-coding"}
+
+// ListModelIDs lists the models served by the Kimi API, falling back to the
+// known models when the endpoint cannot be queried.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	fallback := append([]string(nil), knownModels...)
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/models", nil)
+	if err != nil || c.apiKey == "" {
+		return fallback, nil
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return fallback, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fallback, nil
+	}
+
+	var modelsResp struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&modelsResp); err != nil || len(modelsResp.Data) == 0 {
+		return fallback, nil
+	}
+	ids := make([]string, 0, len(modelsResp.Data))
+	for _, model := range modelsResp.Data {
+		ids = append(ids, model.ID)
+	}
+	return ids, nil
+}
+
 // Chat sends a chat request to Kimi API
 func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
 	model := request.Model
@@ -146,15 +197,15 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	messages := make([]kimiMessage, 0, len(request.Messages)+1)
 
 	// Add system prompt if present
-	if request.SystemPrompt != "" {
+	if systemPrompt := request.ResponseFormat.AppendInstructions(request.SystemPrompt); systemPrompt != "" {
 		messages = append(messages, kimiMessage{
 			Role:    "system",
-			Content: request.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
 
 	for _, msg := range request.Messages {
-		messages = append(messages, c.convertMessage(msg)...)
+		messages = append(messages, c.convertMessage(msg, llm.SupportsVision(model))...)
 	}
 
 	// Convert tools
@@ -171,11 +222,13 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	}
 
 	reqBody := kimiRequest{
-		Model:       model,
-		Messages:    messages,
-		Temperature: request.Temperature,
-		MaxTokens:   request.MaxTokens,
-		Tools:       tools,
+		Model:          model,
+		Messages:       messages,
+		Temperature:    request.Temperature,
+		MaxTokens:      llm.ClampMaxTokens("Kimi", request.MaxTokens, maxOutputTokens),
+		Tools:          tools,
+		Stop:           llm.ClampStopSequences("Kimi", request.StopSequences, 5),
+		ResponseFormat: jsonMode(request.ResponseFormat),
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -266,14 +319,14 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	logging.LogRequestWithContent(model, len(request.Messages), len(request.Tools) > 0, lastMsg)
 
 	messages := make([]kimiMessage, 0, len(request.Messages)+1)
-	if request.SystemPrompt != "" {
+	if systemPrompt := request.ResponseFormat.AppendInstructions(request.SystemPrompt); systemPrompt != "" {
 		messages = append(messages, kimiMessage{
 			Role:    "system",
-			Content: request.SystemPrompt,
+			Content: systemPrompt,
 		})
 	}
 	for _, msg := range request.Messages {
-		messages = append(messages, c.convertMessage(msg)...)
+		messages = append(messages, c.convertMessage(msg, llm.SupportsVision(model))...)
 	}
 
 	tools := make([]kimiTool, 0, len(request.Tools))
@@ -289,12 +342,14 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	}
 
 	reqBody := kimiRequest{
-		Model:       model,
-		Messages:    messages,
-		Temperature: request.Temperature,
-		MaxTokens:   request.MaxTokens,
-		Tools:       tools,
-		Stream:      true,
+		Model:          model,
+		Messages:       messages,
+		Temperature:    request.Temperature,
+		MaxTokens:      llm.ClampMaxTokens("Kimi", request.MaxTokens, maxOutputTokens),
+		Tools:          tools,
+		Stop:           llm.ClampStopSequences("Kimi", request.StopSequences, 5),
+		ResponseFormat: jsonMode(request.ResponseFormat),
+		Stream:         true,
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -423,7 +478,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 
 // convertMessage converts an LLM message to Kimi format.
 // For tool role messages, Kimi expects one message per tool result.
-func (c *Client) convertMessage(msg llm.Message) []kimiMessage {
+func (c *Client) convertMessage(msg llm.Message, vision bool) []kimiMessage {
 	if msg.Role == "tool" {
 		// Tool results - Kimi uses "tool" role for results
 		if len(msg.ToolResults) > 0 {
@@ -435,6 +490,9 @@ func (c *Client) convertMessage(msg llm.Message) []kimiMessage {
 					ToolCallID: result.ToolCallID,
 				})
 			}
+			if turn := msg.ToolImagesTurn(); vision && turn != nil {
+				out = append(out, kimiMessage{Role: "user", Content: buildKimiUserContent(turn, true)})
+			}
 			return out
 		}
 		return []kimiMessage{{
@@ -467,10 +525,10 @@ func (c *Client) convertMessage(msg llm.Message) []kimiMessage {
 	}
 
 	// Simple text message
-	if msg.Role == "user" && len(msg.Images) > 0 {
+	if msg.Role == "user" && msg.HasImageParts() {
 		return []kimiMessage{{
 			Role:    msg.Role,
-			Content: buildKimiUserContent(msg.Content, msg.Images),
+			Content: buildKimiUserContent(msg.ContentParts(), vision),
 		}}
 	}
 	return []kimiMessage{{
@@ -479,30 +537,48 @@ func (c *Client) convertMessage(msg llm.Message) []kimiMessage {
 	}}
 }
 
-func buildKimiUserContent(text string, images []llm.Image) []map[string]interface{} {
-	parts := make([]map[string]interface{}, 0, len(images)+1)
-	if strings.TrimSpace(text) != "" {
-		parts = append(parts, map[string]interface{}{
-			"type": "text",
-			"text": text,
-		})
-	}
-	for _, img := range images {
-		url := strings.TrimSpace(img.URL)
+// buildKimiUserContent converts content parts to chat-completions content. Models
+// without vision get plain text with a placeholder for each image.
+func buildKimiUserContent(parts []llm.ContentPart, vision bool) any {
+	if !vision {
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part.Type == llm.ContentPartImage {
+				texts = append(texts, part.Placeholder())
+			} else if strings.TrimSpace(part.Text) != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	out := make([]map[string]interface{}, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != llm.ContentPartImage {
+			if strings.TrimSpace(part.Text) != "" {
+				out = append(out, map[string]interface{}{
+					"type": "text",
+					"text": part.Text,
+				})
+			}
+			continue
+		}
+		img, err := part.Image()
+		url := img.URL
 		if url == "" {
 			url = img.DataURL()
 		}
-		if url == "" {
+		if err != nil || url == "" {
+			logging.Warn("Skipping image part %s: %v", part.Name, err)
 			continue
 		}
-		parts = append(parts, map[string]interface{}{
+		out = append(out, map[string]interface{}{
 			"type": "image_url",
 			"image_url": map[string]interface{}{
 				"url": url,
 			},
 		})
 	}
-	return parts
+	return out
 }
 
 func parseKimiContent(content any) (string, []llm.Image) {
@@ -558,3 +634,4 @@ func kimiAsString(v interface{}) string {
 // Ensure Client implements llm.Client
 var _ llm.Client = (*Client)(nil)
 var _ llm.StreamingClient = (*Client)(nil)
+var _ llm.ModelLister = (*Client)(nil)
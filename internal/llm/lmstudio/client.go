@@ -72,7 +72,10 @@ type openAIRequest struct {
 	MaxTokens   int             `json:"max_tokens,omitempty"`
 	Temperature float64         `json:"temperature,omitempty"`
 	Tools       []openAITool    `json:"tools,omitempty"`
+	Stop        []string        `json:"stop,omitempty"`
 	Stream      bool            `json:"stream,omitempty"`
+
+	ResponseFormat *llm.OpenAIResponseFormat `json:"response_format,omitempty"`
 }
 
 type openAIMessage struct {
@@ -192,6 +195,44 @@ func (c *Client) ListModels(ctx context.Context) ([]ModelInfo, error) {
 	return modelsResp.Data, nil
 }
 
+// stopSequences applies OpenAI's limit of four stop sequences; local and
+// proxied servers accept more.
+func (c *Client) stopSequences(stops []string) []string {
+	if c.providerName() == "OpenAI" {
+		return llm.ClampStopSequences("OpenAI", stops, 4)
+	}
+	return stops
+}
+
+// maxOutputTokens returns the largest max_tokens the provider accepts, or 0
+// for local servers and routers, where it depends on the model.
+func (c *Client) maxOutputTokens() int {
+	switch c.providerName() {
+	case "OpenAI":
+		return 128000 // GPT-5 output limit
+	case "Gemini":
+		return 65536 // Gemini 2.5 output limit
+	case "Kimi":
+		return 32768
+	}
+	return 0
+}
+
+// ListModelIDs lists the IDs of the models the server serves.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	models, err := c.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(models))
+	for _, model := range models {
+		if id := strings.TrimSpace(model.ID); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
 // Chat sends a chat request to LM Studio
 func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
 	model := request.Model
@@ -203,6 +244,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	if maxTokens == 0 {
 		maxTokens = defaultMaxTokens
 	}
+	maxTokens = llm.ClampMaxTokens(c.providerName(), maxTokens, c.maxOutputTokens())
 
 	// Log request
 	lastMsg := ""
@@ -223,7 +265,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	}
 
 	for _, msg := range request.Messages {
-		oaiMsg := c.convertMessage(msg)
+		oaiMsg := c.convertMessage(msg, llm.SupportsVision(model))
 		messages = append(messages, oaiMsg...)
 	}
 
@@ -245,11 +287,13 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 	}
 
 	reqBody := openAIRequest{
-		Model:       model,
-		Messages:    messages,
-		MaxTokens:   maxTokens,
-		Temperature: request.Temperature,
-		Tools:       tools,
+		Model:          model,
+		Messages:       messages,
+		MaxTokens:      maxTokens,
+		Temperature:    request.Temperature,
+		Tools:          tools,
+		Stop:           c.stopSequences(request.StopSequences),
+		ResponseFormat: request.ResponseFormat.OpenAI(),
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -340,6 +384,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	if maxTokens == 0 {
 		maxTokens = defaultMaxTokens
 	}
+	maxTokens = llm.ClampMaxTokens(c.providerName(), maxTokens, c.maxOutputTokens())
 
 	lastMsg := ""
 	if len(request.Messages) > 0 {
@@ -352,7 +397,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 		messages = append(messages, openAIMessage{Role: "system", Content: request.SystemPrompt})
 	}
 	for _, msg := range request.Messages {
-		messages = append(messages, c.convertMessage(msg)...)
+		messages = append(messages, c.convertMessage(msg, llm.SupportsVision(model))...)
 	}
 
 	var tools []openAITool
@@ -372,12 +417,14 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	}
 
 	reqBody := openAIRequest{
-		Model:       model,
-		Messages:    messages,
-		MaxTokens:   maxTokens,
-		Temperature: request.Temperature,
-		Tools:       tools,
-		Stream:      true,
+		Model:          model,
+		Messages:       messages,
+		MaxTokens:      maxTokens,
+		Temperature:    request.Temperature,
+		Tools:          tools,
+		Stop:           c.stopSequences(request.StopSequences),
+		Stream:         true,
+		ResponseFormat: request.ResponseFormat.OpenAI(),
 	}
 
 	jsonBody, err := json.Marshal(reqBody)
@@ -510,7 +557,7 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 }
 
 // convertMessage converts an LLM message to OpenAI format
-func (c *Client) convertMessage(msg llm.Message) []openAIMessage {
+func (c *Client) convertMessage(msg llm.Message, vision bool) []openAIMessage {
 	if msg.Role == "tool" {
 		// Tool results in OpenAI format
 		var messages []openAIMessage
@@ -521,6 +568,9 @@ func (c *Client) convertMessage(msg llm.Message) []openAIMessage {
 				ToolCallID: result.ToolCallID,
 			})
 		}
+		if turn := msg.ToolImagesTurn(); vision && turn != nil {
+			messages = append(messages, openAIMessage{Role: "user", Content: buildOpenAIUserContent(turn, true)})
+		}
 		return messages
 	}
 
@@ -577,10 +627,10 @@ func (c *Client) convertMessage(msg llm.Message) []openAIMessage {
 	}
 
 	// Simple text message
-	if msg.Role == "user" && len(msg.Images) > 0 {
+	if msg.Role == "user" && msg.HasImageParts() {
 		return []openAIMessage{{
 			Role:    msg.Role,
-			Content: buildOpenAIUserContent(msg.Content, msg.Images),
+			Content: buildOpenAIUserContent(msg.ContentParts(), vision),
 		}}
 	}
 	return []openAIMessage{{
@@ -589,36 +639,48 @@ func (c *Client) convertMessage(msg llm.Message) []openAIMessage {
 	}}
 }
 
-func buildOpenAIUserContent(text string, images []llm.Image) []map[string]interface{} {
-	parts := make([]map[string]interface{}, 0, len(images)+1)
-	if strings.TrimSpace(text) != "" {
-		parts = append(parts, map[string]interface{}{
-			"type": "text",
-			"text": text,
-		})
-	}
-	for _, img := range images {
-		url := strings.TrimSpace(img.URL)
+// buildOpenAIUserContent converts content parts to chat-completions content. Models
+// without vision get plain text with a placeholder for each image.
+func buildOpenAIUserContent(parts []llm.ContentPart, vision bool) any {
+	if !vision {
+		texts := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part.Type == llm.ContentPartImage {
+				texts = append(texts, part.Placeholder())
+			} else if strings.TrimSpace(part.Text) != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	out := make([]map[string]interface{}, 0, len(parts))
+	for _, part := range parts {
+		if part.Type != llm.ContentPartImage {
+			if strings.TrimSpace(part.Text) != "" {
+				out = append(out, map[string]interface{}{
+					"type": "text",
+					"text": part.Text,
+				})
+			}
+			continue
+		}
+		img, err := part.Image()
+		url := img.URL
 		if url == "" {
 			url = img.DataURL()
 		}
-		if url == "" {
+		if err != nil || url == "" {
+			logging.Warn("Skipping image part %s: %v", part.Name, err)
 			continue
 		}
-		parts = append(parts, map[string]interface{}{
+		out = append(out, map[string]interface{}{
 			"type": "image_url",
 			"image_url": map[string]interface{}{
 				"url": url,
 			},
 		})
 	}
-	if len(parts) == 0 && strings.TrimSpace(text) != "" {
-		parts = append(parts, map[string]interface{}{
-			"type": "text",
-			"text": text,
-		})
-	}
-	return parts
+	return out
 }
 
 func parseOpenAIContent(content any) (string, []llm.Image) {
@@ -674,3 +736,4 @@ func asString(v interface{}) string {
 // Ensure Client implements llm.Client
 var _ llm.Client = (*Client)(nil)
 var _ llm.StreamingClient = (*Client)(nil)
+var _ llm.ModelLister = (*Client)(nil)
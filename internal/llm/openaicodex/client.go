@@ -34,10 +34,33 @@ type responsesRequest struct {
 	Instructions string               `json:"instructions,omitempty"`
 	Input        []responsesInputItem `json:"input"`
 	Tools        []responsesTool      `json:"tools,omitempty"`
+	Text         *responsesText       `json:"text,omitempty"`
 	Store        bool                 `json:"store"`
 	Stream       bool                 `json:"stream"`
 }
 
+// responsesText carries the structured output format of a Responses request.
+type responsesText struct {
+	Format responsesTextFormat `json:"format"`
+}
+
+type responsesTextFormat struct {
+	Type   string          `json:"type"`
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+func textFormat(format *llm.ResponseFormat) *responsesText {
+	if format == nil {
+		return nil
+	}
+	return &responsesText{Format: responsesTextFormat{
+		Type:   "json_schema",
+		Name:   format.SchemaName(),
+		Schema: format.Schema,
+	}}
+}
+
 type responsesInputItem struct {
 	Type      string                 `json:"type,omitempty"`
 	Role      string                 `json:"role,omitempty"`
@@ -142,6 +165,7 @@ func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatR
 		Instructions: codexInstructions(request.SystemPrompt),
 		Input:        input,
 		Tools:        tools,
+		Text:         textFormat(request.ResponseFormat),
 		Store:        false,
 		Stream:       true,
 	}
@@ -469,4 +493,20 @@ func codexInstructions(systemPrompt string) string {
 	return codexPrefix + "\n\n" + prompt
 }
 
+// knownModels are the Codex-capable models. Codex OAuth has no public models
+// listing endpoint like the standard OpenAI API.
+var knownModels = []string{
+	"gpt-5.3-codex",
+	"gpt-5.2-codex",
+	"gpt-5.1-codex",
+	"gpt-5.1-codex-max",
+	"gpt-5.1-codex-mini",
+}
+
+// ListModelIDs returns the known Codex-capable models.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	return append([]string(nil), knownModels...), nil
+}
+
 var _ llm.Client = (*Client)(nil)
+var _ llm.ModelLister = (*Client)(nil)
@@ -0,0 +1,357 @@
+package reqlog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/logging"
+)
+
+const (
+	// DirName is the directory under DataPath holding the request logs.
+	DirName = "llm-logs"
+	// DefaultMaxFileSize rotates a session's log once it would grow past it.
+	DefaultMaxFileSize = 10 << 20
+	// DefaultMaxDirSize bounds all request logs together; the oldest files
+	// are deleted beyond it.
+	DefaultMaxDirSize = 200 << 20
+
+	redacted = "[REDACTED]"
+	// minSecretLength keeps short values such as "true" from being
+	// scrubbed out of every message.
+	minSecretLength = 8
+)
+
+// secretPatterns match credentials that were never configured here, such as
+// keys pasted into a prompt or printed by a tool.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`),
+	regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
+	regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36,}`),
+	regexp.MustCompile(`xox[abprs]-[A-Za-z0-9\-]{10,}`),
+	regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/\-]{16,}=*`),
+}
+
+// Options configures a request-logging client.
+type Options struct {
+	Dir             string
+	MaxContentChars int   // truncate message contents; 0 keeps them whole
+	MaxFileSize     int64 // 0 uses DefaultMaxFileSize
+	MaxDirSize      int64 // 0 uses DefaultMaxDirSize
+	// Secrets returns the values to scrub, e.g. API keys and integration
+	// tokens. It is called for every entry so newly added secrets apply.
+	Secrets func() []string
+}
+
+// Client writes every request made within a session, and the response or
+// error it got, as one JSON line to Dir/<session>.jsonl.
+type Client struct {
+	inner llm.Client
+	opts  Options
+	mu    sync.Mutex
+}
+
+// Wrap returns inner with request logging.
+func Wrap(inner llm.Client, opts Options) llm.Client {
+	if inner == nil {
+		return nil
+	}
+	if opts.MaxFileSize <= 0 {
+		opts.MaxFileSize = DefaultMaxFileSize
+	}
+	if opts.MaxDirSize <= 0 {
+		opts.MaxDirSize = DefaultMaxDirSize
+	}
+	return &Client{inner: inner, opts: opts}
+}
+
+// Entry is one logged request.
+type Entry struct {
+	Time       time.Time `json:"time"`
+	SessionID  string    `json:"session_id"`
+	Step       int       `json:"step,omitempty"`
+	DurationMS int64     `json:"duration_ms"`
+	Request    Request   `json:"request"`
+	Response   *Response `json:"response,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Request is the logged form of an llm.ChatRequest. Tools are listed by name.
+type Request struct {
+	Model          string              `json:"model,omitempty"`
+	SystemPrompt   string              `json:"system_prompt,omitempty"`
+	Messages       []llm.Message       `json:"messages"`
+	Tools          []string            `json:"tools,omitempty"`
+	Temperature    float64             `json:"temperature,omitempty"`
+	MaxTokens      int                 `json:"max_tokens,omitempty"`
+	StopSequences  []string            `json:"stop_sequences,omitempty"`
+	ResponseFormat *llm.ResponseFormat `json:"response_format,omitempty"`
+}
+
+// Response is the logged form of an llm.ChatResponse.
+type Response struct {
+	Content    string         `json:"content,omitempty"`
+	ToolCalls  []llm.ToolCall `json:"tool_calls,omitempty"`
+	Usage      llm.TokenUsage `json:"usage"`
+	StopReason string         `json:"stop_reason,omitempty"`
+	Provider   string         `json:"provider,omitempty"`
+	Model      string         `json:"model,omitempty"`
+}
+
+func (c *Client) Chat(ctx context.Context, request *llm.ChatRequest) (*llm.ChatResponse, error) {
+	start := time.Now()
+	resp, err := c.inner.Chat(ctx, request)
+	c.record(ctx, start, request, resp, err)
+	return resp, err
+}
+
+// ChatStream streams through the inner client when it supports streaming.
+func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEvent func(llm.StreamEvent) error) (*llm.ChatResponse, error) {
+	streamClient, ok := c.inner.(llm.StreamingClient)
+	if !ok {
+		return c.Chat(ctx, request)
+	}
+	start := time.Now()
+	resp, err := streamClient.ChatStream(ctx, request, onEvent)
+	c.record(ctx, start, request, resp, err)
+	return resp, err
+}
+
+// ListModelIDs passes model listing through to the inner client.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	return llm.ListModelIDs(ctx, c.inner)
+}
+
+// record appends an entry for a request made on behalf of a session.
+// Requests without a session, such as model listing or proxying, are not
+// logged. Failures to log are reported but never fail the request.
+func (c *Client) record(ctx context.Context, start time.Time, request *llm.ChatRequest, resp *llm.ChatResponse, callErr error) {
+	sessionID := logging.SessionIDFromContext(ctx)
+	if sessionID == "" || request == nil {
+		return
+	}
+	var secrets []string
+	if c.opts.Secrets != nil {
+		secrets = c.opts.Secrets()
+		// Longest first, so a secret containing another is scrubbed whole.
+		sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	}
+	// Contents are scrubbed before truncation so a cut cannot leave part of
+	// a secret behind, and the whole line again for every other field.
+	clip := func(text string) string {
+		return truncate(redact(text, secrets), c.opts.MaxContentChars)
+	}
+	entry := Entry{
+		Time:       start.UTC(),
+		SessionID:  sessionID,
+		DurationMS: time.Since(start).Milliseconds(),
+		Request:    loggedRequest(request, clip),
+	}
+	entry.Step, _ = logging.StepFromContext(ctx)
+	if resp != nil {
+		entry.Response = &Response{
+			Content:    clip(resp.Content),
+			ToolCalls:  resp.ToolCalls,
+			Usage:      resp.Usage,
+			StopReason: resp.StopReason,
+			Provider:   resp.Provider,
+			Model:      resp.Model,
+		}
+	}
+	if callErr != nil {
+		entry.Error = callErr.Error()
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logging.WarnContext(ctx, "LLM request log: encode failed: %v", err)
+		return
+	}
+	line = []byte(redact(string(line), secrets))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.appendLine(FilePath(c.opts.Dir, sessionID), append(line, '\n')); err != nil {
+		logging.WarnContext(ctx, "LLM request log: write failed: %v", err)
+	}
+}
+
+func loggedRequest(request *llm.ChatRequest, clip func(string) string) Request {
+	out := Request{
+		Model:          request.Model,
+		SystemPrompt:   request.SystemPrompt,
+		Messages:       make([]llm.Message, 0, len(request.Messages)),
+		Temperature:    request.Temperature,
+		MaxTokens:      request.MaxTokens,
+		StopSequences:  request.StopSequences,
+		ResponseFormat: request.ResponseFormat,
+	}
+	for _, tool := range request.Tools {
+		out.Tools = append(out.Tools, tool.Name)
+	}
+	for _, msg := range request.Messages {
+		msg.Content = clip(msg.Content)
+		if len(msg.Images) > 0 {
+			images := make([]llm.Image, len(msg.Images))
+			for i, img := range msg.Images {
+				if img.DataBase64 != "" {
+					img.DataBase64 = fmt.Sprintf("<%d base64 chars>", len(img.DataBase64))
+				}
+				images[i] = img
+			}
+			msg.Images = images
+		}
+		if len(msg.ToolResults) > 0 {
+			results := make([]llm.ToolResult, len(msg.ToolResults))
+			for i, result := range msg.ToolResults {
+				result.Content = clip(result.Content)
+				result.Metadata = nil
+				results[i] = result
+			}
+			msg.ToolResults = results
+		}
+		out.Messages = append(out.Messages, msg)
+	}
+	return out
+}
+
+// redact scrubs secrets and anything that looks like a key.
+func redact(text string, secrets []string) string {
+	for _, secret := range secrets {
+		secret = strings.TrimSpace(secret)
+		if len(secret) < minSecretLength {
+			continue
+		}
+		text = strings.ReplaceAll(text, secret, redacted)
+		if encoded, err := json.Marshal(secret); err == nil {
+			// Secrets with quotes or backslashes appear escaped in JSON.
+			text = strings.ReplaceAll(text, strings.Trim(string(encoded), `"`), redacted)
+		}
+	}
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, redacted)
+	}
+	return text
+}
+
+func truncate(text string, limit int) string {
+	if limit <= 0 || len(text) <= limit {
+		return text
+	}
+	cut := limit
+	for cut > 0 && !isRuneStart(text[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s…[truncated %d bytes]", text[:cut], len(text)-cut)
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// appendLine appends line to path, first rotating the file to path.1 when it
+// would exceed MaxFileSize and pruning the directory when it has grown past
+// MaxDirSize.
+func (c *Client) appendLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err == nil && info.Size() > 0 && info.Size()+int64(len(line)) > c.opts.MaxFileSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return err
+		}
+		err = os.ErrNotExist
+	}
+	if err != nil {
+		pruneDir(filepath.Dir(path), c.opts.MaxDirSize-c.opts.MaxFileSize)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	_, err = file.Write(line)
+	return err
+}
+
+// pruneDir deletes the least recently written logs until the directory holds
+// at most limit bytes.
+func pruneDir(dir string, limit int64) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	type logFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []logFile
+	var total int64
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		files = append(files, logFile{filepath.Join(dir, entry.Name()), info.Size(), info.ModTime()})
+		total += info.Size()
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= limit {
+			return
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// FilePath returns the request log of a session.
+func FilePath(dir, sessionID string) string {
+	return filepath.Join(dir, unsafeFileChars.ReplaceAllString(sessionID, "_")+".jsonl")
+}
+
+// LastEntries returns up to n of the most recent entries logged for a
+// session, oldest first, including the rotated file when needed.
+func LastEntries(dir, sessionID string, n int) ([]Entry, error) {
+	path := FilePath(dir, sessionID)
+	var entries []Entry
+	for _, candidate := range []string{path, path + ".1"} {
+		data, err := os.ReadFile(candidate)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var older []Entry
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var entry Entry
+			if line == "" || json.Unmarshal([]byte(line), &entry) != nil {
+				continue
+			}
+			older = append(older, entry)
+		}
+		entries = append(older, entries...)
+		if len(entries) >= n {
+			break
+		}
+	}
+	if len(entries) > n {
+		entries = entries[len(entries)-n:]
+	}
+	return entries, nil
+}
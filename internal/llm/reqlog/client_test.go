@@ -0,0 +1,122 @@
+package reqlog
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/logging"
+)
+
+type stubClient struct {
+	resp *llm.ChatResponse
+	err  error
+}
+
+func (s stubClient) Chat(context.Context, *llm.ChatRequest) (*llm.ChatResponse, error) {
+	return s.resp, s.err
+}
+
+func TestChatLogsRedactedRequestPerSession(t *testing.T) {
+	dir := t.TempDir()
+	client := Wrap(stubClient{resp: &llm.ChatResponse{Content: "ok", StopReason: "end_turn"}}, Options{
+		Dir:             dir,
+		MaxContentChars: 60,
+		Secrets:         func() []string { return []string{"hunter2-super-secret", "short"} },
+	})
+	ctx := logging.WithStep(logging.WithSessionID(context.Background(), "sess-1"), 3)
+	request := &llm.ChatRequest{
+		Model: "kimi-k2",
+		Messages: []llm.Message{
+			{Role: "user", Content: "my password is hunter2-super-secret, keep it short"},
+			{Role: "tool", ToolResults: []llm.ToolResult{{ToolCallID: "1", Content: "OPENAI_API_KEY=sk-abcdefghijklmnopqrstuvwx " + strings.Repeat("x", 100)}}},
+		},
+		Tools: []llm.ToolDefinition{{Name: "bash"}},
+	}
+	if _, err := client.Chat(ctx, request); err != nil {
+		t.Fatalf("Chat: %v", err)
+	}
+	if _, err := client.Chat(context.Background(), request); err != nil {
+		t.Fatalf("Chat without session: %v", err)
+	}
+
+	data, err := os.ReadFile(FilePath(dir, "sess-1"))
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	text := string(data)
+	if strings.Contains(text, "hunter2-super-secret") || strings.Contains(text, "sk-abcdefghijklmnop") {
+		t.Fatalf("secret leaked into log: %s", text)
+	}
+	if !strings.Contains(text, "keep it short") || !strings.Contains(text, "truncated") {
+		t.Fatalf("expected short values kept and long content truncated: %s", text)
+	}
+
+	entries, err := LastEntries(dir, "sess-1", 5)
+	if err != nil {
+		t.Fatalf("LastEntries: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected only the session request to be logged, got %d entries", len(entries))
+	}
+	got := entries[0]
+	if got.Step != 3 || got.Request.Model != "kimi-k2" || len(got.Request.Tools) != 1 || got.Response == nil || got.Response.StopReason != "end_turn" {
+		t.Fatalf("unexpected entry %+v", got)
+	}
+}
+
+func TestChatLogsErrors(t *testing.T) {
+	dir := t.TempDir()
+	client := Wrap(stubClient{err: errors.New("API error (400): invalid message order")}, Options{Dir: dir})
+	ctx := logging.WithSessionID(context.Background(), "sess-2")
+	if _, err := client.Chat(ctx, &llm.ChatRequest{}); err == nil {
+		t.Fatal("expected the provider error")
+	}
+	entries, err := LastEntries(dir, "sess-2", 1)
+	if err != nil || len(entries) != 1 || !strings.Contains(entries[0].Error, "invalid message order") {
+		t.Fatalf("entries = %+v, err = %v", entries, err)
+	}
+}
+
+func TestLogRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	client := Wrap(stubClient{resp: &llm.ChatResponse{}}, Options{Dir: dir, MaxFileSize: 600, MaxDirSize: 1 << 20})
+	ctx := logging.WithSessionID(context.Background(), "sess-3")
+	for i := 0; i < 10; i++ {
+		client.Chat(ctx, &llm.ChatRequest{Messages: []llm.Message{{Role: "user", Content: strings.Repeat("a", 100)}}})
+	}
+
+	path := FilePath(dir, "sess-3")
+	for _, p := range []string{path, path + ".1"} {
+		info, err := os.Stat(p)
+		if err != nil {
+			t.Fatalf("stat %s: %v", p, err)
+		}
+		if info.Size() > 600 {
+			t.Fatalf("%s grew to %d bytes", filepath.Base(p), info.Size())
+		}
+	}
+	entries, err := LastEntries(dir, "sess-3", 100)
+	if err != nil || len(entries) == 0 || len(entries) >= 10 {
+		t.Fatalf("expected rotation to drop the oldest entries, got %d (err %v)", len(entries), err)
+	}
+}
+
+func TestPruneDirRemovesOldestLogs(t *testing.T) {
+	dir := t.TempDir()
+	client := Wrap(stubClient{resp: &llm.ChatResponse{}}, Options{Dir: dir, MaxFileSize: 1000, MaxDirSize: 1500})
+	for _, id := range []string{"a", "b", "c"} {
+		ctx := logging.WithSessionID(context.Background(), id)
+		client.Chat(ctx, &llm.ChatRequest{Messages: []llm.Message{{Role: "user", Content: strings.Repeat("a", 300)}}})
+	}
+	if _, err := os.Stat(FilePath(dir, "a")); !os.IsNotExist(err) {
+		t.Fatalf("expected the oldest session log to be pruned, stat err = %v", err)
+	}
+	if _, err := os.Stat(FilePath(dir, "c")); err != nil {
+		t.Fatalf("newest session log missing: %v", err)
+	}
+}
@@ -0,0 +1,48 @@
+package reqlog
+
+import (
+	"path/filepath"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// Dir returns the request log directory for cfg.
+func Dir(cfg *config.Config) string {
+	return filepath.Join(cfg.DataPath, DirName)
+}
+
+// WrapFromConfig wraps client with request logging when cfg.LLM.LogRequests
+// is set. Secrets are read from cfg and, when store is not nil, from the
+// stored integrations.
+func WrapFromConfig(client llm.Client, cfg *config.Config, store storage.Store) llm.Client {
+	if client == nil || cfg == nil || !cfg.LLM.LogRequests {
+		return client
+	}
+	return Wrap(client, Options{
+		Dir:             Dir(cfg),
+		MaxContentChars: cfg.LLM.LogMaxContentChars,
+		MaxFileSize:     int64(cfg.LLM.LogMaxSizeMB) << 20,
+		Secrets:         Secrets(cfg, store),
+	})
+}
+
+// Secrets returns a function listing the secret values of cfg and, when
+// store is not nil, of the stored integrations, as they are when called.
+func Secrets(cfg *config.Config, store storage.Store) func() []string {
+	return func() []string {
+		secrets := cfg.SecretValues()
+		if store == nil {
+			return secrets
+		}
+		integrations, err := store.ListIntegrations()
+		if err != nil {
+			return secrets
+		}
+		for _, integration := range integrations {
+			secrets = append(secrets, integration.SecretValues()...)
+		}
+		return secrets
+	}
+}
@@ -0,0 +1,247 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultResponseFormatName names the schema for providers that require one.
+const defaultResponseFormatName = "response"
+
+// ResponseFormat describes the JSON a final answer must be.
+type ResponseFormat struct {
+	// Name identifies the schema; providers that need a name get "response"
+	// when it is empty.
+	Name string
+	// Schema is a JSON Schema object.
+	Schema json.RawMessage
+}
+
+// SchemaName returns the schema name sent to providers.
+func (f *ResponseFormat) SchemaName() string {
+	if name := strings.TrimSpace(f.Name); name != "" {
+		return name
+	}
+	return defaultResponseFormatName
+}
+
+// Instructions is the system prompt addition for providers that cannot
+// enforce the schema themselves.
+func (f *ResponseFormat) Instructions() string {
+	return "When you give your final answer, reply with only a JSON value that matches this JSON Schema, without prose or code fences:\n" + string(f.Schema)
+}
+
+// AppendInstructions returns systemPrompt followed by Instructions, or
+// systemPrompt unchanged when f is nil.
+func (f *ResponseFormat) AppendInstructions(systemPrompt string) string {
+	if f == nil {
+		return systemPrompt
+	}
+	if strings.TrimSpace(systemPrompt) == "" {
+		return f.Instructions()
+	}
+	return systemPrompt + "\n\n" + f.Instructions()
+}
+
+// OpenAIResponseFormat is the response_format object of OpenAI-compatible
+// chat completion APIs.
+type OpenAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *OpenAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+// OpenAIJSONSchema is the json_schema member of OpenAIResponseFormat.
+type OpenAIJSONSchema struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+}
+
+// OpenAI returns the json_schema response_format for f, or nil when f is nil.
+func (f *ResponseFormat) OpenAI() *OpenAIResponseFormat {
+	if f == nil {
+		return nil
+	}
+	return &OpenAIResponseFormat{
+		Type:       "json_schema",
+		JSONSchema: &OpenAIJSONSchema{Name: f.SchemaName(), Schema: f.Schema},
+	}
+}
+
+// CheckSchema reports whether schema is a JSON object usable as a response
+// schema.
+func CheckSchema(schema json.RawMessage) error {
+	var parsed map[string]any
+	if err := json.Unmarshal(schema, &parsed); err != nil {
+		return fmt.Errorf("response schema must be a JSON object: %w", err)
+	}
+	if parsed == nil {
+		return errors.New("response schema must be a JSON object")
+	}
+	return nil
+}
+
+// ValidateJSON checks content against schema and returns the JSON itself,
+// without surrounding whitespace or a markdown code fence. It understands the
+// keywords models are usually given: type, properties, required,
+// additionalProperties (false), items and enum; others are ignored.
+func ValidateJSON(schema json.RawMessage, content string) (string, error) {
+	var parsedSchema map[string]any
+	if err := json.Unmarshal(schema, &parsedSchema); err != nil {
+		return "", fmt.Errorf("invalid response schema: %w", err)
+	}
+
+	text := stripCodeFence(content)
+	var value any
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return "", fmt.Errorf("answer is not valid JSON: %w", err)
+	}
+	if decoder.More() {
+		return "", errors.New("answer has text after the JSON value")
+	}
+	if err := validateValue(parsedSchema, value, "$"); err != nil {
+		return "", err
+	}
+	return text, nil
+}
+
+// stripCodeFence trims content and removes a ```json fence around it.
+func stripCodeFence(content string) string {
+	text := strings.TrimSpace(content)
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	text = strings.TrimSuffix(strings.TrimPrefix(text, "```"), "```")
+	if newline := strings.IndexByte(text, '\n'); newline >= 0 && !strings.ContainsAny(text[:newline], "{[\"") {
+		text = text[newline+1:]
+	}
+	return strings.TrimSpace(text)
+}
+
+func validateValue(schema map[string]any, value any, path string) error {
+	if types := schemaTypes(schema["type"]); len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if hasJSONType(value, t) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: expected %s, got %s", path, strings.Join(types, " or "), jsonTypeName(value))
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%s: value is not one of the allowed values", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				key, _ := name.(string)
+				if _, present := v[key]; !present {
+					return fmt.Errorf("%s: missing required property %q", path, key)
+				}
+			}
+		}
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			propertySchema, known := properties[key].(map[string]any)
+			if !known {
+				if additional, ok := schema["additionalProperties"].(bool); ok && !additional {
+					return fmt.Errorf("%s: unexpected property %q", path, key)
+				}
+				continue
+			}
+			if err := validateValue(propertySchema, v[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				if err := validateValue(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func schemaTypes(raw any) []string {
+	switch t := raw.(type) {
+	case string:
+		return []string{t}
+	case []any:
+		types := make([]string, 0, len(t))
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+		return types
+	}
+	return nil
+}
+
+func hasJSONType(value any, schemaType string) bool {
+	switch schemaType {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return jsonTypeName(value) == schemaType
+	}
+}
+
+func jsonTypeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return "unknown"
+}
+
+func jsonEqual(a, b any) bool {
+	left, errLeft := json.Marshal(a)
+	right, errRight := json.Marshal(b)
+	return errLeft == nil && errRight == nil && string(left) == string(right)
+}
@@ -0,0 +1,78 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const dependencySchema = `{
+	"type": "array",
+	"items": {
+		"type": "object",
+		"properties": {
+			"name": {"type": "string"},
+			"current": {"type": "string"},
+			"latest": {"type": "string"},
+			"severity": {"enum": ["patch", "minor", "major"]}
+		},
+		"required": ["name", "latest"],
+		"additionalProperties": false
+	}
+}`
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+		wantErr string
+	}{
+		{name: "valid", content: `[{"name":"cobra","latest":"1.10.2"}]`, want: `[{"name":"cobra","latest":"1.10.2"}]`},
+		{name: "code fence", content: "```json\n[{\"name\":\"chi\",\"latest\":\"5.2.5\"}]\n```", want: `[{"name":"chi","latest":"5.2.5"}]`},
+		{name: "prose", content: "Here are the outdated dependencies: cobra", wantErr: "not valid JSON"},
+		{name: "trailing text", content: `[] and that is all`, wantErr: "text after"},
+		{name: "wrong type", content: `{"name":"cobra"}`, wantErr: "$: expected array, got object"},
+		{name: "missing required", content: `[{"name":"cobra"}]`, wantErr: `$[0]: missing required property "latest"`},
+		{name: "extra property", content: `[{"name":"cobra","latest":"1","url":"x"}]`, wantErr: `$[0]: unexpected property "url"`},
+		{name: "enum", content: `[{"name":"cobra","latest":"1","severity":"huge"}]`, wantErr: "$[0].severity: value is not one of"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ValidateJSON(json.RawMessage(dependencySchema), tt.content)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateJSON: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJSONIntegerAndNumber(t *testing.T) {
+	schema := json.RawMessage(`{"type":"object","properties":{"count":{"type":"integer"},"ratio":{"type":"number"}}}`)
+	if _, err := ValidateJSON(schema, `{"count":3,"ratio":0.5}`); err != nil {
+		t.Fatalf("ValidateJSON: %v", err)
+	}
+	if _, err := ValidateJSON(schema, `{"count":3.5}`); err == nil {
+		t.Fatal("expected 3.5 to be rejected as an integer")
+	}
+}
+
+func TestCheckSchema(t *testing.T) {
+	if err := CheckSchema(json.RawMessage(dependencySchema)); err != nil {
+		t.Fatalf("CheckSchema: %v", err)
+	}
+	for _, bad := range []string{`"object"`, `[1]`, `null`, `{`} {
+		if err := CheckSchema(json.RawMessage(bad)); err == nil {
+			t.Errorf("CheckSchema(%s) should fail", bad)
+		}
+	}
+}
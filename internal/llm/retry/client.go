@@ -150,6 +150,11 @@ func (c *Client) ChatStream(ctx context.Context, request *llm.ChatRequest, onEve
 	return nil, lastErr
 }
 
+// ListModelIDs lists the models of the wrapped client, which is not retried.
+func (c *Client) ListModelIDs(ctx context.Context) ([]string, error) {
+	return llm.ListModelIDs(ctx, c.inner)
+}
+
 func retryBackoff(attempt int) time.Duration {
 	base := DefaultRetryBackoff
 	for i := 0; i < attempt; i++ {
@@ -210,3 +215,4 @@ func IsRetryableError(ctx context.Context, err error) bool {
 
 var _ llm.Client = (*Client)(nil)
 var _ llm.StreamingClient = (*Client)(nil)
+var _ llm.ModelLister = (*Client)(nil)
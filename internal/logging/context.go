@@ -0,0 +1,99 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type correlationKey int
+
+const (
+	sessionIDKey correlationKey = iota
+	jobIDKey
+	stepKey
+	requestIDKey
+)
+
+// legacySessionIDKey is the untyped key the agent loop already uses to hand
+// the session ID to tools.
+const legacySessionIDKey = "session_id"
+
+// WithSessionID returns a context whose log records carry session_id.
+func WithSessionID(ctx context.Context, sessionID string) context.Context {
+	if sessionID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, sessionIDKey, sessionID)
+}
+
+// WithJobID returns a context whose log records carry job_id.
+func WithJobID(ctx context.Context, jobID string) context.Context {
+	if jobID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, jobIDKey, jobID)
+}
+
+// WithRequestID returns a context whose log records carry request_id.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	if requestID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// WithStep returns a context whose log records carry the agent step number.
+func WithStep(ctx context.Context, step int) context.Context {
+	return context.WithValue(ctx, stepKey, step)
+}
+
+// contextAttrs collects the correlation fields present in ctx.
+func contextAttrs(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+	var attrs []slog.Attr
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if sessionID := SessionIDFromContext(ctx); sessionID != "" {
+		attrs = append(attrs, slog.String("session_id", sessionID))
+	}
+	if jobID, _ := ctx.Value(jobIDKey).(string); jobID != "" {
+		attrs = append(attrs, slog.String("job_id", jobID))
+	}
+	if step, ok := StepFromContext(ctx); ok {
+		attrs = append(attrs, slog.Int("step", step))
+	}
+	return attrs
+}
+
+// SessionIDFromContext returns the session ID set by WithSessionID, if any.
+func SessionIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if sessionID, _ := ctx.Value(sessionIDKey).(string); sessionID != "" {
+		return sessionID
+	}
+	sessionID, _ := ctx.Value(legacySessionIDKey).(string)
+	return sessionID
+}
+
+// RequestIDFromContext returns the request ID set by WithRequestID, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// StepFromContext returns the agent step set by WithStep.
+func StepFromContext(ctx context.Context) (int, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	step, ok := ctx.Value(stepKey).(int)
+	return step, ok
+}
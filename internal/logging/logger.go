@@ -1,9 +1,10 @@
 package logging
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -36,11 +37,59 @@ func (l Level) String() string {
 	}
 }
 
+func (l Level) slogLevel() slog.Level {
+	switch l {
+	case LevelDebug:
+		return slog.LevelDebug
+	case LevelWarn:
+		return slog.LevelWarn
+	case LevelError:
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ParseLevel parses "debug", "info", "warn"/"warning" or "error".
+func ParseLevel(raw string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelDebug, fmt.Errorf("unknown log level %q", raw)
+	}
+}
+
+// Log file defaults.
+const (
+	logFileName       = "aagent.log"
+	defaultMaxSizeMB  = 20
+	defaultMaxBackups = 5
+)
+
+// Options configures the default logger. Zero values select the defaults:
+// debug level, JSON records, 20 MB files and 5 rotated backups.
+type Options struct {
+	Level      string // debug, info, warn, error
+	Format     string // json or text
+	MaxSizeMB  int
+	MaxBackups int
+	// Console, when set, also receives every record, e.g. os.Stdout for a
+	// service managed by systemd.
+	Console io.Writer
+}
+
 // Logger provides structured logging to file
 type Logger struct {
 	mu       sync.Mutex
-	file     *os.File
-	logger   *log.Logger
+	out      *rotatingFile
+	handler  slog.Handler
 	level    Level
 	filePath string
 	recent   []string
@@ -53,39 +102,78 @@ var (
 
 // Init initializes the default logger with the given data path
 func Init(dataPath string) error {
+	return InitWithOptions(dataPath, Options{})
+}
+
+// InitWithOptions initializes the default logger with explicit level, format
+// and rotation settings.
+func InitWithOptions(dataPath string, opts Options) error {
 	var initErr error
 	once.Do(func() {
-		logDir := filepath.Join(dataPath, "logs")
-		if err := os.MkdirAll(logDir, 0755); err != nil {
-			initErr = fmt.Errorf("failed to create log directory: %w", err)
-			return
-		}
-
-		// Create log file with date in name
-		logFileName := fmt.Sprintf("aagent_%s.log", time.Now().Format("2006-01-02"))
-		logPath := filepath.Join(logDir, logFileName)
+		defaultLogger, initErr = newLogger(filepath.Join(dataPath, "logs"), opts)
+	})
+	return initErr
+}
 
-		file, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+func newLogger(logDir string, opts Options) (*Logger, error) {
+	level := LevelDebug
+	if strings.TrimSpace(opts.Level) != "" {
+		parsed, err := ParseLevel(opts.Level)
 		if err != nil {
-			initErr = fmt.Errorf("failed to open log file: %w", err)
-			return
+			return nil, err
 		}
+		level = parsed
+	}
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create log directory: %w", err)
+	}
 
-		defaultLogger = &Logger{
-			file:     file,
-			logger:   log.New(file, "", 0),
-			level:    LevelDebug,
-			filePath: logPath,
-			recent:   make([]string, 0, 1024),
-		}
-	})
-	return initErr
+	maxSizeMB := opts.MaxSizeMB
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	maxBackups := opts.MaxBackups
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+	logPath := filepath.Join(logDir, logFileName)
+	out, err := openRotatingFile(logPath, int64(maxSizeMB)*1024*1024, maxBackups)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %w", err)
+	}
+
+	// Level filtering happens in logAttrs so SetLevel applies immediately.
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var sink io.Writer = out
+	if opts.Console != nil {
+		sink = io.MultiWriter(out, opts.Console)
+	}
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(opts.Format)) {
+	case "", "json":
+		handler = slog.NewJSONHandler(sink, handlerOpts)
+	case "text":
+		handler = slog.NewTextHandler(sink, handlerOpts)
+	default:
+		out.Close()
+		return nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+
+	return &Logger{
+		out:      out,
+		handler:  handler,
+		level:    level,
+		filePath: logPath,
+		recent:   make([]string, 0, 1024),
+	}, nil
 }
 
 // Close closes the log file
 func Close() {
-	if defaultLogger != nil && defaultLogger.file != nil {
-		defaultLogger.file.Close()
+	if defaultLogger != nil && defaultLogger.out != nil {
+		defaultLogger.mu.Lock()
+		defaultLogger.out.Close()
+		defaultLogger.mu.Unlock()
 	}
 }
 
@@ -97,6 +185,12 @@ func GetLogPath() string {
 	return ""
 }
 
+// LogFilePath returns the active log file for a data path, whether or not a
+// logger is running in this process.
+func LogFilePath(dataPath string) string {
+	return filepath.Join(dataPath, "logs", logFileName)
+}
+
 // SetLevel sets the minimum log level
 func SetLevel(level Level) {
 	if defaultLogger != nil {
@@ -109,33 +203,67 @@ func SetLevel(level Level) {
 // Writer returns an io.Writer that writes to the log file
 func Writer() io.Writer {
 	if defaultLogger != nil {
-		return defaultLogger.file
+		return lockedWriter{defaultLogger}
 	}
 	return io.Discard
 }
 
-func logf(level Level, format string, args ...interface{}) {
-	if defaultLogger == nil {
+type lockedWriter struct{ l *Logger }
+
+func (w lockedWriter) Write(p []byte) (int, error) {
+	w.l.mu.Lock()
+	defer w.l.mu.Unlock()
+	return w.l.out.Write(p)
+}
+
+func logf(ctx context.Context, level Level, format string, args ...interface{}) {
+	l := defaultLogger
+	if l == nil {
 		return
 	}
+	// Skip formatting for filtered levels.
+	l.mu.Lock()
+	filtered := level < l.level
+	l.mu.Unlock()
+	if filtered {
+		return
+	}
+	logAttrs(ctx, level, fmt.Sprintf(format, args...), nil)
+}
 
-	defaultLogger.mu.Lock()
-	defer defaultLogger.mu.Unlock()
+// logAttrs writes one record with correlation fields from ctx followed by attrs.
+func logAttrs(ctx context.Context, level Level, msg string, attrs []slog.Attr) {
+	l := defaultLogger
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-	if level < defaultLogger.level {
+	if level < l.level {
 		return
 	}
 
-	timestamp := time.Now().Format("2006-01-02 15:04:05.000")
-	msg := fmt.Sprintf(format, args...)
-	line := fmt.Sprintf("[%s] [%s] %s", timestamp, level.String(), msg)
-	defaultLogger.logger.Print(line)
-	defaultLogger.appendRecent(line)
+	all := append(contextAttrs(ctx), attrs...)
+	now := time.Now()
+	record := slog.NewRecord(now, level.slogLevel(), msg, 0)
+	record.AddAttrs(all...)
+	_ = l.handler.Handle(ctx, record)
+	l.appendRecent(formatRecentLine(now, level, msg, all))
 
 	// Flush to disk immediately for real-time log viewing
-	if defaultLogger.file != nil {
-		defaultLogger.file.Sync()
+	l.out.Sync()
+}
+
+// formatRecentLine renders the human-readable form kept for the TUI log view.
+func formatRecentLine(ts time.Time, level Level, msg string, attrs []slog.Attr) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] [%s] %s", ts.Format("2006-01-02 15:04:05.000"), level.String(), msg)
+	for _, attr := range attrs {
+		fmt.Fprintf(&b, " %s=%v", attr.Key, attr.Value)
 	}
+	return b.String()
 }
 
 func (l *Logger) appendRecent(line string) {
@@ -169,22 +297,42 @@ func RecentLines(n int) []string {
 
 // Debug logs a debug message
 func Debug(format string, args ...interface{}) {
-	logf(LevelDebug, format, args...)
+	logf(context.Background(), LevelDebug, format, args...)
 }
 
 // Info logs an info message
 func Info(format string, args ...interface{}) {
-	logf(LevelInfo, format, args...)
+	logf(context.Background(), LevelInfo, format, args...)
 }
 
 // Warn logs a warning message
 func Warn(format string, args ...interface{}) {
-	logf(LevelWarn, format, args...)
+	logf(context.Background(), LevelWarn, format, args...)
 }
 
 // Error logs an error message
 func Error(format string, args ...interface{}) {
-	logf(LevelError, format, args...)
+	logf(context.Background(), LevelError, format, args...)
+}
+
+// DebugContext logs a debug message with the correlation fields in ctx.
+func DebugContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelDebug, format, args...)
+}
+
+// InfoContext logs an info message with the correlation fields in ctx.
+func InfoContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelInfo, format, args...)
+}
+
+// WarnContext logs a warning message with the correlation fields in ctx.
+func WarnContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelWarn, format, args...)
+}
+
+// ErrorContext logs an error message with the correlation fields in ctx.
+func ErrorContext(ctx context.Context, format string, args ...interface{}) {
+	logf(ctx, LevelError, format, args...)
 }
 
 // LogRequest logs an LLM request
@@ -230,14 +378,21 @@ func LogResponseWithContent(inputTokens, outputTokens int, toolCallCount int, co
 
 // LogToolExecution logs tool execution
 func LogToolExecution(toolName string, success bool, duration time.Duration) {
+	LogToolExecutionContext(context.Background(), toolName, success, duration)
+}
+
+// LogToolExecutionContext logs tool execution with the correlation fields in ctx.
+func LogToolExecutionContext(ctx context.Context, toolName string, success bool, duration time.Duration) {
+	attrs := []slog.Attr{slog.String("tool", toolName), slog.Duration("duration", duration)}
 	if success {
-		Debug("Tool executed: name=%s duration=%v", toolName, duration)
+		logAttrs(ctx, LevelDebug, "Tool executed", attrs)
 	} else {
-		Warn("Tool failed: name=%s duration=%v", toolName, duration)
+		logAttrs(ctx, LevelWarn, "Tool failed", attrs)
 	}
 }
 
 // LogSession logs session events
 func LogSession(event string, sessionID string, details string) {
-	Info("Session %s: id=%s %s", event, sessionID, details)
+	ctx := WithSessionID(context.Background(), sessionID)
+	logf(ctx, LevelInfo, "Session %s: id=%s %s", event, sessionID, details)
 }
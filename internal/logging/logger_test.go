@@ -0,0 +1,130 @@
+package logging
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func useTestLogger(t *testing.T, opts Options) *Logger {
+	t.Helper()
+	l, err := newLogger(t.TempDir(), opts)
+	if err != nil {
+		t.Fatalf("newLogger: %v", err)
+	}
+	previous := defaultLogger
+	defaultLogger = l
+	t.Cleanup(func() {
+		defaultLogger = previous
+		l.out.Close()
+	})
+	return l
+}
+
+func readRecords(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open log: %v", err)
+	}
+	defer file.Close()
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("log line is not JSON: %q", scanner.Text())
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestJSONRecordsCarryCorrelationFields(t *testing.T) {
+	l := useTestLogger(t, Options{Level: "info"})
+
+	ctx := WithJobID(WithSessionID(context.Background(), "sess-1"), "job-7")
+	ctx = WithStep(ctx, 3)
+	InfoContext(ctx, "step %d done", 3)
+	Debug("filtered out")
+	legacy := context.WithValue(context.Background(), "session_id", "sess-legacy")
+	WarnContext(legacy, "tool warning")
+
+	records := readRecords(t, l.filePath)
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records after level filtering, got %d: %v", len(records), records)
+	}
+	first := records[0]
+	if first["msg"] != "step 3 done" || first["level"] != "INFO" {
+		t.Fatalf("unexpected record: %v", first)
+	}
+	if first["session_id"] != "sess-1" || first["job_id"] != "job-7" || first["step"] != float64(3) {
+		t.Fatalf("missing correlation fields: %v", first)
+	}
+	if records[1]["session_id"] != "sess-legacy" {
+		t.Fatalf("legacy session_id context key not picked up: %v", records[1])
+	}
+
+	recent := RecentLines(0)
+	if len(recent) != 2 || !strings.Contains(recent[0], "[INFO] step 3 done session_id=sess-1") {
+		t.Fatalf("unexpected recent lines: %v", recent)
+	}
+}
+
+func TestConsoleReceivesRecords(t *testing.T) {
+	var console strings.Builder
+	l := useTestLogger(t, Options{Format: "text", Console: &console})
+
+	Info("server listening")
+
+	if !strings.Contains(console.String(), "msg=\"server listening\"") {
+		t.Fatalf("expected record on console, got %q", console.String())
+	}
+	data, err := os.ReadFile(l.filePath)
+	if err != nil || !strings.Contains(string(data), "server listening") {
+		t.Fatalf("expected record in log file too, got %q (%v)", data, err)
+	}
+}
+
+func TestLogFileRotatesBySize(t *testing.T) {
+	l := useTestLogger(t, Options{MaxSizeMB: 1, MaxBackups: 2})
+	l.out.maxSize = 512
+
+	for i := 0; i < 40; i++ {
+		Info("message %02d %s", i, strings.Repeat("x", 64))
+	}
+
+	for _, name := range []string{logFileName, logFileName + ".1", logFileName + ".2"} {
+		info, err := os.Stat(filepath.Join(filepath.Dir(l.filePath), name))
+		if err != nil {
+			t.Fatalf("expected %s to exist: %v", name, err)
+		}
+		if info.Size() > 512 {
+			t.Fatalf("%s exceeds max size: %d", name, info.Size())
+		}
+	}
+	if _, err := os.Stat(l.filePath + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 backups, stat .3: %v", err)
+	}
+	records := readRecords(t, l.filePath)
+	last := records[len(records)-1]
+	if !strings.HasPrefix(fmt.Sprint(last["msg"]), "message 39") {
+		t.Fatalf("newest record should be in the active file, got %v", last)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for raw, want := range map[string]Level{"debug": LevelDebug, "INFO": LevelInfo, "warning": LevelWarn, "error": LevelError} {
+		if got, err := ParseLevel(raw); err != nil || got != want {
+			t.Fatalf("ParseLevel(%q) = %v, %v", raw, got, err)
+		}
+	}
+	if _, err := ParseLevel("loud"); err == nil {
+		t.Fatal("expected error for unknown level")
+	}
+}
@@ -0,0 +1,230 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// smtpsPort is implicit TLS; other ports upgrade with STARTTLS when offered.
+	smtpsPort         = 465
+	emailSubjectRunes = 120
+	emailTestMessage  = "Test message from A2gent: this integration can deliver notifications."
+)
+
+// ValidateEmailConfig checks the SMTP port and the from/to addresses.
+func ValidateEmailConfig(config map[string]string) error {
+	if _, err := emailPort(config); err != nil {
+		return err
+	}
+	if _, err := mail.ParseAddress(config["from"]); err != nil {
+		return fmt.Errorf("invalid email from address: %w", err)
+	}
+	if _, err := mail.ParseAddressList(config["to"]); err != nil {
+		return fmt.Errorf("invalid email to addresses: %w", err)
+	}
+	return nil
+}
+
+// CheckEmail connects to the SMTP server, greets it and authenticates, and
+// sends a short test email when sendTestMessage is set.
+func CheckEmail(ctx context.Context, config map[string]string, sendTestMessage bool) error {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	client, err := dialSMTP(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if sendTestMessage {
+		if err := deliverEmail(client, config, Message{Text: emailTestMessage}); err != nil {
+			return err
+		}
+	}
+	return client.Quit()
+}
+
+// sendEmail mails the message to the integration's to addresses.
+func sendEmail(ctx context.Context, config map[string]string, msg Message) error {
+	client, err := dialSMTP(ctx, config)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	if err := deliverEmail(client, config, msg); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+func emailPort(config map[string]string) (int, error) {
+	port, err := strconv.Atoi(strings.TrimSpace(config["smtp_port"]))
+	if err != nil || port <= 0 || port > 65535 {
+		return 0, fmt.Errorf("smtp_port must be a port number, got %q", config["smtp_port"])
+	}
+	return port, nil
+}
+
+// dialSMTP connects, says EHLO, upgrades to TLS and authenticates.
+func dialSMTP(ctx context.Context, config map[string]string) (*smtp.Client, error) {
+	host := strings.TrimSpace(config["smtp_host"])
+	port, err := emailPort(config)
+	if err != nil {
+		return nil, err
+	}
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if port == smtpsPort {
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SMTP handshake failed: %w", err)
+	}
+	if err := client.Hello("localhost"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("SMTP EHLO failed: %w", err)
+	}
+	if ok, _ := client.Extension("STARTTLS"); ok && port != smtpsPort {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("SMTP STARTTLS failed: %w", err)
+		}
+	}
+	if ok, _ := client.Extension("AUTH"); !ok {
+		client.Close()
+		return nil, fmt.Errorf("SMTP server does not offer AUTH")
+	}
+	// PlainAuth refuses to send credentials over unencrypted connections to
+	// anything but localhost.
+	if err := client.Auth(smtp.PlainAuth("", config["username"], config["password"], host)); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("SMTP AUTH failed: %w", err)
+	}
+	return client, nil
+}
+
+func deliverEmail(client *smtp.Client, config map[string]string, msg Message) error {
+	from, err := mail.ParseAddress(config["from"])
+	if err != nil {
+		return fmt.Errorf("invalid email from address: %w", err)
+	}
+	to, err := mail.ParseAddressList(config["to"])
+	if err != nil {
+		return fmt.Errorf("invalid email to addresses: %w", err)
+	}
+	body, err := buildEmail(from, to, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("SMTP MAIL FROM rejected: %w", err)
+	}
+	for _, addr := range to {
+		if err := client.Rcpt(addr.Address); err != nil {
+			return fmt.Errorf("SMTP RCPT TO %s rejected: %w", addr.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP DATA rejected: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("failed to write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP server rejected the email: %w", err)
+	}
+	return nil
+}
+
+// buildEmail renders msg as a multipart/alternative email with a plain-text
+// part and an HTML part that also lists the message fields.
+func buildEmail(from *mail.Address, to []*mail.Address, msg Message, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	if err := writeEmailPart(parts, "text/plain", msg.Text); err != nil {
+		return nil, err
+	}
+	if err := writeEmailPart(parts, "text/html", emailHTML(msg)); err != nil {
+		return nil, err
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	recipients := make([]string, 0, len(to))
+	for _, addr := range to {
+		recipients = append(recipients, addr.String())
+	}
+	subject, _, _ := strings.Cut(strings.TrimSpace(msg.Text), "\n")
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "From: %s\r\n", from.String())
+	fmt.Fprintf(&out, "To: %s\r\n", strings.Join(recipients, ", "))
+	fmt.Fprintf(&out, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", truncateRunes(subject, emailSubjectRunes)))
+	fmt.Fprintf(&out, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	out.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&out, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", parts.Boundary())
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func writeEmailPart(parts *multipart.Writer, contentType string, content string) error {
+	part, err := parts.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return err
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+func emailHTML(msg Message) string {
+	var b strings.Builder
+	b.WriteString("<html><body>\n")
+	for _, paragraph := range strings.Split(strings.TrimSpace(msg.Text), "\n\n") {
+		lines := strings.Split(html.EscapeString(paragraph), "\n")
+		b.WriteString("<p>" + strings.Join(lines, "<br>") + "</p>\n")
+	}
+	if len(msg.Fields) > 0 {
+		keys := make([]string, 0, len(msg.Fields))
+		for key := range msg.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		b.WriteString("<table>\n")
+		for _, key := range keys {
+			fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", html.EscapeString(key), html.EscapeString(fmt.Sprint(msg.Fields[key])))
+		}
+		b.WriteString("</table>\n")
+	}
+	b.WriteString("</body></html>\n")
+	return b.String()
+}
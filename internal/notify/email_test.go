@@ -0,0 +1,189 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// smtpTestServer is a minimal SMTP server that accepts AUTH PLAIN and
+// records the last message it received.
+type smtpTestServer struct {
+	addr       string
+	authReject bool
+	auth       string
+	rcpts      []string
+	data       chan string
+}
+
+func newSMTPTestServer(t *testing.T) *smtpTestServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	srv := &smtpTestServer{addr: listener.Addr().String(), data: make(chan string, 4)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	return srv
+}
+
+func (srv *smtpTestServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = io.WriteString(conn, line+"\r\n") }
+	reply("220 test ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+		switch verb {
+		case "EHLO":
+			reply("250-test")
+			reply("250 AUTH PLAIN")
+		case "AUTH":
+			srv.auth = line
+			if srv.authReject {
+				reply("535 authentication failed")
+			} else {
+				reply("235 ok")
+			}
+		case "MAIL":
+			reply("250 ok")
+		case "RCPT":
+			srv.rcpts = append(srv.rcpts, line)
+			reply("250 ok")
+		case "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			srv.data <- data.String()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (srv *smtpTestServer) config() map[string]string {
+	host, port, _ := net.SplitHostPort(srv.addr)
+	return map[string]string{"smtp_host": host, "smtp_port": port, "username": "bot", "password": "pw", "from": "A2gent <bot@example.com>", "to": "ops@example.com, Dev <dev@example.com>"}
+}
+
+func TestSendEmail(t *testing.T) {
+	srv := newSMTPTestServer(t)
+	job, exec := finishedExecution("failed", "", "provider <unavailable>")
+	integration := &storage.Integration{ID: "mail", Provider: "email", Enabled: true, Config: srv.config()}
+	if err := Send(context.Background(), integration, JobMessage(job, exec)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	raw := <-srv.data
+	if len(srv.rcpts) != 2 || !strings.Contains(srv.rcpts[1], "<dev@example.com>") || !strings.HasPrefix(srv.auth, "AUTH PLAIN") {
+		t.Fatalf("unexpected envelope rcpts=%v auth=%q", srv.rcpts, srv.auth)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if subject := msg.Header.Get("Subject"); !strings.HasPrefix(subject, `Job "nightly report" failed`) {
+		t.Fatalf("unexpected subject %q", subject)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q: %v", msg.Header.Get("Content-Type"), err)
+	}
+	parts := map[string]string{}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		content, _ := io.ReadAll(quotedprintable.NewReader(part))
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = string(content)
+	}
+	if !strings.Contains(parts["text/plain"], "provider <unavailable>") {
+		t.Fatalf("plain part is missing the summary: %q", parts["text/plain"])
+	}
+	if !strings.Contains(parts["text/html"], "provider &lt;unavailable&gt;") || !strings.Contains(parts["text/html"], "<th align=\"left\">session_id</th><td>sess-1</td>") {
+		t.Fatalf("unexpected html part: %q", parts["text/html"])
+	}
+}
+
+func TestCheckEmail(t *testing.T) {
+	srv := newSMTPTestServer(t)
+	if err := CheckEmail(context.Background(), srv.config(), false); err != nil {
+		t.Fatalf("CheckEmail: %v", err)
+	}
+	select {
+	case data := <-srv.data:
+		t.Fatalf("a connectivity check should not send mail, got %q", data)
+	default:
+	}
+
+	if err := CheckEmail(context.Background(), srv.config(), true); err != nil {
+		t.Fatalf("CheckEmail with test message: %v", err)
+	}
+	if data := <-srv.data; !strings.Contains(data, "Test message from A2gent") {
+		t.Fatalf("unexpected test email %q", data)
+	}
+
+	srv.authReject = true
+	if err := CheckEmail(context.Background(), srv.config(), false); err == nil || !strings.Contains(err.Error(), "AUTH") {
+		t.Fatalf("expected an auth failure, got %v", err)
+	}
+}
+
+func TestValidateEmailConfig(t *testing.T) {
+	config := map[string]string{"smtp_port": "587", "from": "bot@example.com", "to": "a@example.com, b@example.com"}
+	if err := ValidateEmailConfig(config); err != nil {
+		t.Fatalf("ValidateEmailConfig: %v", err)
+	}
+	for key, value := range map[string]string{"smtp_port": "smtp", "from": "not an address", "to": "a@example.com, nope"} {
+		bad := map[string]string{}
+		for k, v := range config {
+			bad[k] = v
+		}
+		bad[key] = value
+		if err := ValidateEmailConfig(bad); err == nil {
+			t.Fatalf("expected %s=%q to be rejected", key, value)
+		}
+	}
+}
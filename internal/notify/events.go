@@ -0,0 +1,207 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/storage"
+	"github.com/google/uuid"
+)
+
+// Events webhook integrations can subscribe to.
+const (
+	EventSessionCompleted     = "session.completed"
+	EventSessionFailed        = "session.failed"
+	EventSessionInputRequired = "session.input_required"
+	EventJobFinished          = "job.finished"
+)
+
+// Events lists every event type, in documentation order.
+var Events = []string{EventSessionCompleted, EventSessionFailed, EventSessionInputRequired, EventJobFinished}
+
+// Webhook headers. The signature is the hex HMAC-SHA256 of the body keyed
+// with the integration's secret, sent as "sha256=<hex>".
+const (
+	EventHeader     = "X-A2gent-Event"
+	DeliveryHeader  = "X-A2gent-Delivery"
+	SignatureHeader = "X-A2gent-Signature"
+)
+
+// Webhook config keys: events is a comma-separated subscription list
+// (empty subscribes to everything) and secret keys the signature.
+const (
+	webhookEventsConfigKey = "events"
+	webhookSecretConfigKey = "secret"
+)
+
+// deliveryBackoff is the wait before each retry; a delivery makes
+// len(deliveryBackoff)+1 attempts. Replaced in tests.
+var deliveryBackoff = []time.Duration{2 * time.Second, 10 * time.Second}
+
+// Event is something that happened which webhooks may want to react to.
+type Event struct {
+	Type   string
+	Time   time.Time
+	Fields map[string]interface{}
+}
+
+// Subscribed reports whether a webhook integration wants events of type.
+func Subscribed(integration *storage.Integration, eventType string) bool {
+	raw := strings.TrimSpace(integration.Config[webhookEventsConfigKey])
+	if raw == "" {
+		return true
+	}
+	for _, name := range strings.Split(raw, ",") {
+		if strings.TrimSpace(name) == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateEvents rejects unknown names in a webhook's events setting.
+func ValidateEvents(raw string) error {
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, event := range Events {
+			known = known || name == event
+		}
+		if !known {
+			return fmt.Errorf("unknown webhook event %q (available: %s)", name, strings.Join(Events, ", "))
+		}
+	}
+	return nil
+}
+
+// DeliverEvent posts the event to every enabled webhook integration
+// subscribed to it, retrying failures, and records each delivery. It blocks
+// until all attempts are done and returns the integrations that got it.
+func DeliverEvent(ctx context.Context, store storage.Store, event Event) []string {
+	integrations, err := store.ListIntegrations()
+	if err != nil {
+		logging.Warn("Webhook event %s not delivered: failed to list integrations: %v", event.Type, err)
+		return nil
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	var delivered []string
+	for _, integration := range integrations {
+		if integration == nil || !integration.Enabled || integration.Provider != "webhook" || !Subscribed(integration, event.Type) {
+			continue
+		}
+		delivery := deliverWebhookEvent(ctx, integration, event)
+		if err := store.SaveWebhookDelivery(delivery); err != nil {
+			logging.Warn("Failed to record webhook delivery %s for integration %s: %v", delivery.ID, integration.ID, err)
+		}
+		if delivery.Status == "delivered" {
+			delivered = append(delivered, integration.ID)
+		} else {
+			logging.Warn("Webhook event %s to integration %s failed after %d attempts: %s", event.Type, integration.ID, delivery.Attempts, delivery.Error)
+		}
+	}
+	return delivered
+}
+
+func deliverWebhookEvent(ctx context.Context, integration *storage.Integration, event Event) *storage.WebhookDelivery {
+	delivery := &storage.WebhookDelivery{
+		ID:            uuid.New().String(),
+		IntegrationID: integration.ID,
+		Event:         event.Type,
+		Status:        "failed",
+		CreatedAt:     event.Time,
+	}
+
+	payload := make(map[string]interface{}, len(event.Fields)+3)
+	for key, value := range event.Fields {
+		payload[key] = value
+	}
+	payload["event"] = event.Type
+	payload["delivery_id"] = delivery.ID
+	payload["timestamp"] = event.Time.UTC()
+	body, err := json.Marshal(payload)
+	if err != nil {
+		delivery.Error = "failed to encode payload: " + err.Error()
+		return delivery
+	}
+	delivery.Payload = string(body)
+
+	for attempt := 0; attempt <= len(deliveryBackoff); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				delivery.Error = ctx.Err().Error()
+				return delivery
+			case <-time.After(deliveryBackoff[attempt-1]):
+			}
+		}
+		delivery.Attempts = attempt + 1
+		code, err := postWebhook(ctx, integration.Config, body, map[string]string{EventHeader: event.Type, DeliveryHeader: delivery.ID})
+		delivery.ResponseCode = code
+		if err == nil {
+			delivery.Status = "delivered"
+			delivery.Error = ""
+			return delivery
+		}
+		delivery.Error = err.Error()
+		if code >= 400 && code < 500 && code != http.StatusTooManyRequests {
+			return delivery // the receiver rejected it; retrying won't help
+		}
+	}
+	return delivery
+}
+
+// postWebhook POSTs a JSON body to the webhook, signed when it has a secret,
+// and returns the response status (0 when none arrived).
+func postWebhook(ctx context.Context, config map[string]string, body []byte, headers map[string]string) (int, error) {
+	target := strings.TrimSpace(config["url"])
+	if target == "" {
+		return 0, fmt.Errorf("webhook integration is missing url")
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	if secret := config[webhookSecretConfigKey]; secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp.StatusCode, fmt.Errorf("webhook returned %s: %s", resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	return resp.StatusCode, nil
+}
+
+// Sign returns the SignatureHeader value for body.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
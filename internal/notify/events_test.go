@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestDeliverEvent(t *testing.T) {
+	previous := deliveryBackoff
+	deliveryBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { deliveryBackoff = previous }()
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	var calls int32
+	var body []byte
+	var header http.Header
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+	}))
+	defer hook.Close()
+
+	now := time.Now()
+	for _, integration := range []*storage.Integration{
+		{ID: "signed", Provider: "webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": hook.URL, "secret": "s3cret", "events": "session.completed, job.finished"}},
+		{ID: "jobs-only", Provider: "webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": hook.URL, "events": "job.finished"}},
+		{ID: "disabled", Provider: "webhook", Mode: "notify_only", Config: map[string]string{"url": hook.URL}},
+	} {
+		integration.CreatedAt, integration.UpdatedAt = now, now
+		if err := store.SaveIntegration(integration); err != nil {
+			t.Fatalf("SaveIntegration: %v", err)
+		}
+	}
+
+	delivered := DeliverEvent(context.Background(), store, Event{Type: EventSessionCompleted, Fields: map[string]interface{}{"session_id": "sess-1"}})
+	if len(delivered) != 1 || delivered[0] != "signed" || calls != 2 {
+		t.Fatalf("expected one delivery after a retry, got %v after %d calls", delivered, calls)
+	}
+	if header.Get(SignatureHeader) != Sign("s3cret", body) || header.Get(EventHeader) != EventSessionCompleted {
+		t.Fatalf("unexpected headers %v", header)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("decode payload: %v", err)
+	}
+	if payload["event"] != EventSessionCompleted || payload["session_id"] != "sess-1" || payload["delivery_id"] != header.Get(DeliveryHeader) || payload["timestamp"] == nil {
+		t.Fatalf("unexpected payload %+v", payload)
+	}
+
+	log, err := store.ListWebhookDeliveries("signed", 10)
+	if err != nil || len(log) != 1 {
+		t.Fatalf("expected one logged delivery, got %+v, %v", log, err)
+	}
+	if log[0].Status != "delivered" || log[0].Attempts != 2 || log[0].ResponseCode != http.StatusOK || log[0].Payload != string(body) {
+		t.Fatalf("unexpected delivery record %+v", log[0])
+	}
+}
+
+func TestDeliverEventGivesUp(t *testing.T) {
+	previous := deliveryBackoff
+	deliveryBackoff = []time.Duration{time.Millisecond, time.Millisecond}
+	defer func() { deliveryBackoff = previous }()
+
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+
+	var calls int32
+	status := http.StatusServiceUnavailable
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(status)
+	}))
+	defer hook.Close()
+	now := time.Now()
+	if err := store.SaveIntegration(&storage.Integration{ID: "hook", Provider: "webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": hook.URL}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveIntegration: %v", err)
+	}
+
+	if delivered := DeliverEvent(context.Background(), store, Event{Type: EventJobFinished}); len(delivered) != 0 || calls != 3 {
+		t.Fatalf("expected three failed attempts, got %v after %d calls", delivered, calls)
+	}
+	status = http.StatusBadRequest
+	DeliverEvent(context.Background(), store, Event{Type: EventJobFinished})
+	if calls != 4 {
+		t.Fatalf("a rejected delivery should not be retried, got %d calls", calls)
+	}
+
+	log, err := store.ListWebhookDeliveries("hook", 10)
+	if err != nil || len(log) != 2 {
+		t.Fatalf("expected two logged deliveries, got %+v, %v", log, err)
+	}
+	for _, d := range log {
+		if d.Status != "failed" || d.Error == "" {
+			t.Fatalf("unexpected delivery record %+v", d)
+		}
+	}
+}
+
+func TestValidateEvents(t *testing.T) {
+	if err := ValidateEvents(" session.failed ,job.finished"); err != nil {
+		t.Fatalf("ValidateEvents: %v", err)
+	}
+	if err := ValidateEvents("session.deleted"); err == nil {
+		t.Fatal("expected unknown events to be rejected")
+	}
+}
@@ -0,0 +1,154 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+const (
+	sendTimeout = 20 * time.Second
+	// telegramMaxMessageRunes keeps messages under Telegram's 4096 character limit.
+	telegramMaxMessageRunes = 3900
+)
+
+// telegramAPIBaseURL is replaced in tests.
+var telegramAPIBaseURL = "https://api.telegram.org"
+
+// Message is a notification delivered through an integration.
+type Message struct {
+	// Text is the human-readable body chat providers send as is.
+	Text string
+	// Fields are structured details webhook receivers get alongside Text.
+	Fields map[string]interface{}
+}
+
+type sender func(ctx context.Context, config map[string]string, msg Message) error
+
+var senders = map[string]sender{
+	"email":    sendEmail,
+	"slack":    sendSlack,
+	"telegram": sendTelegram,
+	"webhook":  sendWebhook,
+}
+
+// Supports reports whether messages can be sent through the provider.
+func Supports(provider string) bool {
+	_, ok := senders[provider]
+	return ok
+}
+
+// SupportedProviders lists the providers Send can deliver through.
+func SupportedProviders() []string {
+	providers := make([]string, 0, len(senders))
+	for provider := range senders {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// Send delivers msg through the integration.
+func Send(ctx context.Context, integration *storage.Integration, msg Message) error {
+	if integration == nil {
+		return fmt.Errorf("integration is nil")
+	}
+	if !integration.Enabled {
+		return fmt.Errorf("integration %s is disabled", integration.ID)
+	}
+	send, ok := senders[integration.Provider]
+	if !ok {
+		return fmt.Errorf("sending through %s integrations is not supported (supported: %s)", integration.Provider, strings.Join(SupportedProviders(), ", "))
+	}
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+	return send(ctx, integration.Config, msg)
+}
+
+// sendTelegram posts the text to the integration's default chat.
+func sendTelegram(ctx context.Context, config map[string]string, msg Message) error {
+	botToken := strings.TrimSpace(config["bot_token"])
+	chatID := strings.TrimSpace(config["default_chat_id"])
+	if botToken == "" {
+		return fmt.Errorf("telegram integration is missing bot_token")
+	}
+	if chatID == "" {
+		return fmt.Errorf("telegram integration has no default_chat_id to send to")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id": chatID,
+		"text":    truncateRunes(msg.Text, telegramMaxMessageRunes),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode sendMessage payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/bot%s/sendMessage", telegramAPIBaseURL, botToken), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build sendMessage request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// The request URL embeds the bot token; keep it out of the error.
+		return fmt.Errorf("telegram sendMessage request failed: %w", unwrapURLError(err))
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode sendMessage response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || !result.OK {
+		reason := strings.TrimSpace(result.Description)
+		if reason == "" {
+			reason = resp.Status
+		}
+		return fmt.Errorf("telegram sendMessage failed: %s", reason)
+	}
+	return nil
+}
+
+// sendWebhook posts the message as JSON: the fields plus a "text" key.
+func sendWebhook(ctx context.Context, config map[string]string, msg Message) error {
+	payload := make(map[string]interface{}, len(msg.Fields)+1)
+	for key, value := range msg.Fields {
+		payload[key] = value
+	}
+	payload["text"] = msg.Text
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	_, err = postWebhook(ctx, config, body, nil)
+	return err
+}
+
+func unwrapURLError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return urlErr.Err
+	}
+	return err
+}
+
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -0,0 +1,123 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func finishedExecution(status, output, errText string) (*storage.RecurringJob, *storage.JobExecution) {
+	started := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	finished := started.Add(95 * time.Second)
+	job := &storage.RecurringJob{ID: "job-1", Name: "nightly report"}
+	exec := &storage.JobExecution{ID: "exec-1", JobID: job.ID, SessionID: "sess-1", Status: status, Output: output, Error: errText, StartedAt: started, FinishedAt: &finished}
+	return job, exec
+}
+
+func TestSendTelegram(t *testing.T) {
+	var gotPath string
+	var got map[string]interface{}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer api.Close()
+	previous := telegramAPIBaseURL
+	telegramAPIBaseURL = api.URL
+	defer func() { telegramAPIBaseURL = previous }()
+
+	job, exec := finishedExecution("failed", "", "provider unavailable")
+	integration := &storage.Integration{ID: "tg", Provider: "telegram", Enabled: true, Config: map[string]string{"bot_token": "123:abc", "default_chat_id": "42"}}
+	if err := Send(context.Background(), integration, JobMessage(job, exec)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotPath != "/bot123:abc/sendMessage" || got["chat_id"] != "42" {
+		t.Fatalf("unexpected request %s %+v", gotPath, got)
+	}
+	text, _ := got["text"].(string)
+	for _, want := range []string{`Job "nightly report" failed after 1m35s`, "provider unavailable", "Session: sess-1"} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("message %q is missing %q", text, want)
+		}
+	}
+
+	delete(integration.Config, "default_chat_id")
+	if err := Send(context.Background(), integration, JobMessage(job, exec)); err == nil || !strings.Contains(err.Error(), "default_chat_id") {
+		t.Fatalf("expected a missing chat error, got %v", err)
+	}
+}
+
+func TestSendWebhook(t *testing.T) {
+	var got map[string]interface{}
+	status := http.StatusOK
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer hook.Close()
+
+	job, exec := finishedExecution("success", strings.Repeat("done ", 200), "")
+	integration := &storage.Integration{ID: "hook", Provider: "webhook", Enabled: true, Config: map[string]string{"url": hook.URL}}
+	if err := Send(context.Background(), integration, JobMessage(job, exec)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if got["status"] != "success" || got["job_name"] != "nightly report" || got["session_id"] != "sess-1" || got["duration_seconds"] != float64(95) {
+		t.Fatalf("unexpected payload %+v", got)
+	}
+	if summary, _ := got["summary"].(string); len([]rune(summary)) != jobSummaryRunes || !strings.HasSuffix(summary, "…") {
+		t.Fatalf("expected a truncated summary, got %d runes", len([]rune(summary)))
+	}
+	if text, _ := got["text"].(string); !strings.HasPrefix(text, `Job "nightly report" succeeded`) {
+		t.Fatalf("unexpected text %q", text)
+	}
+
+	status = http.StatusInternalServerError
+	if err := Send(context.Background(), integration, JobMessage(job, exec)); err == nil {
+		t.Fatal("expected a failing webhook to return an error")
+	}
+}
+
+func TestSendRejectsUnsupportedAndDisabled(t *testing.T) {
+	msg := Message{Text: "hi"}
+	if err := Send(context.Background(), &storage.Integration{ID: "d", Provider: "discord", Enabled: true}, msg); err == nil || !strings.Contains(err.Error(), "slack, telegram, webhook") {
+		t.Fatalf("expected an unsupported provider error, got %v", err)
+	}
+	if err := Send(context.Background(), &storage.Integration{ID: "w", Provider: "webhook"}, msg); err == nil {
+		t.Fatal("expected disabled integrations to be skipped")
+	}
+}
+
+func TestSendSlack(t *testing.T) {
+	var gotAuth string
+	var got map[string]interface{}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/chat.postMessage" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		gotAuth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{"ok":true,"ts":"1700000000.000100"}`))
+	}))
+	defer api.Close()
+
+	job, exec := finishedExecution("success", "all good", "")
+	integration := &storage.Integration{ID: "sl", Provider: "slack", Enabled: true, Config: map[string]string{"bot_token": "xoxb-1", "channel_id": "C123", "api_base_url": api.URL + "/api/"}}
+	if err := Send(context.Background(), integration, JobMessage(job, exec)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotAuth != "Bearer xoxb-1" || got["channel"] != "C123" || got["thread_ts"] != nil {
+		t.Fatalf("unexpected request auth=%q body=%+v", gotAuth, got)
+	}
+
+	ts, err := PostSlackMessage(context.Background(), integration.Config, "C999", "1699999999.000001", "reply")
+	if err != nil || ts != "1700000000.000100" || got["thread_ts"] != "1699999999.000001" || got["channel"] != "C999" {
+		t.Fatalf("threaded post: ts=%q err=%v body=%+v", ts, err, got)
+	}
+}
@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// jobSummaryRunes bounds the output or error excerpt in job notifications.
+const jobSummaryRunes = 500
+
+// JobMessage describes a finished job execution.
+func JobMessage(job *storage.RecurringJob, exec *storage.JobExecution) Message {
+	duration := time.Duration(0)
+	if exec.FinishedAt != nil {
+		duration = exec.FinishedAt.Sub(exec.StartedAt).Round(time.Second)
+	}
+	summary := exec.Output
+	if exec.Status != "success" {
+		summary = exec.Error
+	}
+	summary = truncateRunes(strings.TrimSpace(summary), jobSummaryRunes)
+
+	var text strings.Builder
+	fmt.Fprintf(&text, "Job %q %s after %s", job.Name, jobOutcome(exec.Status), duration)
+	if summary != "" {
+		text.WriteString("\n\n" + summary)
+	}
+	if exec.SessionID != "" {
+		text.WriteString("\n\nSession: " + exec.SessionID)
+	}
+
+	return Message{
+		Text: text.String(),
+		Fields: map[string]interface{}{
+			"event":            "job.finished",
+			"job_id":           job.ID,
+			"job_name":         job.Name,
+			"execution_id":     exec.ID,
+			"status":           exec.Status,
+			"duration_seconds": int64(duration / time.Second),
+			"summary":          summary,
+			"session_id":       exec.SessionID,
+			"started_at":       exec.StartedAt,
+			"finished_at":      exec.FinishedAt,
+		},
+	}
+}
+
+// JobQuestionMessage asks the question a job run is waiting on, listing the
+// options by number so chat replies can pick one.
+func JobQuestionMessage(job *storage.RecurringJob, exec *storage.JobExecution, question *session.QuestionData) Message {
+	var text strings.Builder
+	fmt.Fprintf(&text, "Job %q is waiting for an answer", job.Name)
+	if header := strings.TrimSpace(question.Header); header != "" {
+		text.WriteString(" (" + header + ")")
+	}
+	text.WriteString(":\n\n" + strings.TrimSpace(question.Question))
+
+	labels := make([]string, 0, len(question.Options))
+	if len(question.Options) > 0 {
+		text.WriteString("\n")
+	}
+	for i, option := range question.Options {
+		labels = append(labels, option.Label)
+		fmt.Fprintf(&text, "\n%d. %s", i+1, option.Label)
+		if description := strings.TrimSpace(option.Description); description != "" {
+			text.WriteString(" - " + description)
+		}
+	}
+
+	text.WriteString("\n\nReply with ")
+	switch {
+	case len(labels) == 0:
+		text.WriteString("your answer")
+	case question.Multiple:
+		text.WriteString("the numbers or labels of your choices, separated by commas")
+	default:
+		text.WriteString("the number or label of your choice")
+	}
+	if len(labels) > 0 && question.Custom {
+		text.WriteString(", or any other answer")
+	}
+	text.WriteString(", or answer through POST /sessions/" + exec.SessionID + "/answer.")
+
+	return Message{
+		Text: text.String(),
+		Fields: map[string]interface{}{
+			"event":        "job.input_required",
+			"job_id":       job.ID,
+			"job_name":     job.Name,
+			"execution_id": exec.ID,
+			"status":       exec.Status,
+			"session_id":   exec.SessionID,
+			"question":     question.Question,
+			"options":      labels,
+			"multiple":     question.Multiple,
+			"custom":       question.Custom,
+			"started_at":   exec.StartedAt,
+		},
+	}
+}
+
+func jobOutcome(status string) string {
+	switch status {
+	case "success":
+		return "succeeded"
+	case "cancelled":
+		return "was cancelled"
+	case "timed_out":
+		return "timed out"
+	default:
+		return status
+	}
+}
@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const (
+	// SlackMaxMessageRunes keeps messages within what Slack displays in full.
+	SlackMaxMessageRunes = 3900
+	// defaultSlackAPIBaseURL is used unless the integration sets api_base_url
+	// (e.g. https://slack-gov.com/api).
+	defaultSlackAPIBaseURL = "https://slack.com/api"
+)
+
+// PostSlackMessage posts text to a channel with chat.postMessage, as a reply
+// in threadTS when set, and returns the new message's ts.
+func PostSlackMessage(ctx context.Context, config map[string]string, channel, threadTS, text string) (string, error) {
+	botToken := strings.TrimSpace(config["bot_token"])
+	if botToken == "" {
+		return "", fmt.Errorf("slack integration is missing bot_token")
+	}
+	if strings.TrimSpace(channel) == "" {
+		return "", fmt.Errorf("slack integration has no channel to post to")
+	}
+	baseURL := strings.TrimRight(strings.TrimSpace(config["api_base_url"]), "/")
+	if baseURL == "" {
+		baseURL = defaultSlackAPIBaseURL
+	}
+
+	payload := map[string]interface{}{
+		"channel": channel,
+		"text":    truncateRunes(text, SlackMaxMessageRunes),
+	}
+	if threadTS != "" {
+		payload["thread_ts"] = threadTS
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode chat.postMessage payload: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build chat.postMessage request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+botToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("slack chat.postMessage request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+		TS    string `json:"ts"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode chat.postMessage response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK || !result.OK {
+		reason := strings.TrimSpace(result.Error)
+		if reason == "" {
+			reason = resp.Status
+		}
+		return "", fmt.Errorf("slack chat.postMessage failed: %s", reason)
+	}
+	return result.TS, nil
+}
+
+// sendSlack posts the text to the integration's channel_id.
+func sendSlack(ctx context.Context, config map[string]string, msg Message) error {
+	_, err := PostSlackMessage(ctx, config, strings.TrimSpace(config["channel_id"]), "", msg.Text)
+	return err
+}
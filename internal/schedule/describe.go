@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var weekdayNames = []string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"}
+
+// Describe renders a 5-field cron expression in words, e.g. "every weekday
+// at 08:30". Expressions without a simple reading are described as
+// "cron schedule <expr>".
+func Describe(expr string) string {
+	fields := strings.Fields(expr)
+	fallback := "cron schedule " + strings.Join(fields, " ")
+	if len(fields) != 5 {
+		return fallback
+	}
+	minute, hour, dom, month, dow := fields[0], fields[1], fields[2], fields[3], fields[4]
+	if month != "*" {
+		return fallback
+	}
+
+	// Intervals within the day.
+	if dom == "*" && dow == "*" {
+		switch {
+		case minute == "*" && hour == "*":
+			return "every minute"
+		case strings.HasPrefix(minute, "*/") && hour == "*":
+			return fmt.Sprintf("every %s minutes", strings.TrimPrefix(minute, "*/"))
+		}
+		if m, ok := singleValue(minute, 59); ok {
+			switch {
+			case hour == "*":
+				if m == 0 {
+					return "every hour"
+				}
+				return fmt.Sprintf("every hour at :%02d", m)
+			case strings.HasPrefix(hour, "*/"):
+				if m == 0 {
+					return fmt.Sprintf("every %s hours", strings.TrimPrefix(hour, "*/"))
+				}
+				return fmt.Sprintf("every %s hours at :%02d", strings.TrimPrefix(hour, "*/"), m)
+			}
+		}
+	}
+
+	m, ok := singleValue(minute, 59)
+	if !ok {
+		return fallback
+	}
+	hours, ok := valueList(hour, 23)
+	if !ok {
+		return fallback
+	}
+	times := make([]string, len(hours))
+	for i, h := range hours {
+		times[i] = fmt.Sprintf("%02d:%02d", h, m)
+	}
+	at := "at " + joinWords(times)
+
+	switch {
+	case dom == "*" && dow == "*":
+		return "every day " + at
+	case dom == "*":
+		days, ok := valueList(dow, 7)
+		if !ok {
+			return fallback
+		}
+		return describeWeekdays(days) + " " + at
+	case dow == "*":
+		day, ok := singleValue(dom, 31)
+		if !ok || day < 1 {
+			return fallback
+		}
+		return fmt.Sprintf("every month on the %s %s", ordinal(day), at)
+	}
+	return fallback
+}
+
+func describeWeekdays(days []int) string {
+	set := make(map[int]bool, len(days))
+	for _, d := range days {
+		set[d%7] = true
+	}
+	switch {
+	case len(set) == 5 && !set[0] && !set[6]:
+		return "every weekday"
+	case len(set) == 2 && set[0] && set[6]:
+		return "every weekend day"
+	case len(set) == 7:
+		return "every day"
+	}
+	ordered := make([]int, 0, len(set))
+	for d := range set {
+		ordered = append(ordered, d)
+	}
+	// Monday first reads more naturally than cron's Sunday first.
+	sort.Slice(ordered, func(i, j int) bool { return (ordered[i]+6)%7 < (ordered[j]+6)%7 })
+	names := make([]string, len(ordered))
+	for i, d := range ordered {
+		names[i] = weekdayNames[d]
+	}
+	return "every " + joinWords(names)
+}
+
+// singleValue parses a field holding one number in [0, max].
+func singleValue(field string, max int) (int, bool) {
+	n, err := strconv.Atoi(field)
+	if err != nil || n < 0 || n > max {
+		return 0, false
+	}
+	return n, true
+}
+
+// valueList expands a field of numbers and plain ranges such as "1-5,7".
+func valueList(field string, max int) ([]int, bool) {
+	var values []int
+	for _, part := range strings.Split(field, ",") {
+		lo, hi, isRange := strings.Cut(part, "-")
+		start, ok := singleValue(lo, max)
+		if !ok {
+			return nil, false
+		}
+		end := start
+		if isRange {
+			if end, ok = singleValue(hi, max); !ok || end < start {
+				return nil, false
+			}
+		}
+		for v := start; v <= end; v++ {
+			values = append(values, v)
+		}
+	}
+	return values, true
+}
+
+func joinWords(words []string) string {
+	switch len(words) {
+	case 0:
+		return ""
+	case 1:
+		return words[0]
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " and " + words[len(words)-1]
+}
+
+func ordinal(n int) string {
+	suffix := "th"
+	switch {
+	case n%100 >= 11 && n%100 <= 13:
+	case n%10 == 1:
+		suffix = "st"
+	case n%10 == 2:
+		suffix = "nd"
+	case n%10 == 3:
+		suffix = "rd"
+	}
+	return strconv.Itoa(n) + suffix
+}
@@ -0,0 +1,61 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cronFieldPattern matches a numeric cron field such as "*/15" or "1-5".
+var cronFieldPattern = regexp.MustCompile(`^[0-9*/,\-]+$`)
+
+// scheduleExamples is suggested when no part of a schedule can be blamed.
+const scheduleExamples = `try "every weekday at 9am", "every 15 minutes", "tomorrow at 9am" or a cron expression such as "0 9 * * 1-5"`
+
+// unrecognizedReason names the part of text that Parse could not read: a
+// bad cron field, an interval cron cannot express, or the time or day part
+// of a calendar phrase.
+func unrecognizedReason(text string) string {
+	if fields := strings.Fields(text); len(fields) == 5 {
+		numeric := 0
+		for _, field := range fields {
+			if cronFieldPattern.MatchString(field) {
+				numeric++
+			}
+		}
+		if numeric >= 3 {
+			if err := Validate(strings.Join(fields, " ")); err != nil {
+				return err.Error()
+			}
+		}
+	}
+
+	s := normalize(text)
+	if m := everyNMinutes.FindStringSubmatch(s); m != nil {
+		return fmt.Sprintf("an interval of %s minutes cannot be expressed in cron; use 1 to 59 minutes, or hours", m[1])
+	}
+	if m := everyNHours.FindStringSubmatch(s); m != nil {
+		if n, _ := strconv.Atoi(m[1]); n < 1 || n > 23 {
+			return fmt.Sprintf("an interval of %s hours cannot be expressed in cron; use 1 to 23 hours, or a daily schedule", m[1])
+		}
+		return fmt.Sprintf("minute %s is past the end of the hour", m[2])
+	}
+	if m := hourlyAtMinute.FindStringSubmatch(s); m != nil {
+		return fmt.Sprintf("minute %s is past the end of the hour", m[1])
+	}
+
+	dayPart, timePart := splitCalendar(s)
+	if timePart != "" {
+		if _, _, ok := parseTimes(timePart); !ok {
+			if len(splitList(timePart)) > 1 {
+				return fmt.Sprintf("could not read the times %q; several times must be valid and share the same minute", timePart)
+			}
+			return fmt.Sprintf("could not read the time %q", timePart)
+		}
+	}
+	if _, _, ok := parseDays(dayPart); !ok && dayPart != s {
+		return fmt.Sprintf("could not read the days %q", dayPart)
+	}
+	return scheduleExamples
+}
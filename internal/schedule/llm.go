@@ -0,0 +1,72 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+const llmSystemPrompt = "You convert natural-language schedules into strict 5-field cron expressions. Reply with the cron expression only."
+
+const llmPromptTemplate = `Convert the following natural language schedule to a standard 5-field cron expression
+(minute hour day-of-month month day-of-week).
+Only respond with the cron expression, nothing else. No explanation, no formatting, just the cron expression.
+
+Schedule: %q
+
+Examples:
+- "every day at 7pm" -> "0 19 * * *"
+- "every Monday at 9am" -> "0 9 * * 1"
+- "every hour" -> "0 * * * *"
+- "every weekday at 8:30am" -> "30 8 * * 1-5"
+- "every 15 minutes" -> "*/15 * * * *"
+
+Cron expression:`
+
+// ParseWithLLM tries Parse first and only asks the model when the text is
+// not recognized. The model gets a single tool-free request, and its answer
+// is accepted only if it is a valid cron expression.
+func ParseWithLLM(ctx context.Context, client llm.Client, model, text string) (Schedule, error) {
+	parsed, err := Parse(text)
+	if err == nil || client == nil {
+		return parsed, err
+	}
+	if !errors.Is(err, ErrUnrecognized) {
+		return Schedule{}, err
+	}
+
+	resp, err := client.Chat(ctx, &llm.ChatRequest{
+		Model:        model,
+		Messages:     []llm.Message{{Role: "user", Content: fmt.Sprintf(llmPromptTemplate, text)}},
+		Temperature:  0,
+		MaxTokens:    64,
+		SystemPrompt: llmSystemPrompt,
+	})
+	if err != nil {
+		return Schedule{}, fmt.Errorf("failed to parse schedule: %w", err)
+	}
+
+	expr := extractCron(resp.Content)
+	if err := Validate(expr); err != nil {
+		return Schedule{}, fmt.Errorf("could not understand schedule %q (%s); the model answered %q", text, unrecognizedReason(text), strings.TrimSpace(resp.Content))
+	}
+	return Schedule{Cron: expr, Description: Describe(expr)}, nil
+}
+
+// extractCron picks the cron expression out of a model reply, tolerating
+// quotes, code fences and a leading label.
+func extractCron(reply string) string {
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.Trim(strings.TrimSpace(line), "`\"'")
+		if i := strings.LastIndex(line, ":"); i >= 0 && len(strings.Fields(line[i+1:])) == 5 {
+			line = line[i+1:]
+		}
+		if fields := strings.Fields(line); len(fields) == 5 {
+			return strings.Join(fields, " ")
+		}
+	}
+	return strings.TrimSpace(reply)
+}
@@ -0,0 +1,111 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// exampleZones are suggested when a timezone name is not recognized.
+var exampleZones = []string{"UTC", "Europe/London", "America/New_York", "Asia/Tokyo"}
+
+// LoadLocation resolves an IANA timezone name. An empty name is the server's
+// local zone, which is what jobs created before timezones existed use.
+func LoadLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: use an IANA zone name such as %s", name, strings.Join(exampleZones, ", "))
+	}
+	return loc, nil
+}
+
+// DefaultTimezone returns the IANA name of the server's local zone, from TZ
+// or the /etc/localtime link, and "Local" when neither names one.
+func DefaultTimezone() string {
+	if tz := strings.TrimPrefix(os.Getenv("TZ"), ":"); tz != "" {
+		if _, err := time.LoadLocation(tz); err == nil {
+			return tz
+		}
+	}
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(filepath.ToSlash(target), "zoneinfo/"); ok {
+			if _, err := time.LoadLocation(name); err == nil {
+				return name
+			}
+		}
+	}
+	return time.Local.String()
+}
+
+// NextRun returns the first time after after that cronExpr matches on the
+// wall clock of timezone. Across DST changes a time skipped when clocks go
+// forward runs once right after the gap (02:30 becomes 03:30), and a time
+// repeated when clocks go back runs only at its first occurrence.
+func NextRun(cronExpr, timezone string, after time.Time) (time.Time, error) {
+	loc, err := LoadLocation(timezone)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if err := Validate(cronExpr); err != nil {
+		return time.Time{}, err
+	}
+	spec, _ := cronParser.Parse(cronExpr)
+
+	// Match the expression against wall-clock time, kept in UTC so the
+	// search itself never crosses a DST change, then map each match back
+	// into the zone.
+	wall := wallClock(after.In(loc))
+	for i := 0; i < 4; i++ {
+		wall = spec.Next(wall)
+		if wall.IsZero() {
+			break
+		}
+		if next, ok := instantAt(wall, loc, after); ok {
+			return next.In(after.Location()), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("cron expression %q has no upcoming run", cronExpr)
+}
+
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// instantAt returns the earliest instant after after whose wall clock in loc
+// reads wall. A wall time inside a DST gap maps to the instant the same
+// distance past the gap.
+func instantAt(wall time.Time, loc *time.Location, after time.Time) (time.Time, bool) {
+	// Offsets a day either side cover both sides of any transition.
+	_, before := wall.Add(-24 * time.Hour).In(loc).Zone()
+	_, later := wall.Add(24 * time.Hour).In(loc).Zone()
+	candidates := []time.Time{
+		wall.Add(-time.Duration(before) * time.Second),
+		wall.Add(-time.Duration(later) * time.Second),
+	}
+	if candidates[1].Before(candidates[0]) {
+		candidates[0], candidates[1] = candidates[1], candidates[0]
+	}
+
+	matched := false
+	for _, c := range candidates {
+		if !wallClock(c.In(loc)).Equal(wall) {
+			continue
+		}
+		matched = true
+		if c.After(after) {
+			return c, true
+		}
+	}
+	if matched {
+		return time.Time{}, false
+	}
+	// In a gap: the pre-transition offset lands just past it.
+	gap := wall.Add(-time.Duration(before) * time.Second)
+	return gap, gap.After(after)
+}
@@ -0,0 +1,88 @@
+package schedule
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNextRun(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	at := func(value string) time.Time {
+		parsed, err := time.ParseInLocation("2006-01-02 15:04 MST", value, newYork)
+		if err != nil {
+			t.Fatalf("bad test time %q: %v", value, err)
+		}
+		return parsed
+	}
+	utc := func(value string) time.Time {
+		parsed, err := time.Parse("2006-01-02 15:04", value)
+		if err != nil {
+			t.Fatalf("bad test time %q: %v", value, err)
+		}
+		return parsed
+	}
+
+	tests := []struct {
+		name     string
+		cron     string
+		timezone string
+		after    time.Time
+		want     time.Time
+	}{
+		{"weekday in zone", "30 8 * * 1-5", "America/New_York", utc("2026-07-03 13:00"), utc("2026-07-06 12:30")},
+		{"utc zone", "30 8 * * 1-5", "UTC", utc("2026-07-03 13:00"), utc("2026-07-06 08:30")},
+		{"skipped time runs after the gap", "30 2 * * *", "America/New_York", at("2026-03-07 02:30 EST"), utc("2026-03-08 07:30")},
+		{"day after the gap is normal", "30 2 * * *", "America/New_York", utc("2026-03-08 07:30"), utc("2026-03-09 06:30")},
+		{"interval continues after the gap", "*/15 * * * *", "America/New_York", at("2026-03-08 01:45 EST"), utc("2026-03-08 07:00")},
+		{"repeated time runs at first occurrence", "30 1 * * *", "America/New_York", at("2026-10-31 01:30 EDT"), utc("2026-11-01 05:30")},
+		{"repeated time does not run twice", "30 1 * * *", "America/New_York", utc("2026-11-01 05:30"), utc("2026-11-02 06:30")},
+		{"second occurrence after restart", "45 1 * * *", "America/New_York", utc("2026-11-01 06:10"), utc("2026-11-01 06:45")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextRun(tt.cron, tt.timezone, tt.after)
+			if err != nil {
+				t.Fatalf("NextRun: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("NextRun(%q, %q, %s) = %s, want %s", tt.cron, tt.timezone, tt.after.UTC(), got.UTC(), tt.want.UTC())
+			}
+		})
+	}
+}
+
+func TestNextRunNeverRepeatsAcrossDST(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Berlin"); err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	for _, expr := range []string{"30 2 * * *", "0 * * * *", "*/20 * * * *"} {
+		last := time.Date(2026, 3, 27, 0, 0, 0, 0, time.UTC)
+		for i := 0; i < 500; i++ {
+			next, err := NextRun(expr, "Europe/Berlin", last)
+			if err != nil {
+				t.Fatalf("NextRun(%q): %v", expr, err)
+			}
+			if !next.After(last) {
+				t.Fatalf("NextRun(%q) went from %s to %s", expr, last, next)
+			}
+			last = next
+		}
+	}
+}
+
+func TestLoadLocation(t *testing.T) {
+	if loc, err := LoadLocation(" "); err != nil || loc != time.Local {
+		t.Fatalf("empty timezone should be the local zone, got %v, %v", loc, err)
+	}
+	_, err := LoadLocation("Mars/Olympus_Mons")
+	if err == nil || !strings.Contains(err.Error(), "Mars/Olympus_Mons") || !strings.Contains(err.Error(), "America/New_York") {
+		t.Fatalf("expected an error listing example zones, got %v", err)
+	}
+	if _, err := NextRun("0 9 * * *", "Mars/Olympus_Mons", time.Now()); err == nil {
+		t.Fatal("NextRun should reject unknown timezones")
+	}
+}
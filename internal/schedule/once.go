@@ -0,0 +1,190 @@
+package schedule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	inDurationPattern = regexp.MustCompile(`^in (\d+) ?(minutes?|mins?|m|hours?|hrs?|h|days?|d)$`)
+	isoDatePattern    = regexp.MustCompile(`^(\d{4})-(\d{1,2})-(\d{1,2})$`)
+)
+
+var monthNumbers = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// ParseAt is Parse for a schedule entered at now: one-time phrasings such as
+// "tomorrow at 9am", "on March 3rd at noon" or "in 2 hours" are resolved in
+// now's location to an absolute RunAt instead of a cron expression.
+func ParseAt(text string, now time.Time) (Schedule, error) {
+	runAt, ok := ParseOnce(text, now)
+	if !ok {
+		return Parse(text)
+	}
+	if !runAt.After(now) {
+		return Schedule{}, fmt.Errorf("%s is in the past", runAt.Format("Mon 2 Jan 2006 15:04 MST"))
+	}
+	return Schedule{RunAt: runAt, Description: DescribeOnce(runAt)}, nil
+}
+
+// DescribeOnce renders a one-time run, e.g. "once on Tue 3 Mar 2026 at 12:00 CET".
+func DescribeOnce(t time.Time) string {
+	return "once on " + t.Format("Mon 2 Jan 2006 at 15:04 MST")
+}
+
+// ParseOnce recognizes one-time schedules relative to now: "in N
+// minutes/hours/days", and "today", "tomorrow", "next <weekday>", "<month>
+// <day> [year]", "the <day> of <month>" or "YYYY-MM-DD" with a time. Dates
+// without a year mean their next occurrence.
+func ParseOnce(text string, now time.Time) (time.Time, bool) {
+	raw := strings.TrimSpace(text)
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04"} {
+		if t, err := time.ParseInLocation(layout, raw, now.Location()); err == nil {
+			return t, true
+		}
+	}
+
+	s := normalize(text)
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(s, "once "), "one time "))
+	s = strings.TrimSpace(strings.TrimSuffix(strings.TrimSuffix(s, " once"), " only"))
+
+	if m := inDurationPattern.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		unit := time.Minute
+		switch m[2][0] {
+		case 'h':
+			unit = time.Hour
+		case 'd':
+			unit = 24 * time.Hour
+		}
+		if n < 1 {
+			return time.Time{}, false
+		}
+		return now.Add(time.Duration(n) * unit).Truncate(time.Minute), true
+	}
+
+	datePart, timePart := splitDateTime(s)
+	if datePart == "" || timePart == "" {
+		return time.Time{}, false
+	}
+	hour, minute, ok := parseClock(timePart)
+	if !ok {
+		return time.Time{}, false
+	}
+	year, month, day, ok := parseDate(datePart, now)
+	if !ok {
+		return time.Time{}, false
+	}
+	t := time.Date(year, month, day, hour, minute, 0, 0, now.Location())
+	if t.Day() != day {
+		return time.Time{}, false // e.g. February 30th
+	}
+	return t, true
+}
+
+// splitDateTime splits "tomorrow at 9am", "at 9am tomorrow" and "march 3
+// 12:30" into their date and time parts.
+func splitDateTime(s string) (date, clock string) {
+	if rest, ok := strings.CutPrefix(s, "at "); ok {
+		words := strings.Fields(rest)
+		for n := 2; n >= 1; n-- {
+			if n < len(words) {
+				head := strings.Join(words[:n], " ")
+				if _, _, ok := parseClock(head); ok {
+					return strings.Join(words[n:], " "), head
+				}
+			}
+		}
+		return "", ""
+	}
+	if i := strings.LastIndex(s, " at "); i >= 0 {
+		return s[:i], s[i+len(" at "):]
+	}
+	if i := lastTimeIndex(s); i > 0 {
+		return strings.TrimSpace(s[:i]), s[i:]
+	}
+	return "", ""
+}
+
+func parseDate(s string, now time.Time) (year int, month time.Month, day int, ok bool) {
+	s = strings.TrimSpace(strings.TrimPrefix(s, "on "))
+	switch s {
+	case "today":
+		return now.Year(), now.Month(), now.Day(), true
+	case "tomorrow":
+		t := now.AddDate(0, 0, 1)
+		return t.Year(), t.Month(), t.Day(), true
+	case "day after tomorrow", "the day after tomorrow":
+		t := now.AddDate(0, 0, 2)
+		return t.Year(), t.Month(), t.Day(), true
+	}
+
+	if name, found := strings.CutPrefix(s, "next "); found {
+		weekday, known := weekdayNumbers[name]
+		if !known {
+			return 0, 0, 0, false
+		}
+		ahead := (weekday - int(now.Weekday()) + 7) % 7
+		if ahead == 0 {
+			ahead = 7
+		}
+		t := now.AddDate(0, 0, ahead)
+		return t.Year(), t.Month(), t.Day(), true
+	}
+
+	if m := isoDatePattern.FindStringSubmatch(s); m != nil {
+		year, _ = strconv.Atoi(m[1])
+		mon, _ := strconv.Atoi(m[2])
+		day, _ = strconv.Atoi(m[3])
+		if mon < 1 || mon > 12 || day < 1 || day > 31 {
+			return 0, 0, 0, false
+		}
+		return year, time.Month(mon), day, true
+	}
+
+	// "march 3rd", "march 3rd 2027", "3 march", "the 3rd of march"
+	words := strings.Fields(strings.NewReplacer("the ", "", " of ", " ").Replace(" " + s + " "))
+	if len(words) < 2 || len(words) > 3 {
+		return 0, 0, 0, false
+	}
+	monthWord, dayWord := words[0], words[1]
+	if _, isMonth := monthNumbers[monthWord]; !isMonth {
+		monthWord, dayWord = words[1], words[0]
+	}
+	month, isMonth := monthNumbers[monthWord]
+	m := ordinalPattern.FindStringSubmatch(dayWord)
+	if !isMonth || m == nil {
+		return 0, 0, 0, false
+	}
+	day, _ = strconv.Atoi(m[1])
+	if day < 1 || day > 31 {
+		return 0, 0, 0, false
+	}
+	if len(words) == 3 {
+		year, err := strconv.Atoi(words[2])
+		if err != nil || year < now.Year() {
+			return 0, 0, 0, false
+		}
+		return year, month, day, true
+	}
+	year = now.Year()
+	if time.Date(year, month, day+1, 0, 0, 0, 0, now.Location()).Before(now) {
+		year++
+	}
+	return year, month, day, true
+}
@@ -0,0 +1,352 @@
+// Package schedule turns natural-language job schedules such as "every
+// weekday at 8:30am" into 5-field cron expressions and computes their next
+// runs in a job's timezone.
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// Schedule is a parsed schedule: the cron expression, or for one-time
+// schedules the run time, and a rendering of what it means, for echoing
+// back to the user.
+type Schedule struct {
+	Cron        string
+	RunAt       time.Time
+	Description string
+}
+
+// ErrUnrecognized is returned by Parse for text it has no rule for.
+var ErrUnrecognized = errors.New("schedule not recognized")
+
+var cronParser = cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
+
+// Validate reports whether expr is a valid standard 5-field cron expression.
+func Validate(expr string) error {
+	if len(strings.Fields(expr)) != 5 {
+		return fmt.Errorf("invalid cron expression %q: expected 5 fields", expr)
+	}
+	if _, err := cronParser.Parse(expr); err != nil {
+		return fmt.Errorf("invalid cron expression %q: %w", expr, err)
+	}
+	return nil
+}
+
+// Parse converts the common schedule forms deterministically: every N
+// minutes or hours, hourly, daily, weekdays, weekends, weekly or specific
+// weekdays at one or more times, monthly on a day, and plain cron
+// expressions. Anything else returns ErrUnrecognized.
+func Parse(text string) (Schedule, error) {
+	normalized := normalize(text)
+	if normalized == "" {
+		return Schedule{}, fmt.Errorf("schedule is empty")
+	}
+
+	expr, ok := parseCron(strings.TrimSpace(text))
+	if !ok {
+		expr, ok = parseInterval(normalized)
+	}
+	if !ok {
+		expr, ok = parseCalendar(normalized)
+	}
+	if !ok {
+		return Schedule{}, fmt.Errorf("%w: %q: %s", ErrUnrecognized, text, unrecognizedReason(text))
+	}
+	if err := Validate(expr); err != nil {
+		return Schedule{}, err
+	}
+	return Schedule{Cron: expr, Description: Describe(expr)}, nil
+}
+
+var (
+	nonWordPattern   = regexp.MustCompile(`[^a-z0-9:&\-]+`)
+	spacePattern     = regexp.MustCompile(`\s+`)
+	everyNMinutes    = regexp.MustCompile(`^(?:every|each) (\d+) ?(?:minutes?|mins?|m)$`)
+	everyNHours      = regexp.MustCompile(`^(?:every|each) (\d+) ?(?:hours?|hrs?|h)(?: at :?(\d{1,2}))?$`)
+	hourlyAtMinute   = regexp.MustCompile(`^(?:every hour|each hour|hourly) at (?::|minute )?(\d{1,2})(?: past)?$`)
+	clockTimePattern = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))? ?(am|pm)?$`)
+	ordinalPattern   = regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`)
+)
+
+// normalize lowercases text, drops punctuation other than what times and
+// ranges need, and collapses whitespace.
+func normalize(text string) string {
+	s := strings.ToLower(strings.TrimSpace(text))
+	s = strings.NewReplacer("a.m.", "am", "p.m.", "pm", "o'clock", "").Replace(s)
+	s = nonWordPattern.ReplaceAllString(s, " ")
+	return strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+}
+
+func parseCron(text string) (string, bool) {
+	fields := strings.Fields(text)
+	if len(fields) != 5 {
+		return "", false
+	}
+	expr := strings.Join(fields, " ")
+	if Validate(expr) != nil {
+		return "", false
+	}
+	return expr, true
+}
+
+func parseInterval(s string) (string, bool) {
+	switch s {
+	case "every minute", "each minute", "every 1 minute", "minutely":
+		return "* * * * *", true
+	case "every hour", "each hour", "hourly", "every 1 hour":
+		return "0 * * * *", true
+	case "every half hour", "every half an hour", "twice an hour":
+		return "*/30 * * * *", true
+	case "every quarter hour", "every quarter of an hour":
+		return "*/15 * * * *", true
+	}
+
+	if m := everyNMinutes.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		if n < 1 || n > 59 {
+			return "", false
+		}
+		if n == 1 {
+			return "* * * * *", true
+		}
+		return fmt.Sprintf("*/%d * * * *", n), true
+	}
+	if m := everyNHours.FindStringSubmatch(s); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		minute := 0
+		if m[2] != "" {
+			minute, _ = strconv.Atoi(m[2])
+		}
+		if n < 1 || n > 23 || minute > 59 {
+			return "", false
+		}
+		if n == 1 {
+			return fmt.Sprintf("%d * * * *", minute), true
+		}
+		return fmt.Sprintf("%d */%d * * *", minute, n), true
+	}
+	if m := hourlyAtMinute.FindStringSubmatch(s); m != nil {
+		minute, _ := strconv.Atoi(m[1])
+		if minute > 59 {
+			return "", false
+		}
+		return fmt.Sprintf("%d * * * *", minute), true
+	}
+	return "", false
+}
+
+// parseCalendar handles "<days> at <times>" forms, in either order.
+func parseCalendar(s string) (string, bool) {
+	dayPart, timePart := splitCalendar(s)
+
+	minute, hours, ok := "0", "0", true
+	if timePart != "" {
+		minute, hours, ok = parseTimes(timePart)
+		if !ok {
+			return "", false
+		}
+	}
+
+	dom, dow, ok := parseDays(dayPart)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s %s %s * %s", minute, hours, dom, dow), true
+}
+
+// splitCalendar splits a calendar schedule into its day and time parts.
+func splitCalendar(s string) (dayPart, timePart string) {
+	dayPart = s
+	if strings.HasPrefix(s, "at ") {
+		// "at 9am every day"
+		rest := strings.TrimPrefix(s, "at ")
+		for _, sep := range []string{" every ", " each ", " on ", " daily", " weekly", " monthly"} {
+			if i := strings.Index(rest, sep); i >= 0 {
+				timePart, dayPart = rest[:i], strings.TrimSpace(rest[i:])
+				break
+			}
+		}
+		if timePart == "" {
+			timePart, dayPart = rest, "daily"
+		}
+	} else if i := strings.Index(s, " at "); i >= 0 {
+		dayPart, timePart = s[:i], s[i+len(" at "):]
+	} else if i := lastTimeIndex(s); i >= 0 {
+		// "daily 9am", "weekdays 8:30"
+		dayPart, timePart = strings.TrimSpace(s[:i]), s[i:]
+	}
+	return dayPart, timePart
+}
+
+// lastTimeIndex returns where a trailing clock time starts in s, or -1.
+func lastTimeIndex(s string) int {
+	words := strings.Fields(s)
+	for n := 1; n <= 2 && n < len(words); n++ {
+		tail := strings.Join(words[len(words)-n:], " ")
+		if _, _, ok := parseClock(tail); ok {
+			return len(s) - len(tail)
+		}
+	}
+	return -1
+}
+
+// parseTimes parses "7pm", "9am and 5pm" or "8:30, 17:30". Several times
+// need the same minute to fit in one cron expression.
+func parseTimes(s string) (minute, hours string, ok bool) {
+	parts := splitList(s)
+	if len(parts) == 0 {
+		return "", "", false
+	}
+	hourSet := make(map[int]bool)
+	minuteValue := -1
+	for i := 0; i < len(parts); i++ {
+		part := parts[i]
+		// Rejoin "7 pm" split by splitList.
+		if i+1 < len(parts) && (parts[i+1] == "am" || parts[i+1] == "pm") {
+			part += parts[i+1]
+			i++
+		}
+		h, m, ok := parseClock(part)
+		if !ok || (minuteValue >= 0 && m != minuteValue) {
+			return "", "", false
+		}
+		minuteValue = m
+		hourSet[h] = true
+	}
+	return strconv.Itoa(minuteValue), joinInts(hourSet), true
+}
+
+func parseClock(s string) (hour, minute int, ok bool) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), " ", "")
+	switch s {
+	case "noon", "midday":
+		return 12, 0, true
+	case "midnight":
+		return 0, 0, true
+	}
+	m := clockTimePattern.FindStringSubmatch(s)
+	if m == nil {
+		return 0, 0, false
+	}
+	hour, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		minute, _ = strconv.Atoi(m[2])
+	}
+	switch m[3] {
+	case "am":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour == 12 {
+			hour = 0
+		}
+	case "pm":
+		if hour < 1 || hour > 12 {
+			return 0, 0, false
+		}
+		if hour != 12 {
+			hour += 12
+		}
+	default:
+		// A bare number is only a time with minutes ("8:30"), not "every 5".
+		if m[2] == "" {
+			return 0, 0, false
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0, 0, false
+	}
+	return hour, minute, true
+}
+
+var weekdayNumbers = map[string]int{
+	"sunday": 0, "sun": 0,
+	"monday": 1, "mon": 1,
+	"tuesday": 2, "tue": 2,
+	"wednesday": 3, "wed": 3,
+	"thursday": 4, "thu": 4, "thur": 4,
+	"friday": 5, "fri": 5,
+	"saturday": 6, "sat": 6,
+}
+
+// parseDays returns the day-of-month and day-of-week fields for the day
+// part of a schedule.
+func parseDays(s string) (dom, dow string, ok bool) {
+	s = strings.TrimSpace(s)
+	for _, prefix := range []string{"every ", "each ", "on "} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	switch s {
+	case "", "day", "days", "daily", "everyday", "every day", "each day", "day of the week":
+		return "*", "*", true
+	case "weekday", "weekdays", "week day", "week days", "workday", "workdays", "business day", "business days",
+		"monday to friday", "monday through friday", "monday - friday", "mon-fri", "monday-friday":
+		return "*", "1-5", true
+	case "weekend", "weekends", "weekend day", "weekend days", "saturday and sunday", "sat and sun":
+		return "*", "0,6", true
+	case "week", "weekly":
+		return "*", "0", true
+	case "month", "monthly":
+		return "1", "*", true
+	}
+
+	for _, prefix := range []string{"week on ", "weekly on "} {
+		if strings.HasPrefix(s, prefix) {
+			return parseDays(strings.TrimPrefix(s, prefix))
+		}
+	}
+	for _, prefix := range []string{"month on the ", "monthly on the ", "month on ", "monthly on ", "the "} {
+		if strings.HasPrefix(s, prefix) {
+			rest := strings.TrimPrefix(s, prefix)
+			rest = strings.TrimSuffix(strings.TrimSuffix(rest, " of every month"), " of the month")
+			if m := ordinalPattern.FindStringSubmatch(rest); m != nil {
+				day, _ := strconv.Atoi(m[1])
+				if day >= 1 && day <= 31 {
+					return strconv.Itoa(day), "*", true
+				}
+			}
+			return "", "", false
+		}
+	}
+
+	days := make(map[int]bool)
+	for _, word := range splitList(s) {
+		// "mondays", "tues" and "thurs" all match once the s is dropped.
+		n, found := weekdayNumbers[strings.TrimSuffix(word, "s")]
+		if !found {
+			return "", "", false
+		}
+		days[n] = true
+	}
+	if len(days) == 0 {
+		return "", "", false
+	}
+	return "*", joinInts(days), true
+}
+
+// splitList splits "a, b and c" into its items.
+func splitList(s string) []string {
+	s = strings.NewReplacer(",", " ", "&", " ", " and ", " ").Replace(" " + s + " ")
+	return strings.Fields(s)
+}
+
+func joinInts(set map[int]bool) string {
+	values := make([]int, 0, len(set))
+	for v := range set {
+		values = append(values, v)
+	}
+	sort.Ints(values)
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
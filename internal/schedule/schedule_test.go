@@ -0,0 +1,322 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		text string
+		cron string
+		desc string
+	}{
+		// Intervals
+		{"every minute", "* * * * *", "every minute"},
+		{"every 1 minute", "* * * * *", "every minute"},
+		{"every 5 minutes", "*/5 * * * *", "every 5 minutes"},
+		{"Every 15 mins", "*/15 * * * *", "every 15 minutes"},
+		{"each 30 minutes", "*/30 * * * *", "every 30 minutes"},
+		{"every half hour", "*/30 * * * *", "every 30 minutes"},
+		{"every hour", "0 * * * *", "every hour"},
+		{"hourly", "0 * * * *", "every hour"},
+		{"every hour at :15", "15 * * * *", "every hour at :15"},
+		{"hourly at minute 45", "45 * * * *", "every hour at :45"},
+		{"every 2 hours", "0 */2 * * *", "every 2 hours"},
+		{"every 6 hrs", "0 */6 * * *", "every 6 hours"},
+		{"every 3 hours at 10", "10 */3 * * *", "every 3 hours at :10"},
+
+		// Daily
+		{"every day at 7pm", "0 19 * * *", "every day at 19:00"},
+		{"daily at 19:00", "0 19 * * *", "every day at 19:00"},
+		{"Every day at 7:30 PM", "30 19 * * *", "every day at 19:30"},
+		{"every day at 7 p.m.", "0 19 * * *", "every day at 19:00"},
+		{"daily 9am", "0 9 * * *", "every day at 09:00"},
+		{"daily", "0 0 * * *", "every day at 00:00"},
+		{"every day at noon", "0 12 * * *", "every day at 12:00"},
+		{"every day at midnight", "0 0 * * *", "every day at 00:00"},
+		{"every day at 12am", "0 0 * * *", "every day at 00:00"},
+		{"every day at 12pm", "0 12 * * *", "every day at 12:00"},
+		{"every day at 9am and 5pm", "0 9,17 * * *", "every day at 09:00 and 17:00"},
+		{"every day at 8:30, 12:30 and 18:30", "30 8,12,18 * * *", "every day at 08:30, 12:30 and 18:30"},
+		{"at 6am every day", "0 6 * * *", "every day at 06:00"},
+		{"at 6:45am", "45 6 * * *", "every day at 06:45"},
+
+		// Weekdays and weekends
+		{"every weekday at 8:30am", "30 8 * * 1-5", "every weekday at 08:30"},
+		{"weekdays at 9", "", ""},
+		{"on weekdays at 17:45", "45 17 * * 1-5", "every weekday at 17:45"},
+		{"monday to friday at 7am", "0 7 * * 1-5", "every weekday at 07:00"},
+		{"mon-fri at 7am", "0 7 * * 1-5", "every weekday at 07:00"},
+		{"at 9am on weekdays", "0 9 * * 1-5", "every weekday at 09:00"},
+		{"every weekend at 10am", "0 10 * * 0,6", "every weekend day at 10:00"},
+		{"weekends at 10:00", "0 10 * * 0,6", "every weekend day at 10:00"},
+
+		// Specific weekdays
+		{"every Monday at 9am", "0 9 * * 1", "every Monday at 09:00"},
+		{"every sunday at midnight", "0 0 * * 0", "every Sunday at 00:00"},
+		{"mondays at 10:15", "15 10 * * 1", "every Monday at 10:15"},
+		{"every tues at 4pm", "0 16 * * 2", "every Tuesday at 16:00"},
+		{"every monday and thursday at 6pm", "0 18 * * 1,4", "every Monday and Thursday at 18:00"},
+		{"every Monday, Wednesday and Friday at 9am", "0 9 * * 1,3,5", "every Monday, Wednesday and Friday at 09:00"},
+		{"mon, wed & fri at 8:00", "0 8 * * 1,3,5", "every Monday, Wednesday and Friday at 08:00"},
+		{"saturday and sunday at 11am", "0 11 * * 0,6", "every weekend day at 11:00"},
+		{"every sunday and monday at 7am", "0 7 * * 0,1", "every Monday and Sunday at 07:00"},
+		{"every week on friday at 5pm", "0 17 * * 5", "every Friday at 17:00"},
+		{"weekly on thursdays at 3pm", "0 15 * * 4", "every Thursday at 15:00"},
+		{"weekly", "0 0 * * 0", "every Sunday at 00:00"},
+
+		// Monthly
+		{"every month on the 1st at 9am", "0 9 1 * *", "every month on the 1st at 09:00"},
+		{"monthly on the 15th", "0 0 15 * *", "every month on the 15th at 00:00"},
+		{"on the 2nd of every month at 6:30pm", "30 18 2 * *", "every month on the 2nd at 18:30"},
+		{"monthly", "0 0 1 * *", "every month on the 1st at 00:00"},
+
+		// Cron passthrough
+		{"30 8 * * 1-5", "30 8 * * 1-5", "every weekday at 08:30"},
+		{"  */10  *  * * * ", "*/10 * * * *", "every 10 minutes"},
+		{"0 0 1 1 *", "0 0 1 1 *", "cron schedule 0 0 1 1 *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := Parse(tt.text)
+			if tt.cron == "" {
+				if !errors.Is(err, ErrUnrecognized) {
+					t.Fatalf("Parse(%q) = %+v, %v; want ErrUnrecognized", tt.text, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.text, err)
+			}
+			if got.Cron != tt.cron {
+				t.Errorf("Parse(%q).Cron = %q, want %q", tt.text, got.Cron, tt.cron)
+			}
+			if got.Description != tt.desc {
+				t.Errorf("Parse(%q).Description = %q, want %q", tt.text, got.Description, tt.desc)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []string{
+		"every 90 minutes",
+		"every 0 minutes",
+		"every 24 hours",
+		"every other day",
+		"every day at 25:00",
+		"every day at 13pm",
+		"every day at 9am and 5:30pm",
+		"every funday at 9am",
+		"on the 32nd of every month",
+		"first monday of the month",
+		"60 * * * *",
+	}
+	for _, text := range tests {
+		t.Run(text, func(t *testing.T) {
+			if got, err := Parse(text); err == nil {
+				t.Fatalf("Parse(%q) = %+v, want an error", text, got)
+			}
+		})
+	}
+
+	if _, err := Parse("   "); err == nil || errors.Is(err, ErrUnrecognized) {
+		t.Fatalf("empty schedule should be rejected outright, got %v", err)
+	}
+}
+
+func TestParseErrorNamesTheUnreadablePart(t *testing.T) {
+	tests := map[string]string{
+		"every 90 minutes":     "interval of 90 minutes",
+		"every day at 25:00":   `time "25:00"`,
+		"every funday at 9am":  `days "every funday"`,
+		"60 * * * *":           "above maximum (59)",
+		"whenever you like":    "every weekday at 9am",
+		"every 5 hours at :75": "minute 75",
+	}
+	for text, want := range tests {
+		if _, err := Parse(text); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", text, err, want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	tests := []struct {
+		cron string
+		want string
+	}{
+		{"0 9 * * 1-5", "every weekday at 09:00"},
+		{"0 9 * * 1,2,3,4,5", "every weekday at 09:00"},
+		{"0 9 * * 0-6", "every day at 09:00"},
+		{"0 9 * * 7", "every Sunday at 09:00"},
+		{"5 4 * * 6,0", "every weekend day at 04:05"},
+		{"0 9-11 * * *", "every day at 09:00, 10:00 and 11:00"},
+		{"0 9 11 * *", "every month on the 11th at 09:00"},
+		{"0 9 22 * *", "every month on the 22nd at 09:00"},
+		{"*/20 9-17 * * 1-5", "cron schedule */20 9-17 * * 1-5"},
+		{"0 9 * * MON", "cron schedule 0 9 * * MON"},
+		{"not cron", "cron schedule not cron"},
+	}
+	for _, tt := range tests {
+		if got := Describe(tt.cron); got != tt.want {
+			t.Errorf("Describe(%q) = %q, want %q", tt.cron, got, tt.want)
+		}
+	}
+}
+
+type fakeScheduleLLM struct {
+	reply    string
+	err      error
+	requests []*llm.ChatRequest
+}
+
+func (f *fakeScheduleLLM) Chat(ctx context.Context, req *llm.ChatRequest) (*llm.ChatResponse, error) {
+	f.requests = append(f.requests, req)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &llm.ChatResponse{Content: f.reply}, nil
+}
+
+func TestParseWithLLM(t *testing.T) {
+	t.Run("deterministic forms skip the model", func(t *testing.T) {
+		client := &fakeScheduleLLM{reply: "1 2 3 4 5"}
+		got, err := ParseWithLLM(context.Background(), client, "model", "every day at 7pm")
+		if err != nil || got.Cron != "0 19 * * *" {
+			t.Fatalf("got %+v, %v", got, err)
+		}
+		if len(client.requests) != 0 {
+			t.Fatal("model should not be called")
+		}
+	})
+
+	replies := []struct {
+		name  string
+		reply string
+		cron  string
+	}{
+		{"plain", "0 9 1-7 * 1", "0 9 1-7 * 1"},
+		{"quoted", "`0 9 1-7 * 1`", "0 9 1-7 * 1"},
+		{"fenced", "```\n0 9 1-7 * 1\n```", "0 9 1-7 * 1"},
+		{"labelled", "Cron expression: 0 9 1-7 * 1", "0 9 1-7 * 1"},
+	}
+	for _, tt := range replies {
+		t.Run("falls back on "+tt.name+" reply", func(t *testing.T) {
+			client := &fakeScheduleLLM{reply: tt.reply}
+			got, err := ParseWithLLM(context.Background(), client, "model", "first monday of the month at 9am")
+			if err != nil {
+				t.Fatalf("ParseWithLLM: %v", err)
+			}
+			if got.Cron != tt.cron || got.Description != "cron schedule "+tt.cron {
+				t.Fatalf("got %+v", got)
+			}
+			req := client.requests[0]
+			if len(req.Tools) != 0 || req.Model != "model" || !strings.Contains(req.Messages[0].Content, "first monday of the month") {
+				t.Fatalf("unexpected request %+v", req)
+			}
+		})
+	}
+
+	t.Run("invalid model answers are rejected", func(t *testing.T) {
+		for _, reply := range []string{"every second tuesday", "0 9 * *", "61 9 * * *"} {
+			client := &fakeScheduleLLM{reply: reply}
+			if got, err := ParseWithLLM(context.Background(), client, "model", "every other day"); err == nil {
+				t.Fatalf("reply %q accepted as %+v", reply, got)
+			}
+		}
+	})
+
+	t.Run("model errors are returned", func(t *testing.T) {
+		client := &fakeScheduleLLM{err: errors.New("rate limited")}
+		if _, err := ParseWithLLM(context.Background(), client, "model", "every other day"); err == nil || !strings.Contains(err.Error(), "rate limited") {
+			t.Fatalf("expected the model error, got %v", err)
+		}
+	})
+}
+
+func TestParseAt(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// Wednesday 2026-03-04 15:20 in Berlin.
+	now := time.Date(2026, 3, 4, 15, 20, 0, 0, berlin)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, berlin)
+	}
+
+	tests := []struct {
+		text string
+		want time.Time
+	}{
+		{"tomorrow at 9am", at(3, 5, 9, 0)},
+		{"Tomorrow 9:30", at(3, 5, 9, 30)},
+		{"at 9am tomorrow", at(3, 5, 9, 0)},
+		{"today at 5pm", at(3, 4, 17, 0)},
+		{"once today at 23:59", at(3, 4, 23, 59)},
+		{"the day after tomorrow at noon", at(3, 6, 12, 0)},
+		{"next monday at 8am", at(3, 9, 8, 0)},
+		{"next wednesday at 8am", at(3, 11, 8, 0)},
+		{"on March 13th at noon", at(3, 13, 12, 0)},
+		{"march 4 at 16:00", at(3, 4, 16, 0)},
+		{"on the 1st of april at 7:15am", at(4, 1, 7, 15)},
+		{"12 june at 6pm", at(6, 12, 18, 0)},
+		{"Jan 3rd at 9am", time.Date(2027, 1, 3, 9, 0, 0, 0, berlin)},
+		{"on March 3rd at noon", time.Date(2027, 3, 3, 12, 0, 0, 0, berlin)},
+		{"dec 24 2026 at 18:00", at(12, 24, 18, 0)},
+		{"2026-05-01 at 10:00", at(5, 1, 10, 0)},
+		{"2026-05-01 10:00", at(5, 1, 10, 0)},
+		{"2026-05-01T10:00", at(5, 1, 10, 0)},
+		{"2026-05-01T10:00:00Z", time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)},
+		{"in 45 minutes", at(3, 4, 16, 5)},
+		{"in 2 hours", at(3, 4, 17, 20)},
+		{"in 3 days", at(3, 7, 15, 20)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseAt(tt.text, now)
+			if err != nil {
+				t.Fatalf("ParseAt(%q): %v", tt.text, err)
+			}
+			if got.Cron != "" || !got.RunAt.Equal(tt.want) {
+				t.Fatalf("ParseAt(%q) = %+v, want run at %s", tt.text, got, tt.want)
+			}
+			if !strings.HasPrefix(got.Description, "once on ") {
+				t.Fatalf("unexpected description %q", got.Description)
+			}
+		})
+	}
+
+	recurring := []struct{ text, cron string }{
+		{"every day at 7pm", "0 19 * * *"},
+		{"at 6am every day", "0 6 * * *"},
+		{"at 6:45am", "45 6 * * *"},
+		{"every monday at 9am", "0 9 * * 1"},
+		{"monthly on the 15th", "0 0 15 * *"},
+	}
+	for _, tt := range recurring {
+		got, err := ParseAt(tt.text, now)
+		if err != nil || got.Cron != tt.cron || !got.RunAt.IsZero() {
+			t.Errorf("ParseAt(%q) = %+v, %v; want recurring %q", tt.text, got, err, tt.cron)
+		}
+	}
+
+	for _, text := range []string{"today at 9am", "2026-03-01 at 10:00"} {
+		if _, err := ParseAt(text, now); err == nil || !strings.Contains(err.Error(), "in the past") {
+			t.Errorf("ParseAt(%q) should reject a past time, got %v", text, err)
+		}
+	}
+	for _, text := range []string{"tomorrow", "february 30 at 9am", "next funday at 9am", "in 0 minutes"} {
+		if got, err := ParseAt(text, now); err == nil {
+			t.Errorf("ParseAt(%q) = %+v, want an error", text, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/A2gent/brute/internal/jobs"
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// retentionInterval is how often the scheduler loop applies the retention policy.
+const retentionInterval = time.Hour
+
+// protectedSessionStatuses are never removed by retention cleanup, whatever
+// the caller asks for: these sessions are live or waiting on a user.
+var protectedSessionStatuses = []string{
+	string(session.StatusQueued),
+	string(session.StatusRunning),
+	string(session.StatusInputRequired),
+}
+
+// PurgeSessions removes sessions last updated before olderThan, always
+// skipping running, queued and input_required sessions.
+func PurgeSessions(store storage.Store, olderThan time.Time, excludeStatuses []string, opts storage.PurgeOptions) ([]*storage.Session, error) {
+	excluded := append(append([]string{}, protectedSessionStatuses...), excludeStatuses...)
+	return store.PurgeSessions(olderThan, excluded, opts)
+}
+
+// applyRetention purges old job sessions according to config.Retention. It is
+// throttled to run at most once per retentionInterval.
+func (s *Scheduler) applyRetention(now time.Time) {
+	if s.config == nil {
+		return
+	}
+	maxAge, err := s.config.Retention.MaxAge()
+	if err != nil {
+		logging.Warn("Invalid retention.max_session_age, skipping cleanup: %v", err)
+		return
+	}
+	if maxAge <= 0 {
+		return
+	}
+
+	// Only the scheduler loop calls this, so lastRetentionAt needs no locking.
+	if !s.lastRetentionAt.IsZero() && now.Sub(s.lastRetentionAt) < retentionInterval {
+		return
+	}
+	s.lastRetentionAt = now
+
+	purged, err := PurgeSessions(s.store, now.Add(-maxAge), nil, storage.PurgeOptions{
+		KeepLastPerJob: s.config.Retention.KeepLastPerJob,
+	})
+	if err != nil {
+		logging.Error("Session retention cleanup failed: %v", err)
+		return
+	}
+	if len(purged) > 0 {
+		logging.Info("Session retention removed %d job session(s) older than %s", len(purged), maxAge)
+	}
+}
+
+// pruneExecutions deletes the executions of each job beyond the newest it
+// keeps, along with their sessions. Running executions and those waiting for
+// an answer are never touched. It is throttled like applyRetention.
+func (s *Scheduler) pruneExecutions(now time.Time) {
+	// Only the scheduler loop calls this, so lastPruneAt needs no locking.
+	if !s.lastPruneAt.IsZero() && now.Sub(s.lastPruneAt) < retentionInterval {
+		return
+	}
+	s.lastPruneAt = now
+
+	jobList, err := s.store.ListJobs()
+	if err != nil {
+		logging.Error("Failed to list jobs for execution pruning: %v", err)
+		return
+	}
+	pruned := 0
+	for _, job := range jobList {
+		execs, err := s.store.PruneJobExecutions(job.ID, jobs.KeepExecutions(job), now)
+		if err != nil {
+			logging.Error("Failed to prune executions of job %s: %v", job.ID, err)
+			continue
+		}
+		pruned += len(execs)
+	}
+	if pruned > 0 {
+		logging.Info("Execution pruning removed %d old job execution(s)", pruned)
+	}
+}
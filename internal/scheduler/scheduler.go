@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/agents"
 	"github.com/A2gent/brute/internal/config"
 	"github.com/A2gent/brute/internal/jobs"
 	"github.com/A2gent/brute/internal/llm"
@@ -16,18 +18,17 @@ import (
 	"github.com/A2gent/brute/internal/llm/fallback"
 	"github.com/A2gent/brute/internal/llm/gemini"
 	"github.com/A2gent/brute/internal/llm/lmstudio"
+	"github.com/A2gent/brute/internal/llm/reqlog"
 	"github.com/A2gent/brute/internal/llm/retry"
 	"github.com/A2gent/brute/internal/logging"
 	"github.com/A2gent/brute/internal/session"
 	"github.com/A2gent/brute/internal/storage"
 	"github.com/A2gent/brute/internal/tools"
-	"github.com/google/uuid"
-	"github.com/robfig/cron/v3"
 )
 
-const thinkingJobIDSettingKey = "A2GENT_THINKING_JOB_ID"
-const thinkingProjectID = "project-thinking"
-const thinkingProjectName = "Thinking"
+// InterruptedExecutionError is the error recorded on executions that were
+// still running when the process stopped, as opposed to genuine failures.
+const InterruptedExecutionError = "interrupted by restart"
 
 // Scheduler manages recurring job execution
 type Scheduler struct {
@@ -37,12 +38,23 @@ type Scheduler struct {
 	toolManager    *tools.Manager
 	config         *config.Config
 
-	ticker      *time.Ticker
-	stopChan    chan struct{}
-	wg          sync.WaitGroup
-	mu          sync.Mutex
-	running     bool
-	runningJobs map[string]struct{}
+	ticker   *time.Ticker
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	running  bool
+	// lastTickAt is when the loop last checked for due jobs.
+	lastTickAt time.Time
+
+	// runner runs the jobs, due ones as well as those started on demand.
+	runner *jobs.Runner
+
+	lastRetentionAt time.Time
+	lastPruneAt     time.Time
+
+	// runTask runs the task prompt of a job in its session and returns the
+	// final answer; runAgent unless a test swaps it out.
+	runTask jobs.AgentFunc
 }
 
 // NewScheduler creates a new scheduler instance
@@ -53,15 +65,35 @@ func NewScheduler(
 	toolManager *tools.Manager,
 	cfg *config.Config,
 ) *Scheduler {
-	return &Scheduler{
+	s := &Scheduler{
 		store:          store,
 		sessionManager: sessionManager,
 		llmClient:      llmClient,
 		toolManager:    toolManager,
 		config:         cfg,
 		stopChan:       make(chan struct{}),
-		runningJobs:    make(map[string]struct{}),
 	}
+	s.runTask = s.runAgent
+	s.runner = jobs.NewRunner(s.executions(), func(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error) {
+		return s.runTask(ctx, job, sess, prompt)
+	})
+	return s
+}
+
+// executions returns the keeper of the scheduler's execution records.
+func (s *Scheduler) executions() jobs.Executions {
+	return jobs.Executions{
+		Store:        s.store,
+		Sessions:     s.sessionManager,
+		DataPath:     s.config.DataPath,
+		SummaryChars: s.config.Jobs.SummaryChars(),
+	}
+}
+
+// Runner returns the runner the scheduler runs jobs with, for callers that
+// start or cancel runs on demand.
+func (s *Scheduler) Runner() *jobs.Runner {
+	return s.runner
 }
 
 // Start begins the scheduler background loop
@@ -74,11 +106,16 @@ func (s *Scheduler) Start(ctx context.Context) {
 	s.running = true
 	s.ticker = time.NewTicker(1 * time.Minute)
 	s.mu.Unlock()
+	s.runner.Bind(ctx)
 
 	logging.Info("Scheduler started, checking jobs every minute")
 
-	// Run immediately on start to catch any missed jobs
+	// Settle runs cut off by a crash, then catch any missed jobs
+	s.recoverInterruptedExecutions(time.Now())
+	s.markTick(time.Now())
 	s.checkAndRunDueJobs(ctx)
+	s.applyRetention(time.Now())
+	s.pruneExecutions(time.Now())
 
 	s.wg.Add(1)
 	go func() {
@@ -91,225 +128,203 @@ func (s *Scheduler) Start(ctx context.Context) {
 			case <-s.stopChan:
 				logging.Info("Scheduler stopped")
 				return
-			case <-s.ticker.C:
+			case now := <-s.ticker.C:
+				s.markTick(now)
 				s.checkAndRunDueJobs(ctx)
+				s.applyRetention(now)
+				s.pruneExecutions(now)
 			}
 		}
 	}()
 }
 
-// Stop stops the scheduler
-func (s *Scheduler) Stop() {
+func (s *Scheduler) markTick(now time.Time) {
+	s.mu.Lock()
+	s.lastTickAt = now
+	s.mu.Unlock()
+}
+
+// LastTick returns when the scheduling loop last checked for due jobs and
+// whether the loop is running.
+func (s *Scheduler) LastTick() (time.Time, bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	return s.lastTickAt, s.running
+}
 
-	if !s.running {
-		return
-	}
+// ActiveRuns returns the number of job runs in progress.
+func (s *Scheduler) ActiveRuns() int {
+	return s.runner.ActiveRuns()
+}
+
+// Bind ties runs started through the runner to ctx without starting the
+// scheduling loop, for processes that only run jobs on demand. Start binds
+// its own context.
+func (s *Scheduler) Bind(ctx context.Context) {
+	s.runner.Bind(ctx)
+}
 
-	s.running = false
-	s.ticker.Stop()
-	close(s.stopChan)
+// Stop stops the scheduler and waits for the jobs it is running, including
+// those started through the runner.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	if s.running {
+		s.running = false
+		s.ticker.Stop()
+		close(s.stopChan)
+	}
+	s.mu.Unlock()
 	s.wg.Wait()
+	s.runner.Wait()
 }
 
 // checkAndRunDueJobs checks for jobs that need to run and executes them
 func (s *Scheduler) checkAndRunDueJobs(ctx context.Context) {
 	now := time.Now()
 
-	jobs, err := s.store.GetDueJobs(now)
+	due, err := s.store.GetDueJobs(now)
 	if err != nil {
 		logging.Error("Failed to get due jobs: %v", err)
 		return
 	}
 
-	if len(jobs) == 0 {
+	if len(due) == 0 {
 		return
 	}
 
-	logging.Info("Found %d due job(s) to execute", len(jobs))
+	logging.Info("Found %d due job(s) to execute", len(due))
 
-	for _, job := range jobs {
-		s.mu.Lock()
-		if _, ok := s.runningJobs[job.ID]; ok {
-			s.mu.Unlock()
+	// Due jobs run in the background like jobs started through the API
+	for _, job := range due {
+		if _, err := s.runner.StartRun(ctx, job); errors.Is(err, jobs.ErrAlreadyRunning) {
 			logging.Info("Skipping due job %s (%s): execution already in progress", job.Name, job.ID)
-			continue
+		} else if err != nil {
+			logging.ErrorContext(logging.WithJobID(ctx, job.ID), "Failed to start job %s: %v", job.ID, err)
 		}
-		s.runningJobs[job.ID] = struct{}{}
-		s.mu.Unlock()
-
-		// Run each job in a separate goroutine
-		s.wg.Add(1)
-		go func(job *storage.RecurringJob) {
-			defer func() {
-				s.mu.Lock()
-				delete(s.runningJobs, job.ID)
-				s.mu.Unlock()
-				s.wg.Done()
-			}()
-			s.executeJob(ctx, job)
-		}(job)
 	}
 }
 
-// executeJob runs a single job
-func (s *Scheduler) executeJob(ctx context.Context, job *storage.RecurringJob) {
-	logging.Info("Executing job: %s (%s)", job.Name, job.ID)
-	now := time.Now()
-	defer s.rescheduleJobAfterAttempt(job, now)
-
-	// Create execution record
-	exec := &storage.JobExecution{
-		ID:        uuid.New().String(),
-		JobID:     job.ID,
-		Status:    "running",
-		StartedAt: now,
-	}
-
-	if err := s.store.SaveJobExecution(exec); err != nil {
-		logging.Error("Failed to create execution record for job %s: %v", job.ID, err)
-		return
-	}
-
-	// Create a session for this job execution
-	sess, err := s.sessionManager.CreateWithJob("job-runner", job.ID)
+// runAgent runs the job's agent on the task prompt already added to sess,
+// with the provider and model the job, its agent definition or the session
+// pick.
+func (s *Scheduler) runAgent(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error) {
+	agentID := jobs.AgentID(job)
+	providerType := s.resolveJobProviderType(job)
+	model := s.resolveModelForProvider(providerType)
+	registry, err := agents.Load(s.config, s.config.WorkDir)
 	if err != nil {
-		logging.Error("Failed to create session for job %s: %v", job.ID, err)
-		exec.Status = "failed"
-		exec.Error = "Failed to create session: " + err.Error()
-		finishedAt := time.Now()
-		exec.FinishedAt = &finishedAt
-		s.store.SaveJobExecution(exec)
-		return
+		logging.WarnContext(ctx, "Failed to load some agent definitions: %v", err)
 	}
-
-	exec.SessionID = sess.ID
-	if thinking, thinkErr := s.isThinkingJob(job.ID); thinkErr != nil {
-		logging.Warn("Failed to check thinking job for project assignment: %v", thinkErr)
-	} else if thinking {
-		if assignErr := s.assignSessionToThinkingProject(sess); assignErr != nil {
-			logging.Warn("Failed to assign Thinking project for session %s: %v", sess.ID, assignErr)
-		}
+	agentDef := registry.Resolve(agentID)
+	if agentDef.Model != "" {
+		model = agentDef.Model
+	}
+	if job.Model != "" {
+		model = job.Model
 	}
-
-	// Run the agent with the job's task prompt
-	providerType := s.resolveJobProviderType(job)
-	model := s.resolveModelForProvider(providerType)
 	sess.Metadata["provider"] = string(providerType)
-	sess.Metadata["model"] = model
+	if pinned := sess.Model(); pinned != "" {
+		model = pinned
+	}
+	sess.SetModel(model)
 	if err := s.sessionManager.Save(sess); err != nil {
-		logging.Warn("Failed to persist job session provider metadata: %v", err)
+		logging.WarnContext(ctx, "Failed to persist job session provider metadata: %v", err)
 	}
 
-	contextWindow := s.resolveContextWindowForProvider(providerType)
-	effectiveTaskPrompt, resolveErr := jobs.ResolveTaskPrompt(job)
-	if resolveErr != nil {
-		logging.Error("Failed to resolve task instructions for job %s: %v", job.ID, resolveErr)
-		exec.Status = "failed"
-		exec.Error = "Failed to resolve task instructions: " + resolveErr.Error()
-		finishedAt := time.Now()
-		exec.FinishedAt = &finishedAt
-		s.store.SaveJobExecution(exec)
-		return
+	temperature := s.config.Temperature
+	if agentDef.Temperature != nil {
+		temperature = *agentDef.Temperature
+	}
+	if override, ok := sess.Temperature(); ok {
+		temperature = override
 	}
-
 	agentConfig := agent.Config{
-		Name:          "job-runner",
+		Name:          agentID,
 		Model:         model,
 		MaxSteps:      s.config.MaxSteps,
-		Temperature:   s.config.Temperature,
-		ContextWindow: contextWindow,
+		MaxTokens:     s.config.MaxTokens,
+		MaxDuration:   s.config.MaxRunDuration(),
+		StopSequences: s.config.StopSequences,
+		Temperature:   temperature,
+		ContextWindow: s.resolveContextWindowForProvider(providerType),
+
+		ResponseSchema: jobs.ResponseSchema(job),
 	}
+	agentDef.Apply(&agentConfig)
 
 	client, err := s.createLLMClient(providerType, model)
 	if err != nil {
-		logging.Error("Failed to initialize provider %s for job %s: %v", providerType, job.ID, err)
-		exec.Status = "failed"
-		exec.Error = "Failed to initialize provider: " + err.Error()
-		finishedAt := time.Now()
-		exec.FinishedAt = &finishedAt
-		s.store.SaveJobExecution(exec)
-		return
+		return "", fmt.Errorf("failed to initialize provider %s: %w", providerType, err)
 	}
 
 	ag := agent.New(agentConfig, client, s.toolManager, s.sessionManager)
+	output, _, err := ag.Run(ctx, sess, prompt)
+	return output, err
+}
 
-	// Create a timeout context for job execution (default 30 minutes)
-	jobCtx, cancel := context.WithTimeout(ctx, 30*time.Minute)
-	defer cancel()
-
-	sess.AddUserMessage(effectiveTaskPrompt)
-
-	output, _, err := ag.Run(jobCtx, sess, effectiveTaskPrompt)
-
-	finishedAt := time.Now()
-	exec.FinishedAt = &finishedAt
-
+// recoverInterruptedExecutions fails executions left running by a process
+// that died mid-job. Only runs older than their job's timeout are touched,
+// since no live run can last longer. Their sessions are paused so they can
+// be resumed, and their jobs get a fresh next run so they keep firing.
+func (s *Scheduler) recoverInterruptedExecutions(now time.Time) {
+	running, err := s.store.ListRunningJobExecutions(now)
 	if err != nil {
-		logging.Error("Job %s failed: %v", job.ID, err)
-		exec.Status = "failed"
-		exec.Error = err.Error()
-	} else {
-		logging.Info("Job %s completed successfully", job.ID)
-		exec.Status = "success"
-		// Truncate output if too long
-		if len(output) > 10000 {
-			exec.Output = output[:10000] + "... (truncated)"
-		} else {
-			exec.Output = output
-		}
-	}
-
-	// Update execution record
-	if err := s.store.SaveJobExecution(exec); err != nil {
-		logging.Error("Failed to update execution record for job %s: %v", job.ID, err)
+		logging.Error("Failed to list interrupted job executions: %v", err)
+		return
 	}
 
-}
+	recovered := 0
+	rescheduled := make(map[string]bool)
+	for _, exec := range running {
+		job, err := s.store.GetJob(exec.JobID)
+		if err != nil {
+			job = nil // a missing job falls back to the default timeout
+		}
+		if exec.StartedAt.After(now.Add(-jobs.Timeout(job))) {
+			continue
+		}
+		recovered++
 
-func (s *Scheduler) rescheduleJobAfterAttempt(job *storage.RecurringJob, attemptedAt time.Time) {
-	job.LastRunAt = &attemptedAt
-	nextRun, err := s.calculateNextRun(job.ScheduleCron, attemptedAt)
-	if err == nil {
-		job.NextRunAt = &nextRun
-		logging.Info("Job %s next run scheduled for: %s", job.Name, nextRun.Format(time.RFC3339))
-	} else {
-		logging.Error("Failed to calculate next run for job %s: %v", job.ID, err)
-	}
-	job.UpdatedAt = time.Now()
+		exec.Status = "failed"
+		exec.Error = InterruptedExecutionError
+		finishedAt := now
+		exec.FinishedAt = &finishedAt
+		if err := s.store.SaveJobExecution(exec); err != nil {
+			logging.Error("Failed to mark execution %s as interrupted: %v", exec.ID, err)
+			continue
+		}
 
-	if err := s.store.SaveJob(job); err != nil {
-		logging.Error("Failed to update job %s after execution attempt: %v", job.ID, err)
-	}
-}
+		if exec.SessionID != "" {
+			_, err := s.sessionManager.Update(exec.SessionID, func(sess *session.Session) error {
+				if sess.Status == session.StatusRunning || sess.Status == session.StatusQueued {
+					sess.SetStatus(session.StatusPaused)
+				}
+				return nil
+			})
+			if err != nil {
+				logging.Warn("Failed to pause session %s of interrupted execution %s: %v", exec.SessionID, exec.ID, err)
+			}
+		}
 
-func (s *Scheduler) isThinkingJob(jobID string) (bool, error) {
-	settings, err := s.store.GetSettings()
-	if err != nil {
-		return false, err
+		if job == nil || rescheduled[job.ID] {
+			continue
+		}
+		rescheduled[job.ID] = true
+		if job.LastRunAt == nil || job.LastRunAt.Before(exec.StartedAt) {
+			startedAt := exec.StartedAt
+			job.LastRunAt = &startedAt
+		}
+		if err := jobs.Reschedule(job, now); err != nil {
+			logging.Error("Failed to calculate next run for job %s: %v", job.ID, err)
+		}
+		job.UpdatedAt = now
+		if err := s.store.SaveJob(job); err != nil {
+			logging.Error("Failed to reschedule job %s after interrupted execution: %v", job.ID, err)
+		}
 	}
-	thinkingJobID := strings.TrimSpace(settings[thinkingJobIDSettingKey])
-	if thinkingJobID == "" {
-		return false, nil
+	if recovered > 0 {
+		logging.Info("Recovered %d job execution(s) interrupted by a restart", recovered)
 	}
-	return thinkingJobID == strings.TrimSpace(jobID), nil
-}
-
-func (s *Scheduler) assignSessionToThinkingProject(sess *session.Session) error {
-	now := time.Now()
-	project := &storage.Project{
-		ID:        thinkingProjectID,
-		Name:      thinkingProjectName,
-		IsSystem:  true,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-	if err := s.store.SaveProject(project); err != nil {
-		return err
-	}
-	sess.ProjectID = &project.ID
-	return s.sessionManager.Save(sess)
 }
 
 func normalizeJobLLMProvider(raw string) string {
@@ -365,6 +380,14 @@ func (s *Scheduler) resolveContextWindowForProvider(providerType config.Provider
 }
 
 func (s *Scheduler) createLLMClient(providerType config.ProviderType, model string) (llm.Client, error) {
+	client, err := s.createProviderClient(providerType, model)
+	if err != nil {
+		return nil, err
+	}
+	return reqlog.WrapFromConfig(client, s.config, s.store), nil
+}
+
+func (s *Scheduler) createProviderClient(providerType config.ProviderType, model string) (llm.Client, error) {
 	if config.IsFallbackAggregateRef(string(providerType)) || providerType == config.ProviderFallback {
 		return s.createFallbackChainClient(providerType)
 	}
@@ -372,6 +395,9 @@ func (s *Scheduler) createLLMClient(providerType config.ProviderType, model stri
 	if err != nil {
 		return nil, err
 	}
+	if chained := s.withFallbackModels(providerType, model, client); chained != nil {
+		return chained, nil
+	}
 	retries := s.config.LLMRetries
 	if retries <= 0 {
 		retries = retry.DefaultMaxRetries
@@ -379,6 +405,36 @@ func (s *Scheduler) createLLMClient(providerType config.ProviderType, model stri
 	return retry.Wrap(client, retry.WithMaxRetries(retries)), nil
 }
 
+// withFallbackModels puts client at the head of a chain over the configured
+// fallback_models. It returns nil when no usable fallback model is set.
+func (s *Scheduler) withFallbackModels(providerType config.ProviderType, model string, client llm.Client) llm.Client {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		model = s.resolveModelForProvider(providerType)
+	}
+	chain, err := s.config.FallbackModelNodes(providerType, model)
+	if err != nil {
+		logging.Warn("Ignoring fallback_models entries: %v", err)
+	}
+	nodes := []fallback.Node{{Name: string(providerType), Model: model, Client: client}}
+	for _, node := range chain {
+		nodeClient, err := s.createBaseLLMClient(config.ProviderType(node.Provider), node.Model)
+		if err != nil {
+			logging.Warn("Fallback model %s/%s is not available: %v", node.Provider, node.Model, err)
+			continue
+		}
+		nodes = append(nodes, fallback.Node{Name: node.Provider, Model: node.Model, Client: nodeClient})
+	}
+	if len(nodes) == 1 {
+		return nil
+	}
+	retries := s.config.LLMRetries
+	if retries <= 0 {
+		retries = fallback.DefaultMaxRetries
+	}
+	return fallback.NewClient(nodes, fallback.WithMaxRetries(retries))
+}
+
 func (s *Scheduler) createBaseLLMClient(providerType config.ProviderType, model string) (llm.Client, error) {
 	def := config.GetProviderDefinition(providerType)
 	if def == nil {
@@ -617,13 +673,3 @@ func (s *Scheduler) providerConfiguredForUse(providerType config.ProviderType) b
 	}
 	return apiKey != ""
 }
-
-// calculateNextRun calculates the next run time based on cron expression
-func (s *Scheduler) calculateNextRun(cronExpr string, after time.Time) (time.Time, error) {
-	parser := cron.NewParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-	schedule, err := parser.Parse(cronExpr)
-	if err != nil {
-		return time.Time{}, err
-	}
-	return schedule.Next(after), nil
-}
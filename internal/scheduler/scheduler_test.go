@@ -0,0 +1,272 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestRecoverInterruptedExecutions(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	sessionManager := session.NewManager(store)
+	s := NewScheduler(store, sessionManager, nil, nil, &config.Config{})
+
+	now := time.Now()
+	started := now.Add(-2 * time.Hour)
+	staleNext := now.Add(-90 * time.Minute)
+	job := &storage.RecurringJob{ID: "job-1", Name: "nightly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "report", TaskPromptSource: "text", Enabled: true, NextRunAt: &staleNext, CreatedAt: started, UpdatedAt: started}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	longJob := &storage.RecurringJob{ID: "job-2", Name: "crawl", ScheduleHuman: "every day", ScheduleCron: "0 0 * * *", TaskPrompt: "crawl", TaskPromptSource: "text", TimeoutMinutes: 180, Enabled: true, CreatedAt: started, UpdatedAt: started}
+	if err := store.SaveJob(longJob); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	sess, err := sessionManager.CreateWithJob("job-runner", job.ID, "stale")
+	if err != nil {
+		t.Fatalf("CreateWithJob: %v", err)
+	}
+	if err := sessionManager.SetSessionStatus(sess.ID, string(session.StatusRunning)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+	for _, exec := range []*storage.JobExecution{
+		{ID: "stale", JobID: job.ID, SessionID: sess.ID, Status: "running", StartedAt: started},
+		{ID: "recent", JobID: job.ID, Status: "running", StartedAt: now.Add(-time.Minute)},
+		{ID: "done", JobID: job.ID, Status: "success", StartedAt: started.Add(-time.Hour)},
+		{ID: "long", JobID: longJob.ID, Status: "running", StartedAt: started},
+	} {
+		if err := store.SaveJobExecution(exec); err != nil {
+			t.Fatalf("SaveJobExecution: %v", err)
+		}
+	}
+
+	s.recoverInterruptedExecutions(now)
+
+	stale, err := store.GetJobExecution("stale")
+	if err != nil {
+		t.Fatalf("GetJobExecution: %v", err)
+	}
+	if stale.Status != "failed" || stale.Error != InterruptedExecutionError || stale.FinishedAt == nil {
+		t.Fatalf("stale execution not recovered: %+v", stale)
+	}
+	for id, status := range map[string]string{"recent": "running", "done": "success", "long": "running"} {
+		exec, err := store.GetJobExecution(id)
+		if err != nil {
+			t.Fatalf("GetJobExecution(%s): %v", id, err)
+		}
+		if exec.Status != status || exec.Error != "" {
+			t.Fatalf("execution %s should be left alone, got %+v", id, exec)
+		}
+	}
+
+	reloaded, err := sessionManager.Get(sess.ID)
+	if err != nil {
+		t.Fatalf("Get session: %v", err)
+	}
+	if reloaded.Status != session.StatusPaused {
+		t.Fatalf("expected the interrupted session to be paused, got %s", reloaded.Status)
+	}
+
+	rescheduled, err := store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if rescheduled.NextRunAt == nil || !rescheduled.NextRunAt.After(now) {
+		t.Fatalf("expected a future next run, got %v", rescheduled.NextRunAt)
+	}
+	if rescheduled.LastRunAt == nil || !rescheduled.LastRunAt.Equal(started) {
+		t.Fatalf("expected last run at the interrupted start, got %v", rescheduled.LastRunAt)
+	}
+}
+
+func TestRescheduleDisablesFinishedOneShotJob(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	s := NewScheduler(store, session.NewManager(store), nil, nil, &config.Config{DataPath: t.TempDir()})
+
+	runAt := time.Now().Add(-time.Minute).Truncate(time.Second)
+	job := &storage.RecurringJob{ID: "once", Name: "reminder", ScheduleHuman: "today at 9am", TaskPrompt: "x", TaskPromptSource: "text", RunAt: &runAt, NextRunAt: &runAt, Enabled: true, CreatedAt: runAt, UpdatedAt: runAt}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	due, err := store.GetDueJobs(time.Now())
+	if err != nil || len(due) != 1 || due[0].ID != job.ID {
+		t.Fatalf("expected the one-shot job to be due, got %+v, %v", due, err)
+	}
+
+	s.runTask = func(context.Context, *storage.RecurringJob, *session.Session, string) (string, error) {
+		return "reminded", nil
+	}
+	if _, err := s.Runner().RunNow(context.Background(), due[0]); err != nil {
+		t.Fatalf("RunNow: %v", err)
+	}
+
+	stored, err := store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if stored.Enabled || stored.NextRunAt != nil || stored.LastRunAt == nil || stored.RunAt == nil {
+		t.Fatalf("expected a disabled job that keeps its run time, got %+v", stored)
+	}
+	if due, _ := store.GetDueJobs(time.Now().Add(time.Hour)); len(due) != 0 {
+		t.Fatalf("a finished one-shot job must not be due again, got %+v", due)
+	}
+}
+
+func TestStartRunOutlivesCallerContext(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	s := NewScheduler(store, session.NewManager(store), nil, nil, &config.Config{})
+
+	job := &storage.RecurringJob{ID: "job-1", Name: "nightly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "report", TaskPromptSource: "text", Enabled: true}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	exec, err := s.Runner().StartRun(ctx, job)
+	cancel()
+	if err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	if exec.Status != "running" || exec.FinishedAt != nil {
+		t.Fatalf("expected a running execution, got %+v", exec)
+	}
+	if job.LastRunAt != nil {
+		t.Fatalf("StartRun should leave the caller's job untouched")
+	}
+
+	s.Stop()
+	finished, err := store.GetJobExecution(exec.ID)
+	if err != nil {
+		t.Fatalf("GetJobExecution: %v", err)
+	}
+	// No provider is configured, so the run fails on its own rather than
+	// being cancelled with the caller's context.
+	if finished.Status != "failed" || !strings.Contains(finished.Error, "provider") {
+		t.Fatalf("expected the run to fail on the provider, got %+v", finished)
+	}
+	saved, err := store.GetJob(job.ID)
+	if err != nil {
+		t.Fatalf("GetJob: %v", err)
+	}
+	if saved.LastRunAt == nil {
+		t.Fatalf("expected the run to record the job's last run")
+	}
+	if _, err := s.Runner().StartRun(context.Background(), job); err != nil {
+		t.Fatalf("expected the job to be runnable again once finished: %v", err)
+	}
+	s.Stop()
+}
+
+func TestPruneExecutionsKeepsPerJobLimit(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	s := NewScheduler(store, session.NewManager(store), nil, nil, &config.Config{})
+
+	now := time.Now()
+	job := &storage.RecurringJob{ID: "job-1", Name: "nightly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "report", TaskPromptSource: "text", KeepExecutions: 2, Enabled: true, CreatedAt: now, UpdatedAt: now}
+	if err := store.SaveJob(job); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		exec := &storage.JobExecution{ID: fmt.Sprintf("exec-%d", i), JobID: job.ID, Status: "success", StartedAt: now.Add(-time.Duration(5-i) * time.Hour)}
+		if err := store.SaveJobExecution(exec); err != nil {
+			t.Fatalf("SaveJobExecution: %v", err)
+		}
+	}
+
+	s.pruneExecutions(now)
+	if executions, _ := store.ListJobExecutions(job.ID, 10); len(executions) != 2 || executions[0].ID != "exec-4" {
+		t.Fatalf("expected the 2 newest executions to remain, got %+v", executions)
+	}
+}
+
+func TestScheduledAndStartedRunsShareExecution(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	sessionManager := session.NewManager(store)
+	s := NewScheduler(store, sessionManager, nil, nil, &config.Config{DataPath: t.TempDir(), Jobs: config.JobsConfig{OutputSummaryChars: 5}})
+	var mu sync.Mutex
+	ran := make(map[string]string)
+	s.runTask = func(ctx context.Context, job *storage.RecurringJob, sess *session.Session, prompt string) (string, error) {
+		if _, ok := ctx.Deadline(); !ok {
+			return "", errors.New("the run has no timeout")
+		}
+		// The execution leads to the session while the run is still going
+		if exec, err := store.GetJobExecution(sess.ExecutionID()); err != nil || exec.SessionID != sess.ID || exec.Status != "running" {
+			return "", fmt.Errorf("session %s is not linked to a running execution: %+v, %v", sess.ID, exec, err)
+		}
+		mu.Lock()
+		ran[job.ID] = prompt
+		mu.Unlock()
+		return "full answer of " + job.ID, nil
+	}
+
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+	due := &storage.RecurringJob{ID: "due", Name: "nightly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "report", TaskPromptSource: "text", Enabled: true, NextRunAt: &past, CreatedAt: now, UpdatedAt: now}
+	manual := &storage.RecurringJob{ID: "manual", Name: "weekly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "summarize", TaskPromptSource: "text", Enabled: true, NextRunAt: &future, CreatedAt: now, UpdatedAt: now}
+	for _, job := range []*storage.RecurringJob{due, manual} {
+		if err := store.SaveJob(job); err != nil {
+			t.Fatalf("SaveJob: %v", err)
+		}
+	}
+
+	// The scheduling loop and the run-now handler take the same runner
+	s.checkAndRunDueJobs(context.Background())
+	if _, err := s.Runner().StartRun(context.Background(), manual); err != nil {
+		t.Fatalf("StartRun: %v", err)
+	}
+	s.Stop()
+
+	for id, prompt := range map[string]string{"due": "report", "manual": "summarize"} {
+		if ran[id] != prompt {
+			t.Errorf("job %s ran with prompt %q, want %q", id, ran[id], prompt)
+		}
+		executions, err := store.ListJobExecutions(id, 10)
+		if err != nil || len(executions) != 1 {
+			t.Fatalf("job %s: expected one execution, got %d, %v", id, len(executions), err)
+		}
+		exec := executions[0]
+		if exec.Status != "success" || exec.FinishedAt == nil || exec.Output != "full ... (truncated)" {
+			t.Errorf("job %s: unexpected execution %+v", id, exec)
+		}
+		if data, err := os.ReadFile(exec.OutputPath); err != nil || string(data) != "full answer of "+id {
+			t.Errorf("job %s: full output = %q, %v", id, data, err)
+		}
+		sess, err := sessionManager.Get(exec.SessionID)
+		if err != nil || sess.JobID == nil || *sess.JobID != id || sess.ExecutionID() != exec.ID {
+			t.Errorf("job %s: expected the execution and its session %q to link each other: %v", id, exec.SessionID, err)
+		}
+		job, err := store.GetJob(id)
+		if err != nil || job.LastRunAt == nil || job.NextRunAt == nil || !job.NextRunAt.After(now) {
+			t.Errorf("job %s was not rescheduled: %+v, %v", id, job, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package session
+
+import (
+	"encoding/json"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// ErrStaleSession is returned when a session was saved by another writer
+// after the caller loaded it.
+var ErrStaleSession = storage.ErrStaleSession
+
+// savedState records the fields of a session as last loaded from or written
+// to the store, so a conflicting save can tell local edits from edits made
+// concurrently by another writer.
+type savedState struct {
+	title        string
+	status       Status
+	projectID    string
+	taskProgress string
+	metadata     map[string]string // JSON-encoded values
+}
+
+func snapshotState(s *Session) *savedState {
+	state := &savedState{
+		title:        s.Title,
+		status:       s.Status,
+		projectID:    stringValue(s.ProjectID),
+		taskProgress: s.TaskProgress,
+		metadata:     make(map[string]string, len(s.Metadata)),
+	}
+	for k, v := range s.Metadata {
+		state.metadata[k] = encodeMetadataValue(v)
+	}
+	return state
+}
+
+// rebaseOnto merges a newer stored copy of the session into s. Fields that s
+// has not changed since it was loaded take the stored value; fields s changed
+// keep the local value. Messages always stay local because the writer holding
+// a session object owns its conversation.
+func (s *Session) rebaseOnto(stored *Session) {
+	base := s.saved
+	if base == nil {
+		base = snapshotState(stored)
+	}
+
+	if s.Title == base.title {
+		s.Title = stored.Title
+	}
+	if s.Status == base.status {
+		s.Status = stored.Status
+	}
+	if stringValue(s.ProjectID) == base.projectID {
+		s.ProjectID = stored.ProjectID
+	}
+	if s.TaskProgress == base.taskProgress {
+		s.TaskProgress = stored.TaskProgress
+	}
+
+	merged := make(map[string]interface{}, len(stored.Metadata)+len(s.Metadata))
+	for k, v := range stored.Metadata {
+		if !metadataChangedLocally(base, s.Metadata, k) {
+			merged[k] = v
+		}
+	}
+	for k, v := range s.Metadata {
+		if metadataChangedLocally(base, s.Metadata, k) {
+			merged[k] = v
+		}
+	}
+	s.Metadata = merged
+
+	s.Version = stored.Version
+	s.saved = snapshotState(stored)
+}
+
+// metadataChangedLocally reports whether key was added, changed or removed in
+// local since base was taken.
+func metadataChangedLocally(base *savedState, local map[string]interface{}, key string) bool {
+	baseValue, inBase := base.metadata[key]
+	localValue, inLocal := local[key]
+	if inBase != inLocal {
+		return true
+	}
+	return inLocal && encodeMetadataValue(localValue) != baseValue
+}
+
+func encodeMetadataValue(v interface{}) string {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(encoded)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 	"time"
 
@@ -14,18 +15,38 @@ import (
 // --- minimal in-memory Store stub ---
 
 type memStore struct {
+	mu       sync.Mutex
 	sessions map[string]*storage.Session
 }
 
 func newMemStore() *memStore { return &memStore{sessions: make(map[string]*storage.Session)} }
 
-func (m *memStore) SaveSession(s *storage.Session) error { m.sessions[s.ID] = s; return nil }
+// SaveSession mirrors the SQLite store's version check.
+func (m *memStore) SaveSession(s *storage.Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	existing, ok := m.sessions[s.ID]
+	version := s.Version + 1
+	if ok {
+		if s.Version != 0 && existing.Version != s.Version {
+			return storage.ErrStaleSession
+		}
+		version = existing.Version + 1
+	}
+	stored := copyStoredSession(s)
+	stored.Version = version
+	m.sessions[s.ID] = stored
+	s.Version = version
+	return nil
+}
 func (m *memStore) GetSession(id string) (*storage.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	s, ok := m.sessions[id]
 	if !ok {
 		return nil, os.ErrNotExist
 	}
-	return s, nil
+	return copyStoredSession(s), nil
 }
 func (m *memStore) ListSessions() ([]*storage.Session, error)            { return nil, nil }
 func (m *memStore) ListSessionsByJob(string) ([]*storage.Session, error) { return nil, nil }
@@ -48,6 +69,16 @@ func (m *memStore) GetJobExecution(string) (*storage.JobExecution, error) {
 func (m *memStore) ListJobExecutions(string, int) ([]*storage.JobExecution, error) {
 	return nil, nil
 }
+func (m *memStore) ListRunningJobExecutions(time.Time) ([]*storage.JobExecution, error) {
+	return nil, nil
+}
+func (m *memStore) ListWaitingJobExecutions() ([]*storage.JobExecution, error) {
+	return nil, nil
+}
+func (m *memStore) PruneJobExecutions(string, int, time.Time) ([]*storage.JobExecution, error) {
+	return nil, nil
+}
+func (m *memStore) CheckWritable() error                       { return nil }
 func (m *memStore) GetSettings() (map[string]string, error)    { return nil, nil }
 func (m *memStore) SaveSettings(map[string]string) error       { return nil }
 func (m *memStore) SaveIntegration(*storage.Integration) error { return nil }
@@ -64,10 +95,45 @@ func (m *memStore) SaveSubAgent(*storage.SubAgent) error              { return n
 func (m *memStore) GetSubAgent(string) (*storage.SubAgent, error)     { return nil, nil }
 func (m *memStore) ListSubAgents() ([]*storage.SubAgent, error)       { return nil, nil }
 func (m *memStore) DeleteSubAgent(string) error                       { return nil }
+func (m *memStore) SaveToolOutput(*storage.ToolOutput) error          { return nil }
+func (m *memStore) GetToolOutput(string) (*storage.ToolOutput, error) { return nil, nil }
+func (m *memStore) SaveAttachment(*storage.Attachment) error          { return nil }
+func (m *memStore) GetAttachment(string) (*storage.Attachment, error) { return nil, nil }
+func (m *memStore) ListAttachments(string) ([]*storage.Attachment, error) {
+	return nil, nil
+}
+func (m *memStore) SaveMemory(*storage.Memory) error                  { return nil }
+func (m *memStore) GetMemory(string, string) (*storage.Memory, error) { return nil, nil }
+func (m *memStore) ListMemories(string) ([]*storage.Memory, error)    { return nil, nil }
+func (m *memStore) DeleteMemories(string, string) error               { return nil }
 func (m *memStore) Close() error                                      { return nil }
+func (m *memStore) SaveWebhookDelivery(*storage.WebhookDelivery) error {
+	return nil
+}
+func (m *memStore) ListWebhookDeliveries(string, int) ([]*storage.WebhookDelivery, error) {
+	return nil, nil
+}
+func (m *memStore) ListSessionsPage(storage.SessionFilter) ([]*storage.Session, int, error) {
+	return nil, 0, nil
+}
+func (m *memStore) PurgeSessions(time.Time, []string, storage.PurgeOptions) ([]*storage.Session, error) {
+	return nil, nil
+}
 
 // --- helpers ---
 
+func copyStoredSession(s *storage.Session) *storage.Session {
+	c := *s
+	c.Messages = append([]storage.Message(nil), s.Messages...)
+	if s.Metadata != nil {
+		c.Metadata = make(map[string]interface{}, len(s.Metadata))
+		for k, v := range s.Metadata {
+			c.Metadata[k] = v
+		}
+	}
+	return &c
+}
+
 func readJSONLFile(t *testing.T, path string) []JSONLRecord {
 	t.Helper()
 	f, err := os.Open(path)
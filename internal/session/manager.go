@@ -2,18 +2,22 @@ package session
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/A2gent/brute/internal/storage"
+	"github.com/google/uuid"
 )
 
 // Manager manages sessions
 type Manager struct {
-	store      storage.Store
+	store       storage.Store
 	jsonlWriter *JSONLWriter
+	statusHook  func(sess *Session, previous Status)
 }
 
 // NewManager creates a new session manager
@@ -27,6 +31,13 @@ func (m *Manager) SetJSONLWriter(w *JSONLWriter) {
 	m.jsonlWriter = w
 }
 
+// SetStatusHook registers fn to run after every save that changes a
+// session's status. It runs on the saving goroutine, so it must not block;
+// previous is empty for a session's first save. Set it before use.
+func (m *Manager) SetStatusHook(fn func(sess *Session, previous Status)) {
+	m.statusHook = fn
+}
+
 // SetJSONLFolder updates the folder used for JSONL persistence.
 // If the writer has not been initialised yet it is created. Passing "" disables writing.
 func (m *Manager) SetJSONLFolder(folder string) {
@@ -86,15 +97,70 @@ func (m *Manager) CreateWithParent(agentID, parentID string) (*Session, error) {
 	return sess, nil
 }
 
-// CreateWithJob creates a new session associated with a recurring job
-func (m *Manager) CreateWithJob(agentID, jobID string) (*Session, error) {
+// CreateWithJob creates a new session associated with a recurring job and,
+// when executionID is set, the execution of it that the session runs
+func (m *Manager) CreateWithJob(agentID, jobID, executionID string) (*Session, error) {
 	sess := NewWithJob(agentID, jobID)
+	if executionID != "" {
+		sess.Metadata[ExecutionIDMetadataKey] = executionID
+	}
 	if err := m.store.SaveSession(sess.ToStorage()); err != nil {
 		return nil, fmt.Errorf("failed to save session: %w", err)
 	}
 	return sess, nil
 }
 
+// Fork creates a child session that copies the parent's messages before
+// atMessageIndex (all messages when atMessageIndex is negative or past the end).
+// Copied messages get fresh IDs so they never collide with the parent's rows.
+func (m *Manager) Fork(sessionID string, atMessageIndex int) (*Session, error) {
+	parent, err := m.Get(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	count := len(parent.Messages)
+	if atMessageIndex >= 0 && atMessageIndex < count {
+		count = atMessageIndex
+	}
+
+	fork := NewWithParent(parent.AgentID, parent.ID)
+	fork.Status = StatusPaused
+	if parent.ProjectID != nil {
+		projectID := *parent.ProjectID
+		fork.ProjectID = &projectID
+	}
+	for k, v := range parent.Metadata {
+		fork.Metadata[k] = v
+	}
+	fork.Metadata["link_type"] = LinkTypeFork
+	fork.Metadata["forked_at_message_index"] = count
+	if strings.TrimSpace(parent.Title) != "" {
+		fork.SetTitleWithSource(parent.Title+" (fork)", TitleSourceManual)
+	}
+
+	fork.Messages = make([]Message, 0, count)
+	for _, msg := range parent.Messages[:count] {
+		copied := msg
+		copied.ID = uuid.New().String()
+		copied.Images = append([]ImageAttachment(nil), msg.Images...)
+		copied.ToolCalls = append([]ToolCall(nil), msg.ToolCalls...)
+		copied.ToolResults = append([]ToolResult(nil), msg.ToolResults...)
+		if msg.Metadata != nil {
+			copied.Metadata = make(map[string]interface{}, len(msg.Metadata))
+			for k, v := range msg.Metadata {
+				copied.Metadata[k] = v
+			}
+		}
+		fork.Messages = append(fork.Messages, copied)
+	}
+
+	if err := m.Save(fork); err != nil {
+		return nil, fmt.Errorf("failed to save forked session: %w", err)
+	}
+	return fork, nil
+}
+
 // Get retrieves a session by ID
 func (m *Manager) Get(id string) (*Session, error) {
 	ss, err := m.store.GetSession(id)
@@ -104,11 +170,65 @@ func (m *Manager) Get(id string) (*Session, error) {
 	return FromStorage(ss), nil
 }
 
+// maxSaveAttempts bounds how often Save and Update retry after losing a race
+// with another writer.
+const maxSaveAttempts = 5
+
 // Save saves a session and appends new messages to the JSONL log (if configured).
+// If another writer saved the session since sess was loaded, Save re-reads it
+// and keeps the other writer's changes to fields sess did not touch (see
+// rebaseOnto) before retrying. Use Update for read-modify-write helpers.
 func (m *Manager) Save(sess *Session) error {
-	if err := m.store.SaveSession(sess.ToStorage()); err != nil {
+	for attempt := 1; ; attempt++ {
+		err := m.save(sess)
+		if !errors.Is(err, ErrStaleSession) || attempt >= maxSaveAttempts {
+			return err
+		}
+		stored, getErr := m.Get(sess.ID)
+		if getErr != nil {
+			return err
+		}
+		sess.rebaseOnto(stored)
+	}
+}
+
+// Update loads the latest copy of a session, applies mutate and saves it. When
+// another writer saves first, the session is re-read and mutate re-applied, so
+// concurrent updates never overwrite each other.
+func (m *Manager) Update(id string, mutate func(*Session) error) (*Session, error) {
+	for attempt := 1; ; attempt++ {
+		sess, err := m.Get(id)
+		if err != nil {
+			return nil, fmt.Errorf("session not found: %w", err)
+		}
+		if err := mutate(sess); err != nil {
+			return nil, err
+		}
+		err = m.save(sess)
+		if err == nil {
+			return sess, nil
+		}
+		if !errors.Is(err, ErrStaleSession) || attempt >= maxSaveAttempts {
+			return nil, err
+		}
+	}
+}
+
+// save writes sess once, failing with ErrStaleSession on a version conflict.
+func (m *Manager) save(sess *Session) error {
+	var previous Status
+	if sess.saved != nil {
+		previous = sess.saved.status
+	}
+	stored := sess.ToStorage()
+	if err := m.store.SaveSession(stored); err != nil {
 		return err
 	}
+	sess.Version = stored.Version
+	sess.saved = snapshotState(sess)
+	if m.statusHook != nil && sess.Status != previous {
+		m.statusHook(sess, previous)
+	}
 	// Best-effort JSONL flush – do not fail the save if writing fails.
 	if m.jsonlWriter != nil {
 		if err := m.jsonlWriter.Flush(sess); err != nil {
@@ -134,6 +254,20 @@ func (m *Manager) List() ([]*Session, error) {
 	return sessions, nil
 }
 
+// ListPage lists one page of sessions matching filter and the total match count.
+func (m *Manager) ListPage(filter storage.SessionFilter) ([]*Session, int, error) {
+	stored, total, err := m.store.ListSessionsPage(filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sessions := make([]*Session, len(stored))
+	for i, ss := range stored {
+		sessions[i] = FromStorage(ss)
+	}
+	return sessions, total, nil
+}
+
 // Delete deletes a session
 func (m *Manager) Delete(id string) error {
 	return m.store.DeleteSession(id)
@@ -156,17 +290,29 @@ type QuestionOption struct {
 
 // SetPendingQuestion stores a pending question in session metadata
 func (m *Manager) SetPendingQuestion(sessionID string, data *QuestionData) error {
-	sess, err := m.Get(sessionID)
-	if err != nil {
-		return fmt.Errorf("session not found: %w", err)
-	}
-
-	if sess.Metadata == nil {
-		sess.Metadata = make(map[string]interface{})
-	}
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		if sess.Metadata == nil {
+			sess.Metadata = make(map[string]interface{})
+		}
+		sess.Metadata["pending_question"] = data
+		return nil
+	})
+	return err
+}
 
-	sess.Metadata["pending_question"] = data
-	return m.Save(sess)
+// AskQuestion stores a pending question and moves the session to
+// StatusInputRequired in a single update, so readers never see one without
+// the other.
+func (m *Manager) AskQuestion(sessionID string, data *QuestionData) error {
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		if sess.Metadata == nil {
+			sess.Metadata = make(map[string]interface{})
+		}
+		sess.Metadata["pending_question"] = data
+		sess.SetStatus(StatusInputRequired)
+		return nil
+	})
+	return err
 }
 
 // GetPendingQuestion retrieves pending question from session metadata
@@ -175,13 +321,18 @@ func (m *Manager) GetPendingQuestion(sessionID string) (*QuestionData, error) {
 	if err != nil {
 		return nil, fmt.Errorf("session not found: %w", err)
 	}
+	return sess.PendingQuestion()
+}
 
-	if sess.Status != StatusInputRequired {
+// PendingQuestion returns the question the session is waiting on, or nil when
+// it is not in StatusInputRequired.
+func (s *Session) PendingQuestion() (*QuestionData, error) {
+	if s.Status != StatusInputRequired {
 		return nil, nil
 	}
 
-	data, ok := sess.Metadata["pending_question"]
-	if !ok {
+	data, ok := s.Metadata["pending_question"]
+	if !ok || data == nil {
 		return nil, nil
 	}
 
@@ -199,41 +350,127 @@ func (m *Manager) GetPendingQuestion(sessionID string) (*QuestionData, error) {
 	return &question, nil
 }
 
-// AnswerQuestion handles user's answer to a pending question
-func (m *Manager) AnswerQuestion(sessionID string, answer string) error {
-	sess, err := m.Get(sessionID)
-	if err != nil {
-		return fmt.Errorf("session not found: %w", err)
+// ResolveAnswer validates the selected answers against the question and
+// returns the text recorded as the user's reply. Answers must match option
+// labels unless Custom is set; several answers are only accepted when
+// Multiple is set and are joined with ", ".
+func (q *QuestionData) ResolveAnswer(answers []string) (string, error) {
+	var picked []string
+	for _, answer := range answers {
+		answer = strings.TrimSpace(answer)
+		if answer == "" {
+			continue
+		}
+		if len(q.Options) > 0 && !q.Custom {
+			label, ok := q.matchOption(answer)
+			if !ok {
+				return "", fmt.Errorf("%q is not one of the offered options", answer)
+			}
+			answer = label
+		} else if label, ok := q.matchOption(answer); ok {
+			answer = label
+		}
+		picked = append(picked, answer)
+	}
+	if len(picked) == 0 {
+		return "", fmt.Errorf("answer is required")
+	}
+	if len(picked) > 1 && !q.Multiple {
+		return "", fmt.Errorf("question accepts a single answer")
 	}
+	return strings.Join(picked, ", "), nil
+}
 
-	if sess.Status != StatusInputRequired {
-		return fmt.Errorf("session is not waiting for input")
+func (q *QuestionData) matchOption(answer string) (string, bool) {
+	for _, option := range q.Options {
+		if strings.EqualFold(strings.TrimSpace(option.Label), answer) {
+			return option.Label, true
+		}
 	}
+	return "", false
+}
 
-	// Remove pending question
-	delete(sess.Metadata, "pending_question")
+// AnswerQuestion handles user's answer to a pending question
+func (m *Manager) AnswerQuestion(sessionID string, answer string) error {
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		if sess.Status != StatusInputRequired {
+			return fmt.Errorf("session is not waiting for input")
+		}
 
-	// Add user answer as a message
-	sess.AddMessage(Message{
-		Role:    "user",
-		Content: answer,
-	})
+		// Remove pending question
+		delete(sess.Metadata, "pending_question")
 
-	// Resume session
-	sess.SetStatus(StatusRunning)
+		// Add user answer as a message
+		sess.AddMessage(Message{
+			Role:    "user",
+			Content: answer,
+		})
 
-	return m.Save(sess)
+		// Resume session
+		sess.SetStatus(StatusRunning)
+		return nil
+	})
+	return err
 }
 
-// SetSessionStatus updates session status (used by question tool)
+// SetSessionStatus updates session status
 func (m *Manager) SetSessionStatus(sessionID string, status string) error {
-	sess, err := m.Get(sessionID)
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		sess.SetStatus(Status(status))
+		return nil
+	})
+	return err
+}
+
+// Rename sets a user-chosen title, which automatic titling will not replace.
+func (m *Manager) Rename(sessionID string, title string) (*Session, error) {
+	return m.Update(sessionID, func(sess *Session) error {
+		sess.SetTitleWithSource(title, TitleSourceManual)
+		return nil
+	})
+}
+
+// ApplyGeneratedTitle stores an automatically generated title unless the
+// session was renamed in the meantime. It reports whether the title was applied.
+func (m *Manager) ApplyGeneratedTitle(sessionID string, title string) (bool, error) {
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		if !sess.NeedsGeneratedTitle() {
+			return errTitleAlreadySet
+		}
+		sess.SetTitleWithSource(title, TitleSourceGenerated)
+		return nil
+	})
+	if errors.Is(err, errTitleAlreadySet) {
+		return false, nil
+	}
 	if err != nil {
-		return fmt.Errorf("session not found: %w", err)
+		return false, err
 	}
+	return true, nil
+}
 
-	sess.SetStatus(Status(status))
-	return m.Save(sess)
+var errTitleAlreadySet = errors.New("session title already set")
+
+// SaveToolOutput stores the full output of a truncated tool result and
+// returns the ID it can be fetched with.
+func (m *Manager) SaveToolOutput(sessionID, toolCallID, toolName, content string) (string, error) {
+	output := &storage.ToolOutput{
+		ID:         uuid.New().String(),
+		SessionID:  sessionID,
+		ToolCallID: toolCallID,
+		ToolName:   toolName,
+		Content:    content,
+		CreatedAt:  time.Now(),
+	}
+	if err := m.store.SaveToolOutput(output); err != nil {
+		return "", err
+	}
+	return output.ID, nil
+}
+
+// GetToolOutput returns a stored tool output.
+func (m *Manager) GetToolOutput(id string) (*storage.ToolOutput, error) {
+	return m.store.GetToolOutput(id)
 }
 
 // GetSessionTaskProgress retrieves task progress for a session
@@ -247,12 +484,11 @@ func (m *Manager) GetSessionTaskProgress(sessionID string) (string, error) {
 
 // SetSessionTaskProgress updates task progress for a session
 func (m *Manager) SetSessionTaskProgress(sessionID string, progress string) error {
-	sess, err := m.Get(sessionID)
-	if err != nil {
-		return fmt.Errorf("session not found: %w", err)
-	}
-	sess.TaskProgress = progress
-	return m.Save(sess)
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		sess.TaskProgress = progress
+		return nil
+	})
+	return err
 }
 
 // Project represents a project for grouping sessions
@@ -304,11 +540,9 @@ func (m *Manager) GetProject(id string) (*Project, error) {
 
 // SetSessionProject associates a session with a project
 func (m *Manager) SetSessionProject(sessionID string, projectID *string) error {
-	sess, err := m.Get(sessionID)
-	if err != nil {
-		return fmt.Errorf("session not found: %w", err)
-	}
-
-	sess.ProjectID = projectID
-	return m.Save(sess)
+	_, err := m.Update(sessionID, func(sess *Session) error {
+		sess.ProjectID = projectID
+		return nil
+	})
+	return err
 }
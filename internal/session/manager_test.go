@@ -0,0 +1,252 @@
+package session
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestGeneratedTitleReplacesPromptTitleOnly(t *testing.T) {
+	m := NewManager(newMemStore())
+	sess, err := m.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	sess.AddUserMessage("please refactor the storage layer so that sessions can be paginated")
+	if err := m.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if !sess.NeedsGeneratedTitle() {
+		t.Fatal("prompt-derived title should be eligible for generation")
+	}
+
+	applied, err := m.ApplyGeneratedTitle(sess.ID, "Paginate session storage")
+	if err != nil || !applied {
+		t.Fatalf("ApplyGeneratedTitle = %v, %v", applied, err)
+	}
+	got, _ := m.Get(sess.ID)
+	if got.Title != "Paginate session storage" || got.TitleSource() != TitleSourceGenerated {
+		t.Fatalf("unexpected title %q (source %q)", got.Title, got.TitleSource())
+	}
+
+	if _, err := m.Rename(sess.ID, "My storage work"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	applied, err = m.ApplyGeneratedTitle(sess.ID, "Something else")
+	if err != nil || applied {
+		t.Fatalf("generated title must not override manual rename: applied=%v err=%v", applied, err)
+	}
+	got, _ = m.Get(sess.ID)
+	if got.Title != "My storage work" {
+		t.Fatalf("expected manual title to stick, got %q", got.Title)
+	}
+}
+
+func TestForkCopiesHistoryWithFreshIDs(t *testing.T) {
+	m := NewManager(newMemStore())
+	parent, err := m.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	parent.AddUserMessage("first")
+	parent.AddAssistantMessage("reply", nil)
+	parent.AddUserMessage("second")
+	if err := m.Save(parent); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	fork, err := m.Fork(parent.ID, 2)
+	if err != nil {
+		t.Fatalf("Fork: %v", err)
+	}
+	if fork.ParentID == nil || *fork.ParentID != parent.ID {
+		t.Fatalf("expected parent %s, got %v", parent.ID, fork.ParentID)
+	}
+	if len(fork.Messages) != 2 {
+		t.Fatalf("expected 2 copied messages, got %d", len(fork.Messages))
+	}
+	for i, msg := range fork.Messages {
+		if msg.ID == "" || msg.ID == parent.Messages[i].ID {
+			t.Fatalf("message %d kept parent ID %q", i, msg.ID)
+		}
+		if msg.Content != parent.Messages[i].Content {
+			t.Fatalf("message %d content = %q", i, msg.Content)
+		}
+	}
+	if fork.Metadata["link_type"] != LinkTypeFork {
+		t.Fatalf("expected fork link type, got %v", fork.Metadata["link_type"])
+	}
+
+	full, err := m.Fork(parent.ID, -1)
+	if err != nil {
+		t.Fatalf("Fork full: %v", err)
+	}
+	if len(full.Messages) != len(parent.Messages) {
+		t.Fatalf("full fork copied %d of %d messages", len(full.Messages), len(parent.Messages))
+	}
+}
+
+func TestConcurrentUpdatesAreNotLost(t *testing.T) {
+	m := NewManager(newMemStore())
+	sess, err := m.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	const perWriter = 50
+	var wg sync.WaitGroup
+	for _, key := range []string{"writer_a", "writer_b"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			for i := 1; i <= perWriter; i++ {
+				_, err := m.Update(sess.ID, func(s *Session) error {
+					count, _ := s.Metadata[key].(int)
+					s.Metadata[key] = count + 1
+					return nil
+				})
+				if err != nil {
+					t.Errorf("Update(%s): %v", key, err)
+					return
+				}
+			}
+		}(key)
+	}
+	wg.Wait()
+
+	got, _ := m.Get(sess.ID)
+	for _, key := range []string{"writer_a", "writer_b"} {
+		if got.Metadata[key] != perWriter {
+			t.Fatalf("%s = %v, want %d", key, got.Metadata[key], perWriter)
+		}
+	}
+}
+
+func TestSaveRebasesOverConcurrentChanges(t *testing.T) {
+	store := newMemStore()
+	m := NewManager(store)
+	sess, err := m.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	owner, _ := m.Get(sess.ID)
+	if err := m.SetSessionStatus(sess.ID, string(StatusInputRequired)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+	if _, err := m.Rename(sess.ID, "Renamed elsewhere"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if err := store.SaveSession(owner.ToStorage()); !errors.Is(err, ErrStaleSession) {
+		t.Fatalf("expected ErrStaleSession from store, got %v", err)
+	}
+
+	owner.AddAssistantMessage("still working", nil)
+	owner.Metadata["owner_key"] = "kept"
+	if err := m.Save(owner); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	got, _ := m.Get(sess.ID)
+	if got.Status != StatusInputRequired {
+		t.Fatalf("concurrent status change lost: %s", got.Status)
+	}
+	if got.Title != "Renamed elsewhere" || got.TitleSource() != TitleSourceManual {
+		t.Fatalf("concurrent rename lost: %q (%s)", got.Title, got.TitleSource())
+	}
+	if got.Metadata["owner_key"] != "kept" || len(got.Messages) != 1 {
+		t.Fatalf("owner changes lost: metadata=%v messages=%d", got.Metadata, len(got.Messages))
+	}
+}
+
+func TestSessionModelAndTemperatureOverrides(t *testing.T) {
+	m := NewManager(newMemStore())
+	sess, err := m.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if sess.Model() != "" {
+		t.Fatalf("new session should not be pinned, got %q", sess.Model())
+	}
+	if _, ok := sess.Temperature(); ok {
+		t.Fatal("new session should not have a temperature override")
+	}
+
+	temperature := 0.3
+	sess.SetModel(" gpt-4o ")
+	sess.SetTemperature(&temperature)
+	if err := m.Save(sess); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, _ := m.Get(sess.ID)
+	if got.Model() != "gpt-4o" {
+		t.Fatalf("expected pinned model gpt-4o, got %q", got.Model())
+	}
+	if value, ok := got.Temperature(); !ok || value != 0.3 {
+		t.Fatalf("expected temperature 0.3, got %v (set=%v)", value, ok)
+	}
+
+	got.SetModel("")
+	got.SetTemperature(nil)
+	if got.Model() != "" {
+		t.Fatalf("expected model pin to be cleared, got %q", got.Model())
+	}
+	if _, ok := got.Temperature(); ok {
+		t.Fatal("expected temperature override to be cleared")
+	}
+}
+
+func TestQuestionResolveAnswer(t *testing.T) {
+	single := &QuestionData{Options: []QuestionOption{{Label: "Yes"}, {Label: "No"}}}
+	if got, err := single.ResolveAnswer([]string{" yes "}); err != nil || got != "Yes" {
+		t.Fatalf("ResolveAnswer(yes) = %q, %v", got, err)
+	}
+	if _, err := single.ResolveAnswer([]string{"maybe"}); err == nil {
+		t.Fatal("expected unknown option to be rejected without Custom")
+	}
+	if _, err := single.ResolveAnswer([]string{"Yes", "No"}); err == nil {
+		t.Fatal("expected several answers to be rejected without Multiple")
+	}
+	if _, err := single.ResolveAnswer([]string{""}); err == nil {
+		t.Fatal("expected empty answer to be rejected")
+	}
+
+	multi := &QuestionData{Options: []QuestionOption{{Label: "Go"}, {Label: "Rust"}}, Multiple: true, Custom: true}
+	if got, err := multi.ResolveAnswer([]string{"go", "Zig"}); err != nil || got != "Go, Zig" {
+		t.Fatalf("ResolveAnswer(multi) = %q, %v", got, err)
+	}
+}
+
+func TestPendingQuestionOnlyWhileInputRequired(t *testing.T) {
+	m := NewManager(newMemStore())
+	sess, err := m.Create("build")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	question := &QuestionData{Question: "Proceed?", Options: []QuestionOption{{Label: "Yes"}}}
+	if err := m.SetPendingQuestion(sess.ID, question); err != nil {
+		t.Fatalf("SetPendingQuestion: %v", err)
+	}
+	if got, _ := m.GetPendingQuestion(sess.ID); got != nil {
+		t.Fatalf("question should be hidden until input is required, got %+v", got)
+	}
+	if err := m.SetSessionStatus(sess.ID, string(StatusInputRequired)); err != nil {
+		t.Fatalf("SetSessionStatus: %v", err)
+	}
+	got, err := m.GetPendingQuestion(sess.ID)
+	if err != nil || got == nil || got.Question != "Proceed?" {
+		t.Fatalf("GetPendingQuestion = %+v, %v", got, err)
+	}
+
+	if err := m.AnswerQuestion(sess.ID, "Yes"); err != nil {
+		t.Fatalf("AnswerQuestion: %v", err)
+	}
+	answered, _ := m.Get(sess.ID)
+	if answered.Status != StatusRunning || answered.Messages[len(answered.Messages)-1].Content != "Yes" {
+		t.Fatalf("unexpected session after answer: status=%s messages=%+v", answered.Status, answered.Messages)
+	}
+	if q, _ := answered.PendingQuestion(); q != nil {
+		t.Fatal("pending question should be cleared after answering")
+	}
+}
@@ -35,6 +35,9 @@ type Session struct {
 	TaskProgress string                 `json:"task_progress,omitempty"` // Temporary task planning and progress tracking
 	CreatedAt    time.Time              `json:"created_at"`
 	UpdatedAt    time.Time              `json:"updated_at"`
+	Version      int64                  `json:"version,omitempty"` // Stored revision, used to detect concurrent saves
+
+	saved *savedState // State as last loaded or saved; see Manager.Save
 }
 
 // Message represents a conversation message
@@ -72,6 +75,11 @@ type ToolResult struct {
 	IsError    bool                   `json:"is_error,omitempty"`
 	Metadata   map[string]interface{} `json:"metadata,omitempty"`
 	Name       string                 `json:"name,omitempty"` // Tool name (required by Gemini)
+	// Set by tools.Manager. OutputBytes is the size of the output before it
+	// was truncated to fit the model context.
+	StartedAt   time.Time `json:"started_at,omitzero"`
+	DurationMs  int64     `json:"duration_ms,omitempty"`
+	OutputBytes int       `json:"output_bytes,omitempty"`
 }
 
 // New creates a new session
@@ -136,7 +144,7 @@ func (s *Session) AddUserMessageWithImages(content string, images []ImageAttachm
 		if title == "" && len(images) > 0 {
 			title = "Image request"
 		}
-		s.SetTitle(title)
+		s.SetTitleWithSource(title, TitleSourcePrompt)
 	}
 
 	s.AddMessage(Message{
@@ -210,6 +218,120 @@ func (s *Session) SetTitle(title string) {
 	s.UpdatedAt = time.Now()
 }
 
+// LinkTypeFork marks sessions created by Manager.Fork in the "link_type" metadata key.
+const LinkTypeFork = "fork"
+
+// Title sources recorded in session metadata so automatic titling never
+// overrides a title chosen by a caller or user.
+const (
+	titleSourceMetadataKey = "title_source"
+	TitleSourcePrompt      = "prompt"
+	TitleSourceGenerated   = "generated"
+	TitleSourceManual      = "manual"
+)
+
+// SetTitleWithSource sets the session title and records where it came from.
+func (s *Session) SetTitleWithSource(title string, source string) {
+	s.SetTitle(title)
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]interface{})
+	}
+	s.Metadata[titleSourceMetadataKey] = source
+}
+
+// TitleSource returns how the current title was set, or "" if unknown.
+func (s *Session) TitleSource() string {
+	if s.Metadata == nil {
+		return ""
+	}
+	source, _ := s.Metadata[titleSourceMetadataKey].(string)
+	return source
+}
+
+// NeedsGeneratedTitle reports whether the title is empty or only a truncated
+// copy of the first prompt, i.e. a summarized title should replace it.
+func (s *Session) NeedsGeneratedTitle() bool {
+	return strings.TrimSpace(s.Title) == "" || s.TitleSource() == TitleSourcePrompt
+}
+
+// FirstUserMessage returns the content of the first user message, if any.
+func (s *Session) FirstUserMessage() string {
+	for _, msg := range s.Messages {
+		if msg.Role == "user" && strings.TrimSpace(msg.Content) != "" {
+			return msg.Content
+		}
+	}
+	return ""
+}
+
+// ExecutionIDMetadataKey holds the ID of the job execution a session runs.
+const ExecutionIDMetadataKey = "execution_id"
+
+// ExecutionID returns the ID of the job execution the session runs, or "" for
+// sessions not started by a job run.
+func (s *Session) ExecutionID() string {
+	if s.Metadata == nil {
+		return ""
+	}
+	id, _ := s.Metadata[ExecutionIDMetadataKey].(string)
+	return id
+}
+
+// Metadata keys for the model and sampling temperature a session is pinned to.
+const (
+	ModelMetadataKey       = "model"
+	TemperatureMetadataKey = "temperature"
+)
+
+// Model returns the model the session is pinned to, or "" for the default.
+func (s *Session) Model() string {
+	if s.Metadata == nil {
+		return ""
+	}
+	model, _ := s.Metadata[ModelMetadataKey].(string)
+	return strings.TrimSpace(model)
+}
+
+// SetModel pins the session to model; an empty model clears the override.
+func (s *Session) SetModel(model string) {
+	model = strings.TrimSpace(model)
+	if model == "" {
+		delete(s.Metadata, ModelMetadataKey)
+		return
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]interface{})
+	}
+	s.Metadata[ModelMetadataKey] = model
+}
+
+// Temperature returns the session's temperature override, if set.
+func (s *Session) Temperature() (float64, bool) {
+	if s.Metadata == nil {
+		return 0, false
+	}
+	switch v := s.Metadata[TemperatureMetadataKey].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// SetTemperature overrides the sampling temperature; nil clears the override.
+func (s *Session) SetTemperature(temperature *float64) {
+	if temperature == nil {
+		delete(s.Metadata, TemperatureMetadataKey)
+		return
+	}
+	if s.Metadata == nil {
+		s.Metadata = make(map[string]interface{})
+	}
+	s.Metadata[TemperatureMetadataKey] = *temperature
+}
+
 // ToStorage converts to storage format
 func (s *Session) ToStorage() *storage.Session {
 	messages := make([]storage.Message, len(s.Messages))
@@ -240,6 +362,7 @@ func (s *Session) ToStorage() *storage.Session {
 		TaskProgress: s.TaskProgress,
 		CreatedAt:    s.CreatedAt,
 		UpdatedAt:    s.UpdatedAt,
+		Version:      s.Version,
 	}
 }
 
@@ -266,7 +389,7 @@ func FromStorage(ss *storage.Session) *Session {
 		}
 	}
 
-	return &Session{
+	sess := &Session{
 		ID:           ss.ID,
 		AgentID:      ss.AgentID,
 		ParentID:     ss.ParentID,
@@ -279,7 +402,10 @@ func FromStorage(ss *storage.Session) *Session {
 		TaskProgress: ss.TaskProgress,
 		CreatedAt:    ss.CreatedAt,
 		UpdatedAt:    ss.UpdatedAt,
+		Version:      ss.Version,
 	}
+	sess.saved = snapshotState(sess)
+	return sess
 }
 
 const messageImagesMetadataKey = "images"
@@ -0,0 +1,207 @@
+package skills
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Setting keys for the skills folder and its disabled skills. Settings are
+// mirrored into the environment, so the TUI and the use_skill tool read them
+// from there.
+const (
+	FolderSettingKey   = "AAGENT_SKILLS_FOLDER"
+	DisabledSettingKey = "A2GENT_EXTERNAL_MARKDOWN_DISABLED_SKILLS"
+)
+
+// SessionSelectionMetadataKey holds the skills selected for one session. When
+// present it replaces the global selection for that session.
+const SessionSelectionMetadataKey = "selected_skills"
+
+// ResolveFolder returns the absolute path of a configured skills folder.
+func ResolveFolder(folder string) (string, error) {
+	folder = strings.TrimSpace(folder)
+	if folder == "" {
+		return "", fmt.Errorf("skills folder is not configured")
+	}
+	resolved, err := filepath.Abs(folder)
+	if err != nil {
+		return "", fmt.Errorf("invalid skills folder path")
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("skills folder is not accessible: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("skills folder path is not a directory")
+	}
+	return resolved, nil
+}
+
+// ParsePathList parses a JSON array or a comma/newline separated list of skill
+// paths into cleaned absolute paths. Relative entries are resolved against folder.
+func ParsePathList(raw string, folder string) map[string]struct{} {
+	paths := make(map[string]struct{})
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return paths
+	}
+	entries := make([]string, 0)
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		entries = strings.FieldsFunc(raw, func(r rune) bool {
+			return r == ',' || r == '\n'
+		})
+	}
+	for _, entry := range entries {
+		candidate := strings.TrimSpace(entry)
+		if candidate == "" {
+			continue
+		}
+		if !filepath.IsAbs(candidate) {
+			candidate = filepath.Join(folder, candidate)
+		}
+		resolved, err := filepath.Abs(candidate)
+		if err != nil {
+			continue
+		}
+		paths[filepath.Clean(resolved)] = struct{}{}
+	}
+	return paths
+}
+
+// LoadActive loads the skills of folder that are enabled. Skills disabled by
+// their strategy are always skipped. When selected is non-nil it replaces the
+// disabled list: only skills it names by name, relative path or absolute path
+// are loaded.
+func LoadActive(folder string, disabled map[string]struct{}, selected []string) ([]*Skill, *SkillConfig, error) {
+	config, err := LoadConfig(folder)
+	if err != nil {
+		config = DefaultConfig()
+	}
+	all, err := LoadSkillsFromDirectory(folder, config)
+	if err != nil {
+		return nil, config, err
+	}
+
+	var wanted map[string]struct{}
+	if selected != nil {
+		wanted = make(map[string]struct{}, len(selected))
+		for _, ref := range selected {
+			wanted[strings.TrimSpace(ref)] = struct{}{}
+		}
+	}
+
+	active := make([]*Skill, 0, len(all))
+	for _, skill := range all {
+		if skill.Strategy == StrategyDisabled {
+			continue
+		}
+		if wanted != nil {
+			if !matchesAny(skill, wanted) {
+				continue
+			}
+		} else if _, isDisabled := disabled[filepath.Clean(strings.TrimSpace(skill.Path))]; isDisabled {
+			continue
+		}
+		active = append(active, skill)
+	}
+	return active, config, nil
+}
+
+// Find loads the skill of folder whose name or relative path is ref.
+func Find(folder string, ref string) (*Skill, error) {
+	ref = strings.TrimSpace(ref)
+	config, err := LoadConfig(folder)
+	if err != nil {
+		config = DefaultConfig()
+	}
+	all, err := LoadSkillsFromDirectory(folder, config)
+	if err != nil {
+		return nil, err
+	}
+	for _, skill := range all {
+		if matchesAny(skill, map[string]struct{}{ref: {}}) {
+			return skill, nil
+		}
+	}
+	return nil, fmt.Errorf("skill %q not found", ref)
+}
+
+func matchesAny(skill *Skill, refs map[string]struct{}) bool {
+	for _, candidate := range []string{skill.Name, skill.RelativePath, filepath.Clean(skill.Path)} {
+		if _, ok := refs[candidate]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SessionSelection returns the skills selected for a session from its
+// metadata, or nil when the session follows the global selection.
+func SessionSelection(metadata map[string]interface{}) []string {
+	raw, ok := metadata[SessionSelectionMetadataKey]
+	if !ok || raw == nil {
+		return nil
+	}
+	selected := []string{}
+	switch values := raw.(type) {
+	case []string:
+		selected = append(selected, values...)
+	case []interface{}:
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				selected = append(selected, s)
+			}
+		}
+	default:
+		return nil
+	}
+	return selected
+}
+
+// PromptSection renders active skills for the system prompt. Skills with the
+// "always" strategy are included in full (bodies capped at maxBodyBytes);
+// the others are listed by name and description for the use_skill tool.
+func PromptSection(active []*Skill, maxBodyBytes int) string {
+	alwaysSkills := make([]*Skill, 0)
+	onDemandSkills := make([]*Skill, 0)
+	for _, skill := range active {
+		if skill.Strategy == StrategyAlways {
+			alwaysSkills = append(alwaysSkills, skill)
+		} else {
+			onDemandSkills = append(onDemandSkills, skill)
+		}
+	}
+	sort.SliceStable(alwaysSkills, func(i, j int) bool {
+		return alwaysSkills[i].Priority < alwaysSkills[j].Priority
+	})
+	sort.SliceStable(onDemandSkills, func(i, j int) bool {
+		return strings.ToLower(onDemandSkills[i].Name) < strings.ToLower(onDemandSkills[j].Name)
+	})
+
+	var builder strings.Builder
+	if len(alwaysSkills) > 0 {
+		builder.WriteString("Loaded skills (always available):\n\n")
+		for _, skill := range alwaysSkills {
+			content := skill.Body
+			if maxBodyBytes > 0 && len(content) > maxBodyBytes {
+				content = content[:maxBodyBytes] + "\n\n[truncated]"
+			}
+			builder.WriteString(fmt.Sprintf("Instructions from: %s\n%s\n\n", skill.RelativePath, content))
+		}
+	}
+	if len(onDemandSkills) > 0 {
+		builder.WriteString("Available skills (call use_skill with the name or path to load the full instructions before following a skill):\n\n")
+		for _, skill := range onDemandSkills {
+			if skill.Description != "" {
+				builder.WriteString(fmt.Sprintf("- %s: %s [%s]\n", skill.Name, skill.Description, skill.RelativePath))
+			} else {
+				builder.WriteString(fmt.Sprintf("- %s [%s]\n", skill.Name, skill.RelativePath))
+			}
+		}
+	}
+	return builder.String()
+}
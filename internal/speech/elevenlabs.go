@@ -0,0 +1,148 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderElevenLabs = "elevenlabs"
+
+	elevenLabsDefaultBaseURL = "https://api.elevenlabs.io/v1"
+	elevenLabsDefaultModel   = "eleven_multilingual_v2"
+)
+
+// ElevenLabs implements Provider against the ElevenLabs REST API.
+type ElevenLabs struct {
+	APIKey         string
+	BaseURL        string
+	DefaultVoiceID string
+	DefaultModelID string
+	DefaultSpeed   float64
+	Client         *http.Client
+}
+
+// NewElevenLabs creates an ElevenLabs provider with the given defaults.
+func NewElevenLabs(apiKey string, defaultVoiceID string, defaultSpeed float64) *ElevenLabs {
+	return &ElevenLabs{
+		APIKey:         strings.TrimSpace(apiKey),
+		BaseURL:        elevenLabsDefaultBaseURL,
+		DefaultVoiceID: strings.TrimSpace(defaultVoiceID),
+		DefaultModelID: elevenLabsDefaultModel,
+		DefaultSpeed:   defaultSpeed,
+		Client:         &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (p *ElevenLabs) Name() string {
+	return ProviderElevenLabs
+}
+
+type elevenLabsVoicesResponse struct {
+	Voices []struct {
+		VoiceID    string `json:"voice_id"`
+		Name       string `json:"name"`
+		PreviewURL string `json:"preview_url,omitempty"`
+	} `json:"voices"`
+}
+
+type elevenLabsTTSRequest struct {
+	Text          string                   `json:"text"`
+	ModelID       string                   `json:"model_id"`
+	VoiceSettings *elevenLabsVoiceSettings `json:"voice_settings,omitempty"`
+}
+
+type elevenLabsVoiceSettings struct {
+	Speed float64 `json:"speed,omitempty"`
+}
+
+func (p *ElevenLabs) ListVoices(ctx context.Context) ([]Voice, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.BaseURL, "/")+"/voices", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ElevenLabs request: %w", err)
+	}
+	req.Header.Set("xi-api-key", p.APIKey)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch ElevenLabs voices: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, newAPIError(p.Name(), resp.StatusCode, resp.Status, resp.Body)
+	}
+
+	var payload elevenLabsVoicesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("failed to decode ElevenLabs voices response: %w", err)
+	}
+	voices := make([]Voice, 0, len(payload.Voices))
+	for _, v := range payload.Voices {
+		voices = append(voices, Voice{
+			VoiceID:    v.VoiceID,
+			Name:       v.Name,
+			PreviewURL: v.PreviewURL,
+			Provider:   p.Name(),
+		})
+	}
+	return voices, nil
+}
+
+func (p *ElevenLabs) Synthesize(ctx context.Context, r Request) (*Audio, error) {
+	voiceID := strings.TrimSpace(r.VoiceID)
+	if voiceID == "" {
+		voiceID = p.DefaultVoiceID
+	}
+	if voiceID == "" {
+		return nil, fmt.Errorf("voice_id is required (pass voice_id or set ELEVENLABS_VOICE_ID)")
+	}
+	modelID := strings.TrimSpace(r.ModelID)
+	if modelID == "" {
+		modelID = p.DefaultModelID
+	}
+	speed := r.Speed
+	if speed <= 0 {
+		speed = p.DefaultSpeed
+	}
+
+	body := elevenLabsTTSRequest{Text: r.Text, ModelID: modelID}
+	if speed > 0 {
+		body.VoiceSettings = &elevenLabsVoiceSettings{Speed: speed}
+	}
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ElevenLabs request payload: %w", err)
+	}
+
+	ttsURL := strings.TrimRight(p.BaseURL, "/") + "/text-to-speech/" + url.PathEscape(voiceID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ttsURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ElevenLabs request: %w", err)
+	}
+	req.Header.Set("xi-api-key", p.APIKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call ElevenLabs: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newAPIError(p.Name(), resp.StatusCode, resp.Status, resp.Body)
+	}
+	contentType := strings.TrimSpace(resp.Header.Get("Content-Type"))
+	if contentType == "" {
+		contentType = "audio/mpeg"
+	}
+	return &Audio{ContentType: contentType, Body: resp.Body}, nil
+}
+
+var _ Provider = (*ElevenLabs)(nil)
@@ -0,0 +1,128 @@
+package speech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	ProviderOpenAI = "openai"
+
+	openAIDefaultBaseURL = "https://api.openai.com/v1"
+	openAIDefaultModel   = "gpt-4o-mini-tts"
+	openAIDefaultVoice   = "alloy"
+)
+
+// openAIVoices is the built-in voice set; the API has no voices endpoint.
+var openAIVoices = []string{"alloy", "ash", "ballad", "coral", "echo", "fable", "nova", "onyx", "sage", "shimmer"}
+
+// OpenAI implements Provider against an OpenAI-compatible /audio/speech endpoint.
+type OpenAI struct {
+	APIKey         string
+	BaseURL        string
+	DefaultVoiceID string
+	DefaultModelID string
+	DefaultSpeed   float64
+	Client         *http.Client
+}
+
+// NewOpenAI creates an OpenAI-compatible TTS provider. Empty arguments use the
+// OpenAI defaults.
+func NewOpenAI(apiKey string, baseURL string, defaultVoiceID string, defaultModelID string) *OpenAI {
+	baseURL = strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if baseURL == "" {
+		baseURL = openAIDefaultBaseURL
+	}
+	defaultVoiceID = strings.TrimSpace(defaultVoiceID)
+	if defaultVoiceID == "" {
+		defaultVoiceID = openAIDefaultVoice
+	}
+	defaultModelID = strings.TrimSpace(defaultModelID)
+	if defaultModelID == "" {
+		defaultModelID = openAIDefaultModel
+	}
+	return &OpenAI{
+		APIKey:         strings.TrimSpace(apiKey),
+		BaseURL:        baseURL,
+		DefaultVoiceID: defaultVoiceID,
+		DefaultModelID: defaultModelID,
+		Client:         &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (p *OpenAI) Name() string {
+	return ProviderOpenAI
+}
+
+type openAISpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	ResponseFormat string  `json:"response_format"`
+	Speed          float64 `json:"speed,omitempty"`
+}
+
+func (p *OpenAI) ListVoices(ctx context.Context) ([]Voice, error) {
+	voices := make([]Voice, 0, len(openAIVoices))
+	for _, id := range openAIVoices {
+		voices = append(voices, Voice{VoiceID: id, Name: id, Provider: p.Name()})
+	}
+	return voices, nil
+}
+
+func (p *OpenAI) Synthesize(ctx context.Context, r Request) (*Audio, error) {
+	voiceID := strings.TrimSpace(r.VoiceID)
+	if voiceID == "" {
+		voiceID = p.DefaultVoiceID
+	}
+	modelID := strings.TrimSpace(r.ModelID)
+	if modelID == "" {
+		modelID = p.DefaultModelID
+	}
+	speed := r.Speed
+	if speed <= 0 {
+		speed = p.DefaultSpeed
+	}
+
+	jsonBody, err := json.Marshal(openAISpeechRequest{
+		Model:          modelID,
+		Input:          r.Text,
+		Voice:          voiceID,
+		ResponseFormat: "mp3",
+		Speed:          speed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAI speech payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.BaseURL+"/audio/speech", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAI speech request: %w", err)
+	}
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/mpeg")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call OpenAI speech endpoint: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		return nil, newAPIError(p.Name(), resp.StatusCode, resp.Status, resp.Body)
+	}
+	contentType := strings.TrimSpace(resp.Header.Get("Content-Type"))
+	if contentType == "" {
+		contentType = "audio/mpeg"
+	}
+	return &Audio{ContentType: contentType, Body: resp.Body}, nil
+}
+
+var _ Provider = (*OpenAI)(nil)
@@ -0,0 +1,66 @@
+// Package speech provides text-to-speech provider implementations behind a
+// common interface so HTTP handlers can pick a backend per request.
+package speech
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Voice describes a voice offered by a provider.
+type Voice struct {
+	VoiceID    string `json:"voice_id"`
+	Name       string `json:"name"`
+	PreviewURL string `json:"preview_url,omitempty"`
+	Provider   string `json:"provider"`
+}
+
+// Request is a single synthesis request. Empty fields fall back to the
+// provider's configured defaults.
+type Request struct {
+	Text    string
+	VoiceID string
+	ModelID string
+	Speed   float64
+}
+
+// Audio is a synthesized clip. Callers must close Body.
+type Audio struct {
+	ContentType string
+	Body        io.ReadCloser
+}
+
+// Provider synthesizes speech and lists available voices.
+type Provider interface {
+	Name() string
+	ListVoices(ctx context.Context) ([]Voice, error)
+	Synthesize(ctx context.Context, req Request) (*Audio, error)
+}
+
+// APIError carries a non-2xx upstream response so callers can proxy the status.
+type APIError struct {
+	Provider   string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	detail := strings.TrimSpace(e.Body)
+	if detail == "" {
+		detail = e.Status
+	}
+	return fmt.Sprintf("%s returned %d: %s", e.Provider, e.StatusCode, detail)
+}
+
+func newAPIError(provider string, statusCode int, status string, body io.Reader) *APIError {
+	raw, _ := io.ReadAll(io.LimitReader(body, 8192))
+	return &APIError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Status:     status,
+		Body:       strings.TrimSpace(string(raw)),
+	}
+}
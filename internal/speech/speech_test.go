@@ -0,0 +1,84 @@
+package speech
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOpenAISynthesizeUsesOverrides(t *testing.T) {
+	var got openAISpeechRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/audio/speech" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "Bearer key" {
+			t.Errorf("missing bearer token")
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.Header().Set("Content-Type", "audio/mpeg")
+		_, _ = w.Write([]byte("mp3-bytes"))
+	}))
+	defer srv.Close()
+
+	p := NewOpenAI("key", srv.URL+"/v1/", "", "")
+	audio, err := p.Synthesize(context.Background(), Request{Text: "hi", VoiceID: "nova", Speed: 1.25})
+	if err != nil {
+		t.Fatalf("Synthesize: %v", err)
+	}
+	defer audio.Body.Close()
+	body, _ := io.ReadAll(audio.Body)
+	if string(body) != "mp3-bytes" || audio.ContentType != "audio/mpeg" {
+		t.Fatalf("unexpected audio: %q %q", body, audio.ContentType)
+	}
+	if got.Voice != "nova" || got.Model != openAIDefaultModel || got.Speed != 1.25 || got.Input != "hi" {
+		t.Fatalf("unexpected request: %+v", got)
+	}
+}
+
+func TestElevenLabsSynthesizeRequiresVoice(t *testing.T) {
+	p := NewElevenLabs("key", "", 0)
+	if _, err := p.Synthesize(context.Background(), Request{Text: "hi"}); err == nil {
+		t.Fatal("expected error without voice id")
+	}
+}
+
+func TestElevenLabsPropagatesAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/text-to-speech/voice-1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"detail":"bad key"}`))
+	}))
+	defer srv.Close()
+
+	p := NewElevenLabs("key", "voice-1", 0)
+	p.BaseURL = srv.URL
+	_, err := p.Synthesize(context.Background(), Request{Text: "hi"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected APIError 401, got %v", err)
+	}
+}
+
+func TestElevenLabsListVoicesTagsProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"voices":[{"voice_id":"v1","name":"Rachel"}]}`))
+	}))
+	defer srv.Close()
+
+	p := NewElevenLabs("key", "", 0)
+	p.BaseURL = srv.URL
+	voices, err := p.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices: %v", err)
+	}
+	if len(voices) != 1 || voices[0].Provider != ProviderElevenLabs || voices[0].VoiceID != "v1" {
+		t.Fatalf("unexpected voices: %+v", voices)
+	}
+}
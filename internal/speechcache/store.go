@@ -1,36 +1,96 @@
 package speechcache
 
 import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/A2gent/brute/internal/logging"
 	"github.com/google/uuid"
 )
 
-const DefaultTTL = 15 * time.Minute
+const (
+	DefaultTTL = 15 * time.Minute
+	// DefaultSpillThreshold is the clip size above which payloads are kept on disk.
+	DefaultSpillThreshold = 256 * 1024
+	// DefaultCleanupInterval is how often the background evictor runs.
+	DefaultCleanupInterval = time.Minute
+)
 
 type clip struct {
 	contentType string
 	data        []byte
+	path        string
 	createdAt   time.Time
 }
 
-// Store keeps short-lived generated speech clips in memory for web playback.
+// Store keeps short-lived generated speech clips for web playback. Small clips
+// stay in memory; clips larger than the spill threshold are written to a temp
+// directory and streamed back from disk.
 type Store struct {
 	mu    sync.Mutex
 	ttl   time.Duration
 	clips map[string]clip
+
+	spillThreshold  int
+	spillDir        string
+	ownsSpillDir    bool
+	cleanupInterval time.Duration
+
+	stopOnce sync.Once
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// Option configures a Store.
+type Option func(*Store)
+
+// WithSpillThreshold sets the size in bytes above which clips go to disk.
+// A negative value keeps every clip in memory.
+func WithSpillThreshold(bytes int) Option {
+	return func(s *Store) {
+		s.spillThreshold = bytes
+	}
+}
+
+// WithSpillDir sets the directory used for spilled clips. By default a private
+// temp directory is created on first spill and removed on Stop.
+func WithSpillDir(dir string) Option {
+	return func(s *Store) {
+		s.spillDir = strings.TrimSpace(dir)
+	}
+}
+
+// WithCleanupInterval sets how often expired clips are evicted in the background.
+func WithCleanupInterval(interval time.Duration) Option {
+	return func(s *Store) {
+		if interval > 0 {
+			s.cleanupInterval = interval
+		}
+	}
 }
 
-func New(ttl time.Duration) *Store {
+func New(ttl time.Duration, opts ...Option) *Store {
 	if ttl <= 0 {
 		ttl = DefaultTTL
 	}
-	return &Store{
-		ttl:   ttl,
-		clips: make(map[string]clip, 32),
+	s := &Store{
+		ttl:             ttl,
+		clips:           make(map[string]clip, 32),
+		spillThreshold:  DefaultSpillThreshold,
+		cleanupInterval: DefaultCleanupInterval,
+		stopChan:        make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(s)
 	}
+	go s.evictLoop()
+	return s
 }
 
 func (s *Store) Save(contentType string, data []byte) string {
@@ -42,8 +102,6 @@ func (s *Store) Save(contentType string, data []byte) string {
 	if ct == "" {
 		ct = "audio/mpeg"
 	}
-	payload := make([]byte, len(data))
-	copy(payload, data)
 
 	id := uuid.New().String()
 	now := time.Now()
@@ -51,15 +109,45 @@ func (s *Store) Save(contentType string, data []byte) string {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.cleanupExpiredLocked(now)
-	s.clips[id] = clip{
+
+	item := clip{
 		contentType: ct,
-		data:        payload,
 		createdAt:   now,
 	}
+	if s.spillThreshold >= 0 && len(data) > s.spillThreshold {
+		path, err := s.spillLocked(id, data)
+		if err == nil {
+			item.path = path
+		} else {
+			logging.Warn("Failed to spill speech clip to disk, keeping in memory: %v", err)
+		}
+	}
+	if item.path == "" {
+		item.data = make([]byte, len(data))
+		copy(item.data, data)
+	}
+	s.clips[id] = item
 	return id
 }
 
 func (s *Store) Load(id string) (string, []byte, bool) {
+	contentType, reader, ok := s.Open(id)
+	if !ok {
+		return "", nil, false
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		logging.Warn("Failed to read speech clip %s: %v", id, err)
+		return "", nil, false
+	}
+	return contentType, payload, true
+}
+
+// Open returns a reader for the clip, streaming from disk for spilled clips.
+// Callers must close the reader.
+func (s *Store) Open(id string) (string, io.ReadCloser, bool) {
 	if s == nil {
 		return "", nil, false
 	}
@@ -74,16 +162,93 @@ func (s *Store) Load(id string) (string, []byte, bool) {
 		return "", nil, false
 	}
 
+	if item.path != "" {
+		f, err := os.Open(item.path)
+		if err != nil {
+			logging.Warn("Failed to open spilled speech clip %s: %v", id, err)
+			delete(s.clips, strings.TrimSpace(id))
+			return "", nil, false
+		}
+		return item.contentType, f, true
+	}
+
 	payload := make([]byte, len(item.data))
 	copy(payload, item.data)
-	return item.contentType, payload, true
+	return item.contentType, io.NopCloser(bytes.NewReader(payload)), true
+}
+
+// Stop ends background eviction and removes every spilled clip from disk.
+func (s *Store) Stop() {
+	if s == nil {
+		return
+	}
+	s.stopOnce.Do(func() {
+		close(s.stopChan)
+		<-s.done
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for id, item := range s.clips {
+			removeClipFile(item)
+			delete(s.clips, id)
+		}
+		if s.ownsSpillDir && s.spillDir != "" {
+			_ = os.RemoveAll(s.spillDir)
+		}
+	})
+}
+
+func (s *Store) evictLoop() {
+	defer close(s.done)
+	ticker := time.NewTicker(s.cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopChan:
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			s.cleanupExpiredLocked(now)
+			s.mu.Unlock()
+		}
+	}
+}
+
+func (s *Store) spillLocked(id string, data []byte) (string, error) {
+	if s.spillDir == "" {
+		dir, err := os.MkdirTemp("", "aagent-speech-*")
+		if err != nil {
+			return "", err
+		}
+		s.spillDir = dir
+		s.ownsSpillDir = true
+	} else if err := os.MkdirAll(s.spillDir, 0o700); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(s.spillDir, id+".clip")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, nil
 }
 
 func (s *Store) cleanupExpiredLocked(now time.Time) {
 	cutoff := now.Add(-s.ttl)
 	for id, item := range s.clips {
 		if item.createdAt.Before(cutoff) {
+			removeClipFile(item)
 			delete(s.clips, id)
 		}
 	}
 }
+
+func removeClipFile(item clip) {
+	if item.path == "" {
+		return
+	}
+	if err := os.Remove(item.path); err != nil && !os.IsNotExist(err) {
+		logging.Warn("Failed to remove spilled speech clip %s: %v", item.path, err)
+	}
+}
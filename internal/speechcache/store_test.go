@@ -0,0 +1,93 @@
+package speechcache
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSmallClipsStayInMemory(t *testing.T) {
+	s := New(time.Minute, WithSpillThreshold(16), WithSpillDir(t.TempDir()))
+	defer s.Stop()
+
+	id := s.Save("", []byte("tiny"))
+	if s.clips[id].path != "" {
+		t.Fatalf("expected in-memory clip, got path %q", s.clips[id].path)
+	}
+	ct, data, ok := s.Load(id)
+	if !ok || ct != "audio/mpeg" || string(data) != "tiny" {
+		t.Fatalf("unexpected load result: %q %q %v", ct, data, ok)
+	}
+}
+
+func TestLargeClipsSpillToDisk(t *testing.T) {
+	s := New(time.Minute, WithSpillThreshold(16))
+	payload := bytes.Repeat([]byte("x"), 64)
+
+	id := s.Save("audio/wav", payload)
+	path := s.clips[id].path
+	if path == "" {
+		t.Fatal("expected clip to be spilled to disk")
+	}
+	if s.clips[id].data != nil {
+		t.Fatal("spilled clip should not keep payload in memory")
+	}
+
+	ct, reader, ok := s.Open(id)
+	if !ok || ct != "audio/wav" {
+		t.Fatalf("unexpected open result: %q %v", ct, ok)
+	}
+	got, _ := io.ReadAll(reader)
+	reader.Close()
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("unexpected payload length %d", len(got))
+	}
+
+	dir := s.spillDir
+	s.Stop()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected spilled file removed on Stop, got %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected owned spill dir removed on Stop, got %v", err)
+	}
+	if _, _, ok := s.Load(id); ok {
+		t.Fatal("expected clip to be gone after Stop")
+	}
+}
+
+func TestExpiredDiskClipsAreEvictedInBackground(t *testing.T) {
+	s := New(20*time.Millisecond, WithSpillThreshold(0), WithSpillDir(t.TempDir()), WithCleanupInterval(10*time.Millisecond))
+	defer s.Stop()
+
+	id := s.Save("audio/mpeg", []byte("payload"))
+	s.mu.Lock()
+	path := s.clips[id].path
+	s.mu.Unlock()
+	if path == "" {
+		t.Fatal("expected clip to be spilled to disk")
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		_, present := s.clips[id]
+		s.mu.Unlock()
+		if !present {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.mu.Lock()
+	_, present := s.clips[id]
+	s.mu.Unlock()
+	if present {
+		t.Fatal("expected expired clip to be evicted without Save/Load")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("expected expired clip file removed, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	"github.com/A2gent/brute/internal/logging"
+)
+
+// AttachmentDir returns the folder holding the uploaded files of a session.
+func AttachmentDir(dataPath, sessionID string) string {
+	return filepath.Join(dataPath, "attachments", sessionID)
+}
+
+func scanAttachment(row rowScanner) (*Attachment, error) {
+	var att Attachment
+	if err := row.Scan(&att.ID, &att.SessionID, &att.Name, &att.MediaType, &att.Size, &att.Path, &att.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &att, nil
+}
+
+func scanAttachments(rows *sql.Rows) ([]*Attachment, error) {
+	defer rows.Close()
+	var attachments []*Attachment
+	for rows.Next() {
+		att, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, att)
+	}
+	return attachments, rows.Err()
+}
+
+// removeAttachmentFiles deletes the files of attachments whose rows are gone,
+// along with folders they leave empty. Failures are logged, not returned: the
+// session is already deleted by then.
+func removeAttachmentFiles(attachments []*Attachment) {
+	dirs := make(map[string]bool)
+	for _, att := range attachments {
+		if err := os.Remove(att.Path); err != nil && !os.IsNotExist(err) {
+			logging.Warn("Failed to remove attachment file %s: %v", att.Path, err)
+		}
+		dirs[filepath.Dir(att.Path)] = true
+	}
+	for dir := range dirs {
+		// Only succeeds once the folder is empty.
+		_ = os.Remove(dir)
+	}
+}
@@ -0,0 +1,34 @@
+package storage
+
+import "testing"
+
+// storeBackend opens stores on a fresh, empty database. Every call of the
+// returned opener connects to that same database, so tests can simulate
+// several processes sharing it.
+type storeBackend struct {
+	name  string
+	newDB func(t *testing.T) func(t *testing.T) Store
+}
+
+// storeBackends lists the backends shared store tests run against. SQLite is
+// always available; postgres_test.go adds Postgres under the postgres build tag.
+var storeBackends = []storeBackend{
+	{
+		name: DriverSQLite,
+		newDB: func(t *testing.T) func(t *testing.T) Store {
+			dir := t.TempDir()
+			return func(t *testing.T) Store { return newTestSQLiteStoreAt(t, dir) }
+		},
+	},
+}
+
+// forEachBackend runs fn once per backend as a subtest.
+func forEachBackend(t *testing.T, fn func(t *testing.T, open func(t *testing.T) Store)) {
+	t.Helper()
+	for _, backend := range storeBackends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			fn(t, backend.newDB(t))
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	"github.com/A2gent/brute/internal/logging"
+)
+
+// ExecutionOutputPath returns the file holding the full output of a job
+// execution, whose record keeps only a summary.
+func ExecutionOutputPath(dataPath, execID string) string {
+	return filepath.Join(dataPath, "executions", execID+".md")
+}
+
+func scanExecutionOutputPaths(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+	var paths []string
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, rows.Err()
+}
+
+// removeExecutionOutputs deletes the output files of executions whose rows
+// are gone. Failures are logged, not returned, like removeAttachmentFiles.
+func removeExecutionOutputs(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logging.Warn("Failed to remove execution output %s: %v", path, err)
+		}
+	}
+}
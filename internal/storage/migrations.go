@@ -0,0 +1,531 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// schemaMigration is one ordered step of a database schema. Each step runs in
+// its own transaction together with the schema_version row that records it.
+type schemaMigration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// schemaMigrations lists every SQLite schema change in application order.
+// Append new steps with the next version number; never edit or reorder
+// applied ones.
+//
+// Databases created before schema_version existed have no version recorded, so
+// every step is applied to them as well. The steps are therefore written to be
+// safe on a partially migrated schema: tables use IF NOT EXISTS and columns are
+// added through addColumnIfMissing.
+var schemaMigrations = []schemaMigration{
+	{
+		version:     1,
+		description: "sessions and messages",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS sessions (
+				id TEXT PRIMARY KEY,
+				agent_id TEXT NOT NULL,
+				parent_id TEXT,
+				project_id TEXT,
+				title TEXT DEFAULT '',
+				status TEXT NOT NULL,
+				metadata TEXT,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS messages (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				role TEXT NOT NULL,
+				content TEXT,
+				tool_calls TEXT,
+				tool_results TEXT,
+				metadata TEXT,
+				timestamp TIMESTAMP NOT NULL,
+				FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_messages_session_id ON messages(session_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_parent_id ON sessions(parent_id)`,
+		),
+	},
+	{
+		version:     2,
+		description: "session title, session project and message metadata",
+		up: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing(tx, "sessions", "title", "TEXT DEFAULT ''"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "sessions", "project_id", "TEXT"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "messages", "metadata", "TEXT"); err != nil {
+				return err
+			}
+			return execStatements(`CREATE INDEX IF NOT EXISTS idx_sessions_project_id ON sessions(project_id)`)(tx)
+		},
+	},
+	{
+		version:     3,
+		description: "recurring jobs",
+		up: func(tx *sql.Tx) error {
+			if err := execStatements(`CREATE TABLE IF NOT EXISTS recurring_jobs (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				schedule_human TEXT NOT NULL,
+				schedule_cron TEXT NOT NULL,
+				task_prompt TEXT NOT NULL,
+				task_prompt_source TEXT NOT NULL DEFAULT 'text',
+				task_prompt_file TEXT NOT NULL DEFAULT '',
+				llm_provider TEXT,
+				enabled INTEGER NOT NULL DEFAULT 1,
+				last_run_at TIMESTAMP,
+				next_run_at TIMESTAMP,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`)(tx); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "recurring_jobs", "task_prompt_source", "TEXT NOT NULL DEFAULT 'text'"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "recurring_jobs", "task_prompt_file", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "recurring_jobs", "llm_provider", "TEXT"); err != nil {
+				return err
+			}
+			return execStatements(
+				`CREATE INDEX IF NOT EXISTS idx_recurring_jobs_next_run ON recurring_jobs(next_run_at)`,
+				`CREATE INDEX IF NOT EXISTS idx_recurring_jobs_enabled ON recurring_jobs(enabled)`,
+			)(tx)
+		},
+	},
+	{
+		version:     4,
+		description: "job executions and session job link",
+		up: func(tx *sql.Tx) error {
+			if err := execStatements(
+				`CREATE TABLE IF NOT EXISTS job_executions (
+					id TEXT PRIMARY KEY,
+					job_id TEXT NOT NULL,
+					session_id TEXT,
+					status TEXT NOT NULL,
+					output TEXT,
+					error TEXT,
+					started_at TIMESTAMP NOT NULL,
+					finished_at TIMESTAMP,
+					FOREIGN KEY (job_id) REFERENCES recurring_jobs(id) ON DELETE CASCADE
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_job_executions_job_id ON job_executions(job_id)`,
+				`CREATE INDEX IF NOT EXISTS idx_job_executions_started_at ON job_executions(started_at)`,
+			)(tx); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "sessions", "job_id", "TEXT"); err != nil {
+				return err
+			}
+			return execStatements(`CREATE INDEX IF NOT EXISTS idx_sessions_job_id ON sessions(job_id)`)(tx)
+		},
+	},
+	{
+		version:     5,
+		description: "app settings and channel integrations",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS app_settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS integrations (
+				id TEXT PRIMARY KEY,
+				provider TEXT NOT NULL,
+				name TEXT NOT NULL,
+				mode TEXT NOT NULL,
+				enabled INTEGER NOT NULL DEFAULT 1,
+				config TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_integrations_provider ON integrations(provider)`,
+		),
+	},
+	{
+		version:     6,
+		description: "MCP server registry",
+		up: func(tx *sql.Tx) error {
+			if err := execStatements(`CREATE TABLE IF NOT EXISTS mcp_servers (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				transport TEXT NOT NULL,
+				enabled INTEGER NOT NULL DEFAULT 1,
+				config TEXT NOT NULL,
+				last_test_at TIMESTAMP,
+				last_test_success INTEGER,
+				last_test_message TEXT,
+				last_estimated_tokens INTEGER,
+				last_tool_count INTEGER,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`)(tx); err != nil {
+				return err
+			}
+			for _, column := range []struct{ name, definition string }{
+				{"last_test_at", "TIMESTAMP"},
+				{"last_test_success", "INTEGER"},
+				{"last_test_message", "TEXT"},
+				{"last_estimated_tokens", "INTEGER"},
+				{"last_tool_count", "INTEGER"},
+			} {
+				if err := addColumnIfMissing(tx, "mcp_servers", column.name, column.definition); err != nil {
+					return err
+				}
+			}
+			return execStatements(`CREATE INDEX IF NOT EXISTS idx_mcp_servers_transport ON mcp_servers(transport)`)(tx)
+		},
+	},
+	{
+		version:     7,
+		description: "projects",
+		up: func(tx *sql.Tx) error {
+			if err := execStatements(
+				`CREATE TABLE IF NOT EXISTS projects (
+					id TEXT PRIMARY KEY,
+					name TEXT NOT NULL,
+					folders TEXT NOT NULL DEFAULT '[]',
+					created_at TIMESTAMP NOT NULL,
+					updated_at TIMESTAMP NOT NULL
+				)`,
+				`CREATE INDEX IF NOT EXISTS idx_projects_name ON projects(name)`,
+			)(tx); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "projects", "is_system", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+			// folders was replaced by a single nullable folder.
+			return addColumnIfMissing(tx, "projects", "folder", "TEXT")
+		},
+	},
+	{
+		version:     8,
+		description: "session task progress",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "sessions", "task_progress", "TEXT")
+		},
+	},
+	{
+		version:     9,
+		description: "sub-agents",
+		up: func(tx *sql.Tx) error {
+			if err := execStatements(`CREATE TABLE IF NOT EXISTS sub_agents (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				provider TEXT NOT NULL DEFAULT '',
+				model TEXT NOT NULL DEFAULT '',
+				enabled_tools TEXT NOT NULL DEFAULT '[]',
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL
+			)`)(tx); err != nil {
+				return err
+			}
+			return addColumnIfMissing(tx, "sub_agents", "instruction_blocks", "TEXT NOT NULL DEFAULT '[]'")
+		},
+	},
+	{
+		version:     10,
+		description: "session version for optimistic concurrency",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "sessions", "version", "INTEGER NOT NULL DEFAULT 1")
+		},
+	},
+	{
+		version:     11,
+		description: "full outputs of truncated tool results",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS tool_outputs (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				tool_call_id TEXT NOT NULL DEFAULT '',
+				tool_name TEXT NOT NULL DEFAULT '',
+				content TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_tool_outputs_session_id ON tool_outputs(session_id)`,
+		),
+	},
+	{
+		version:     12,
+		description: "agent memories",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS memories (
+				scope TEXT NOT NULL,
+				key TEXT NOT NULL,
+				value TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				updated_at TIMESTAMP NOT NULL,
+				PRIMARY KEY (scope, key)
+			)`,
+		),
+	},
+	{
+		version:     13,
+		description: "recurring job timezone",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "recurring_jobs", "timezone", "TEXT NOT NULL DEFAULT ''")
+		},
+	},
+	{
+		version:     14,
+		description: "per-job timeout, model and agent type",
+		up: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing(tx, "recurring_jobs", "timeout_minutes", "INTEGER NOT NULL DEFAULT 0"); err != nil {
+				return err
+			}
+			if err := addColumnIfMissing(tx, "recurring_jobs", "model", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+			return addColumnIfMissing(tx, "recurring_jobs", "agent_id", "TEXT NOT NULL DEFAULT ''")
+		},
+	},
+	{
+		version:     15,
+		description: "one-shot jobs",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "recurring_jobs", "run_at", "TIMESTAMP")
+		},
+	},
+	{
+		version:     16,
+		description: "job completion notifications",
+		up: func(tx *sql.Tx) error {
+			if err := addColumnIfMissing(tx, "recurring_jobs", "notify_on", "TEXT NOT NULL DEFAULT ''"); err != nil {
+				return err
+			}
+			return addColumnIfMissing(tx, "recurring_jobs", "notify_integration_ids", "TEXT NOT NULL DEFAULT ''")
+		},
+	},
+	{
+		version:     17,
+		description: "webhook delivery log",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id TEXT PRIMARY KEY,
+				integration_id TEXT NOT NULL,
+				event TEXT NOT NULL,
+				status TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				response_code INTEGER NOT NULL DEFAULT 0,
+				error TEXT NOT NULL DEFAULT '',
+				payload TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMP NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_integration ON webhook_deliveries(integration_id, created_at)`,
+		),
+	},
+	{
+		version:     18,
+		description: "job response schema",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "recurring_jobs", "response_schema", "TEXT NOT NULL DEFAULT ''")
+		},
+	},
+	{
+		version:     19,
+		description: "session attachments",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS attachments (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL,
+				name TEXT NOT NULL DEFAULT '',
+				media_type TEXT NOT NULL DEFAULT '',
+				size INTEGER NOT NULL DEFAULT 0,
+				path TEXT NOT NULL,
+				created_at TIMESTAMP NOT NULL,
+				FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_attachments_session_id ON attachments(session_id)`,
+		),
+	},
+	{
+		version:     20,
+		description: "job execution output files",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "job_executions", "output_path", "TEXT NOT NULL DEFAULT ''")
+		},
+	},
+	{
+		version:     21,
+		description: "job execution retention",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "recurring_jobs", "keep_executions", "INTEGER NOT NULL DEFAULT 0")
+		},
+	},
+	{
+		version:     22,
+		description: "jobs failing on questions",
+		up: func(tx *sql.Tx) error {
+			return addColumnIfMissing(tx, "recurring_jobs", "fail_on_question", "INTEGER NOT NULL DEFAULT 0")
+		},
+	},
+}
+
+// migrationBackend describes how a database records and serialises migrations.
+type migrationBackend struct {
+	steps []schemaMigration
+	// bind rewrites a query written with ? placeholders for the driver.
+	bind func(query string) string
+	// lock runs first in every migration transaction; backends shared by
+	// several processes use it so each step is applied exactly once.
+	lock func(tx *sql.Tx) error
+}
+
+var sqliteMigrationBackend = migrationBackend{
+	steps: schemaMigrations,
+	bind:  func(query string) string { return query },
+}
+
+// latestSchemaVersion is the version a fully migrated database reports.
+func (b migrationBackend) latestSchemaVersion() int {
+	return b.steps[len(b.steps)-1].version
+}
+
+// applyMigrations brings the database up to the backend's latest version,
+// applying each pending step and its schema_version row in one transaction.
+func applyMigrations(db *sql.DB, backend migrationBackend) error {
+	if err := backend.inTx(db, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			description TEXT NOT NULL DEFAULT '',
+			applied_at TIMESTAMP NOT NULL
+		)`)
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := schemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > backend.latestSchemaVersion() {
+		return fmt.Errorf("database schema version %d is newer than this build supports (%d)", current, backend.latestSchemaVersion())
+	}
+
+	for _, m := range backend.steps {
+		if m.version <= current {
+			continue
+		}
+		err := backend.inTx(db, func(tx *sql.Tx) error {
+			// Another process may have applied this step while we waited on the lock.
+			var applied int
+			if err := tx.QueryRow(backend.bind(`SELECT COUNT(*) FROM schema_version WHERE version = ?`), m.version).Scan(&applied); err != nil {
+				return err
+			}
+			if applied > 0 {
+				return nil
+			}
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(
+				backend.bind(`INSERT INTO schema_version (version, description, applied_at) VALUES (?, ?, ?)`),
+				m.version, m.description, time.Now(),
+			); err != nil {
+				return fmt.Errorf("failed to record version: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.description, err)
+		}
+	}
+	return nil
+}
+
+func (b migrationBackend) inTx(db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if b.lock != nil {
+		if err := b.lock(tx); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// schemaVersion returns the highest applied migration, or 0 for a database
+// that predates versioned migrations.
+func schemaVersion(db *sql.DB) (int, error) {
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
+
+func execStatements(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumnIfMissing adds a column to a SQLite table unless the table already
+// has it, which is the case for databases whose tables were created with the
+// column inline.
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	exists, err := columnExists(tx, table, column)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s: %w", table, column, err)
+	}
+	return nil
+}
+
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	rows, err := tx.Query(fmt.Sprintf(`PRAGMA table_info(%s)`, table))
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cid        int
+			name       string
+			colType    string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return false, err
+		}
+		if strings.EqualFold(name, column) {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
+}
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func assertColumns(t *testing.T, db *sql.DB, table string, columns ...string) {
+	t.Helper()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin: %v", err)
+	}
+	defer tx.Rollback()
+	for _, column := range columns {
+		exists, err := columnExists(tx, table, column)
+		if err != nil {
+			t.Fatalf("columnExists(%s.%s): %v", table, column, err)
+		}
+		if !exists {
+			t.Fatalf("expected column %s.%s to exist", table, column)
+		}
+	}
+}
+
+func assertSchemaVersion(t *testing.T, db *sql.DB) {
+	t.Helper()
+	version, err := schemaVersion(db)
+	if err != nil {
+		t.Fatalf("schemaVersion: %v", err)
+	}
+	if version != sqliteMigrationBackend.latestSchemaVersion() {
+		t.Fatalf("expected schema version %d, got %d", sqliteMigrationBackend.latestSchemaVersion(), version)
+	}
+	var applied int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&applied); err != nil {
+		t.Fatalf("count schema_version: %v", err)
+	}
+	if applied != len(schemaMigrations) {
+		t.Fatalf("expected %d recorded migrations, got %d", len(schemaMigrations), applied)
+	}
+}
+
+func TestMigrationsOnEmptyDatabase(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	assertSchemaVersion(t, store.db)
+	assertColumns(t, store.db, "sessions", "title", "project_id", "job_id", "task_progress", "version")
+	assertColumns(t, store.db, "sub_agents", "instruction_blocks")
+	store.Close()
+
+	// Reopening must not re-run or re-record anything.
+	store, err = NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("reopen NewSQLiteStore: %v", err)
+	}
+	defer store.Close()
+	assertSchemaVersion(t, store.db)
+}
+
+func TestMigrationsUpgradeLegacyDatabase(t *testing.T) {
+	dir := t.TempDir()
+	db, err := openSQLiteConnection(filepath.Join(dir, "aagent.db"))
+	if err != nil {
+		t.Fatalf("openSQLiteConnection: %v", err)
+	}
+	// Schema as left behind by the unversioned migrate() of an older release:
+	// no schema_version table and several columns not yet added.
+	legacy := []string{
+		`CREATE TABLE sessions (
+			id TEXT PRIMARY KEY,
+			agent_id TEXT NOT NULL,
+			parent_id TEXT,
+			status TEXT NOT NULL,
+			metadata TEXT,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE messages (
+			id TEXT PRIMARY KEY,
+			session_id TEXT NOT NULL,
+			role TEXT NOT NULL,
+			content TEXT,
+			tool_calls TEXT,
+			tool_results TEXT,
+			timestamp TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE recurring_jobs (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			schedule_human TEXT NOT NULL,
+			schedule_cron TEXT NOT NULL,
+			task_prompt TEXT NOT NULL,
+			enabled INTEGER NOT NULL DEFAULT 1,
+			last_run_at TIMESTAMP,
+			next_run_at TIMESTAMP,
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE projects (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL,
+			folders TEXT NOT NULL DEFAULT '[]',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+	}
+	for _, stmt := range legacy {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("legacy schema: %v", err)
+		}
+	}
+	now := time.Now()
+	if _, err := db.Exec(`INSERT INTO sessions (id, agent_id, status, metadata, created_at, updated_at) VALUES ('legacy', 'build', 'completed', '{}', ?, ?)`, now, now); err != nil {
+		t.Fatalf("insert legacy session: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO messages (id, session_id, role, content, timestamp) VALUES ('m1', 'legacy', 'user', 'hello', ?)`, now); err != nil {
+		t.Fatalf("insert legacy message: %v", err)
+	}
+	db.Close()
+
+	store, err := NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore on legacy database: %v", err)
+	}
+	defer store.Close()
+
+	assertSchemaVersion(t, store.db)
+	assertColumns(t, store.db, "sessions", "title", "project_id", "job_id", "task_progress", "version")
+	assertColumns(t, store.db, "messages", "metadata")
+	assertColumns(t, store.db, "recurring_jobs", "task_prompt_source", "task_prompt_file", "llm_provider", "timezone", "timeout_minutes", "model", "agent_id", "run_at", "notify_on", "notify_integration_ids")
+	assertColumns(t, store.db, "projects", "is_system", "folder")
+
+	sess, err := store.GetSession("legacy")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if len(sess.Messages) != 1 || sess.Messages[0].Content != "hello" {
+		t.Fatalf("legacy messages not preserved: %+v", sess.Messages)
+	}
+	if sess.Version != 1 {
+		t.Fatalf("expected legacy session to get default version 1, got %d", sess.Version)
+	}
+}
@@ -0,0 +1,1684 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+const (
+	// postgresMaxOpenConns bounds the pool per replica.
+	postgresMaxOpenConns = 10
+	// postgresMaxTxAttempts is how often a serializable transaction is retried
+	// after a serialization failure or deadlock.
+	postgresMaxTxAttempts = 5
+	// postgresMigrationLockID keys the advisory lock that serialises schema
+	// migrations across replicas.
+	postgresMigrationLockID = 0x6161676e74 // "aagnt"
+)
+
+// PostgresStore implements Store on PostgreSQL so several aagent replicas can
+// share one database.
+type PostgresStore struct {
+	db       *sql.DB
+	secrets  *SecretBox // nil stores integration configs in plaintext
+	dataPath string
+}
+
+// postgresMigrations lists every PostgreSQL schema change in application
+// order. Append new steps with the next version number.
+var postgresMigrations = []schemaMigration{
+	{
+		version:     1,
+		description: "initial schema",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS sessions (
+				id TEXT PRIMARY KEY,
+				agent_id TEXT NOT NULL,
+				parent_id TEXT,
+				job_id TEXT,
+				project_id TEXT,
+				title TEXT NOT NULL DEFAULT '',
+				status TEXT NOT NULL,
+				metadata TEXT,
+				task_progress TEXT,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL,
+				version BIGINT NOT NULL DEFAULT 1
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_parent_id ON sessions(parent_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_project_id ON sessions(project_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_job_id ON sessions(job_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_sessions_created_at ON sessions(created_at)`,
+			`CREATE TABLE IF NOT EXISTS messages (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				role TEXT NOT NULL,
+				content TEXT,
+				tool_calls TEXT,
+				tool_results TEXT,
+				metadata TEXT,
+				timestamp TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_messages_session_id ON messages(session_id)`,
+			`CREATE TABLE IF NOT EXISTS recurring_jobs (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				schedule_human TEXT NOT NULL,
+				schedule_cron TEXT NOT NULL,
+				task_prompt TEXT NOT NULL,
+				task_prompt_source TEXT NOT NULL DEFAULT 'text',
+				task_prompt_file TEXT NOT NULL DEFAULT '',
+				llm_provider TEXT NOT NULL DEFAULT '',
+				enabled BOOLEAN NOT NULL DEFAULT TRUE,
+				last_run_at TIMESTAMPTZ,
+				next_run_at TIMESTAMPTZ,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_recurring_jobs_next_run ON recurring_jobs(next_run_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_recurring_jobs_enabled ON recurring_jobs(enabled)`,
+			`CREATE TABLE IF NOT EXISTS job_executions (
+				id TEXT PRIMARY KEY,
+				job_id TEXT NOT NULL REFERENCES recurring_jobs(id) ON DELETE CASCADE,
+				session_id TEXT,
+				status TEXT NOT NULL,
+				output TEXT,
+				error TEXT,
+				started_at TIMESTAMPTZ NOT NULL,
+				finished_at TIMESTAMPTZ
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_job_executions_job_id ON job_executions(job_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_job_executions_session_id ON job_executions(session_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_job_executions_started_at ON job_executions(started_at)`,
+			`CREATE TABLE IF NOT EXISTS app_settings (
+				key TEXT PRIMARY KEY,
+				value TEXT NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE TABLE IF NOT EXISTS integrations (
+				id TEXT PRIMARY KEY,
+				provider TEXT NOT NULL,
+				name TEXT NOT NULL,
+				mode TEXT NOT NULL,
+				enabled BOOLEAN NOT NULL DEFAULT TRUE,
+				config TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_integrations_provider ON integrations(provider)`,
+			`CREATE TABLE IF NOT EXISTS mcp_servers (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				transport TEXT NOT NULL,
+				enabled BOOLEAN NOT NULL DEFAULT TRUE,
+				config TEXT NOT NULL,
+				last_test_at TIMESTAMPTZ,
+				last_test_success BOOLEAN,
+				last_test_message TEXT,
+				last_estimated_tokens INTEGER,
+				last_tool_count INTEGER,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_mcp_servers_transport ON mcp_servers(transport)`,
+			`CREATE TABLE IF NOT EXISTS projects (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				folder TEXT,
+				is_system BOOLEAN NOT NULL DEFAULT FALSE,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_projects_name ON projects(name)`,
+			`CREATE TABLE IF NOT EXISTS sub_agents (
+				id TEXT PRIMARY KEY,
+				name TEXT NOT NULL,
+				provider TEXT NOT NULL DEFAULT '',
+				model TEXT NOT NULL DEFAULT '',
+				enabled_tools TEXT NOT NULL DEFAULT '[]',
+				instruction_blocks TEXT NOT NULL DEFAULT '[]',
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL
+			)`,
+		),
+	},
+	{
+		version:     2,
+		description: "full outputs of truncated tool results",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS tool_outputs (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				tool_call_id TEXT NOT NULL DEFAULT '',
+				tool_name TEXT NOT NULL DEFAULT '',
+				content TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_tool_outputs_session_id ON tool_outputs(session_id)`,
+		),
+	},
+	{
+		version:     3,
+		description: "agent memories",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS memories (
+				scope TEXT NOT NULL,
+				key TEXT NOT NULL,
+				value TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL,
+				updated_at TIMESTAMPTZ NOT NULL,
+				PRIMARY KEY (scope, key)
+			)`,
+		),
+	},
+	{
+		version:     4,
+		description: "recurring job timezone",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		version:     5,
+		description: "per-job timeout, model and agent type",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS timeout_minutes INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS model TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS agent_id TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		version:     6,
+		description: "one-shot jobs",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS run_at TIMESTAMPTZ`,
+		),
+	},
+	{
+		version:     7,
+		description: "job completion notifications",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS notify_on TEXT NOT NULL DEFAULT ''`,
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS notify_integration_ids TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		version:     8,
+		description: "webhook delivery log",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS webhook_deliveries (
+				id TEXT PRIMARY KEY,
+				integration_id TEXT NOT NULL,
+				event TEXT NOT NULL,
+				status TEXT NOT NULL,
+				attempts INTEGER NOT NULL DEFAULT 0,
+				response_code INTEGER NOT NULL DEFAULT 0,
+				error TEXT NOT NULL DEFAULT '',
+				payload TEXT NOT NULL DEFAULT '',
+				created_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_integration ON webhook_deliveries(integration_id, created_at)`,
+		),
+	},
+	{
+		version:     9,
+		description: "job response schema",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS response_schema TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		version:     10,
+		description: "session attachments",
+		up: execStatements(
+			`CREATE TABLE IF NOT EXISTS attachments (
+				id TEXT PRIMARY KEY,
+				session_id TEXT NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+				name TEXT NOT NULL DEFAULT '',
+				media_type TEXT NOT NULL DEFAULT '',
+				size BIGINT NOT NULL DEFAULT 0,
+				path TEXT NOT NULL,
+				created_at TIMESTAMPTZ NOT NULL
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_attachments_session_id ON attachments(session_id)`,
+		),
+	},
+	{
+		version:     11,
+		description: "job execution output files",
+		up: execStatements(
+			`ALTER TABLE job_executions ADD COLUMN IF NOT EXISTS output_path TEXT NOT NULL DEFAULT ''`,
+		),
+	},
+	{
+		version:     12,
+		description: "job execution retention",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS keep_executions INTEGER NOT NULL DEFAULT 0`,
+		),
+	},
+	{
+		version:     13,
+		description: "jobs failing on questions",
+		up: execStatements(
+			`ALTER TABLE recurring_jobs ADD COLUMN IF NOT EXISTS fail_on_question BOOLEAN NOT NULL DEFAULT FALSE`,
+		),
+	},
+}
+
+var postgresMigrationBackend = migrationBackend{
+	steps: postgresMigrations,
+	bind:  rebindPostgres,
+	lock: func(tx *sql.Tx) error {
+		_, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, int64(postgresMigrationLockID))
+		return err
+	},
+}
+
+// NewPostgresStore connects to PostgreSQL using a pgx DSN (URL or key=value
+// form) and applies pending migrations. dataPath is still used for the Soul
+// project folder, which lives on local disk.
+func NewPostgresStore(dsn, dataPath string) (*PostgresStore, error) {
+	if strings.TrimSpace(dsn) == "" {
+		return nil, fmt.Errorf("postgres storage requires a DSN")
+	}
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(postgresMaxOpenConns)
+	db.SetMaxIdleConns(postgresMaxOpenConns)
+	db.SetConnMaxLifetime(30 * time.Minute)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	store := &PostgresStore{db: db, secrets: secretBoxFromEnv(), dataPath: dataPath}
+	if err := applyMigrations(db, postgresMigrationBackend); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	if _, err := sealPlaintextIntegrations(db, store.secrets, rebindPostgres); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to encrypt integration secrets: %w", err)
+	}
+	if err := store.seedSystemProjects(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to seed system projects: %w", err)
+	}
+	return store, nil
+}
+
+// rebindPostgres rewrites ? placeholders as $1, $2, ... so queries can be
+// written the same way as for SQLite.
+func rebindPostgres(query string) string {
+	var b strings.Builder
+	b.Grow(len(query) + 16)
+	n := 0
+	inString := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inString = !inString
+		case r == '?' && !inString:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func (s *PostgresStore) exec(query string, args ...interface{}) (sql.Result, error) {
+	return s.db.Exec(rebindPostgres(query), args...)
+}
+
+func (s *PostgresStore) query(query string, args ...interface{}) (*sql.Rows, error) {
+	return s.db.Query(rebindPostgres(query), args...)
+}
+
+func (s *PostgresStore) queryRow(query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRow(rebindPostgres(query), args...)
+}
+
+// serializable runs fn in a SERIALIZABLE transaction, retrying when Postgres
+// aborts it because of a concurrent writer.
+func (s *PostgresStore) serializable(fn func(tx *sql.Tx) error) error {
+	var err error
+	for attempt := 0; attempt < postgresMaxTxAttempts; attempt++ {
+		if attempt > 0 {
+			time.Sleep(time.Duration(attempt*attempt) * 10 * time.Millisecond)
+		}
+		err = s.runTx(&sql.TxOptions{Isolation: sql.LevelSerializable}, fn)
+		if !isPostgresRetryable(err) {
+			return err
+		}
+	}
+	return err
+}
+
+func (s *PostgresStore) runTx(opts *sql.TxOptions, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(context.Background(), opts)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isPostgresRetryable reports serialization failures and deadlocks.
+func isPostgresRetryable(err error) bool {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return false
+	}
+	return pgErr.Code == "40001" || pgErr.Code == "40P01"
+}
+
+// nullableJSON stores empty raw JSON as NULL, matching the SQLite store.
+func nullableJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return string(raw)
+}
+
+func (s *PostgresStore) seedSystemProjects() error {
+	now := time.Now()
+	for _, p := range systemProjectSeeds(s.dataPath) {
+		if _, err := s.exec(`
+			INSERT INTO projects (id, name, folder, is_system, created_at, updated_at)
+			VALUES (?, ?, NULL, TRUE, ?, ?)
+			ON CONFLICT (id) DO NOTHING
+		`, p.id, p.name, now, now); err != nil {
+			return fmt.Errorf("failed to seed system project %s: %w", p.id, err)
+		}
+		if _, err := s.exec(`
+			UPDATE projects
+			SET name = ?, is_system = TRUE, folder = COALESCE(?, folder), updated_at = ?
+			WHERE id = ?
+		`, p.name, p.folder, now, p.id); err != nil {
+			return fmt.Errorf("failed to update system project %s metadata: %w", p.id, err)
+		}
+		if p.id == SystemProjectSoulID {
+			if err := ensureSoulProjectDefaults(s.dataPath); err != nil {
+				return fmt.Errorf("failed to apply soul project defaults: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+// --- Sessions ---
+
+// SaveSession saves a session and its messages. Like the SQLite store it only
+// overwrites a session still at sess.Version, returning ErrStaleSession
+// otherwise.
+func (s *PostgresStore) SaveSession(sess *Session) error {
+	metadata, _ := json.Marshal(sess.Metadata)
+	var newVersion int64
+	err := s.serializable(func(tx *sql.Tx) error {
+		newVersion = sess.Version + 1
+		upsert := sess.Version == 0
+		if !upsert {
+			res, err := tx.Exec(rebindPostgres(`
+				UPDATE sessions SET
+					parent_id = ?,
+					job_id = ?,
+					project_id = ?,
+					title = ?,
+					status = ?,
+					metadata = ?,
+					task_progress = ?,
+					updated_at = ?,
+					version = ?
+				WHERE id = ? AND version = ?
+			`), sess.ParentID, sess.JobID, sess.ProjectID, sess.Title, sess.Status, string(metadata), sess.TaskProgress, sess.UpdatedAt, newVersion, sess.ID, sess.Version)
+			if err != nil {
+				return fmt.Errorf("failed to save session: %w", err)
+			}
+			if affected, _ := res.RowsAffected(); affected == 0 {
+				var exists int
+				if err := tx.QueryRow(`SELECT COUNT(*) FROM sessions WHERE id = $1`, sess.ID).Scan(&exists); err != nil {
+					return fmt.Errorf("failed to check session version: %w", err)
+				}
+				if exists > 0 {
+					return ErrStaleSession
+				}
+				upsert = true
+			}
+		}
+		if upsert {
+			err := tx.QueryRow(rebindPostgres(`
+				INSERT INTO sessions (id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at, version)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET
+					parent_id = excluded.parent_id,
+					job_id = excluded.job_id,
+					project_id = excluded.project_id,
+					title = excluded.title,
+					status = excluded.status,
+					metadata = excluded.metadata,
+					task_progress = excluded.task_progress,
+					updated_at = excluded.updated_at,
+					version = sessions.version + 1
+				RETURNING version
+			`), sess.ID, sess.AgentID, sess.ParentID, sess.JobID, sess.ProjectID, sess.Title, sess.Status, string(metadata), sess.TaskProgress, sess.CreatedAt, sess.UpdatedAt, newVersion).Scan(&newVersion)
+			if err != nil {
+				return fmt.Errorf("failed to save session: %w", err)
+			}
+		}
+
+		if _, err := tx.Exec(`DELETE FROM messages WHERE session_id = $1`, sess.ID); err != nil {
+			return fmt.Errorf("failed to delete messages: %w", err)
+		}
+		if len(sess.Messages) == 0 {
+			return nil
+		}
+		insert, err := tx.Prepare(`
+			INSERT INTO messages (id, session_id, role, content, tool_calls, tool_results, metadata, timestamp)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to prepare message insert: %w", err)
+		}
+		defer insert.Close()
+		for _, msg := range sess.Messages {
+			messageMetadata, _ := json.Marshal(msg.Metadata)
+			if _, err := insert.Exec(msg.ID, sess.ID, msg.Role, msg.Content, nullableJSON(msg.ToolCalls), nullableJSON(msg.ToolResults), string(messageMetadata), msg.Timestamp); err != nil {
+				return fmt.Errorf("failed to save message: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sess.Version = newVersion
+	return nil
+}
+
+const postgresSessionColumns = `id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at, version`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanPostgresSession(row rowScanner) (*Session, error) {
+	var sess Session
+	var parentID, jobID, projectID, metadata, taskProgress sql.NullString
+	if err := row.Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &sess.Title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt, &sess.Version); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		sess.ParentID = &parentID.String
+	}
+	if jobID.Valid {
+		sess.JobID = &jobID.String
+	}
+	if projectID.Valid {
+		sess.ProjectID = &projectID.String
+	}
+	if metadata.Valid && metadata.String != "" {
+		_ = json.Unmarshal([]byte(metadata.String), &sess.Metadata)
+	}
+	if taskProgress.Valid {
+		sess.TaskProgress = taskProgress.String
+	}
+	return &sess, nil
+}
+
+func (s *PostgresStore) scanSessions(rows *sql.Rows) ([]*Session, error) {
+	defer rows.Close()
+	var sessions []*Session
+	for rows.Next() {
+		sess, err := scanPostgresSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// GetSession retrieves a session with its messages.
+func (s *PostgresStore) GetSession(id string) (*Session, error) {
+	sess, err := scanPostgresSession(s.queryRow(`SELECT `+postgresSessionColumns+` FROM sessions WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("session not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.query(`
+		SELECT id, role, content, tool_calls, tool_results, metadata, timestamp
+		FROM messages WHERE session_id = ? ORDER BY timestamp
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var msg Message
+		var content, toolCalls, toolResults, metadata sql.NullString
+		if err := rows.Scan(&msg.ID, &msg.Role, &content, &toolCalls, &toolResults, &metadata, &msg.Timestamp); err != nil {
+			return nil, err
+		}
+		msg.Content = content.String
+		if toolCalls.Valid {
+			msg.ToolCalls = json.RawMessage(toolCalls.String)
+		}
+		if toolResults.Valid {
+			msg.ToolResults = json.RawMessage(toolResults.String)
+		}
+		if metadata.Valid && metadata.String != "" {
+			_ = json.Unmarshal([]byte(metadata.String), &msg.Metadata)
+		}
+		sess.Messages = append(sess.Messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// ListSessions lists all regular sessions plus Thinking job sessions.
+func (s *PostgresStore) ListSessions() ([]*Session, error) {
+	sessions, _, err := s.ListSessionsPage(SessionFilter{Limit: -1})
+	return sessions, err
+}
+
+// ListSessionsPage returns one page of ListSessions matching the filter,
+// newest first, together with the total number of matching sessions.
+func (s *PostgresStore) ListSessionsPage(filter SessionFilter) ([]*Session, int, error) {
+	where := []string{"(job_id IS NULL OR project_id = 'project-thinking')"}
+	var args []interface{}
+	if status := strings.TrimSpace(filter.Status); status != "" {
+		where = append(where, "status = ?")
+		args = append(args, status)
+	}
+	if agentID := strings.TrimSpace(filter.AgentID); agentID != "" {
+		where = append(where, "agent_id = ?")
+		args = append(args, agentID)
+	}
+	for _, agentID := range filter.ExcludeAgentIDs {
+		if agentID = strings.TrimSpace(agentID); agentID != "" {
+			where = append(where, "agent_id <> ?")
+			args = append(args, agentID)
+		}
+	}
+	if filter.CreatedAfter != nil {
+		where = append(where, "created_at > ?")
+		args = append(args, *filter.CreatedAfter)
+	}
+	for _, key := range filter.MetadataFlags {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		where = append(where, "(metadata::jsonb ->> CAST(? AS TEXT)) = 'true'")
+		args = append(args, key)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.queryRow("SELECT COUNT(*) FROM sessions WHERE "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultSessionPageSize
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	orderBy := "created_at"
+	if filter.OrderByUpdated {
+		orderBy = "updated_at"
+	}
+	query := `SELECT ` + postgresSessionColumns + ` FROM sessions WHERE ` + whereClause + ` ORDER BY ` + orderBy + ` DESC`
+	pageArgs := append([]interface{}{}, args...)
+	if limit > 0 {
+		query += ` LIMIT ?`
+		pageArgs = append(pageArgs, limit)
+	}
+	query += ` OFFSET ?`
+	pageArgs = append(pageArgs, offset)
+
+	rows, err := s.query(query, pageArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+	sessions, err := s.scanSessions(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return sessions, total, nil
+}
+
+// ListSessionsByJob returns all sessions associated with a specific job.
+func (s *PostgresStore) ListSessionsByJob(jobID string) ([]*Session, error) {
+	rows, err := s.query(`SELECT `+postgresSessionColumns+` FROM sessions WHERE job_id = ? ORDER BY created_at DESC`, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return s.scanSessions(rows)
+}
+
+// DeleteSession deletes a session; its messages are removed by cascade.
+func (s *PostgresStore) DeleteSession(id string) error {
+	attachments, err := s.ListAttachments(id)
+	if err != nil {
+		return err
+	}
+	if _, err := s.exec(`DELETE FROM sessions WHERE id = ?`, id); err != nil {
+		return err
+	}
+	removeAttachmentFiles(attachments)
+	removeUndoBackups(s.dataPath, id)
+	return nil
+}
+
+// PurgeSessions deletes stale sessions in a single transaction. Job sessions
+// are ranked per job so the newest opts.KeepLastPerJob always survive.
+func (s *PostgresStore) PurgeSessions(olderThan time.Time, excludeStatuses []string, opts PurgeOptions) ([]*Session, error) {
+	where := []string{"updated_at < ?"}
+	args := []interface{}{olderThan}
+	if !opts.IncludeNonJob {
+		where = append(where, "job_id IS NOT NULL")
+	}
+	if len(excludeStatuses) > 0 {
+		placeholders := make([]string, len(excludeStatuses))
+		for i, status := range excludeStatuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		where = append(where, "status NOT IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if opts.KeepLastPerJob > 0 {
+		where = append(where, "(job_id IS NULL OR job_rank > ?)")
+		args = append(args, opts.KeepLastPerJob)
+	}
+
+	rows, err := s.query(`
+		SELECT id, agent_id, job_id, title, status, created_at, updated_at
+		FROM (
+			SELECT id, agent_id, job_id, title, status, created_at, updated_at,
+				ROW_NUMBER() OVER (PARTITION BY job_id ORDER BY created_at DESC) AS job_rank
+			FROM sessions
+		) ranked
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	var purged []*Session
+	for rows.Next() {
+		var sess Session
+		var jobID sql.NullString
+		if err := rows.Scan(&sess.ID, &sess.AgentID, &jobID, &sess.Title, &sess.Status, &sess.CreatedAt, &sess.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if jobID.Valid {
+			sess.JobID = &jobID.String
+		}
+		purged = append(purged, &sess)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(purged) == 0 {
+		return purged, nil
+	}
+
+	ids := make([]string, len(purged))
+	var attachments []*Attachment
+	for i, sess := range purged {
+		ids[i] = sess.ID
+		sessionAttachments, err := s.ListAttachments(sess.ID)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, sessionAttachments...)
+	}
+	outputs, err := s.executionOutputPaths(ids)
+	if err != nil {
+		return nil, err
+	}
+	err = s.runTx(nil, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM job_executions WHERE session_id = ANY($1)`, ids); err != nil {
+			return fmt.Errorf("failed to delete executions: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ANY($1)`, ids); err != nil {
+			return fmt.Errorf("failed to delete sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	removeAttachmentFiles(attachments)
+	removeExecutionOutputs(outputs)
+	for _, sess := range purged {
+		removeUndoBackups(s.dataPath, sess.ID)
+	}
+	return purged, nil
+}
+
+// executionOutputPaths returns the output files of the executions of the
+// given sessions.
+func (s *PostgresStore) executionOutputPaths(sessionIDs []string) ([]string, error) {
+	rows, err := s.query(`SELECT output_path FROM job_executions WHERE session_id = ANY(?) AND output_path <> ''`, sessionIDs)
+	if err != nil {
+		return nil, err
+	}
+	return scanExecutionOutputPaths(rows)
+}
+
+// --- Projects ---
+
+// SaveProject creates or updates a project.
+func (s *PostgresStore) SaveProject(project *Project) error {
+	_, err := s.exec(`
+		INSERT INTO projects (id, name, folder, is_system, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			folder = excluded.folder,
+			is_system = excluded.is_system,
+			updated_at = excluded.updated_at
+	`, project.ID, project.Name, project.Folder, project.IsSystem, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save project: %w", err)
+	}
+	return nil
+}
+
+func scanPostgresProject(row rowScanner) (*Project, error) {
+	var project Project
+	var folder sql.NullString
+	if err := row.Scan(&project.ID, &project.Name, &folder, &project.IsSystem, &project.CreatedAt, &project.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if folder.Valid {
+		project.Folder = &folder.String
+	}
+	return &project, nil
+}
+
+// GetProject retrieves a project by ID.
+func (s *PostgresStore) GetProject(id string) (*Project, error) {
+	project, err := scanPostgresProject(s.queryRow(`
+		SELECT id, name, folder, is_system, created_at, updated_at
+		FROM projects WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("project not found: %s", id)
+	}
+	return project, err
+}
+
+// ListProjects returns all projects ordered by name.
+func (s *PostgresStore) ListProjects() ([]*Project, error) {
+	rows, err := s.query(`
+		SELECT id, name, folder, is_system, created_at, updated_at
+		FROM projects
+		ORDER BY LOWER(name) ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []*Project
+	for rows.Next() {
+		project, err := scanPostgresProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, project)
+	}
+	return projects, rows.Err()
+}
+
+// DeleteProject deletes a project and all associated sessions and their messages.
+// System projects cannot be deleted.
+func (s *PostgresStore) DeleteProject(id string) error {
+	project, err := s.GetProject(id)
+	if err != nil {
+		return err
+	}
+	if project.IsSystem {
+		return fmt.Errorf("cannot delete system project: %s", id)
+	}
+	return s.runTx(nil, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM sessions WHERE project_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete project sessions: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM projects WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete project: %w", err)
+		}
+		return nil
+	})
+}
+
+// Close closes the connection pool.
+// CheckWritable writes a settings row and rolls it back.
+func (s *PostgresStore) CheckWritable() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("INSERT INTO app_settings (key, value, updated_at) VALUES ('__health_check', '', $1) ON CONFLICT (key) DO NOTHING", time.Now())
+	return err
+}
+
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// --- Recurring Jobs ---
+
+// SaveJob creates or updates a recurring job.
+func (s *PostgresStore) SaveJob(job *RecurringJob) error {
+	err := s.serializable(func(tx *sql.Tx) error {
+		_, err := tx.Exec(rebindPostgres(`
+			INSERT INTO recurring_jobs (id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, timezone, timeout_minutes, keep_executions, fail_on_question, model, agent_id, run_at, notify_on, notify_integration_ids, response_schema, enabled, last_run_at, next_run_at, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO UPDATE SET
+				name = excluded.name,
+				schedule_human = excluded.schedule_human,
+				schedule_cron = excluded.schedule_cron,
+				task_prompt = excluded.task_prompt,
+				task_prompt_source = excluded.task_prompt_source,
+				task_prompt_file = excluded.task_prompt_file,
+				llm_provider = excluded.llm_provider,
+				timezone = excluded.timezone,
+				timeout_minutes = excluded.timeout_minutes,
+				keep_executions = excluded.keep_executions,
+				fail_on_question = excluded.fail_on_question,
+				model = excluded.model,
+				agent_id = excluded.agent_id,
+				run_at = excluded.run_at,
+				notify_on = excluded.notify_on,
+				notify_integration_ids = excluded.notify_integration_ids,
+				response_schema = excluded.response_schema,
+				enabled = excluded.enabled,
+				last_run_at = excluded.last_run_at,
+				next_run_at = excluded.next_run_at,
+				updated_at = excluded.updated_at
+		`), job.ID, job.Name, job.ScheduleHuman, job.ScheduleCron, job.TaskPrompt, job.TaskPromptSource, job.TaskPromptFile, job.LLMProvider, job.Timezone, job.TimeoutMinutes, job.KeepExecutions, job.FailOnQuestion, job.Model, job.AgentID, job.RunAt, job.NotifyOn, joinJobIDs(job.NotifyTargets), job.ResponseSchema, job.Enabled, job.LastRunAt, job.NextRunAt, job.CreatedAt, job.UpdatedAt)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save job: %w", err)
+	}
+	return nil
+}
+
+const postgresJobColumns = `id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, timezone, timeout_minutes, keep_executions, fail_on_question, model, agent_id, run_at, notify_on, notify_integration_ids, response_schema, enabled, last_run_at, next_run_at, created_at, updated_at`
+
+func scanPostgresJob(row rowScanner) (*RecurringJob, error) {
+	var job RecurringJob
+	var runAt, lastRunAt, nextRunAt sql.NullTime
+	var notifyTargets string
+	if err := row.Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &job.Timezone, &job.TimeoutMinutes, &job.KeepExecutions, &job.FailOnQuestion, &job.Model, &job.AgentID, &runAt, &job.NotifyOn, &notifyTargets, &job.ResponseSchema, &job.Enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return nil, err
+	}
+	job.NotifyTargets = splitJobIDs(notifyTargets)
+	if runAt.Valid {
+		job.RunAt = &runAt.Time
+	}
+	if lastRunAt.Valid {
+		job.LastRunAt = &lastRunAt.Time
+	}
+	if nextRunAt.Valid {
+		job.NextRunAt = &nextRunAt.Time
+	}
+	return &job, nil
+}
+
+func (s *PostgresStore) listJobs(query string, args ...interface{}) ([]*RecurringJob, error) {
+	rows, err := s.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*RecurringJob
+	for rows.Next() {
+		job, err := scanPostgresJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// GetJob retrieves a recurring job by ID.
+func (s *PostgresStore) GetJob(id string) (*RecurringJob, error) {
+	job, err := scanPostgresJob(s.queryRow(`SELECT `+postgresJobColumns+` FROM recurring_jobs WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job not found: %s", id)
+	}
+	return job, err
+}
+
+// ListJobs lists all recurring jobs.
+func (s *PostgresStore) ListJobs() ([]*RecurringJob, error) {
+	return s.listJobs(`SELECT ` + postgresJobColumns + ` FROM recurring_jobs ORDER BY created_at DESC`)
+}
+
+// DeleteJob deletes a recurring job and, by cascade, its executions.
+func (s *PostgresStore) DeleteJob(id string) error {
+	_, err := s.exec(`DELETE FROM recurring_jobs WHERE id = ?`, id)
+	return err
+}
+
+// GetDueJobs returns jobs that are due to run (next_run_at <= now and enabled).
+// One-shot jobs carry their run_at in next_run_at until they have run.
+func (s *PostgresStore) GetDueJobs(now time.Time) ([]*RecurringJob, error) {
+	return s.listJobs(`
+		SELECT `+postgresJobColumns+`
+		FROM recurring_jobs
+		WHERE enabled AND next_run_at IS NOT NULL AND next_run_at <= ?
+		ORDER BY next_run_at ASC
+	`, now)
+}
+
+// --- Job Executions ---
+
+// SaveJobExecution creates or updates a job execution.
+func (s *PostgresStore) SaveJobExecution(exec *JobExecution) error {
+	var sessionID interface{}
+	if exec.SessionID != "" {
+		sessionID = exec.SessionID
+	}
+	_, err := s.exec(`
+		INSERT INTO job_executions (id, job_id, session_id, status, output, output_path, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			session_id = excluded.session_id,
+			status = excluded.status,
+			output = excluded.output,
+			output_path = excluded.output_path,
+			error = excluded.error,
+			finished_at = excluded.finished_at
+	`, exec.ID, exec.JobID, sessionID, exec.Status, exec.Output, exec.OutputPath, exec.Error, exec.StartedAt, exec.FinishedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save job execution: %w", err)
+	}
+	return nil
+}
+
+func scanPostgresExecution(row rowScanner) (*JobExecution, error) {
+	var exec JobExecution
+	var sessionID, output, execError sql.NullString
+	var finishedAt sql.NullTime
+	if err := row.Scan(&exec.ID, &exec.JobID, &sessionID, &exec.Status, &output, &exec.OutputPath, &execError, &exec.StartedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	exec.SessionID = sessionID.String
+	exec.Output = output.String
+	exec.Error = execError.String
+	if finishedAt.Valid {
+		exec.FinishedAt = &finishedAt.Time
+	}
+	return &exec, nil
+}
+
+// GetJobExecution retrieves a job execution by ID.
+func (s *PostgresStore) GetJobExecution(id string) (*JobExecution, error) {
+	exec, err := scanPostgresExecution(s.queryRow(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM job_executions WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("job execution not found: %s", id)
+	}
+	return exec, err
+}
+
+// ListJobExecutions lists executions for a job, most recent first. A
+// non-positive limit returns every execution.
+func (s *PostgresStore) ListJobExecutions(jobID string, limit int) ([]*JobExecution, error) {
+	query := `
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM job_executions
+		WHERE job_id = ?
+		ORDER BY started_at DESC`
+	args := []interface{}{jobID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	return s.listJobExecutions(query, args...)
+}
+
+// ListRunningJobExecutions returns executions still marked running that
+// started before startedBefore.
+func (s *PostgresStore) ListRunningJobExecutions(startedBefore time.Time) ([]*JobExecution, error) {
+	return s.listJobExecutions(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM job_executions
+		WHERE status = 'running' AND started_at < ?
+		ORDER BY started_at ASC`, startedBefore)
+}
+
+// ListWaitingJobExecutions returns the executions waiting for an answer,
+// newest first.
+func (s *PostgresStore) ListWaitingJobExecutions() ([]*JobExecution, error) {
+	return s.listJobExecutions(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM job_executions
+		WHERE status = 'waiting_input'
+		ORDER BY started_at DESC`)
+}
+
+// PruneJobExecutions deletes the executions of a job beyond its newest
+// keepLast that started before olderThan, never running or waiting_input
+// ones. Sessions created for those runs of the job are deleted with them.
+func (s *PostgresStore) PruneJobExecutions(jobID string, keepLast int, olderThan time.Time) ([]*JobExecution, error) {
+	pruned, err := s.listJobExecutions(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (ORDER BY started_at DESC) AS exec_rank
+			FROM job_executions
+			WHERE job_id = ?
+		) ranked
+		WHERE exec_rank > ? AND status NOT IN ('running', 'waiting_input') AND started_at < ?
+		ORDER BY started_at ASC`, jobID, keepLast, olderThan)
+	if err != nil || len(pruned) == 0 {
+		return nil, err
+	}
+
+	execIDs := make([]string, len(pruned))
+	var candidates, outputs []string
+	for i, exec := range pruned {
+		execIDs[i] = exec.ID
+		if exec.OutputPath != "" {
+			outputs = append(outputs, exec.OutputPath)
+		}
+		if exec.SessionID != "" {
+			candidates = append(candidates, exec.SessionID)
+		}
+	}
+	rows, err := s.query(`SELECT id FROM sessions WHERE id = ANY(?) AND job_id = ?`, candidates, jobID)
+	if err != nil {
+		return nil, err
+	}
+	var sessionIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		sessionIDs = append(sessionIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	var attachments []*Attachment
+	for _, id := range sessionIDs {
+		sessionAttachments, err := s.ListAttachments(id)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, sessionAttachments...)
+	}
+
+	err = s.runTx(nil, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM job_executions WHERE id = ANY($1)`, execIDs); err != nil {
+			return fmt.Errorf("failed to delete executions: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM sessions WHERE id = ANY($1)`, sessionIDs); err != nil {
+			return fmt.Errorf("failed to delete sessions: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	removeAttachmentFiles(attachments)
+	removeExecutionOutputs(outputs)
+	removeUndoBackups(s.dataPath, sessionIDs...)
+	return pruned, nil
+}
+
+func (s *PostgresStore) listJobExecutions(query string, args ...interface{}) ([]*JobExecution, error) {
+	rows, err := s.query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var executions []*JobExecution
+	for rows.Next() {
+		exec, err := scanPostgresExecution(rows)
+		if err != nil {
+			return nil, err
+		}
+		executions = append(executions, exec)
+	}
+	return executions, rows.Err()
+}
+
+// --- Settings ---
+
+// GetSettings returns all app settings as key/value pairs.
+func (s *PostgresStore) GetSettings() (map[string]string, error) {
+	rows, err := s.query(`SELECT key, value FROM app_settings`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	settings := make(map[string]string)
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		settings[key] = value
+	}
+	return settings, rows.Err()
+}
+
+// SaveSettings replaces all app settings with the provided map.
+func (s *PostgresStore) SaveSettings(settings map[string]string) error {
+	return s.serializable(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM app_settings`); err != nil {
+			return fmt.Errorf("failed to clear settings: %w", err)
+		}
+		now := time.Now()
+		for key, value := range settings {
+			if key == "" {
+				continue
+			}
+			if _, err := tx.Exec(`INSERT INTO app_settings (key, value, updated_at) VALUES ($1, $2, $3)`, key, value, now); err != nil {
+				return fmt.Errorf("failed to save setting %q: %w", key, err)
+			}
+		}
+		return nil
+	})
+}
+
+// --- Integrations ---
+
+// SaveIntegration creates or updates an integration.
+func (s *PostgresStore) SaveIntegration(integration *Integration) error {
+	if integration.Config == nil {
+		integration.Config = map[string]string{}
+	}
+	configJSON, err := encodeIntegrationConfig(s.secrets, integration.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode integration config: %w", err)
+	}
+	_, err = s.exec(`
+		INSERT INTO integrations (id, provider, name, mode, enabled, config, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			provider = excluded.provider,
+			name = excluded.name,
+			mode = excluded.mode,
+			enabled = excluded.enabled,
+			config = excluded.config,
+			updated_at = excluded.updated_at
+	`, integration.ID, integration.Provider, integration.Name, integration.Mode, integration.Enabled, configJSON, integration.CreatedAt, integration.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save integration: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) scanIntegration(row rowScanner) (*Integration, error) {
+	var integration Integration
+	var configJSON string
+	if err := row.Scan(&integration.ID, &integration.Provider, &integration.Name, &integration.Mode, &integration.Enabled, &configJSON, &integration.CreatedAt, &integration.UpdatedAt); err != nil {
+		return nil, err
+	}
+	config, err := decodeIntegrationConfig(s.secrets, configJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode integration config: %w", err)
+	}
+	integration.Config = config
+	return &integration, nil
+}
+
+// GetIntegration returns an integration by id.
+func (s *PostgresStore) GetIntegration(id string) (*Integration, error) {
+	integration, err := s.scanIntegration(s.queryRow(`
+		SELECT id, provider, name, mode, enabled, config, created_at, updated_at
+		FROM integrations WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("integration not found: %s", id)
+	}
+	return integration, err
+}
+
+// ListIntegrations returns all integrations ordered by creation date.
+func (s *PostgresStore) ListIntegrations() ([]*Integration, error) {
+	rows, err := s.query(`
+		SELECT id, provider, name, mode, enabled, config, created_at, updated_at
+		FROM integrations
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var integrations []*Integration
+	for rows.Next() {
+		integration, err := s.scanIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, integration)
+	}
+	return integrations, rows.Err()
+}
+
+// DeleteIntegration deletes an integration by id.
+func (s *PostgresStore) DeleteIntegration(id string) error {
+	if _, err := s.exec(`DELETE FROM integrations WHERE id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.exec(`DELETE FROM webhook_deliveries WHERE integration_id = ?`, id)
+	return err
+}
+
+// SaveWebhookDelivery records a delivery and prunes the integration's log to
+// its newest MaxWebhookDeliveries entries.
+func (s *PostgresStore) SaveWebhookDelivery(delivery *WebhookDelivery) error {
+	if _, err := s.exec(`
+		INSERT INTO webhook_deliveries (id, integration_id, event, status, attempts, response_code, error, payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, delivery.ID, delivery.IntegrationID, delivery.Event, delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.Error, delivery.Payload, delivery.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	_, err := s.exec(`
+		DELETE FROM webhook_deliveries WHERE integration_id = ? AND id NOT IN (
+			SELECT id FROM webhook_deliveries WHERE integration_id = ? ORDER BY created_at DESC LIMIT ?
+		)
+	`, delivery.IntegrationID, delivery.IntegrationID, MaxWebhookDeliveries)
+	return err
+}
+
+// ListWebhookDeliveries returns an integration's newest deliveries first.
+func (s *PostgresStore) ListWebhookDeliveries(integrationID string, limit int) ([]*WebhookDelivery, error) {
+	rows, err := s.query(`
+		SELECT id, integration_id, event, status, attempts, response_code, error, payload, created_at
+		FROM webhook_deliveries WHERE integration_id = ?
+		ORDER BY created_at DESC LIMIT ?
+	`, integrationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.IntegrationID, &d.Event, &d.Status, &d.Attempts, &d.ResponseCode, &d.Error, &d.Payload, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
+// --- MCP servers ---
+
+// SaveMCPServer creates or updates an MCP server.
+func (s *PostgresStore) SaveMCPServer(server *MCPServer) error {
+	if server.Config == nil {
+		server.Config = map[string]string{}
+	}
+	configJSON, err := json.Marshal(server.Config)
+	if err != nil {
+		return fmt.Errorf("failed to encode mcp server config: %w", err)
+	}
+	_, err = s.exec(`
+		INSERT INTO mcp_servers (id, name, transport, enabled, config, last_test_at, last_test_success, last_test_message, last_estimated_tokens, last_tool_count, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			transport = excluded.transport,
+			enabled = excluded.enabled,
+			config = excluded.config,
+			last_test_at = excluded.last_test_at,
+			last_test_success = excluded.last_test_success,
+			last_test_message = excluded.last_test_message,
+			last_estimated_tokens = excluded.last_estimated_tokens,
+			last_tool_count = excluded.last_tool_count,
+			updated_at = excluded.updated_at
+	`, server.ID, server.Name, server.Transport, server.Enabled, string(configJSON), server.LastTestAt, server.LastTestSuccess, server.LastTestMessage, server.LastEstimatedTokens, server.LastToolCount, server.CreatedAt, server.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save mcp server: %w", err)
+	}
+	return nil
+}
+
+const postgresMCPServerColumns = `id, name, transport, enabled, config, last_test_at, last_test_success, last_test_message, last_estimated_tokens, last_tool_count, created_at, updated_at`
+
+func scanPostgresMCPServer(row rowScanner) (*MCPServer, error) {
+	var server MCPServer
+	var configJSON string
+	var lastTestAt sql.NullTime
+	var lastTestSuccess sql.NullBool
+	var lastTestMessage sql.NullString
+	var lastEstimatedTokens, lastToolCount sql.NullInt64
+	if err := row.Scan(
+		&server.ID,
+		&server.Name,
+		&server.Transport,
+		&server.Enabled,
+		&configJSON,
+		&lastTestAt,
+		&lastTestSuccess,
+		&lastTestMessage,
+		&lastEstimatedTokens,
+		&lastToolCount,
+		&server.CreatedAt,
+		&server.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastTestAt.Valid {
+		server.LastTestAt = &lastTestAt.Time
+	}
+	if lastTestSuccess.Valid {
+		v := lastTestSuccess.Bool
+		server.LastTestSuccess = &v
+	}
+	server.LastTestMessage = lastTestMessage.String
+	if lastEstimatedTokens.Valid {
+		v := int(lastEstimatedTokens.Int64)
+		server.LastEstimatedTokens = &v
+	}
+	if lastToolCount.Valid {
+		v := int(lastToolCount.Int64)
+		server.LastToolCount = &v
+	}
+	if configJSON != "" {
+		if err := json.Unmarshal([]byte(configJSON), &server.Config); err != nil {
+			return nil, fmt.Errorf("failed to decode mcp server config: %w", err)
+		}
+	}
+	if server.Config == nil {
+		server.Config = map[string]string{}
+	}
+	return &server, nil
+}
+
+// GetMCPServer returns an MCP server by id.
+func (s *PostgresStore) GetMCPServer(id string) (*MCPServer, error) {
+	server, err := scanPostgresMCPServer(s.queryRow(`SELECT `+postgresMCPServerColumns+` FROM mcp_servers WHERE id = ?`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("mcp server not found: %s", id)
+	}
+	return server, err
+}
+
+// ListMCPServers returns all MCP servers ordered by creation date.
+func (s *PostgresStore) ListMCPServers() ([]*MCPServer, error) {
+	rows, err := s.query(`SELECT ` + postgresMCPServerColumns + ` FROM mcp_servers ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var servers []*MCPServer
+	for rows.Next() {
+		server, err := scanPostgresMCPServer(rows)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, server)
+	}
+	return servers, rows.Err()
+}
+
+// DeleteMCPServer deletes an MCP server by id.
+func (s *PostgresStore) DeleteMCPServer(id string) error {
+	_, err := s.exec(`DELETE FROM mcp_servers WHERE id = ?`, id)
+	return err
+}
+
+// --- Sub-agents ---
+
+// SaveSubAgent creates or updates a sub-agent.
+func (s *PostgresStore) SaveSubAgent(sa *SubAgent) error {
+	enabledToolsJSON, err := json.Marshal(sa.EnabledTools)
+	if err != nil {
+		return fmt.Errorf("failed to encode enabled tools: %w", err)
+	}
+	instrBlocks := sa.InstructionBlocks
+	if instrBlocks == "" {
+		instrBlocks = "[]"
+	}
+	_, err = s.exec(`
+		INSERT INTO sub_agents (id, name, provider, model, enabled_tools, instruction_blocks, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			provider = excluded.provider,
+			model = excluded.model,
+			enabled_tools = excluded.enabled_tools,
+			instruction_blocks = excluded.instruction_blocks,
+			updated_at = excluded.updated_at
+	`, sa.ID, sa.Name, sa.Provider, sa.Model, string(enabledToolsJSON), instrBlocks, sa.CreatedAt, sa.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save sub-agent: %w", err)
+	}
+	return nil
+}
+
+func scanPostgresSubAgent(row rowScanner) (*SubAgent, error) {
+	var sa SubAgent
+	var enabledToolsJSON string
+	if err := row.Scan(&sa.ID, &sa.Name, &sa.Provider, &sa.Model, &enabledToolsJSON, &sa.InstructionBlocks, &sa.CreatedAt, &sa.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if enabledToolsJSON != "" {
+		if err := json.Unmarshal([]byte(enabledToolsJSON), &sa.EnabledTools); err != nil {
+			return nil, fmt.Errorf("failed to decode enabled tools: %w", err)
+		}
+	}
+	if sa.EnabledTools == nil {
+		sa.EnabledTools = []string{}
+	}
+	return &sa, nil
+}
+
+// GetSubAgent retrieves a sub-agent by ID.
+func (s *PostgresStore) GetSubAgent(id string) (*SubAgent, error) {
+	sa, err := scanPostgresSubAgent(s.queryRow(`
+		SELECT id, name, provider, model, enabled_tools, instruction_blocks, created_at, updated_at
+		FROM sub_agents WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("sub-agent not found: %s", id)
+	}
+	return sa, err
+}
+
+// ListSubAgents returns all sub-agents ordered by name.
+func (s *PostgresStore) ListSubAgents() ([]*SubAgent, error) {
+	rows, err := s.query(`
+		SELECT id, name, provider, model, enabled_tools, instruction_blocks, created_at, updated_at
+		FROM sub_agents
+		ORDER BY LOWER(name) ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var agents []*SubAgent
+	for rows.Next() {
+		sa, err := scanPostgresSubAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		agents = append(agents, sa)
+	}
+	return agents, rows.Err()
+}
+
+// DeleteSubAgent deletes a sub-agent by ID.
+func (s *PostgresStore) DeleteSubAgent(id string) error {
+	_, err := s.exec(`DELETE FROM sub_agents WHERE id = ?`, id)
+	return err
+}
+
+// SaveToolOutput stores the full output of a truncated tool result.
+func (s *PostgresStore) SaveToolOutput(output *ToolOutput) error {
+	_, err := s.exec(`
+		INSERT INTO tool_outputs (id, session_id, tool_call_id, tool_name, content, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, output.ID, output.SessionID, output.ToolCallID, output.ToolName, output.Content, output.CreatedAt)
+	return err
+}
+
+// GetToolOutput retrieves a stored tool output by ID.
+func (s *PostgresStore) GetToolOutput(id string) (*ToolOutput, error) {
+	var output ToolOutput
+	err := s.queryRow(`
+		SELECT id, session_id, tool_call_id, tool_name, content, created_at
+		FROM tool_outputs WHERE id = ?
+	`, id).Scan(&output.ID, &output.SessionID, &output.ToolCallID, &output.ToolName, &output.Content, &output.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tool output not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// SaveAttachment records a file uploaded to a session.
+func (s *PostgresStore) SaveAttachment(att *Attachment) error {
+	_, err := s.exec(`
+		INSERT INTO attachments (id, session_id, name, media_type, size, path, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, att.ID, att.SessionID, att.Name, att.MediaType, att.Size, att.Path, att.CreatedAt)
+	return err
+}
+
+// GetAttachment retrieves an attachment by ID.
+func (s *PostgresStore) GetAttachment(id string) (*Attachment, error) {
+	att, err := scanAttachment(s.queryRow(`
+		SELECT id, session_id, name, media_type, size, path, created_at
+		FROM attachments WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found: %s", id)
+	}
+	return att, err
+}
+
+// ListAttachments returns the attachments of a session, oldest first.
+func (s *PostgresStore) ListAttachments(sessionID string) ([]*Attachment, error) {
+	rows, err := s.query(`
+		SELECT id, session_id, name, media_type, size, path, created_at
+		FROM attachments WHERE session_id = ? ORDER BY created_at, id
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+// SaveMemory inserts a memory or replaces the value of an existing one.
+func (s *PostgresStore) SaveMemory(mem *Memory) error {
+	_, err := s.exec(`
+		INSERT INTO memories (scope, key, value, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (scope, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, mem.Scope, mem.Key, mem.Value, mem.CreatedAt, mem.UpdatedAt)
+	return err
+}
+
+// GetMemory retrieves a memory by scope and key.
+func (s *PostgresStore) GetMemory(scope, key string) (*Memory, error) {
+	var mem Memory
+	err := s.queryRow(`
+		SELECT scope, key, value, created_at, updated_at
+		FROM memories WHERE scope = ? AND key = ?
+	`, scope, key).Scan(&mem.Scope, &mem.Key, &mem.Value, &mem.CreatedAt, &mem.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("memory not found: %s/%s", scope, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mem, nil
+}
+
+// ListMemories returns the memories of a scope, or of every scope when scope
+// is empty, ordered by scope and key.
+func (s *PostgresStore) ListMemories(scope string) ([]*Memory, error) {
+	query := `SELECT scope, key, value, created_at, updated_at FROM memories`
+	var args []interface{}
+	if scope != "" {
+		query += ` WHERE scope = ?`
+		args = append(args, scope)
+	}
+	rows, err := s.query(query+` ORDER BY scope, key`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []*Memory
+	for rows.Next() {
+		var mem Memory
+		if err := rows.Scan(&mem.Scope, &mem.Key, &mem.Value, &mem.CreatedAt, &mem.UpdatedAt); err != nil {
+			return nil, err
+		}
+		memories = append(memories, &mem)
+	}
+	return memories, rows.Err()
+}
+
+// DeleteMemories deletes one memory, or every memory of scope when key is
+// empty.
+func (s *PostgresStore) DeleteMemories(scope, key string) error {
+	if key == "" {
+		_, err := s.exec(`DELETE FROM memories WHERE scope = ?`, scope)
+		return err
+	}
+	_, err := s.exec(`DELETE FROM memories WHERE scope = ? AND key = ?`, scope, key)
+	return err
+}
+
+// Ensure PostgresStore implements Store
+var _ Store = (*PostgresStore)(nil)
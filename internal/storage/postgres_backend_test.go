@@ -0,0 +1,60 @@
+//go:build postgres
+
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run with: AAGENT_TEST_POSTGRES_DSN=postgres://... go test -tags postgres ./internal/storage
+func init() {
+	storeBackends = append(storeBackends, storeBackend{
+		name:  DriverPostgres,
+		newDB: newTestPostgresDB,
+	})
+}
+
+// newTestPostgresDB creates a throwaway schema so each test starts empty.
+func newTestPostgresDB(t *testing.T) func(t *testing.T) Store {
+	dsn := os.Getenv("AAGENT_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("AAGENT_TEST_POSTGRES_DSN not set")
+	}
+	admin, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open admin connection: %v", err)
+	}
+	schema := fmt.Sprintf("aagent_test_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		admin.Close()
+		t.Fatalf("create schema: %v", err)
+	}
+	t.Cleanup(func() {
+		admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		admin.Close()
+	})
+
+	schemaDSN := dsn + " search_path=" + schema
+	if strings.Contains(dsn, "://") {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		schemaDSN = dsn + sep + "search_path=" + schema
+	}
+	dataPath := t.TempDir()
+	return func(t *testing.T) Store {
+		t.Helper()
+		store, err := NewPostgresStore(schemaDSN, dataPath)
+		if err != nil {
+			t.Fatalf("NewPostgresStore: %v", err)
+		}
+		t.Cleanup(func() { store.Close() })
+		return store
+	}
+}
@@ -0,0 +1,11 @@
+package storage
+
+import "testing"
+
+func TestRebindPostgres(t *testing.T) {
+	got := rebindPostgres(`SELECT * FROM t WHERE a = ? AND b = '?' AND c IN (?, ?)`)
+	want := `SELECT * FROM t WHERE a = $1 AND b = '?' AND c IN ($2, $3)`
+	if got != want {
+		t.Fatalf("rebindPostgres = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,240 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// SecretKeyEnv names the environment variable holding the master key used to
+// encrypt integration configs at rest. Any string works; it is stretched to a
+// 256-bit AES key with SHA-256.
+const SecretKeyEnv = "AAGENT_SECRET_KEY"
+
+// sealedConfigVersion marks integration configs stored as an encrypted envelope.
+const sealedConfigVersion = "aesgcm-v1"
+
+// ErrSecretKeyMissing is returned when an encrypted integration config is read
+// without AAGENT_SECRET_KEY set.
+var ErrSecretKeyMissing = errors.New("integration config is encrypted but " + SecretKeyEnv + " is not set")
+
+// SecretBox performs envelope encryption: every sealed config gets a random
+// data key that encrypts its values, and the data key itself is stored
+// encrypted with the master key.
+type SecretBox struct {
+	masterKey []byte
+}
+
+// NewSecretBox derives a SecretBox from a master key string.
+func NewSecretBox(masterKey string) *SecretBox {
+	sum := sha256.Sum256([]byte(masterKey))
+	return &SecretBox{masterKey: sum[:]}
+}
+
+// secretBoxFromEnv returns the SecretBox for AAGENT_SECRET_KEY, or nil when the
+// variable is unset and configs are stored in plaintext.
+func secretBoxFromEnv() *SecretBox {
+	key := strings.TrimSpace(os.Getenv(SecretKeyEnv))
+	if key == "" {
+		return nil
+	}
+	return NewSecretBox(key)
+}
+
+// sealedConfig is the JSON stored in place of a plaintext config map.
+type sealedConfig struct {
+	Encryption string            `json:"encryption"`
+	DataKey    string            `json:"data_key"`
+	Values     map[string]string `json:"values"`
+}
+
+// SealConfig encrypts every config value and returns the JSON envelope.
+func (b *SecretBox) SealConfig(config map[string]string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+	wrappedKey, err := sealBytes(b.masterKey, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	envelope := sealedConfig{
+		Encryption: sealedConfigVersion,
+		DataKey:    wrappedKey,
+		Values:     make(map[string]string, len(config)),
+	}
+	for key, value := range config {
+		sealed, err := sealBytes(dataKey, []byte(value))
+		if err != nil {
+			return "", err
+		}
+		envelope.Values[key] = sealed
+	}
+	encoded, err := json.Marshal(envelope)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// OpenConfig decrypts an envelope produced by SealConfig.
+func (b *SecretBox) OpenConfig(raw string) (map[string]string, error) {
+	var envelope sealedConfig
+	if err := json.Unmarshal([]byte(raw), &envelope); err != nil {
+		return nil, fmt.Errorf("failed to decode sealed config: %w", err)
+	}
+	if envelope.Encryption != sealedConfigVersion {
+		return nil, fmt.Errorf("unsupported config encryption %q", envelope.Encryption)
+	}
+	dataKey, err := openBytes(b.masterKey, envelope.DataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key (wrong %s?): %w", SecretKeyEnv, err)
+	}
+
+	config := make(map[string]string, len(envelope.Values))
+	for key, sealed := range envelope.Values {
+		value, err := openBytes(dataKey, sealed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt config value %q: %w", key, err)
+		}
+		config[key] = string(value)
+	}
+	return config, nil
+}
+
+// isSealedConfig reports whether a stored config column holds an envelope.
+func isSealedConfig(raw string) bool {
+	var probe struct {
+		Encryption json.RawMessage `json:"encryption"`
+		DataKey    json.RawMessage `json:"data_key"`
+	}
+	if err := json.Unmarshal([]byte(raw), &probe); err != nil {
+		return false
+	}
+	return len(probe.Encryption) > 0 && len(probe.DataKey) > 0
+}
+
+// encodeIntegrationConfig serialises a config for storage, sealing it when a
+// SecretBox is configured.
+func encodeIntegrationConfig(box *SecretBox, config map[string]string) (string, error) {
+	if box != nil {
+		return box.SealConfig(config)
+	}
+	encoded, err := json.Marshal(config)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// decodeIntegrationConfig reads a stored config, accepting both envelopes and
+// legacy plaintext maps.
+func decodeIntegrationConfig(box *SecretBox, raw string) (map[string]string, error) {
+	config := map[string]string{}
+	if raw == "" {
+		return config, nil
+	}
+	if isSealedConfig(raw) {
+		if box == nil {
+			return nil, ErrSecretKeyMissing
+		}
+		return box.OpenConfig(raw)
+	}
+	if err := json.Unmarshal([]byte(raw), &config); err != nil {
+		return nil, err
+	}
+	if config == nil {
+		config = map[string]string{}
+	}
+	return config, nil
+}
+
+// sealPlaintextIntegrations encrypts integration rows that were written before
+// a secret key was configured. It is a no-op without a SecretBox and safe to
+// run on every start.
+func sealPlaintextIntegrations(db *sql.DB, box *SecretBox, bind func(string) string) (int, error) {
+	if box == nil {
+		return 0, nil
+	}
+	rows, err := db.Query(`SELECT id, config FROM integrations`)
+	if err != nil {
+		return 0, err
+	}
+	pending := map[string]string{}
+	for rows.Next() {
+		var id, raw string
+		if err := rows.Scan(&id, &raw); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if raw != "" && !isSealedConfig(raw) {
+			pending[id] = raw
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for id, raw := range pending {
+		config, err := decodeIntegrationConfig(nil, raw)
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode integration %s config: %w", id, err)
+		}
+		sealed, err := box.SealConfig(config)
+		if err != nil {
+			return 0, err
+		}
+		// Only replace the row if it still holds the plaintext we read.
+		if _, err := db.Exec(bind(`UPDATE integrations SET config = ? WHERE id = ? AND config = ?`), sealed, id, raw); err != nil {
+			return 0, fmt.Errorf("failed to encrypt integration %s config: %w", id, err)
+		}
+	}
+	return len(pending), nil
+}
+
+func sealBytes(key, plaintext []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func openBytes(key []byte, encoded string) ([]byte, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
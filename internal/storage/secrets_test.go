@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIntegrationConfigEncryptedAtRest(t *testing.T) {
+	t.Setenv(SecretKeyEnv, "test-master-key")
+	dir := t.TempDir()
+	store := newTestSQLiteStoreAt(t, dir)
+
+	now := time.Now()
+	integration := &Integration{
+		ID:        "tg",
+		Provider:  "telegram",
+		Name:      "Telegram",
+		Mode:      "duplex",
+		Enabled:   true,
+		Config:    map[string]string{"bot_token": "123456:super-secret-token", "chat_id": "42"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := store.SaveIntegration(integration); err != nil {
+		t.Fatalf("SaveIntegration: %v", err)
+	}
+
+	var raw string
+	if err := store.db.QueryRow(`SELECT config FROM integrations WHERE id = ?`, "tg").Scan(&raw); err != nil {
+		t.Fatalf("read raw config: %v", err)
+	}
+	if strings.Contains(raw, "super-secret-token") || !isSealedConfig(raw) {
+		t.Fatalf("expected sealed config at rest, got %s", raw)
+	}
+
+	got, err := store.GetIntegration("tg")
+	if err != nil {
+		t.Fatalf("GetIntegration: %v", err)
+	}
+	if got.Config["bot_token"] != "123456:super-secret-token" || got.Config["chat_id"] != "42" {
+		t.Fatalf("unexpected decrypted config: %v", got.Config)
+	}
+
+	t.Setenv(SecretKeyEnv, "")
+	keyless := newTestSQLiteStoreAt(t, dir)
+	if _, err := keyless.GetIntegration("tg"); !errors.Is(err, ErrSecretKeyMissing) {
+		t.Fatalf("expected ErrSecretKeyMissing without key, got %v", err)
+	}
+
+	t.Setenv(SecretKeyEnv, "wrong-key")
+	wrongKey := newTestSQLiteStoreAt(t, dir)
+	if _, err := wrongKey.GetIntegration("tg"); err == nil {
+		t.Fatal("expected decryption to fail with the wrong key")
+	}
+}
+
+func TestPlaintextIntegrationsSealedOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	plain := newTestSQLiteStoreAt(t, dir)
+	now := time.Now()
+	if err := plain.SaveIntegration(&Integration{ID: "slack", Provider: "slack", Name: "Slack", Mode: "notify_only", Config: map[string]string{"api_key": "xoxb-legacy"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveIntegration: %v", err)
+	}
+	plain.Close()
+
+	t.Setenv(SecretKeyEnv, "test-master-key")
+	store := newTestSQLiteStoreAt(t, dir)
+	var raw string
+	if err := store.db.QueryRow(`SELECT config FROM integrations WHERE id = ?`, "slack").Scan(&raw); err != nil {
+		t.Fatalf("read raw config: %v", err)
+	}
+	if !isSealedConfig(raw) {
+		t.Fatalf("expected existing row to be encrypted on open, got %s", raw)
+	}
+	got, err := store.GetIntegration("slack")
+	if err != nil {
+		t.Fatalf("GetIntegration: %v", err)
+	}
+	if got.Config["api_key"] != "xoxb-legacy" {
+		t.Fatalf("unexpected config after sealing: %v", got.Config)
+	}
+}
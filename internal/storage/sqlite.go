@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -16,11 +17,30 @@ import (
 // SQLiteStore implements Store using SQLite
 type SQLiteStore struct {
 	db       *sql.DB
+	stmts    *sqliteStatements
+	secrets  *SecretBox // nil stores integration configs in plaintext
 	dataPath string
 	dbPath   string
 	mu       sync.Mutex
 }
 
+const (
+	// sqliteBusyTimeout is how long a connection waits on a locked database
+	// before failing with "database is locked".
+	sqliteBusyTimeout = 5 * time.Second
+	// sqliteMaxOpenConns bounds the pool. WAL lets readers run alongside the
+	// single writer; writers queue on the busy timeout.
+	sqliteMaxOpenConns = 4
+)
+
+// sqliteStatements holds prepared statements for the session hot paths. They
+// are bound to one *sql.DB and re-prepared whenever the connection is swapped.
+type sqliteStatements struct {
+	insertMessage *sql.Stmt
+	getSession    *sql.Stmt
+	listMessages  *sql.Stmt
+}
+
 // NewSQLiteStore creates a new SQLite store
 func NewSQLiteStore(dataPath string) (*SQLiteStore, error) {
 	resolvedDataPath, err := filepath.Abs(dataPath)
@@ -37,28 +57,99 @@ func NewSQLiteStore(dataPath string) (*SQLiteStore, error) {
 		return nil, err
 	}
 
-	store := &SQLiteStore{db: db, dataPath: resolvedDataPath, dbPath: dbPath}
+	store := &SQLiteStore{db: db, secrets: secretBoxFromEnv(), dataPath: resolvedDataPath, dbPath: dbPath}
 	if err := store.migrate(); err != nil {
 		db.Close()
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
+	if _, err := sealPlaintextIntegrations(db, store.secrets, func(query string) string { return query }); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to encrypt integration secrets: %w", err)
+	}
+	stmts, err := prepareSQLiteStatements(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	store.stmts = stmts
 
 	return store, nil
 }
 
 func openSQLiteConnection(dbPath string) (*sql.DB, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", sqliteDSN(dbPath))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
-	// SQLite supports one writer at a time. Keep pool size constrained to
-	// reduce lock contention ("SQLITE_BUSY") under concurrent goroutines.
-	db.SetMaxOpenConns(1)
-	db.SetMaxIdleConns(1)
+	// SQLite supports one writer at a time. WAL mode lets a few readers share
+	// the pool with it, and the busy timeout makes writers (including other
+	// processes using the same file) wait instead of failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(sqliteMaxOpenConns)
+	db.SetMaxIdleConns(sqliteMaxOpenConns)
 	db.SetConnMaxLifetime(0)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
 	return db, nil
 }
 
+// sqliteDSN applies the connection pragmas to every pooled connection.
+// Transactions begin IMMEDIATE so a read-then-write transaction takes the
+// write lock up front and waits on the busy timeout rather than failing when
+// it later tries to upgrade.
+func sqliteDSN(dbPath string) string {
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", sqliteBusyTimeout.Milliseconds()))
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "synchronous(NORMAL)")
+	params.Set("_txlock", "immediate")
+	return dbPath + "?" + params.Encode()
+}
+
+func prepareSQLiteStatements(db *sql.DB) (*sqliteStatements, error) {
+	var (
+		stmts sqliteStatements
+		err   error
+	)
+	prepare := func(query string) *sql.Stmt {
+		if err != nil {
+			return nil
+		}
+		var stmt *sql.Stmt
+		stmt, err = db.Prepare(query)
+		return stmt
+	}
+	stmts.insertMessage = prepare(`
+		INSERT INTO messages (id, session_id, role, content, tool_calls, tool_results, metadata, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	stmts.getSession = prepare(`
+		SELECT id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at, version
+		FROM sessions WHERE id = ?
+	`)
+	stmts.listMessages = prepare(`
+		SELECT id, role, content, tool_calls, tool_results, metadata, timestamp
+		FROM messages WHERE session_id = ? ORDER BY timestamp
+	`)
+	if err != nil {
+		stmts.close()
+		return nil, fmt.Errorf("failed to prepare statements: %w", err)
+	}
+	return &stmts, nil
+}
+
+func (st *sqliteStatements) close() {
+	if st == nil {
+		return
+	}
+	for _, stmt := range []*sql.Stmt{st.insertMessage, st.getSession, st.listMessages} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+}
+
 func isSQLiteReadonlyError(err error) bool {
 	if err == nil {
 		return false
@@ -81,160 +172,24 @@ func (s *SQLiteStore) reopenOnReadonly(writeErr error) error {
 	if err != nil {
 		return fmt.Errorf("failed to reopen sqlite database after readonly error: %w", err)
 	}
-	prev := s.db
-	s.db = nextDB
+	nextStmts, err := prepareSQLiteStatements(nextDB)
+	if err != nil {
+		nextDB.Close()
+		return fmt.Errorf("failed to reopen sqlite database after readonly error: %w", err)
+	}
+	prev, prevStmts := s.db, s.stmts
+	s.db, s.stmts = nextDB, nextStmts
+	prevStmts.close()
 	if prev != nil {
 		_ = prev.Close()
 	}
 	return nil
 }
 
-// migrate runs database migrations
+// migrate applies pending schema migrations and seeds built-in rows
 func (s *SQLiteStore) migrate() error {
-	migrations := []string{
-		`CREATE TABLE IF NOT EXISTS sessions (
-			id TEXT PRIMARY KEY,
-			agent_id TEXT NOT NULL,
-			parent_id TEXT,
-			project_id TEXT,
-			title TEXT DEFAULT '',
-			status TEXT NOT NULL,
-			metadata TEXT,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		`CREATE TABLE IF NOT EXISTS messages (
-			id TEXT PRIMARY KEY,
-			session_id TEXT NOT NULL,
-			role TEXT NOT NULL,
-			content TEXT,
-			tool_calls TEXT,
-			tool_results TEXT,
-			metadata TEXT,
-			timestamp TIMESTAMP NOT NULL,
-			FOREIGN KEY (session_id) REFERENCES sessions(id) ON DELETE CASCADE
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_messages_session_id ON messages(session_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_sessions_parent_id ON sessions(parent_id)`,
-		// Migration to add title column if it doesn't exist
-		`ALTER TABLE sessions ADD COLUMN title TEXT DEFAULT ''`,
-		// Migration to add project_id column to sessions
-		`ALTER TABLE sessions ADD COLUMN project_id TEXT`,
-		// Migration to add metadata column to messages
-		`ALTER TABLE messages ADD COLUMN metadata TEXT`,
-		`CREATE INDEX IF NOT EXISTS idx_sessions_project_id ON sessions(project_id)`,
-		// Recurring jobs table
-		`CREATE TABLE IF NOT EXISTS recurring_jobs (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			schedule_human TEXT NOT NULL,
-			schedule_cron TEXT NOT NULL,
-			task_prompt TEXT NOT NULL,
-			task_prompt_source TEXT NOT NULL DEFAULT 'text',
-			task_prompt_file TEXT NOT NULL DEFAULT '',
-			llm_provider TEXT,
-			enabled INTEGER NOT NULL DEFAULT 1,
-			last_run_at TIMESTAMP,
-			next_run_at TIMESTAMP,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		`ALTER TABLE recurring_jobs ADD COLUMN task_prompt_source TEXT NOT NULL DEFAULT 'text'`,
-		`ALTER TABLE recurring_jobs ADD COLUMN task_prompt_file TEXT NOT NULL DEFAULT ''`,
-		`ALTER TABLE recurring_jobs ADD COLUMN llm_provider TEXT`,
-		`CREATE INDEX IF NOT EXISTS idx_recurring_jobs_next_run ON recurring_jobs(next_run_at)`,
-		`CREATE INDEX IF NOT EXISTS idx_recurring_jobs_enabled ON recurring_jobs(enabled)`,
-		// Job executions table
-		`CREATE TABLE IF NOT EXISTS job_executions (
-			id TEXT PRIMARY KEY,
-			job_id TEXT NOT NULL,
-			session_id TEXT,
-			status TEXT NOT NULL,
-			output TEXT,
-			error TEXT,
-			started_at TIMESTAMP NOT NULL,
-			finished_at TIMESTAMP,
-			FOREIGN KEY (job_id) REFERENCES recurring_jobs(id) ON DELETE CASCADE
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_job_executions_job_id ON job_executions(job_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_job_executions_started_at ON job_executions(started_at)`,
-		// Migration: Add job_id column to sessions
-		`ALTER TABLE sessions ADD COLUMN job_id TEXT`,
-		`CREATE INDEX IF NOT EXISTS idx_sessions_job_id ON sessions(job_id)`,
-		// App settings key/value table (secrets/tokens and other runtime settings)
-		`CREATE TABLE IF NOT EXISTS app_settings (
-			key TEXT PRIMARY KEY,
-			value TEXT NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		// Channel integrations (Telegram/Slack/Discord/WhatsApp/Webhook)
-		`CREATE TABLE IF NOT EXISTS integrations (
-			id TEXT PRIMARY KEY,
-			provider TEXT NOT NULL,
-			name TEXT NOT NULL,
-			mode TEXT NOT NULL,
-			enabled INTEGER NOT NULL DEFAULT 1,
-			config TEXT NOT NULL,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_integrations_provider ON integrations(provider)`,
-		// MCP server registry
-		`CREATE TABLE IF NOT EXISTS mcp_servers (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			transport TEXT NOT NULL,
-			enabled INTEGER NOT NULL DEFAULT 1,
-			config TEXT NOT NULL,
-			last_test_at TIMESTAMP,
-			last_test_success INTEGER,
-			last_test_message TEXT,
-			last_estimated_tokens INTEGER,
-			last_tool_count INTEGER,
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		`ALTER TABLE mcp_servers ADD COLUMN last_test_at TIMESTAMP`,
-		`ALTER TABLE mcp_servers ADD COLUMN last_test_success INTEGER`,
-		`ALTER TABLE mcp_servers ADD COLUMN last_test_message TEXT`,
-		`ALTER TABLE mcp_servers ADD COLUMN last_estimated_tokens INTEGER`,
-		`ALTER TABLE mcp_servers ADD COLUMN last_tool_count INTEGER`,
-		`CREATE INDEX IF NOT EXISTS idx_mcp_servers_transport ON mcp_servers(transport)`,
-		// Projects for optional session grouping
-		`CREATE TABLE IF NOT EXISTS projects (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			folders TEXT NOT NULL DEFAULT '[]',
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		`CREATE INDEX IF NOT EXISTS idx_projects_name ON projects(name)`,
-		// Migration: Add is_system column to projects
-		`ALTER TABLE projects ADD COLUMN is_system INTEGER NOT NULL DEFAULT 0`,
-		// Migration: Change folders to folder (single folder, nullable)
-		`ALTER TABLE projects ADD COLUMN folder TEXT`,
-		// Migration: Add task_progress column to sessions
-		`ALTER TABLE sessions ADD COLUMN task_progress TEXT`,
-		// Sub-agents table
-		`CREATE TABLE IF NOT EXISTS sub_agents (
-			id TEXT PRIMARY KEY,
-			name TEXT NOT NULL,
-			provider TEXT NOT NULL DEFAULT '',
-			model TEXT NOT NULL DEFAULT '',
-			enabled_tools TEXT NOT NULL DEFAULT '[]',
-			created_at TIMESTAMP NOT NULL,
-			updated_at TIMESTAMP NOT NULL
-		)`,
-		// Migration: Add instruction_blocks column to sub_agents
-		`ALTER TABLE sub_agents ADD COLUMN instruction_blocks TEXT NOT NULL DEFAULT '[]'`,
-	}
-
-	for _, m := range migrations {
-		// Ignore errors for ALTER TABLE (column may already exist)
-		_, err := s.db.Exec(m)
-		if err != nil && m[:5] != "ALTER" {
-			return fmt.Errorf("migration failed: %w", err)
-		}
+	if err := applyMigrations(s.db, sqliteMigrationBackend); err != nil {
+		return fmt.Errorf("migration failed: %w", err)
 	}
 
 	// Seed system projects (idempotent - uses INSERT OR IGNORE)
@@ -252,21 +207,27 @@ const (
 	SystemProjectSoulID  = "system-soul"
 )
 
-// seedSystemProjects creates the system projects if they don't exist.
-// These are required for the Knowledge Base and Agent session lists in the sidebar.
-func (s *SQLiteStore) seedSystemProjects() error {
-	systemProjects := []struct {
-		id     string
-		name   string
-		folder *string
-	}{
+type systemProjectSeed struct {
+	id     string
+	name   string
+	folder *string
+}
+
+// systemProjectSeeds lists the built-in projects every store creates. The Soul
+// project is pinned to the data directory.
+func systemProjectSeeds(dataPath string) []systemProjectSeed {
+	return []systemProjectSeed{
 		{SystemProjectKBID, "Knowledge Base", nil},
 		{SystemProjectAgentID, "Body", nil},
-		{SystemProjectSoulID, "Soul", &s.dataPath},
+		{SystemProjectSoulID, "Soul", &dataPath},
 	}
+}
 
+// seedSystemProjects creates the system projects if they don't exist.
+// These are required for the Knowledge Base and Agent session lists in the sidebar.
+func (s *SQLiteStore) seedSystemProjects() error {
 	now := time.Now()
-	for _, p := range systemProjects {
+	for _, p := range systemProjectSeeds(s.dataPath) {
 		_, err := s.db.Exec(`
 			INSERT OR IGNORE INTO projects (id, name, folder, is_system, created_at, updated_at)
 			VALUES (?, ?, NULL, 1, ?, ?)
@@ -283,7 +244,7 @@ func (s *SQLiteStore) seedSystemProjects() error {
 			`, p.name, p.folder, now, p.id); err != nil {
 				return fmt.Errorf("failed to update system project %s metadata: %w", p.id, err)
 			}
-			if err := s.ensureSoulProjectDefaults(); err != nil {
+			if err := ensureSoulProjectDefaults(s.dataPath); err != nil {
 				return fmt.Errorf("failed to apply soul project defaults: %w", err)
 			}
 			continue
@@ -301,16 +262,16 @@ func (s *SQLiteStore) seedSystemProjects() error {
 
 const soulGitignoreManagedBlock = "# A2gent Soul defaults\nlogs/\n*.log\n"
 
-func (s *SQLiteStore) ensureSoulProjectDefaults() error {
-	if strings.TrimSpace(s.dataPath) == "" {
+func ensureSoulProjectDefaults(dataPath string) error {
+	if strings.TrimSpace(dataPath) == "" {
 		return nil
 	}
 
-	if err := os.MkdirAll(s.dataPath, 0o755); err != nil {
+	if err := os.MkdirAll(dataPath, 0o755); err != nil {
 		return err
 	}
 
-	gitignorePath := filepath.Join(s.dataPath, ".gitignore")
+	gitignorePath := filepath.Join(dataPath, ".gitignore")
 	existing, err := os.ReadFile(gitignorePath)
 	if err != nil && !os.IsNotExist(err) {
 		return err
@@ -340,22 +301,59 @@ func (s *SQLiteStore) SaveSession(sess *Session) error {
 
 		metadata, _ := json.Marshal(sess.Metadata)
 
-		// Upsert session
-		_, err = tx.Exec(`
-			INSERT INTO sessions (id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-			ON CONFLICT(id) DO UPDATE SET
-				parent_id = excluded.parent_id,
-				job_id = excluded.job_id,
-				project_id = excluded.project_id,
-				title = excluded.title,
-				status = excluded.status,
-				metadata = excluded.metadata,
-				task_progress = excluded.task_progress,
-				updated_at = excluded.updated_at
-		`, sess.ID, sess.AgentID, sess.ParentID, sess.JobID, sess.ProjectID, sess.Title, sess.Status, metadata, sess.TaskProgress, sess.CreatedAt, sess.UpdatedAt)
-		if err != nil {
-			return fmt.Errorf("failed to save session: %w", err)
+		// Sessions loaded from the store carry their version; only overwrite
+		// the row if nobody else saved it in the meantime.
+		newVersion := sess.Version + 1
+		upsert := sess.Version == 0
+		if !upsert {
+			res, err := tx.Exec(`
+				UPDATE sessions SET
+					parent_id = ?,
+					job_id = ?,
+					project_id = ?,
+					title = ?,
+					status = ?,
+					metadata = ?,
+					task_progress = ?,
+					updated_at = ?,
+					version = ?
+				WHERE id = ? AND version = ?
+			`, sess.ParentID, sess.JobID, sess.ProjectID, sess.Title, sess.Status, metadata, sess.TaskProgress, sess.UpdatedAt, newVersion, sess.ID, sess.Version)
+			if err != nil {
+				return fmt.Errorf("failed to save session: %w", err)
+			}
+			if affected, _ := res.RowsAffected(); affected == 0 {
+				var exists int
+				if err := tx.QueryRow("SELECT COUNT(*) FROM sessions WHERE id = ?", sess.ID).Scan(&exists); err != nil {
+					return fmt.Errorf("failed to check session version: %w", err)
+				}
+				if exists > 0 {
+					return ErrStaleSession
+				}
+				upsert = true
+			}
+		}
+		if upsert {
+			_, err = tx.Exec(`
+				INSERT INTO sessions (id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at, version)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(id) DO UPDATE SET
+					parent_id = excluded.parent_id,
+					job_id = excluded.job_id,
+					project_id = excluded.project_id,
+					title = excluded.title,
+					status = excluded.status,
+					metadata = excluded.metadata,
+					task_progress = excluded.task_progress,
+					updated_at = excluded.updated_at,
+					version = sessions.version + 1
+			`, sess.ID, sess.AgentID, sess.ParentID, sess.JobID, sess.ProjectID, sess.Title, sess.Status, metadata, sess.TaskProgress, sess.CreatedAt, sess.UpdatedAt, newVersion)
+			if err != nil {
+				return fmt.Errorf("failed to save session: %w", err)
+			}
+			if err := tx.QueryRow("SELECT version FROM sessions WHERE id = ?", sess.ID).Scan(&newVersion); err != nil {
+				return fmt.Errorf("failed to read session version: %w", err)
+			}
 		}
 
 		// Delete existing messages and re-insert (simple approach for now)
@@ -365,18 +363,21 @@ func (s *SQLiteStore) SaveSession(sess *Session) error {
 		}
 
 		// Insert messages
+		insertMessage := tx.Stmt(s.stmts.insertMessage)
+		defer insertMessage.Close()
 		for _, msg := range sess.Messages {
 			messageMetadata, _ := json.Marshal(msg.Metadata)
-			_, err = tx.Exec(`
-				INSERT INTO messages (id, session_id, role, content, tool_calls, tool_results, metadata, timestamp)
-				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-			`, msg.ID, sess.ID, msg.Role, msg.Content, msg.ToolCalls, msg.ToolResults, messageMetadata, msg.Timestamp)
+			_, err = insertMessage.Exec(msg.ID, sess.ID, msg.Role, msg.Content, msg.ToolCalls, msg.ToolResults, messageMetadata, msg.Timestamp)
 			if err != nil {
 				return fmt.Errorf("failed to save message: %w", err)
 			}
 		}
 
-		return tx.Commit()
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+		sess.Version = newVersion
+		return nil
 	}
 
 	if err := save(); err != nil {
@@ -401,10 +402,7 @@ func (s *SQLiteStore) GetSession(id string) (*Session, error) {
 	var title sql.NullString
 	var taskProgress sql.NullString
 
-	err := s.db.QueryRow(`
-		SELECT id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at
-		FROM sessions WHERE id = ?
-	`, id).Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt)
+	err := s.stmts.getSession.QueryRow(id).Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt, &sess.Version)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("session not found: %s", id)
 	}
@@ -432,10 +430,7 @@ func (s *SQLiteStore) GetSession(id string) (*Session, error) {
 	}
 
 	// Load messages
-	rows, err := s.db.Query(`
-		SELECT id, role, content, tool_calls, tool_results, metadata, timestamp
-		FROM messages WHERE session_id = ? ORDER BY timestamp
-	`, id)
+	rows, err := s.stmts.listMessages.Query(id)
 	if err != nil {
 		return nil, err
 	}
@@ -468,14 +463,71 @@ func (s *SQLiteStore) GetSession(id string) (*Session, error) {
 
 // ListSessions lists all regular sessions plus Thinking job sessions.
 func (s *SQLiteStore) ListSessions() ([]*Session, error) {
+	sessions, _, err := s.ListSessionsPage(SessionFilter{Limit: -1})
+	return sessions, err
+}
+
+// ListSessionsPage returns one page of ListSessions matching the filter,
+// newest first, together with the total number of matching sessions.
+func (s *SQLiteStore) ListSessionsPage(filter SessionFilter) ([]*Session, int, error) {
+	where := []string{"(job_id IS NULL OR project_id = 'project-thinking')"}
+	var args []interface{}
+	if status := strings.TrimSpace(filter.Status); status != "" {
+		where = append(where, "status = ?")
+		args = append(args, status)
+	}
+	if agentID := strings.TrimSpace(filter.AgentID); agentID != "" {
+		where = append(where, "agent_id = ?")
+		args = append(args, agentID)
+	}
+	for _, agentID := range filter.ExcludeAgentIDs {
+		if agentID = strings.TrimSpace(agentID); agentID != "" {
+			where = append(where, "agent_id <> ?")
+			args = append(args, agentID)
+		}
+	}
+	if filter.CreatedAfter != nil {
+		where = append(where, "created_at > ?")
+		args = append(args, *filter.CreatedAfter)
+	}
+	for _, key := range filter.MetadataFlags {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		where = append(where, "json_extract(metadata, ?) = 1")
+		args = append(args, "$."+key)
+	}
+	whereClause := strings.Join(where, " AND ")
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM sessions WHERE "+whereClause, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = DefaultSessionPageSize
+	}
+	offset := filter.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	pageArgs := append(append([]interface{}{}, args...), limit, offset)
+	orderBy := "created_at"
+	if filter.OrderByUpdated {
+		orderBy = "updated_at"
+	}
+
 	rows, err := s.db.Query(`
-		SELECT id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at
+		SELECT id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at, version
 		FROM sessions 
-		WHERE job_id IS NULL OR project_id = 'project-thinking'
-		ORDER BY created_at DESC
-	`)
+		WHERE `+whereClause+`
+		ORDER BY `+orderBy+` DESC
+		LIMIT ? OFFSET ?
+	`, pageArgs...)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer rows.Close()
 
@@ -487,9 +539,9 @@ func (s *SQLiteStore) ListSessions() ([]*Session, error) {
 		var metadata sql.NullString
 		var taskProgress sql.NullString
 
-		err := rows.Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt)
+		err := rows.Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt, &sess.Version)
 		if err != nil {
-			return nil, err
+			return nil, 0, err
 		}
 
 		if parentID.Valid {
@@ -513,14 +565,17 @@ func (s *SQLiteStore) ListSessions() ([]*Session, error) {
 
 		sessions = append(sessions, &sess)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
-	return sessions, nil
+	return sessions, total, nil
 }
 
 // ListSessionsByJob returns all sessions associated with a specific job
 func (s *SQLiteStore) ListSessionsByJob(jobID string) ([]*Session, error) {
 	rows, err := s.db.Query(`
-		SELECT id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at
+		SELECT id, agent_id, parent_id, job_id, project_id, title, status, metadata, task_progress, created_at, updated_at, version
 		FROM sessions 
 		WHERE job_id = ?
 		ORDER BY created_at DESC
@@ -538,7 +593,7 @@ func (s *SQLiteStore) ListSessionsByJob(jobID string) ([]*Session, error) {
 		var metadata sql.NullString
 		var taskProgress sql.NullString
 
-		err := rows.Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt)
+		err := rows.Scan(&sess.ID, &sess.AgentID, &parentID, &jobID, &projectID, &title, &sess.Status, &metadata, &taskProgress, &sess.CreatedAt, &sess.UpdatedAt, &sess.Version)
 		if err != nil {
 			return nil, err
 		}
@@ -568,10 +623,160 @@ func (s *SQLiteStore) ListSessionsByJob(jobID string) ([]*Session, error) {
 	return sessions, nil
 }
 
-// DeleteSession deletes a session
+// DeleteSession deletes a session and everything recorded for it in a single
+// transaction. Its files are removed only once that has committed.
 func (s *SQLiteStore) DeleteSession(id string) error {
-	_, err := s.db.Exec("DELETE FROM sessions WHERE id = ?", id)
-	return err
+	attachments, err := s.ListAttachments(id)
+	if err != nil {
+		return err
+	}
+	outputs, err := s.executionOutputPaths(id)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := deleteSessionTx(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	removeAttachmentFiles(attachments)
+	removeExecutionOutputs(outputs)
+	removeUndoBackups(s.dataPath, id)
+	return nil
+}
+
+// PurgeSessions deletes stale sessions in a single transaction. Job sessions
+// are ranked per job so the newest opts.KeepLastPerJob always survive.
+func (s *SQLiteStore) PurgeSessions(olderThan time.Time, excludeStatuses []string, opts PurgeOptions) ([]*Session, error) {
+	where := []string{"updated_at < ?"}
+	args := []interface{}{olderThan}
+	if !opts.IncludeNonJob {
+		where = append(where, "job_id IS NOT NULL")
+	}
+	if len(excludeStatuses) > 0 {
+		placeholders := make([]string, len(excludeStatuses))
+		for i, status := range excludeStatuses {
+			placeholders[i] = "?"
+			args = append(args, status)
+		}
+		where = append(where, "status NOT IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if opts.KeepLastPerJob > 0 {
+		where = append(where, "(job_id IS NULL OR job_rank > ?)")
+		args = append(args, opts.KeepLastPerJob)
+	}
+
+	rows, err := s.db.Query(`
+		SELECT id, agent_id, job_id, title, status, created_at, updated_at
+		FROM (
+			SELECT id, agent_id, job_id, title, status, created_at, updated_at,
+				ROW_NUMBER() OVER (PARTITION BY job_id ORDER BY created_at DESC) AS job_rank
+			FROM sessions
+		)
+		WHERE `+strings.Join(where, " AND ")+`
+		ORDER BY created_at ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var purged []*Session
+	for rows.Next() {
+		var sess Session
+		var jobID, title sql.NullString
+		if err := rows.Scan(&sess.ID, &sess.AgentID, &jobID, &title, &sess.Status, &sess.CreatedAt, &sess.UpdatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if jobID.Valid {
+			sess.JobID = &jobID.String
+		}
+		if title.Valid {
+			sess.Title = title.String
+		}
+		purged = append(purged, &sess)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if opts.DryRun || len(purged) == 0 {
+		return purged, nil
+	}
+
+	var attachments []*Attachment
+	var outputs []string
+	for _, sess := range purged {
+		sessionAttachments, err := s.ListAttachments(sess.ID)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, sessionAttachments...)
+		sessionOutputs, err := s.executionOutputPaths(sess.ID)
+		if err != nil {
+			return nil, err
+		}
+		outputs = append(outputs, sessionOutputs...)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, sess := range purged {
+		if err := deleteSessionTx(tx, sess.ID); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	removeAttachmentFiles(attachments)
+	removeExecutionOutputs(outputs)
+	for _, sess := range purged {
+		removeUndoBackups(s.dataPath, sess.ID)
+	}
+	return purged, nil
+}
+
+// deleteSessionTx deletes a session along with its messages, executions,
+// tool outputs and attachment rows.
+func deleteSessionTx(tx *sql.Tx, id string) error {
+	if _, err := tx.Exec("DELETE FROM messages WHERE session_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete messages for session %s: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM job_executions WHERE session_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete executions for session %s: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM tool_outputs WHERE session_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete tool outputs for session %s: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM attachments WHERE session_id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete attachments for session %s: %w", id, err)
+	}
+	if _, err := tx.Exec("DELETE FROM sessions WHERE id = ?", id); err != nil {
+		return fmt.Errorf("failed to delete session %s: %w", id, err)
+	}
+	return nil
+}
+
+// executionOutputPaths returns the output files of a session's executions.
+func (s *SQLiteStore) executionOutputPaths(sessionID string) ([]string, error) {
+	rows, err := s.db.Query(`SELECT output_path FROM job_executions WHERE session_id = ? AND output_path != ''`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanExecutionOutputPaths(rows)
 }
 
 // SaveProject saves a project to the database.
@@ -676,7 +881,20 @@ func (s *SQLiteStore) DeleteProject(id string) error {
 }
 
 // Close closes the database connection
+// CheckWritable writes a settings row and rolls it back, which fails on a
+// read-only or full disk.
+func (s *SQLiteStore) CheckWritable() error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec("INSERT OR REPLACE INTO app_settings (key, value, updated_at) VALUES ('__health_check', '', ?)", time.Now())
+	return err
+}
+
 func (s *SQLiteStore) Close() error {
+	s.stmts.close()
 	return s.db.Close()
 }
 
@@ -685,8 +903,8 @@ func (s *SQLiteStore) Close() error {
 // SaveJob saves a recurring job to the database
 func (s *SQLiteStore) SaveJob(job *RecurringJob) error {
 	_, err := s.db.Exec(`
-		INSERT INTO recurring_jobs (id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, enabled, last_run_at, next_run_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO recurring_jobs (id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, timezone, timeout_minutes, keep_executions, fail_on_question, model, agent_id, run_at, notify_on, notify_integration_ids, response_schema, enabled, last_run_at, next_run_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
 			name = excluded.name,
 			schedule_human = excluded.schedule_human,
@@ -695,11 +913,21 @@ func (s *SQLiteStore) SaveJob(job *RecurringJob) error {
 			task_prompt_source = excluded.task_prompt_source,
 			task_prompt_file = excluded.task_prompt_file,
 			llm_provider = excluded.llm_provider,
+			timezone = excluded.timezone,
+			timeout_minutes = excluded.timeout_minutes,
+			keep_executions = excluded.keep_executions,
+			fail_on_question = excluded.fail_on_question,
+			model = excluded.model,
+			agent_id = excluded.agent_id,
+			run_at = excluded.run_at,
+			notify_on = excluded.notify_on,
+			notify_integration_ids = excluded.notify_integration_ids,
+			response_schema = excluded.response_schema,
 			enabled = excluded.enabled,
 			last_run_at = excluded.last_run_at,
 			next_run_at = excluded.next_run_at,
 			updated_at = excluded.updated_at
-	`, job.ID, job.Name, job.ScheduleHuman, job.ScheduleCron, job.TaskPrompt, job.TaskPromptSource, job.TaskPromptFile, job.LLMProvider, job.Enabled, job.LastRunAt, job.NextRunAt, job.CreatedAt, job.UpdatedAt)
+	`, job.ID, job.Name, job.ScheduleHuman, job.ScheduleCron, job.TaskPrompt, job.TaskPromptSource, job.TaskPromptFile, job.LLMProvider, job.Timezone, job.TimeoutMinutes, job.KeepExecutions, job.FailOnQuestion, job.Model, job.AgentID, job.RunAt, job.NotifyOn, joinJobIDs(job.NotifyTargets), job.ResponseSchema, job.Enabled, job.LastRunAt, job.NextRunAt, job.CreatedAt, job.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save job: %w", err)
 	}
@@ -709,13 +937,14 @@ func (s *SQLiteStore) SaveJob(job *RecurringJob) error {
 // GetJob retrieves a recurring job by ID
 func (s *SQLiteStore) GetJob(id string) (*RecurringJob, error) {
 	var job RecurringJob
-	var lastRunAt, nextRunAt sql.NullTime
-	var enabled int
+	var runAt, lastRunAt, nextRunAt sql.NullTime
+	var notifyTargets string
+	var enabled, failOnQuestion int
 
 	err := s.db.QueryRow(`
-		SELECT id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, enabled, last_run_at, next_run_at, created_at, updated_at
+		SELECT id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, timezone, timeout_minutes, keep_executions, fail_on_question, model, agent_id, run_at, notify_on, notify_integration_ids, response_schema, enabled, last_run_at, next_run_at, created_at, updated_at
 		FROM recurring_jobs WHERE id = ?
-	`, id).Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt)
+	`, id).Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &job.Timezone, &job.TimeoutMinutes, &job.KeepExecutions, &failOnQuestion, &job.Model, &job.AgentID, &runAt, &job.NotifyOn, &notifyTargets, &job.ResponseSchema, &enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("job not found: %s", id)
 	}
@@ -724,6 +953,11 @@ func (s *SQLiteStore) GetJob(id string) (*RecurringJob, error) {
 	}
 
 	job.Enabled = enabled == 1
+	job.FailOnQuestion = failOnQuestion == 1
+	job.NotifyTargets = splitJobIDs(notifyTargets)
+	if runAt.Valid {
+		job.RunAt = &runAt.Time
+	}
 	if lastRunAt.Valid {
 		job.LastRunAt = &lastRunAt.Time
 	}
@@ -737,7 +971,7 @@ func (s *SQLiteStore) GetJob(id string) (*RecurringJob, error) {
 // ListJobs lists all recurring jobs
 func (s *SQLiteStore) ListJobs() ([]*RecurringJob, error) {
 	rows, err := s.db.Query(`
-		SELECT id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, enabled, last_run_at, next_run_at, created_at, updated_at
+		SELECT id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, timezone, timeout_minutes, keep_executions, fail_on_question, model, agent_id, run_at, notify_on, notify_integration_ids, response_schema, enabled, last_run_at, next_run_at, created_at, updated_at
 		FROM recurring_jobs ORDER BY created_at DESC
 	`)
 	if err != nil {
@@ -748,15 +982,21 @@ func (s *SQLiteStore) ListJobs() ([]*RecurringJob, error) {
 	var jobs []*RecurringJob
 	for rows.Next() {
 		var job RecurringJob
-		var lastRunAt, nextRunAt sql.NullTime
-		var enabled int
+		var runAt, lastRunAt, nextRunAt sql.NullTime
+		var notifyTargets string
+		var enabled, failOnQuestion int
 
-		err := rows.Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt)
+		err := rows.Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &job.Timezone, &job.TimeoutMinutes, &job.KeepExecutions, &failOnQuestion, &job.Model, &job.AgentID, &runAt, &job.NotifyOn, &notifyTargets, &job.ResponseSchema, &enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
 
 		job.Enabled = enabled == 1
+		job.FailOnQuestion = failOnQuestion == 1
+		job.NotifyTargets = splitJobIDs(notifyTargets)
+		if runAt.Valid {
+			job.RunAt = &runAt.Time
+		}
 		if lastRunAt.Valid {
 			job.LastRunAt = &lastRunAt.Time
 		}
@@ -777,9 +1017,10 @@ func (s *SQLiteStore) DeleteJob(id string) error {
 }
 
 // GetDueJobs returns jobs that are due to run (next_run_at <= now and enabled)
+// One-shot jobs carry their run_at in next_run_at until they have run.
 func (s *SQLiteStore) GetDueJobs(now time.Time) ([]*RecurringJob, error) {
 	rows, err := s.db.Query(`
-		SELECT id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, enabled, last_run_at, next_run_at, created_at, updated_at
+		SELECT id, name, schedule_human, schedule_cron, task_prompt, task_prompt_source, task_prompt_file, llm_provider, timezone, timeout_minutes, keep_executions, fail_on_question, model, agent_id, run_at, notify_on, notify_integration_ids, response_schema, enabled, last_run_at, next_run_at, created_at, updated_at
 		FROM recurring_jobs 
 		WHERE enabled = 1 AND next_run_at IS NOT NULL AND next_run_at <= ?
 		ORDER BY next_run_at ASC
@@ -792,15 +1033,21 @@ func (s *SQLiteStore) GetDueJobs(now time.Time) ([]*RecurringJob, error) {
 	var jobs []*RecurringJob
 	for rows.Next() {
 		var job RecurringJob
-		var lastRunAt, nextRunAt sql.NullTime
-		var enabled int
+		var runAt, lastRunAt, nextRunAt sql.NullTime
+		var notifyTargets string
+		var enabled, failOnQuestion int
 
-		err := rows.Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt)
+		err := rows.Scan(&job.ID, &job.Name, &job.ScheduleHuman, &job.ScheduleCron, &job.TaskPrompt, &job.TaskPromptSource, &job.TaskPromptFile, &job.LLMProvider, &job.Timezone, &job.TimeoutMinutes, &job.KeepExecutions, &failOnQuestion, &job.Model, &job.AgentID, &runAt, &job.NotifyOn, &notifyTargets, &job.ResponseSchema, &enabled, &lastRunAt, &nextRunAt, &job.CreatedAt, &job.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
 
 		job.Enabled = enabled == 1
+		job.FailOnQuestion = failOnQuestion == 1
+		job.NotifyTargets = splitJobIDs(notifyTargets)
+		if runAt.Valid {
+			job.RunAt = &runAt.Time
+		}
 		if lastRunAt.Valid {
 			job.LastRunAt = &lastRunAt.Time
 		}
@@ -819,14 +1066,16 @@ func (s *SQLiteStore) GetDueJobs(now time.Time) ([]*RecurringJob, error) {
 // SaveJobExecution saves a job execution to the database
 func (s *SQLiteStore) SaveJobExecution(exec *JobExecution) error {
 	_, err := s.db.Exec(`
-		INSERT INTO job_executions (id, job_id, session_id, status, output, error, started_at, finished_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO job_executions (id, job_id, session_id, status, output, output_path, error, started_at, finished_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(id) DO UPDATE SET
+			session_id = excluded.session_id,
 			status = excluded.status,
 			output = excluded.output,
+			output_path = excluded.output_path,
 			error = excluded.error,
 			finished_at = excluded.finished_at
-	`, exec.ID, exec.JobID, exec.SessionID, exec.Status, exec.Output, exec.Error, exec.StartedAt, exec.FinishedAt)
+	`, exec.ID, exec.JobID, exec.SessionID, exec.Status, exec.Output, exec.OutputPath, exec.Error, exec.StartedAt, exec.FinishedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save job execution: %w", err)
 	}
@@ -841,9 +1090,9 @@ func (s *SQLiteStore) GetJobExecution(id string) (*JobExecution, error) {
 	var output, execError sql.NullString
 
 	err := s.db.QueryRow(`
-		SELECT id, job_id, session_id, status, output, error, started_at, finished_at
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
 		FROM job_executions WHERE id = ?
-	`, id).Scan(&exec.ID, &exec.JobID, &sessionID, &exec.Status, &output, &execError, &exec.StartedAt, &finishedAt)
+	`, id).Scan(&exec.ID, &exec.JobID, &sessionID, &exec.Status, &output, &exec.OutputPath, &execError, &exec.StartedAt, &finishedAt)
 	if err == sql.ErrNoRows {
 		return nil, fmt.Errorf("job execution not found: %s", id)
 	}
@@ -870,7 +1119,7 @@ func (s *SQLiteStore) GetJobExecution(id string) (*JobExecution, error) {
 // ListJobExecutions lists executions for a job, ordered by most recent first
 func (s *SQLiteStore) ListJobExecutions(jobID string, limit int) ([]*JobExecution, error) {
 	rows, err := s.db.Query(`
-		SELECT id, job_id, session_id, status, output, error, started_at, finished_at
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
 		FROM job_executions 
 		WHERE job_id = ?
 		ORDER BY started_at DESC
@@ -879,6 +1128,111 @@ func (s *SQLiteStore) ListJobExecutions(jobID string, limit int) ([]*JobExecutio
 	if err != nil {
 		return nil, err
 	}
+	return scanSQLiteJobExecutions(rows)
+}
+
+// ListRunningJobExecutions returns executions still marked running that
+// started before startedBefore.
+func (s *SQLiteStore) ListRunningJobExecutions(startedBefore time.Time) ([]*JobExecution, error) {
+	rows, err := s.db.Query(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM job_executions
+		WHERE status = 'running' AND started_at < ?
+		ORDER BY started_at ASC
+	`, startedBefore)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLiteJobExecutions(rows)
+}
+
+// ListWaitingJobExecutions returns the executions waiting for an answer,
+// newest first.
+func (s *SQLiteStore) ListWaitingJobExecutions() ([]*JobExecution, error) {
+	rows, err := s.db.Query(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM job_executions
+		WHERE status = 'waiting_input'
+		ORDER BY started_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	return scanSQLiteJobExecutions(rows)
+}
+
+// PruneJobExecutions deletes the executions of a job beyond its newest
+// keepLast that started before olderThan, never running or waiting_input
+// ones. Sessions created for those runs of the job are deleted with them.
+func (s *SQLiteStore) PruneJobExecutions(jobID string, keepLast int, olderThan time.Time) ([]*JobExecution, error) {
+	rows, err := s.db.Query(`
+		SELECT id, job_id, session_id, status, output, output_path, error, started_at, finished_at
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (ORDER BY started_at DESC) AS exec_rank
+			FROM job_executions
+			WHERE job_id = ?
+		)
+		WHERE exec_rank > ? AND status NOT IN ('running', 'waiting_input') AND started_at < ?
+		ORDER BY started_at ASC
+	`, jobID, keepLast, olderThan)
+	if err != nil {
+		return nil, err
+	}
+	pruned, err := scanSQLiteJobExecutions(rows)
+	if err != nil || len(pruned) == 0 {
+		return nil, err
+	}
+
+	var sessionIDs, outputs []string
+	var attachments []*Attachment
+	for _, exec := range pruned {
+		if exec.OutputPath != "" {
+			outputs = append(outputs, exec.OutputPath)
+		}
+		if exec.SessionID == "" {
+			continue
+		}
+		var owned int
+		if err := s.db.QueryRow("SELECT COUNT(*) FROM sessions WHERE id = ? AND job_id = ?", exec.SessionID, jobID).Scan(&owned); err != nil {
+			return nil, err
+		}
+		if owned == 0 {
+			continue
+		}
+		sessionIDs = append(sessionIDs, exec.SessionID)
+		sessionAttachments, err := s.ListAttachments(exec.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, sessionAttachments...)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, exec := range pruned {
+		if _, err := tx.Exec("DELETE FROM job_executions WHERE id = ?", exec.ID); err != nil {
+			return nil, fmt.Errorf("failed to delete execution %s: %w", exec.ID, err)
+		}
+	}
+	for _, id := range sessionIDs {
+		if err := deleteSessionTx(tx, id); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	removeAttachmentFiles(attachments)
+	removeExecutionOutputs(outputs)
+	removeUndoBackups(s.dataPath, sessionIDs...)
+	return pruned, nil
+}
+
+func scanSQLiteJobExecutions(rows *sql.Rows) ([]*JobExecution, error) {
 	defer rows.Close()
 
 	var executions []*JobExecution
@@ -888,7 +1242,7 @@ func (s *SQLiteStore) ListJobExecutions(jobID string, limit int) ([]*JobExecutio
 		var finishedAt sql.NullTime
 		var output, execError sql.NullString
 
-		err := rows.Scan(&exec.ID, &exec.JobID, &sessionID, &exec.Status, &output, &execError, &exec.StartedAt, &finishedAt)
+		err := rows.Scan(&exec.ID, &exec.JobID, &sessionID, &exec.Status, &output, &exec.OutputPath, &execError, &exec.StartedAt, &finishedAt)
 		if err != nil {
 			return nil, err
 		}
@@ -909,7 +1263,7 @@ func (s *SQLiteStore) ListJobExecutions(jobID string, limit int) ([]*JobExecutio
 		executions = append(executions, &exec)
 	}
 
-	return executions, nil
+	return executions, rows.Err()
 }
 
 // GetSettings returns all app settings as key/value pairs.
@@ -969,7 +1323,7 @@ func (s *SQLiteStore) SaveIntegration(integration *Integration) error {
 		integration.Config = map[string]string{}
 	}
 
-	configJSON, err := json.Marshal(integration.Config)
+	configJSON, err := encodeIntegrationConfig(s.secrets, integration.Config)
 	if err != nil {
 		return fmt.Errorf("failed to encode integration config: %w", err)
 	}
@@ -984,7 +1338,7 @@ func (s *SQLiteStore) SaveIntegration(integration *Integration) error {
 			enabled = excluded.enabled,
 			config = excluded.config,
 			updated_at = excluded.updated_at
-	`, integration.ID, integration.Provider, integration.Name, integration.Mode, integration.Enabled, string(configJSON), integration.CreatedAt, integration.UpdatedAt)
+	`, integration.ID, integration.Provider, integration.Name, integration.Mode, integration.Enabled, configJSON, integration.CreatedAt, integration.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to save integration: %w", err)
 	}
@@ -1020,13 +1374,8 @@ func (s *SQLiteStore) GetIntegration(id string) (*Integration, error) {
 	}
 
 	integration.Enabled = enabled == 1
-	if configJSON != "" {
-		if err := json.Unmarshal([]byte(configJSON), &integration.Config); err != nil {
-			return nil, fmt.Errorf("failed to decode integration config: %w", err)
-		}
-	}
-	if integration.Config == nil {
-		integration.Config = map[string]string{}
+	if integration.Config, err = decodeIntegrationConfig(s.secrets, configJSON); err != nil {
+		return nil, fmt.Errorf("failed to decode integration config: %w", err)
 	}
 
 	return &integration, nil
@@ -1063,14 +1412,11 @@ func (s *SQLiteStore) ListIntegrations() ([]*Integration, error) {
 		}
 
 		integration.Enabled = enabled == 1
-		if configJSON != "" {
-			if err := json.Unmarshal([]byte(configJSON), &integration.Config); err != nil {
-				return nil, fmt.Errorf("failed to decode integration config: %w", err)
-			}
-		}
-		if integration.Config == nil {
-			integration.Config = map[string]string{}
+		config, err := decodeIntegrationConfig(s.secrets, configJSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode integration config: %w", err)
 		}
+		integration.Config = config
 
 		integrations = append(integrations, &integration)
 	}
@@ -1080,10 +1426,53 @@ func (s *SQLiteStore) ListIntegrations() ([]*Integration, error) {
 
 // DeleteIntegration deletes an integration by id.
 func (s *SQLiteStore) DeleteIntegration(id string) error {
-	_, err := s.db.Exec(`DELETE FROM integrations WHERE id = ?`, id)
+	if _, err := s.db.Exec(`DELETE FROM integrations WHERE id = ?`, id); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM webhook_deliveries WHERE integration_id = ?`, id)
+	return err
+}
+
+// SaveWebhookDelivery records a delivery and prunes the integration's log to
+// its newest MaxWebhookDeliveries entries.
+func (s *SQLiteStore) SaveWebhookDelivery(delivery *WebhookDelivery) error {
+	if _, err := s.db.Exec(`
+		INSERT INTO webhook_deliveries (id, integration_id, event, status, attempts, response_code, error, payload, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, delivery.ID, delivery.IntegrationID, delivery.Event, delivery.Status, delivery.Attempts, delivery.ResponseCode, delivery.Error, delivery.Payload, delivery.CreatedAt); err != nil {
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+	_, err := s.db.Exec(`
+		DELETE FROM webhook_deliveries WHERE integration_id = ? AND id NOT IN (
+			SELECT id FROM webhook_deliveries WHERE integration_id = ? ORDER BY created_at DESC LIMIT ?
+		)
+	`, delivery.IntegrationID, delivery.IntegrationID, MaxWebhookDeliveries)
 	return err
 }
 
+// ListWebhookDeliveries returns an integration's newest deliveries first.
+func (s *SQLiteStore) ListWebhookDeliveries(integrationID string, limit int) ([]*WebhookDelivery, error) {
+	rows, err := s.db.Query(`
+		SELECT id, integration_id, event, status, attempts, response_code, error, payload, created_at
+		FROM webhook_deliveries WHERE integration_id = ?
+		ORDER BY created_at DESC LIMIT ?
+	`, integrationID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []*WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.IntegrationID, &d.Event, &d.Status, &d.Attempts, &d.ResponseCode, &d.Error, &d.Payload, &d.CreatedAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, &d)
+	}
+	return deliveries, rows.Err()
+}
+
 // SaveMCPServer saves an MCP server to the database.
 func (s *SQLiteStore) SaveMCPServer(server *MCPServer) error {
 	if server.Config == nil {
@@ -1383,5 +1772,126 @@ func (s *SQLiteStore) DeleteSubAgent(id string) error {
 	return err
 }
 
+// SaveToolOutput stores the full output of a truncated tool result.
+func (s *SQLiteStore) SaveToolOutput(output *ToolOutput) error {
+	_, err := s.db.Exec(`
+		INSERT INTO tool_outputs (id, session_id, tool_call_id, tool_name, content, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, output.ID, output.SessionID, output.ToolCallID, output.ToolName, output.Content, output.CreatedAt)
+	return err
+}
+
+// GetToolOutput retrieves a stored tool output by ID.
+func (s *SQLiteStore) GetToolOutput(id string) (*ToolOutput, error) {
+	var output ToolOutput
+	err := s.db.QueryRow(`
+		SELECT id, session_id, tool_call_id, tool_name, content, created_at
+		FROM tool_outputs WHERE id = ?
+	`, id).Scan(&output.ID, &output.SessionID, &output.ToolCallID, &output.ToolName, &output.Content, &output.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("tool output not found: %s", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// SaveAttachment records a file uploaded to a session.
+func (s *SQLiteStore) SaveAttachment(att *Attachment) error {
+	_, err := s.db.Exec(`
+		INSERT INTO attachments (id, session_id, name, media_type, size, path, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, att.ID, att.SessionID, att.Name, att.MediaType, att.Size, att.Path, att.CreatedAt)
+	return err
+}
+
+// GetAttachment retrieves an attachment by ID.
+func (s *SQLiteStore) GetAttachment(id string) (*Attachment, error) {
+	att, err := scanAttachment(s.db.QueryRow(`
+		SELECT id, session_id, name, media_type, size, path, created_at
+		FROM attachments WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("attachment not found: %s", id)
+	}
+	return att, err
+}
+
+// ListAttachments returns the attachments of a session, oldest first.
+func (s *SQLiteStore) ListAttachments(sessionID string) ([]*Attachment, error) {
+	rows, err := s.db.Query(`
+		SELECT id, session_id, name, media_type, size, path, created_at
+		FROM attachments WHERE session_id = ? ORDER BY created_at, id
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return scanAttachments(rows)
+}
+
+// SaveMemory inserts a memory or replaces the value of an existing one.
+func (s *SQLiteStore) SaveMemory(mem *Memory) error {
+	_, err := s.db.Exec(`
+		INSERT INTO memories (scope, key, value, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (scope, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, mem.Scope, mem.Key, mem.Value, mem.CreatedAt, mem.UpdatedAt)
+	return err
+}
+
+// GetMemory retrieves a memory by scope and key.
+func (s *SQLiteStore) GetMemory(scope, key string) (*Memory, error) {
+	var mem Memory
+	err := s.db.QueryRow(`
+		SELECT scope, key, value, created_at, updated_at
+		FROM memories WHERE scope = ? AND key = ?
+	`, scope, key).Scan(&mem.Scope, &mem.Key, &mem.Value, &mem.CreatedAt, &mem.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("memory not found: %s/%s", scope, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &mem, nil
+}
+
+// ListMemories returns the memories of a scope, or of every scope when scope
+// is empty, ordered by scope and key.
+func (s *SQLiteStore) ListMemories(scope string) ([]*Memory, error) {
+	query := `SELECT scope, key, value, created_at, updated_at FROM memories`
+	var args []interface{}
+	if scope != "" {
+		query += ` WHERE scope = ?`
+		args = append(args, scope)
+	}
+	rows, err := s.db.Query(query+` ORDER BY scope, key`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var memories []*Memory
+	for rows.Next() {
+		var mem Memory
+		if err := rows.Scan(&mem.Scope, &mem.Key, &mem.Value, &mem.CreatedAt, &mem.UpdatedAt); err != nil {
+			return nil, err
+		}
+		memories = append(memories, &mem)
+	}
+	return memories, rows.Err()
+}
+
+// DeleteMemories deletes one memory, or every memory of scope when key is
+// empty.
+func (s *SQLiteStore) DeleteMemories(scope, key string) error {
+	if key == "" {
+		_, err := s.db.Exec(`DELETE FROM memories WHERE scope = ?`, scope)
+		return err
+	}
+	_, err := s.db.Exec(`DELETE FROM memories WHERE scope = ? AND key = ?`, scope, key)
+	return err
+}
+
 // Ensure SQLiteStore implements Store
 var _ Store = (*SQLiteStore)(nil)
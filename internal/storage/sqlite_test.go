@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStoreAt(t *testing.T, dir string) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestSQLiteDeleteSessionLeavesNoRows(t *testing.T) {
+	store := newTestSQLiteStoreAt(t, t.TempDir())
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, id := range []string{"sess-gone", "sess-kept"} {
+		sess := &Session{ID: id, AgentID: "build", Status: "completed", CreatedAt: now, UpdatedAt: now, Messages: []Message{
+			{ID: id + "-msg-1", Role: "user", Content: "hello", Timestamp: now},
+			{ID: id + "-msg-2", Role: "assistant", Content: "hi", Timestamp: now},
+		}}
+		if err := store.SaveSession(sess); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+		if err := store.SaveJobExecution(&JobExecution{ID: id + "-exec", JobID: "job-1", SessionID: id, Status: "success", StartedAt: now}); err != nil {
+			t.Fatalf("SaveJobExecution: %v", err)
+		}
+	}
+
+	if err := store.DeleteSession("sess-gone"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	counts := []struct {
+		table, sessionID string
+		want             int
+	}{
+		{"messages", "sess-gone", 0},
+		{"messages", "sess-kept", 2},
+		{"job_executions", "sess-gone", 0},
+		{"job_executions", "sess-kept", 1},
+	}
+	for _, c := range counts {
+		var got int
+		if err := store.db.QueryRow("SELECT COUNT(*) FROM "+c.table+" WHERE session_id = ?", c.sessionID).Scan(&got); err != nil {
+			t.Fatalf("count %s: %v", c.table, err)
+		}
+		if got != c.want {
+			t.Fatalf("%s rows for %s = %d, want %d", c.table, c.sessionID, got, c.want)
+		}
+	}
+}
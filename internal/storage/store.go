@@ -2,9 +2,16 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// ErrStaleSession is returned by SaveSession when the stored session has been
+// saved by another writer since the caller loaded it.
+var ErrStaleSession = errors.New("session was modified concurrently")
+
 // Session represents a stored session (storage layer copy to avoid import cycle)
 type Session struct {
 	ID           string
@@ -19,6 +26,10 @@ type Session struct {
 	TaskProgress string // Temporary task planning and progress tracking
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
+	// Version is the stored revision the caller last saw. SaveSession only
+	// overwrites a row still at this version and then sets it to the new
+	// revision; zero skips the check (new sessions).
+	Version int64
 }
 
 // Message represents a stored message
@@ -37,12 +48,22 @@ type RecurringJob struct {
 	ID               string
 	Name             string
 	ScheduleHuman    string // Human-readable schedule (e.g., "every Monday at 9am")
-	ScheduleCron     string // Parsed cron expression (e.g., "0 9 * * 1")
+	ScheduleCron     string // Parsed cron expression (e.g., "0 9 * * 1"); empty for one-shot jobs
 	TaskPrompt       string // The actual task instructions for the agent
 	TaskPromptSource string // "text" | "file"
 	TaskPromptFile   string // Absolute path when TaskPromptSource is "file"
 	LLMProvider      string // Optional provider override for this job
+	Timezone         string // IANA zone the schedule is read in; empty means the server's zone
+	TimeoutMinutes   int    // Run time limit; 0 uses the default
+	KeepExecutions   int    // Executions kept by pruning; 0 uses the default
+	FailOnQuestion   bool   // Fail runs whose agent asks a question instead of waiting for an answer
+	Model            string // Optional model override for this job
+	AgentID          string // Agent type to run as; empty uses "job-runner"
 	Enabled          bool
+	RunAt            *time.Time // When a one-shot job runs; it is disabled afterwards
+	NotifyOn         string     // "failure" | "success" | "always"; empty sends nothing
+	NotifyTargets    []string   // Integration IDs that receive run notifications
+	ResponseSchema   string     // JSON Schema the final answer must match; empty allows prose
 	LastRunAt        *time.Time
 	NextRunAt        *time.Time
 	CreatedAt        time.Time
@@ -54,8 +75,9 @@ type JobExecution struct {
 	ID         string
 	JobID      string
 	SessionID  string // Reference to the agent session created for this execution
-	Status     string // "running", "success", "failed"
+	Status     string // "running", "waiting_input", "success", "failed", "cancelled", "timed_out"
 	Output     string // Summary of what the agent did
+	OutputPath string // File holding the full output; see ExecutionOutputPath
 	Error      string // Error message if failed
 	StartedAt  time.Time
 	FinishedAt *time.Time
@@ -73,6 +95,47 @@ type Integration struct {
 	UpdatedAt time.Time
 }
 
+// IsSecretConfigKey reports whether an integration config field looks like a
+// credential.
+func IsSecretConfigKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range []string{"token", "key", "secret", "password"} {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// SecretValues returns the non-empty config values of i stored under
+// credential-like keys.
+func (i *Integration) SecretValues() []string {
+	var secrets []string
+	for key, value := range i.Config {
+		if value = strings.TrimSpace(value); value != "" && IsSecretConfigKey(key) {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
+
+// WebhookDelivery records one event sent to a webhook integration, after all
+// of its attempts.
+type WebhookDelivery struct {
+	ID            string
+	IntegrationID string
+	Event         string
+	Status        string // "delivered" | "failed"
+	Attempts      int
+	ResponseCode  int // Last HTTP status; 0 when no response arrived
+	Error         string
+	Payload       string
+	CreatedAt     time.Time
+}
+
+// MaxWebhookDeliveries is how many deliveries are kept per integration.
+const MaxWebhookDeliveries = 200
+
 // MCPServer represents a configured MCP server endpoint.
 type MCPServer struct {
 	ID                  string
@@ -111,6 +174,85 @@ type Project struct {
 	UpdatedAt time.Time
 }
 
+// ToolOutput is the full output of a tool call whose result was truncated
+// before it entered the conversation.
+type ToolOutput struct {
+	ID         string
+	SessionID  string
+	ToolCallID string
+	ToolName   string
+	Content    string
+	CreatedAt  time.Time
+}
+
+// Attachment is a file uploaded to a session. The bytes live on disk at Path;
+// the file is removed together with its session.
+type Attachment struct {
+	ID        string
+	SessionID string
+	Name      string
+	MediaType string
+	Size      int64
+	Path      string
+	CreatedAt time.Time
+}
+
+// Memory is a note agents keep across runs, addressed by scope and key.
+// Scope is MemoryScopeGlobal or the scope of one job (see MemoryJobScope).
+type Memory struct {
+	Scope     string
+	Key       string
+	Value     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// MemoryScopeGlobal holds memories shared by every agent run.
+const MemoryScopeGlobal = "global"
+
+// MemoryJobScope returns the memory scope of a recurring job.
+func MemoryJobScope(jobID string) string {
+	return "job:" + jobID
+}
+
+// joinJobIDs stores a job's ID list in a single comma-separated column.
+func joinJobIDs(ids []string) string {
+	return strings.Join(ids, ",")
+}
+
+func splitJobIDs(value string) []string {
+	var ids []string
+	for _, id := range strings.Split(value, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// DefaultSessionPageSize is the page size used by ListSessionsPage when the
+// filter does not set a limit.
+const DefaultSessionPageSize = 200
+
+// SessionFilter narrows and pages the session list. Zero values mean "no filter".
+type SessionFilter struct {
+	Status          string
+	AgentID         string
+	ExcludeAgentIDs []string // Agent IDs whose sessions are left out
+	CreatedAfter    *time.Time
+	MetadataFlags   []string // Metadata keys that must be set to true
+	OrderByUpdated  bool     // Newest updated_at first instead of newest created_at
+	Limit           int      // 0 uses DefaultSessionPageSize; negative means unbounded
+	Offset          int
+}
+
+// PurgeOptions tunes PurgeSessions beyond the age and status cutoffs.
+type PurgeOptions struct {
+	KeepLastPerJob int  // Newest sessions per job that are always kept
+	IncludeNonJob  bool // Also purge interactive sessions (by default only job sessions are purged)
+	DryRun         bool // Report matching sessions without deleting them
+}
+
 // Store defines the interface for session storage
 type Store interface {
 	// Session operations
@@ -119,6 +261,12 @@ type Store interface {
 	ListSessions() ([]*Session, error)                  // Returns only non-job sessions
 	ListSessionsByJob(jobID string) ([]*Session, error) // Returns sessions for a specific job
 	DeleteSession(id string) error
+	// ListSessionsPage returns a filtered page of ListSessions plus the total match count.
+	ListSessionsPage(filter SessionFilter) ([]*Session, int, error)
+	// PurgeSessions deletes sessions last updated before olderThan whose status is
+	// not excluded, along with their messages and job executions. It returns the
+	// purged sessions without messages.
+	PurgeSessions(olderThan time.Time, excludeStatuses []string, opts PurgeOptions) ([]*Session, error)
 
 	// Project operations
 	SaveProject(project *Project) error
@@ -137,6 +285,14 @@ type Store interface {
 	SaveJobExecution(exec *JobExecution) error
 	GetJobExecution(id string) (*JobExecution, error)
 	ListJobExecutions(jobID string, limit int) ([]*JobExecution, error)
+	ListRunningJobExecutions(startedBefore time.Time) ([]*JobExecution, error)
+	// ListWaitingJobExecutions returns the executions waiting for an answer
+	// to a question, newest first.
+	ListWaitingJobExecutions() ([]*JobExecution, error)
+	// PruneJobExecutions deletes the executions of a job beyond its newest
+	// keepLast that started before olderThan, never running or waiting_input
+	// ones, along with their job-run sessions. It returns the deleted executions.
+	PruneJobExecutions(jobID string, keepLast int, olderThan time.Time) ([]*JobExecution, error)
 
 	// Settings operations
 	GetSettings() (map[string]string, error)
@@ -146,7 +302,11 @@ type Store interface {
 	SaveIntegration(integration *Integration) error
 	GetIntegration(id string) (*Integration, error)
 	ListIntegrations() ([]*Integration, error)
-	DeleteIntegration(id string) error
+	DeleteIntegration(id string) error // Also deletes its webhook deliveries
+
+	// Webhook delivery log, newest first; saving prunes beyond MaxWebhookDeliveries
+	SaveWebhookDelivery(delivery *WebhookDelivery) error
+	ListWebhookDeliveries(integrationID string, limit int) ([]*WebhookDelivery, error)
 
 	// MCP server operations
 	SaveMCPServer(server *MCPServer) error
@@ -160,6 +320,44 @@ type Store interface {
 	ListSubAgents() ([]*SubAgent, error)
 	DeleteSubAgent(id string) error
 
+	// Tool output operations (deleted together with their session)
+	SaveToolOutput(output *ToolOutput) error
+	GetToolOutput(id string) (*ToolOutput, error)
+
+	// Attachment operations (rows and files are deleted together with their session)
+	SaveAttachment(att *Attachment) error
+	GetAttachment(id string) (*Attachment, error)
+	ListAttachments(sessionID string) ([]*Attachment, error) // Oldest first
+
+	// Memory operations
+	SaveMemory(mem *Memory) error // Inserts or replaces the value of scope/key
+	GetMemory(scope, key string) (*Memory, error)
+	ListMemories(scope string) ([]*Memory, error) // Empty scope lists all scopes
+	DeleteMemories(scope, key string) error       // Empty key deletes the whole scope
+
+	// CheckWritable verifies that the database accepts writes, writing a
+	// row in a transaction that is rolled back
+	CheckWritable() error
+
 	// Close closes the store
 	Close() error
 }
+
+// Storage drivers accepted by Open.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Open creates the Store for the configured driver. An empty driver selects
+// SQLite in dataPath; postgres connects to dsn.
+func Open(driver, dsn, dataPath string) (Store, error) {
+	switch strings.ToLower(strings.TrimSpace(driver)) {
+	case "", DriverSQLite:
+		return NewSQLiteStore(dataPath)
+	case DriverPostgres, "postgresql", "pgx":
+		return NewPostgresStore(dsn, dataPath)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q (expected %q or %q)", driver, DriverSQLite, DriverPostgres)
+	}
+}
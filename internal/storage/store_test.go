@@ -0,0 +1,579 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListSessionsPageFiltersAndCounts(t *testing.T) {
+	forEachBackend(t, testListSessionsPageFiltersAndCounts)
+}
+
+func testListSessionsPageFiltersAndCounts(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	base := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		status := "completed"
+		if i%2 == 1 {
+			status = "failed"
+		}
+		agentID := "build"
+		if i == 4 {
+			agentID = "plan"
+		}
+		updatedAt := base.Add(time.Duration(i) * time.Hour)
+		if i == 1 {
+			updatedAt = base.Add(10 * time.Hour)
+		}
+		sess := &Session{
+			ID:        fmt.Sprintf("sess-%d", i),
+			AgentID:   agentID,
+			Status:    status,
+			Metadata:  map[string]interface{}{"a2a_inbound": i == 2},
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+			UpdatedAt: updatedAt,
+		}
+		if err := store.SaveSession(sess); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+
+	page, total, err := store.ListSessionsPage(SessionFilter{Limit: 2, Offset: 1})
+	if err != nil {
+		t.Fatalf("ListSessionsPage: %v", err)
+	}
+	if total != 5 || len(page) != 2 {
+		t.Fatalf("expected 2 of 5, got %d of %d", len(page), total)
+	}
+	if page[0].ID != "sess-3" || page[1].ID != "sess-2" {
+		t.Fatalf("unexpected page order: %s, %s", page[0].ID, page[1].ID)
+	}
+
+	page, total, err = store.ListSessionsPage(SessionFilter{Status: "completed", AgentID: "build"})
+	if err != nil {
+		t.Fatalf("ListSessionsPage: %v", err)
+	}
+	if total != 2 || len(page) != 2 {
+		t.Fatalf("expected 2 completed build sessions, got %d (total %d)", len(page), total)
+	}
+
+	after := base.Add(90 * time.Minute)
+	_, total, err = store.ListSessionsPage(SessionFilter{CreatedAfter: &after})
+	if err != nil {
+		t.Fatalf("ListSessionsPage: %v", err)
+	}
+	if total != 3 {
+		t.Fatalf("expected 3 sessions created after cutoff, got %d", total)
+	}
+
+	page, _, err = store.ListSessionsPage(SessionFilter{MetadataFlags: []string{"a2a_inbound"}})
+	if err != nil {
+		t.Fatalf("ListSessionsPage: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != "sess-2" {
+		t.Fatalf("expected only sess-2 for metadata flag, got %d sessions", len(page))
+	}
+
+	page, total, err = store.ListSessionsPage(SessionFilter{ExcludeAgentIDs: []string{"plan"}, OrderByUpdated: true, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListSessionsPage: %v", err)
+	}
+	if total != 4 || len(page) != 2 || page[0].ID != "sess-1" || page[1].ID != "sess-3" {
+		t.Fatalf("expected sess-1, sess-3 of 4 by update time, got %d of %d", len(page), total)
+	}
+}
+
+func TestPurgeSessionsKeepsRecentAndProtected(t *testing.T) {
+	forEachBackend(t, testPurgeSessionsKeepsRecentAndProtected)
+}
+
+func testPurgeSessionsKeepsRecentAndProtected(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	old := time.Now().Add(-60 * 24 * time.Hour)
+	jobID := "job-1"
+	if err := store.SaveJob(&RecurringJob{ID: jobID, Name: "nightly", ScheduleHuman: "every day", ScheduleCron: "0 0 * * *", TaskPrompt: "report", TaskPromptSource: "text", Enabled: true, CreatedAt: old, UpdatedAt: old}); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	sessions := []struct {
+		id     string
+		jobID  *string
+		status string
+		age    time.Duration
+	}{
+		{"job-old-1", &jobID, "completed", 0},
+		{"job-old-2", &jobID, "completed", time.Hour},
+		{"job-old-3", &jobID, "completed", 2 * time.Hour},
+		{"job-running", &jobID, "running", -time.Hour},
+		{"manual-old", nil, "completed", 0},
+	}
+	for _, s := range sessions {
+		ts := old.Add(s.age)
+		if err := store.SaveSession(&Session{ID: s.id, AgentID: "build", JobID: s.jobID, Status: s.status, CreatedAt: ts, UpdatedAt: ts}); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+	outputPath := ExecutionOutputPath(t.TempDir(), "exec-1")
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(outputPath, []byte("full report"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := store.SaveJobExecution(&JobExecution{ID: "exec-1", JobID: jobID, SessionID: "job-old-1", Status: "success", Output: "full...", OutputPath: outputPath, StartedAt: old}); err != nil {
+		t.Fatalf("SaveJobExecution: %v", err)
+	}
+	if exec, err := store.GetJobExecution("exec-1"); err != nil || exec.OutputPath != outputPath {
+		t.Fatalf("expected the output path to be stored, got %+v (err=%v)", exec, err)
+	}
+
+	cutoff := time.Now().Add(-30 * 24 * time.Hour)
+	preview, err := store.PurgeSessions(cutoff, []string{"running"}, PurgeOptions{KeepLastPerJob: 2, DryRun: true})
+	if err != nil {
+		t.Fatalf("PurgeSessions dry run: %v", err)
+	}
+	if len(preview) != 1 || preview[0].ID != "job-old-1" {
+		ids := make([]string, len(preview))
+		for i, p := range preview {
+			ids[i] = p.ID
+		}
+		t.Fatalf("unexpected dry-run candidates: %v", ids)
+	}
+	if _, err := store.GetSession("job-old-1"); err != nil {
+		t.Fatalf("dry run must not delete: %v", err)
+	}
+
+	purged, err := store.PurgeSessions(cutoff, []string{"running"}, PurgeOptions{})
+	if err != nil {
+		t.Fatalf("PurgeSessions: %v", err)
+	}
+	if len(purged) != 3 {
+		t.Fatalf("expected 3 purged job sessions, got %d", len(purged))
+	}
+	for _, id := range []string{"job-running", "manual-old"} {
+		if _, err := store.GetSession(id); err != nil {
+			t.Fatalf("session %s should survive: %v", id, err)
+		}
+	}
+	if _, err := store.GetJobExecution("exec-1"); err == nil {
+		t.Fatal("expected execution of purged session to be deleted")
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the output file of the purged execution to be removed, stat err=%v", err)
+	}
+}
+
+func TestSaveSessionRejectsStaleVersion(t *testing.T) {
+	forEachBackend(t, testSaveSessionRejectsStaleVersion)
+}
+
+func testSaveSessionRejectsStaleVersion(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now()
+	sess := &Session{ID: "sess-v", AgentID: "build", Status: "running", CreatedAt: now, UpdatedAt: now}
+	if err := store.SaveSession(sess); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	if sess.Version == 0 {
+		t.Fatal("expected version to be assigned on insert")
+	}
+
+	first, _ := store.GetSession("sess-v")
+	second, _ := store.GetSession("sess-v")
+	first.Status = "completed"
+	if err := store.SaveSession(first); err != nil {
+		t.Fatalf("SaveSession first: %v", err)
+	}
+	second.Status = "failed"
+	if err := store.SaveSession(second); !errors.Is(err, ErrStaleSession) {
+		t.Fatalf("expected ErrStaleSession, got %v", err)
+	}
+
+	got, _ := store.GetSession("sess-v")
+	if got.Status != "completed" || got.Version != first.Version {
+		t.Fatalf("unexpected stored session: status=%s version=%d", got.Status, got.Version)
+	}
+}
+
+func TestConcurrentSaveAndGetAcrossConnections(t *testing.T) {
+	forEachBackend(t, testConcurrentSaveAndGetAcrossConnections)
+}
+
+func testConcurrentSaveAndGetAcrossConnections(t *testing.T, open func(t *testing.T) Store) {
+	// Two stores on one database stand in for the server and scheduler (or a
+	// CLI process) writing at the same time.
+	stores := []Store{open(t), open(t)}
+
+	const workers = 8
+	const iterations = 25
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			store := stores[w%len(stores)]
+			now := time.Now()
+			sess := &Session{ID: fmt.Sprintf("sess-%d", w), AgentID: "build", Status: "running", CreatedAt: now, UpdatedAt: now}
+			for i := 0; i < iterations; i++ {
+				sess.Messages = append(sess.Messages, Message{
+					ID:        fmt.Sprintf("msg-%d-%d", w, i),
+					Role:      "user",
+					Content:   "hello",
+					Timestamp: now.Add(time.Duration(i) * time.Millisecond),
+				})
+				if err := store.SaveSession(sess); err != nil {
+					errs <- fmt.Errorf("worker %d save %d: %w", w, i, err)
+					return
+				}
+				got, err := store.GetSession(sess.ID)
+				if err != nil {
+					errs <- fmt.Errorf("worker %d get %d: %w", w, i, err)
+					return
+				}
+				if len(got.Messages) != i+1 {
+					errs <- fmt.Errorf("worker %d: expected %d messages, got %d", w, i+1, len(got.Messages))
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestToolOutputRoundTripAndSessionDelete(t *testing.T) {
+	forEachBackend(t, testToolOutputRoundTripAndSessionDelete)
+}
+
+func testToolOutputRoundTripAndSessionDelete(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := store.SaveSession(&Session{ID: "sess-out", AgentID: "build", Status: "running", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	content := strings.Repeat("line of output\n", 1000)
+	if err := store.SaveToolOutput(&ToolOutput{ID: "out-1", SessionID: "sess-out", ToolCallID: "call-1", ToolName: "bash", Content: content, CreatedAt: now}); err != nil {
+		t.Fatalf("SaveToolOutput: %v", err)
+	}
+
+	got, err := store.GetToolOutput("out-1")
+	if err != nil {
+		t.Fatalf("GetToolOutput: %v", err)
+	}
+	if got.SessionID != "sess-out" || got.ToolCallID != "call-1" || got.ToolName != "bash" || got.Content != content {
+		t.Fatalf("unexpected tool output: %+v", got)
+	}
+
+	if err := store.DeleteSession("sess-out"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := store.GetToolOutput("out-1"); err == nil {
+		t.Fatal("tool output should be deleted with its session")
+	}
+}
+
+func TestAttachmentsListedAndDeletedWithSession(t *testing.T) {
+	forEachBackend(t, testAttachmentsListedAndDeletedWithSession)
+}
+
+func testAttachmentsListedAndDeletedWithSession(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := store.SaveSession(&Session{ID: "sess-att", AgentID: "build", Status: "running", CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveSession: %v", err)
+	}
+	dir := AttachmentDir(t.TempDir(), "sess-att")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for i, name := range []string{"notes.txt", "photo.png"} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(name), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		att := &Attachment{ID: fmt.Sprintf("att-%d", i), SessionID: "sess-att", Name: name, MediaType: "text/plain", Size: int64(len(name)), Path: path, CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := store.SaveAttachment(att); err != nil {
+			t.Fatalf("SaveAttachment: %v", err)
+		}
+	}
+
+	list, err := store.ListAttachments("sess-att")
+	if err != nil {
+		t.Fatalf("ListAttachments: %v", err)
+	}
+	if len(list) != 2 || list[0].Name != "notes.txt" || list[1].Name != "photo.png" || list[1].Size != 9 {
+		t.Fatalf("unexpected attachments: %+v", list)
+	}
+	got, err := store.GetAttachment("att-1")
+	if err != nil {
+		t.Fatalf("GetAttachment: %v", err)
+	}
+	if got.Path != filepath.Join(dir, "photo.png") || got.SessionID != "sess-att" {
+		t.Fatalf("unexpected attachment: %+v", got)
+	}
+
+	if err := store.DeleteSession("sess-att"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := store.GetAttachment("att-1"); err == nil {
+		t.Fatal("attachment should be deleted with its session")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("attachment folder should be removed with its session, stat err=%v", err)
+	}
+}
+
+func TestUndoBackupsDeletedWithSession(t *testing.T) {
+	forEachBackend(t, testUndoBackupsDeletedWithSession)
+}
+
+func testUndoBackupsDeletedWithSession(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	var dataPath string
+	switch s := store.(type) {
+	case *SQLiteStore:
+		dataPath = s.dataPath
+	case *PostgresStore:
+		dataPath = s.dataPath
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, id := range []string{"sess-undo", "sess-kept"} {
+		if err := store.SaveSession(&Session{ID: id, AgentID: "build", Status: "completed", CreatedAt: now, UpdatedAt: now}); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+		if err := os.MkdirAll(UndoDir(dataPath, id), 0o755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(UndoDir(dataPath, id), "manifest.json"), []byte("{}"), 0o644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+
+	if err := store.DeleteSession("sess-undo"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err := os.Stat(UndoDir(dataPath, "sess-undo")); !os.IsNotExist(err) {
+		t.Fatalf("undo folder should be removed with its session, stat err=%v", err)
+	}
+	if _, err := os.Stat(UndoDir(dataPath, "sess-kept")); err != nil {
+		t.Fatalf("undo folder of another session should survive: %v", err)
+	}
+
+	if _, err := store.PurgeSessions(now.Add(time.Hour), nil, PurgeOptions{IncludeNonJob: true}); err != nil {
+		t.Fatalf("PurgeSessions: %v", err)
+	}
+	if _, err := os.Stat(UndoDir(dataPath, "sess-kept")); !os.IsNotExist(err) {
+		t.Fatalf("undo folder should be removed with a purged session, stat err=%v", err)
+	}
+}
+
+func TestMemoriesUpsertListAndDelete(t *testing.T) {
+	forEachBackend(t, testMemoriesUpsertListAndDelete)
+}
+
+func testMemoriesUpsertListAndDelete(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now().UTC().Truncate(time.Second)
+	jobScope := MemoryJobScope("job-1")
+	for _, mem := range []*Memory{
+		{Scope: jobScope, Key: "last_status", Value: "200", CreatedAt: now, UpdatedAt: now},
+		{Scope: jobScope, Key: "headline", Value: "old", CreatedAt: now, UpdatedAt: now},
+		{Scope: MemoryScopeGlobal, Key: "owner", Value: "ops", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := store.SaveMemory(mem); err != nil {
+			t.Fatalf("SaveMemory: %v", err)
+		}
+	}
+
+	later := now.Add(time.Hour)
+	if err := store.SaveMemory(&Memory{Scope: jobScope, Key: "headline", Value: "new", CreatedAt: later, UpdatedAt: later}); err != nil {
+		t.Fatalf("SaveMemory (update): %v", err)
+	}
+	got, err := store.GetMemory(jobScope, "headline")
+	if err != nil {
+		t.Fatalf("GetMemory: %v", err)
+	}
+	if got.Value != "new" || !got.CreatedAt.Equal(now) || !got.UpdatedAt.Equal(later) {
+		t.Fatalf("update should replace the value and keep created_at: %+v", got)
+	}
+
+	jobMemories, err := store.ListMemories(jobScope)
+	if err != nil {
+		t.Fatalf("ListMemories: %v", err)
+	}
+	if len(jobMemories) != 2 || jobMemories[0].Key != "headline" || jobMemories[1].Key != "last_status" {
+		t.Fatalf("unexpected job memories: %+v", jobMemories)
+	}
+	if all, _ := store.ListMemories(""); len(all) != 3 {
+		t.Fatalf("expected 3 memories across scopes, got %d", len(all))
+	}
+
+	if err := store.DeleteMemories(jobScope, "headline"); err != nil {
+		t.Fatalf("DeleteMemories (key): %v", err)
+	}
+	if _, err := store.GetMemory(jobScope, "headline"); err == nil {
+		t.Fatal("deleted memory should be gone")
+	}
+	if err := store.DeleteMemories(jobScope, ""); err != nil {
+		t.Fatalf("DeleteMemories (scope): %v", err)
+	}
+	if all, _ := store.ListMemories(""); len(all) != 1 || all[0].Scope != MemoryScopeGlobal {
+		t.Fatalf("only the global memory should remain: %+v", all)
+	}
+}
+
+func TestListRunningJobExecutions(t *testing.T) {
+	forEachBackend(t, testListRunningJobExecutions)
+}
+
+func testListRunningJobExecutions(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now()
+	if err := store.SaveJob(&RecurringJob{ID: "job-1", Name: "hourly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "x", TaskPromptSource: "text", Timezone: "Europe/Berlin", Enabled: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	if job, err := store.GetJob("job-1"); err != nil || job.Timezone != "Europe/Berlin" {
+		t.Fatalf("GetJob: %+v, %v", job, err)
+	}
+	for _, exec := range []*JobExecution{
+		{ID: "old-running", JobID: "job-1", Status: "running", StartedAt: now.Add(-2 * time.Hour)},
+		{ID: "new-running", JobID: "job-1", Status: "running", StartedAt: now.Add(-time.Minute)},
+		{ID: "old-done", JobID: "job-1", Status: "success", StartedAt: now.Add(-3 * time.Hour)},
+		{ID: "waiting", JobID: "job-1", Status: "waiting_input", StartedAt: now.Add(-4 * time.Hour)},
+	} {
+		if err := store.SaveJobExecution(exec); err != nil {
+			t.Fatalf("SaveJobExecution: %v", err)
+		}
+	}
+
+	running, err := store.ListRunningJobExecutions(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("ListRunningJobExecutions: %v", err)
+	}
+	if len(running) != 1 || running[0].ID != "old-running" {
+		t.Fatalf("expected only the stale running execution, got %+v", running)
+	}
+	waiting, err := store.ListWaitingJobExecutions()
+	if err != nil || len(waiting) != 1 || waiting[0].ID != "waiting" {
+		t.Fatalf("expected only the waiting execution, got %+v, %v", waiting, err)
+	}
+}
+
+func TestPruneJobExecutions(t *testing.T) {
+	forEachBackend(t, testPruneJobExecutions)
+}
+
+func testPruneJobExecutions(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now()
+	jobID := "job-1"
+	if err := store.SaveJob(&RecurringJob{ID: jobID, Name: "hourly", ScheduleHuman: "every hour", ScheduleCron: "0 * * * *", TaskPrompt: "x", TaskPromptSource: "text", Enabled: true, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveJob: %v", err)
+	}
+	for _, sess := range []*Session{
+		{ID: "run-1", AgentID: "build", JobID: &jobID, Status: "completed", CreatedAt: now, UpdatedAt: now},
+		{ID: "run-2", AgentID: "build", JobID: &jobID, Status: "completed", CreatedAt: now, UpdatedAt: now},
+		{ID: "manual", AgentID: "build", Status: "completed", CreatedAt: now, UpdatedAt: now},
+	} {
+		if err := store.SaveSession(sess); err != nil {
+			t.Fatalf("SaveSession: %v", err)
+		}
+	}
+	for _, exec := range []*JobExecution{
+		{ID: "exec-1", JobID: jobID, SessionID: "run-1", Status: "success", StartedAt: now.Add(-5 * time.Hour)},
+		{ID: "exec-2", JobID: jobID, SessionID: "manual", Status: "failed", StartedAt: now.Add(-4 * time.Hour)},
+		{ID: "exec-3", JobID: jobID, Status: "running", StartedAt: now.Add(-3 * time.Hour)},
+		{ID: "exec-4", JobID: jobID, SessionID: "run-2", Status: "success", StartedAt: now.Add(-2 * time.Hour)},
+		{ID: "exec-5", JobID: jobID, Status: "success", StartedAt: now.Add(-time.Hour)},
+	} {
+		if err := store.SaveJobExecution(exec); err != nil {
+			t.Fatalf("SaveJobExecution: %v", err)
+		}
+	}
+
+	pruned, err := store.PruneJobExecutions(jobID, 1, now)
+	if err != nil {
+		t.Fatalf("PruneJobExecutions: %v", err)
+	}
+	var ids []string
+	for _, exec := range pruned {
+		ids = append(ids, exec.ID)
+	}
+	if strings.Join(ids, ",") != "exec-1,exec-2,exec-4" {
+		t.Fatalf("expected all but the newest and the running execution pruned, got %v", ids)
+	}
+	remaining, err := store.ListJobExecutions(jobID, 10)
+	if err != nil {
+		t.Fatalf("ListJobExecutions: %v", err)
+	}
+	if len(remaining) != 2 {
+		t.Fatalf("expected 2 executions left, got %+v", remaining)
+	}
+	for _, id := range []string{"run-1", "run-2"} {
+		if _, err := store.GetSession(id); err == nil {
+			t.Fatalf("session %s of a pruned run should be deleted", id)
+		}
+	}
+	if _, err := store.GetSession("manual"); err != nil {
+		t.Fatalf("a session not created by the job must be kept: %v", err)
+	}
+
+	if pruned, err := store.PruneJobExecutions(jobID, 0, now.Add(-2*time.Hour)); err != nil || len(pruned) != 0 {
+		t.Fatalf("executions newer than olderThan must be kept, got %+v, %v", pruned, err)
+	}
+}
+
+func TestCheckWritableLeavesNoRow(t *testing.T) {
+	forEachBackend(t, testCheckWritableLeavesNoRow)
+}
+
+func testCheckWritableLeavesNoRow(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	if err := store.CheckWritable(); err != nil {
+		t.Fatalf("CheckWritable: %v", err)
+	}
+	if settings, err := store.GetSettings(); err != nil || len(settings) != 0 {
+		t.Fatalf("the check must roll back its write, got %v, %v", settings, err)
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	forEachBackend(t, testWebhookDeliveries)
+}
+
+func testWebhookDeliveries(t *testing.T, open func(t *testing.T) Store) {
+	store := open(t)
+	now := time.Now()
+	if err := store.SaveIntegration(&Integration{ID: "hook", Provider: "webhook", Name: "Webhook", Mode: "notify_only", Enabled: true, Config: map[string]string{"url": "https://example.com"}, CreatedAt: now, UpdatedAt: now}); err != nil {
+		t.Fatalf("SaveIntegration: %v", err)
+	}
+	for i := 0; i < MaxWebhookDeliveries+5; i++ {
+		delivery := &WebhookDelivery{ID: fmt.Sprintf("d-%03d", i), IntegrationID: "hook", Event: "session.completed", Status: "delivered", Attempts: 1, ResponseCode: 200, Payload: "{}", CreatedAt: now.Add(time.Duration(i) * time.Second)}
+		if err := store.SaveWebhookDelivery(delivery); err != nil {
+			t.Fatalf("SaveWebhookDelivery: %v", err)
+		}
+	}
+
+	deliveries, err := store.ListWebhookDeliveries("hook", 1000)
+	if err != nil {
+		t.Fatalf("ListWebhookDeliveries: %v", err)
+	}
+	if len(deliveries) != MaxWebhookDeliveries || deliveries[0].ID != fmt.Sprintf("d-%03d", MaxWebhookDeliveries+4) || deliveries[0].ResponseCode != 200 {
+		t.Fatalf("expected the newest %d deliveries first, got %d starting with %+v", MaxWebhookDeliveries, len(deliveries), deliveries[0])
+	}
+	if err := store.DeleteIntegration("hook"); err != nil {
+		t.Fatalf("DeleteIntegration: %v", err)
+	}
+	if deliveries, err := store.ListWebhookDeliveries("hook", 10); err != nil || len(deliveries) != 0 {
+		t.Fatalf("expected deliveries to go with their integration, got %d, %v", len(deliveries), err)
+	}
+}
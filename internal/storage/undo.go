@@ -0,0 +1,27 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/A2gent/brute/internal/logging"
+)
+
+// UndoDir returns the folder holding the file backups taken before the
+// tools of a session changed files, which undo restores.
+func UndoDir(dataPath, sessionID string) string {
+	return filepath.Join(dataPath, "undo", sessionID)
+}
+
+// removeUndoBackups deletes the backups of sessions that are gone. Failures
+// are logged, not returned, like removeAttachmentFiles.
+func removeUndoBackups(dataPath string, sessionIDs ...string) {
+	for _, id := range sessionIDs {
+		if id == "" || filepath.Base(id) != id {
+			continue
+		}
+		if err := os.RemoveAll(UndoDir(dataPath, id)); err != nil {
+			logging.Warn("Failed to remove undo backups of session %s: %v", id, err)
+		}
+	}
+}
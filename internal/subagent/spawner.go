@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/agents"
 	"github.com/A2gent/brute/internal/llm"
 	"github.com/A2gent/brute/internal/session"
 	"github.com/A2gent/brute/internal/tools"
@@ -28,15 +29,18 @@ type Spawner struct {
 	toolManager     *tools.Manager
 	sessionManager  *session.Manager
 	model           string
+	agents          *agents.Registry
 }
 
-// NewSpawner creates a new sub-agent spawner
+// NewSpawner creates a new sub-agent spawner. Agent types are resolved
+// through registry; a nil registry means the built-in types only.
 func NewSpawner(
 	parentSessionID string,
 	llmClient llm.Client,
 	toolManager *tools.Manager,
 	sessionManager *session.Manager,
 	model string,
+	registry *agents.Registry,
 ) *Spawner {
 	return &Spawner{
 		parentSessionID: parentSessionID,
@@ -44,6 +48,7 @@ func NewSpawner(
 		toolManager:     toolManager,
 		sessionManager:  sessionManager,
 		model:           model,
+		agents:          registry,
 	}
 }
 
@@ -81,84 +86,23 @@ func (s *Spawner) getAgentConfig(agentType AgentType) agent.Config {
 		Temperature: 0.0,
 	}
 
-	switch agentType {
-	case AgentTypeGeneral:
-		base.Description = "General-purpose agent for research and multi-step tasks"
-		base.SystemPrompt = generalAgentPrompt
-
-	case AgentTypeExplore:
-		base.Description = "Fast read-only agent for codebase exploration"
-		base.SystemPrompt = exploreAgentPrompt
-		base.MaxSteps = 15 // Explore should be fast
-
-	case AgentTypeDeveloper:
-		base.Description = "Code implementation and debugging"
-		base.SystemPrompt = developerAgentPrompt
-
-	case AgentTypeTester:
-		base.Description = "Code review and test writing"
-		base.SystemPrompt = testerAgentPrompt
-
-	case AgentTypeDocs:
-		base.Description = "Documentation generation"
-		base.SystemPrompt = docsAgentPrompt
-
-	default:
+	// Types without a prompt of their own, including unknown ones, run as
+	// general-purpose sub-agents.
+	s.registry().Resolve(string(agentType)).Apply(&base)
+	if base.SystemPrompt == "" {
 		base.Description = "General-purpose sub-agent"
-		base.SystemPrompt = generalAgentPrompt
+		base.SystemPrompt = s.registry().Resolve(string(AgentTypeGeneral)).SystemPrompt
 	}
 
 	return base
 }
 
-const generalAgentPrompt = `You are a general-purpose sub-agent. Your task is to complete the specific task assigned to you and return a clear, concise result.
-
-Focus on:
-- Completing the assigned task thoroughly
-- Using tools efficiently
-- Returning useful results to the parent agent
-
-Be direct and efficient. Complete the task and summarize your findings.`
-
-const exploreAgentPrompt = `You are a fast exploration agent. Your task is to quickly find information in the codebase.
-
-Focus on:
-- Using glob to find files by pattern
-- Using grep to search file contents
-- Using read to examine specific files
-- Providing clear, organized summaries
-
-Do NOT modify any files. Be fast and thorough in your exploration.`
-
-const developerAgentPrompt = `You are a development agent. Your task is to implement code changes.
-
-Focus on:
-- Reading existing code to understand context
-- Making minimal, targeted changes
-- Following existing code style
-- Testing changes with bash commands when possible
-
-Implement the requested changes efficiently and verify they work.`
-
-const testerAgentPrompt = `You are a testing and review agent. Your task is to review code and write tests.
-
-Focus on:
-- Analyzing code for potential issues
-- Identifying edge cases
-- Writing comprehensive tests
-- Providing actionable feedback
-
-Be thorough but constructive in your review.`
-
-const docsAgentPrompt = `You are a documentation agent. Your task is to create or update documentation.
-
-Focus on:
-- Clear, concise explanations
-- Proper formatting (Markdown)
-- Code examples where helpful
-- Keeping docs up-to-date with code
-
-Write documentation that helps users understand and use the code effectively.`
+func (s *Spawner) registry() *agents.Registry {
+	if s.agents == nil {
+		s.agents = agents.NewRegistry()
+	}
+	return s.agents
+}
 
 // Ensure Spawner implements tools.SubAgentSpawner
 var _ tools.SubAgentSpawner = (*Spawner)(nil)
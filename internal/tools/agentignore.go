@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// agentIgnoreFile lists paths of the work directory, in gitignore syntax,
+// that file tools must not touch.
+const agentIgnoreFile = ".aagentignore"
+
+// agentIgnore is the .aagentignore of a work directory. Its rules win over
+// .gitignore files: a "!" line there cannot bring an excluded path back.
+type agentIgnore struct {
+	dir string
+	// realDir is dir with symlinks resolved; see underDir.
+	realDir string
+
+	mu      sync.Mutex
+	modTime time.Time
+	size    int64
+	matcher ignoreMatcher
+}
+
+// agentIgnores holds one agentIgnore per work directory, so the file is only
+// parsed again once it changes.
+var agentIgnores sync.Map
+
+// agentIgnoreFor returns the .aagentignore of workDir, or nil without a work
+// directory.
+func agentIgnoreFor(workDir string) *agentIgnore {
+	if workDir == "" {
+		return nil
+	}
+	dir, err := filepath.Abs(workDir)
+	if err != nil {
+		dir = filepath.Clean(workDir)
+	}
+	ignore, _ := agentIgnores.LoadOrStore(dir, &agentIgnore{dir: dir, realDir: realPath(dir)})
+	return ignore.(*agentIgnore)
+}
+
+// current returns the rules of the file as it is now, reading it again when
+// its modification time or size changed since the last call.
+func (a *agentIgnore) current() ignoreMatcher {
+	if a == nil {
+		return ignoreMatcher{}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	info, err := os.Stat(filepath.Join(a.dir, agentIgnoreFile))
+	if err != nil {
+		a.modTime, a.size, a.matcher = time.Time{}, 0, ignoreMatcher{}
+		return a.matcher
+	}
+	if !info.ModTime().Equal(a.modTime) || info.Size() != a.size {
+		a.modTime, a.size = info.ModTime(), info.Size()
+		a.matcher = ignoreMatcher{}.with(a.dir, agentIgnoreFile)
+	}
+	return a.matcher
+}
+
+// excludes reports whether path, or a directory between the work directory
+// and it, is excluded. So are paths that lead to an excluded one through
+// symlinks, which are resolved the way sandbox roots are (see realPath).
+func (a *agentIgnore) excludes(path string) bool {
+	matcher := a.current()
+	if len(matcher.files) == 0 {
+		return false
+	}
+	info, err := os.Stat(path)
+	isDir := err == nil && info.IsDir()
+	return excludedWithParents(matcher, a.dir, path, isDir) ||
+		excludedWithParents(matcher, a.dir, underDir(a.dir, a.realDir, realPath(path)), isDir)
+}
+
+// underDir maps real, a path with symlinks resolved, to the same place below
+// dir, whose resolved form is realDir, so that the rules of dir apply to it.
+// Paths outside realDir are returned as they are.
+func underDir(dir, realDir, real string) string {
+	rel, err := filepath.Rel(realDir, real)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return real
+	}
+	return filepath.Join(dir, rel)
+}
+
+// excludedWithParents reports whether matcher ignores p or one of its parent
+// directories below dir. Unlike walks, single paths are not reached through
+// their parents, which have to be checked too.
+func excludedWithParents(matcher ignoreMatcher, dir, p string, isDir bool) bool {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	rel, err := filepath.Rel(dir, p)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	parent := dir
+	parts := strings.Split(rel, string(filepath.Separator))
+	for _, part := range parts[:len(parts)-1] {
+		parent = filepath.Join(parent, part)
+		if matcher.ignored(parent, true) {
+			return true
+		}
+	}
+	return matcher.ignored(p, isDir)
+}
+
+// agentIgnoreResult is the error result of tools asked for an excluded path.
+func agentIgnoreResult(path string) *Result {
+	return &Result{Success: false, Error: fmt.Sprintf("path is excluded by %s: %s", agentIgnoreFile, path)}
+}
@@ -0,0 +1,115 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAgentIgnoreKeepsPathsOutOfReach(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, ".aagentignore", ".env\ndata/\n")
+	// .gitignore cannot bring back what .aagentignore excludes
+	createTestFile(t, tempDir, ".gitignore", "!.env\n!data/\n")
+	createTestFile(t, tempDir, ".env", "API_KEY=secret")
+	createTestFile(t, tempDir, "data/export.csv", "customer,secret")
+	createTestFile(t, tempDir, "src/main.go", "package main // secret")
+
+	run := func(tool Tool, params string) *Result {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tool.Name(), params, err)
+		}
+		return result
+	}
+
+	for _, result := range []*Result{
+		run(NewGrepTool(tempDir), `{"pattern":"secret"}`),
+		run(NewGlobTool(tempDir), `{"pattern":"**/*"}`),
+		run(NewFindFilesTool(tempDir), `{"pattern":"**/*","show_hidden":true}`),
+		run(NewGlobTool(tempDir), `{"pattern":"*","path":"data"}`),
+	} {
+		if strings.Contains(result.Output, ".env") || strings.Contains(result.Output, "export.csv") {
+			t.Errorf("excluded paths listed:\n%s", result.Output)
+		}
+	}
+
+	for _, call := range []struct {
+		tool   Tool
+		params string
+	}{
+		{NewReadTool(tempDir), `{"path":".env"}`},
+		{NewReadTool(tempDir), `{"path":"data/export.csv"}`},
+		{NewWriteTool(tempDir), `{"path":"data/new.csv","content":"x"}`},
+		{NewEditTool(tempDir), `{"path":".env","old_string":"secret","new_string":"leaked"}`},
+	} {
+		if result := run(call.tool, call.params); result.Success || !strings.Contains(result.Error, "path is excluded by .aagentignore") {
+			t.Errorf("%s %s = %+v, want the exclusion error", call.tool.Name(), call.params, result)
+		}
+	}
+
+	// A changed file is read again
+	createTestFile(t, tempDir, ".aagentignore", ".env\n")
+	if result := run(NewReadTool(tempDir), `{"path":"data/export.csv"}`); !result.Success {
+		t.Fatalf("read after data/ was allowed again: %+v", result)
+	}
+}
+
+func TestAgentIgnoreFollowsSymlinks(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, ".aagentignore", ".env\ndata/\nconfig/secret.yml\n")
+	createTestFile(t, tempDir, ".env", "API_KEY=secret")
+	createTestFile(t, tempDir, "data/export.csv", "customer,secret")
+	createTestFile(t, tempDir, "config/secret.yml", "token: secret")
+	createTestFile(t, tempDir, "config/public.yml", "name: demo")
+	for link, target := range map[string]string{"env-link": ".env", "exports": "data", "settings": "config"} {
+		if err := os.Symlink(filepath.Join(tempDir, target), filepath.Join(tempDir, link)); err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	run := func(tool Tool, params string) *Result {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tool.Name(), params, err)
+		}
+		return result
+	}
+
+	for _, result := range []*Result{
+		run(NewGrepTool(tempDir), `{"pattern":"API_KEY|customer|token"}`),
+		run(NewGlobTool(tempDir), `{"pattern":"**/*"}`),
+		run(NewFindFilesTool(tempDir), `{"pattern":"**/*"}`),
+		run(NewGlobTool(tempDir), `{"pattern":"*","path":"exports"}`),
+	} {
+		for _, excluded := range []string{"env-link", "export.csv", "secret.yml"} {
+			if strings.Contains(result.Output, excluded) {
+				t.Errorf("excluded path %s listed through a symlink:\n%s", excluded, result.Output)
+			}
+		}
+	}
+
+	for _, call := range []struct {
+		tool   Tool
+		params string
+	}{
+		{NewReadTool(tempDir), `{"path":"env-link"}`},
+		{NewReadTool(tempDir), `{"path":"exports/export.csv"}`},
+		{NewReadTool(tempDir), `{"path":"settings/secret.yml"}`},
+		{NewWriteTool(tempDir), `{"path":"exports/new.csv","content":"x"}`},
+		{NewEditTool(tempDir), `{"path":"env-link","old_string":"secret","new_string":"leaked"}`},
+	} {
+		if result := run(call.tool, call.params); result.Success || !strings.Contains(result.Error, "path is excluded by .aagentignore") {
+			t.Errorf("%s %s = %+v, want the exclusion error", call.tool.Name(), call.params, result)
+		}
+	}
+
+	if result := run(NewReadTool(tempDir), `{"path":"settings/public.yml"}`); !result.Success {
+		t.Fatalf("read of an allowed file through a symlinked directory: %+v", result)
+	}
+}
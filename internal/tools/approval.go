@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Tool approval policies, set per tool name with SetApprovalPolicy.
+const (
+	ApprovalAllow = "allow"
+	ApprovalDeny  = "deny"
+	ApprovalAsk   = "ask"
+)
+
+// ApprovalRequest is a call waiting for the user's decision. For calls that
+// overwrite an existing file, Overwrite is set and Replaced holds the content
+// that would be lost, so that the prompt can show both sides.
+type ApprovalRequest struct {
+	Tool      string
+	Params    json.RawMessage
+	Overwrite bool
+	Replaced  string
+}
+
+// ApprovalDecision answers an ApprovalRequest. Params, when set, replaces
+// the arguments the call runs with. Always asks the approver to stop asking
+// about this tool for the rest of the session; Manager does not track it.
+type ApprovalDecision struct {
+	Approved bool
+	Always   bool
+	Params   json.RawMessage
+}
+
+// OverwritingTool is implemented by tools that replace whole files.
+// ReplacedContent returns the current content of the file a call would
+// overwrite, and false when it would create a new one.
+type OverwritingTool interface {
+	ReplacedContent(params json.RawMessage) (string, bool)
+}
+
+// Approver asks the user about a call whose tool policy is "ask". It blocks
+// until the user answers or ctx is done.
+type Approver func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error)
+
+type approverContextKey struct{}
+
+// WithApprover routes "ask" decisions for Execute calls made with the
+// returned context to approver. Without one, such calls are refused, as
+// there is nobody to ask in HTTP runs and jobs.
+func WithApprover(ctx context.Context, approver Approver) context.Context {
+	return context.WithValue(ctx, approverContextKey{}, approver)
+}
+
+// SetApprovalPolicy replaces the per-tool approval policies. Tools without
+// an entry are allowed.
+func (m *Manager) SetApprovalPolicy(policy map[string]string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.approvals = policy
+}
+
+// ApprovalPolicy returns the per-tool approval policies.
+func (m *Manager) ApprovalPolicy() map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.approvals
+}
+
+// approvalFor returns the policy of the named tool.
+func (m *Manager) approvalFor(name string) string {
+	policy := strings.ToLower(strings.TrimSpace(m.ApprovalPolicy()[name]))
+	if policy == "" {
+		return ApprovalAllow
+	}
+	return policy
+}
+
+// approve applies the approval policy to a call and returns the arguments
+// it should run with.
+func (m *Manager) approve(ctx context.Context, tool Tool, params json.RawMessage) (json.RawMessage, error) {
+	name := tool.Name()
+	switch m.approvalFor(name) {
+	case ApprovalDeny:
+		return nil, fmt.Errorf("tool %s is denied by the tool approval policy", name)
+	case ApprovalAsk:
+		approver, ok := ctx.Value(approverContextKey{}).(Approver)
+		if !ok || approver == nil {
+			return nil, fmt.Errorf("tool %s needs approval (tools.%s is \"ask\") and this run has nobody to approve it; do not retry it, tell the user instead", name, name)
+		}
+		req := ApprovalRequest{Tool: name, Params: params}
+		if overwriting, ok := tool.(OverwritingTool); ok {
+			req.Replaced, req.Overwrite = overwriting.ReplacedContent(params)
+		}
+		decision, err := approver(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !decision.Approved {
+			return nil, fmt.Errorf("the user denied this %s call; do not retry it unchanged", name)
+		}
+		if len(decision.Params) > 0 {
+			if !json.Valid(decision.Params) {
+				return nil, fmt.Errorf("edited arguments for %s are not valid JSON", name)
+			}
+			return decision.Params, nil
+		}
+	}
+	return params, nil
+}
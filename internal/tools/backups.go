@@ -0,0 +1,368 @@
+package tools
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+const (
+	// maxUndoBackups bounds the backups kept per session; the oldest go
+	// first.
+	maxUndoBackups = 100
+	// maxUndoBytes bounds the total size of the backups of a session. Files
+	// larger than this are changed without a backup.
+	maxUndoBytes = 64 << 20
+
+	undoManifestFile = "manifest.json"
+)
+
+// ErrNoBackup is returned by Undo when there is no change to revert.
+var ErrNoBackup = errors.New("no backed up change to revert")
+
+// MutatingTool is implemented by tools that change files in place, such as
+// write and edit. MutatedPaths returns the absolute paths a call would
+// change, so that the manager can back them up first.
+type MutatingTool interface {
+	MutatedPaths(params json.RawMessage) []string
+}
+
+// Backup describes the content of a file before a tool call changed it.
+type Backup struct {
+	Seq        int       `json:"seq"`
+	Path       string    `json:"path"`
+	Tool       string    `json:"tool"`
+	ToolCallID string    `json:"tool_call_id,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	// File names the saved content; empty when the call created the file.
+	File string `json:"file,omitempty"`
+	Size int64  `json:"size"`
+}
+
+type undoManifest struct {
+	NextSeq int      `json:"next_seq"`
+	Backups []Backup `json:"backups"`
+}
+
+// BackupStore keeps per-session file backups under DataPath/undo, with a
+// manifest listing them oldest first.
+type BackupStore struct {
+	dataPath string
+	mu       sync.Mutex
+}
+
+// NewBackupStore returns a store keeping backups under dataPath.
+func NewBackupStore(dataPath string) *BackupStore {
+	return &BackupStore{dataPath: dataPath}
+}
+
+func (s *BackupStore) dir(sessionID string) (string, error) {
+	if sessionID == "" || filepath.Base(sessionID) != sessionID {
+		return "", fmt.Errorf("invalid session ID %q", sessionID)
+	}
+	return storage.UndoDir(s.dataPath, sessionID), nil
+}
+
+func (s *BackupStore) load(dir string) (undoManifest, error) {
+	var manifest undoManifest
+	data, err := os.ReadFile(filepath.Join(dir, undoManifestFile))
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("invalid undo manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func (s *BackupStore) store(dir string, manifest undoManifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, undoManifestFile), data, 0o644)
+}
+
+// Save backs up the current content of path before tool changes it. A path
+// that does not exist yet is recorded too, so that undo removes the file
+// the call creates.
+func (s *BackupStore) Save(sessionID, path, tool, toolCallID string) (*Backup, error) {
+	dir, err := s.dir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(content) > maxUndoBytes {
+		return nil, fmt.Errorf("%s is too large to back up (%d bytes)", path, len(content))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	manifest, err := s.load(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.NextSeq++
+	backup := Backup{
+		Seq:        manifest.NextSeq,
+		Path:       path,
+		Tool:       tool,
+		ToolCallID: toolCallID,
+		Timestamp:  time.Now(),
+		Size:       int64(len(content)),
+	}
+	if existed {
+		sum := sha256.Sum256(content)
+		backup.File = fmt.Sprintf("%06d_%s", backup.Seq, hex.EncodeToString(sum[:6]))
+		if err := os.WriteFile(filepath.Join(dir, backup.File), content, 0o644); err != nil {
+			return nil, err
+		}
+	}
+	manifest.Backups = append(manifest.Backups, backup)
+
+	// Drop the oldest backups beyond the caps
+	var total int64
+	for _, b := range manifest.Backups {
+		total += b.Size
+	}
+	for len(manifest.Backups) > maxUndoBackups || total > maxUndoBytes {
+		oldest := manifest.Backups[0]
+		removeBackupFile(dir, oldest)
+		total -= oldest.Size
+		manifest.Backups = manifest.Backups[1:]
+	}
+
+	if err := s.store(dir, manifest); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// discard drops a backup whose call turned out not to change anything.
+func (s *BackupStore) discard(sessionID string, seq int) {
+	dir, err := s.dir(sessionID)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest, err := s.load(dir)
+	if err != nil {
+		return
+	}
+	for i, b := range manifest.Backups {
+		if b.Seq == seq {
+			removeBackupFile(dir, b)
+			manifest.Backups = append(manifest.Backups[:i], manifest.Backups[i+1:]...)
+			_ = s.store(dir, manifest)
+			return
+		}
+	}
+}
+
+// Undo restores the most recent backup of path, or of any file when path is
+// empty, and drops it, so that repeated calls step further back. A file the
+// change created is removed. It returns ErrNoBackup when there is nothing to
+// revert.
+func (s *BackupStore) Undo(sessionID, path string) (*Backup, error) {
+	dir, err := s.dir(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	manifest, err := s.load(dir)
+	if err != nil {
+		return nil, err
+	}
+	index := -1
+	for i := len(manifest.Backups) - 1; i >= 0; i-- {
+		if path == "" || manifest.Backups[i].Path == filepath.Clean(path) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, ErrNoBackup
+	}
+	backup := manifest.Backups[index]
+
+	if backup.File == "" {
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to remove %s: %w", backup.Path, err)
+		}
+	} else {
+		content, err := os.ReadFile(filepath.Join(dir, backup.File))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+		mode := os.FileMode(0o644)
+		if info, err := os.Stat(backup.Path); err == nil {
+			mode = info.Mode().Perm()
+		}
+		if err := os.MkdirAll(filepath.Dir(backup.Path), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(backup.Path, content, mode); err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", backup.Path, err)
+		}
+	}
+
+	removeBackupFile(dir, backup)
+	manifest.Backups = append(manifest.Backups[:index], manifest.Backups[index+1:]...)
+	if err := s.store(dir, manifest); err != nil {
+		return nil, err
+	}
+	return &backup, nil
+}
+
+// Remaining returns how many backups of path, or of any file when path is
+// empty, are left to revert.
+func (s *BackupStore) Remaining(sessionID, path string) int {
+	dir, err := s.dir(sessionID)
+	if err != nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	manifest, err := s.load(dir)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, b := range manifest.Backups {
+		if path == "" || b.Path == filepath.Clean(path) {
+			count++
+		}
+	}
+	return count
+}
+
+func removeBackupFile(dir string, backup Backup) {
+	if backup.File == "" {
+		return
+	}
+	if err := os.Remove(filepath.Join(dir, backup.File)); err != nil && !os.IsNotExist(err) {
+		logging.Warn("Failed to remove undo backup %s: %v", backup.File, err)
+	}
+}
+
+// DescribeUndo tells what an Undo call reverted, naming the file relative
+// to workDir when it lies inside.
+func DescribeUndo(backup *Backup, workDir string) string {
+	displayPath := backup.Path
+	if rel, err := filepath.Rel(workDir, backup.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		displayPath = rel
+	}
+	change := fmt.Sprintf("%s call", backup.Tool)
+	if backup.ToolCallID != "" {
+		change += " " + backup.ToolCallID
+	}
+	when := backup.Timestamp.Format(time.RFC3339)
+	if backup.File == "" {
+		return fmt.Sprintf("Reverted the %s from %s: removed %s, which it created", change, when, displayPath)
+	}
+	return fmt.Sprintf("Reverted the %s from %s: restored %s (%d bytes)", change, when, displayPath, backup.Size)
+}
+
+type toolCallIDContextKey struct{}
+
+func withToolCallID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, toolCallIDContextKey{}, id)
+}
+
+func toolCallIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(toolCallIDContextKey{}).(string)
+	return id
+}
+
+// EnableUndo makes the manager back up files before MutatingTool calls of
+// a session change them, and registers the undo_edit tool.
+func (m *Manager) EnableUndo(backups *BackupStore) {
+	m.mu.Lock()
+	m.backups = backups
+	m.mu.Unlock()
+	m.Register(NewUndoEditTool(m.workDir, backups))
+}
+
+// backUp saves the files a call of tool is about to change. Calls outside a
+// session are not backed up; failures are logged and do not stop the call.
+func (m *Manager) backUp(ctx context.Context, tool Tool, params json.RawMessage) []*Backup {
+	m.mu.RLock()
+	backups := m.backups
+	m.mu.RUnlock()
+	mutating, ok := tool.(MutatingTool)
+	sessionID := getSessionIDFromContext(ctx)
+	if backups == nil || !ok || sessionID == "" {
+		return nil
+	}
+
+	var saved []*Backup
+	for _, path := range mutating.MutatedPaths(params) {
+		backup, err := backups.Save(sessionID, path, tool.Name(), toolCallIDFromContext(ctx))
+		if err != nil {
+			logging.WarnContext(ctx, "Skipping undo backup of %s: %v", path, err)
+			continue
+		}
+		saved = append(saved, backup)
+	}
+	return saved
+}
+
+// discardBackups drops the backups of a call that failed without changing
+// its files.
+func (m *Manager) discardBackups(ctx context.Context, saved []*Backup) {
+	m.mu.RLock()
+	backups := m.backups
+	m.mu.RUnlock()
+	sessionID := getSessionIDFromContext(ctx)
+	for _, backup := range saved {
+		backups.discard(sessionID, backup.Seq)
+	}
+}
+
+// mutatedPathParam returns the "path" argument of a file tool call resolved
+// against workDir, for MutatedPaths.
+func mutatedPathParam(workDir string, params json.RawMessage) []string {
+	var p struct {
+		Path string `json:"path"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil || p.Path == "" {
+		return nil
+	}
+	return []string{resolveToolPath(workDir, p.Path)}
+}
+
+// resolveToolPath resolves a path argument against workDir.
+func resolveToolPath(workDir, path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	return filepath.Clean(path)
+}
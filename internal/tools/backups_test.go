@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func TestUndoEditRevertsBackedUpChanges(t *testing.T) {
+	workDir := t.TempDir()
+	dataPath := t.TempDir()
+	m := newBareManager()
+	m.workDir = workDir
+	m.Register(NewWriteTool(workDir))
+	m.Register(NewEditTool(workDir))
+	m.EnableUndo(NewBackupStore(dataPath))
+	ctx := context.WithValue(context.Background(), "session_id", "sess-1")
+
+	call := func(id, name, input string) llm.ToolResult {
+		t.Helper()
+		return m.ExecuteParallel(ctx, []llm.ToolCall{{ID: id, Name: name, Input: input}})[0]
+	}
+	content := func() string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(workDir, "main.go"))
+		if os.IsNotExist(err) {
+			return "<missing>"
+		}
+		return string(data)
+	}
+
+	call("call-1", "write", `{"path":"main.go","content":"a := 1"}`)
+	call("call-2", "edit", `{"path":"main.go","old_string":"a := 1","new_string":"a := 2"}`)
+	// A failed edit changes nothing and leaves no backup behind
+	if result := call("call-3", "edit", `{"path":"main.go","old_string":"missing","new_string":"x"}`); !result.IsError {
+		t.Fatalf("expected the edit to fail, got %+v", result)
+	}
+
+	result := call("call-4", UndoEditToolName, `{"path":"main.go"}`)
+	if result.IsError || !strings.Contains(result.Content, "edit call call-2") || !strings.Contains(result.Content, "restored main.go") {
+		t.Fatalf("first undo = %+v", result)
+	}
+	if got := content(); got != "a := 1" {
+		t.Fatalf("after the first undo main.go = %q", got)
+	}
+
+	result = call("call-5", UndoEditToolName, `{}`)
+	if result.IsError || !strings.Contains(result.Content, "removed main.go, which it created") {
+		t.Fatalf("second undo = %+v", result)
+	}
+	if got := content(); got != "<missing>" {
+		t.Fatalf("after the second undo main.go = %q", got)
+	}
+
+	if result := call("call-6", UndoEditToolName, `{}`); !result.IsError {
+		t.Fatalf("expected nothing left to undo, got %+v", result)
+	}
+
+	// Outside a session nothing is backed up
+	m.ExecuteParallel(context.Background(), []llm.ToolCall{{ID: "call-7", Name: "write", Input: `{"path":"other.go","content":"x"}`}})
+	if entries, _ := os.ReadDir(filepath.Join(dataPath, "undo")); len(entries) != 1 {
+		t.Fatalf("undo folders = %v, want only sess-1", entries)
+	}
+}
+
+func TestBackupStoreCapsBackupsPerSession(t *testing.T) {
+	workDir := t.TempDir()
+	backups := NewBackupStore(t.TempDir())
+	path := filepath.Join(workDir, "notes.txt")
+
+	for i := 0; i < maxUndoBackups+5; i++ {
+		if err := os.WriteFile(path, []byte(fmt.Sprintf("version %d", i)), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := backups.Save("sess-1", path, "write", ""); err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	if got := backups.Remaining("sess-1", ""); got != maxUndoBackups {
+		t.Fatalf("kept %d backups, want %d", got, maxUndoBackups)
+	}
+	files, _ := os.ReadDir(storage.UndoDir(backups.dataPath, "sess-1"))
+	if len(files) != maxUndoBackups+1 { // the backups and the manifest
+		t.Fatalf("undo folder holds %d files, want %d", len(files), maxUndoBackups+1)
+	}
+	if _, err := backups.Save("../escape", path, "write", ""); err == nil {
+		t.Fatal("expected an invalid session ID to be rejected")
+	}
+}
@@ -13,6 +13,7 @@ import (
 const (
 	defaultBashTimeout = 30 * time.Second
 	maxOutputSize      = 50 * 1024 // 50KB
+	bashWaitDelay      = 2 * time.Second
 )
 
 // BashTool executes shell commands
@@ -36,6 +37,11 @@ func (t *BashTool) Name() string {
 	return "bash"
 }
 
+// SelfTimed reports that bash applies its own timeout parameter.
+func (t *BashTool) SelfTimed() bool {
+	return true
+}
+
 func (t *BashTool) Description() string {
 	return `Execute shell commands in the project environment.
 Use this for running terminal commands like git, npm, make, etc.
@@ -92,6 +98,9 @@ func (t *BashTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	// Execute command
 	cmd := exec.CommandContext(ctx, "bash", "-c", p.Command)
 	cmd.Dir = workDir
+	killProcessGroupOnCancel(cmd)
+	// Don't wait forever on pipes held open by processes that escaped the group.
+	cmd.WaitDelay = bashWaitDelay
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -121,6 +130,13 @@ func (t *BashTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 				Output:  output,
 			}, nil
 		}
+		if ctx.Err() == context.Canceled {
+			return &Result{
+				Success: false,
+				Error:   "command cancelled",
+				Output:  output,
+			}, nil
+		}
 
 		// Command failed but we still want to return output
 		return &Result{
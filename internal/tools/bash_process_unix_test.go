@@ -0,0 +1,78 @@
+//go:build !windows
+
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBashCancelKillsBackgroundChildren(t *testing.T) {
+	dir := t.TempDir()
+	pidFile := filepath.Join(dir, "child.pid")
+	tool := NewBashTool(dir)
+
+	params, _ := json.Marshal(BashParams{
+		Command: "sleep 30 & echo $! > " + pidFile + "; wait",
+		Timeout: 60000,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for i := 0; i < 100; i++ {
+			if _, err := os.Stat(pidFile); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		cancel()
+	}()
+
+	start := time.Now()
+	result, err := tool.Execute(ctx, params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Execute returned after %v; cancellation did not stop the command", elapsed)
+	}
+	if result.Success || result.Error != "command cancelled" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatalf("read pid file: %v", err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatalf("parse pid: %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for processRunning(pid) && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	if processRunning(pid) {
+		t.Fatalf("background child %d survived cancellation", pid)
+	}
+}
+
+// processRunning treats zombies as dead: an orphaned child that was killed
+// may wait a while for init to reap it.
+func processRunning(pid int) bool {
+	if syscall.Kill(pid, 0) != nil {
+		return false
+	}
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return true
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) == 0 || fields[0] != "Z"
+}
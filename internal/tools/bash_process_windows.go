@@ -0,0 +1,9 @@
+//go:build windows
+
+package tools
+
+import "os/exec"
+
+// killProcessGroupOnCancel keeps the default behaviour on Windows, where
+// exec.CommandContext kills only the direct child.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {}
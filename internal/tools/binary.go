@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// binarySniffBytes is how much of a file with an unknown extension is read
+// to decide whether it is binary.
+const binarySniffBytes = 512
+
+// binaryExtensions are file types treated as binary without opening them.
+var binaryExtensions = map[string]bool{
+	// images
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".bmp": true,
+	".ico": true, ".webp": true, ".tif": true, ".tiff": true, ".psd": true,
+	".heic": true,
+	// audio and video
+	".mp3": true, ".wav": true, ".flac": true, ".ogg": true, ".m4a": true,
+	".mp4": true, ".mov": true, ".avi": true, ".mkv": true, ".webm": true,
+	// archives
+	".zip": true, ".gz": true, ".tgz": true, ".bz2": true, ".xz": true,
+	".zst": true, ".7z": true, ".rar": true, ".tar": true, ".jar": true,
+	".war": true, ".whl": true,
+	// compiled code and libraries
+	".exe": true, ".dll": true, ".so": true, ".dylib": true, ".a": true,
+	".o": true, ".obj": true, ".lib": true, ".class": true, ".pyc": true,
+	".wasm": true, ".bin": true,
+	// databases
+	".db": true, ".sqlite": true, ".sqlite3": true, ".mdb": true,
+	// documents and fonts
+	".pdf": true, ".doc": true, ".docx": true, ".xls": true, ".xlsx": true,
+	".ppt": true, ".pptx": true, ".woff": true, ".woff2": true, ".ttf": true,
+	".otf": true, ".eot": true,
+}
+
+// hasBinaryExtension reports whether path names a well-known binary file
+// type.
+func hasBinaryExtension(path string) bool {
+	return binaryExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// isBinaryFile reports whether path is binary: known binary extensions are
+// decided by name, other files by a NUL byte in their first 512 bytes.
+// Unreadable files count as binary.
+func isBinaryFile(path string) bool {
+	if hasBinaryExtension(path) {
+		return true
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer file.Close()
+
+	buf := make([]byte, binarySniffBytes)
+	n, err := file.Read(buf)
+	if err != nil {
+		// An empty file is text
+		return !errors.Is(err, io.EOF)
+	}
+
+	for i := 0; i < n; i++ {
+		if buf[i] == 0 {
+			return true
+		}
+	}
+	return false
+}
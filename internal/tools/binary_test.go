@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestGrepSkipsBinaryAndLargeFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, "src/main.go", "// TODO: handle errors")
+	createTestFile(t, tempDir, "data/app.sqlite", "TODO: stored as text but named like a database")
+	createTestFile(t, tempDir, "data/blob.dat", "TODO\x00binary")
+	createTestFile(t, tempDir, "logs/big.log", "TODO: in a big log\n"+strings.Repeat("x", 2048))
+
+	tool := NewGrepTool(tempDir)
+	grep := func(params map[string]interface{}) *Result {
+		t.Helper()
+		raw, _ := json.Marshal(params)
+		result, err := tool.Execute(context.Background(), raw)
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		assertSuccess(t, result)
+		return result
+	}
+
+	result := grep(map[string]interface{}{"pattern": "TODO", "max_file_bytes": 1024})
+	assertContains(t, result.Output, "src/main.go:1:")
+	assertNotContains(t, result.Output, "app.sqlite")
+	assertNotContains(t, result.Output, "blob.dat")
+	assertContains(t, result.Output, "skipped 1 file(s) larger than 1024 bytes, which may contain matches: logs/big.log")
+
+	result = grep(map[string]interface{}{"pattern": "TODO", "max_file_bytes": -1})
+	assertContains(t, result.Output, "logs/big.log:1:")
+	assertNotContains(t, result.Output, "skipped")
+}
+
+func TestReadRefusesBinaryFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, "image.png", "not really a png")
+	createTestFile(t, tempDir, "empty.txt", "")
+
+	tool := NewReadTool(tempDir)
+	result, err := tool.Execute(context.Background(), json.RawMessage(`{"path":"image.png"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "binary file") {
+		t.Fatalf("expected a binary file error, got %+v", result)
+	}
+
+	result, err = tool.Execute(context.Background(), json.RawMessage(`{"path":"empty.txt"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	assertSuccess(t, result)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"runtime"
+	"strings"
 )
 
 type CameraDevice struct {
@@ -17,10 +18,61 @@ func ListCameraDevices(ctx context.Context) ([]CameraDevice, error) {
 	case "darwin":
 		return listCameraDevicesDarwin()
 	case "linux":
-		return listCameraDevicesLinux()
+		return listCameraDevicesLinux(ctx)
 	case "windows":
 		return listCameraDevicesWindows(ctx)
 	default:
 		return nil, fmt.Errorf("camera device listing is not supported on %s", runtime.GOOS)
 	}
 }
+
+// ValidateCameraIndex checks that a 1-based camera index maps to one of the
+// devices reported by ListCameraDevices.
+func ValidateCameraIndex(devices []CameraDevice, index int) error {
+	if index <= 0 {
+		return fmt.Errorf("camera index must be >= 1, got %d", index)
+	}
+	for _, device := range devices {
+		if device.Index == index {
+			return nil
+		}
+	}
+	return fmt.Errorf("camera index %d not found (available: %s)", index, describeCameraIndexes(devices))
+}
+
+func describeCameraIndexes(devices []CameraDevice) string {
+	if len(devices) == 0 {
+		return "none"
+	}
+	parts := make([]string, 0, len(devices))
+	for _, device := range devices {
+		parts = append(parts, fmt.Sprintf("%d=%s", device.Index, device.Name))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// parseV4L2CtlDevices parses `v4l2-ctl --list-devices` output, where each card
+// header line is followed by tab-indented device node paths.
+func parseV4L2CtlDevices(output string) map[string]string {
+	names := map[string]string{}
+	current := ""
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			current = ""
+			continue
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			header := strings.TrimSuffix(strings.TrimSpace(line), ":")
+			if idx := strings.LastIndex(header, " ("); idx > 0 {
+				header = header[:idx]
+			}
+			current = strings.TrimSpace(header)
+			continue
+		}
+		path := strings.TrimSpace(line)
+		if current != "" && strings.HasPrefix(path, "/dev/video") {
+			names[path] = current
+		}
+	}
+	return names
+}
@@ -3,19 +3,22 @@
 package tools
 
 import (
+	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
 )
 
-func listCameraDevicesLinux() ([]CameraDevice, error) {
+func listCameraDevicesLinux(ctx context.Context) ([]CameraDevice, error) {
 	paths, err := filepath.Glob("/dev/video*")
 	if err != nil {
 		return nil, err
 	}
 	sort.Strings(paths)
+	v4l2Names := v4l2CtlDeviceNames(ctx)
 
 	devices := make([]CameraDevice, 0, len(paths))
 	for _, path := range paths {
@@ -28,7 +31,10 @@ func listCameraDevicesLinux() ([]CameraDevice, error) {
 		if convErr != nil {
 			continue
 		}
-		name := strings.TrimSpace(readFirstLine(filepath.Join("/sys/class/video4linux", base, "name")))
+		name := v4l2Names[path]
+		if name == "" {
+			name = strings.TrimSpace(readFirstLine(filepath.Join("/sys/class/video4linux", base, "name")))
+		}
 		if name == "" {
 			name = path
 		}
@@ -41,6 +47,19 @@ func listCameraDevicesLinux() ([]CameraDevice, error) {
 	return devices, nil
 }
 
+// v4l2CtlDeviceNames maps /dev/video* paths to card names reported by
+// `v4l2-ctl --list-devices`. It returns an empty map when v4l2-ctl is missing.
+func v4l2CtlDeviceNames(ctx context.Context) map[string]string {
+	if _, err := exec.LookPath("v4l2-ctl"); err != nil {
+		return map[string]string{}
+	}
+	out, err := exec.CommandContext(ctx, "v4l2-ctl", "--list-devices").Output()
+	if len(out) == 0 && err != nil {
+		return map[string]string{}
+	}
+	return parseV4L2CtlDevices(string(out))
+}
+
 func readFirstLine(path string) string {
 	raw, err := os.ReadFile(path)
 	if err != nil {
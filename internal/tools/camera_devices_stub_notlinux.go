@@ -2,8 +2,11 @@
 
 package tools
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
-func listCameraDevicesLinux() ([]CameraDevice, error) {
+func listCameraDevicesLinux(ctx context.Context) ([]CameraDevice, error) {
 	return nil, fmt.Errorf("camera device listing is not supported on this platform")
 }
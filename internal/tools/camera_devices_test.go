@@ -0,0 +1,17 @@
+package tools
+
+import "testing"
+
+func TestParseV4L2CtlDevices(t *testing.T) {
+	output := "HD Webcam: HD Webcam (usb-0000:00:14.0-5):\n\t/dev/video0\n\t/dev/video1\n\t/dev/media0\n\nUSB Capture: USB Capture (usb-0000:00:14.0-6):\n\t/dev/video2\n"
+	names := parseV4L2CtlDevices(output)
+	if names["/dev/video0"] != "HD Webcam: HD Webcam" {
+		t.Fatalf("unexpected name for video0: %q", names["/dev/video0"])
+	}
+	if names["/dev/video2"] != "USB Capture: USB Capture" {
+		t.Fatalf("unexpected name for video2: %q", names["/dev/video2"])
+	}
+	if _, ok := names["/dev/media0"]; ok {
+		t.Fatal("media nodes should be ignored")
+	}
+}
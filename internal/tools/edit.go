@@ -85,6 +85,12 @@ func (t *EditTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(t.workDir, path)
 	}
+	if agentIgnoreFor(t.workDir).excludes(path) {
+		return agentIgnoreResult(p.Path), nil
+	}
+	if outsideSandbox(t.workDir, path) {
+		return sandboxResult(p.Path), nil
+	}
 
 	// Read file
 	content, err := os.ReadFile(path)
@@ -137,5 +143,10 @@ func (t *EditTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	}, nil
 }
 
+// MutatedPaths returns the file a call changes, for undo backups.
+func (t *EditTool) MutatedPaths(params json.RawMessage) []string {
+	return mutatedPathParam(t.workDir, params)
+}
+
 // Ensure EditTool implements Tool
 var _ Tool = (*EditTool)(nil)
@@ -252,6 +252,12 @@ func (t *FilterTool) loadInput(p FilterParams) (string, string, error) {
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(t.workDir, path)
 	}
+	if agentIgnoreFor(t.workDir).excludes(path) {
+		return "", "", fmt.Errorf("path is excluded by %s: %s", agentIgnoreFile, p.Path)
+	}
+	if outsideSandbox(t.workDir, path) {
+		return "", "", sandboxError(p.Path)
+	}
 	info, err := os.Stat(path)
 	if os.IsNotExist(err) {
 		return "", "", fmt.Errorf("file not found: %s", p.Path)
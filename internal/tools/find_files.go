@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"sort"
 	"strings"
@@ -44,9 +44,15 @@ func (t *FindFilesTool) Name() string {
 	return "find_files"
 }
 
+// Idempotent reports that listing files has no side effects.
+func (t *FindFilesTool) Idempotent() bool {
+	return true
+}
+
 func (t *FindFilesTool) Description() string {
 	return `Find files with glob patterns and exclude filters.
 Supports pagination (30 files per page by default) and hides hidden files by default.
+Skips .git and paths ignored by .gitignore or excluded by .aagentignore.
 Optimized for precise file discovery with compact output.
 Use this before grep/read/edit to minimize context usage.`
 }
@@ -65,14 +71,14 @@ func (t *FindFilesTool) Schema() map[string]interface{} {
 			},
 			"exclude": map[string]interface{}{
 				"type":        "array",
-				"description": "Exclude glob patterns matched against relative paths",
+				"description": "Exclude glob patterns matched against relative paths; a matching directory is skipped entirely",
 				"items": map[string]interface{}{
 					"type": "string",
 				},
 			},
 			"max_results": map[string]interface{}{
 				"type":        "integer",
-				"description": "Maximum number of results (default: 30, max: 2000)",
+				"description": "Caps how many sorted matches can be paged through (default and max: 2000); page_size sets the page",
 			},
 			"sort": map[string]interface{}{
 				"type":        "string",
@@ -132,10 +138,10 @@ func (t *FindFilesTool) Execute(ctx context.Context, params json.RawMessage) (*R
 		pageSize = 100
 	}
 
-	// Handle max_results for backwards compatibility
+	// max_results caps the sorted candidate pool that pages are cut from
 	limit := p.MaxResults
 	if limit <= 0 {
-		limit = maxFindFilesLimit // Use max to collect all files for pagination
+		limit = maxFindFilesLimit
 	}
 	if limit > maxFindFilesLimit {
 		limit = maxFindFilesLimit
@@ -149,71 +155,97 @@ func (t *FindFilesTool) Execute(ctx context.Context, params json.RawMessage) (*R
 		return &Result{Success: false, Error: "sort must be one of: none, path, mtime"}, nil
 	}
 
-	globPattern := filepath.Join(basePath, pattern)
-	matches, err := doublestar.FilepathGlob(globPattern)
-	if err != nil {
-		return nil, fmt.Errorf("glob error: %w", err)
-	}
-
 	type fileResult struct {
 		path    string
 		modTime int64
 	}
-	results := make([]fileResult, 0, min(limit, len(matches)))
-	totalIncluded := 0
-
-	for _, match := range matches {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-
-		info, err := os.Stat(match)
-		if err != nil || info.IsDir() {
-			continue
+	var results []fileResult
+	sortResults := func() {
+		switch sortMode {
+		case "path":
+			sort.Slice(results, func(i, j int) bool {
+				return results[i].path < results[j].path
+			})
+		case "mtime":
+			sort.Slice(results, func(i, j int) bool {
+				return results[i].modTime > results[j].modTime
+			})
 		}
+	}
+	totalMatches := 0
 
-		rel, err := filepath.Rel(basePath, match)
+	relPath := func(path string) string {
+		rel, err := filepath.Rel(basePath, path)
 		if err != nil {
-			rel = match
+			return path
 		}
+		return rel
+	}
+	opts := walkOptions{
+		Gitignore:   true,
+		AgentIgnore: agentIgnoreFor(t.workDir),
+		SkipDir: func(path string) bool {
+			rel := relPath(path)
+			return isExcluded(rel, p.Exclude) || (!p.ShowHidden && isHiddenPath(rel))
+		},
+	}
 
+	// Every match is counted, but only the first ones in sort order are kept
+	// while walking (following symlinks), so memory stays bounded
+	globPattern := filepath.Join(basePath, pattern)
+	if globOutsideSandbox(t.workDir, globPattern) {
+		return sandboxResult(globPattern), nil
+	}
+	err := walkGlob(ctx, []string{globPattern}, opts, func(path string, info fs.FileInfo) error {
+		rel := relPath(path)
 		if isExcluded(rel, p.Exclude) {
-			continue
+			return nil
 		}
 
 		// Skip hidden files unless explicitly requested
 		if !p.ShowHidden && isHiddenPath(rel) {
-			continue
+			return nil
 		}
 
-		totalIncluded++
-		if len(results) < limit {
-			results = append(results, fileResult{path: rel, modTime: info.ModTime().UnixNano()})
+		totalMatches++
+		if sortMode == "none" && len(results) >= limit {
+			return nil
 		}
+		results = append(results, fileResult{path: rel, modTime: info.ModTime().UnixNano()})
+		if len(results) >= 2*limit {
+			sortResults()
+			results = results[:limit]
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("glob error: %w", err)
 	}
 
-	// Sort all results first
-	switch sortMode {
-	case "path":
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].path < results[j].path
-		})
-	case "mtime":
-		sort.Slice(results, func(i, j int) bool {
-			return results[i].modTime > results[j].modTime
-		})
-	}
+	// Sort every kept match, so that the cap keeps the first ones in sort order
+	sortResults()
 
-	if len(results) == 0 {
-		return &Result{Success: true, Output: "No files found"}, nil
+	if len(results) > limit {
+		results = results[:limit]
 	}
-
-	// Apply pagination
 	totalResults := len(results)
 	totalPages := (totalResults + pageSize - 1) / pageSize // Ceiling division
+	metadata := map[string]interface{}{
+		"total":       totalMatches,
+		"page":        page,
+		"page_size":   pageSize,
+		"total_pages": totalPages,
+	}
+
+	if totalResults == 0 {
+		return &Result{Success: true, Output: "No files found", Metadata: metadata}, nil
+	}
 
 	if page > totalPages {
-		return &Result{Success: true, Output: fmt.Sprintf("Page %d does not exist. Total pages: %d", page, totalPages)}, nil
+		return &Result{Success: true, Output: fmt.Sprintf("Page %d does not exist. Total pages: %d", page, totalPages), Metadata: metadata}, nil
 	}
 
 	startIdx := (page - 1) * pageSize
@@ -233,16 +265,19 @@ func (t *FindFilesTool) Execute(ctx context.Context, params json.RawMessage) (*R
 
 	// Add pagination info
 	if totalPages > 1 {
-		output += fmt.Sprintf("\n\nPage %d of %d (showing %d-%d of %d files)",
-			page, totalPages, startIdx+1, endIdx, totalResults)
+		output += fmt.Sprintf("\n\nPage %d of %d (showing %d-%d of %d files, %d total matches)",
+			page, totalPages, startIdx+1, endIdx, totalResults, totalMatches)
 		if page < totalPages {
 			output += fmt.Sprintf("\nUse page=%d for next page", page+1)
 		}
-	} else if totalResults > 0 {
-		output += fmt.Sprintf("\n\n(showing all %d files)", totalResults)
+	} else {
+		output += fmt.Sprintf("\n\n(showing %d files, %d total matches)", totalResults, totalMatches)
+	}
+	if totalMatches > totalResults {
+		output += fmt.Sprintf("\nmax_results limits paging to the first %d matches", totalResults)
 	}
 
-	return &Result{Success: true, Output: output}, nil
+	return &Result{Success: true, Output: output, Metadata: metadata}, nil
 }
 
 func isExcluded(path string, patterns []string) bool {
@@ -277,12 +312,5 @@ func isHiddenPath(path string) bool {
 	return false
 }
 
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 // Ensure FindFilesTool implements Tool.
 var _ Tool = (*FindFilesTool)(nil)
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestFindFilesTool_Execute(t *testing.T) {
@@ -125,6 +126,36 @@ func TestFindFilesTool_Pagination(t *testing.T) {
 		assertSuccess(t, result)
 		assertContains(t, result.Output, "Page 99 does not exist")
 	})
+
+	t.Run("max_results caps the sorted pool and page_size the page", func(t *testing.T) {
+		// Newer files sort first, so the cap must keep j, i, h, g and f.
+		base := time.Now().Add(-time.Hour)
+		for i := 0; i < 10; i++ {
+			path := filepath.Join(tempDir, "files", string('a'+byte(i))+".txt")
+			mtime := base.Add(time.Duration(i) * time.Minute)
+			if err := os.Chtimes(path, mtime, mtime); err != nil {
+				t.Fatalf("Chtimes: %v", err)
+			}
+		}
+		params := map[string]interface{}{
+			"pattern":     "files/*.txt",
+			"sort":        "mtime",
+			"max_results": 5,
+			"page":        2,
+			"page_size":   3,
+		}
+		result := executeTool(t, tool, params)
+
+		assertSuccess(t, result)
+		if got := strings.Split(result.Output, "\n\n")[0]; got != filepath.Join("files", "g.txt")+"\n"+filepath.Join("files", "f.txt") {
+			t.Fatalf("page 2 = %q, want g.txt and f.txt", got)
+		}
+		assertContains(t, result.Output, "Page 2 of 2 (showing 4-5 of 5 files, 10 total matches)")
+		assertContains(t, result.Output, "max_results limits paging to the first 5 matches")
+		if result.Metadata["total"] != 10 || result.Metadata["page"] != 2 || result.Metadata["page_size"] != 3 {
+			t.Fatalf("unexpected metadata %v", result.Metadata)
+		}
+	})
 }
 
 func TestFindFilesTool_Sorting(t *testing.T) {
@@ -4,12 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io/fs"
 	"path/filepath"
 	"sort"
 	"strings"
-
-	"github.com/bmatcuk/doublestar/v4"
 )
 
 const maxGlobResults = 1000
@@ -34,10 +32,16 @@ func (t *GlobTool) Name() string {
 	return "glob"
 }
 
+// Idempotent reports that matching paths has no side effects.
+func (t *GlobTool) Idempotent() bool {
+	return true
+}
+
 func (t *GlobTool) Description() string {
 	return `Find files by pattern matching using glob patterns.
 Supports patterns like "**/*.go", "src/**/*.ts", "*.json".
-Returns matching file paths sorted by modification time (newest first).`
+Returns matching file paths sorted by modification time (newest first).
+Skips .git and paths ignored by .gitignore or excluded by .aagentignore.`
 }
 
 func (t *GlobTool) Schema() map[string]interface{} {
@@ -77,62 +81,53 @@ func (t *GlobTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 		}
 	}
 
-	// Use filesystem for globbing (follows symlinks by default)
-	pattern := filepath.Join(basePath, p.Pattern)
-	matches, err := doublestar.FilepathGlob(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("glob error: %w", err)
+	// Walk the filesystem (following symlinks), keeping only the newest
+	// matches so that huge trees do not pile up in memory
+	type fileInfo struct {
+		path    string
+		modTime int64
 	}
+	var files []fileInfo
+	newestFirst := func() {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].modTime > files[j].modTime
+		})
+	}
+	totalMatches := 0
 
-	// Convert absolute paths to relative paths from basePath
-	for i, match := range matches {
-		rel, err := filepath.Rel(basePath, match)
+	pattern := filepath.Join(basePath, p.Pattern)
+	if globOutsideSandbox(t.workDir, pattern) {
+		return sandboxResult(pattern), nil
+	}
+	err := walkGlob(ctx, []string{pattern}, walkOptions{Gitignore: true, AgentIgnore: agentIgnoreFor(t.workDir)}, func(path string, info fs.FileInfo) error {
+		totalMatches++
+		rel, err := filepath.Rel(basePath, path)
 		if err != nil {
-			rel = match
+			rel = path
+		}
+		files = append(files, fileInfo{path: rel, modTime: info.ModTime().UnixNano()})
+		if len(files) >= 2*maxGlobResults {
+			newestFirst()
+			files = files[:maxGlobResults]
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
-		matches[i] = rel
+		return nil, fmt.Errorf("glob error: %w", err)
 	}
 
-	if len(matches) == 0 {
+	if len(files) == 0 {
 		return &Result{
 			Success: true,
 			Output:  "No files found matching pattern",
 		}, nil
 	}
 
-	// Get file info for sorting
-	type fileInfo struct {
-		path    string
-		modTime int64
-	}
-	files := make([]fileInfo, 0, len(matches))
-
-	for _, match := range matches {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
-		}
-
-		fullPath := filepath.Join(basePath, match)
-		info, err := os.Stat(fullPath)
-		if err != nil {
-			continue
-		}
-
-		// Skip directories
-		if info.IsDir() {
-			continue
-		}
-
-		files = append(files, fileInfo{
-			path:    match,
-			modTime: info.ModTime().UnixNano(),
-		})
-	}
-
 	// Sort by modification time (newest first)
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].modTime > files[j].modTime
-	})
+	newestFirst()
 
 	// Limit results
 	if len(files) > maxGlobResults {
@@ -146,8 +141,8 @@ func (t *GlobTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	}
 
 	output := strings.Join(paths, "\n")
-	if len(matches) > maxGlobResults {
-		output += fmt.Sprintf("\n\n(showing %d of %d matches)", maxGlobResults, len(matches))
+	if totalMatches > maxGlobResults {
+		output += fmt.Sprintf("\n\n(showing %d of %d matches)", maxGlobResults, totalMatches)
 	}
 
 	return &Result{
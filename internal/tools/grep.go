@@ -4,19 +4,24 @@ import (
 	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
-
-	"github.com/bmatcuk/doublestar/v4"
 )
 
 const (
 	maxGrepResults    = 500
 	maxGrepLineLength = 500
+	// defaultGrepMaxFileBytes skips files too large to scan line by line,
+	// such as databases and logs, unless max_file_bytes says otherwise.
+	defaultGrepMaxFileBytes = 4 << 20
+	// maxGrepSkippedListed bounds the skipped files named in the output.
+	maxGrepSkippedListed = 5
 )
 
 // GrepTool searches file contents using regex
@@ -26,13 +31,31 @@ type GrepTool struct {
 
 // GrepParams defines parameters for the grep tool
 type GrepParams struct {
-	Pattern           string   `json:"pattern"`
-	Path              string   `json:"path,omitempty"`
-	Include           string   `json:"include,omitempty"` // File pattern filter
-	Exclude           []string `json:"exclude,omitempty"` // Relative path filters
-	MaxResults        int      `json:"max_results,omitempty"`
-	MaxMatchesPerFile int      `json:"max_matches_per_file,omitempty"`
-	Mode              string   `json:"mode,omitempty"` // lines|files|count
+	Pattern           string      `json:"pattern"`
+	Path              string      `json:"path,omitempty"`
+	Include           patternList `json:"include,omitempty"` // File pattern filters, a string or an array
+	Exclude           []string    `json:"exclude,omitempty"` // Relative path filters
+	MaxResults        int         `json:"max_results,omitempty"`
+	MaxMatchesPerFile int         `json:"max_matches_per_file,omitempty"`
+	Mode              string      `json:"mode,omitempty"` // lines|files|count
+	MaxFileBytes      int64       `json:"max_file_bytes,omitempty"`
+}
+
+// patternList accepts a single pattern string as well as an array of them.
+type patternList []string
+
+func (l *patternList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = patternList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("include must be a string or an array of strings")
+	}
+	*l = list
+	return nil
 }
 
 // NewGrepTool creates a new grep tool
@@ -44,10 +67,16 @@ func (t *GrepTool) Name() string {
 	return "grep"
 }
 
+// Idempotent reports that searching has no side effects.
+func (t *GrepTool) Idempotent() bool {
+	return true
+}
+
 func (t *GrepTool) Description() string {
 	return `Search file contents using regular expressions.
 Use mode=files or mode=count for compact outputs.
-Use include/exclude and limits to reduce context usage.`
+Use include/exclude and limits to reduce context usage.
+Skips .git, binary files and paths ignored by .gitignore or excluded by .aagentignore.`
 }
 
 func (t *GrepTool) Schema() map[string]interface{} {
@@ -63,12 +92,18 @@ func (t *GrepTool) Schema() map[string]interface{} {
 				"description": "Directory to search in (optional, defaults to working directory)",
 			},
 			"include": map[string]interface{}{
-				"type":        "string",
-				"description": "File pattern to include (e.g., '*.go', '*.{ts,tsx}')",
+				"description": "File pattern or patterns to include, matched in any directory (e.g., '*.go', '*.{ts,tsx}', ['*.go', 'Makefile'])",
+				"anyOf": []interface{}{
+					map[string]interface{}{"type": "string"},
+					map[string]interface{}{
+						"type":  "array",
+						"items": map[string]interface{}{"type": "string"},
+					},
+				},
 			},
 			"exclude": map[string]interface{}{
 				"type":        "array",
-				"description": "Exclude glob patterns matched against relative paths",
+				"description": "Exclude glob patterns matched against relative paths; a matching directory is skipped entirely",
 				"items": map[string]interface{}{
 					"type": "string",
 				},
@@ -86,6 +121,10 @@ func (t *GrepTool) Schema() map[string]interface{} {
 				"description": "Output mode: lines (default), files, count",
 				"enum":        []string{"lines", "files", "count"},
 			},
+			"max_file_bytes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Skip files larger than this many bytes (default: 4194304, -1 for no limit)",
+			},
 		},
 		"required": []string{"pattern"},
 	}
@@ -130,18 +169,8 @@ func (t *GrepTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 			basePath = filepath.Join(t.workDir, p.Path)
 		}
 	}
-
-	// Determine file pattern
-	filePattern := "**/*"
-	if p.Include != "" {
-		filePattern = "**/" + p.Include
-	}
-
-	// Find files to search (follows symlinks by default)
-	pattern := filepath.Join(basePath, filePattern)
-	files, err := doublestar.FilepathGlob(pattern)
-	if err != nil {
-		return nil, fmt.Errorf("glob error: %w", err)
+	if outsideSandbox(t.workDir, basePath) {
+		return sandboxResult(basePath), nil
 	}
 
 	// Search files
@@ -155,48 +184,71 @@ func (t *GrepTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 		maxResults = maxGrepResults
 	}
 	maxPerFile := p.MaxMatchesPerFile
+	maxFileBytes := p.MaxFileBytes
+	if maxFileBytes == 0 {
+		maxFileBytes = defaultGrepMaxFileBytes
+	}
+	var skippedLarge []string
 
-	for _, file := range files {
-		if ctx.Err() != nil {
-			return nil, ctx.Err()
+	relPath := func(path string) string {
+		rel, err := filepath.Rel(basePath, path)
+		if err != nil {
+			return path
 		}
+		return rel
+	}
+	opts := walkOptions{
+		Gitignore:   true,
+		AgentIgnore: agentIgnoreFor(t.workDir),
+		SkipDir: func(path string) bool {
+			return isExcluded(relPath(path), p.Exclude)
+		},
+	}
 
-		// FilepathGlob returns absolute paths
-		fullPath := file
-		info, err := os.Stat(fullPath)
-		if err != nil || info.IsDir() {
-			continue
+	// Files are searched while walking (following symlinks), so the walk
+	// stops as soon as there are enough matches
+	err = walkGlob(ctx, grepPatterns(basePath, p.Include), opts, func(fullPath string, info fs.FileInfo) error {
+		rel := relPath(fullPath)
+		if isExcluded(rel, p.Exclude) {
+			return nil
 		}
 
-		// Skip binary files (simple heuristic)
-		if isBinaryFile(fullPath) {
-			continue
+		// Cheap checks first: known binary types and oversized files are
+		// skipped without opening them
+		if hasBinaryExtension(fullPath) {
+			return nil
 		}
-
-		// Get relative path for display
-		relPath, err := filepath.Rel(basePath, fullPath)
-		if err != nil {
-			relPath = file
+		if maxFileBytes > 0 && info.Size() > maxFileBytes {
+			skippedLarge = append(skippedLarge, rel)
+			return nil
 		}
-		if isExcluded(relPath, p.Exclude) {
-			continue
+		if isBinaryFile(fullPath) {
+			return nil
 		}
 
-		fileMatches, totalCount := t.searchFile(fullPath, relPath, re, info.ModTime().UnixNano(), maxPerFile, mode == "files")
+		fileMatches, totalCount := t.searchFile(fullPath, rel, re, info.ModTime().UnixNano(), maxPerFile, mode == "files")
 		if totalCount > 0 {
-			fileCounts[relPath] = totalCount
+			fileCounts[rel] = totalCount
 		}
 		matches = append(matches, fileMatches...)
 
 		if len(matches) >= maxResults {
-			break
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
+		return nil, fmt.Errorf("glob error: %w", err)
 	}
 
+	skippedNote := grepSkippedNote(skippedLarge, maxFileBytes)
 	if len(matches) == 0 && len(fileCounts) == 0 {
 		return &Result{
 			Success: true,
-			Output:  "No matches found",
+			Output:  "No matches found" + skippedNote,
 		}, nil
 	}
 
@@ -241,14 +293,99 @@ func (t *GrepTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 		}
 	}
 
-	output := strings.Join(lines, "\n")
+	output := strings.Join(lines, "\n") + skippedNote
+
+	// Files with hits count as read for the write tool
+	hitPaths := make([]string, 0, len(fileCounts))
+	for rel := range fileCounts {
+		hitPaths = append(hitPaths, filepath.Join(basePath, rel))
+	}
 
 	return &Result{
-		Success: true,
-		Output:  output,
+		Success:  true,
+		Output:   output,
+		Metadata: readPathsMetadata(hitPaths...),
 	}, nil
 }
 
+// grepPatterns returns the filesystem patterns for the files under basePath
+// matching any include pattern, or all files without one. Braces are
+// expanded here so that every pattern is a plain one; "**/" also matches
+// files in basePath.
+func grepPatterns(basePath string, include []string) []string {
+	var patterns []string
+	for _, pattern := range include {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			for _, expanded := range expandBraces(pattern) {
+				patterns = append(patterns, filepath.Join(basePath, "**", expanded))
+			}
+		}
+	}
+	if len(patterns) == 0 {
+		patterns = []string{filepath.Join(basePath, "**", "*")}
+	}
+	return patterns
+}
+
+// expandBraces expands {a,b} alternations, including nested and repeated
+// ones, into every pattern they describe: "*.{ts,tsx}" gives "*.ts" and
+// "*.tsx". Unbalanced braces are kept as they are.
+func expandBraces(pattern string) []string {
+	open := strings.IndexByte(pattern, '{')
+	if open < 0 {
+		return []string{pattern}
+	}
+
+	depth := 0
+	start := open + 1
+	var options []string
+	for i := open; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '{':
+			depth++
+		case ',':
+			if depth == 1 {
+				options = append(options, pattern[start:i])
+				start = i + 1
+			}
+		case '}':
+			depth--
+			if depth > 0 {
+				continue
+			}
+			options = append(options, pattern[start:i])
+			prefix, suffixes := pattern[:open], expandBraces(pattern[i+1:])
+			var expanded []string
+			for _, option := range options {
+				for _, head := range expandBraces(option) {
+					for _, suffix := range suffixes {
+						expanded = append(expanded, prefix+head+suffix)
+					}
+				}
+			}
+			return expanded
+		}
+	}
+	return []string{pattern}
+}
+
+// grepSkippedNote tells the model which files were too large to search, since
+// they may hold matches.
+func grepSkippedNote(skipped []string, maxFileBytes int64) string {
+	if len(skipped) == 0 {
+		return ""
+	}
+	listed := skipped
+	if len(listed) > maxGrepSkippedListed {
+		listed = listed[:maxGrepSkippedListed]
+	}
+	note := fmt.Sprintf("\n\n(skipped %d file(s) larger than %d bytes, which may contain matches: %s", len(skipped), maxFileBytes, strings.Join(listed, ", "))
+	if len(skipped) > len(listed) {
+		note += fmt.Sprintf(" and %d more", len(skipped)-len(listed))
+	}
+	return note + "; raise max_file_bytes to search them)"
+}
+
 func (t *GrepTool) searchFile(fullPath, relPath string, re *regexp.Regexp, modTime int64, maxMatches int, stopAtFirst bool) ([]grepMatch, int) {
 	file, err := os.Open(fullPath)
 	if err != nil {
@@ -285,30 +422,5 @@ func (t *GrepTool) searchFile(fullPath, relPath string, re *regexp.Regexp, modTi
 	return matches, totalCount
 }
 
-// isBinaryFile checks if a file appears to be binary
-func isBinaryFile(path string) bool {
-	file, err := os.Open(path)
-	if err != nil {
-		return true
-	}
-	defer file.Close()
-
-	// Read first 512 bytes
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil {
-		return true
-	}
-
-	// Check for null bytes (common in binary files)
-	for i := 0; i < n; i++ {
-		if buf[i] == 0 {
-			return true
-		}
-	}
-
-	return false
-}
-
 // Ensure GrepTool implements Tool
 var _ Tool = (*GrepTool)(nil)
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestExpandBraces(t *testing.T) {
+	tests := map[string][]string{
+		"*.go":                {"*.go"},
+		"*.{ts,tsx}":          {"*.ts", "*.tsx"},
+		"{src,lib}/*.{js,ts}": {"src/*.js", "src/*.ts", "lib/*.js", "lib/*.ts"},
+		"*.{j{s,sx},ts}":      {"*.js", "*.jsx", "*.ts"},
+		"*.{go":               {"*.{go"},
+	}
+	for pattern, want := range tests {
+		if got := expandBraces(pattern); !reflect.DeepEqual(got, want) {
+			t.Errorf("expandBraces(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestGrepIncludePatterns(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, "index.ts", "const answer = 42")
+	createTestFile(t, tempDir, "web/app.tsx", "const answer = <App />")
+	createTestFile(t, tempDir, "main.go", "var answer = 42")
+	createTestFile(t, tempDir, "cmd/tool/main.go", "var answer = 7")
+	createTestFile(t, tempDir, "notes.md", "the answer")
+
+	tool := NewGrepTool(tempDir)
+	files := func(include interface{}) []string {
+		t.Helper()
+		params := map[string]interface{}{"pattern": "answer", "mode": "files"}
+		if include != nil {
+			params["include"] = include
+		}
+		raw, _ := json.Marshal(params)
+		result, err := tool.Execute(context.Background(), raw)
+		if err != nil {
+			t.Fatalf("Execute: %v", err)
+		}
+		assertSuccess(t, result)
+		lines := strings.Split(result.Output, "\n")
+		sort.Strings(lines)
+		return lines
+	}
+
+	if got, want := files("*.{ts,tsx}"), []string{"index.ts", "web/app.tsx"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("include *.{ts,tsx} = %q, want %q", got, want)
+	}
+	if got, want := files([]string{"*.tsx", "*.go"}), []string{"cmd/tool/main.go", "main.go", "web/app.tsx"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("include [*.tsx, *.go] = %q, want %q", got, want)
+	}
+	// Overlapping patterns list each file once
+	if got, want := files([]string{"*.go", "main.*"}), []string{"cmd/tool/main.go", "main.go"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("include [*.go, main.*] = %q, want %q", got, want)
+	}
+	if got := files(nil); len(got) != 5 {
+		t.Errorf("without include got %q, want all 5 files", got)
+	}
+
+	if _, err := tool.Execute(context.Background(), json.RawMessage(`{"pattern":"answer","include":42}`)); err == nil {
+		t.Error("expected an error for a numeric include")
+	}
+}
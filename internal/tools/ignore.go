@@ -0,0 +1,151 @@
+package tools
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// gitignoreFile is the name of the ignore files honoured by file walks.
+const gitignoreFile = ".gitignore"
+
+// ignoreRule is one pattern line of an ignore file.
+type ignoreRule struct {
+	pattern  string
+	negate   bool // "!pattern" re-includes what earlier rules ignored
+	dirOnly  bool // "pattern/" only matches directories
+	anchored bool // patterns with a slash match from the file's directory
+}
+
+// ignoreFile holds the rules of an ignore file and the directory they are
+// relative to.
+type ignoreFile struct {
+	dir   string
+	rules []ignoreRule
+}
+
+// ignoreMatcher applies ignore files with gitignore semantics. Files are
+// ordered from the outermost directory in, so that the rules of nested files
+// and later lines win.
+type ignoreMatcher struct {
+	files []ignoreFile
+}
+
+// parseIgnoreRules reads gitignore syntax: blank lines and # comments are
+// skipped, a leading backslash escapes # and !, a leading ! negates and a
+// trailing / restricts the pattern to directories.
+func parseIgnoreRules(content string) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// loadIgnoreFile reads the named ignore file of dir. It returns false when
+// the file is missing or has no rules.
+func loadIgnoreFile(dir, name string) (ignoreFile, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		return ignoreFile{}, false
+	}
+	rules := parseIgnoreRules(string(data))
+	return ignoreFile{dir: dir, rules: rules}, len(rules) > 0
+}
+
+// with returns a matcher that also applies the ignore file of dir, if any.
+// The receiver is left untouched, so callers can keep one per directory.
+func (m ignoreMatcher) with(dir, name string) ignoreMatcher {
+	file, ok := loadIgnoreFile(dir, name)
+	if !ok {
+		return m
+	}
+	files := make([]ignoreFile, len(m.files), len(m.files)+1)
+	copy(files, m.files)
+	return ignoreMatcher{files: append(files, file)}
+}
+
+// ignored reports whether the last rule matching path ignores it. Parent
+// directories are not checked; walks prune ignored directories instead.
+func (m ignoreMatcher) ignored(p string, isDir bool) bool {
+	ignored := false
+	for _, file := range m.files {
+		rel, err := filepath.Rel(file.dir, p)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, rule := range file.rules {
+			if rule.dirOnly && !isDir {
+				continue
+			}
+			if rule.matches(rel) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+func (r ignoreRule) matches(rel string) bool {
+	if r.anchored {
+		ok, _ := doublestar.Match(r.pattern, rel)
+		return ok
+	}
+	ok, _ := doublestar.Match(r.pattern, path.Base(rel))
+	return ok
+}
+
+// repoIgnoreMatcher returns the matcher for the .gitignore files of the
+// directories from the enclosing git repository's root down to dir. Outside
+// a repository only dir's own file applies.
+func repoIgnoreMatcher(dir string) ignoreMatcher {
+	dirs := []string{dir}
+	for current := dir; ; {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			break
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			// Not in a repository: outer .gitignore files do not apply
+			dirs = dirs[:1]
+			break
+		}
+		current = parent
+		dirs = append(dirs, current)
+	}
+
+	var m ignoreMatcher
+	for i := len(dirs) - 1; i >= 0; i-- {
+		m = m.with(dirs[i], gitignoreFile)
+	}
+	return m
+}
@@ -72,6 +72,12 @@ func (t *InsertLinesTool) Execute(ctx context.Context, params json.RawMessage) (
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(t.workDir, path)
 	}
+	if agentIgnoreFor(t.workDir).excludes(path) {
+		return agentIgnoreResult(p.Path), nil
+	}
+	if outsideSandbox(t.workDir, path) {
+		return sandboxResult(p.Path), nil
+	}
 
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
@@ -133,5 +139,10 @@ func (t *InsertLinesTool) Execute(ctx context.Context, params json.RawMessage) (
 	}, nil
 }
 
+// MutatedPaths returns the file a call changes, for undo backups.
+func (t *InsertLinesTool) MutatedPaths(params json.RawMessage) []string {
+	return mutatedPathParam(t.workDir, params)
+}
+
 // Ensure InsertLinesTool implements Tool.
 var _ Tool = (*InsertLinesTool)(nil)
@@ -28,6 +28,11 @@ func (t *FetchURLTool) Name() string {
 	return "fetch_url"
 }
 
+// Idempotent reports that fetching a page has no side effects.
+func (t *FetchURLTool) Idempotent() bool {
+	return true
+}
+
 func (t *FetchURLTool) Description() string {
 	return "Fetch a web page and return its content as markdown. Useful for reading documentation or articles."
 }
@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+type ListCamerasTool struct{}
+
+func NewListCamerasTool() *ListCamerasTool {
+	return &ListCamerasTool{}
+}
+
+func (t *ListCamerasTool) Name() string {
+	return "list_cameras_tool"
+}
+
+func (t *ListCamerasTool) Description() string {
+	return `List available camera devices.
+Each entry's index is the 1-based camera_index accepted by take_camera_photo_tool.
+Also reports the default camera index configured in the Tools UI and whether it matches a device.`
+}
+
+func (t *ListCamerasTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{},
+	}
+}
+
+func (t *ListCamerasTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	cameras, err := ListCameraDevices(ctx)
+	if err != nil {
+		return &Result{Success: false, Error: err.Error()}, nil
+	}
+	if cameras == nil {
+		cameras = []CameraDevice{}
+	}
+
+	payload := map[string]interface{}{
+		"cameras": cameras,
+		"count":   len(cameras),
+	}
+
+	if defaultIndex := configuredDefaultCameraIndex(); defaultIndex > 0 {
+		payload["default_camera_index"] = defaultIndex
+		valid := false
+		for _, camera := range cameras {
+			if camera.Index == defaultIndex {
+				valid = true
+				break
+			}
+		}
+		payload["default_camera_index_valid"] = valid
+		if !valid {
+			payload["default_camera_index_warning"] = fmt.Sprintf("%s=%d does not match any listed camera", CameraIndexKey, defaultIndex)
+		}
+	}
+
+	out, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return &Result{
+		Success: true,
+		Output:  string(out),
+	}, nil
+}
+
+var _ Tool = (*ListCamerasTool)(nil)
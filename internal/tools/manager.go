@@ -3,13 +3,21 @@ package tools
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"runtime/debug"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/A2gent/brute/internal/config"
 	"github.com/A2gent/brute/internal/llm"
 	"github.com/A2gent/brute/internal/logging"
 	"github.com/A2gent/brute/internal/storage"
+	"github.com/A2gent/brute/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // Tool defines the interface for executable tools
@@ -28,11 +36,55 @@ type Result struct {
 	Metadata map[string]interface{} `json:"metadata,omitempty"`
 }
 
+// DefaultMaxParallel is how many tool calls of one step run at the same time
+// unless AAGENT_TOOL_MAX_PARALLEL or SetMaxParallel says otherwise.
+const DefaultMaxParallel = 4
+
+const envToolMaxParallel = "AAGENT_TOOL_MAX_PARALLEL"
+
+// panicStackLines bounds the stack summary included in a panic result.
+const panicStackLines = 12
+
+// DefaultToolTimeout bounds a single tool call unless a TimeoutPolicy says
+// otherwise.
+const DefaultToolTimeout = 5 * time.Minute
+
+// DurationMetadataKey holds the elapsed milliseconds of a tool call in
+// result metadata.
+const DurationMetadataKey = "duration_ms"
+
+// TimeoutPolicy sets how long a tool call may run. Zero Default selects
+// DefaultToolTimeout and a negative one disables the limit. PerTool overrides
+// the default by tool name; entries <= 0 disable the limit for that tool.
+type TimeoutPolicy struct {
+	Default time.Duration
+	PerTool map[string]time.Duration
+}
+
+// SelfTimedTool is implemented by tools that enforce their own time limit,
+// such as bash with its timeout parameter. The default timeout does not
+// apply to them; a PerTool entry still does.
+type SelfTimedTool interface {
+	SelfTimed() bool
+}
+
+// IdempotentTool is implemented by tools whose calls have no side effects,
+// such as read and grep. Identical calls of such a tool in one parallel
+// batch run once and share the result.
+type IdempotentTool interface {
+	Idempotent() bool
+}
+
 // Manager manages available tools
 type Manager struct {
-	tools   map[string]Tool
-	workDir string
-	mu      sync.RWMutex
+	tools       map[string]Tool
+	workDir     string
+	maxParallel int
+	timeouts    TimeoutPolicy
+	redaction   RedactionPolicy
+	approvals   map[string]string
+	backups     *BackupStore
+	mu          sync.RWMutex
 }
 
 // Clone creates a shallow copy of the manager preserving tool registrations.
@@ -45,8 +97,13 @@ func (m *Manager) Clone() *Manager {
 	defer m.mu.RUnlock()
 
 	cloned := &Manager{
-		tools:   make(map[string]Tool, len(m.tools)),
-		workDir: m.workDir,
+		tools:       make(map[string]Tool, len(m.tools)),
+		workDir:     m.workDir,
+		maxParallel: m.maxParallel,
+		timeouts:    m.timeouts,
+		redaction:   m.redaction,
+		approvals:   m.approvals,
+		backups:     m.backups,
 	}
 	for name, tool := range m.tools {
 		cloned.tools[name] = tool
@@ -62,6 +119,72 @@ func (m *Manager) WorkDir() string {
 	return m.workDir
 }
 
+// SetMaxParallel limits how many calls ExecuteParallel runs at once. Zero
+// restores the default.
+func (m *Manager) SetMaxParallel(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxParallel = n
+}
+
+func (m *Manager) parallelLimit() int {
+	m.mu.RLock()
+	limit := m.maxParallel
+	m.mu.RUnlock()
+	if limit > 0 {
+		return limit
+	}
+	if raw := strings.TrimSpace(os.Getenv(envToolMaxParallel)); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return DefaultMaxParallel
+}
+
+// SetTimeoutPolicy replaces the per-call time limits.
+func (m *Manager) SetTimeoutPolicy(policy TimeoutPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.timeouts = policy
+}
+
+// TimeoutPolicy returns the per-call time limits.
+func (m *Manager) TimeoutPolicy() TimeoutPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.timeouts
+}
+
+// SetRedactionPolicy replaces how secrets are removed from results.
+func (m *Manager) SetRedactionPolicy(policy RedactionPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.redaction = policy
+}
+
+// RedactionPolicy returns how secrets are removed from results.
+func (m *Manager) RedactionPolicy() RedactionPolicy {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.redaction
+}
+
+// timeoutFor returns the limit for one call of tool, or zero for none.
+func (m *Manager) timeoutFor(tool Tool) time.Duration {
+	policy := m.TimeoutPolicy()
+	if timeout, ok := policy.PerTool[tool.Name()]; ok {
+		return max(timeout, 0)
+	}
+	if selfTimed, ok := tool.(SelfTimedTool); ok && selfTimed.SelfTimed() {
+		return 0
+	}
+	if policy.Default == 0 {
+		return DefaultToolTimeout
+	}
+	return max(policy.Default, 0)
+}
+
 // NewManager creates a new tool manager
 func NewManager(workDir string) *Manager {
 	m := &Manager{
@@ -83,6 +206,9 @@ func NewManager(workDir string) *Manager {
 	m.Register(NewFilterTool(workDir))
 	m.Register(NewTakeScreenshotTool(workDir))
 	m.Register(NewTakeCameraPhotoTool(workDir))
+	m.Register(NewListCamerasTool())
+	m.Register(NewRecordAudioTool(workDir))
+	m.Register(NewUseSkillTool())
 	m.Register(NewPipelineTool(m))
 
 	return m
@@ -99,6 +225,16 @@ func (m *Manager) RegisterQuestionTool(store QuestionSessionStore) {
 	m.Register(NewQuestionTool(store))
 }
 
+// RegisterToolOutputTool registers the reader for truncated tool outputs
+func (m *Manager) RegisterToolOutputTool(store ToolOutputStore) {
+	m.Register(NewToolOutputTool(store))
+}
+
+// RegisterMemoryTool registers the persistent memory tool
+func (m *Manager) RegisterMemoryTool(store MemoryStore) {
+	m.Register(NewMemoryTool(store))
+}
+
 // RegisterSessionTaskProgressTool registers the session task progress tool
 func (m *Manager) RegisterSessionTaskProgressTool(store TaskProgressStore) {
 	m.Register(NewSessionTaskProgressTool(store))
@@ -126,53 +262,221 @@ func (m *Manager) Get(name string) (Tool, bool) {
 	return tool, ok
 }
 
-// Execute executes a tool by name with the given parameters
+type toolAccessContextKey struct{}
+
+// WithToolAccess restricts Execute calls made with the returned context,
+// including nested calls such as pipeline steps, to the tools access permits.
+func WithToolAccess(ctx context.Context, access config.ToolAccess) context.Context {
+	return context.WithValue(ctx, toolAccessContextKey{}, access)
+}
+
+// ToolPermitted reports whether access allows the named tool.
+func ToolPermitted(access config.ToolAccess, name string) bool {
+	for _, denied := range access.Denied {
+		if denied == name {
+			return false
+		}
+	}
+	if len(access.Allowed) == 0 {
+		return true
+	}
+	for _, allowed := range access.Allowed {
+		if allowed == name {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterDefinitions returns the definitions access allows.
+func FilterDefinitions(defs []llm.ToolDefinition, access config.ToolAccess) []llm.ToolDefinition {
+	if len(access.Allowed) == 0 && len(access.Denied) == 0 {
+		return defs
+	}
+	filtered := make([]llm.ToolDefinition, 0, len(defs))
+	for _, def := range defs {
+		if ToolPermitted(access, def.Name) {
+			filtered = append(filtered, def)
+		}
+	}
+	return filtered
+}
+
+// Execute executes a tool by name with the given parameters, once the
+// approval policy allows it. A panicking tool is reported as an error instead
+// of crashing the process, and a call that outlives its timeout returns an
+// error without waiting for the tool. With undo enabled, the files a
+// MutatingTool call changes are backed up first.
 func (m *Manager) Execute(ctx context.Context, name string, params json.RawMessage) (*Result, error) {
+	if access, ok := ctx.Value(toolAccessContextKey{}).(config.ToolAccess); ok && !ToolPermitted(access, name) {
+		if len(access.Allowed) > 0 {
+			return nil, fmt.Errorf("tool %s is not available to this agent; available tools: %s", name, strings.Join(access.Allowed, ", "))
+		}
+		return nil, fmt.Errorf("tool %s is not available to this agent", name)
+	}
 	tool, ok := m.Get(name)
 	if !ok {
 		return nil, fmt.Errorf("tool not found: %s", name)
 	}
+	params, err := m.approve(ctx, tool, params)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := m.backUp(ctx, tool, params)
+	result, err := m.runWithTimeout(ctx, tool, params)
+	if err == nil && result != nil && !result.Success && len(saved) > 0 {
+		m.discardBackups(ctx, saved)
+	}
+	return result, err
+}
+
+// runWithTimeout runs tool under its time limit.
+func (m *Manager) runWithTimeout(ctx context.Context, tool Tool, params json.RawMessage) (*Result, error) {
+	name := tool.Name()
+	timeout := m.timeoutFor(tool)
+	if timeout <= 0 {
+		return runTool(ctx, tool, params)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result *Result
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		result, err := runTool(callCtx, tool, params)
+		done <- outcome{result, err}
+	}()
+
+	select {
+	case out := <-done:
+		if ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return nil, toolTimeoutError(name, timeout)
+		}
+		return out.result, out.err
+	case <-callCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		logging.WarnContext(ctx, "Tool %s timed out after %s", name, timeout)
+		return nil, toolTimeoutError(name, timeout)
+	}
+}
+
+func toolTimeoutError(name string, timeout time.Duration) error {
+	return fmt.Errorf("tool %s timed out after %s", name, timeout)
+}
+
+// runTool executes tool and converts a panic into an error.
+func runTool(ctx context.Context, tool Tool, params json.RawMessage) (result *Result, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			logging.ErrorContext(ctx, "Tool %s panicked: %v\n%s", tool.Name(), r, stack)
+			result = nil
+			err = fmt.Errorf("tool %s panicked: %v\n%s", tool.Name(), r, summarizeStack(stack))
+		}
+	}()
 	return tool.Execute(ctx, params)
 }
 
+// summarizeStack keeps the first frames of a goroutine stack trace.
+func summarizeStack(stack []byte) string {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	if len(lines) > panicStackLines {
+		lines = append(lines[:panicStackLines], "...")
+	}
+	return strings.Join(lines, "\n")
+}
+
 // ExecuteParallel executes multiple tool calls in parallel
 func (m *Manager) ExecuteParallel(ctx context.Context, calls []llm.ToolCall) []llm.ToolResult {
+	return m.ExecuteParallelWithHooks(ctx, calls, ExecuteHooks{})
+}
+
+// ExecuteHooks observe individual calls made by ExecuteParallelWithHooks.
+// They run on the goroutine executing the call, so they may be invoked
+// concurrently.
+type ExecuteHooks struct {
+	OnStart func(call llm.ToolCall)
+	OnDone  func(result llm.ToolResult, duration time.Duration)
+}
+
+// ExecuteParallelWithHooks executes tool calls concurrently like
+// ExecuteParallel and reports each call's start and completion. At most
+// parallelLimit calls run at once; calls still queued when ctx is cancelled
+// are not started and return an error result. Identical calls of an
+// IdempotentTool run once and every duplicate gets a copy of the result.
+func (m *Manager) ExecuteParallelWithHooks(ctx context.Context, calls []llm.ToolCall, hooks ExecuteHooks) []llm.ToolResult {
 	results := make([]llm.ToolResult, len(calls))
 	var wg sync.WaitGroup
+	limit := m.parallelLimit()
+	slots := make(chan struct{}, limit)
+	duplicates := m.duplicateCalls(calls)
+	cache := resultCacheFromContext(ctx)
+	if cache != nil && !m.allIdempotent(calls) {
+		// The batch may change what earlier calls saw, and its own
+		// idempotent calls may race with the change, so none is cached.
+		cache.Clear()
+		cache = nil
+	}
+	skip := make(map[int]bool)
+	for _, dups := range duplicates {
+		for _, dup := range dups {
+			skip[dup] = true
+		}
+	}
 
-	logging.Debug("Executing %d tool(s) in parallel", len(calls))
+	logging.Debug("Executing %d tool(s) in parallel (limit %d)", len(calls), limit)
 
 	for i, call := range calls {
+		if skip[i] {
+			continue
+		}
 		wg.Add(1)
 		go func(idx int, tc llm.ToolCall) {
 			defer wg.Done()
 
-			start := time.Now()
-			result, err := m.Execute(ctx, tc.Name, json.RawMessage(tc.Input))
-			duration := time.Since(start)
-
-			tr := llm.ToolResult{
-				ToolCallID: tc.ID,
-				Name:       tc.Name,
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+			}
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				results[idx] = llm.ToolResult{
+					ToolCallID: tc.ID,
+					Name:       tc.Name,
+					Content:    fmt.Sprintf("Error: tool call not started: %v", ctxErr),
+					IsError:    true,
+				}
+				for _, dup := range duplicates[idx] {
+					results[dup] = sharedResult(results[idx], calls[dup])
+				}
+				return
 			}
 
-			if err != nil {
-				tr.Content = fmt.Sprintf("Error: %v", err)
-				tr.IsError = true
-				logging.LogToolExecution(tc.Name, false, duration)
-				logging.Debug("Tool %s error: %v", tc.Name, err)
-			} else if !result.Success {
-				tr.Content = fmt.Sprintf("Error: %s", result.Error)
-				tr.IsError = true
-				logging.LogToolExecution(tc.Name, false, duration)
-				logging.Debug("Tool %s failed: %s", tc.Name, result.Error)
-			} else {
-				tr.Content = result.Output
-				tr.Metadata = result.Metadata
-				logging.LogToolExecution(tc.Name, true, duration)
+			if hooks.OnStart != nil {
+				hooks.OnStart(tc)
+				for _, dup := range duplicates[idx] {
+					hooks.OnStart(calls[dup])
+				}
 			}
+			tr, duration := m.executeCached(ctx, cache, tc)
 
 			results[idx] = tr
+			for _, dup := range duplicates[idx] {
+				results[dup] = sharedResult(tr, calls[dup])
+			}
+			if hooks.OnDone != nil {
+				hooks.OnDone(tr, duration)
+				for _, dup := range duplicates[idx] {
+					hooks.OnDone(results[dup], duration)
+				}
+			}
 		}(i, call)
 	}
 
@@ -180,6 +484,137 @@ func (m *Manager) ExecuteParallel(ctx context.Context, calls []llm.ToolCall) []l
 	return results
 }
 
+// executeCall runs one tool call and turns its outcome into a result.
+func (m *Manager) executeCall(ctx context.Context, tc llm.ToolCall) (llm.ToolResult, time.Duration) {
+	spanCtx, span := tracing.Start(ctx, "tool.execute",
+		attribute.String("tool.name", tc.Name),
+		attribute.String("tool.call_id", tc.ID),
+		attribute.Int("tool.input_bytes", len(tc.Input)),
+	)
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("tool.input", tracing.Truncate(tc.Input, tracing.MaxAttributeLength)))
+	}
+	start := time.Now()
+	result, err := m.Execute(withToolCallID(spanCtx, tc.ID), tc.Name, json.RawMessage(tc.Input))
+	duration := time.Since(start)
+	if err == nil && result.Success {
+		recordReadPaths(ctx, result)
+	}
+	if err == nil && !result.Success {
+		tracing.Fail(span, result.Error)
+	}
+	tracing.End(span, err)
+
+	tr := llm.ToolResult{
+		ToolCallID: tc.ID,
+		Name:       tc.Name,
+		StartedAt:  start,
+		DurationMs: duration.Milliseconds(),
+	}
+	if err == nil && result.Success && result.Metadata != nil {
+		tr.Metadata = result.Metadata
+	} else {
+		tr.Metadata = map[string]interface{}{}
+	}
+	tr.Metadata[DurationMetadataKey] = duration.Milliseconds()
+
+	if err != nil {
+		tr.Content = fmt.Sprintf("Error: %v", err)
+		tr.IsError = true
+		logging.LogToolExecutionContext(ctx, tc.Name, false, duration)
+		logging.DebugContext(ctx, "Tool %s error: %v", tc.Name, err)
+	} else if !result.Success {
+		tr.Content = fmt.Sprintf("Error: %s", result.Error)
+		tr.IsError = true
+		logging.LogToolExecutionContext(ctx, tc.Name, false, duration)
+		logging.DebugContext(ctx, "Tool %s failed: %s", tc.Name, result.Error)
+	} else {
+		tr.Content = result.Output
+		logging.LogToolExecutionContext(ctx, tc.Name, true, duration)
+	}
+	// Results are stored with the session and sent to the provider
+	tr.Content = m.RedactionPolicy().redact(tr.Content)
+	tr.OutputBytes = len(tr.Content)
+	return tr, duration
+}
+
+// duplicateCalls maps the index of the first call of each repeated
+// idempotent call to the indexes of its repeats. Inputs are compared as
+// JSON, so key order and spacing do not matter.
+func (m *Manager) duplicateCalls(calls []llm.ToolCall) map[int][]int {
+	var duplicates map[int][]int
+	first := make(map[string]int)
+	for i, call := range calls {
+		if !m.isIdempotent(call.Name) {
+			continue
+		}
+		key := callKey(call)
+		if primary, ok := first[key]; ok {
+			if duplicates == nil {
+				duplicates = make(map[int][]int)
+			}
+			duplicates[primary] = append(duplicates[primary], i)
+			logging.Debug("Tool call %s repeats %s (%s); running it once", call.ID, calls[primary].ID, call.Name)
+			continue
+		}
+		first[key] = i
+	}
+	return duplicates
+}
+
+// allIdempotent reports whether every call is of an IdempotentTool.
+func (m *Manager) allIdempotent(calls []llm.ToolCall) bool {
+	for _, call := range calls {
+		if !m.isIdempotent(call.Name) {
+			return false
+		}
+	}
+	return true
+}
+
+// isIdempotent reports whether the named tool is an IdempotentTool.
+func (m *Manager) isIdempotent(name string) bool {
+	tool, ok := m.Get(name)
+	if !ok {
+		return false
+	}
+	idempotent, ok := tool.(IdempotentTool)
+	return ok && idempotent.Idempotent()
+}
+
+// callKey identifies a call by its tool and canonical input.
+func callKey(call llm.ToolCall) string {
+	return call.Name + "\x00" + canonicalInput(call.Input)
+}
+
+// canonicalInput re-encodes a JSON tool input with sorted keys and no
+// spacing, or returns it unchanged when it does not parse.
+func canonicalInput(input string) string {
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(input), &decoded); err != nil {
+		return input
+	}
+	encoded, err := json.Marshal(decoded)
+	if err != nil {
+		return input
+	}
+	return string(encoded)
+}
+
+// sharedResult copies the result of a call to a duplicate call.
+func sharedResult(result llm.ToolResult, call llm.ToolCall) llm.ToolResult {
+	result.ToolCallID = call.ID
+	result.Name = call.Name
+	if result.Metadata != nil {
+		metadata := make(map[string]interface{}, len(result.Metadata))
+		for k, v := range result.Metadata {
+			metadata[k] = v
+		}
+		result.Metadata = metadata
+	}
+	return result
+}
+
 // GetDefinitions returns tool definitions for LLM
 func (m *Manager) GetDefinitions() []llm.ToolDefinition {
 	m.mu.RLock()
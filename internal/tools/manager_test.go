@@ -0,0 +1,377 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/A2gent/brute/internal/llm"
+)
+
+type panicTool struct{}
+
+func (panicTool) Name() string                   { return "panic_tool" }
+func (panicTool) Description() string            { return "Always panics" }
+func (panicTool) Schema() map[string]interface{} { return map[string]interface{}{"type": "object"} }
+func (panicTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	panic("boom")
+}
+
+// concurrencyTool records how many of its calls run at the same time.
+type concurrencyTool struct {
+	running atomic.Int32
+	max     atomic.Int32
+	calls   atomic.Int32
+}
+
+func (c *concurrencyTool) Name() string        { return "slow_tool" }
+func (c *concurrencyTool) Description() string { return "Sleeps briefly" }
+func (c *concurrencyTool) Schema() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (c *concurrencyTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	c.calls.Add(1)
+	n := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		prev := c.max.Load()
+		if n <= prev || c.max.CompareAndSwap(prev, n) {
+			break
+		}
+	}
+	select {
+	case <-time.After(20 * time.Millisecond):
+	case <-ctx.Done():
+	}
+	return &Result{Success: true, Output: "done"}, nil
+}
+
+func newBareManager() *Manager {
+	return &Manager{tools: make(map[string]Tool)}
+}
+
+func slowCalls(n int) []llm.ToolCall {
+	calls := make([]llm.ToolCall, n)
+	for i := range calls {
+		calls[i] = llm.ToolCall{ID: fmt.Sprintf("call-%d", i), Name: "slow_tool", Input: `{}`}
+	}
+	return calls
+}
+
+func TestExecuteParallelRecoversFromPanics(t *testing.T) {
+	m := newBareManager()
+	m.Register(panicTool{})
+	m.Register(&concurrencyTool{})
+
+	results := m.ExecuteParallel(context.Background(), []llm.ToolCall{
+		{ID: "call-1", Name: "panic_tool", Input: `{}`},
+		{ID: "call-2", Name: "slow_tool", Input: `{}`},
+	})
+
+	if !results[0].IsError {
+		t.Fatalf("panicking tool should produce an error result: %+v", results[0])
+	}
+	if !strings.Contains(results[0].Content, "panicked: boom") {
+		t.Fatalf("error should contain the panic message, got %q", results[0].Content)
+	}
+	if !strings.Contains(results[0].Content, "goroutine") {
+		t.Fatalf("error should contain a stack summary, got %q", results[0].Content)
+	}
+	if results[1].IsError || results[1].Content != "done" {
+		t.Fatalf("other calls should be unaffected: %+v", results[1])
+	}
+}
+
+func TestExecuteParallelLimitsConcurrency(t *testing.T) {
+	m := newBareManager()
+	tool := &concurrencyTool{}
+	m.Register(tool)
+	m.SetMaxParallel(4)
+
+	results := m.ExecuteParallel(context.Background(), slowCalls(50))
+
+	if got := tool.calls.Load(); got != 50 {
+		t.Fatalf("expected all 50 calls to run, got %d", got)
+	}
+	if got := tool.max.Load(); got > 4 || got < 2 {
+		t.Fatalf("observed max parallelism %d, want between 2 and 4", got)
+	}
+	for i, result := range results {
+		if result.IsError || result.ToolCallID != fmt.Sprintf("call-%d", i) {
+			t.Fatalf("unexpected result %d: %+v", i, result)
+		}
+	}
+}
+
+// idempotentTool is a concurrencyTool that declares itself idempotent.
+type idempotentTool struct {
+	concurrencyTool
+}
+
+func (t *idempotentTool) Name() string     { return "lookup_tool" }
+func (t *idempotentTool) Idempotent() bool { return true }
+
+func TestExecuteParallelRunsIdenticalIdempotentCallsOnce(t *testing.T) {
+	m := newBareManager()
+	lookup := &idempotentTool{}
+	slow := &concurrencyTool{}
+	m.Register(lookup)
+	m.Register(slow)
+
+	calls := []llm.ToolCall{
+		{ID: "call-1", Name: "lookup_tool", Input: `{"path":"a","limit":5}`},
+		{ID: "call-2", Name: "slow_tool", Input: `{}`},
+		{ID: "call-3", Name: "lookup_tool", Input: `{ "limit": 5, "path": "a" }`},
+		{ID: "call-4", Name: "lookup_tool", Input: `{"path":"b"}`},
+		{ID: "call-5", Name: "slow_tool", Input: `{}`},
+	}
+	var started, finished atomic.Int32
+	hooks := ExecuteHooks{
+		OnStart: func(llm.ToolCall) { started.Add(1) },
+		OnDone:  func(llm.ToolResult, time.Duration) { finished.Add(1) },
+	}
+	results := m.ExecuteParallelWithHooks(context.Background(), calls, hooks)
+
+	if got := lookup.calls.Load(); got != 2 {
+		t.Fatalf("lookup_tool ran %d times, want 2", got)
+	}
+	if got := slow.calls.Load(); got != 2 {
+		t.Fatalf("non-idempotent slow_tool ran %d times, want 2", got)
+	}
+	for i, result := range results {
+		if result.ToolCallID != calls[i].ID || result.Name != calls[i].Name || result.IsError || result.Content != "done" {
+			t.Fatalf("result %d = %+v, want the result of %s", i, result, calls[i].ID)
+		}
+	}
+	if started.Load() != 5 || finished.Load() != 5 {
+		t.Fatalf("hooks ran %d/%d times, want 5 each", started.Load(), finished.Load())
+	}
+}
+
+func TestExecuteParallelSkipsQueuedCallsWhenCancelled(t *testing.T) {
+	m := newBareManager()
+	tool := &concurrencyTool{}
+	m.Register(tool)
+	m.SetMaxParallel(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var once sync.Once
+	hooks := ExecuteHooks{OnStart: func(llm.ToolCall) { once.Do(cancel) }}
+
+	done := make(chan []llm.ToolResult)
+	go func() { done <- m.ExecuteParallelWithHooks(ctx, slowCalls(10), hooks) }()
+
+	var results []llm.ToolResult
+	select {
+	case results = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ExecuteParallel did not return after cancellation")
+	}
+
+	if got := tool.calls.Load(); got != 1 {
+		t.Fatalf("expected only the running call to execute, got %d", got)
+	}
+	skipped := 0
+	for _, result := range results {
+		if result.IsError && strings.Contains(result.Content, "not started") {
+			skipped++
+		}
+	}
+	if skipped != 9 {
+		t.Fatalf("expected 9 queued calls to be skipped, got %d", skipped)
+	}
+}
+
+func TestParallelLimitFromEnv(t *testing.T) {
+	m := newBareManager()
+	t.Setenv(envToolMaxParallel, "")
+	if got := m.parallelLimit(); got != DefaultMaxParallel {
+		t.Fatalf("default limit = %d, want %d", got, DefaultMaxParallel)
+	}
+	t.Setenv(envToolMaxParallel, "7")
+	if got := m.parallelLimit(); got != 7 {
+		t.Fatalf("env limit = %d, want 7", got)
+	}
+	m.SetMaxParallel(2)
+	if got := m.parallelLimit(); got != 2 {
+		t.Fatalf("explicit limit = %d, want 2", got)
+	}
+}
+
+// hangingTool ignores its context and blocks until released.
+type hangingTool struct {
+	release chan struct{}
+}
+
+func (h *hangingTool) Name() string        { return "hanging_tool" }
+func (h *hangingTool) Description() string { return "Blocks until released" }
+func (h *hangingTool) Schema() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (h *hangingTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	<-h.release
+	return &Result{Success: true, Output: "late"}, nil
+}
+
+func TestExecuteTimesOutHungTool(t *testing.T) {
+	m := newBareManager()
+	tool := &hangingTool{release: make(chan struct{})}
+	defer close(tool.release)
+	m.Register(tool)
+	m.SetTimeoutPolicy(TimeoutPolicy{Default: 50 * time.Millisecond})
+
+	start := time.Now()
+	results := m.ExecuteParallel(context.Background(), []llm.ToolCall{{ID: "call-1", Name: "hanging_tool", Input: `{}`}})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("hung tool was not abandoned after its timeout (took %s)", elapsed)
+	}
+	if !results[0].IsError || !strings.Contains(results[0].Content, "tool hanging_tool timed out after 50ms") {
+		t.Fatalf("unexpected timeout result: %+v", results[0])
+	}
+	if _, ok := results[0].Metadata[DurationMetadataKey]; !ok {
+		t.Fatalf("result should carry %s metadata: %+v", DurationMetadataKey, results[0].Metadata)
+	}
+	if results[0].StartedAt.Before(start) || results[0].DurationMs < 50 {
+		t.Fatalf("result should record when the call started and how long it ran: %+v", results[0])
+	}
+}
+
+func TestExecuteRecordsOutputBytes(t *testing.T) {
+	m := newBareManager()
+	m.Register(echoTool{})
+	cache := NewResultCache()
+	call := llm.ToolCall{ID: "call-1", Name: "echo_tool", Input: `{"a":1}`}
+
+	first, _ := m.executeCached(context.Background(), cache, call)
+	if first.OutputBytes != len(`{"a":1}`) || first.StartedAt.IsZero() {
+		t.Fatalf("unexpected result %+v", first)
+	}
+	cached, _ := m.executeCached(context.Background(), cache, call)
+	if cached.OutputBytes != first.OutputBytes || cached.DurationMs != 0 || cached.StartedAt.Before(first.StartedAt) {
+		t.Fatalf("cached result should report its own start and no duration: %+v", cached)
+	}
+}
+
+func TestTimeoutForHonoursSelfTimedAndOverrides(t *testing.T) {
+	m := newBareManager()
+	bash := NewBashTool(t.TempDir())
+	slow := &concurrencyTool{}
+
+	if got := m.timeoutFor(slow); got != DefaultToolTimeout {
+		t.Fatalf("default timeout = %s, want %s", got, DefaultToolTimeout)
+	}
+	if got := m.timeoutFor(bash); got != 0 {
+		t.Fatalf("bash manages its own timeout, got %s", got)
+	}
+
+	m.SetTimeoutPolicy(TimeoutPolicy{
+		Default: -1,
+		PerTool: map[string]time.Duration{"bash": 10 * time.Minute},
+	})
+	if got := m.timeoutFor(slow); got != 0 {
+		t.Fatalf("negative default should disable the limit, got %s", got)
+	}
+	if got := m.timeoutFor(bash); got != 10*time.Minute {
+		t.Fatalf("per-tool override should apply to bash, got %s", got)
+	}
+}
+
+func TestExecuteRefusesToolsOutsideAccess(t *testing.T) {
+	m := newBareManager()
+	m.Register(&concurrencyTool{})
+	m.Register(panicTool{})
+
+	ctx := WithToolAccess(context.Background(), config.ToolAccess{Allowed: []string{"slow_tool"}})
+	if _, err := m.Execute(ctx, "slow_tool", json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("allowed tool failed: %v", err)
+	}
+	_, err := m.Execute(ctx, "panic_tool", json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "not available to this agent; available tools: slow_tool") {
+		t.Fatalf("expected refusal, got %v", err)
+	}
+
+	ctx = WithToolAccess(context.Background(), config.ToolAccess{Denied: []string{"slow_tool"}})
+	if _, err := m.Execute(ctx, "slow_tool", json.RawMessage(`{}`)); err == nil {
+		t.Fatal("denied tool should be refused")
+	}
+
+	defs := FilterDefinitions(m.GetDefinitions(), config.ToolAccess{Denied: []string{"slow_tool"}})
+	if len(defs) != 1 || defs[0].Name != "panic_tool" {
+		t.Fatalf("unexpected filtered definitions: %+v", defs)
+	}
+}
+
+type echoTool struct{}
+
+func (echoTool) Name() string                   { return "echo_tool" }
+func (echoTool) Description() string            { return "Returns its arguments" }
+func (echoTool) Schema() map[string]interface{} { return map[string]interface{}{"type": "object"} }
+func (echoTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	return &Result{Success: true, Output: string(params)}, nil
+}
+
+func TestExecuteAppliesApprovalPolicy(t *testing.T) {
+	m := newBareManager()
+	m.Register(echoTool{})
+	m.Register(&concurrencyTool{})
+	m.SetApprovalPolicy(map[string]string{"echo_tool": "ask", "slow_tool": "deny"})
+
+	if _, err := m.Execute(context.Background(), "slow_tool", json.RawMessage(`{}`)); err == nil {
+		t.Fatal("denied tool should be refused")
+	}
+	if _, err := m.Execute(context.Background(), "echo_tool", json.RawMessage(`{"a":1}`)); err == nil || !strings.Contains(err.Error(), "needs approval") {
+		t.Fatalf("ask without an approver should be refused, got %v", err)
+	}
+
+	var asked []ApprovalRequest
+	decision := ApprovalDecision{}
+	ctx := WithApprover(context.Background(), func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		asked = append(asked, req)
+		return decision, nil
+	})
+	if _, err := m.Execute(ctx, "echo_tool", json.RawMessage(`{"a":1}`)); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected the user's denial, got %v", err)
+	}
+	decision = ApprovalDecision{Approved: true, Params: json.RawMessage(`{"a":2}`)}
+	result, err := m.Execute(ctx, "echo_tool", json.RawMessage(`{"a":1}`))
+	if err != nil || result.Output != `{"a":2}` {
+		t.Fatalf("expected the edited arguments to run, got %+v, %v", result, err)
+	}
+	if len(asked) != 2 || asked[0].Tool != "echo_tool" || string(asked[0].Params) != `{"a":1}` {
+		t.Fatalf("unexpected approval requests %+v", asked)
+	}
+}
+
+// recordingTool counts its executions.
+type recordingTool struct{ runs atomic.Int32 }
+
+func (*recordingTool) Name() string        { return "recording_tool" }
+func (*recordingTool) Description() string { return "Counts its calls" }
+func (*recordingTool) Schema() map[string]interface{} {
+	return map[string]interface{}{"type": "object"}
+}
+func (r *recordingTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	r.runs.Add(1)
+	return &Result{Success: true, Output: "ran"}, nil
+}
+
+func TestAskToolDoesNotRunWithoutApprover(t *testing.T) {
+	tool := &recordingTool{}
+	m := newBareManager()
+	m.Register(tool)
+	m.SetApprovalPolicy(map[string]string{"recording_tool": ApprovalAsk})
+
+	result := m.ExecuteParallel(context.Background(), []llm.ToolCall{{ID: "call", Name: "recording_tool", Input: `{}`}})[0]
+	if !result.IsError || !strings.Contains(result.Content, "needs approval") {
+		t.Fatalf("expected an approval error, got %+v", result)
+	}
+	if runs := tool.runs.Load(); runs != 0 {
+		t.Fatalf("ask tool ran %d times without an approver", runs)
+	}
+}
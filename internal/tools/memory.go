@@ -0,0 +1,200 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// MemoryToolName is the name the persistent memory tool is registered under.
+const MemoryToolName = "memory"
+
+const (
+	// MaxMemoryValueBytes caps a single memory so recalled notes cannot
+	// crowd out the rest of the context.
+	MaxMemoryValueBytes = 8 * 1024
+	maxMemoryKeyLength  = 200
+)
+
+// MemoryStore persists memories across agent runs.
+type MemoryStore interface {
+	SaveMemory(mem *storage.Memory) error
+	GetMemory(scope, key string) (*storage.Memory, error)
+	ListMemories(scope string) ([]*storage.Memory, error)
+}
+
+// MemoryTool keeps key-value notes that survive between runs, either shared
+// by all runs or private to the recurring job being executed.
+type MemoryTool struct {
+	store MemoryStore
+	// mu serializes read-modify-write appends from parallel tool calls.
+	mu sync.Mutex
+}
+
+// MemoryParams defines parameters for the memory tool
+type MemoryParams struct {
+	Action string `json:"action"`
+	Key    string `json:"key,omitempty"`
+	Value  string `json:"value,omitempty"`
+	Scope  string `json:"scope,omitempty"`
+}
+
+// NewMemoryTool creates a new memory tool
+func NewMemoryTool(store MemoryStore) *MemoryTool {
+	return &MemoryTool{store: store}
+}
+
+func (t *MemoryTool) Name() string {
+	return MemoryToolName
+}
+
+func (t *MemoryTool) Description() string {
+	return fmt.Sprintf(`Remember notes across runs. Scheduled jobs start without the previous conversation,
+so store anything the next run needs (last seen values, what was already reported) here.
+
+Actions:
+- get: read the value of key
+- set: replace the value of key
+- append: add value to the end of key on a new line
+- list: show the keys in the scope with their sizes
+
+Scope "job" (the default during a recurring job run) is private to that job; "global"
+is shared by all runs. Values are limited to %d bytes.`, MaxMemoryValueBytes)
+}
+
+func (t *MemoryTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"action": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"get", "set", "append", "list"},
+				"description": "Operation to perform",
+			},
+			"key": map[string]interface{}{
+				"type":        "string",
+				"description": "Memory key, e.g. \"site/last_headline\" (required except for list)",
+			},
+			"value": map[string]interface{}{
+				"type":        "string",
+				"description": "Value to store (set, append)",
+			},
+			"scope": map[string]interface{}{
+				"type":        "string",
+				"enum":        []string{"job", "global"},
+				"description": "job (default inside a recurring job run) or global",
+			},
+		},
+		"required": []string{"action"},
+	}
+}
+
+func (t *MemoryTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	var p MemoryParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	scope, err := resolveMemoryScope(ctx, p.Scope)
+	if err != nil {
+		return &Result{Success: false, Error: err.Error()}, nil
+	}
+
+	action := strings.ToLower(strings.TrimSpace(p.Action))
+	if action == "list" {
+		return t.list(scope)
+	}
+
+	key := strings.TrimSpace(p.Key)
+	if key == "" {
+		return &Result{Success: false, Error: "key is required"}, nil
+	}
+	if len(key) > maxMemoryKeyLength {
+		return &Result{Success: false, Error: fmt.Sprintf("key must be at most %d characters", maxMemoryKeyLength)}, nil
+	}
+
+	switch action {
+	case "get":
+		mem, err := t.store.GetMemory(scope, key)
+		if err != nil {
+			return &Result{Success: true, Output: fmt.Sprintf("No memory stored for %s in %s scope", key, scope)}, nil
+		}
+		return &Result{Success: true, Output: mem.Value}, nil
+	case "set":
+		return t.save(scope, key, p.Value)
+	case "append":
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		value := p.Value
+		if mem, err := t.store.GetMemory(scope, key); err == nil && mem.Value != "" {
+			value = mem.Value + "\n" + p.Value
+		}
+		return t.save(scope, key, value)
+	default:
+		return &Result{Success: false, Error: fmt.Sprintf("unknown action %q (use get, set, append or list)", p.Action)}, nil
+	}
+}
+
+func (t *MemoryTool) save(scope, key, value string) (*Result, error) {
+	if len(value) > MaxMemoryValueBytes {
+		return &Result{
+			Success: false,
+			Error:   fmt.Sprintf("value would be %d bytes; memories are limited to %d bytes, so summarize or replace it with set", len(value), MaxMemoryValueBytes),
+		}, nil
+	}
+	now := time.Now()
+	if err := t.store.SaveMemory(&storage.Memory{Scope: scope, Key: key, Value: value, CreatedAt: now, UpdatedAt: now}); err != nil {
+		return &Result{Success: false, Error: fmt.Sprintf("failed to save memory: %v", err)}, nil
+	}
+	return &Result{Success: true, Output: fmt.Sprintf("Saved %s in %s scope (%d bytes)", key, scope, len(value))}, nil
+}
+
+func (t *MemoryTool) list(scope string) (*Result, error) {
+	memories, err := t.store.ListMemories(scope)
+	if err != nil {
+		return &Result{Success: false, Error: fmt.Sprintf("failed to list memories: %v", err)}, nil
+	}
+	if len(memories) == 0 {
+		return &Result{Success: true, Output: fmt.Sprintf("No memories in %s scope", scope)}, nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d memories in %s scope:\n", len(memories), scope)
+	for _, mem := range memories {
+		fmt.Fprintf(&b, "- %s (%d bytes, updated %s)\n", mem.Key, len(mem.Value), mem.UpdatedAt.Format(time.RFC3339))
+	}
+	return &Result{Success: true, Output: strings.TrimRight(b.String(), "\n")}, nil
+}
+
+// resolveMemoryScope maps the scope parameter to a storage scope. Without a
+// scope, job runs use their job scope and everything else the global one.
+func resolveMemoryScope(ctx context.Context, scope string) (string, error) {
+	jobID := getJobIDFromContext(ctx)
+	switch strings.ToLower(strings.TrimSpace(scope)) {
+	case "":
+		if jobID != "" {
+			return storage.MemoryJobScope(jobID), nil
+		}
+		return storage.MemoryScopeGlobal, nil
+	case "global":
+		return storage.MemoryScopeGlobal, nil
+	case "job":
+		if jobID == "" {
+			return "", fmt.Errorf("job scope is only available during a recurring job run; use scope global")
+		}
+		return storage.MemoryJobScope(jobID), nil
+	default:
+		return "", fmt.Errorf("unknown scope %q (use job or global)", scope)
+	}
+}
+
+func getJobIDFromContext(ctx context.Context) string {
+	if jobID, ok := ctx.Value("job_id").(string); ok {
+		return jobID
+	}
+	return ""
+}
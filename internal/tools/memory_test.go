@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+func runMemoryTool(t *testing.T, tool *MemoryTool, ctx context.Context, params string) *Result {
+	t.Helper()
+	result, err := tool.Execute(ctx, json.RawMessage(params))
+	if err != nil {
+		t.Fatalf("Execute(%s): %v", params, err)
+	}
+	return result
+}
+
+func TestMemoryToolScopesNotesToJobs(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	tool := NewMemoryTool(store)
+	jobCtx := context.WithValue(context.Background(), "job_id", "job-1")
+
+	if result := runMemoryTool(t, tool, jobCtx, `{"action":"set","key":"headline","value":"Monday"}`); !result.Success {
+		t.Fatalf("set failed: %s", result.Error)
+	}
+	if result := runMemoryTool(t, tool, jobCtx, `{"action":"append","key":"headline","value":"Tuesday"}`); !result.Success {
+		t.Fatalf("append failed: %s", result.Error)
+	}
+	if result := runMemoryTool(t, tool, jobCtx, `{"action":"get","key":"headline"}`); result.Output != "Monday\nTuesday" {
+		t.Fatalf("get = %q", result.Output)
+	}
+	if mem, err := store.GetMemory(storage.MemoryJobScope("job-1"), "headline"); err != nil || mem.Value != "Monday\nTuesday" {
+		t.Fatalf("memory should be stored in the job scope: %+v, %v", mem, err)
+	}
+
+	otherJob := context.WithValue(context.Background(), "job_id", "job-2")
+	if result := runMemoryTool(t, tool, otherJob, `{"action":"get","key":"headline"}`); !strings.Contains(result.Output, "No memory stored") {
+		t.Fatalf("another job should not see the note, got %q", result.Output)
+	}
+	if result := runMemoryTool(t, tool, context.Background(), `{"action":"list"}`); !strings.Contains(result.Output, "No memories in global scope") {
+		t.Fatalf("runs outside a job should default to the global scope, got %q", result.Output)
+	}
+	if result := runMemoryTool(t, tool, context.Background(), `{"action":"get","key":"x","scope":"job"}`); result.Success {
+		t.Fatal("job scope outside a job run should fail")
+	}
+
+	runMemoryTool(t, tool, jobCtx, `{"action":"set","key":"owner","value":"ops","scope":"global"}`)
+	if result := runMemoryTool(t, tool, otherJob, `{"action":"get","key":"owner","scope":"global"}`); result.Output != "ops" {
+		t.Fatalf("global notes should be shared, got %q", result.Output)
+	}
+	if result := runMemoryTool(t, tool, jobCtx, `{"action":"list"}`); !strings.Contains(result.Output, "1 memories in job:job-1 scope") || !strings.Contains(result.Output, "- headline (14 bytes") {
+		t.Fatalf("unexpected list output %q", result.Output)
+	}
+}
+
+func TestMemoryToolCapsValueSize(t *testing.T) {
+	store, err := storage.NewSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewSQLiteStore: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	tool := NewMemoryTool(store)
+	ctx := context.Background()
+
+	half, _ := json.Marshal(strings.Repeat("a", MaxMemoryValueBytes/2+1))
+	params := `{"action":"append","key":"log","value":` + string(half) + `}`
+	if result := runMemoryTool(t, tool, ctx, params); !result.Success {
+		t.Fatalf("first append failed: %s", result.Error)
+	}
+	result := runMemoryTool(t, tool, ctx, params)
+	if result.Success || !strings.Contains(result.Error, "limited to") {
+		t.Fatalf("append past the cap should be refused, got %+v", result)
+	}
+	if mem, _ := store.GetMemory(storage.MemoryScopeGlobal, "log"); len(mem.Value) != MaxMemoryValueBytes/2+1 {
+		t.Fatalf("refused append should leave the value unchanged, got %d bytes", len(mem.Value))
+	}
+}
@@ -9,6 +9,13 @@ import (
 	"github.com/A2gent/brute/internal/session"
 )
 
+// QuestionToolName is the name the question tool is registered under.
+const QuestionToolName = "ask_question"
+
+// AwaitingInputMetadataKey is set on a tool result when the run must stop and
+// wait for the user to answer.
+const AwaitingInputMetadataKey = "awaiting_input"
+
 // QuestionTool allows agents to ask user for input when encountering ambiguous situations
 type QuestionTool struct {
 	sessionMetadataStore QuestionSessionStore
@@ -16,8 +23,8 @@ type QuestionTool struct {
 
 // QuestionSessionStore interface for storing question data in session
 type QuestionSessionStore interface {
-	SetPendingQuestion(sessionID string, data *session.QuestionData) error
-	SetSessionStatus(sessionID string, status string) error
+	// AskQuestion stores the question and flips the session to input_required.
+	AskQuestion(sessionID string, data *session.QuestionData) error
 }
 
 // QuestionParams defines parameters for the question tool
@@ -37,7 +44,7 @@ func NewQuestionTool(store QuestionSessionStore) *QuestionTool {
 }
 
 func (t *QuestionTool) Name() string {
-	return "question"
+	return QuestionToolName
 }
 
 func (t *QuestionTool) Description() string {
@@ -49,7 +56,8 @@ Use this when:
 - You encounter an ambiguous situation requiring human judgment
 - An operation fails and you need to know whether to retry or skip
 
-The session will pause and wait for user input. Once they respond, execution continues.
+The session will pause and wait for user input. After calling this tool, stop: do not call
+other tools or answer on the user's behalf. Their answer arrives as the next user message.
 
 Guidelines:
 - Use clear, specific questions
@@ -153,19 +161,18 @@ func (t *QuestionTool) Execute(ctx context.Context, params json.RawMessage) (*Re
 		return &Result{Success: false, Error: "session ID not found in context"}, nil
 	}
 
-	// Store question in session metadata
-	if err := t.sessionMetadataStore.SetPendingQuestion(sessionID, questionData); err != nil {
+	// Store question and change session status to input_required
+	if err := t.sessionMetadataStore.AskQuestion(sessionID, questionData); err != nil {
 		return &Result{Success: false, Error: fmt.Sprintf("failed to store question: %v", err)}, nil
 	}
 
-	// Change session status to input_required
-	if err := t.sessionMetadataStore.SetSessionStatus(sessionID, "input_required"); err != nil {
-		return &Result{Success: false, Error: fmt.Sprintf("failed to set status: %v", err)}, nil
-	}
-
-	// Return success - agent loop will pause when it sees input_required status
-	output := fmt.Sprintf("Question asked: %s\nAwaiting user response...", header)
-	return &Result{Success: true, Output: output}, nil
+	// Return success - agent loop ends the run when it sees the awaiting_input marker
+	output := fmt.Sprintf("Question asked: %s\nStop here and wait for the user's answer; it will arrive as the next user message.", header)
+	return &Result{
+		Success:  true,
+		Output:   output,
+		Metadata: map[string]interface{}{AwaitingInputMetadataKey: true},
+	}, nil
 }
 
 // generateHeader creates a short header from the question
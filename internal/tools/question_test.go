@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/session"
+)
+
+type fakeQuestionStore struct {
+	sessionID string
+	question  *session.QuestionData
+}
+
+func (f *fakeQuestionStore) AskQuestion(sessionID string, data *session.QuestionData) error {
+	f.sessionID = sessionID
+	f.question = data
+	return nil
+}
+
+func TestQuestionToolStoresQuestionAndAwaitsInput(t *testing.T) {
+	store := &fakeQuestionStore{}
+	tool := NewQuestionTool(store)
+	if tool.Name() != "ask_question" {
+		t.Fatalf("Name() = %q, want ask_question", tool.Name())
+	}
+
+	params, _ := json.Marshal(map[string]interface{}{
+		"question": "Which branch should I deploy? Both are green.",
+		"options": []map[string]string{
+			{"label": "main", "description": "Deploy the latest commit"},
+			{"label": "release", "description": "Deploy the last release"},
+		},
+		"multiple": false,
+		"custom":   false,
+	})
+	ctx := context.WithValue(context.Background(), "session_id", "sess-1")
+	result, err := tool.Execute(ctx, params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Execute failed: %s", result.Error)
+	}
+	if awaiting, _ := result.Metadata[AwaitingInputMetadataKey].(bool); !awaiting {
+		t.Fatalf("expected %s metadata, got %#v", AwaitingInputMetadataKey, result.Metadata)
+	}
+	if !strings.Contains(result.Output, "wait for the user's answer") {
+		t.Fatalf("output should tell the model to wait, got %q", result.Output)
+	}
+
+	if store.sessionID != "sess-1" {
+		t.Fatalf("stored for session %q, want sess-1", store.sessionID)
+	}
+	if store.question == nil || len(store.question.Options) != 2 {
+		t.Fatalf("unexpected stored question: %#v", store.question)
+	}
+	if store.question.Header != "Which branch should I deploy? Both are green" {
+		t.Fatalf("unexpected generated header %q", store.question.Header)
+	}
+	if store.question.Custom {
+		t.Fatal("custom answers should be disabled when custom=false")
+	}
+}
+
+func TestQuestionToolRequiresSessionID(t *testing.T) {
+	store := &fakeQuestionStore{}
+	tool := NewQuestionTool(store)
+
+	params := json.RawMessage(`{"question":"Retry?","options":[{"label":"Yes","description":"Try again"}]}`)
+	result, err := tool.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success {
+		t.Fatal("expected failure without a session ID in context")
+	}
+	if store.question != nil {
+		t.Fatal("question should not be stored without a session ID")
+	}
+}
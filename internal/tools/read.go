@@ -38,6 +38,11 @@ func (t *ReadTool) Name() string {
 	return "read"
 }
 
+// Idempotent reports that reading a file has no side effects.
+func (t *ReadTool) Idempotent() bool {
+	return true
+}
+
 func (t *ReadTool) Description() string {
 	return `Read file contents from the filesystem.
 By default reads up to 20 lines from the beginning.
@@ -96,6 +101,12 @@ func (t *ReadTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(t.workDir, path)
 	}
+	if agentIgnoreFor(t.workDir).excludes(path) {
+		return agentIgnoreResult(p.Path), nil
+	}
+	if outsideSandbox(t.workDir, path) {
+		return sandboxResult(p.Path), nil
+	}
 
 	// Check if file exists
 	info, err := os.Stat(path)
@@ -108,6 +119,10 @@ func (t *ReadTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	if info.IsDir() {
 		return &Result{Success: false, Error: fmt.Sprintf("%s is a directory", p.Path)}, nil
 	}
+	// Binary content would only fill the context with garbage
+	if isBinaryFile(path) {
+		return &Result{Success: false, Error: fmt.Sprintf("%s appears to be a binary file (%d bytes); read only shows text files", p.Path, info.Size())}, nil
+	}
 
 	// Open file
 	file, err := os.Open(path)
@@ -184,8 +199,9 @@ func (t *ReadTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 
 	if len(lines) == 0 {
 		return &Result{
-			Success: true,
-			Output:  "(empty file or no lines in range)",
+			Success:  true,
+			Output:   "(empty file or no lines in range)",
+			Metadata: readPathsMetadata(path),
 		}, nil
 	}
 
@@ -202,8 +218,9 @@ func (t *ReadTool) Execute(ctx context.Context, params json.RawMessage) (*Result
 	}
 
 	return &Result{
-		Success: true,
-		Output:  output,
+		Success:  true,
+		Output:   output,
+		Metadata: readPathsMetadata(path),
 	}, nil
 }
 
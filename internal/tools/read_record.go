@@ -0,0 +1,71 @@
+package tools
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+)
+
+// readPathsMetadataKey holds the absolute paths whose content a successful
+// call showed to or wrote for the model. The manager moves them into the
+// run's ReadRecord and drops the key, so it never reaches the session.
+const readPathsMetadataKey = "read_paths"
+
+// ReadRecord tracks the files an agent run has read, found grep hits in or
+// written itself, so that the write tool can refuse to overwrite a file the
+// model only half-remembers.
+type ReadRecord struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// NewReadRecord returns an empty record.
+func NewReadRecord() *ReadRecord {
+	return &ReadRecord{paths: make(map[string]bool)}
+}
+
+type readRecordContextKey struct{}
+
+// WithReadRecord makes calls run through ExecuteParallel with the returned
+// context populate record, and write calls check it. Without one, write
+// overwrites files unchecked.
+func WithReadRecord(ctx context.Context, record *ReadRecord) context.Context {
+	return context.WithValue(ctx, readRecordContextKey{}, record)
+}
+
+func readRecordFromContext(ctx context.Context) *ReadRecord {
+	record, _ := ctx.Value(readRecordContextKey{}).(*ReadRecord)
+	return record
+}
+
+func (r *ReadRecord) add(paths ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, path := range paths {
+		r.paths[filepath.Clean(path)] = true
+	}
+}
+
+func (r *ReadRecord) has(path string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.paths[filepath.Clean(path)]
+}
+
+// readPathsMetadata returns result metadata recording paths as read.
+func readPathsMetadata(paths ...string) map[string]interface{} {
+	return map[string]interface{}{readPathsMetadataKey: paths}
+}
+
+// recordReadPaths moves the read paths of a result into the ReadRecord of
+// ctx, if any.
+func recordReadPaths(ctx context.Context, result *Result) {
+	paths, ok := result.Metadata[readPathsMetadataKey].([]string)
+	if !ok {
+		return
+	}
+	delete(result.Metadata, readPathsMetadataKey)
+	if record := readRecordFromContext(ctx); record != nil {
+		record.add(paths...)
+	}
+}
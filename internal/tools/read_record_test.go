@@ -0,0 +1,79 @@
+package tools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestWriteRefusesToOverwriteUnreadFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, "read.go", "package read")
+	createTestFile(t, tempDir, "hit.go", "package hit // TODO")
+	createTestFile(t, tempDir, "unread.go", "package unread")
+
+	m := newBareManager()
+	m.Register(NewReadTool(tempDir))
+	m.Register(NewGrepTool(tempDir))
+	m.Register(NewWriteTool(tempDir))
+	ctx := WithReadRecord(context.Background(), NewReadRecord())
+
+	call := func(name, input string) llm.ToolResult {
+		t.Helper()
+		return m.ExecuteParallel(ctx, []llm.ToolCall{{ID: "call", Name: name, Input: input}})[0]
+	}
+
+	result := call("write", `{"path":"read.go","content":"package lost"}`)
+	if !result.IsError || !strings.Contains(result.Content, "has not been read in this run") {
+		t.Fatalf("expected an unread overwrite to fail, got %+v", result)
+	}
+
+	if result := call("read", `{"path":"read.go"}`); result.IsError || result.Metadata[readPathsMetadataKey] != nil {
+		t.Fatalf("read result = %+v", result)
+	}
+	call("grep", `{"pattern":"TODO"}`)
+	for _, input := range []string{
+		`{"path":"read.go","content":"package read2"}`,
+		`{"path":"hit.go","content":"package hit2"}`,
+		`{"path":"unread.go","content":"package unread2","force":true}`,
+		`{"path":"new/file.go","content":"package created"}`,
+		`{"path":"new/file.go","content":"package rewritten"}`,
+	} {
+		if result := call("write", input); result.IsError {
+			t.Fatalf("write %s failed: %s", input, result.Content)
+		}
+	}
+	if data, _ := os.ReadFile(filepath.Join(tempDir, "new", "file.go")); string(data) != "package rewritten" {
+		t.Fatalf("new/file.go = %q", data)
+	}
+
+	// Without a record, as outside agent runs, writes are unchecked
+	result = m.ExecuteParallel(context.Background(), []llm.ToolCall{{ID: "call", Name: "write", Input: `{"path":"unread.go","content":"x"}`}})[0]
+	if result.IsError {
+		t.Fatalf("write without a record failed: %s", result.Content)
+	}
+}
+
+func TestWriteApprovalShowsReplacedContent(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, "main.go", "package main")
+
+	m := newBareManager()
+	m.Register(NewWriteTool(tempDir))
+	m.SetApprovalPolicy(map[string]string{"write": ApprovalAsk})
+	var asked []ApprovalRequest
+	ctx := WithApprover(context.Background(), func(ctx context.Context, req ApprovalRequest) (ApprovalDecision, error) {
+		asked = append(asked, req)
+		return ApprovalDecision{Approved: true}, nil
+	})
+
+	m.Execute(ctx, "write", []byte(`{"path":"main.go","content":"package next"}`))
+	m.Execute(ctx, "write", []byte(`{"path":"other.go","content":"package other"}`))
+	if len(asked) != 2 || !asked[0].Overwrite || asked[0].Replaced != "package main" || asked[1].Overwrite {
+		t.Fatalf("unexpected approval requests %+v", asked)
+	}
+}
@@ -0,0 +1,393 @@
+package tools
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/stt/whispercpp"
+)
+
+const (
+	defaultAudioFormat          = "wav"
+	defaultAudioDirKey          = "AAGENT_AUDIO_OUTPUT_DIR"
+	defaultAudioDir             = "/tmp"
+	defaultMicrophoneIndexKey   = "AAGENT_MICROPHONE_INDEX"
+	defaultAudioDurationSeconds = 5
+	maxAudioDurationSeconds     = 600
+	audioSampleRate             = 16000
+)
+
+type RecordAudioParams struct {
+	OutputPath      string `json:"output_path,omitempty"`
+	OutputDir       string `json:"output_dir,omitempty"`
+	Filename        string `json:"filename,omitempty"`
+	Format          string `json:"format,omitempty"` // wav | m4a
+	DurationSeconds int    `json:"duration_seconds,omitempty"`
+	MicrophoneIndex int    `json:"microphone_index,omitempty"`
+	Transcribe      bool   `json:"transcribe,omitempty"`
+	Language        string `json:"language,omitempty"`
+}
+
+type RecordAudioTool struct {
+	workDir string
+}
+
+func NewRecordAudioTool(workDir string) *RecordAudioTool {
+	return &RecordAudioTool{workDir: workDir}
+}
+
+func (t *RecordAudioTool) Name() string {
+	return "record_audio_tool"
+}
+
+func (t *RecordAudioTool) Description() string {
+	return `Record audio from the default (or indexed) microphone for a fixed number of seconds.
+Saves a wav (16kHz mono, ready for whisper_stt) or m4a file and returns its path, duration, and size.
+Set transcribe=true to run local whisper.cpp on the recording and include the transcript in the result.`
+}
+
+func (t *RecordAudioTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"output_path": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional output file path, or directory path if no extension is provided. Relative paths are resolved from project workdir.",
+			},
+			"output_dir": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional output directory. Ignored when output_path points to a file.",
+			},
+			"filename": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional filename. If omitted, a timestamp-based name is used.",
+			},
+			"format": map[string]interface{}{
+				"type":        "string",
+				"description": "Audio format: wav or m4a (default: wav).",
+				"enum":        []string{"wav", "m4a"},
+			},
+			"duration_seconds": map[string]interface{}{
+				"type":        "integer",
+				"description": "Recording length in seconds (default: 5, max: 600).",
+			},
+			"microphone_index": map[string]interface{}{
+				"type":        "integer",
+				"description": "Optional 1-based microphone index. Defaults to the configured default microphone, or the system default input.",
+			},
+			"transcribe": map[string]interface{}{
+				"type":        "boolean",
+				"description": "When true, transcribe the recording with local whisper.cpp (wav only).",
+			},
+			"language": map[string]interface{}{
+				"type":        "string",
+				"description": "Optional language hint for transcription (`auto`, `en`, etc).",
+			},
+		},
+	}
+}
+
+func (t *RecordAudioTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	var p RecordAudioParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	format, err := normalizeAudioFormat(p.Format, p.Filename, p.OutputPath)
+	if err != nil {
+		return &Result{Success: false, Error: err.Error()}, nil
+	}
+	if p.Transcribe && format != "wav" {
+		return &Result{Success: false, Error: "transcribe=true requires format=wav"}, nil
+	}
+
+	duration := p.DurationSeconds
+	if duration <= 0 {
+		duration = defaultAudioDurationSeconds
+	}
+	if duration > maxAudioDurationSeconds {
+		return &Result{Success: false, Error: fmt.Sprintf("duration_seconds must be <= %d", maxAudioDurationSeconds)}, nil
+	}
+
+	micIndex := p.MicrophoneIndex
+	if micIndex <= 0 {
+		micIndex = configuredDefaultMicrophoneIndex()
+	}
+
+	absPath, err := t.resolveOutputPath(p, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve output path: %w", err)
+	}
+
+	if err := recordAudio(ctx, micIndex, duration, format, absPath); err != nil {
+		return &Result{Success: false, Error: err.Error()}, nil
+	}
+
+	info, statErr := os.Stat(absPath)
+	if statErr != nil {
+		return nil, fmt.Errorf("audio recording completed but output file is missing: %w", statErr)
+	}
+
+	durationSeconds := float64(duration)
+	if format == "wav" {
+		if measured, ok := wavDurationSeconds(absPath); ok {
+			durationSeconds = measured
+		}
+	}
+
+	payload := map[string]interface{}{
+		"path":             absPath,
+		"format":           format,
+		"duration_seconds": durationSeconds,
+		"bytes":            info.Size(),
+	}
+	if micIndex > 0 {
+		payload["microphone_index"] = micIndex
+	}
+	if rel, err := filepath.Rel(t.workDir, absPath); err == nil {
+		payload["relative_path"] = rel
+	}
+
+	metadata := map[string]interface{}{
+		"audio_file": map[string]interface{}{
+			"path":             absPath,
+			"format":           format,
+			"bytes":            info.Size(),
+			"duration_seconds": durationSeconds,
+			"source_tool":      t.Name(),
+		},
+	}
+
+	if p.Transcribe {
+		transcript, err := whispercpp.Transcribe(ctx, absPath, strings.TrimSpace(p.Language))
+		if err != nil {
+			payload["transcribe_error"] = err.Error()
+		} else {
+			payload["transcript"] = transcript
+			metadata["transcript"] = transcript
+		}
+	}
+
+	out, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	return &Result{
+		Success:  true,
+		Output:   string(out),
+		Metadata: metadata,
+	}, nil
+}
+
+func normalizeAudioFormat(raw string, filename string, outputPath string) (string, error) {
+	format := strings.ToLower(strings.TrimSpace(raw))
+	if format == "" {
+		ext := strings.ToLower(strings.TrimPrefix(filepath.Ext(strings.TrimSpace(filename)), "."))
+		if ext == "" {
+			ext = strings.ToLower(strings.TrimPrefix(filepath.Ext(strings.TrimSpace(outputPath)), "."))
+		}
+		switch ext {
+		case "wav", "m4a":
+			format = ext
+		default:
+			format = defaultAudioFormat
+		}
+	}
+
+	switch format {
+	case "wav", "m4a":
+	default:
+		return "", fmt.Errorf("unsupported format %q (expected wav or m4a)", format)
+	}
+	return format, nil
+}
+
+func configuredDefaultMicrophoneIndex() int {
+	raw := strings.TrimSpace(os.Getenv(defaultMicrophoneIndexKey))
+	if raw == "" {
+		return 0
+	}
+	idx, err := strconv.Atoi(raw)
+	if err != nil || idx <= 0 {
+		return 0
+	}
+	return idx
+}
+
+func (t *RecordAudioTool) resolveOutputPath(p RecordAudioParams, format string) (string, error) {
+	resolvePath := func(raw string) string {
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return ""
+		}
+		if filepath.IsAbs(raw) {
+			return raw
+		}
+		return filepath.Join(t.workDir, raw)
+	}
+
+	filename := strings.TrimSpace(p.Filename)
+	if filename == "" {
+		filename = fmt.Sprintf("recording-%s.%s", time.Now().Format("20060102-150405"), format)
+	} else if ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(filename)), "."); ext == "" {
+		filename += "." + format
+	}
+
+	outputPath := resolvePath(p.OutputPath)
+	if outputPath != "" {
+		ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(outputPath)), ".")
+		if ext != "" {
+			if ext != format {
+				return "", fmt.Errorf("output_path extension .%s does not match format %q", ext, format)
+			}
+			if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+				return "", err
+			}
+			return outputPath, nil
+		}
+		if err := os.MkdirAll(outputPath, 0755); err != nil {
+			return "", err
+		}
+		return filepath.Join(outputPath, filename), nil
+	}
+
+	outputDir := resolvePath(p.OutputDir)
+	if outputDir == "" {
+		envDir := strings.TrimSpace(os.Getenv(defaultAudioDirKey))
+		if envDir != "" {
+			outputDir = resolvePath(envDir)
+		} else {
+			outputDir = defaultAudioDir
+		}
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", err
+	}
+	return filepath.Join(outputDir, filename), nil
+}
+
+// wavDurationSeconds reads the RIFF header to report the recorded length.
+func wavDurationSeconds(path string) (float64, bool) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	header := make([]byte, 44)
+	if _, err := f.Read(header); err != nil {
+		return 0, false
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return 0, false
+	}
+	byteRate := binary.LittleEndian.Uint32(header[28:32])
+	if byteRate == 0 {
+		return 0, false
+	}
+	info, err := f.Stat()
+	if err != nil || info.Size() <= 44 {
+		return 0, false
+	}
+	return float64(info.Size()-44) / float64(byteRate), true
+}
+
+func recordAudio(ctx context.Context, micIndex int, duration int, format string, outputPath string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return recordAudioDarwin(micIndex, duration, format, outputPath)
+	case "linux":
+		return recordAudioLinux(ctx, micIndex, duration, format, outputPath)
+	case "windows":
+		return recordAudioWindows(ctx, micIndex, duration, format, outputPath)
+	default:
+		return fmt.Errorf("audio recording is not supported on %s", runtime.GOOS)
+	}
+}
+
+func ffmpegAudioOutputArgs(duration int, format string, outputPath string) []string {
+	args := []string{"-t", strconv.Itoa(duration), "-ac", "1", "-ar", strconv.Itoa(audioSampleRate)}
+	if format == "wav" {
+		args = append(args, "-c:a", "pcm_s16le")
+	} else {
+		args = append(args, "-c:a", "aac")
+	}
+	return append(args, outputPath)
+}
+
+func recordAudioLinux(ctx context.Context, micIndex int, duration int, format string, outputPath string) error {
+	device := "default"
+	if micIndex > 0 {
+		device = fmt.Sprintf("plughw:%d", micIndex-1)
+	}
+
+	if _, err := exec.LookPath("ffmpeg"); err == nil {
+		args := []string{
+			"-y",
+			"-loglevel", "error",
+			"-f", "alsa",
+			"-i", device,
+		}
+		args = append(args, ffmpegAudioOutputArgs(duration, format, outputPath)...)
+		return runCommand(ctx, "ffmpeg", args...)
+	}
+
+	if format != "wav" {
+		return fmt.Errorf("format %q on linux requires ffmpeg in PATH", format)
+	}
+	if _, err := exec.LookPath("arecord"); err == nil {
+		args := []string{
+			"-q",
+			"-D", device,
+			"-d", strconv.Itoa(duration),
+			"-f", "S16_LE",
+			"-r", strconv.Itoa(audioSampleRate),
+			"-c", "1",
+			outputPath,
+		}
+		return runCommand(ctx, "arecord", args...)
+	}
+
+	return fmt.Errorf("no supported audio recording binary found on linux (tried ffmpeg, arecord)")
+}
+
+func recordAudioWindows(ctx context.Context, micIndex int, duration int, format string, outputPath string) error {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("audio recording on windows requires ffmpeg in PATH")
+	}
+
+	mics, err := listDShowDevicesFFmpeg(ctx, "audio")
+	if err != nil {
+		return fmt.Errorf("failed to enumerate microphones via ffmpeg: %w", err)
+	}
+	if len(mics) == 0 {
+		return fmt.Errorf("no audio input devices reported by ffmpeg")
+	}
+	if micIndex <= 0 {
+		micIndex = 1
+	}
+	if micIndex > len(mics) {
+		return fmt.Errorf("microphone_index out of range: %d (available: %d)", micIndex, len(mics))
+	}
+
+	args := []string{
+		"-y",
+		"-loglevel", "error",
+		"-f", "dshow",
+		"-i", "audio=" + mics[micIndex-1],
+	}
+	args = append(args, ffmpegAudioOutputArgs(duration, format, outputPath)...)
+	return runCommand(ctx, "ffmpeg", args...)
+}
+
+var _ Tool = (*RecordAudioTool)(nil)
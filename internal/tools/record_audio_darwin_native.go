@@ -0,0 +1,204 @@
+//go:build darwin && cgo
+
+package tools
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation -framework AVFoundation -framework CoreMedia -framework CoreAudio
+
+#import <Foundation/Foundation.h>
+#import <AVFoundation/AVFoundation.h>
+#import <CoreAudio/CoreAudioTypes.h>
+#import <dispatch/dispatch.h>
+#include <stdlib.h>
+#include <string.h>
+
+@interface AAgentAudioRecordingDelegate : NSObject <AVCaptureFileOutputRecordingDelegate>
+@property (atomic, strong) NSError *recordError;
+@property (nonatomic) dispatch_semaphore_t semaphore;
+@end
+
+@implementation AAgentAudioRecordingDelegate
+- (void)captureOutput:(AVCaptureFileOutput *)output
+didFinishRecordingToOutputFileAtURL:(NSURL *)outputFileURL
+      fromConnections:(NSArray<AVCaptureConnection *> *)connections
+                error:(NSError *)error {
+    (void)output;
+    (void)outputFileURL;
+    (void)connections;
+    if (error != nil) {
+        NSNumber *finished = error.userInfo[AVErrorRecordingSuccessfullyFinishedKey];
+        if (finished == nil || ![finished boolValue]) {
+            self.recordError = error;
+        }
+    }
+    dispatch_semaphore_signal(self.semaphore);
+}
+@end
+
+static void set_audio_error(char **err_out, NSString *message) {
+    if (err_out == NULL) {
+        return;
+    }
+    const char *utf8 = [message UTF8String];
+    if (utf8 == NULL) {
+        utf8 = "unknown error";
+    }
+    *err_out = strdup(utf8);
+}
+
+int aagent_record_audio_darwin(int mic_index, int duration_seconds, const char *output_path, const char *format, char **err_out) {
+    @autoreleasepool {
+        if (output_path == NULL || format == NULL || duration_seconds <= 0) {
+            set_audio_error(err_out, @"invalid recording arguments");
+            return 1;
+        }
+        NSString *outputPath = [NSString stringWithUTF8String:output_path];
+        NSString *formatStr = [[NSString stringWithUTF8String:format] lowercaseString];
+        if (outputPath == nil || formatStr == nil) {
+            set_audio_error(err_out, @"invalid recording arguments encoding");
+            return 1;
+        }
+        BOOL outputWAV = [formatStr isEqualToString:@"wav"];
+        if (!outputWAV && ![formatStr isEqualToString:@"m4a"]) {
+            set_audio_error(err_out, [NSString stringWithFormat:@"unsupported format: %@", formatStr]);
+            return 1;
+        }
+
+        AVAuthorizationStatus auth = [AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+        if (auth == AVAuthorizationStatusNotDetermined) {
+            dispatch_semaphore_t authSem = dispatch_semaphore_create(0);
+            __block BOOL granted = NO;
+            [AVCaptureDevice requestAccessForMediaType:AVMediaTypeAudio completionHandler:^(BOOL ok) {
+                granted = ok;
+                dispatch_semaphore_signal(authSem);
+            }];
+            dispatch_semaphore_wait(authSem, dispatch_time(DISPATCH_TIME_NOW, (int64_t)(10 * NSEC_PER_SEC)));
+            if (!granted) {
+                set_audio_error(err_out, @"microphone access was denied");
+                return 1;
+            }
+            auth = [AVCaptureDevice authorizationStatusForMediaType:AVMediaTypeAudio];
+        }
+        if (auth != AVAuthorizationStatusAuthorized) {
+            set_audio_error(err_out, @"microphone access is not authorized for this process");
+            return 1;
+        }
+
+        AVCaptureDevice *device = nil;
+        if (mic_index <= 0) {
+            device = [AVCaptureDevice defaultDeviceWithMediaType:AVMediaTypeAudio];
+        } else {
+            AVCaptureDeviceDiscoverySession *discovery =
+                [AVCaptureDeviceDiscoverySession discoverySessionWithDeviceTypes:@[AVCaptureDeviceTypeBuiltInMicrophone, AVCaptureDeviceTypeExternalUnknown]
+                                                                       mediaType:AVMediaTypeAudio
+                                                                        position:AVCaptureDevicePositionUnspecified];
+            NSArray<AVCaptureDevice *> *devices = [discovery devices];
+            if (mic_index > (int)devices.count) {
+                set_audio_error(err_out, [NSString stringWithFormat:@"microphone_index out of range: %d (available: %lu)",
+                                          mic_index, (unsigned long)devices.count]);
+                return 1;
+            }
+            device = devices[(NSUInteger)(mic_index - 1)];
+        }
+        if (device == nil) {
+            set_audio_error(err_out, @"no microphone devices found");
+            return 1;
+        }
+
+        NSError *inputErr = nil;
+        AVCaptureDeviceInput *input = [AVCaptureDeviceInput deviceInputWithDevice:device error:&inputErr];
+        if (input == nil || inputErr != nil) {
+            set_audio_error(err_out, inputErr != nil ? inputErr.localizedDescription : @"unable to create microphone input");
+            return 1;
+        }
+
+        AVCaptureSession *session = [[AVCaptureSession alloc] init];
+        AVCaptureAudioFileOutput *fileOutput = [[AVCaptureAudioFileOutput alloc] init];
+        if (outputWAV) {
+            fileOutput.audioSettings = @{
+                AVFormatIDKey: @(kAudioFormatLinearPCM),
+                AVSampleRateKey: @16000,
+                AVNumberOfChannelsKey: @1,
+                AVLinearPCMBitDepthKey: @16,
+                AVLinearPCMIsFloatKey: @NO,
+                AVLinearPCMIsBigEndianKey: @NO,
+            };
+        } else {
+            fileOutput.audioSettings = @{
+                AVFormatIDKey: @(kAudioFormatMPEG4AAC),
+                AVSampleRateKey: @16000,
+                AVNumberOfChannelsKey: @1,
+            };
+        }
+
+        [session beginConfiguration];
+        if (![session canAddInput:input]) {
+            [session commitConfiguration];
+            set_audio_error(err_out, @"unable to add microphone input");
+            return 1;
+        }
+        [session addInput:input];
+        if (![session canAddOutput:fileOutput]) {
+            [session commitConfiguration];
+            set_audio_error(err_out, @"unable to add audio file output");
+            return 1;
+        }
+        [session addOutput:fileOutput];
+        [session commitConfiguration];
+
+        AAgentAudioRecordingDelegate *delegate = [[AAgentAudioRecordingDelegate alloc] init];
+        delegate.semaphore = dispatch_semaphore_create(0);
+
+        [session startRunning];
+        NSURL *url = [NSURL fileURLWithPath:outputPath];
+        [[NSFileManager defaultManager] removeItemAtURL:url error:nil];
+        AVFileType fileType = outputWAV ? AVFileTypeWAVE : AVFileTypeAppleM4A;
+        [fileOutput startRecordingToOutputFileURL:url outputFileType:fileType recordingDelegate:delegate];
+
+        [NSThread sleepForTimeInterval:(NSTimeInterval)duration_seconds];
+        [fileOutput stopRecording];
+
+        long semResult = dispatch_semaphore_wait(delegate.semaphore, dispatch_time(DISPATCH_TIME_NOW, (int64_t)(10 * NSEC_PER_SEC)));
+        [session stopRunning];
+
+        if (semResult != 0) {
+            set_audio_error(err_out, @"audio recording did not finish in time");
+            return 1;
+        }
+        if (delegate.recordError != nil) {
+            set_audio_error(err_out, delegate.recordError.localizedDescription);
+            return 1;
+        }
+        return 0;
+    }
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+func recordAudioDarwin(micIndex int, duration int, format string, outputPath string) error {
+	cOutputPath := C.CString(outputPath)
+	cFormat := C.CString(format)
+	defer C.free(unsafe.Pointer(cOutputPath))
+	defer C.free(unsafe.Pointer(cFormat))
+
+	var cErr *C.char
+	rc := C.aagent_record_audio_darwin(C.int(micIndex), C.int(duration), cOutputPath, cFormat, &cErr)
+	if rc == 0 {
+		return nil
+	}
+	defer func() {
+		if cErr != nil {
+			C.free(unsafe.Pointer(cErr))
+		}
+	}()
+	if cErr != nil {
+		return fmt.Errorf("native audio recording failed: %s", C.GoString(cErr))
+	}
+	return fmt.Errorf("native audio recording failed")
+}
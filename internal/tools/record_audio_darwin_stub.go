@@ -0,0 +1,13 @@
+//go:build darwin && !cgo
+
+package tools
+
+import "fmt"
+
+func recordAudioDarwin(micIndex int, duration int, format string, outputPath string) error {
+	_ = micIndex
+	_ = duration
+	_ = format
+	_ = outputPath
+	return fmt.Errorf("audio recording on darwin requires a cgo-enabled build")
+}
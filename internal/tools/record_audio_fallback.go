@@ -0,0 +1,9 @@
+//go:build !darwin
+
+package tools
+
+import "fmt"
+
+func recordAudioDarwin(micIndex int, duration int, format string, outputPath string) error {
+	return fmt.Errorf("audio recording is not supported on this platform")
+}
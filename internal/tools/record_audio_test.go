@@ -0,0 +1,76 @@
+package tools
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestNormalizeAudioFormat(t *testing.T) {
+	cases := []struct {
+		raw, filename, outputPath, want string
+		wantErr                         bool
+	}{
+		{want: "wav"},
+		{raw: "M4A", want: "m4a"},
+		{filename: "note.m4a", want: "m4a"},
+		{outputPath: "clips/out.wav", want: "wav"},
+		{raw: "mp3", wantErr: true},
+	}
+	for _, tc := range cases {
+		got, err := normalizeAudioFormat(tc.raw, tc.filename, tc.outputPath)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("expected error for %+v", tc)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("unexpected error for %+v: %v", tc, err)
+		}
+		if got != tc.want {
+			t.Fatalf("normalizeAudioFormat(%+v) = %q, want %q", tc, got, tc.want)
+		}
+	}
+}
+
+func TestWavDurationSeconds(t *testing.T) {
+	header := make([]byte, 44)
+	copy(header[0:4], "RIFF")
+	copy(header[8:12], "WAVE")
+	binary.LittleEndian.PutUint32(header[28:32], audioSampleRate*2)
+	payload := append(header, make([]byte, audioSampleRate*2*3)...)
+
+	path := filepath.Join(t.TempDir(), "clip.wav")
+	if err := os.WriteFile(path, payload, 0644); err != nil {
+		t.Fatalf("write wav: %v", err)
+	}
+	got, ok := wavDurationSeconds(path)
+	if !ok || got != 3 {
+		t.Fatalf("expected 3s duration, got %v (ok=%v)", got, ok)
+	}
+}
+
+func TestParseDShowDevices(t *testing.T) {
+	sectioned := `[dshow @ 0000] DirectShow video devices
+[dshow @ 0000]  "Integrated Camera"
+[dshow @ 0000]     Alternative name "@device_pnp_\\?\usb#vid"
+[dshow @ 0000] DirectShow audio devices
+[dshow @ 0000]  "Microphone Array (Realtek)"
+`
+	if got := parseDShowDevices(sectioned, "video"); len(got) != 1 || got[0] != "Integrated Camera" {
+		t.Fatalf("unexpected video devices: %v", got)
+	}
+	if got := parseDShowDevices(sectioned, "audio"); len(got) != 1 || got[0] != "Microphone Array (Realtek)" {
+		t.Fatalf("unexpected audio devices: %v", got)
+	}
+
+	suffixed := `[dshow @ 0000] "Integrated Camera" (video)
+[dshow @ 0000]   Alternative name "@device_pnp_x"
+[dshow @ 0000] "Headset Mic" (audio)
+`
+	if got := parseDShowDevices(suffixed, "audio"); len(got) != 1 || got[0] != "Headset Mic" {
+		t.Fatalf("unexpected suffixed audio devices: %v", got)
+	}
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces secrets in tool results.
+const Redacted = "••••REDACTED••••"
+
+// minRedactedSecretLength keeps short known values such as "true" from
+// being scrubbed out of every result.
+const minRedactedSecretLength = 8
+
+// RedactionPolicy controls the pass that removes secrets from tool results
+// before they are stored in the session and sent to the model. Well-known
+// key formats are always matched; Secrets adds the literal values aagent
+// itself holds, such as provider API keys and integration tokens.
+type RedactionPolicy struct {
+	Disabled bool
+	Secrets  func() []string
+}
+
+// secretPatterns match credentials by their format. Bare hex strings such as
+// git SHAs are left alone: only values assigned to a *_KEY, *_TOKEN or
+// *_SECRET name are taken for secrets by their length.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b`),
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,}`),
+	regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`https://hooks\.slack\.com/services/[A-Za-z0-9/]+`),
+	regexp.MustCompile(`\bsk-[A-Za-z0-9_\-]{20,}`),
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}\b`),
+}
+
+// secretAssignment matches NAME=value, NAME: value and "name": "value"
+// where NAME ends in KEY, TOKEN or SECRET and value is 32 or more hex or
+// base64 characters. The first two groups are kept.
+var secretAssignment = regexp.MustCompile(`(?i)(\b(?:[a-z0-9]+[_-])*(?:key|token|secret)s?\b)("?\s*[:=]\s*["']?)[A-Za-z0-9+/_\-]{32,}={0,2}`)
+
+// redact replaces the secrets in text by Redacted.
+func (p RedactionPolicy) redact(text string) string {
+	if p.Disabled || text == "" {
+		return text
+	}
+	if p.Secrets != nil {
+		secrets := p.Secrets()
+		// Longer secrets first, so one containing another is replaced whole.
+		sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+		for _, secret := range secrets {
+			secret = strings.TrimSpace(secret)
+			if len(secret) < minRedactedSecretLength {
+				continue
+			}
+			text = strings.ReplaceAll(text, secret, Redacted)
+			if encoded, err := json.Marshal(secret); err == nil {
+				// Secrets with quotes or backslashes appear escaped in JSON.
+				text = strings.ReplaceAll(text, strings.Trim(string(encoded), `"`), Redacted)
+			}
+		}
+	}
+	for _, pattern := range secretPatterns {
+		text = pattern.ReplaceAllString(text, Redacted)
+	}
+	return secretAssignment.ReplaceAllString(text, "${1}${2}"+Redacted)
+}
@@ -82,6 +82,12 @@ func (t *ReplaceLinesTool) Execute(ctx context.Context, params json.RawMessage)
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(t.workDir, path)
 	}
+	if agentIgnoreFor(t.workDir).excludes(path) {
+		return agentIgnoreResult(p.Path), nil
+	}
+	if outsideSandbox(t.workDir, path) {
+		return sandboxResult(p.Path), nil
+	}
 
 	if ctx.Err() != nil {
 		return nil, ctx.Err()
@@ -144,5 +150,10 @@ func shouldKeepTrailingNewline(originalTrailing, replacementTrailing bool, lineC
 	return false
 }
 
+// MutatedPaths returns the file a call changes, for undo backups.
+func (t *ReplaceLinesTool) MutatedPaths(params json.RawMessage) []string {
+	return mutatedPathParam(t.workDir, params)
+}
+
 // Ensure ReplaceLinesTool implements Tool.
 var _ Tool = (*ReplaceLinesTool)(nil)
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/llm"
+	"github.com/A2gent/brute/internal/logging"
+)
+
+// CachedMetadataKey is set to true on results answered from a ResultCache.
+const CachedMetadataKey = "cached"
+
+// ResultCache keeps the successful results of IdempotentTool calls for the
+// length of one agent run, so that re-reading an unchanged file or re-running
+// a glob does not touch the disk again. Any batch with a call that is not
+// idempotent, such as write, edit or bash, empties it.
+type ResultCache struct {
+	mu      sync.Mutex
+	results map[string]llm.ToolResult
+	hits    int
+}
+
+// NewResultCache returns an empty cache.
+func NewResultCache() *ResultCache {
+	return &ResultCache{results: make(map[string]llm.ToolResult)}
+}
+
+type resultCacheContextKey struct{}
+
+// WithResultCache makes ExecuteParallel calls made with the returned context
+// use cache.
+func WithResultCache(ctx context.Context, cache *ResultCache) context.Context {
+	return context.WithValue(ctx, resultCacheContextKey{}, cache)
+}
+
+func resultCacheFromContext(ctx context.Context) *ResultCache {
+	cache, _ := ctx.Value(resultCacheContextKey{}).(*ResultCache)
+	return cache
+}
+
+// Hits returns how many calls the cache has answered.
+func (c *ResultCache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+// Clear drops every cached result.
+func (c *ResultCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.results)
+}
+
+func (c *ResultCache) get(key string) (llm.ToolResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.results[key]
+	if ok {
+		c.hits++
+	}
+	return result, ok
+}
+
+func (c *ResultCache) put(key string, result llm.ToolResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[key] = result
+}
+
+// executeCached answers tc from cache when it holds the result of an
+// identical call, and otherwise runs it and caches a successful result. A
+// nil cache runs every call.
+func (m *Manager) executeCached(ctx context.Context, cache *ResultCache, tc llm.ToolCall) (llm.ToolResult, time.Duration) {
+	if cache == nil {
+		return m.executeCall(ctx, tc)
+	}
+	key := callKey(tc)
+	if cached, ok := cache.get(key); ok {
+		logging.DebugContext(ctx, "Tool call %s (%s) answered from the result cache", tc.ID, tc.Name)
+		tr := sharedResult(cached, tc)
+		tr.StartedAt = time.Now()
+		tr.DurationMs = 0
+		tr.Metadata[DurationMetadataKey] = int64(0)
+		tr.Metadata[CachedMetadataKey] = true
+		return tr, 0
+	}
+	tr, duration := m.executeCall(ctx, tc)
+	if !tr.IsError {
+		cache.put(key, sharedResult(tr, tc))
+	}
+	return tr, duration
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/A2gent/brute/internal/llm"
+)
+
+func TestResultCacheAnswersRepeatedCallsUntilAMutation(t *testing.T) {
+	m := newBareManager()
+	lookup := &idempotentTool{}
+	m.Register(lookup)
+	m.Register(&concurrencyTool{})
+	cache := NewResultCache()
+	ctx := WithResultCache(context.Background(), cache)
+
+	read := func(id string) []llm.ToolResult {
+		return m.ExecuteParallel(ctx, []llm.ToolCall{{ID: id, Name: "lookup_tool", Input: `{"path":"a"}`}})
+	}
+
+	read("call-1")
+	results := read("call-2")
+	if lookup.calls.Load() != 1 || cache.Hits() != 1 {
+		t.Fatalf("lookup_tool ran %d times with %d hits, want 1 and 1", lookup.calls.Load(), cache.Hits())
+	}
+	if results[0].ToolCallID != "call-2" || results[0].Content != "done" || results[0].Metadata[CachedMetadataKey] != true {
+		t.Fatalf("cached result = %+v", results[0])
+	}
+
+	// A batch with a tool that is not idempotent empties the cache, and its
+	// own lookups are not cached.
+	m.ExecuteParallel(ctx, []llm.ToolCall{
+		{ID: "call-3", Name: "slow_tool", Input: `{}`},
+		{ID: "call-4", Name: "lookup_tool", Input: `{"path":"a"}`},
+	})
+	read("call-5")
+	if lookup.calls.Load() != 3 || cache.Hits() != 1 {
+		t.Fatalf("lookup_tool ran %d times with %d hits, want 3 and 1", lookup.calls.Load(), cache.Hits())
+	}
+
+	// Without a cache every call runs.
+	m.ExecuteParallel(context.Background(), []llm.ToolCall{{ID: "call-6", Name: "lookup_tool", Input: `{"path":"a"}`}})
+	if lookup.calls.Load() != 4 {
+		t.Fatalf("lookup_tool ran %d times, want 4", lookup.calls.Load())
+	}
+}
@@ -0,0 +1,91 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/A2gent/brute/internal/config"
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// envSandboxRoots lists, separated by commas, the directories besides the
+// work directory that file tools may use. It is the variable config.Load maps
+// to tools.sandbox_roots, so it confines the tools whether or not the config
+// was loaded. Relative entries are taken from the work directory. Unset, file
+// tools are not confined.
+const envSandboxRoots = config.EnvPrefix + "TOOLS_SANDBOX_ROOTS"
+
+// sandboxRoots returns the directories file tools of workDir are confined
+// to, or nil when they are not confined.
+func sandboxRoots(workDir string) []string {
+	raw := strings.TrimSpace(os.Getenv(envSandboxRoots))
+	if raw == "" {
+		return nil
+	}
+	roots := []string{realPath(workDir)}
+	for _, root := range strings.Split(raw, ",") {
+		if root = strings.TrimSpace(root); root == "" {
+			continue
+		}
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(workDir, root)
+		}
+		roots = append(roots, realPath(root))
+	}
+	return roots
+}
+
+// outsideSandbox reports whether path lies outside the sandbox roots of
+// workDir. Symlinks are resolved first, so that a link cannot lead out.
+func outsideSandbox(workDir, path string) bool {
+	roots := sandboxRoots(workDir)
+	if roots == nil {
+		return false
+	}
+	path = realPath(path)
+	for _, root := range roots {
+		if rel, err := filepath.Rel(root, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return false
+		}
+	}
+	return true
+}
+
+// globOutsideSandbox reports whether the directory a glob pattern is walked
+// from lies outside the sandbox roots of workDir.
+func globOutsideSandbox(workDir, pattern string) bool {
+	base, _ := doublestar.SplitPattern(filepath.ToSlash(pattern))
+	return outsideSandbox(workDir, filepath.FromSlash(base))
+}
+
+// realPath returns the absolute path of p with symlinks resolved, as far as
+// p exists: a file about to be created is resolved through its directory.
+func realPath(p string) string {
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	var missing []string
+	for {
+		if resolved, err := filepath.EvalSymlinks(p); err == nil {
+			return filepath.Join(append([]string{resolved}, missing...)...)
+		}
+		parent := filepath.Dir(p)
+		if parent == p {
+			return filepath.Join(append([]string{p}, missing...)...)
+		}
+		missing = append([]string{filepath.Base(p)}, missing...)
+		p = parent
+	}
+}
+
+// sandboxResult is the error result of tools asked for a path outside the
+// sandbox roots.
+func sandboxResult(path string) *Result {
+	return &Result{Success: false, Error: sandboxError(path).Error()}
+}
+
+func sandboxError(path string) error {
+	return fmt.Errorf("path is outside the sandbox roots (tools.sandbox_roots): %s", path)
+}
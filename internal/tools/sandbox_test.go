@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSandboxRootsConfineFileTools(t *testing.T) {
+	workDir := t.TempDir()
+	shared := t.TempDir()
+	outside := t.TempDir()
+	createTestFile(t, workDir, "main.go", "package main")
+	createTestFile(t, shared, "notes.txt", "shared notes")
+	createTestFile(t, outside, "secret.txt", "secret")
+	if err := os.Symlink(outside, filepath.Join(workDir, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(envSandboxRoots, shared)
+
+	run := func(tool Tool, params string) *Result {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), json.RawMessage(params))
+		if err != nil {
+			t.Fatalf("%s %s: %v", tool.Name(), params, err)
+		}
+		return result
+	}
+	for _, params := range []string{`{"path":"main.go"}`, `{"path":"` + filepath.Join(shared, "notes.txt") + `"}`} {
+		if result := run(NewReadTool(workDir), params); !result.Success {
+			t.Errorf("read %s = %+v, want it allowed", params, result)
+		}
+	}
+
+	for _, call := range []struct {
+		tool   Tool
+		params string
+	}{
+		{NewReadTool(workDir), `{"path":"` + filepath.Join(outside, "secret.txt") + `"}`},
+		{NewReadTool(workDir), `{"path":"escape/secret.txt"}`},
+		{NewWriteTool(workDir), `{"path":"../new.txt","content":"x"}`},
+		{NewGrepTool(workDir), `{"pattern":"secret","path":"` + outside + `"}`},
+		{NewGlobTool(workDir), `{"pattern":"../**/*.txt"}`},
+	} {
+		if result := run(call.tool, call.params); result.Success || !strings.Contains(result.Error, "outside the sandbox roots") {
+			t.Errorf("%s %s = %+v, want the sandbox error", call.tool.Name(), call.params, result)
+		}
+	}
+
+	t.Setenv(envSandboxRoots, "")
+	if result := run(NewReadTool(workDir), `{"path":"`+filepath.Join(outside, "secret.txt")+`"}`); !result.Success {
+		t.Fatalf("read without sandbox roots = %+v", result)
+	}
+}
@@ -126,7 +126,7 @@ func (t *SessionTaskProgressTool) handleSet(sessionID string, content string) (*
 		}, nil
 	}
 
-	stats := parseTaskStats(content)
+	stats := ParseTaskStats(content)
 
 	return &Result{
 		Success: true,
@@ -169,7 +169,7 @@ func (t *SessionTaskProgressTool) handleAppend(sessionID string, content string)
 		}, nil
 	}
 
-	stats := parseTaskStats(combined)
+	stats := ParseTaskStats(combined)
 
 	return &Result{
 		Success: true,
@@ -189,9 +189,9 @@ type TaskStats struct {
 	ProgressPct int
 }
 
-// parseTaskStats extracts statistics from task progress text
+// ParseTaskStats extracts statistics from task progress text
 // Supports both "- [ ] Task" and "[ ] Task" formats
-func parseTaskStats(content string) TaskStats {
+func ParseTaskStats(content string) TaskStats {
 	lines := strings.Split(content, "\n")
 	total := 0
 	completed := 0
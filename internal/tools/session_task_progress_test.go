@@ -123,7 +123,7 @@ func TestParseTaskStats(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			stats := parseTaskStats(tt.content)
+			stats := ParseTaskStats(tt.content)
 
 			if stats.Total != tt.wantTotal {
 				t.Errorf("Total = %d, want %d", stats.Total, tt.wantTotal)
@@ -182,7 +182,7 @@ func TestParseTaskStats_RealWorldExamples(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			stats := parseTaskStats(tt.content)
+			stats := ParseTaskStats(tt.content)
 
 			if stats.Total != tt.wantTotal {
 				t.Errorf("Total = %d, want %d", stats.Total, tt.wantTotal)
@@ -17,11 +17,13 @@ const (
 	defaultCameraFormat         = "jpg"
 	defaultCameraDirKey         = "AAGENT_CAMERA_OUTPUT_DIR"
 	defaultCameraDir            = "/tmp"
-	defaultCameraIndexKey       = "AAGENT_CAMERA_INDEX"
 	defaultCameraIndex          = 1
 	defaultInlineMaxBytes int64 = 2 * 1024 * 1024
 )
 
+// CameraIndexKey is the setting holding the default 1-based camera index.
+const CameraIndexKey = "AAGENT_CAMERA_INDEX"
+
 type TakeCameraPhotoParams struct {
 	OutputPath     string `json:"output_path,omitempty"`
 	OutputDir      string `json:"output_dir,omitempty"`
@@ -211,7 +213,7 @@ func normalizeCameraFormat(raw string, filename string, outputPath string) (stri
 }
 
 func configuredDefaultCameraIndex() int {
-	raw := strings.TrimSpace(os.Getenv(defaultCameraIndexKey))
+	raw := strings.TrimSpace(os.Getenv(CameraIndexKey))
 	if raw == "" {
 		return 0
 	}
@@ -354,27 +356,53 @@ func captureCameraPhotoWindows(ctx context.Context, cameraIndex int, format stri
 }
 
 func listCamerasWindowsFFmpeg(ctx context.Context) ([]string, error) {
+	return listDShowDevicesFFmpeg(ctx, "video")
+}
+
+// listDShowDevicesFFmpeg lists DirectShow device names of the given kind
+// ("video" or "audio") as reported by `ffmpeg -list_devices`.
+func listDShowDevicesFFmpeg(ctx context.Context, kind string) ([]string, error) {
 	cmd := exec.CommandContext(ctx, "ffmpeg", "-hide_banner", "-list_devices", "true", "-f", "dshow", "-i", "dummy")
 	out, err := cmd.CombinedOutput()
 	if len(out) == 0 && err != nil {
 		return nil, err
 	}
+	return parseDShowDevices(string(out), kind), nil
+}
 
-	lines := strings.Split(string(out), "\n")
-	cameras := make([]string, 0, len(lines))
-	inVideoDevices := false
+// parseDShowDevices handles both the sectioned ("DirectShow video devices")
+// and the suffixed (`"Name" (video)`) listing formats used by ffmpeg versions.
+func parseDShowDevices(output string, kind string) []string {
+	lines := strings.Split(output, "\n")
+	devices := make([]string, 0, len(lines))
+	section := ""
 	for _, line := range lines {
 		trimmed := strings.TrimSpace(line)
 		lower := strings.ToLower(trimmed)
 		if strings.Contains(lower, "directshow video devices") {
-			inVideoDevices = true
+			section = "video"
 			continue
 		}
 		if strings.Contains(lower, "directshow audio devices") {
-			inVideoDevices = false
+			section = "audio"
 			continue
 		}
-		if !inVideoDevices || !strings.Contains(trimmed, "[dshow") || !strings.Contains(trimmed, "\"") {
+		if !strings.Contains(trimmed, "[dshow") || !strings.Contains(trimmed, "\"") {
+			continue
+		}
+		if strings.Contains(lower, "alternative name") {
+			continue
+		}
+		lineKind := section
+		switch {
+		case strings.HasSuffix(lower, "(video)"):
+			lineKind = "video"
+		case strings.HasSuffix(lower, "(audio)"):
+			lineKind = "audio"
+		case strings.HasSuffix(lower, "(none)"):
+			lineKind = ""
+		}
+		if lineKind != kind {
 			continue
 		}
 		start := strings.Index(trimmed, "\"")
@@ -382,21 +410,21 @@ func listCamerasWindowsFFmpeg(ctx context.Context) ([]string, error) {
 		if start >= 0 && end > start {
 			name := strings.TrimSpace(trimmed[start+1 : end])
 			if name != "" {
-				cameras = append(cameras, name)
+				devices = append(devices, name)
 			}
 		}
 	}
 
-	unique := make([]string, 0, len(cameras))
+	unique := make([]string, 0, len(devices))
 	seen := map[string]struct{}{}
-	for _, name := range cameras {
+	for _, name := range devices {
 		if _, ok := seen[name]; ok {
 			continue
 		}
 		seen[name] = struct{}{}
 		unique = append(unique, name)
 	}
-	return unique, nil
+	return unique
 }
 
 var _ Tool = (*TakeCameraPhotoTool)(nil)
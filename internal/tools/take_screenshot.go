@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -22,6 +23,10 @@ const (
 	defaultScreenshotDisplayKey = "AAGENT_SCREENSHOT_DISPLAY_INDEX"
 )
 
+// errNativeScreenshotUnsupported signals that the native capture path cannot
+// handle the request and the CLI fallback should be used instead.
+var errNativeScreenshotUnsupported = errors.New("native screenshot capture is not available")
+
 type ScreenshotArea struct {
 	X      int `json:"x"`
 	Y      int `json:"y"`
@@ -30,13 +35,15 @@ type ScreenshotArea struct {
 }
 
 type TakeScreenshotParams struct {
-	OutputPath   string          `json:"output_path,omitempty"`
-	OutputDir    string          `json:"output_dir,omitempty"`
-	Filename     string          `json:"filename,omitempty"`
-	Format       string          `json:"format,omitempty"` // png | jpg | jpeg
-	Target       string          `json:"target,omitempty"` // main | all | display | area
-	DisplayIndex int             `json:"display_index,omitempty"`
-	Area         *ScreenshotArea `json:"area,omitempty"`
+	OutputPath     string          `json:"output_path,omitempty"`
+	OutputDir      string          `json:"output_dir,omitempty"`
+	Filename       string          `json:"filename,omitempty"`
+	Format         string          `json:"format,omitempty"` // png | jpg | jpeg
+	Target         string          `json:"target,omitempty"` // main | all | display | area
+	DisplayIndex   int             `json:"display_index,omitempty"`
+	Area           *ScreenshotArea `json:"area,omitempty"`
+	ReturnInline   *bool           `json:"return_inline,omitempty"`
+	InlineMaxBytes int64           `json:"inline_max_bytes,omitempty"`
 }
 
 type TakeScreenshotTool struct {
@@ -55,7 +62,8 @@ func (t *TakeScreenshotTool) Description() string {
 	return `Capture a screenshot of the current display setup.
 Supports: main display, all displays, a specific display index, or a rectangular area.
 You can control where the screenshot file is stored via output_path/output_dir/filename.
-If not provided, it uses the default screenshot settings configured in the Tools UI.`
+If not provided, it uses the default screenshot settings configured in the Tools UI.
+Can also return inline image metadata for in-memory multimodal model handoff.`
 }
 
 func (t *TakeScreenshotTool) Schema() map[string]interface{} {
@@ -111,6 +119,14 @@ func (t *TakeScreenshotTool) Schema() map[string]interface{} {
 				},
 				"required": []string{"x", "y", "width", "height"},
 			},
+			"return_inline": map[string]interface{}{
+				"type":        "boolean",
+				"description": "When true, includes inline image metadata in the result for in-memory model handoff (default: true).",
+			},
+			"inline_max_bytes": map[string]interface{}{
+				"type":        "integer",
+				"description": "Maximum bytes allowed for inline base64 payload (default: 2097152).",
+			},
 		},
 	}
 }
@@ -176,21 +192,56 @@ func (t *TakeScreenshotTool) Execute(ctx context.Context, params json.RawMessage
 		payload["area"] = p.Area
 	}
 
+	returnInline := true
+	if p.ReturnInline != nil {
+		returnInline = *p.ReturnInline
+	}
+
+	inlineMaxBytes := p.InlineMaxBytes
+	if inlineMaxBytes <= 0 {
+		inlineMaxBytes = defaultInlineMaxBytes
+	}
+
+	metadata := map[string]interface{}{
+		"image_file": map[string]interface{}{
+			"path":        absPath,
+			"format":      format,
+			"bytes":       info.Size(),
+			"source_tool": t.Name(),
+		},
+	}
+
+	if returnInline && info.Size() <= inlineMaxBytes {
+		mediaType := "image/png"
+		if format == "jpg" {
+			mediaType = "image/jpeg"
+		}
+		inline := map[string]interface{}{
+			"path":        absPath,
+			"media_type":  mediaType,
+			"max_bytes":   inlineMaxBytes,
+			"source_tool": t.Name(),
+		}
+		if target == "display" {
+			inline["display_index"] = p.DisplayIndex
+		}
+		metadata["image_inline"] = inline
+		payload["inline_available"] = true
+	} else {
+		payload["inline_available"] = false
+		if returnInline && info.Size() > inlineMaxBytes {
+			payload["inline_skipped_reason"] = fmt.Sprintf("image is %d bytes, exceeds inline_max_bytes=%d", info.Size(), inlineMaxBytes)
+		}
+	}
+
 	out, err := json.Marshal(payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode result: %w", err)
 	}
 	return &Result{
-		Success: true,
-		Output:  string(out),
-		Metadata: map[string]interface{}{
-			"image_file": map[string]interface{}{
-				"path":        absPath,
-				"format":      format,
-				"bytes":       info.Size(),
-				"source_tool": t.Name(),
-			},
-		},
+		Success:  true,
+		Output:   string(out),
+		Metadata: metadata,
 	}, nil
 }
 
@@ -334,6 +385,11 @@ func captureScreenshot(ctx context.Context, target string, displayIndex int, are
 }
 
 func captureScreenshotDarwin(ctx context.Context, target string, displayIndex int, area *ScreenshotArea, format string, outputPath string) error {
+	err := captureScreenshotDarwinNative(target, displayIndex, area, format, outputPath)
+	if err == nil || !errors.Is(err, errNativeScreenshotUnsupported) {
+		return err
+	}
+
 	args := []string{"-x", "-t", format}
 	switch target {
 	case "main":
@@ -353,7 +409,12 @@ func captureScreenshotDarwin(ctx context.Context, target string, displayIndex in
 
 func captureScreenshotLinux(ctx context.Context, target string, displayIndex int, area *ScreenshotArea, outputPath string) error {
 	if target == "display" {
-		return fmt.Errorf("target=display is not currently supported on linux")
+		bounds, err := linuxDisplayBounds(ctx, displayIndex)
+		if err != nil {
+			return err
+		}
+		target = "area"
+		area = bounds
 	}
 
 	if _, err := exec.LookPath("grim"); err == nil {
@@ -386,7 +447,57 @@ func captureScreenshotLinux(ctx context.Context, target string, displayIndex int
 	return fmt.Errorf("no supported screenshot binary found (tried grim, scrot, import)")
 }
 
+// linuxDisplayBounds resolves a 1-based display index to its geometry using
+// xrandr, so display captures can be expressed as an area crop for every backend.
+func linuxDisplayBounds(ctx context.Context, displayIndex int) (*ScreenshotArea, error) {
+	if _, err := exec.LookPath("xrandr"); err != nil {
+		return nil, fmt.Errorf("target=display on linux requires xrandr to resolve display geometry")
+	}
+	out, err := exec.CommandContext(ctx, "xrandr", "--listmonitors").Output()
+	if err != nil {
+		return nil, fmt.Errorf("xrandr failed: %w", err)
+	}
+	monitors := parseXrandrMonitors(string(out))
+	if displayIndex <= 0 || displayIndex > len(monitors) {
+		return nil, fmt.Errorf("display_index out of range: %d (available: %d)", displayIndex, len(monitors))
+	}
+	return &monitors[displayIndex-1], nil
+}
+
+// parseXrandrMonitors extracts monitor geometry from `xrandr --listmonitors`
+// lines such as " 0: +*eDP-1 1920/344x1080/193+0+0  eDP-1".
+func parseXrandrMonitors(output string) []ScreenshotArea {
+	monitors := make([]ScreenshotArea, 0, 2)
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 || !strings.HasSuffix(fields[0], ":") {
+			continue
+		}
+		geometry := fields[2]
+		var w, h, x, y int
+		var wmm, hmm int
+		if _, err := fmt.Sscanf(geometry, "%d/%dx%d/%d+%d+%d", &w, &wmm, &h, &hmm, &x, &y); err != nil {
+			if _, err := fmt.Sscanf(geometry, "%dx%d+%d+%d", &w, &h, &x, &y); err != nil {
+				continue
+			}
+		}
+		if w <= 0 || h <= 0 {
+			continue
+		}
+		monitors = append(monitors, ScreenshotArea{X: x, Y: y, Width: w, Height: h})
+	}
+	return monitors
+}
+
 func captureScreenshotWindows(ctx context.Context, target string, displayIndex int, area *ScreenshotArea, format string, outputPath string) error {
+	// gdigrab covers the virtual desktop and arbitrary regions without a shell;
+	// per-display bounds still need System.Windows.Forms.
+	if target == "all" || target == "area" {
+		if _, err := exec.LookPath("ffmpeg"); err == nil {
+			return captureScreenshotWindowsFFmpeg(ctx, area, format, outputPath)
+		}
+	}
+
 	shell := "powershell"
 	if _, err := exec.LookPath(shell); err != nil {
 		shell = "pwsh"
@@ -458,6 +569,27 @@ $bmp.Dispose()
 	return runCommand(ctx, shell, cmdArgs...)
 }
 
+func captureScreenshotWindowsFFmpeg(ctx context.Context, area *ScreenshotArea, format string, outputPath string) error {
+	args := []string{
+		"-y",
+		"-loglevel", "error",
+		"-f", "gdigrab",
+	}
+	if area != nil {
+		args = append(args,
+			"-offset_x", strconv.Itoa(area.X),
+			"-offset_y", strconv.Itoa(area.Y),
+			"-video_size", fmt.Sprintf("%dx%d", area.Width, area.Height),
+		)
+	}
+	args = append(args, "-i", "desktop")
+	if format == "png" {
+		args = append(args, "-vcodec", "png")
+	}
+	args = append(args, "-frames:v", "1", outputPath)
+	return runCommand(ctx, "ffmpeg", args...)
+}
+
 func runCommand(ctx context.Context, name string, args ...string) error {
 	cmd := exec.CommandContext(ctx, name, args...)
 	var stderr bytes.Buffer
@@ -0,0 +1,241 @@
+//go:build darwin && cgo
+
+package tools
+
+/*
+#cgo CFLAGS: -x objective-c -fobjc-arc
+#cgo LDFLAGS: -framework Foundation -framework AppKit -framework CoreGraphics -weak_framework ScreenCaptureKit
+
+#import <AvailabilityMacros.h>
+#import <Foundation/Foundation.h>
+#import <AppKit/AppKit.h>
+#import <CoreGraphics/CoreGraphics.h>
+#if __MAC_OS_X_VERSION_MAX_ALLOWED >= 140000
+#import <ScreenCaptureKit/ScreenCaptureKit.h>
+#endif
+#import <dispatch/dispatch.h>
+#include <stdlib.h>
+#include <string.h>
+
+// Return codes: 0 = ok, 1 = capture failed, 2 = not supported natively (caller falls back to screencapture).
+
+static void set_screenshot_error(char **err_out, NSString *message) {
+    if (err_out == NULL) {
+        return;
+    }
+    const char *utf8 = [message UTF8String];
+    if (utf8 == NULL) {
+        utf8 = "unknown error";
+    }
+    *err_out = strdup(utf8);
+}
+
+static CGImageRef aagent_create_display_image(CGDirectDisplayID displayID, CGRect localRect, BOOL useRect, char **err_out) {
+#if __MAC_OS_X_VERSION_MAX_ALLOWED >= 140000
+    if (@available(macOS 14.0, *)) {
+        dispatch_semaphore_t contentSem = dispatch_semaphore_create(0);
+        __block SCShareableContent *content = nil;
+        __block NSError *contentErr = nil;
+        [SCShareableContent getShareableContentWithCompletionHandler:^(SCShareableContent *c, NSError *e) {
+            content = c;
+            contentErr = e;
+            dispatch_semaphore_signal(contentSem);
+        }];
+        if (dispatch_semaphore_wait(contentSem, dispatch_time(DISPATCH_TIME_NOW, (int64_t)(10 * NSEC_PER_SEC))) != 0) {
+            set_screenshot_error(err_out, @"timed out waiting for shareable content");
+            return NULL;
+        }
+        if (content == nil) {
+            set_screenshot_error(err_out, contentErr != nil ? contentErr.localizedDescription : @"screen recording permission is not granted");
+            return NULL;
+        }
+
+        SCDisplay *display = nil;
+        for (SCDisplay *candidate in content.displays) {
+            if (candidate.displayID == displayID) {
+                display = candidate;
+                break;
+            }
+        }
+        if (display == nil) {
+            set_screenshot_error(err_out, @"display not available for capture");
+            return NULL;
+        }
+
+        SCContentFilter *filter = [[SCContentFilter alloc] initWithDisplay:display excludingWindows:@[]];
+        SCStreamConfiguration *cfg = [[SCStreamConfiguration alloc] init];
+        CGFloat scale = 1.0;
+        CGDisplayModeRef mode = CGDisplayCopyDisplayMode(displayID);
+        if (mode != NULL) {
+            size_t pixelWidth = CGDisplayModeGetPixelWidth(mode);
+            size_t pointWidth = CGDisplayModeGetWidth(mode);
+            if (pointWidth > 0) {
+                scale = (CGFloat)pixelWidth / (CGFloat)pointWidth;
+            }
+            CGDisplayModeRelease(mode);
+        }
+        CGSize size = CGSizeMake(display.width, display.height);
+        if (useRect) {
+            cfg.sourceRect = localRect;
+            size = localRect.size;
+        }
+        cfg.width = (size_t)(size.width * scale);
+        cfg.height = (size_t)(size.height * scale);
+        cfg.showsCursor = NO;
+
+        dispatch_semaphore_t shotSem = dispatch_semaphore_create(0);
+        __block CGImageRef captured = NULL;
+        __block NSError *shotErr = nil;
+        [SCScreenshotManager captureImageWithFilter:filter
+                                      configuration:cfg
+                                  completionHandler:^(CGImageRef img, NSError *e) {
+            if (img != NULL) {
+                captured = CGImageRetain(img);
+            }
+            shotErr = e;
+            dispatch_semaphore_signal(shotSem);
+        }];
+        if (dispatch_semaphore_wait(shotSem, dispatch_time(DISPATCH_TIME_NOW, (int64_t)(10 * NSEC_PER_SEC))) != 0) {
+            set_screenshot_error(err_out, @"screenshot capture timed out");
+            return NULL;
+        }
+        if (captured == NULL) {
+            set_screenshot_error(err_out, shotErr != nil ? shotErr.localizedDescription : @"no image captured");
+        }
+        return captured;
+    }
+#endif
+#if __MAC_OS_X_VERSION_MAX_ALLOWED < 150000
+    CGImageRef image = useRect ? CGDisplayCreateImageForRect(displayID, localRect) : CGDisplayCreateImage(displayID);
+    if (image == NULL) {
+        set_screenshot_error(err_out, @"CGDisplayCreateImage returned no image (screen recording permission may be missing)");
+    }
+    return image;
+#else
+    set_screenshot_error(err_out, @"native screen capture requires macOS 14 or newer");
+    return NULL;
+#endif
+}
+
+int aagent_capture_screenshot_darwin(const char *target, int display_index, int x, int y, int w, int h,
+                                     const char *output_path, const char *format, char **err_out) {
+    @autoreleasepool {
+        if (target == NULL || output_path == NULL || format == NULL) {
+            set_screenshot_error(err_out, @"invalid capture arguments");
+            return 1;
+        }
+        NSString *targetStr = [NSString stringWithUTF8String:target];
+        NSString *outputPath = [NSString stringWithUTF8String:output_path];
+        NSString *formatStr = [[NSString stringWithUTF8String:format] lowercaseString];
+        if (targetStr == nil || outputPath == nil || formatStr == nil) {
+            set_screenshot_error(err_out, @"invalid capture arguments encoding");
+            return 1;
+        }
+        if ([targetStr isEqualToString:@"all"]) {
+            // Stitching multiple displays is left to screencapture.
+            return 2;
+        }
+        BOOL outputPNG = [formatStr isEqualToString:@"png"];
+
+        uint32_t count = 0;
+        CGDirectDisplayID displays[32];
+        if (CGGetActiveDisplayList(32, displays, &count) != kCGErrorSuccess || count == 0) {
+            set_screenshot_error(err_out, @"no active displays found");
+            return 1;
+        }
+
+        CGDirectDisplayID displayID = CGMainDisplayID();
+        CGRect localRect = CGRectZero;
+        BOOL useRect = NO;
+        if ([targetStr isEqualToString:@"display"]) {
+            if (display_index <= 0 || display_index > (int)count) {
+                set_screenshot_error(err_out, [NSString stringWithFormat:@"display_index out of range: %d (available: %u)",
+                                               display_index, count]);
+                return 1;
+            }
+            displayID = displays[display_index - 1];
+        } else if ([targetStr isEqualToString:@"area"]) {
+            CGRect area = CGRectMake(x, y, w, h);
+            BOOL found = NO;
+            for (uint32_t i = 0; i < count; i++) {
+                CGRect bounds = CGDisplayBounds(displays[i]);
+                if (CGRectContainsRect(bounds, area)) {
+                    displayID = displays[i];
+                    localRect = CGRectOffset(area, -bounds.origin.x, -bounds.origin.y);
+                    found = YES;
+                    break;
+                }
+            }
+            if (!found) {
+                // Areas spanning several displays are left to screencapture.
+                return 2;
+            }
+            useRect = YES;
+        }
+
+        CGImageRef image = aagent_create_display_image(displayID, localRect, useRect, err_out);
+        if (image == NULL) {
+            return 1;
+        }
+
+        NSBitmapImageRep *bitmap = [[NSBitmapImageRep alloc] initWithCGImage:image];
+        CGImageRelease(image);
+        if (bitmap == nil) {
+            set_screenshot_error(err_out, @"failed to create bitmap image");
+            return 1;
+        }
+        NSBitmapImageFileType fileType = outputPNG ? NSBitmapImageFileTypePNG : NSBitmapImageFileTypeJPEG;
+        NSDictionary *props = outputPNG ? @{} : @{NSImageCompressionFactor: @0.92};
+        NSData *data = [bitmap representationUsingType:fileType properties:props];
+        if (data == nil || data.length == 0) {
+            set_screenshot_error(err_out, @"failed to encode screenshot");
+            return 1;
+        }
+
+        NSError *writeErr = nil;
+        if (![data writeToURL:[NSURL fileURLWithPath:outputPath] options:NSDataWritingAtomic error:&writeErr]) {
+            set_screenshot_error(err_out, writeErr != nil ? writeErr.localizedDescription : @"failed to write output image");
+            return 1;
+        }
+        return 0;
+    }
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+func captureScreenshotDarwinNative(target string, displayIndex int, area *ScreenshotArea, format string, outputPath string) error {
+	cTarget := C.CString(target)
+	cOutputPath := C.CString(outputPath)
+	cFormat := C.CString(format)
+	defer C.free(unsafe.Pointer(cTarget))
+	defer C.free(unsafe.Pointer(cOutputPath))
+	defer C.free(unsafe.Pointer(cFormat))
+
+	var x, y, w, h int
+	if area != nil {
+		x, y, w, h = area.X, area.Y, area.Width, area.Height
+	}
+
+	var cErr *C.char
+	rc := C.aagent_capture_screenshot_darwin(cTarget, C.int(displayIndex), C.int(x), C.int(y), C.int(w), C.int(h), cOutputPath, cFormat, &cErr)
+	if rc == 0 {
+		return nil
+	}
+	defer func() {
+		if cErr != nil {
+			C.free(unsafe.Pointer(cErr))
+		}
+	}()
+	if rc == 2 {
+		return errNativeScreenshotUnsupported
+	}
+	if cErr != nil {
+		return fmt.Errorf("native screenshot capture failed: %s", C.GoString(cErr))
+	}
+	return fmt.Errorf("native screenshot capture failed")
+}
@@ -0,0 +1,12 @@
+//go:build darwin && !cgo
+
+package tools
+
+func captureScreenshotDarwinNative(target string, displayIndex int, area *ScreenshotArea, format string, outputPath string) error {
+	_ = target
+	_ = displayIndex
+	_ = area
+	_ = format
+	_ = outputPath
+	return errNativeScreenshotUnsupported
+}
@@ -0,0 +1,7 @@
+//go:build !darwin
+
+package tools
+
+func captureScreenshotDarwinNative(target string, displayIndex int, area *ScreenshotArea, format string, outputPath string) error {
+	return errNativeScreenshotUnsupported
+}
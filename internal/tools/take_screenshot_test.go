@@ -0,0 +1,35 @@
+package tools
+
+import "testing"
+
+func TestParseXrandrMonitors(t *testing.T) {
+	output := `Monitors: 2
+ 0: +*eDP-1 1920/344x1080/193+0+0  eDP-1
+ 1: +HDMI-1 2560/597x1440/336+1920+0  HDMI-1
+`
+	monitors := parseXrandrMonitors(output)
+	if len(monitors) != 2 {
+		t.Fatalf("expected 2 monitors, got %d", len(monitors))
+	}
+	if monitors[0] != (ScreenshotArea{X: 0, Y: 0, Width: 1920, Height: 1080}) {
+		t.Fatalf("unexpected first monitor: %+v", monitors[0])
+	}
+	if monitors[1] != (ScreenshotArea{X: 1920, Y: 0, Width: 2560, Height: 1440}) {
+		t.Fatalf("unexpected second monitor: %+v", monitors[1])
+	}
+}
+
+func TestValidateScreenshotTarget(t *testing.T) {
+	if err := validateScreenshotTarget("area", 0, nil); err == nil {
+		t.Fatal("expected error when area is missing")
+	}
+	if err := validateScreenshotTarget("display", 2, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validateScreenshotTarget("main", 1, nil); err == nil {
+		t.Fatal("expected error for display_index with target=main")
+	}
+	if err := validateScreenshotTarget("area", 0, &ScreenshotArea{Width: 10, Height: 0}); err == nil {
+		t.Fatal("expected error for zero-height area")
+	}
+}
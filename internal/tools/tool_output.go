@@ -0,0 +1,123 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+// ToolOutputToolName is the name the stored tool output reader is registered under.
+const ToolOutputToolName = "read_tool_output"
+
+const defaultToolOutputReadLimit = 16 * 1024
+
+// ToolOutputStore returns full outputs that were truncated in the conversation.
+type ToolOutputStore interface {
+	GetToolOutput(id string) (*storage.ToolOutput, error)
+}
+
+// ToolOutputTool reads byte ranges of stored tool outputs.
+type ToolOutputTool struct {
+	store ToolOutputStore
+}
+
+// ToolOutputParams defines parameters for the read_tool_output tool
+type ToolOutputParams struct {
+	ID     string `json:"id"`
+	Offset int    `json:"offset,omitempty"`
+	Limit  int    `json:"limit,omitempty"`
+}
+
+// NewToolOutputTool creates a new stored tool output reader
+func NewToolOutputTool(store ToolOutputStore) *ToolOutputTool {
+	return &ToolOutputTool{store: store}
+}
+
+func (t *ToolOutputTool) Name() string {
+	return ToolOutputToolName
+}
+
+func (t *ToolOutputTool) Description() string {
+	return `Read part of a tool output that was too large to include in full.
+
+Truncated results end their visible head with a marker containing tool_output_id=<id>
+and the offset of the omitted part. Pass that id and an offset (in bytes) to read the
+next range; the response says which range was returned and how many bytes remain.`
+}
+
+func (t *ToolOutputTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id": map[string]interface{}{
+				"type":        "string",
+				"description": "The tool_output_id from the truncation marker",
+			},
+			"offset": map[string]interface{}{
+				"type":        "integer",
+				"description": "Byte offset to start reading from (default: 0)",
+			},
+			"limit": map[string]interface{}{
+				"type":        "integer",
+				"description": fmt.Sprintf("Maximum number of bytes to return (default: %d)", defaultToolOutputReadLimit),
+			},
+		},
+		"required": []string{"id"},
+	}
+}
+
+func (t *ToolOutputTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	var p ToolOutputParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+
+	id := strings.TrimSpace(p.ID)
+	if id == "" {
+		return &Result{Success: false, Error: "id is required"}, nil
+	}
+	if p.Offset < 0 {
+		return &Result{Success: false, Error: "offset must not be negative"}, nil
+	}
+	limit := p.Limit
+	if limit <= 0 || limit > defaultToolOutputReadLimit {
+		limit = defaultToolOutputReadLimit
+	}
+
+	output, err := t.store.GetToolOutput(id)
+	if err != nil {
+		return &Result{Success: false, Error: fmt.Sprintf("tool output %s not found", id)}, nil
+	}
+	// Outputs are only readable from the session that produced them.
+	if sessionID := getSessionIDFromContext(ctx); sessionID != "" && output.SessionID != sessionID {
+		return &Result{Success: false, Error: fmt.Sprintf("tool output %s not found", id)}, nil
+	}
+
+	content := output.Content
+	if p.Offset >= len(content) {
+		return &Result{Success: false, Error: fmt.Sprintf("offset %d is past the end of the output (%d bytes)", p.Offset, len(content))}, nil
+	}
+
+	start := p.Offset
+	for start > 0 && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	end := start + limit
+	if end >= len(content) {
+		end = len(content)
+	} else {
+		for end > start && !utf8.RuneStart(content[end]) {
+			end--
+		}
+	}
+
+	header := fmt.Sprintf("[%s output %s, bytes %d-%d of %d; %d bytes remaining]\n", output.ToolName, output.ID, start, end, len(content), len(content)-end)
+	return &Result{
+		Success: true,
+		Output:  header + content[start:end],
+	}, nil
+}
@@ -0,0 +1,65 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/storage"
+)
+
+type fakeToolOutputStore map[string]*storage.ToolOutput
+
+func (f fakeToolOutputStore) GetToolOutput(id string) (*storage.ToolOutput, error) {
+	output, ok := f[id]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return output, nil
+}
+
+func TestToolOutputToolReadsRanges(t *testing.T) {
+	store := fakeToolOutputStore{
+		"out-1": {ID: "out-1", SessionID: "sess-1", ToolName: "bash", Content: "0123456789abcdef"},
+	}
+	tool := NewToolOutputTool(store)
+	ctx := context.WithValue(context.Background(), "session_id", "sess-1")
+
+	result, err := tool.Execute(ctx, json.RawMessage(`{"id":"out-1","offset":10,"limit":4}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Execute failed: %s", result.Error)
+	}
+	header, body, _ := strings.Cut(result.Output, "\n")
+	if body != "abcd" {
+		t.Fatalf("read %q, want abcd", body)
+	}
+	if !strings.Contains(header, "bytes 10-14 of 16") || !strings.Contains(header, "2 bytes remaining") {
+		t.Fatalf("unexpected header %q", header)
+	}
+
+	result, _ = tool.Execute(ctx, json.RawMessage(`{"id":"out-1","offset":16}`))
+	if result.Success {
+		t.Fatal("expected failure for an offset past the end")
+	}
+}
+
+func TestToolOutputToolRejectsOtherSessions(t *testing.T) {
+	store := fakeToolOutputStore{
+		"out-1": {ID: "out-1", SessionID: "sess-1", ToolName: "bash", Content: "secret"},
+	}
+	tool := NewToolOutputTool(store)
+	ctx := context.WithValue(context.Background(), "session_id", "sess-2")
+
+	result, err := tool.Execute(ctx, json.RawMessage(`{"id":"out-1"}`))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if result.Success || strings.Contains(result.Output, "secret") {
+		t.Fatalf("output from another session should not be readable: %+v", result)
+	}
+}
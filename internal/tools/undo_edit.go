@@ -0,0 +1,90 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// UndoEditToolName is the name of the tool that reverts file changes.
+const UndoEditToolName = "undo_edit"
+
+// UndoEditTool reverts the changes file tools made in the current session.
+type UndoEditTool struct {
+	workDir string
+	backups *BackupStore
+}
+
+// UndoEditParams defines parameters for the undo_edit tool.
+type UndoEditParams struct {
+	Path string `json:"path,omitempty"`
+}
+
+// NewUndoEditTool creates a new undo_edit tool.
+func NewUndoEditTool(workDir string, backups *BackupStore) *UndoEditTool {
+	return &UndoEditTool{workDir: workDir, backups: backups}
+}
+
+func (t *UndoEditTool) Name() string {
+	return UndoEditToolName
+}
+
+func (t *UndoEditTool) Description() string {
+	return `Revert the most recent change that write, edit, replace_lines or insert_lines made in this session.
+With path, reverts the latest change of that file; without, the latest change overall.
+Calling it again steps further back. A file created by the reverted call is removed.`
+}
+
+func (t *UndoEditTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"path": map[string]interface{}{
+				"type":        "string",
+				"description": "File whose latest change to revert (optional, defaults to the latest change of any file)",
+			},
+		},
+	}
+}
+
+func (t *UndoEditTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	var p UndoEditParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, fmt.Errorf("invalid parameters: %w", err)
+		}
+	}
+
+	sessionID := getSessionIDFromContext(ctx)
+	if sessionID == "" {
+		return &Result{Success: false, Error: "undo_edit only works within a session"}, nil
+	}
+	path := ""
+	if p.Path != "" {
+		path = resolveToolPath(t.workDir, p.Path)
+	}
+
+	backup, err := t.backups.Undo(sessionID, path)
+	if errors.Is(err, ErrNoBackup) {
+		if p.Path != "" {
+			return &Result{Success: false, Error: fmt.Sprintf("no backed up change of %s to revert in this session", p.Path)}, nil
+		}
+		return &Result{Success: false, Error: "no backed up change to revert in this session"}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	output := DescribeUndo(backup, t.workDir)
+	if remaining := t.backups.Remaining(sessionID, backup.Path); remaining > 0 {
+		output += fmt.Sprintf("\n%d older change(s) of this file can still be reverted", remaining)
+	}
+	return &Result{
+		Success: true,
+		Output:  output,
+	}, nil
+}
+
+// Ensure UndoEditTool implements Tool
+var _ Tool = (*UndoEditTool)(nil)
@@ -0,0 +1,77 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	skillsLoader "github.com/A2gent/brute/internal/skills"
+)
+
+// UseSkillToolName is the name the skill loader tool is registered under.
+const UseSkillToolName = "use_skill"
+
+// UseSkillTool loads the full instructions of a markdown skill from the
+// configured skills folder. The system prompt only lists skills by name and
+// description so their bodies cost tokens only when used.
+type UseSkillTool struct{}
+
+// UseSkillParams defines parameters for the use_skill tool
+type UseSkillParams struct {
+	Skill string `json:"skill"`
+}
+
+// NewUseSkillTool creates a new use_skill tool
+func NewUseSkillTool() *UseSkillTool {
+	return &UseSkillTool{}
+}
+
+func (t *UseSkillTool) Name() string {
+	return UseSkillToolName
+}
+
+func (t *UseSkillTool) Description() string {
+	return `Load the full instructions of a skill listed under "Available skills" in the system prompt.
+Pass the skill name or its relative path. Read the instructions before following the skill.`
+}
+
+func (t *UseSkillTool) Schema() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"skill": map[string]interface{}{
+				"type":        "string",
+				"description": "Skill name or path relative to the skills folder, e.g. \"deploy/SKILL.md\"",
+			},
+		},
+		"required": []string{"skill"},
+	}
+}
+
+func (t *UseSkillTool) Execute(ctx context.Context, params json.RawMessage) (*Result, error) {
+	var p UseSkillParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, fmt.Errorf("invalid parameters: %w", err)
+	}
+	if p.Skill == "" {
+		return &Result{Success: false, Error: "skill is required"}, nil
+	}
+
+	folder, err := skillsLoader.ResolveFolder(os.Getenv(skillsLoader.FolderSettingKey))
+	if err != nil {
+		return &Result{Success: false, Error: err.Error()}, nil
+	}
+	skill, err := skillsLoader.Find(folder, p.Skill)
+	if err != nil {
+		return &Result{Success: false, Error: err.Error()}, nil
+	}
+	if skill.Strategy == skillsLoader.StrategyDisabled {
+		return &Result{Success: false, Error: fmt.Sprintf("skill %q is disabled", p.Skill)}, nil
+	}
+
+	return &Result{
+		Success: true,
+		Output:  fmt.Sprintf("Instructions from: %s\n\n%s", skill.RelativePath, skill.Body),
+	}, nil
+}
@@ -0,0 +1,260 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// walkCheckInterval is how many entries a walk visits between checks of its
+// context.
+const walkCheckInterval = 256
+
+// walkSkippedDirs are version control directories, which walks never enter.
+var walkSkippedDirs = map[string]bool{".git": true, ".hg": true, ".svn": true}
+
+// errStopWalk is returned by a walkGlob visit function to end the walk early
+// without an error.
+var errStopWalk = errors.New("stop walk")
+
+// walkOptions controls which directories walkGlob descends into.
+type walkOptions struct {
+	// SkipDir prunes the directory at path when it returns true. It is not
+	// called for the directory a pattern starts in.
+	SkipDir func(path string) bool
+	// Gitignore skips files and directories ignored by .gitignore files.
+	Gitignore bool
+	// AgentIgnore skips what the work directory's .aagentignore excludes,
+	// whatever Gitignore and .gitignore files say. The file is read once per
+	// walk.
+	AgentIgnore *agentIgnore
+}
+
+// walkRoot is a directory walked for the patterns that start in it, which
+// are relative to it and slash separated.
+type walkRoot struct {
+	dir      string
+	patterns []string
+	segments [][]string
+}
+
+// matches reports whether the file at rel matches one of the patterns.
+func (r *walkRoot) matches(rel string) bool {
+	for _, pattern := range r.patterns {
+		if ok, _ := doublestar.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mayContain reports whether the directory at rel can hold a match, so that
+// "src/*.go" only ever lists src.
+func (r *walkRoot) mayContain(rel string) bool {
+	dirSegments := strings.Split(rel, "/")
+	for _, segments := range r.segments {
+		if dirMayMatch(segments, dirSegments) {
+			return true
+		}
+	}
+	return false
+}
+
+func dirMayMatch(patternSegments, dirSegments []string) bool {
+	for i, dir := range dirSegments {
+		if i >= len(patternSegments)-1 {
+			return false
+		}
+		if patternSegments[i] == "**" {
+			return true
+		}
+		if ok, _ := doublestar.Match(patternSegments[i], dir); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// walkGlob calls visit once for each file matching one of the given
+// filesystem patterns, in lexical order within the directory each pattern
+// starts in. Unlike doublestar.FilepathGlob it checks ctx as it goes, prunes
+// directories before descending into them and lets visit stop it, so that a
+// huge tree neither blocks cancellation nor gets listed in full first.
+//
+// Symlinks are followed, except into a directory that contains them.
+// Unreadable directories are skipped. Returning errStopWalk from visit ends
+// the walk with a nil error; any other error ends it and is returned, as is
+// ctx.Err() once ctx is done.
+func walkGlob(ctx context.Context, patterns []string, opts walkOptions, visit func(path string, info fs.FileInfo) error) error {
+	var roots []*walkRoot
+	byDir := make(map[string]*walkRoot)
+	for _, pattern := range patterns {
+		base, rest := doublestar.SplitPattern(filepath.ToSlash(pattern))
+		if !doublestar.ValidatePattern(rest) {
+			return fmt.Errorf("%w: %s", doublestar.ErrBadPattern, pattern)
+		}
+		dir := filepath.FromSlash(base)
+		root, ok := byDir[dir]
+		if !ok {
+			root = &walkRoot{dir: dir}
+			byDir[dir] = root
+			roots = append(roots, root)
+		}
+		root.patterns = append(root.patterns, rest)
+		root.segments = append(root.segments, strings.Split(rest, "/"))
+	}
+
+	w := &walker{ctx: ctx, opts: opts, visit: visit, seen: make(map[string]bool)}
+	if opts.AgentIgnore != nil {
+		w.excludeDir, w.excludeRealDir, w.exclude = opts.AgentIgnore.dir, opts.AgentIgnore.realDir, opts.AgentIgnore.current()
+	}
+	for _, root := range roots {
+		info, err := os.Stat(root.dir)
+		real := realPath(root.dir)
+		if err != nil || !info.IsDir() || w.excluded(root.dir, real, true, true) {
+			continue
+		}
+		var matcher ignoreMatcher
+		if opts.Gitignore {
+			matcher = repoIgnoreMatcher(root.dir)
+		}
+		if err := w.walkDir(root, root.dir, real, "", matcher, []fs.FileInfo{info}); err != nil {
+			if errors.Is(err, errStopWalk) {
+				return nil
+			}
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+type walker struct {
+	ctx     context.Context
+	opts    walkOptions
+	visit   func(path string, info fs.FileInfo) error
+	seen    map[string]bool
+	visited int
+
+	// exclude holds the .aagentignore rules of excludeDir, which resolves to
+	// excludeRealDir through symlinks.
+	excludeDir     string
+	excludeRealDir string
+	exclude        ignoreMatcher
+}
+
+// excluded reports whether .aagentignore excludes the path p, or real, what
+// p resolves to through symlinks. Entries are reached through their parents,
+// which the walk has already checked; withParents checks them for the
+// directories a walk starts in. The parents of real are checked whenever it
+// differs from p, as a symlink can lead anywhere in the work directory.
+func (w *walker) excluded(p, real string, isDir bool, withParents bool) bool {
+	if len(w.exclude.files) == 0 {
+		return false
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	if withParents {
+		if excludedWithParents(w.exclude, w.excludeDir, p, isDir) {
+			return true
+		}
+	} else if w.exclude.ignored(p, isDir) {
+		return true
+	}
+	real = underDir(w.excludeDir, w.excludeRealDir, real)
+	return real != p && excludedWithParents(w.exclude, w.excludeDir, real, isDir)
+}
+
+// walkDir walks dir, which resolves to realDir through symlinks.
+func (w *walker) walkDir(root *walkRoot, dir, realDir, rel string, matcher ignoreMatcher, ancestors []fs.FileInfo) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	for _, entry := range entries {
+		w.visited++
+		if w.visited%walkCheckInterval == 0 {
+			if err := w.ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		name := entry.Name()
+		fullPath := filepath.Join(dir, name)
+		realFullPath := filepath.Join(realDir, name)
+		entryRel := path.Join(rel, name)
+
+		var info fs.FileInfo
+		isDir := entry.IsDir()
+		if entry.Type()&fs.ModeSymlink != 0 {
+			if info, err = os.Stat(fullPath); err != nil {
+				continue
+			}
+			isDir = info.IsDir()
+			realFullPath = realPath(fullPath)
+		}
+
+		if isDir {
+			if walkSkippedDirs[name] || !root.mayContain(entryRel) {
+				continue
+			}
+			if w.opts.Gitignore && matcher.ignored(fullPath, true) || w.excluded(fullPath, realFullPath, true, false) {
+				continue
+			}
+			if w.opts.SkipDir != nil && w.opts.SkipDir(fullPath) {
+				continue
+			}
+			if info == nil {
+				if info, err = entry.Info(); err != nil {
+					continue
+				}
+			} else if containsDir(ancestors, info) {
+				continue
+			}
+			next := matcher
+			if w.opts.Gitignore {
+				next = matcher.with(fullPath, gitignoreFile)
+			}
+			if err := w.walkDir(root, fullPath, realFullPath, entryRel, next, append(ancestors, info)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !root.matches(entryRel) || w.seen[fullPath] {
+			continue
+		}
+		if w.opts.Gitignore && matcher.ignored(fullPath, false) || w.excluded(fullPath, realFullPath, false, false) {
+			continue
+		}
+		if info == nil {
+			if info, err = entry.Info(); err != nil {
+				continue
+			}
+		}
+		w.seen[fullPath] = true
+		if err := w.visit(fullPath, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsDir reports whether info is one of dirs, i.e. a symlink loops back
+// into the walk.
+func containsDir(dirs []fs.FileInfo, info fs.FileInfo) bool {
+	for _, dir := range dirs {
+		if os.SameFile(dir, info) {
+			return true
+		}
+	}
+	return false
+}
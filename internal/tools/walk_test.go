@@ -0,0 +1,168 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestWalkGlobPrunesDirectories(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, ".gitignore", "build/\n*.log\n!keep.log\n")
+	createTestFile(t, tempDir, "src/main.go", "package main")
+	createTestFile(t, tempDir, "src/debug.log", "")
+	createTestFile(t, tempDir, "src/keep.log", "")
+	createTestFile(t, tempDir, "src/gen/.gitignore", "*.pb.go\n")
+	createTestFile(t, tempDir, "src/gen/api.pb.go", "package gen")
+	createTestFile(t, tempDir, "src/gen/api.go", "package gen")
+	createTestFile(t, tempDir, "build/out.go", "package out")
+	createTestFile(t, tempDir, "vendor/lib/lib.go", "package lib")
+	createTestFile(t, tempDir, "docs/readme.go", "package docs")
+	createTestFile(t, tempDir, ".git/config.go", "package git")
+
+	walk := func(pattern string) (files, skipChecked []string) {
+		t.Helper()
+		opts := walkOptions{
+			Gitignore: true,
+			SkipDir: func(path string) bool {
+				rel, _ := filepath.Rel(tempDir, path)
+				skipChecked = append(skipChecked, filepath.ToSlash(rel))
+				return rel == "vendor"
+			},
+		}
+		err := walkGlob(context.Background(), []string{filepath.Join(tempDir, pattern)}, opts, func(path string, info fs.FileInfo) error {
+			rel, _ := filepath.Rel(tempDir, path)
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("walkGlob(%q): %v", pattern, err)
+		}
+		sort.Strings(files)
+		sort.Strings(skipChecked)
+		return files, skipChecked
+	}
+
+	files, skipChecked := walk("**/*.{go,log}")
+	if want := []string{"docs/readme.go", "src/gen/api.go", "src/keep.log", "src/main.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+	// Ignored and version control directories are pruned before SkipDir
+	if want := []string{"docs", "src", "src/gen", "vendor"}; !reflect.DeepEqual(skipChecked, want) {
+		t.Errorf("SkipDir called for %q, want %q", skipChecked, want)
+	}
+
+	// Directories that cannot hold a match are never entered
+	files, skipChecked = walk("*/*.go")
+	if want := []string{"docs/readme.go", "src/main.go"}; !reflect.DeepEqual(files, want) {
+		t.Errorf("files = %q, want %q", files, want)
+	}
+	if want := []string{"docs", "src", "vendor"}; !reflect.DeepEqual(skipChecked, want) {
+		t.Errorf("SkipDir called for %q, want %q", skipChecked, want)
+	}
+}
+
+func TestWalkGlobStopsEarlyAndOnCancel(t *testing.T) {
+	tempDir := t.TempDir()
+	for i := 0; i < 2*walkCheckInterval; i++ {
+		createTestFile(t, tempDir, fmt.Sprintf("dir%d/file.txt", i%4), "")
+		createTestFile(t, tempDir, fmt.Sprintf("flat/file%03d.txt", i), "")
+	}
+	pattern := []string{filepath.Join(tempDir, "**", "*.txt")}
+
+	visited := 0
+	err := walkGlob(context.Background(), pattern, walkOptions{}, func(string, fs.FileInfo) error {
+		visited++
+		if visited == 3 {
+			return errStopWalk
+		}
+		return nil
+	})
+	if err != nil || visited != 3 {
+		t.Fatalf("walk stopped after %d files with %v, want 3 and no error", visited, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	visited = 0
+	err = walkGlob(ctx, pattern, walkOptions{}, func(string, fs.FileInfo) error {
+		visited++
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("walkGlob error = %v, want context.Canceled", err)
+	}
+	if visited >= walkCheckInterval {
+		t.Errorf("visited %d files after cancellation, want fewer than %d", visited, walkCheckInterval)
+	}
+
+	for tool, params := range map[Tool]string{
+		NewGlobTool(tempDir):      `{"pattern":"**/*.txt"}`,
+		NewGrepTool(tempDir):      `{"pattern":"x","include":"*.txt"}`,
+		NewFindFilesTool(tempDir): `{"pattern":"**/*.txt"}`,
+	} {
+		if _, err := tool.Execute(ctx, json.RawMessage(params)); !errors.Is(err, context.Canceled) {
+			t.Errorf("%s error = %v, want context.Canceled", tool.Name(), err)
+		}
+	}
+}
+
+func TestWalkGlobSkipsSymlinkLoops(t *testing.T) {
+	tempDir := t.TempDir()
+	createTestFile(t, tempDir, "a/file.txt", "")
+	if err := os.Symlink(tempDir, filepath.Join(tempDir, "a", "loop")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+
+	var files []string
+	err := walkGlob(context.Background(), []string{filepath.Join(tempDir, "**", "*.txt")}, walkOptions{}, func(path string, info fs.FileInfo) error {
+		files = append(files, path)
+		return nil
+	})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("walk found %q with %v, want a/file.txt once", files, err)
+	}
+}
+
+func TestIgnoreMatcher(t *testing.T) {
+	dir := t.TempDir()
+	m := ignoreMatcher{files: []ignoreFile{{dir: dir, rules: parseIgnoreRules(`
+# comment
+*.tmp
+/root-only.txt
+docs/*.html
+cache/
+!important.tmp
+\#literal
+`)}}}
+
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"a.tmp", false, true},
+		{"deep/nested/a.tmp", false, true},
+		{"important.tmp", false, false},
+		{"root-only.txt", false, true},
+		{"sub/root-only.txt", false, false},
+		{"docs/index.html", false, true},
+		{"sub/docs/index.html", false, false},
+		{"cache", true, true},
+		{"cache", false, false},
+		{"#literal", false, true},
+		{"main.go", false, false},
+	}
+	for _, tt := range tests {
+		if got := m.ignored(filepath.Join(dir, tt.path), tt.isDir); got != tt.want {
+			t.Errorf("ignored(%q, dir=%v) = %v, want %v", tt.path, tt.isDir, got, tt.want)
+		}
+	}
+}
@@ -17,6 +17,7 @@ type WriteTool struct {
 type WriteParams struct {
 	Path    string `json:"path"`
 	Content string `json:"content"`
+	Force   bool   `json:"force,omitempty"` // overwrite a file not read in this run
 }
 
 // NewWriteTool creates a new write tool
@@ -31,7 +32,8 @@ func (t *WriteTool) Name() string {
 func (t *WriteTool) Description() string {
 	return `Create a new file or completely overwrite an existing file.
 Use this when you need to create a new file or replace all contents.
-For partial modifications, use the edit tool instead.`
+For partial modifications, use the edit tool instead.
+An existing file must be read first, or force=true passed, before it can be overwritten.`
 }
 
 func (t *WriteTool) Schema() map[string]interface{} {
@@ -46,6 +48,10 @@ func (t *WriteTool) Schema() map[string]interface{} {
 				"type":        "string",
 				"description": "Content to write to the file",
 			},
+			"force": map[string]interface{}{
+				"type":        "boolean",
+				"description": "Overwrite an existing file even though it was not read in this run (default: false)",
+			},
 		},
 		"required": []string{"path", "content"},
 	}
@@ -61,10 +67,25 @@ func (t *WriteTool) Execute(ctx context.Context, params json.RawMessage) (*Resul
 		return &Result{Success: false, Error: "path is required"}, nil
 	}
 
-	// Resolve path
-	path := p.Path
-	if !filepath.IsAbs(path) {
-		path = filepath.Join(t.workDir, path)
+	path := resolveToolPath(t.workDir, p.Path)
+	if agentIgnoreFor(t.workDir).excludes(path) {
+		return agentIgnoreResult(p.Path), nil
+	}
+	if outsideSandbox(t.workDir, path) {
+		return sandboxResult(p.Path), nil
+	}
+
+	// Refuse to replace a file the model has not seen in this run: writing
+	// from memory is how whole files get wiped
+	_, existErr := os.Stat(path)
+	existed := existErr == nil
+	if existed && !p.Force {
+		if record := readRecordFromContext(ctx); record != nil && !record.has(path) {
+			return &Result{
+				Success: false,
+				Error:   fmt.Sprintf("%s already exists and has not been read in this run; read it first, use edit for partial changes, or pass force=true to replace it entirely", p.Path),
+			}, nil
+		}
 	}
 
 	// Create parent directories if needed
@@ -73,10 +94,6 @@ func (t *WriteTool) Execute(ctx context.Context, params json.RawMessage) (*Resul
 		return nil, fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	// Check if file exists (for informational message)
-	_, existErr := os.Stat(path)
-	existed := existErr == nil
-
 	// Write file
 	if err := os.WriteFile(path, []byte(p.Content), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %w", err)
@@ -88,10 +105,30 @@ func (t *WriteTool) Execute(ctx context.Context, params json.RawMessage) (*Resul
 	}
 
 	return &Result{
-		Success: true,
-		Output:  fmt.Sprintf("%s %s (%d bytes)", action, p.Path, len(p.Content)),
+		Success:  true,
+		Output:   fmt.Sprintf("%s %s (%d bytes)", action, p.Path, len(p.Content)),
+		Metadata: readPathsMetadata(path),
 	}, nil
 }
 
+// ReplacedContent returns the current content of the file a call would
+// overwrite, for the approval prompt.
+func (t *WriteTool) ReplacedContent(params json.RawMessage) (string, bool) {
+	var p WriteParams
+	if err := json.Unmarshal(params, &p); err != nil || p.Path == "" {
+		return "", false
+	}
+	data, err := os.ReadFile(resolveToolPath(t.workDir, p.Path))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// MutatedPaths returns the file a call writes, for undo backups.
+func (t *WriteTool) MutatedPaths(params json.RawMessage) []string {
+	return mutatedPathParam(t.workDir, params)
+}
+
 // Ensure WriteTool implements Tool
 var _ Tool = (*WriteTool)(nil)
@@ -0,0 +1,129 @@
+// Package tracing provides optional OpenTelemetry tracing for agent runs,
+// LLM requests, tool calls, HTTP handlers and scheduled jobs.
+//
+// Tracing is off unless an OTLP endpoint is configured. While off, Start
+// returns the caller's context unchanged and a no-op span, so instrumented
+// code paths pay no allocation or export cost.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"github.com/A2gent/brute/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	instrumentationName = "github.com/A2gent/brute"
+	defaultServiceName  = "aagent"
+)
+
+// MaxAttributeLength caps free-text attributes such as tool inputs.
+const MaxAttributeLength = 256
+
+var enabled atomic.Bool
+
+// noopSpan is returned while tracing is off; a span-less context yields the
+// shared non-recording span.
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// Enabled reports whether spans are being recorded and exported.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Init installs an OTLP/HTTP exporter when cfg.Endpoint or the standard
+// OTEL_EXPORTER_OTLP_[TRACES_]ENDPOINT variables are set. The returned
+// shutdown function flushes pending spans; it is a no-op when tracing is off.
+func Init(ctx context.Context, cfg config.TracingConfig) (func(context.Context) error, error) {
+	noop := func(context.Context) error { return nil }
+
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	if endpoint == "" && os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		return noop, nil
+	}
+
+	var opts []otlptracehttp.Option
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return noop, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	serviceName := strings.TrimSpace(cfg.ServiceName)
+	if serviceName == "" {
+		serviceName = defaultServiceName
+	}
+	resource, err := sdkresource.Merge(sdkresource.Default(), sdkresource.NewSchemaless(semconv.ServiceName(serviceName)))
+	if err != nil {
+		resource = sdkresource.Default()
+	}
+
+	sampler := sdktrace.ParentBased(sdktrace.AlwaysSample())
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource),
+		sdktrace.WithSampler(sampler),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	enabled.Store(true)
+
+	return func(ctx context.Context) error {
+		enabled.Store(false)
+		return provider.Shutdown(ctx)
+	}, nil
+}
+
+// Start opens a span as a child of any span already in ctx.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if !enabled.Load() {
+		return ctx, noopSpan
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Fail marks the span as failed without an error value.
+func Fail(span trace.Span, message string) {
+	span.SetStatus(codes.Error, message)
+}
+
+// Truncate shortens s to at most max bytes without splitting a UTF-8 rune.
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	cut := max
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "…"
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/A2gent/brute/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartIsNoopWhenDisabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	shutdown, err := Init(context.Background(), config.TracingConfig{})
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	defer shutdown(context.Background())
+	if Enabled() {
+		t.Fatal("tracing should stay disabled without an endpoint")
+	}
+
+	ctx := context.Background()
+	spanCtx, span := Start(ctx, "noop")
+	if spanCtx != ctx {
+		t.Fatal("disabled Start must return the caller's context")
+	}
+	if span.IsRecording() {
+		t.Fatal("disabled Start must return a non-recording span")
+	}
+	End(span, errors.New("ignored"))
+}
+
+func TestSpansNestAndRecordErrors(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	enabled.Store(true)
+	t.Cleanup(func() {
+		enabled.Store(false)
+		otel.SetTracerProvider(previous)
+	})
+
+	ctx, run := Start(context.Background(), "agent.run")
+	_, tool := Start(ctx, "tool.execute")
+	End(tool, errors.New("boom"))
+	End(run, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	child, parent := spans[0], spans[1]
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("tool span should be a child of the run span")
+	}
+	if child.Status().Code != codes.Error || len(child.Events()) == 0 {
+		t.Fatalf("expected error status and event on tool span, got %+v", child.Status())
+	}
+	if parent.Status().Code == codes.Error {
+		t.Fatal("run span should not be marked failed")
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := Truncate("short", 10); got != "short" {
+		t.Fatalf("Truncate short = %q", got)
+	}
+	if got := Truncate("héllo", 2); got != "h…" {
+		t.Fatalf("Truncate must not split runes, got %q", got)
+	}
+}
@@ -0,0 +1,271 @@
+// Package transcript renders session conversations for people: a Markdown
+// document for exports and a compact text view for terminals.
+package transcript
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/A2gent/brute/internal/session"
+)
+
+// Markdown renders sess as a Markdown document. Tool calls are listed with
+// their JSON input, and tool results follow in fenced blocks.
+func Markdown(sess *session.Session) string {
+	var b strings.Builder
+	title := strings.TrimSpace(sess.Title)
+	if title == "" {
+		title = "Session " + sess.ID
+	}
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	fmt.Fprintf(&b, "- Session: `%s`\n", sess.ID)
+	fmt.Fprintf(&b, "- Agent: %s\n", sess.AgentID)
+	fmt.Fprintf(&b, "- Status: %s\n", sess.Status)
+	fmt.Fprintf(&b, "- Created: %s\n", sess.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+
+	names := toolNamesByCallID(sess)
+	for _, msg := range sess.Messages {
+		switch msg.Role {
+		case "tool":
+			for _, result := range msg.ToolResults {
+				label := "Result"
+				if result.IsError {
+					label = "Error"
+				}
+				details := "`" + resultName(result, names) + "`"
+				if stats := ResultStats(result); stats != "" {
+					details += ", " + stats
+				}
+				fmt.Fprintf(&b, "\n**%s** (%s):\n\n", label, details)
+				writeFenced(&b, "text", result.Content)
+			}
+		default:
+			fmt.Fprintf(&b, "\n## %s\n", roleTitle(msg.Role))
+			if content := strings.TrimSpace(msg.Content); content != "" {
+				fmt.Fprintf(&b, "\n%s\n", content)
+			}
+			for _, image := range msg.Images {
+				fmt.Fprintf(&b, "\n_[image: %s]_\n", imageLabel(image))
+			}
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(&b, "\n**Tool call:** `%s`\n\n", call.Name)
+				writeFenced(&b, "json", indentJSON(call.Input))
+			}
+		}
+	}
+	return b.String()
+}
+
+// Text renders sess for a terminal that is width columns wide (no wrapping
+// when width <= 0). Turns are prefixed with their role, and each tool call
+// collapses to one line with its input preview and outcome.
+func Text(sess *session.Session, width int) string {
+	var b strings.Builder
+	title := strings.TrimSpace(sess.Title)
+	if title == "" {
+		title = "(no title)"
+	}
+	fmt.Fprintf(&b, "%s\n", clip(title, width))
+	b.WriteString(clip(fmt.Sprintf("%s · %s · %s · %s", sess.ID, sess.AgentID, sess.Status, sess.CreatedAt.Format("2006-01-02 15:04")), width) + "\n")
+
+	results := make(map[string]session.ToolResult)
+	for _, msg := range sess.Messages {
+		for _, result := range msg.ToolResults {
+			results[result.ToolCallID] = result
+		}
+	}
+	calls := make(map[string]struct{})
+
+	for _, msg := range sess.Messages {
+		if msg.Role == "tool" {
+			// Results are shown next to their calls; only list orphans here.
+			for _, result := range msg.ToolResults {
+				if _, ok := calls[result.ToolCallID]; !ok {
+					b.WriteString(clip("  ▸ "+result.Name+" "+outcome(&result), width) + "\n")
+				}
+			}
+			continue
+		}
+
+		content := strings.TrimSpace(msg.Content)
+		for _, image := range msg.Images {
+			content = strings.TrimSpace(content + "\n[image: " + imageLabel(image) + "]")
+		}
+		if content != "" || len(msg.ToolCalls) > 0 {
+			b.WriteString("\n")
+		}
+		if content != "" {
+			prefix := msg.Role + ": "
+			b.WriteString(wrap(content, prefix, strings.Repeat(" ", utf8.RuneCountInString(prefix)), width))
+		}
+		for _, call := range msg.ToolCalls {
+			calls[call.ID] = struct{}{}
+			line := fmt.Sprintf("  ▸ %s(%s)", call.Name, agent.ToolInputPreview(string(call.Input)))
+			if result, ok := results[call.ID]; ok {
+				line += " " + outcome(&result)
+			}
+			b.WriteString(clip(line, width) + "\n")
+		}
+	}
+	return b.String()
+}
+
+func toolNamesByCallID(sess *session.Session) map[string]string {
+	names := make(map[string]string)
+	for _, msg := range sess.Messages {
+		for _, call := range msg.ToolCalls {
+			names[call.ID] = call.Name
+		}
+	}
+	return names
+}
+
+func resultName(result session.ToolResult, names map[string]string) string {
+	if result.Name != "" {
+		return result.Name
+	}
+	if name, ok := names[result.ToolCallID]; ok {
+		return name
+	}
+	return "tool"
+}
+
+// ResultStats describes when a tool call started, how long it ran and, when
+// its output was truncated for the model, how large it was, as in
+// "started 15:04:05, took 1.2s, 120000 bytes before truncation". Results
+// recorded before these fields existed yield "".
+func ResultStats(result session.ToolResult) string {
+	var parts []string
+	if !result.StartedAt.IsZero() {
+		parts = append(parts, "started "+result.StartedAt.Format("15:04:05"))
+		parts = append(parts, "took "+formatDuration(time.Duration(result.DurationMs)*time.Millisecond))
+	}
+	if result.OutputBytes > len(result.Content) {
+		parts = append(parts, fmt.Sprintf("%d bytes before truncation", result.OutputBytes))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatDuration renders d as "850ms" below a second and "1.2s" above.
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return fmt.Sprintf("%dms", d.Milliseconds())
+	}
+	return fmt.Sprintf("%.1fs", d.Seconds())
+}
+
+// outcome summarises a tool result as "ok (12 lines)" or "error: <first line>".
+func outcome(result *session.ToolResult) string {
+	content := strings.TrimSpace(result.Content)
+	if result.IsError {
+		first, _, _ := strings.Cut(content, "\n")
+		return "error: " + first
+	}
+	if content == "" {
+		return "ok"
+	}
+	lines := strings.Count(content, "\n") + 1
+	if lines == 1 {
+		return "ok (1 line)"
+	}
+	return fmt.Sprintf("ok (%d lines)", lines)
+}
+
+func roleTitle(role string) string {
+	switch role {
+	case "user":
+		return "User"
+	case "assistant":
+		return "Assistant"
+	case "system":
+		return "System"
+	}
+	return role
+}
+
+func imageLabel(image session.ImageAttachment) string {
+	switch {
+	case image.Name != "":
+		return image.Name
+	case image.URL != "":
+		return image.URL
+	case image.MediaType != "":
+		return image.MediaType
+	}
+	return "attachment"
+}
+
+func indentJSON(raw json.RawMessage) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return out.String()
+}
+
+// writeFenced writes content as a fenced code block whose fence is longer
+// than any backtick run inside it.
+func writeFenced(b *strings.Builder, lang, content string) {
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			if run > longest {
+				longest = run
+			}
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	fmt.Fprintf(b, "%s%s\n%s\n%s\n", fence, lang, strings.TrimRight(content, "\n"), fence)
+}
+
+// clip shortens s to width runes.
+func clip(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:width-1]) + "…"
+}
+
+// wrap word-wraps text to width, starting the first line with prefix and
+// the following ones with indent.
+func wrap(text, prefix, indent string, width int) string {
+	var b strings.Builder
+	lead := prefix
+	for _, paragraph := range strings.Split(text, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			b.WriteString(strings.TrimRight(lead, " ") + "\n")
+			lead = indent
+			continue
+		}
+		line := lead
+		lineLen := utf8.RuneCountInString(lead)
+		startLen := lineLen
+		for _, word := range words {
+			wordLen := utf8.RuneCountInString(word)
+			if width > 0 && lineLen > startLen && lineLen+1+wordLen > width {
+				b.WriteString(line + "\n")
+				line, lineLen = indent, utf8.RuneCountInString(indent)
+				startLen = lineLen
+			}
+			if lineLen > startLen {
+				line += " "
+				lineLen++
+			}
+			line += word
+			lineLen += wordLen
+		}
+		b.WriteString(line + "\n")
+		lead = indent
+	}
+	return b.String()
+}
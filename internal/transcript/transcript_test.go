@@ -0,0 +1,81 @@
+package transcript
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+)
+
+func testSession() *session.Session {
+	sess := session.New("build")
+	sess.Title = "Find config"
+	sess.AddMessage(session.Message{Role: "user", Content: "Where is the config file loaded from?"})
+	sess.AddMessage(session.Message{Role: "assistant", Content: "Searching.", ToolCalls: []session.ToolCall{
+		{ID: "c1", Name: "grep", Input: []byte(`{"pattern":"config.Load"}`)},
+		{ID: "c2", Name: "read", Input: []byte(`{"path":"missing.go"}`)},
+	}})
+	sess.AddMessage(session.Message{Role: "tool", ToolResults: []session.ToolResult{
+		{ToolCallID: "c1", Name: "grep", Content: "main.go:12\nserver.go:40"},
+		{ToolCallID: "c2", Name: "read", Content: "file not found\nstack", IsError: true,
+			StartedAt: time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), DurationMs: 1500, OutputBytes: 9000},
+	}})
+	sess.AddMessage(session.Message{Role: "assistant", Content: "It is loaded in main.go with ```config.Load```."})
+	return sess
+}
+
+func TestTextCollapsesToolCalls(t *testing.T) {
+	out := Text(testSession(), 60)
+
+	for _, want := range []string{
+		"user: Where is the config file loaded from?",
+		"  ▸ grep(pattern=config.Load) ok (2 lines)",
+		"  ▸ read(path=missing.go) error: file not found",
+		"assistant: It is loaded in main.go",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "server.go:40") {
+		t.Errorf("tool output should be collapsed:\n%s", out)
+	}
+	for _, line := range strings.Split(out, "\n") {
+		if n := len([]rune(line)); n > 60 {
+			t.Errorf("line exceeds width (%d): %q", n, line)
+		}
+	}
+}
+
+func TestWrapIndentsContinuationLines(t *testing.T) {
+	got := wrap("one two three four", "user: ", "      ", 16)
+	want := "user: one two\n      three four\n"
+	if got != want {
+		t.Fatalf("wrap = %q, want %q", got, want)
+	}
+}
+
+func TestMarkdownIncludesToolCallsAndResults(t *testing.T) {
+	out := Markdown(testSession())
+
+	for _, want := range []string{
+		"# Find config",
+		"## User\n\nWhere is the config file loaded from?",
+		"**Tool call:** `grep`\n\n```json\n{\n  \"pattern\": \"config.Load\"\n}\n```",
+		"**Result** (`grep`):\n\n```text\nmain.go:12\nserver.go:40\n```",
+		"**Error** (`read`, started 10:00:00, took 1.5s, 9000 bytes before truncation):",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteFencedOutgrowsBackticks(t *testing.T) {
+	var b strings.Builder
+	writeFenced(&b, "text", "a ```` b")
+	if !strings.HasPrefix(b.String(), "`````text\n") {
+		t.Fatalf("expected a five-backtick fence, got %q", b.String())
+	}
+}
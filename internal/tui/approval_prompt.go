@@ -0,0 +1,322 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/A2gent/brute/internal/tools"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// approvalArmDelay ignores keys pressed just after the prompt appears,
+	// so that typing ahead in the input box cannot answer it.
+	approvalArmDelay = 400 * time.Millisecond
+	// approvalPreviewLines bounds the argument lines shown in the prompt.
+	approvalPreviewLines = 12
+)
+
+var (
+	approvalHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FF9800"))
+
+	approvalKeyStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#2196F3")).
+				Bold(true)
+)
+
+// approvalRequestMsg asks the user to approve a tool call of a running
+// agent. The answer goes to reply, which is buffered so that answering a
+// run that has already ended never blocks.
+type approvalRequestMsg struct {
+	sessionID string
+	request   tools.ApprovalRequest
+	reply     chan tools.ApprovalDecision
+	requests  <-chan approvalRequestMsg
+	done      <-chan struct{}
+}
+
+// approvalGrants records the tools the user chose to always allow, per
+// session. It is shared with the agent goroutines and lives for the TUI.
+type approvalGrants struct {
+	mu        sync.Mutex
+	bySession map[string]map[string]bool
+}
+
+func newApprovalGrants() *approvalGrants {
+	return &approvalGrants{bySession: make(map[string]map[string]bool)}
+}
+
+func (g *approvalGrants) allowed(sessionID, tool string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.bySession[sessionID][tool]
+}
+
+func (g *approvalGrants) allow(sessionID, tool string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.bySession[sessionID] == nil {
+		g.bySession[sessionID] = make(map[string]bool)
+	}
+	g.bySession[sessionID][tool] = true
+}
+
+// approverFor returns the approver of one run: it asks the UI through
+// requests until done is closed, skipping tools the user always allows.
+func approverFor(grants *approvalGrants, sessionID string, requests chan<- approvalRequestMsg, done <-chan struct{}) tools.Approver {
+	return func(ctx context.Context, req tools.ApprovalRequest) (tools.ApprovalDecision, error) {
+		if grants.allowed(sessionID, req.Tool) {
+			return tools.ApprovalDecision{Approved: true}, nil
+		}
+		reply := make(chan tools.ApprovalDecision, 1)
+		select {
+		case requests <- approvalRequestMsg{sessionID: sessionID, request: req, reply: reply}:
+		case <-done:
+			return tools.ApprovalDecision{}, context.Canceled
+		case <-ctx.Done():
+			return tools.ApprovalDecision{}, ctx.Err()
+		}
+		select {
+		case decision := <-reply:
+			if decision.Approved && decision.Always {
+				grants.allow(sessionID, req.Tool)
+			}
+			return decision, nil
+		case <-ctx.Done():
+			return tools.ApprovalDecision{}, ctx.Err()
+		}
+	}
+}
+
+// waitForApproval delivers the next approval request of a run; it stops
+// once the run has finished.
+func waitForApproval(requests <-chan approvalRequestMsg, done <-chan struct{}) tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case req := <-requests:
+			req.requests = requests
+			req.done = done
+			return req
+		case <-done:
+			return nil
+		}
+	}
+}
+
+// showApproval puts a request in front of the user, or answers it at once
+// when an earlier "always" already covers the tool.
+func (m Model) showApproval(msg approvalRequestMsg) (Model, tea.Cmd) {
+	next := waitForApproval(msg.requests, msg.done)
+	if m.approvalGrants.allowed(msg.sessionID, msg.request.Tool) {
+		msg.reply <- tools.ApprovalDecision{Approved: true}
+		return m, next
+	}
+	m.pendingApproval = &msg
+	m.approvalShownAt = time.Now()
+	m.approvalEditing = false
+	m.viewport.Height = m.viewportHeight()
+	return m, next
+}
+
+// answerApproval sends the decision and hides the prompt.
+func (m *Model) answerApproval(decision tools.ApprovalDecision) {
+	if m.pendingApproval == nil {
+		return
+	}
+	m.pendingApproval.reply <- decision
+	verdict := "approved"
+	switch {
+	case !decision.Approved:
+		verdict = "denied"
+	case decision.Always:
+		verdict = "always allowed for this session"
+	case len(decision.Params) > 0:
+		verdict = "approved with edited arguments"
+	}
+	m.addNotice("system", fmt.Sprintf("%s call %s.", m.pendingApproval.request.Tool, verdict))
+	m.dismissApproval()
+}
+
+// dismissApproval hides the prompt, restoring the input draft if the
+// arguments were being edited.
+func (m *Model) dismissApproval() {
+	if m.approvalEditing {
+		m.textarea.SetValue(m.approvalDraft)
+	}
+	m.pendingApproval = nil
+	m.approvalEditing = false
+	m.approvalDraft = ""
+	m.viewport.Height = m.viewportHeight()
+}
+
+// updateApproval handles keys while the approval prompt is shown. It
+// reports false for keys the rest of Update should handle: Ctrl+C, and Esc
+// outside of editing, which interrupt the run as usual.
+func (m Model) updateApproval(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		return m, nil, false
+	case tea.KeyPgUp, tea.KeyPgDown:
+		var cmd tea.Cmd
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd, true
+	}
+
+	if m.approvalEditing {
+		switch {
+		case msg.Type == tea.KeyEsc:
+			m.textarea.SetValue(m.approvalDraft)
+			m.approvalEditing = false
+			m.approvalDraft = ""
+			return m, nil, true
+		case msg.Type == tea.KeyEnter && !msg.Alt:
+			edited := strings.TrimSpace(m.textarea.Value())
+			if !json.Valid([]byte(edited)) {
+				m.addNotice("error", "The edited arguments are not valid JSON.")
+				return m, nil, true
+			}
+			m.answerApproval(tools.ApprovalDecision{Approved: true, Params: json.RawMessage(edited)})
+			return m, nil, true
+		}
+		var cmd tea.Cmd
+		m.textarea, cmd = m.textarea.Update(msg)
+		return m, cmd, true
+	}
+
+	if msg.Type == tea.KeyEsc {
+		return m, nil, false
+	}
+	if msg.Type != tea.KeyRunes || len(msg.Runes) != 1 || time.Since(m.approvalShownAt) < approvalArmDelay {
+		return m, nil, true
+	}
+	switch msg.Runes[0] {
+	case 'y', 'Y':
+		m.answerApproval(tools.ApprovalDecision{Approved: true})
+	case 'n', 'N':
+		m.answerApproval(tools.ApprovalDecision{})
+	case 'a', 'A':
+		m.answerApproval(tools.ApprovalDecision{Approved: true, Always: true})
+	case 'e', 'E':
+		m.approvalDraft = m.textarea.Value()
+		m.approvalEditing = true
+		m.textarea.SetValue(prettyJSON(m.pendingApproval.request.Params))
+	}
+	return m, nil, true
+}
+
+// approvalPromptHeight returns the number of lines renderApprovalPrompt
+// produces.
+func (m Model) approvalPromptHeight() int {
+	if m.pendingApproval == nil {
+		return 0
+	}
+	return lipgloss.Height(m.renderApprovalPrompt())
+}
+
+// renderApprovalPrompt shows the tool, its arguments and the answer keys.
+func (m Model) renderApprovalPrompt() string {
+	if m.pendingApproval == nil {
+		return ""
+	}
+	req := m.pendingApproval.request
+	lines := []string{approvalHeaderStyle.Render("⚠ Run " + req.Tool + "?")}
+	for _, line := range approvalPreview(req, m.width-2) {
+		lines = append(lines, "  "+line)
+	}
+	if m.approvalEditing {
+		lines = append(lines, toolPanelDimStyle.Render("  Editing arguments below · enter: run with them · esc: back"))
+	} else {
+		key := func(k, label string) string {
+			return approvalKeyStyle.Render("["+k+"]") + " " + label
+		}
+		lines = append(lines, "  "+strings.Join([]string{
+			key("y", "approve"),
+			key("n", "deny"),
+			key("a", "always allow "+req.Tool+" this session"),
+			key("e", "edit arguments"),
+		}, "  "))
+	}
+	separator := approvalHeaderStyle.Render(strings.Repeat("─", m.width))
+	return separator + "\n" + strings.Join(lines, "\n") + "\n" + separator
+}
+
+// approvalPreview renders the arguments of a call for review: the command
+// for bash, a diff for edit and write, which shows the content an overwrite
+// replaces, and indented JSON otherwise. Lines are cut to width.
+func approvalPreview(req tools.ApprovalRequest, width int) []string {
+	var args struct {
+		Command   string `json:"command"`
+		WorkDir   string `json:"workdir"`
+		Path      string `json:"path"`
+		OldString string `json:"old_string"`
+		NewString string `json:"new_string"`
+		Content   string `json:"content"`
+	}
+	_ = json.Unmarshal(req.Params, &args)
+
+	var lines []string
+	switch {
+	case req.Tool == "bash" && args.Command != "":
+		for i, line := range strings.Split(args.Command, "\n") {
+			prefix := "  "
+			if i == 0 {
+				prefix = "$ "
+			}
+			lines = append(lines, toolStyle.Render(truncateLine(prefix+line, width)))
+		}
+		if args.WorkDir != "" {
+			lines = append(lines, toolPanelDimStyle.Render(truncateLine("in "+args.WorkDir, width)))
+		}
+	case req.Tool == "edit" && args.Path != "":
+		header := truncateLine(args.Path, width-4)
+		lines = append(lines, diffHeaderStyle.Render("--- "+header), diffHeaderStyle.Render("+++ "+header))
+		lines = append(lines, diffLines("-", args.OldString, width)...)
+		lines = append(lines, diffLines("+", args.NewString, width)...)
+	case req.Tool == "write" && args.Path != "":
+		header := truncateLine(args.Path, width-4)
+		if req.Overwrite {
+			lines = append(lines, diffHeaderStyle.Render("--- "+header))
+		}
+		lines = append(lines, diffHeaderStyle.Render("+++ "+header))
+		if req.Overwrite && req.Replaced != "" {
+			lines = append(lines, diffLines("-", req.Replaced, width)...)
+		}
+		lines = append(lines, diffLines("+", args.Content, width)...)
+	default:
+		for _, line := range strings.Split(prettyJSON(req.Params), "\n") {
+			lines = append(lines, toolResultStyle.Render(truncateLine(line, width)))
+		}
+	}
+	if len(lines) > approvalPreviewLines {
+		more := len(lines) - approvalPreviewLines
+		lines = append(lines[:approvalPreviewLines:approvalPreviewLines], toolPanelDimStyle.Render(fmt.Sprintf("… %d more lines (e to see all)", more)))
+	}
+	return lines
+}
+
+// diffLines renders text as added or removed diff lines cut to width.
+func diffLines(sign, text string, width int) []string {
+	split := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	lines := make([]string, 0, len(split))
+	for _, line := range split {
+		lines = append(lines, renderDiffLine(truncateLine(sign+line, width)))
+	}
+	return lines
+}
+
+// prettyJSON indents raw JSON, returning it unchanged when it is invalid.
+func prettyJSON(raw json.RawMessage) string {
+	var out bytes.Buffer
+	if err := json.Indent(&out, raw, "", "  "); err != nil {
+		return string(raw)
+	}
+	return out.String()
+}
@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/tools"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestApproverRemembersAlwaysPerSession(t *testing.T) {
+	grants := newApprovalGrants()
+	requests := make(chan approvalRequestMsg)
+	done := make(chan struct{})
+	defer close(done)
+	approve := approverFor(grants, "s1", requests, done)
+
+	go func() {
+		req := <-requests
+		req.reply <- tools.ApprovalDecision{Approved: true, Always: true}
+	}()
+	decision, err := approve(context.Background(), tools.ApprovalRequest{Tool: "bash", Params: json.RawMessage(`{}`)})
+	if err != nil || !decision.Approved {
+		t.Fatalf("decision = %+v, err = %v", decision, err)
+	}
+
+	// Nobody reads requests any more: an ask would block, so the grant must apply.
+	if decision, err := approve(context.Background(), tools.ApprovalRequest{Tool: "bash"}); err != nil || !decision.Approved {
+		t.Fatalf("always-allowed tool was asked again: %+v, %v", decision, err)
+	}
+	if grants.allowed("s2", "bash") {
+		t.Fatal("grant leaked into another session")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := approve(ctx, tools.ApprovalRequest{Tool: "write"}); err == nil {
+		t.Fatal("expected a cancelled run to stop waiting for approval")
+	}
+}
+
+func TestApprovalPreview(t *testing.T) {
+	edit := tools.ApprovalRequest{Tool: "edit", Params: json.RawMessage(`{"path":"main.go","old_string":"a := 1","new_string":"a := 2"}`)}
+	got := ansi.Strip(strings.Join(approvalPreview(edit, 80), "\n"))
+	for _, want := range []string{"--- main.go", "−a := 1", "+a := 2"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("edit preview missing %q:\n%s", want, got)
+		}
+	}
+
+	write := tools.ApprovalRequest{Tool: "write", Params: json.RawMessage(`{"path":"main.go","content":"b := 2"}`), Overwrite: true, Replaced: "a := 1\n"}
+	got = ansi.Strip(strings.Join(approvalPreview(write, 80), "\n"))
+	for _, want := range []string{"--- main.go", "+++ main.go", "−a := 1", "+b := 2"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("write preview missing %q:\n%s", want, got)
+		}
+	}
+
+	bash := tools.ApprovalRequest{Tool: "bash", Params: json.RawMessage(`{"command":"go test ./..."}`)}
+	if got := ansi.Strip(strings.Join(approvalPreview(bash, 80), "\n")); got != "$ go test ./..." {
+		t.Fatalf("bash preview = %q", got)
+	}
+}
@@ -0,0 +1,370 @@
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+var (
+	mdHeadingStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#7D56F4")).
+			Bold(true)
+
+	mdBoldStyle   = lipgloss.NewStyle().Bold(true)
+	mdItalicStyle = lipgloss.NewStyle().Italic(true)
+
+	mdInlineCodeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#E5C07B")).
+				Background(lipgloss.Color("#2a2a2a"))
+
+	mdLinkStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#61AFEF")).
+			Underline(true)
+
+	mdQuoteStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#888888")).
+			Italic(true)
+
+	mdRuleStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#444444"))
+
+	mdCodeGutterStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#444444"))
+
+	mdCodeLangStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("#666666")).
+			Italic(true)
+
+	codeKeywordStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#C678DD"))
+	codeStringStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#98C379"))
+	codeNumberStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("#D19A66"))
+	codeCommentStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#5C6370")).Italic(true)
+	codePlainStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("#ABB2BF"))
+)
+
+var (
+	mdHeadingRe    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdBulletRe     = regexp.MustCompile(`^(\s*)([-*+])\s+(.*)$`)
+	mdNumberedRe   = regexp.MustCompile(`^(\s*)(\d+[.)])\s+(.*)$`)
+	mdRuleRe       = regexp.MustCompile(`^\s*([-*_])(\s*([-*_]))(\s*([-*_]))+\s*$`)
+	mdInlineCodeRe = regexp.MustCompile("`([^`]+)`")
+	mdBoldRe       = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalicRe     = regexp.MustCompile(`(^|[^*\w])\*([^*\s][^*]*)\*|(^|[^_\w])_([^_\s][^_]*)_`)
+	mdLinkRe       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+)
+
+// markdownEnabled reports whether assistant messages are rendered as
+// markdown; terminals without color get the plain text.
+func markdownEnabled() bool {
+	return lipgloss.ColorProfile() != termenv.Ascii
+}
+
+// renderMarkdown formats markdown for the terminal at the given width:
+// headings, emphasis, inline code, links, lists, quotes, rules and fenced
+// code blocks with syntax highlighting. Tables and other constructs pass
+// through as wrapped text.
+func renderMarkdown(text string, width int) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence, lang, ok := openingFence(trimmed); ok {
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			out = append(out, renderCodeBlock(code, lang, width)...)
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			out = append(out, "")
+		case mdRuleRe.MatchString(trimmed):
+			out = append(out, mdRuleStyle.Render(strings.Repeat("─", width)))
+		case mdHeadingRe.MatchString(trimmed):
+			heading := mdHeadingRe.FindStringSubmatch(trimmed)[2]
+			for _, l := range strings.Split(wrapText(heading, width), "\n") {
+				out = append(out, mdHeadingStyle.Render(l))
+			}
+		case strings.HasPrefix(trimmed, ">"):
+			quote := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			for _, l := range strings.Split(wrapText(quote, width-2), "\n") {
+				out = append(out, mdQuoteStyle.Render("│ "+l))
+			}
+		case mdBulletRe.MatchString(line):
+			m := mdBulletRe.FindStringSubmatch(line)
+			out = append(out, renderListItem(m[1], "•", m[3], width)...)
+		case mdNumberedRe.MatchString(line):
+			m := mdNumberedRe.FindStringSubmatch(line)
+			out = append(out, renderListItem(m[1], m[2], m[3], width)...)
+		default:
+			for _, l := range strings.Split(wrapText(line, width), "\n") {
+				out = append(out, renderInline(l))
+			}
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// openingFence reports whether line opens a fenced code block, returning
+// the fence to close it and the info string's language.
+func openingFence(line string) (fence, lang string, ok bool) {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(line, marker) {
+			fields := strings.Fields(strings.TrimPrefix(line, marker))
+			if len(fields) > 0 {
+				lang = strings.ToLower(fields[0])
+			}
+			return marker, lang, true
+		}
+	}
+	return "", "", false
+}
+
+// renderListItem renders a list item with a hanging indent. Nested items
+// keep their indentation.
+func renderListItem(indent, marker, text string, width int) []string {
+	prefix := indent + marker + " "
+	hang := strings.Repeat(" ", lipgloss.Width(prefix))
+	wrapped := strings.Split(wrapText(text, width-len(hang)), "\n")
+	out := make([]string, len(wrapped))
+	for i, l := range wrapped {
+		if i == 0 {
+			out[i] = prefix + renderInline(l)
+		} else {
+			out[i] = hang + renderInline(l)
+		}
+	}
+	return out
+}
+
+// renderInline styles inline code, links, bold and italic text of one line.
+// Code spans are set aside first so their contents stay literal.
+func renderInline(line string) string {
+	var spans []string
+	placeholder := func(i int) string { return fmt.Sprintf("\x00%d\x00", i) }
+	line = mdInlineCodeRe.ReplaceAllStringFunc(line, func(match string) string {
+		spans = append(spans, mdInlineCodeStyle.Render(mdInlineCodeRe.FindStringSubmatch(match)[1]))
+		return placeholder(len(spans) - 1)
+	})
+	line = mdLinkRe.ReplaceAllStringFunc(line, func(match string) string {
+		m := mdLinkRe.FindStringSubmatch(match)
+		if m[1] == m[2] {
+			return mdLinkStyle.Render(m[2])
+		}
+		return m[1] + " " + mdLinkStyle.Render("("+m[2]+")")
+	})
+	line = mdBoldRe.ReplaceAllStringFunc(line, func(match string) string {
+		m := mdBoldRe.FindStringSubmatch(match)
+		return mdBoldStyle.Render(m[1] + m[2])
+	})
+	line = mdItalicRe.ReplaceAllStringFunc(line, func(match string) string {
+		m := mdItalicRe.FindStringSubmatch(match)
+		if m[2] != "" {
+			return m[1] + mdItalicStyle.Render(m[2])
+		}
+		return m[3] + mdItalicStyle.Render(m[4])
+	})
+	for i, span := range spans {
+		line = strings.Replace(line, placeholder(i), span, 1)
+	}
+	return line
+}
+
+// renderCodeBlock draws a fenced block behind a gutter, highlighted for its
+// language. Long lines are wrapped rather than cut so nothing is lost.
+func renderCodeBlock(code []string, lang string, width int) []string {
+	out := make([]string, 0, len(code)+1)
+	if lang != "" {
+		out = append(out, mdCodeLangStyle.Render("  "+lang))
+	}
+	diff := lang == "diff" || lang == "patch" || (lang == "" && looksLikeDiff(strings.Join(code, "\n")))
+	gutter := mdCodeGutterStyle.Render("  │ ")
+	inner := width - 4
+	if inner < 10 {
+		inner = 10
+	}
+	for _, line := range code {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		for _, chunk := range hardWrap(line, inner) {
+			if diff {
+				out = append(out, gutter+renderDiffLine(chunk))
+			} else {
+				out = append(out, gutter+highlightCode(chunk, lang))
+			}
+		}
+	}
+	return out
+}
+
+// hardWrap splits line into pieces of at most width runes.
+func hardWrap(line string, width int) []string {
+	runes := []rune(line)
+	if len(runes) <= width {
+		return []string{line}
+	}
+	var pieces []string
+	for len(runes) > width {
+		pieces = append(pieces, string(runes[:width]))
+		runes = runes[width:]
+	}
+	return append(pieces, string(runes))
+}
+
+// looksLikeDiff reports whether text is a unified diff.
+func looksLikeDiff(text string) bool {
+	var hunks, headers, changes int
+	for _, line := range strings.Split(text, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "), strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "+++ "):
+			headers++
+		case strings.HasPrefix(line, "@@"):
+			hunks++
+		case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+			changes++
+		}
+	}
+	return hunks > 0 && changes > 0 || headers >= 2 && changes > 0
+}
+
+// renderDiffLine colors one line of a unified diff.
+func renderDiffLine(line string) string {
+	switch {
+	case strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "), strings.HasPrefix(line, "diff "), strings.HasPrefix(line, "@@"):
+		return diffHeaderStyle.Render(line)
+	case strings.HasPrefix(line, "+"):
+		return diffAddStyle.Render(line)
+	case strings.HasPrefix(line, "-"):
+		return diffRemoveStyle.Render("−" + line[1:])
+	default:
+		return diffContextStyle.Render(line)
+	}
+}
+
+// codeKeywords lists the keywords highlighted per language; languages not
+// listed use the C-family set.
+var codeKeywords = map[string][]string{
+	"go":     {"break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto", "if", "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var", "nil", "true", "false"},
+	"python": {"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif", "else", "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "not", "or", "pass", "raise", "return", "try", "while", "with", "yield", "None", "True", "False", "self"},
+	"js":     {"async", "await", "break", "case", "catch", "class", "const", "continue", "default", "delete", "do", "else", "export", "extends", "finally", "for", "function", "if", "import", "in", "instanceof", "interface", "let", "new", "of", "return", "switch", "this", "throw", "try", "type", "typeof", "var", "while", "yield", "null", "undefined", "true", "false"},
+	"shell":  {"if", "then", "else", "elif", "fi", "for", "while", "until", "do", "done", "case", "esac", "in", "function", "return", "export", "local", "echo", "cd", "set"},
+	"rust":   {"as", "async", "await", "break", "const", "continue", "crate", "else", "enum", "fn", "for", "if", "impl", "in", "let", "loop", "match", "mod", "move", "mut", "pub", "ref", "return", "self", "Self", "static", "struct", "trait", "type", "unsafe", "use", "where", "while", "true", "false"},
+	"c":      {"auto", "break", "case", "catch", "char", "class", "const", "continue", "default", "do", "double", "else", "enum", "extern", "final", "float", "for", "if", "import", "int", "long", "new", "null", "private", "protected", "public", "return", "short", "static", "struct", "switch", "this", "throw", "try", "typedef", "void", "while", "true", "false"},
+}
+
+var codeLanguageAliases = map[string]string{
+	"golang": "go", "py": "python", "python3": "python",
+	"javascript": "js", "ts": "js", "typescript": "js", "jsx": "js", "tsx": "js", "json": "js",
+	"sh": "shell", "bash": "shell", "zsh": "shell", "console": "shell",
+	"rs": "rust",
+}
+
+// lineCommentPrefixes returns the line comment markers of a language.
+func lineCommentPrefixes(lang string) []string {
+	switch lang {
+	case "python", "shell", "ruby", "yaml", "yml", "toml":
+		return []string{"#"}
+	case "sql", "lua":
+		return []string{"--"}
+	default:
+		return []string{"//", "#"}
+	}
+}
+
+// highlightCode colors keywords, strings, numbers and line comments of one
+// line of code. It is a tokenizer, not a parser: multi-line strings and
+// block comments are colored line by line.
+func highlightCode(line, lang string) string {
+	if alias, ok := codeLanguageAliases[lang]; ok {
+		lang = alias
+	}
+	keywords := codeKeywords[lang]
+	if keywords == nil {
+		keywords = codeKeywords["c"]
+	}
+	comments := lineCommentPrefixes(lang)
+
+	var sb strings.Builder
+	var plain []rune
+	emit := func(style lipgloss.Style, text string) {
+		if len(plain) > 0 {
+			sb.WriteString(codePlainStyle.Render(string(plain)))
+			plain = plain[:0]
+		}
+		sb.WriteString(style.Render(text))
+	}
+
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if isLineComment(runes, i, comments) {
+			emit(codeCommentStyle, string(runes[i:]))
+			break
+		}
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			j := i + 1
+			for j < len(runes) && runes[j] != r {
+				if runes[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(runes) {
+				j = len(runes) - 1
+			}
+			emit(codeStringStyle, string(runes[i:j+1]))
+			i = j + 1
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.' || runes[j] == 'x' || runes[j] == '_') {
+				j++
+			}
+			emit(codeNumberStyle, string(runes[i:j]))
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || runes[j] == '_') {
+				j++
+			}
+			if word := string(runes[i:j]); slices.Contains(keywords, word) {
+				emit(codeKeywordStyle, word)
+			} else {
+				plain = append(plain, runes[i:j]...)
+			}
+			i = j
+		default:
+			plain = append(plain, r)
+			i++
+		}
+	}
+	if len(plain) > 0 {
+		sb.WriteString(codePlainStyle.Render(string(plain)))
+	}
+	return sb.String()
+}
+
+// isLineComment reports whether a line comment starts at runes[i]. A "#"
+// only counts at the start of a word, so "a#b" stays code.
+func isLineComment(runes []rune, i int, prefixes []string) bool {
+	rest := string(runes[i:])
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(rest, prefix) && (prefix != "#" || i == 0 || unicode.IsSpace(runes[i-1])) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	input := strings.Join([]string{
+		"# Plan",
+		"Use **bold** and `code_span` here.",
+		"- first item that is long enough to wrap onto a second line of the list",
+		"```go",
+		"func main() { return } // done",
+		"```",
+	}, "\n")
+
+	out := ansi.Strip(renderMarkdown(input, 40))
+	lines := strings.Split(out, "\n")
+
+	if lines[0] != "Plan" {
+		t.Fatalf("heading marker not removed: %q", lines[0])
+	}
+	if !strings.Contains(out, "Use bold and code_span here.") {
+		t.Fatalf("inline markers not removed:\n%s", out)
+	}
+	if !strings.Contains(out, "• first item") || !strings.Contains(out, "\n  ") {
+		t.Fatalf("expected a bullet with a hanging indent:\n%s", out)
+	}
+	if strings.Contains(out, "```") || !strings.Contains(out, "│ func main() { return } //") {
+		t.Fatalf("expected the fenced block behind a gutter:\n%s", out)
+	}
+	for _, line := range lines {
+		if w := ansi.StringWidth(line); w > 40 {
+			t.Fatalf("line %q is %d wide, over the width", line, w)
+		}
+	}
+}
+
+func TestLooksLikeDiff(t *testing.T) {
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n-old\n+new\n"
+	if !looksLikeDiff(diff) {
+		t.Fatal("expected a unified diff to be detected")
+	}
+	if looksLikeDiff("- a list item\n- another") {
+		t.Fatal("a markdown list is not a diff")
+	}
+	if got := ansi.Strip(renderDiffLine("-old")); got != "−old" {
+		t.Fatalf("removed lines should use a minus sign, got %q", got)
+	}
+}
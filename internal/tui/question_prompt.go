@@ -0,0 +1,295 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/logging"
+	"github.com/A2gent/brute/internal/session"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	questionHeaderStyle = lipgloss.NewStyle().
+				Bold(true).
+				Foreground(lipgloss.Color("#FF9800"))
+
+	questionOptionStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#AAAAAA"))
+
+	questionSelectedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#2196F3")).
+				Bold(true)
+
+	questionDescriptionStyle = lipgloss.NewStyle().
+					Foreground(lipgloss.Color("#666666"))
+)
+
+// loadPendingQuestion shows the question sess is waiting on, if any. It
+// reports whether a question is now shown.
+func (m *Model) loadPendingQuestion(sess *session.Session) bool {
+	if sess == nil || sess.Status != session.StatusInputRequired {
+		return false
+	}
+	question, err := m.sessionManager.GetPendingQuestion(sess.ID)
+	if err != nil || question == nil {
+		return false
+	}
+	m.pendingQuestion = question
+	m.showQuestionPrompt = true
+	m.questionOptionIndex = 0
+	if len(question.Options) == 0 {
+		m.questionOptionIndex = -1
+	}
+	m.questionChecked = make([]bool, len(question.Options))
+	m.viewport.Height = m.viewportHeight()
+	logging.Debug("TUI: Loaded pending question: %s", question.Header)
+	return true
+}
+
+// clearQuestion hides the question prompt.
+func (m *Model) clearQuestion() {
+	m.showQuestionPrompt = false
+	m.pendingQuestion = nil
+	m.questionChecked = nil
+	m.viewport.Height = m.viewportHeight()
+}
+
+// onCustomAnswer reports whether the cursor is on the free-text answer.
+func (m Model) onCustomAnswer() bool {
+	return m.questionOptionIndex == -1 && m.pendingQuestion.Custom
+}
+
+// updateQuestionPrompt handles keys while a question is pending: ↑/↓ or a
+// number selects an option, space toggles it when several answers are
+// allowed, and the free-text answer below the options takes typing.
+func (m Model) updateQuestionPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	q := m.pendingQuestion
+	var cmd tea.Cmd
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		// Always allow Ctrl+C to exit
+		return m, tea.Quit
+	case tea.KeyEsc:
+		// Don't allow escaping question prompt - user must answer
+		return m, nil
+	case tea.KeyPgUp, tea.KeyPgDown, tea.KeyHome, tea.KeyEnd:
+		// Allow scrolling viewport even when question is shown
+		m.viewport, cmd = m.viewport.Update(msg)
+		return m, cmd
+	case tea.KeyUp, tea.KeyDown:
+		// Alt+Up/Down scrolls the viewport; plain arrows move through the
+		// options and then the custom answer (-1)
+		if msg.Alt {
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+		m.moveQuestionCursor(msg.Type == tea.KeyDown)
+		return m, nil
+	case tea.KeyEnter:
+		return m.submitQuestionAnswer()
+	case tea.KeySpace:
+		if q.Multiple && m.questionOptionIndex >= 0 {
+			m.questionChecked[m.questionOptionIndex] = !m.questionChecked[m.questionOptionIndex]
+			return m, nil
+		}
+	case tea.KeyRunes:
+		if len(msg.Runes) == 0 {
+			return m, nil
+		}
+		if m.onCustomAnswer() {
+			// Allow slash commands even when question is shown
+			if msg.Runes[0] == '/' && m.textarea.Value() == "" {
+				m.showCommandMenu = true
+				m.commandMenuIndex = 0
+				m.filteredCommands = m.commandRegistry.GetCommands()
+				return m, nil
+			}
+			break
+		}
+		if n := int(msg.Runes[0] - '0'); len(msg.Runes) == 1 && n >= 1 && n <= len(q.Options) {
+			m.questionOptionIndex = n - 1
+			if q.Multiple {
+				m.questionChecked[n-1] = !m.questionChecked[n-1]
+			}
+		}
+		return m, nil
+	}
+
+	// Only the custom answer takes other keys
+	if m.onCustomAnswer() {
+		m.textarea, cmd = m.textarea.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// moveQuestionCursor moves through the options, then the custom answer.
+func (m *Model) moveQuestionCursor(down bool) {
+	last := len(m.pendingQuestion.Options) - 1
+	switch {
+	case down && m.questionOptionIndex >= 0 && m.questionOptionIndex < last:
+		m.questionOptionIndex++
+	case down && m.questionOptionIndex == last && m.pendingQuestion.Custom:
+		m.questionOptionIndex = -1
+	case !down && m.questionOptionIndex == -1:
+		m.questionOptionIndex = last
+	case !down && m.questionOptionIndex > 0:
+		m.questionOptionIndex--
+	}
+}
+
+// selectedAnswers returns the checked options and the custom answer, or
+// the option under the cursor when nothing is checked.
+func (m Model) selectedAnswers() []string {
+	q := m.pendingQuestion
+	var answers []string
+	if q.Multiple {
+		for i, checked := range m.questionChecked {
+			if checked {
+				answers = append(answers, q.Options[i].Label)
+			}
+		}
+	}
+	if custom := strings.TrimSpace(m.textarea.Value()); q.Custom && custom != "" && (m.onCustomAnswer() || len(answers) > 0) {
+		answers = append(answers, custom)
+	}
+	if len(answers) == 0 && m.questionOptionIndex >= 0 && m.questionOptionIndex < len(q.Options) {
+		answers = append(answers, q.Options[m.questionOptionIndex].Label)
+	}
+	return answers
+}
+
+// submitQuestionAnswer answers the pending question and resumes the run.
+func (m Model) submitQuestionAnswer() (tea.Model, tea.Cmd) {
+	answers := m.selectedAnswers()
+	if len(answers) == 0 {
+		return m, nil
+	}
+	answer, err := m.pendingQuestion.ResolveAnswer(answers)
+	if err == nil {
+		err = m.sessionManager.AnswerQuestion(m.session.ID, answer)
+	}
+	if err != nil {
+		m.addNotice("error", fmt.Sprintf("Failed to answer question: %v", err))
+		return m, nil
+	}
+
+	m.clearQuestion()
+	m.textarea.Reset()
+
+	var cmd tea.Cmd
+	if sess, err := m.sessionManager.Get(m.session.ID); err == nil {
+		m.session = sess
+		// Resume agent if status is running
+		if sess.Status == session.StatusRunning {
+			m.processing = true
+			m.lastUserInputTime = time.Now()
+			var cancel func()
+			cmd, cancel = m.runAgentResume()
+			m.cancelFunc = cancel
+			m.cancelPending = false
+		}
+	}
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, cmd
+}
+
+// calculateQuestionPromptHeight calculates how many lines the question prompt will take
+func (m Model) calculateQuestionPromptHeight() int {
+	if !m.showQuestionPrompt || m.pendingQuestion == nil {
+		return 0
+	}
+
+	height := 0
+	height += 1                              // Top separator
+	height += 1                              // Header line
+	height += len(m.pendingQuestion.Options) // Options (one per line)
+	if m.pendingQuestion.Custom {
+		height += 1 // Empty line before custom hint
+		height += 1 // Custom hint line
+	}
+	height += 1 // Bottom separator
+
+	return height
+}
+
+// renderQuestionPrompt renders the question, its numbered options with
+// their descriptions, and the custom answer hint.
+func (m Model) renderQuestionPrompt() string {
+	if !m.showQuestionPrompt || m.pendingQuestion == nil {
+		return ""
+	}
+	q := m.pendingQuestion
+
+	var sb strings.Builder
+	header := q.Question
+	if q.Header != "" {
+		header = q.Header + ": " + q.Question
+	}
+	sb.WriteString(questionHeaderStyle.Render("❓ " + header))
+	sb.WriteString("\n")
+
+	for i, opt := range q.Options {
+		var icon string
+		if q.Multiple {
+			icon = "☐"
+			if m.questionChecked[i] {
+				icon = "☑"
+			}
+		} else {
+			icon = "○"
+			if i == m.questionOptionIndex {
+				icon = "◉"
+			}
+		}
+
+		style := questionOptionStyle
+		cursor := " "
+		if i == m.questionOptionIndex {
+			style = questionSelectedStyle
+			cursor = "›"
+		}
+
+		text := truncateLine(fmt.Sprintf("%s %s %d. %s", cursor, icon, i+1, opt.Label), m.width)
+		line := style.Render(text)
+		if desc := strings.TrimSpace(opt.Description); desc != "" {
+			if remaining := m.width - lipgloss.Width(text) - 3; remaining > 0 {
+				line += questionDescriptionStyle.Render(" — " + truncateLine(firstLine(desc), remaining))
+			}
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+
+	// Custom answer hint if enabled
+	if q.Custom {
+		sb.WriteString("\n")
+		if m.questionOptionIndex == -1 {
+			sb.WriteString(questionSelectedStyle.Render("› 💡 Custom answer (type below) ▼"))
+		} else {
+			sb.WriteString(questionOptionStyle.Render("  💡 Custom answer (press ↓ to select)"))
+		}
+	}
+
+	// Simple separator line instead of border (more compact)
+	separator := questionHeaderStyle.Render(strings.Repeat("─", m.width))
+
+	return separator + "\n" + strings.TrimSuffix(sb.String(), "\n") + "\n" + separator
+}
+
+// questionSelectionSummary describes what Enter would submit while an
+// option, rather than the custom answer, has the cursor.
+func (m Model) questionSelectionSummary() string {
+	hint := "press Enter to submit"
+	if m.pendingQuestion.Multiple {
+		hint = "space: toggle, enter: submit"
+	}
+	if m.pendingQuestion.Custom {
+		hint += ", ↓ for custom"
+	}
+	return "│ Selected: " + strings.Join(m.selectedAnswers(), ", ") + " (" + hint + ")"
+}
@@ -0,0 +1,77 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/A2gent/brute/internal/session"
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func questionModel(q *session.QuestionData) Model {
+	input := textarea.New()
+	input.Focus()
+	return Model{
+		textarea:           input,
+		width:              80,
+		showQuestionPrompt: true,
+		pendingQuestion:    q,
+		questionChecked:    make([]bool, len(q.Options)),
+	}
+}
+
+func pressKeys(t *testing.T, m Model, keys ...tea.KeyMsg) Model {
+	t.Helper()
+	for _, key := range keys {
+		updated, _ := m.updateQuestionPrompt(key)
+		m = updated.(Model)
+	}
+	return m
+}
+
+func runeKey(r rune) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}}
+}
+
+func TestQuestionPromptNumberSelection(t *testing.T) {
+	q := &session.QuestionData{
+		Question: "Which database?",
+		Options:  []session.QuestionOption{{Label: "SQLite", Description: "Embedded"}, {Label: "Postgres"}},
+	}
+	m := pressKeys(t, questionModel(q), runeKey('2'))
+	if got := m.selectedAnswers(); len(got) != 1 || got[0] != "Postgres" {
+		t.Fatalf("selectedAnswers = %v", got)
+	}
+	m = pressKeys(t, m, runeKey('9'), tea.KeyMsg{Type: tea.KeyUp})
+	if m.questionOptionIndex != 0 {
+		t.Fatalf("questionOptionIndex = %d, want 0", m.questionOptionIndex)
+	}
+	if view := m.renderQuestionPrompt(); !strings.Contains(view, "1. SQLite") || !strings.Contains(view, "Embedded") {
+		t.Fatalf("prompt should number options and show descriptions:\n%s", view)
+	}
+}
+
+func TestQuestionPromptMultipleWithCustomAnswer(t *testing.T) {
+	q := &session.QuestionData{
+		Question: "Which checks?",
+		Options:  []session.QuestionOption{{Label: "lint"}, {Label: "test"}, {Label: "build"}},
+		Multiple: true,
+		Custom:   true,
+	}
+	m := pressKeys(t, questionModel(q),
+		runeKey('1'),
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeyDown},
+		tea.KeyMsg{Type: tea.KeySpace},
+		tea.KeyMsg{Type: tea.KeyDown},
+		runeKey('v'), runeKey('e'), runeKey('t'),
+	)
+	got := strings.Join(m.selectedAnswers(), ", ")
+	if got != "lint, build, vet" {
+		t.Fatalf("selectedAnswers = %q", got)
+	}
+	if _, err := q.ResolveAnswer(m.selectedAnswers()); err != nil {
+		t.Fatalf("ResolveAnswer: %v", err)
+	}
+}
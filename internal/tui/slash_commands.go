@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/session"
+	"github.com/A2gent/brute/internal/transcript"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// commandHandler runs a slash command with the words typed after it.
+type commandHandler func(m Model, args []string) (tea.Model, tea.Cmd)
+
+// commandHandlers runs the commands listed by commands.NewRegistry; adding a
+// command takes a registry entry and a handler here.
+var commandHandlers = map[string]commandHandler{
+	"new":      func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.createNewSession() },
+	"clear":    func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.createNewSession() },
+	"sessions": func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showSessions() },
+	"projects": func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showProjectsSelection() },
+	"provider": func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showProviderSelection() },
+	"models":   func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showModelsSelection() },
+	"model": func(m Model, args []string) (tea.Model, tea.Cmd) {
+		if len(args) == 0 {
+			return m.showModelsSelection()
+		}
+		return m.setSessionModel(strings.Join(args, " "))
+	},
+	"compact": func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.compactSession() },
+	"export":  func(m Model, args []string) (tea.Model, tea.Cmd) { return m.exportTranscript(strings.Join(args, " ")) },
+	"help":    func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showHelp() },
+	"logs":    func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showLogs() },
+	"skills":  func(m Model, _ []string) (tea.Model, tea.Cmd) { return m.showSkills() },
+}
+
+// compactDoneMsg reports the end of a /compact.
+type compactDoneMsg struct {
+	compacted bool
+	err       error
+}
+
+// addNotice appends a system or error line to the conversation view.
+func (m *Model) addNotice(role, content string) {
+	m.messages = append(m.messages, message{
+		role:      role,
+		content:   content,
+		timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// compactSession summarizes the conversation in the background. Esc cancels
+// it like a run.
+func (m Model) compactSession() (tea.Model, tea.Cmd) {
+	if m.processing {
+		m.addNotice("error", "Cannot compact while the agent is running")
+		return m, nil
+	}
+	if len(m.session.Messages) == 0 {
+		m.addNotice("system", "Nothing to compact yet")
+		return m, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.processing = true
+	m.cancelFunc = cancel
+	m.cancelPending = false
+	m.lastUserInputTime = time.Now()
+	m.addNotice("system", "Compacting the conversation...")
+
+	ag, sess := m.agent, m.session
+	return m, func() tea.Msg {
+		defer cancel()
+		_, compacted, err := ag.Compact(ctx, sess)
+		return compactDoneMsg{compacted: compacted, err: err}
+	}
+}
+
+// finishCompaction shows the outcome of a /compact and reloads the
+// conversation with its summary.
+func (m Model) finishCompaction(msg compactDoneMsg) Model {
+	m.processing = false
+	m.cancelFunc = nil
+	m.cancelPending = false
+	switch {
+	case errors.Is(msg.err, context.Canceled):
+		m.addNotice("system", "Compaction cancelled")
+	case msg.err != nil:
+		m.addNotice("error", fmt.Sprintf("Compaction failed: %v", msg.err))
+	case !msg.compacted:
+		m.addNotice("system", "Nothing to compact yet")
+	default:
+		m.messages = messagesFromSession(m.session)
+		m.contextTokens = 0
+		m.applySessionTokenMetadata(m.session)
+		m.addNotice("system", "Conversation compacted; earlier messages are replaced by the summary above")
+	}
+	return m
+}
+
+// exportTranscript writes the session as Markdown to path, by default
+// session-<id>.md in the working directory.
+func (m Model) exportTranscript(path string) (tea.Model, tea.Cmd) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		path = fmt.Sprintf("session-%s.md", m.session.ID)
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	if err := os.WriteFile(path, []byte(transcript.Markdown(m.session)), 0o644); err != nil {
+		m.addNotice("error", fmt.Sprintf("Failed to export transcript: %v", err))
+		return m, nil
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	m.addNotice("system", "Transcript exported to "+path)
+	return m, nil
+}
+
+// messagesFromSession converts stored session messages for display.
+func messagesFromSession(sess *session.Session) []message {
+	messages := make([]message, 0, len(sess.Messages))
+	for _, msg := range sess.Messages {
+		messages = append(messages, message{
+			role:        msg.Role,
+			content:     msg.Content,
+			timestamp:   msg.Timestamp,
+			toolCalls:   msg.ToolCalls,
+			toolResults: msg.ToolResults,
+			metadata:    msg.Metadata,
+		})
+	}
+	return messages
+}
+
+// initialMessageLimit bounds the messages shown when a session is loaded,
+// mirroring the default page of GET /sessions/{id}.
+const initialMessageLimit = 100
+
+// recentMessagesFromSession converts the latest limit messages for display,
+// after a notice counting the earlier ones left out.
+func recentMessagesFromSession(sess *session.Session, limit int) []message {
+	messages := messagesFromSession(sess)
+	if len(messages) <= limit {
+		return messages
+	}
+	hidden := len(messages) - limit
+	notice := message{
+		role:      "system",
+		content:   fmt.Sprintf("%d earlier messages are not shown; /export writes the full transcript", hidden),
+		timestamp: messages[hidden].timestamp,
+	}
+	return append([]message{notice}, messages[hidden:]...)
+}
@@ -0,0 +1,286 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/A2gent/brute/internal/agent"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// toolPanelMaxCalls bounds the calls listed; the oldest finished calls
+	// are dropped first.
+	toolPanelMaxCalls = 6
+	// toolPanelResultLines bounds the lines shown for an expanded result.
+	toolPanelResultLines = 10
+)
+
+var (
+	toolPanelHeaderStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#7D56F4")).
+				Bold(true)
+
+	toolPanelOKStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#04B575"))
+
+	toolPanelSelectedStyle = lipgloss.NewStyle().
+				Background(lipgloss.Color("#2a2a2a"))
+
+	toolPanelDimStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("#666666"))
+)
+
+// toolCallState is one tool call shown in the tool panel.
+type toolCallState struct {
+	id           string
+	name         string
+	inputPreview string
+	started      time.Time
+	duration     time.Duration
+	done         bool
+	isError      bool
+	interrupted  bool // the run ended before the call reported back
+	output       string
+	outputBytes  int
+}
+
+// toolPanel lists the in-flight and recently finished tool calls of the
+// current run, keyed by tool call ID.
+type toolPanel struct {
+	calls     []toolCallState
+	collapsed bool
+	selected  int // index into calls of the expanded entry, or -1
+}
+
+func newToolPanel() toolPanel {
+	return toolPanel{selected: -1}
+}
+
+// reset clears the calls of the previous run, keeping the collapsed state.
+func (p *toolPanel) reset() {
+	p.calls = nil
+	p.selected = -1
+}
+
+func (p *toolPanel) indexOf(id string) int {
+	for i := range p.calls {
+		if p.calls[i].id == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// apply updates the panel from a tool call event.
+func (p *toolPanel) apply(ev agent.Event, now time.Time) {
+	switch ev.Type {
+	case agent.EventToolCallStarted:
+		for _, tc := range ev.ToolCalls {
+			if p.indexOf(tc.ID) >= 0 {
+				continue
+			}
+			p.calls = append(p.calls, toolCallState{id: tc.ID, name: tc.Name, inputPreview: tc.InputPreview, started: now})
+		}
+	case agent.EventToolCallFinished:
+		tr := ev.ToolResult
+		if tr == nil {
+			return
+		}
+		i := p.indexOf(tr.ToolCallID)
+		if i < 0 {
+			// The start event was dropped; show the call from its result.
+			p.calls = append(p.calls, toolCallState{id: tr.ToolCallID, name: tr.Name, inputPreview: tr.InputPreview, started: now.Add(-tr.Duration)})
+			i = len(p.calls) - 1
+		}
+		call := &p.calls[i]
+		call.done = true
+		call.interrupted = false
+		call.isError = tr.IsError
+		call.duration = tr.Duration
+		call.output = tr.Content
+		call.outputBytes = tr.OutputBytes
+	}
+	p.trim()
+}
+
+// finishRun stops the timers of calls the run never reported as finished.
+func (p *toolPanel) finishRun(now time.Time) {
+	for i := range p.calls {
+		if !p.calls[i].done {
+			p.calls[i].done = true
+			p.calls[i].interrupted = true
+			p.calls[i].duration = now.Sub(p.calls[i].started)
+		}
+	}
+}
+
+// trim drops the oldest finished calls beyond toolPanelMaxCalls. Running
+// calls are always kept.
+func (p *toolPanel) trim() {
+	for len(p.calls) > toolPanelMaxCalls {
+		drop := -1
+		for i := range p.calls {
+			if p.calls[i].done {
+				drop = i
+				break
+			}
+		}
+		if drop < 0 {
+			return
+		}
+		p.calls = append(p.calls[:drop], p.calls[drop+1:]...)
+		switch {
+		case p.selected == drop:
+			p.selected = -1
+		case p.selected > drop:
+			p.selected--
+		}
+	}
+}
+
+// moveSelection selects the previous (delta < 0) or next entry. Moving past
+// either end clears the selection, collapsing the expanded result.
+func (p *toolPanel) moveSelection(delta int) {
+	if len(p.calls) == 0 {
+		p.selected = -1
+		return
+	}
+	switch {
+	case p.selected < 0 && delta < 0:
+		p.selected = len(p.calls) - 1
+	case p.selected < 0:
+		p.selected = 0
+	default:
+		p.selected += delta
+		if p.selected < 0 || p.selected >= len(p.calls) {
+			p.selected = -1
+		}
+	}
+}
+
+func (p toolPanel) visible() bool {
+	return len(p.calls) > 0
+}
+
+func (p toolPanel) running() int {
+	n := 0
+	for _, call := range p.calls {
+		if !call.done {
+			n++
+		}
+	}
+	return n
+}
+
+// height returns the number of lines render produces.
+func (p toolPanel) height() int {
+	if !p.visible() {
+		return 0
+	}
+	if p.collapsed {
+		return 1
+	}
+	return 1 + len(p.calls) + len(p.resultLines())
+}
+
+// resultLines returns the truncated result of the selected call.
+func (p toolPanel) resultLines() []string {
+	if p.selected < 0 || p.selected >= len(p.calls) || !p.calls[p.selected].done {
+		return nil
+	}
+	output := strings.TrimRight(p.calls[p.selected].output, "\n")
+	if strings.TrimSpace(output) == "" {
+		return []string{"(no output)"}
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) > toolPanelResultLines {
+		more := len(lines) - toolPanelResultLines
+		lines = append(lines[:toolPanelResultLines:toolPanelResultLines], fmt.Sprintf("… %d more lines", more))
+	}
+	return lines
+}
+
+// render draws the panel at the given width; now drives the elapsed timers
+// of running calls.
+func (p toolPanel) render(width int, now time.Time) string {
+	if !p.visible() {
+		return ""
+	}
+	header := fmt.Sprintf("Tools: %d running, %d done", p.running(), len(p.calls)-p.running())
+	if p.collapsed {
+		return toolPanelHeaderStyle.Render("▸ "+header) + toolPanelDimStyle.Render(" · ctrl+o: show")
+	}
+
+	lines := []string{toolPanelHeaderStyle.Render("▾ "+header) + toolPanelDimStyle.Render(" · ctrl+o: hide · shift+↑↓: show result")}
+	for i, call := range p.calls {
+		line := p.renderCall(call, width, now)
+		if i == p.selected {
+			line = toolPanelSelectedStyle.Width(width).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	for _, line := range p.resultLines() {
+		lines = append(lines, toolResultStyle.Render(truncateLine("    "+line, width)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (p toolPanel) renderCall(call toolCallState, width int, now time.Time) string {
+	var status string
+	elapsed := call.duration
+	switch {
+	case !call.done:
+		status = loadingStyle.Render("●")
+		elapsed = now.Sub(call.started)
+	case call.interrupted, call.isError:
+		status = errorStyle.Render("✗")
+	default:
+		status = toolPanelOKStyle.Render("✓")
+	}
+
+	label := fmt.Sprintf("%s(%s)", call.name, call.inputPreview)
+	timer := fmt.Sprintf("%.1fs", elapsed.Seconds())
+	if call.done && call.outputBytes > 0 {
+		timer += " " + formatByteSize(call.outputBytes)
+	}
+	prefix := fmt.Sprintf("  %s %s ", status, toolStyle.Render(truncateLine(label, width/2)))
+	line := prefix + toolPanelDimStyle.Render(timer)
+
+	var summary string
+	switch {
+	case call.interrupted:
+		summary = "interrupted"
+	case call.done:
+		summary = firstLine(call.output)
+	}
+	if summary != "" {
+		remaining := width - lipgloss.Width(line) - 3
+		if remaining > 0 {
+			line += toolPanelDimStyle.Render(" · " + truncateLine(summary, remaining))
+		}
+	}
+	return line
+}
+
+// formatByteSize renders n as "512 B", "4.2 KB" or "1.3 MB".
+func formatByteSize(n int) string {
+	switch {
+	case n < 1024:
+		return fmt.Sprintf("%d B", n)
+	case n < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+}
+
+// firstLine returns the first non-blank line of text.
+func firstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@ package tui
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -25,7 +26,9 @@ import (
 	"github.com/A2gent/brute/internal/llm/retry"
 	"github.com/A2gent/brute/internal/logging"
 	"github.com/A2gent/brute/internal/session"
+	skillsLoader "github.com/A2gent/brute/internal/skills"
 	"github.com/A2gent/brute/internal/tools"
+	"github.com/A2gent/brute/internal/transcript"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
@@ -192,6 +195,14 @@ var (
 			Foreground(lipgloss.Color("#666666"))
 )
 
+// interruptConfirmWindow is how soon a second Ctrl+C (or Esc when idle)
+// must follow the first to take effect.
+const interruptConfirmWindow = 2 * time.Second
+
+// contextWarningPercent is the context usage at which the status bar turns
+// to the warning color and suggests /compact.
+const contextWarningPercent = 80
+
 // ASCII art for empty state
 const asciiArt = `
          █████╗ ██████╗     ██████╗ ██████╗ ██╗   ██╗████████╗███████╗
@@ -473,6 +484,13 @@ type (
 		memoryMB float64
 	}
 
+	// toolEventMsg carries per-call tool progress and token usage from a
+	// running agent.
+	toolEventMsg struct {
+		event  agent.Event
+		events <-chan agent.Event
+	}
+
 	sessionSyncMsg struct {
 		session *session.Session
 	}
@@ -494,14 +512,19 @@ type Model struct {
 
 	// Display state
 	messages    []message
+	markdown    bool // render assistant messages as markdown
 	taskSummary string
-	width       int
-	height      int
-	ready       bool
+	// titleRequested is set once a summarized title has been requested for the session
+	titleRequested bool
+	width          int
+	height         int
+	ready          bool
 
 	// Token tracking
 	totalInputTokens  int
 	totalOutputTokens int
+	billedInputTokens int // input summed over every request, for the cost estimate
+	contextTokens     int // size of the latest request
 	contextWindow     int // in tokens (default 128k for kimi-k2.5)
 
 	// Interaction tracking for auto-summarization
@@ -517,9 +540,24 @@ type Model struct {
 	loadingFrames     []string
 	loadingIndex      int
 
+	// toolPanel tracks the tool calls of the running agent
+	toolPanel toolPanel
+
+	// Tool approval: the call waiting for the user, and the tools each
+	// session always allows
+	approvalGrants  *approvalGrants
+	pendingApproval *approvalRequestMsg
+	approvalShownAt time.Time
+	approvalEditing bool   // the input holds the call's arguments
+	approvalDraft   string // the input draft kept while editing arguments
+
 	// Cancel support
 	cancelFunc    context.CancelFunc
-	cancelPending bool // true if user pressed Ctrl+C once while processing
+	cancelPending bool // true once the user interrupted the running agent
+	// interruptKey and interruptArmedAt track the first press of a
+	// double Ctrl+C or Esc
+	interruptKey     tea.KeyType
+	interruptArmedAt time.Time
 
 	// Command menu state
 	commandRegistry  *commands.Registry
@@ -570,7 +608,8 @@ type Model struct {
 	// Question prompt state
 	showQuestionPrompt  bool
 	pendingQuestion     *session.QuestionData
-	questionOptionIndex int // Selected option index (-1 = custom answer)
+	questionOptionIndex int    // Selected option index (-1 = custom answer)
+	questionChecked     []bool // Options ticked when Multiple is set
 
 	// Error state
 	err error
@@ -653,7 +692,6 @@ func New(
 		textarea:          ta,
 		session:           sess,
 		sessionManager:    sessionManager,
-		agent:             agent.New(agentConfig, llmClient, toolManager, sessionManager),
 		toolManager:       toolManager,
 		llmClient:         llmClient,
 		agentConfig:       agentConfig,
@@ -666,20 +704,17 @@ func New(
 		commandRegistry:   cmdRegistry,
 		filteredCommands:  cmdRegistry.GetCommands(),
 		appConfig:         appConfig,
+		toolPanel:         newToolPanel(),
+		approvalGrants:    newApprovalGrants(),
+		markdown:          markdownEnabled(),
 	}
+	m.agent = m.agentForSession()
 
-	// Load existing messages from session
-	for _, msg := range sess.Messages {
-		m.messages = append(m.messages, message{
-			role:        msg.Role,
-			content:     msg.Content,
-			timestamp:   msg.Timestamp,
-			toolCalls:   msg.ToolCalls,
-			toolResults: msg.ToolResults,
-			metadata:    msg.Metadata,
-		})
-	}
+	// Load the latest messages of the session
+	m.messages = recentMessagesFromSession(sess, initialMessageLimit)
 	m.applySessionTokenMetadata(sess)
+	// A continued session may still be waiting on a question
+	m.loadPendingQuestion(sess)
 
 	return m
 }
@@ -705,6 +740,56 @@ func (m *Model) saveSessionIfNotEmpty() {
 	_ = m.sessionManager.Save(m.session)
 }
 
+// cancelRun cancels the running agent; the loop pauses the session and keeps
+// the messages produced so far.
+func (m *Model) cancelRun(notice string) {
+	m.cancelPending = true
+	m.interruptArmedAt = time.Time{}
+	if m.pendingApproval != nil {
+		m.dismissApproval()
+	}
+	if m.cancelFunc != nil {
+		m.cancelFunc()
+		logging.Info("Agent cancelled by user")
+	}
+	m.messages = append(m.messages, message{
+		role:      "system",
+		content:   notice,
+		timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+}
+
+// confirmInterrupt reports whether key was already pressed within
+// interruptConfirmWindow. Otherwise it arms key, so that the bottom bar
+// asks for a second press.
+func (m *Model) confirmInterrupt(key tea.KeyType) bool {
+	now := time.Now()
+	if m.interruptKey == key && now.Sub(m.interruptArmedAt) <= interruptConfirmWindow {
+		m.interruptArmedAt = time.Time{}
+		return true
+	}
+	m.interruptKey = key
+	m.interruptArmedAt = now
+	return false
+}
+
+// interruptHint asks for the second press of an armed Ctrl+C or Esc.
+func (m Model) interruptHint() string {
+	if m.interruptArmedAt.IsZero() || time.Since(m.interruptArmedAt) > interruptConfirmWindow {
+		return ""
+	}
+	key := "ctrl+c"
+	if m.interruptKey == tea.KeyEsc {
+		key = "esc"
+	}
+	if m.processing {
+		return "press " + key + " again to stop the run"
+	}
+	return "press " + key + " again to quit"
+}
+
 // tickCmd creates a command that sends a tick message every second
 func tickCmd() tea.Cmd {
 	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
@@ -747,14 +832,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.width = msg.Width
 		m.height = msg.Height
 
-		// Height calculation: total - topBar(1) - textarea(3) - bottomBar(1) = total - 5
-		// If question prompt is shown, also subtract its height
-		fixedHeight := 5 // topBar + textarea + bottomBar
-		questionHeight := m.calculateQuestionPromptHeight()
-		viewportHeight := msg.Height - fixedHeight - questionHeight
-		if viewportHeight < 1 {
-			viewportHeight = 1
-		}
+		viewportHeight := m.viewportHeight()
 
 		if !m.ready {
 			m.viewport = viewport.New(msg.Width, viewportHeight)
@@ -769,6 +847,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewport.SetContent(m.renderMessages())
 
 	case tea.KeyMsg:
+		// A pending tool approval takes every key it does not pass on
+		if m.pendingApproval != nil {
+			updated, cmd, handled := m.updateApproval(msg)
+			if handled {
+				return updated, cmd
+			}
+			m = updated
+		}
+
 		// Handle command menu first (highest priority - works even over question prompt)
 		if m.showCommandMenu {
 			switch msg.Type {
@@ -789,9 +876,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case tea.KeyEnter, tea.KeyTab:
 				if len(m.filteredCommands) > 0 {
 					selectedCmd := m.filteredCommands[m.commandMenuIndex]
+					var args []string
+					if fields := strings.Fields(strings.TrimPrefix(m.textarea.Value(), "/")); len(fields) > 1 {
+						args = fields[1:]
+					}
 					m.showCommandMenu = false
 					m.textarea.Reset()
-					return m.executeCommand(selectedCmd.Name)
+					return m.executeCommand(selectedCmd.Name, args...)
 				}
 				return m, nil
 			case tea.KeyBackspace:
@@ -827,122 +918,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Handle question prompt
 		if m.showQuestionPrompt && m.pendingQuestion != nil {
-			switch msg.Type {
-			case tea.KeyCtrlC:
-				// Always allow Ctrl+C to exit
-				return m, tea.Quit
-			case tea.KeyEsc:
-				// Don't allow escaping question prompt - user must answer
-				return m, nil
-			case tea.KeyPgUp, tea.KeyPgDown, tea.KeyHome, tea.KeyEnd:
-				// Allow scrolling viewport even when question is shown
-				m.viewport, vpCmd = m.viewport.Update(msg)
-				return m, vpCmd
-			case tea.KeyUp:
-				// Use Alt+Up for viewport scroll, plain Up for option navigation
-				if msg.Alt {
-					m.viewport, vpCmd = m.viewport.Update(msg)
-					return m, vpCmd
-				}
-				// Navigate up: custom (-1) -> last option -> ... -> first option
-				if m.questionOptionIndex == -1 {
-					// From custom to last option
-					m.questionOptionIndex = len(m.pendingQuestion.Options) - 1
-				} else if m.questionOptionIndex > 0 {
-					m.questionOptionIndex--
-				}
-				return m, nil
-			case tea.KeyDown:
-				// Use Alt+Down for viewport scroll, plain Down for option navigation
-				if msg.Alt {
-					m.viewport, vpCmd = m.viewport.Update(msg)
-					return m, vpCmd
-				}
-				// Navigate down: first option -> ... -> last option -> custom (-1)
-				if m.questionOptionIndex < len(m.pendingQuestion.Options)-1 {
-					m.questionOptionIndex++
-				} else if m.pendingQuestion.Custom {
-					// Go to custom answer position
-					m.questionOptionIndex = -1
-				}
-				return m, nil
-			case tea.KeyEnter:
-				// Submit answer
-				var answer string
-
-				if m.questionOptionIndex == -1 {
-					// Custom answer - use textarea value
-					answer = strings.TrimSpace(m.textarea.Value())
-				} else if m.questionOptionIndex >= 0 && m.questionOptionIndex < len(m.pendingQuestion.Options) {
-					// Selected option
-					answer = m.pendingQuestion.Options[m.questionOptionIndex].Label
-				}
-
-				if answer != "" {
-					// Send answer
-					if err := m.sessionManager.AnswerQuestion(m.session.ID, answer); err != nil {
-						m.messages = append(m.messages, message{
-							role:      "error",
-							content:   fmt.Sprintf("Failed to answer question: %v", err),
-							timestamp: time.Now(),
-						})
-					} else {
-						// Clear question state
-						m.showQuestionPrompt = false
-						m.pendingQuestion = nil
-						m.textarea.Reset() // Clear textarea
-
-						// Recalculate viewport height now that question is hidden
-						fixedHeight := 5 // topBar + textarea + bottomBar
-						questionHeight := m.calculateQuestionPromptHeight()
-						viewportHeight := m.height - fixedHeight - questionHeight
-						if viewportHeight < 1 {
-							viewportHeight = 1
-						}
-						m.viewport.Height = viewportHeight
-
-						// Reload session
-						if sess, err := m.sessionManager.Get(m.session.ID); err == nil {
-							m.session = sess
-							// Resume agent if status is running
-							if sess.Status == session.StatusRunning {
-								m.processing = true
-								m.lastUserInputTime = time.Now()
-								cmd, cancel := m.runAgentResume()
-								m.cancelFunc = cancel
-								m.cancelPending = false
-								cmds = append(cmds, cmd)
-							}
-						}
-					}
-					m.viewport.SetContent(m.renderMessages())
-					m.viewport.GotoBottom()
-				}
-				return m, tea.Batch(cmds...)
-			case tea.KeyRunes:
-				// Allow slash commands even when question is shown
-				if len(msg.Runes) > 0 && msg.Runes[0] == '/' && m.textarea.Value() == "" && m.questionOptionIndex == -1 {
-					m.showCommandMenu = true
-					m.commandMenuIndex = 0
-					m.filteredCommands = m.commandRegistry.GetCommands()
-					return m, nil
-				}
-				// If custom answer is selected, let textarea handle input
-				if m.questionOptionIndex == -1 && m.pendingQuestion.Custom {
-					m.textarea, taCmd = m.textarea.Update(msg)
-					return m, taCmd
-				}
-				// Otherwise ignore other keys
-				return m, nil
-			default:
-				// If custom answer is selected, let textarea handle input
-				if m.questionOptionIndex == -1 && m.pendingQuestion.Custom {
-					m.textarea, taCmd = m.textarea.Update(msg)
-					return m, taCmd
-				}
-				// Otherwise ignore other keys
-				return m, nil
-			}
+			return m.updateQuestionPrompt(msg)
 		}
 
 		// Handle logs view first
@@ -1172,7 +1148,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyCtrlC:
 			if m.processing {
 				if m.cancelPending {
-					// Second Ctrl+C - force quit
+					// Ctrl+C while the run is already stopping - force quit
 					if m.cancelFunc != nil {
 						m.cancelFunc()
 					}
@@ -1181,29 +1157,33 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					}
 					return m, tea.Quit
 				}
-				// First Ctrl+C while processing - cancel the agent
-				m.cancelPending = true
-				if m.cancelFunc != nil {
-					m.cancelFunc()
-					logging.Info("Agent cancelled by user")
+				// Double Ctrl+C while processing - interrupt the agent
+				if m.confirmInterrupt(tea.KeyCtrlC) {
+					m.cancelRun("Interrupting the run... (press Ctrl+C again to force quit)")
 				}
-				// Show cancellation message
-				m.messages = append(m.messages, message{
-					role:      "error",
-					content:   "Cancelling... (press Ctrl+C again to force quit)",
-					timestamp: time.Now(),
-				})
-				m.viewport.SetContent(m.renderMessages())
-				m.viewport.GotoBottom()
 				return m, nil
 			}
-			// Not processing - quit immediately
+			// Not processing - double Ctrl+C quits
+			if !m.confirmInterrupt(tea.KeyCtrlC) {
+				return m, nil
+			}
 			if m.session != nil {
 				m.saveSessionIfNotEmpty()
 			}
 			return m, tea.Quit
 
 		case tea.KeyEsc:
+			// Esc stops a running agent without leaving the TUI
+			if m.processing {
+				if !m.cancelPending {
+					m.cancelRun("Interrupting the run...")
+				}
+				return m, nil
+			}
+			// A stray Esc just after a run ends must not quit: ask for a second press
+			if !m.confirmInterrupt(tea.KeyEsc) {
+				return m, nil
+			}
 			// Save session before quitting
 			if m.session != nil {
 				m.saveSessionIfNotEmpty()
@@ -1220,11 +1200,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if strings.TrimSpace(input) != "" {
 				// Check if it's a command
 				if strings.HasPrefix(input, "/") {
-					cmdName := strings.TrimPrefix(input, "/")
-					cmdName = strings.TrimSpace(cmdName)
-					if cmd := m.commandRegistry.FindCommand(cmdName); cmd != nil {
-						m.textarea.Reset()
-						return m.executeCommand(cmd.Name)
+					fields := strings.Fields(strings.TrimPrefix(input, "/"))
+					if len(fields) > 0 {
+						if cmd := m.commandRegistry.FindCommand(fields[0]); cmd != nil {
+							m.textarea.Reset()
+							return m.executeCommand(cmd.Name, fields[1:]...)
+						}
 					}
 				}
 
@@ -1255,6 +1236,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case tea.KeyCtrlO:
+			m.toolPanel.collapsed = !m.toolPanel.collapsed
+			m.viewport.Height = m.viewportHeight()
+			return m, nil
+
+		case tea.KeyShiftUp, tea.KeyShiftDown:
+			// Select a tool call in the panel to show its result
+			if m.toolPanel.visible() && !m.toolPanel.collapsed {
+				delta := 1
+				if msg.Type == tea.KeyShiftUp {
+					delta = -1
+				}
+				m.toolPanel.moveSelection(delta)
+				m.viewport.Height = m.viewportHeight()
+				return m, nil
+			}
+
 		case tea.KeyRunes:
 			// Check if user is typing a slash to show command menu
 			if len(msg.Runes) > 0 && msg.Runes[0] == '/' && m.textarea.Value() == "" {
@@ -1279,24 +1277,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case sessionSyncMsg:
 		if msg.session != nil {
 			// Check if session status changed to input_required
-			if msg.session.Status == session.StatusInputRequired && !m.showQuestionPrompt {
-				// Load pending question
-				question, err := m.sessionManager.GetPendingQuestion(msg.session.ID)
-				if err == nil && question != nil {
-					m.pendingQuestion = question
-					m.showQuestionPrompt = true
-					m.questionOptionIndex = 0
-					m.processing = false // Stop processing, wait for answer
-
-					// Recalculate viewport height now that question is shown
-					fixedHeight := 5 // topBar + textarea + bottomBar
-					questionHeight := m.calculateQuestionPromptHeight()
-					viewportHeight := m.height - fixedHeight - questionHeight
-					if viewportHeight < 1 {
-						viewportHeight = 1
-					}
-					m.viewport.Height = viewportHeight
-				}
+			if !m.showQuestionPrompt && m.loadPendingQuestion(msg.session) {
+				m.processing = false // Stop processing, wait for answer
 			}
 
 			// Check if there are new messages from external sources (e.g., web app)
@@ -1305,17 +1287,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if len(msg.session.Messages) > m.lastSyncedMessageCount {
 				// Reload messages from the synced session
 				m.session = msg.session
-				m.messages = make([]message, 0, len(msg.session.Messages))
-				for _, sessionMsg := range msg.session.Messages {
-					m.messages = append(m.messages, message{
-						role:        sessionMsg.Role,
-						content:     sessionMsg.Content,
-						timestamp:   sessionMsg.Timestamp,
-						toolCalls:   sessionMsg.ToolCalls,
-						toolResults: sessionMsg.ToolResults,
-						metadata:    sessionMsg.Metadata,
-					})
-				}
+				m.messages = recentMessagesFromSession(msg.session, initialMessageLimit)
 				m.lastSyncedMessageCount = len(msg.session.Messages)
 				m.taskSummary = msg.session.Title
 				m.applySessionTokenMetadata(msg.session)
@@ -1329,20 +1301,43 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Schedule next sync
 		cmds = append(cmds, sessionSyncCmd(m.sessionManager, m.session.ID))
 
+	case toolEventMsg:
+		if usage := msg.event.Usage; usage != nil {
+			m.totalInputTokens = usage.TotalInputTokens
+			m.totalOutputTokens = usage.TotalOutputTokens
+			m.billedInputTokens = usage.BilledInputTokens
+			m.contextTokens = usage.ContextTokens
+		} else {
+			m.toolPanel.apply(msg.event, time.Now())
+			m.viewport.Height = m.viewportHeight()
+		}
+		cmds = append(cmds, waitForToolEvent(msg.events))
+
+	case approvalRequestMsg:
+		var cmd tea.Cmd
+		m, cmd = m.showApproval(msg)
+		cmds = append(cmds, cmd)
+
 	case agentResponseMsg:
+		m.toolPanel.finishRun(time.Now())
+		if m.pendingApproval != nil {
+			m.dismissApproval()
+		}
+		// Token counts were updated after every step by usage events
 		logging.Debug("TUI received agentResponseMsg: done=%v err=%v tokens=%d/%d", msg.done, msg.err != nil, msg.inputTokens, msg.outputTokens)
 
-		// Update token counts
-		m.totalInputTokens += msg.inputTokens
-		m.totalOutputTokens += msg.outputTokens
-
 		if msg.err != nil {
 			m.processing = false
 			m.cancelFunc = nil
 			m.cancelPending = false
+			role, content := "error", msg.err.Error()
+			if errors.Is(msg.err, context.Canceled) {
+				role = "system"
+				content = "Run interrupted. Its messages so far were saved and the session is paused; send a message to continue."
+			}
 			m.messages = append(m.messages, message{
-				role:      "error",
-				content:   msg.err.Error(),
+				role:      role,
+				content:   content,
 				timestamp: time.Now(),
 			})
 			m.viewport.SetContent(m.renderMessages())
@@ -1356,25 +1351,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Check if session is waiting for input
 			if freshSess, err := m.sessionManager.Get(m.session.ID); err == nil {
 				m.session = freshSess
-				if freshSess.Status == session.StatusInputRequired {
-					// Load pending question immediately
-					question, qErr := m.sessionManager.GetPendingQuestion(freshSess.ID)
-					if qErr == nil && question != nil {
-						m.pendingQuestion = question
-						m.showQuestionPrompt = true
-						m.questionOptionIndex = 0
-						logging.Debug("TUI: Loaded pending question: %s", question.Header)
-
-						// Recalculate viewport height now that question is shown
-						fixedHeight := 5 // topBar + textarea + bottomBar
-						questionHeight := m.calculateQuestionPromptHeight()
-						viewportHeight := m.height - fixedHeight - questionHeight
-						if viewportHeight < 1 {
-							viewportHeight = 1
-						}
-						m.viewport.Height = viewportHeight
-					}
-				}
+				// Load pending question immediately
+				m.loadPendingQuestion(freshSess)
 			}
 
 			// Add assistant response message
@@ -1390,6 +1368,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Update sync counter after agent completes
 			m.lastSyncedMessageCount = len(m.session.Messages)
 
+			// Replace the prompt-derived title with a short summary once.
+			if !m.titleRequested && m.session.NeedsGeneratedTitle() {
+				m.titleRequested = true
+				cmds = append(cmds, m.generateTitle())
+			}
+
 			// Process queued messages
 			if len(m.queuedMessages) > 0 {
 				// Get the first queued message
@@ -1409,6 +1393,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.session.AddUserMessage(nextInput)
 				m.lastUserInputTime = time.Now()
 				m.processing = true
+				m.toolPanel.reset()
+				m.viewport.Height = m.viewportHeight()
 				m.viewport.SetContent(m.renderMessages())
 				m.viewport.GotoBottom()
 				cmd, cancel := m.runAgent(nextInput)
@@ -1419,13 +1405,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case titleUpdateMsg:
-		// Update session title
-		m.session.SetTitle(msg.title)
-		m.taskSummary = msg.title
-		m.saveSessionIfNotEmpty()
+		// Update session title unless generation failed or the user renamed it meanwhile
+		if msg.title != "" && m.session.NeedsGeneratedTitle() {
+			m.session.SetTitleWithSource(msg.title, session.TitleSourceGenerated)
+			m.taskSummary = msg.title
+			m.saveSessionIfNotEmpty()
+		}
 		// Update token counts from title generation
 		m.totalInputTokens += msg.inputTokens
 		m.totalOutputTokens += msg.outputTokens
+		m.billedInputTokens += msg.inputTokens
+
+	case compactDoneMsg:
+		m = m.finishCompaction(msg)
 
 	case tokenUpdateMsg:
 		m.totalInputTokens += msg.inputTokens
@@ -1516,11 +1508,20 @@ func (m Model) View() string {
 		)
 	}
 
+	// Tool activity panel (rendered above the question prompt and input)
+	var toolPanelView string
+	if m.toolPanel.visible() {
+		toolPanelView = m.toolPanel.render(m.width, time.Now()) + "\n"
+	}
+
 	// Question prompt (rendered above input if active)
 	var questionPrompt string
 	if m.showQuestionPrompt {
 		questionPrompt = m.renderQuestionPrompt() + "\n"
 	}
+	if m.pendingApproval != nil {
+		questionPrompt = m.renderApprovalPrompt() + "\n" + questionPrompt
+	}
 
 	// Command menu (rendered above input if active)
 	var commandMenu string
@@ -1537,11 +1538,7 @@ func (m Model) View() string {
 			Foreground(lipgloss.Color("#666666")).
 			Width(m.width)
 
-		selectedOption := ""
-		if m.questionOptionIndex < len(m.pendingQuestion.Options) {
-			selectedOption = m.pendingQuestion.Options[m.questionOptionIndex].Label
-		}
-		inputView = disabledStyle.Render("│ Selected: " + selectedOption + " (press Enter to submit, ↓ for custom)")
+		inputView = disabledStyle.Render(truncateLine(m.questionSelectionSummary(), m.width))
 	} else {
 		// Normal textarea (for regular input or custom answer)
 		textareaContent := m.textarea.View()
@@ -1568,18 +1565,27 @@ func (m Model) View() string {
 
 	// Help text (now on the right side)
 	var helpStr string
-	if m.showQuestionPrompt {
-		if m.pendingQuestion != nil && m.pendingQuestion.Custom {
-			helpStr = "↑↓: navigate • type: custom answer • enter: submit"
-		} else {
-			helpStr = "↑↓: navigate • enter: submit"
+	if m.pendingApproval != nil && m.approvalEditing {
+		helpStr = "enter: run with these arguments • alt+enter: new line • esc: back"
+	} else if m.pendingApproval != nil {
+		helpStr = "y: approve • n: deny • a: always • e: edit • esc: stop run"
+	} else if m.showQuestionPrompt {
+		switch {
+		case m.pendingQuestion != nil && m.pendingQuestion.Multiple:
+			helpStr = "↑↓/1-9: navigate • space: toggle • enter: submit"
+		case m.pendingQuestion != nil && m.pendingQuestion.Custom:
+			helpStr = "↑↓/1-9: choose • type: custom answer • enter: submit"
+		default:
+			helpStr = "↑↓/1-9: choose • enter: submit"
 		}
 	} else if m.showCommandMenu {
 		helpStr = "↑↓: navigate • enter/tab: select • esc: cancel"
+	} else if hint := m.interruptHint(); hint != "" {
+		helpStr = hint
 	} else if m.processing {
-		helpStr = "ctrl+c: cancel • esc: quit • enter: queue message • /: commands"
+		helpStr = "esc/ctrl+c ctrl+c: stop run • enter: queue message • /: commands"
 	} else {
-		helpStr = "esc: quit • enter: send • alt+enter: new line • /: commands"
+		helpStr = "esc esc: quit • enter: send • alt+enter: new line • /: commands"
 	}
 
 	// Get current working directory
@@ -1612,7 +1618,7 @@ func (m Model) View() string {
 		lipgloss.Left,
 		topBar,
 		messagesView,
-		questionPrompt+commandMenu+inputView,
+		toolPanelView+questionPrompt+commandMenu+inputView,
 		bottomBar,
 	)
 }
@@ -1622,6 +1628,9 @@ func (m Model) renderSeparator() string {
 	var leftPart string
 	if m.processing {
 		leftPart = loadingStyle.Render(m.loadingFrames[m.loadingIndex] + " Processing")
+		if running := m.toolPanel.running(); running > 0 {
+			leftPart += queuedStyle.Render(fmt.Sprintf(" · %d tools running", running))
+		}
 		if len(m.queuedMessages) > 0 {
 			leftPart += queuedStyle.Render(fmt.Sprintf(" (%d queued)", len(m.queuedMessages)))
 		}
@@ -1701,7 +1710,7 @@ func (m Model) renderTopBar() string {
 	switch {
 	case contextPercent >= 90:
 		percentStyle = contextDangerStyle
-	case contextPercent >= 70:
+	case contextPercent >= contextWarningPercent:
 		percentStyle = contextWarningStyle
 	default:
 		percentStyle = tokenStyle
@@ -1709,7 +1718,13 @@ func (m Model) renderTopBar() string {
 
 	tokenStats := fmt.Sprintf("%d↓ %d↑",
 		m.totalInputTokens, m.totalOutputTokens)
+	if price, ok := m.appConfig.PriceFor(m.agentConfig.Model); ok {
+		tokenStats += fmt.Sprintf(" ~$%.2f", price.Cost(m.billedInputTokens, m.totalOutputTokens))
+	}
 	percentText := fmt.Sprintf("%.1f%%", contextPercent)
+	if contextPercent >= contextWarningPercent {
+		percentText += " /compact"
+	}
 
 	// Memory usage
 	memoryText := fmt.Sprintf("%.1fMB", m.memoryMB)
@@ -1860,10 +1875,21 @@ func (m *Model) applySessionTokenMetadata(sess *session.Session) {
 	if totalIn > 0 || totalOut > 0 {
 		m.totalInputTokens = totalIn
 		m.totalOutputTokens = totalOut
+		// Sessions from before billed input was recorded only have the total.
+		m.billedInputTokens = int(sessionMetadataFloat(sess, "billed_input_tokens"))
+		if m.billedInputTokens == 0 {
+			m.billedInputTokens = totalIn
+		}
+	}
+	if current := int(sessionMetadataFloat(sess, "current_context_tokens")); current > 0 {
+		m.contextTokens = current
 	}
 }
 
 func (m Model) currentContextTokenCount() int {
+	if m.contextTokens > 0 {
+		return m.contextTokens
+	}
 	current := int(sessionMetadataFloat(m.session, "current_context_tokens"))
 	if current > 0 {
 		return current
@@ -1944,9 +1970,14 @@ func (m Model) renderMessageWithContext(msg message, prevMsg *message) string {
 			contentStyle = compactionStyle
 		}
 		sb.WriteString(fmt.Sprintf("%s %s%s\n", timestamp, header, indicator))
-		// Wrap assistant content
-		wrapped := wrapText(msg.content, wrapWidth)
-		sb.WriteString(contentStyle.Render(wrapped))
+		// Render assistant markdown; compaction summaries and colorless
+		// terminals get the wrapped text
+		if m.markdown && !isCompactionMetadata(msg.metadata) {
+			sb.WriteString(renderMarkdown(msg.content, wrapWidth))
+		} else {
+			wrapped := wrapText(msg.content, wrapWidth)
+			sb.WriteString(contentStyle.Render(wrapped))
+		}
 
 		// Render tool calls with icons and details
 		for _, tc := range msg.toolCalls {
@@ -1997,10 +2028,17 @@ func (m Model) renderMessageWithContext(msg message, prevMsg *message) string {
 
 			// Format the result with icon and status
 			resultHeader := statusStyle.Render(fmt.Sprintf("  %s %s %s", icon, toolName, statusIcon))
+			if stats := transcript.ResultStats(tr); stats != "" {
+				resultHeader += toolPanelDimStyle.Render(" " + stats)
+			}
 			sb.WriteString(resultHeader + "\n")
 
-			// Show content preview (truncated)
+			// Show content preview (truncated), colored when it is a diff
 			content := tr.Content
+			lineStyle := func(line string) string { return toolResultStyle.Render(line) }
+			if looksLikeDiff(content) {
+				lineStyle = renderDiffLine
+			}
 			if len(content) > 0 {
 				// Limit to first few lines
 				lines := strings.SplitN(content, "\n", 6)
@@ -2014,7 +2052,7 @@ func (m Model) renderMessageWithContext(msg message, prevMsg *message) string {
 					}
 					line = strings.TrimRight(line, " \t\r")
 					line = truncateLine(line, m.width-8)
-					sb.WriteString(toolResultStyle.Render(fmt.Sprintf("    %s", line)) + "\n")
+					sb.WriteString("    " + lineStyle(line) + "\n")
 				}
 			}
 		}
@@ -2059,6 +2097,8 @@ func (m Model) handleUserInput(input string) Model {
 	m.session.AddUserMessage(input)
 	m.lastUserInputTime = time.Now()
 	m.processing = true
+	m.toolPanel.reset()
+	m.viewport.Height = m.viewportHeight()
 
 	// Update sync counter to prevent duplicate messages
 	m.lastSyncedMessageCount = len(m.session.Messages)
@@ -2072,9 +2112,14 @@ func (m Model) runAgent(input string) (tea.Cmd, context.CancelFunc) {
 	// Create a cancellable context
 	ctx, cancel := context.WithCancel(context.Background())
 
+	events := make(chan agent.Event, 32)
+	approvals := make(chan approvalRequestMsg)
+	runDone := make(chan struct{})
+
 	// Capture necessary fields for the goroutine
 	agent := m.agent
 	sess := m.session
+	ctx = tools.WithApprover(ctx, approverFor(m.approvalGrants, sess.ID, approvals, runDone))
 
 	cmd := func() tea.Msg {
 		if err := m.validateActiveProviderConfig(); err != nil {
@@ -2084,7 +2129,9 @@ func (m Model) runAgent(input string) (tea.Cmd, context.CancelFunc) {
 			return agentResponseMsg{err: err}
 		}
 
-		result, usage, err := agent.Run(ctx, sess, input)
+		result, usage, err := agent.RunWithEvents(ctx, sess, input, forwardToolEvents(events))
+		close(events)
+		close(runDone)
 		if err != nil {
 			return agentResponseMsg{err: err}
 		}
@@ -2096,7 +2143,7 @@ func (m Model) runAgent(input string) (tea.Cmd, context.CancelFunc) {
 		}
 	}
 
-	return cmd, cancel
+	return tea.Batch(cmd, waitForToolEvent(events), waitForApproval(approvals, runDone)), cancel
 }
 
 // runAgentResume continues agent execution after answering a question
@@ -2104,14 +2151,21 @@ func (m Model) runAgentResume() (tea.Cmd, context.CancelFunc) {
 	// Create a cancellable context
 	ctx, cancel := context.WithCancel(context.Background())
 
+	events := make(chan agent.Event, 32)
+	approvals := make(chan approvalRequestMsg)
+	runDone := make(chan struct{})
+
 	// Capture necessary fields for the goroutine
 	agent := m.agent
 	sess := m.session
+	ctx = tools.WithApprover(ctx, approverFor(m.approvalGrants, sess.ID, approvals, runDone))
 
 	cmd := func() tea.Msg {
 		// Agent continues from where it left off
 		// The answer was already added as a user message by AnswerQuestion
-		result, usage, err := agent.Run(ctx, sess, "")
+		result, usage, err := agent.RunWithEvents(ctx, sess, "", forwardToolEvents(events))
+		close(events)
+		close(runDone)
 		if err != nil {
 			return agentResponseMsg{err: err}
 		}
@@ -2123,7 +2177,36 @@ func (m Model) runAgentResume() (tea.Cmd, context.CancelFunc) {
 		}
 	}
 
-	return cmd, cancel
+	return tea.Batch(cmd, waitForToolEvent(events), waitForApproval(approvals, runDone)), cancel
+}
+
+// forwardToolEvents passes per-call tool events and usage updates to the
+// TUI. Events are dropped rather than blocking the agent when the UI falls
+// behind.
+func forwardToolEvents(events chan<- agent.Event) func(agent.Event) {
+	return func(ev agent.Event) {
+		switch ev.Type {
+		case agent.EventToolCallStarted, agent.EventToolCallFinished, agent.EventUsageUpdated:
+		default:
+			return
+		}
+		select {
+		case events <- ev:
+		default:
+		}
+	}
+}
+
+// waitForToolEvent delivers the next tool event; it stops once the run has
+// finished and the channel is closed.
+func waitForToolEvent(events <-chan agent.Event) tea.Cmd {
+	return func() tea.Msg {
+		ev, ok := <-events
+		if !ok {
+			return nil
+		}
+		return toolEventMsg{event: ev, events: events}
+	}
 }
 
 // generateTitle generates a session title from the conversation
@@ -2193,33 +2276,17 @@ func (m *Model) SetSize(width, height int) {
 }
 
 // executeCommand executes a slash command and returns the updated model
-func (m Model) executeCommand(cmdName string) (tea.Model, tea.Cmd) {
-	switch cmdName {
-	case "new":
-		return m.createNewSession()
-	case "sessions":
-		return m.showSessions()
-	case "projects":
-		return m.showProjectsSelection()
-	case "provider":
-		return m.showProviderSelection()
-	case "models":
-		return m.showModelsSelection()
-	case "clear":
-		return m.clearConversation()
-	case "help":
-		return m.showHelp()
-	case "logs":
-		return m.showLogs()
-	default:
-		m.messages = append(m.messages, message{
-			role:      "error",
-			content:   fmt.Sprintf("Unknown command: /%s", cmdName),
-			timestamp: time.Now(),
-		})
-		m.viewport.SetContent(m.renderMessages())
-		return m, nil
+func (m Model) executeCommand(cmdName string, args ...string) (tea.Model, tea.Cmd) {
+	if handler, ok := commandHandlers[cmdName]; ok {
+		return handler(m, args)
 	}
+	m.messages = append(m.messages, message{
+		role:      "error",
+		content:   fmt.Sprintf("Unknown command: /%s", cmdName),
+		timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	return m, nil
 }
 
 // createNewSession creates a new session
@@ -2243,13 +2310,18 @@ func (m Model) createNewSession() (tea.Model, tea.Cmd) {
 
 	// Reset model state for new session
 	m.session = newSess
-	m.agent = agent.New(m.agentConfig, m.llmClient, m.toolManager, m.sessionManager)
+	m.agent = m.agentForSession()
 	m.messages = make([]message, 0)
 	m.taskSummary = ""
+	m.titleRequested = false
 	m.totalInputTokens = 0
 	m.totalOutputTokens = 0
+	m.billedInputTokens = 0
+	m.contextTokens = 0
 	m.queuedMessages = nil
 	m.lastUserInputTime = time.Now()
+	m.toolPanel.reset()
+	m.viewport.Height = m.viewportHeight()
 
 	// Show confirmation
 	m.messages = append(m.messages, message{
@@ -2329,51 +2401,27 @@ func (m Model) switchToSession(sessionID string) Model {
 
 	// Update model with new session
 	m.session = newSess
-	m.agent = agent.New(m.agentConfig, m.llmClient, m.toolManager, m.sessionManager)
+	m.agent = m.agentForSession()
 	m.taskSummary = newSess.Title
+	m.titleRequested = false
 	m.totalInputTokens = 0
 	m.totalOutputTokens = 0
+	m.billedInputTokens = 0
+	m.contextTokens = 0
 	m.queuedMessages = nil
 	m.lastUserInputTime = time.Now()
+	m.toolPanel.reset()
+	m.clearQuestion()
+	m.loadPendingQuestion(newSess)
 
 	// Load messages from session
-	m.messages = make([]message, 0, len(newSess.Messages))
-	for _, msg := range newSess.Messages {
-		m.messages = append(m.messages, message{
-			role:        msg.Role,
-			content:     msg.Content,
-			timestamp:   msg.Timestamp,
-			toolCalls:   msg.ToolCalls,
-			toolResults: msg.ToolResults,
-			metadata:    msg.Metadata,
-		})
-	}
+	m.messages = messagesFromSession(newSess)
 	m.applySessionTokenMetadata(newSess)
 
 	logging.Info("Switched to session: %s", sessionID)
 	return m
 }
 
-// clearConversation clears the current conversation
-func (m Model) clearConversation() (tea.Model, tea.Cmd) {
-	m.messages = make([]message, 0)
-	m.session.Messages = nil
-	m.totalInputTokens = 0
-	m.totalOutputTokens = 0
-	m.queuedMessages = nil
-	m.sessionManager.Save(m.session)
-
-	m.messages = append(m.messages, message{
-		role:      "system",
-		content:   "Conversation cleared",
-		timestamp: time.Now(),
-	})
-	m.viewport.SetContent(m.renderMessages())
-	m.viewport.GotoBottom()
-
-	return m, nil
-}
-
 // showHelp shows available commands
 func (m Model) showHelp() (tea.Model, tea.Cmd) {
 	var helpText strings.Builder
@@ -2540,101 +2588,15 @@ func (m Model) renderLogsView() string {
 	return commandMenuStyle.Width(m.width - 4).Render(content)
 }
 
-// calculateQuestionPromptHeight calculates how many lines the question prompt will take
-func (m Model) calculateQuestionPromptHeight() int {
-	if !m.showQuestionPrompt || m.pendingQuestion == nil {
-		return 0
-	}
-
-	height := 0
-	height += 1                              // Top separator
-	height += 1                              // Header line
-	height += len(m.pendingQuestion.Options) // Options (one per line)
-	if m.pendingQuestion.Custom {
-		height += 1 // Empty line before custom hint
-		height += 1 // Custom hint line
+// viewportHeight returns the height left for the messages viewport by the
+// bars, the input, the question and approval prompts and the tool panel.
+func (m Model) viewportHeight() int {
+	fixedHeight := 5 // topBar + textarea + bottomBar
+	viewportHeight := m.height - fixedHeight - m.calculateQuestionPromptHeight() - m.approvalPromptHeight() - m.toolPanel.height()
+	if viewportHeight < 1 {
+		viewportHeight = 1
 	}
-	height += 1 // Bottom separator
-
-	return height
-}
-
-// renderQuestionPrompt renders the question prompt overlay
-func (m Model) renderQuestionPrompt() string {
-	if !m.showQuestionPrompt || m.pendingQuestion == nil {
-		return ""
-	}
-
-	var sb strings.Builder
-
-	// Header (compact, one line)
-	questionHeaderStyle := lipgloss.NewStyle().
-		Bold(true).
-		Foreground(lipgloss.Color("#FF9800"))
-
-	sb.WriteString(questionHeaderStyle.Render("❓ " + m.pendingQuestion.Header + ": " + m.pendingQuestion.Question))
-	sb.WriteString("\n")
-
-	// Options (compact, one line each)
-	optionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#AAAAAA"))
-
-	selectedOptionStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#2196F3")).
-		Bold(true)
-
-	for i, opt := range m.pendingQuestion.Options {
-		var icon string
-		var style lipgloss.Style
-
-		if m.pendingQuestion.Multiple {
-			icon = "☐"
-			if i == m.questionOptionIndex {
-				icon = "☑"
-			}
-		} else {
-			icon = "○"
-			if i == m.questionOptionIndex {
-				icon = "◉"
-			}
-		}
-
-		if i == m.questionOptionIndex {
-			style = selectedOptionStyle
-		} else {
-			style = optionStyle
-		}
-
-		text := fmt.Sprintf("  %s %s", icon, opt.Label)
-		sb.WriteString(style.Render(text))
-		sb.WriteString("\n")
-	}
-
-	// Custom answer hint if enabled
-	if m.pendingQuestion.Custom {
-		sb.WriteString("\n")
-
-		// Check if custom field is selected
-		isCustomSelected := m.questionOptionIndex == -1
-
-		var hintStyle lipgloss.Style
-		var hintText string
-		if isCustomSelected {
-			hintStyle = selectedOptionStyle
-			hintText = "  💡 Custom answer (type below) ▼"
-		} else {
-			hintStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("#888888"))
-			hintText = "  💡 Custom answer (press ↓ to select)"
-		}
-		sb.WriteString(hintStyle.Render(hintText))
-	}
-
-	// Simple separator line instead of border (more compact)
-	separator := lipgloss.NewStyle().
-		Foreground(lipgloss.Color("#FF9800")).
-		Render(strings.Repeat("─", m.width))
-
-	return separator + "\n" + sb.String() + separator
+	return viewportHeight
 }
 
 // renderCommandMenu renders the command menu popup
@@ -2856,134 +2818,66 @@ func (m Model) showModelsSelection() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	// For LM Studio, fetch models from the API
-	if m.appConfig.ActiveProvider == string(config.ProviderLMStudio) {
-		return m.fetchLMStudioModels()
-	}
-
-	// For other providers, show known models
-	return m.showStaticModels()
+	return m.fetchModels()
 }
 
-// fetchLMStudioModels fetches models from LM Studio API
-func (m Model) fetchLMStudioModels() (tea.Model, tea.Cmd) {
-	provider := m.appConfig.GetActiveProvider()
-	baseURL := "http://localhost:1234/v1"
-	if provider != nil && provider.BaseURL != "" {
-		baseURL = provider.BaseURL
-	}
-
-	client := lmstudio.NewClient("", "", baseURL)
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	models, err := client.ListModels(ctx)
-	if err != nil {
+// fetchModels lists the active provider's models through its client.
+func (m Model) fetchModels() (tea.Model, tea.Cmd) {
+	providerType := config.ProviderType(m.appConfig.ActiveProvider)
+	providerDef := config.GetProviderDefinition(providerType)
+	if providerDef == nil {
 		m.messages = append(m.messages, message{
 			role:      "error",
-			content:   fmt.Sprintf("Failed to fetch models from LM Studio: %v", err),
+			content:   "Unknown provider",
 			timestamp: time.Now(),
 		})
 		m.viewport.SetContent(m.renderMessages())
 		return m, nil
 	}
 
-	m.availableModels = make([]string, len(models))
-	for i, model := range models {
-		m.availableModels[i] = model.ID
+	client := m.llmClient
+	if client == nil {
+		client = m.createLLMClient(providerType)
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	if len(m.availableModels) == 0 {
+	models, err := llm.ListModelIDs(ctx, client)
+	if errors.Is(err, llm.ErrModelListingUnsupported) {
+		models, err = []string{providerDef.DefaultModel}, nil
+	}
+	if err != nil {
 		m.messages = append(m.messages, message{
 			role:      "error",
-			content:   "No models loaded in LM Studio. Please load a model first.",
+			content:   fmt.Sprintf("Failed to fetch models from %s: %v", providerDef.DisplayName, err),
 			timestamp: time.Now(),
 		})
 		m.viewport.SetContent(m.renderMessages())
 		return m, nil
 	}
 
-	m.showModelsMenu = true
-	m.modelsMenuIndex = 0
-
-	// Find current model in list
-	currentModel := m.appConfig.DefaultModel
-	for i, model := range m.availableModels {
-		if model == currentModel {
-			m.modelsMenuIndex = i
-			break
+	if len(models) == 0 {
+		content := fmt.Sprintf("%s returned no models.", providerDef.DisplayName)
+		if providerType == config.ProviderLMStudio {
+			content = "No models loaded in LM Studio. Please load a model first."
 		}
-	}
-
-	return m, nil
-}
-
-// showStaticModels shows models for providers with known model lists
-func (m Model) showStaticModels() (tea.Model, tea.Cmd) {
-	providerDef := config.GetProviderDefinition(config.ProviderType(m.appConfig.ActiveProvider))
-	if providerDef == nil {
 		m.messages = append(m.messages, message{
 			role:      "error",
-			content:   "Unknown provider",
+			content:   content,
 			timestamp: time.Now(),
 		})
 		m.viewport.SetContent(m.renderMessages())
 		return m, nil
 	}
 
-	// Define known models for each provider
-	switch config.ProviderType(m.appConfig.ActiveProvider) {
-	case config.ProviderKimi:
-		m.availableModels = []string{"kimi-k2.5", "kimi-k2", "kimi-for-coding"}
-	case config.ProviderOpenRouter:
-		m.availableModels = []string{
-			"openrouter/auto",
-			"anthropic/claude-opus-4-6",
-			"anthropic/claude-sonnet-4-6",
-			"anthropic/claude-opus-4-5",
-			"anthropic/claude-sonnet-4-5",
-			"openai/gpt-4.1",
-			"openai/gpt-4.1-mini",
-			"google/gemini-3-flash-preview",
-			"google/gemini-2.5-pro",
-			"meta-llama/llama-4-maverick",
-		}
-	case config.ProviderAnthropic:
-		m.availableModels = []string{
-			"claude-opus-4-6",
-			"claude-sonnet-4-6",
-			"claude-opus-4-5",
-			"claude-opus-4-5-20251101",
-			"claude-sonnet-4-5",
-			"claude-sonnet-4-5-20250929",
-			"claude-haiku-4-5",
-			"claude-opus-4-1",
-			"claude-opus-4-0",
-			"claude-sonnet-4-0",
-		}
-	case config.ProviderGoogle:
-		m.availableModels = []string{
-			"gemini-3-pro-preview",
-			"gemini-3-flash-preview",
-			"gemini-2.5-pro",
-			"gemini-2.5-flash",
-			"gemini-2.5-flash-image",
-			"gemini-2.5-flash-lite",
-			"gemini-2.0-flash",
-			"gemini-2.0-flash-lite",
-		}
-	case config.ProviderOpenAI:
-		m.availableModels = []string{"gpt-4.1", "gpt-4.1-mini", "gpt-4o-mini"}
-	default:
-		m.availableModels = []string{providerDef.DefaultModel}
-	}
-
+	m.availableModels = models
 	m.showModelsMenu = true
 	m.modelsMenuIndex = 0
 
-	// Find current model
+	// Find current model in list
+	currentModel := m.appConfig.DefaultModel
 	for i, model := range m.availableModels {
-		if model == m.appConfig.DefaultModel {
+		if model == currentModel {
 			m.modelsMenuIndex = i
 			break
 		}
@@ -3182,7 +3076,7 @@ func (m Model) activateProvider(providerType config.ProviderType) (tea.Model, te
 	m.llmClient = m.createLLMClient(providerType)
 
 	// Update agent with new client
-	m.agent = agent.New(m.agentConfig, m.llmClient, m.toolManager, m.sessionManager)
+	m.agent = m.agentForSession()
 
 	m.showProviderMenu = false
 	m.providerMenuStep = 0
@@ -3507,6 +3401,120 @@ func (m Model) saveProviderCredentials() (tea.Model, tea.Cmd) {
 	return m.activateProvider(providerType)
 }
 
+// setSessionModel pins the current session to a model without changing the
+// global default; "default" clears the pin and no argument shows it.
+func (m Model) setSessionModel(modelName string) (tea.Model, tea.Cmd) {
+	modelName = strings.TrimSpace(modelName)
+	var content string
+	switch {
+	case modelName == "":
+		if pinned := m.session.Model(); pinned != "" {
+			content = fmt.Sprintf("Session model: %s (use /model default to unpin)", pinned)
+		} else {
+			content = fmt.Sprintf("Session uses the default model: %s", m.agentConfig.Model)
+		}
+	case m.processing:
+		content = "Cannot switch model while the agent is running"
+	default:
+		if strings.EqualFold(modelName, "default") {
+			modelName = ""
+		}
+		m.session.SetModel(modelName)
+		if len(m.session.Messages) > 0 {
+			if err := m.sessionManager.Save(m.session); err != nil {
+				logging.Error("Failed to save session model: %v", err)
+			}
+		}
+		m.agent = m.agentForSession()
+		if modelName == "" {
+			content = fmt.Sprintf("Session model unpinned, using default: %s", m.agentConfig.Model)
+		} else {
+			content = fmt.Sprintf("Session model switched to: %s", modelName)
+		}
+	}
+
+	m.messages = append(m.messages, message{
+		role:      "system",
+		content:   content,
+		timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
+// agentForSession builds the agent for the current session, applying the
+// session's model and temperature overrides on top of the TUI defaults.
+func (m Model) agentForSession() *agent.Agent {
+	cfg := m.agentConfig
+	if m.session != nil {
+		if pinned := m.session.Model(); pinned != "" {
+			cfg.Model = pinned
+		}
+		if temperature, ok := m.session.Temperature(); ok {
+			cfg.Temperature = temperature
+		}
+	}
+	if active, err := m.loadedSkills(); err == nil && len(active) > 0 {
+		cfg.SkillsPrompt = skillsLoader.PromptSection(active, maxSkillBodyBytes)
+	}
+	return agent.New(cfg, m.llmClient, m.toolManager, m.sessionManager)
+}
+
+// maxSkillBodyBytes caps each always-loaded skill in the system prompt.
+const maxSkillBodyBytes = 32 * 1024
+
+// loadedSkills returns the markdown skills loaded into the current session's
+// system prompt: the session's own selection when set, otherwise every skill
+// of the configured folder that is not disabled in settings.
+func (m Model) loadedSkills() ([]*skillsLoader.Skill, error) {
+	folder, err := skillsLoader.ResolveFolder(os.Getenv(skillsLoader.FolderSettingKey))
+	if err != nil {
+		return nil, err
+	}
+	var selected []string
+	if m.session != nil {
+		selected = skillsLoader.SessionSelection(m.session.Metadata)
+	}
+	disabled := skillsLoader.ParsePathList(os.Getenv(skillsLoader.DisabledSettingKey), folder)
+	active, _, err := skillsLoader.LoadActive(folder, disabled, selected)
+	return active, err
+}
+
+// showSkills lists the markdown skills loaded for the current session.
+func (m Model) showSkills() (tea.Model, tea.Cmd) {
+	var content strings.Builder
+	active, err := m.loadedSkills()
+	switch {
+	case err != nil:
+		content.WriteString(fmt.Sprintf("No skills loaded: %v", err))
+	case len(active) == 0:
+		content.WriteString("No skills loaded")
+	default:
+		content.WriteString(fmt.Sprintf("Loaded skills (%d):\n", len(active)))
+		for _, skill := range active {
+			mode := "on demand"
+			if skill.Strategy == skillsLoader.StrategyAlways {
+				mode = "always"
+			}
+			line := fmt.Sprintf("  %s [%s, %s]", skill.Name, skill.RelativePath, mode)
+			if skill.Description != "" {
+				line += " - " + skill.Description
+			}
+			content.WriteString(line + "\n")
+		}
+	}
+
+	m.messages = append(m.messages, message{
+		role:      "system",
+		content:   strings.TrimRight(content.String(), "\n"),
+		timestamp: time.Now(),
+	})
+	m.viewport.SetContent(m.renderMessages())
+	m.viewport.GotoBottom()
+	return m, nil
+}
+
 // selectModel selects a model for the current provider
 func (m Model) selectModel(modelName string) (tea.Model, tea.Cmd) {
 	m.appConfig.DefaultModel = modelName
@@ -3537,7 +3545,7 @@ func (m Model) selectModel(modelName string) (tea.Model, tea.Cmd) {
 
 	// Recreate LLM client with new model
 	m.llmClient = m.createLLMClient(config.ProviderType(m.appConfig.ActiveProvider))
-	m.agent = agent.New(m.agentConfig, m.llmClient, m.toolManager, m.sessionManager)
+	m.agent = m.agentForSession()
 
 	m.showModelsMenu = false
 
@@ -3618,6 +3626,9 @@ func (m Model) renderProviderMenu() string {
 	return commandMenuStyle.Width(m.width - 4).Render(content)
 }
 
+// maxModelsMenuItems is how many models the model menu shows at once.
+const maxModelsMenuItems = 15
+
 // renderModelsMenu renders the model selection menu
 func (m Model) renderModelsMenu() string {
 	if !m.showModelsMenu || len(m.availableModels) == 0 {
@@ -3628,7 +3639,19 @@ func (m Model) renderModelsMenu() string {
 	items = append(items, lipgloss.NewStyle().Bold(true).Render("Select Model (Enter to select, Esc to cancel):"))
 	items = append(items, "")
 
-	for i, model := range m.availableModels {
+	// Providers such as OpenRouter list hundreds of models; show a window
+	// around the selection.
+	start, end := 0, len(m.availableModels)
+	if end > maxModelsMenuItems {
+		start = min(max(m.modelsMenuIndex-maxModelsMenuItems/2, 0), end-maxModelsMenuItems)
+		end = start + maxModelsMenuItems
+	}
+	if start > 0 {
+		items = append(items, commandItemStyle.Render(fmt.Sprintf("↑ %d more", start)))
+	}
+
+	for i := start; i < end; i++ {
+		model := m.availableModels[i]
 		current := ""
 		if model == m.appConfig.DefaultModel {
 			current = " (current)"
@@ -3643,6 +3666,9 @@ func (m Model) renderModelsMenu() string {
 		}
 		items = append(items, item)
 	}
+	if end < len(m.availableModels) {
+		items = append(items, commandItemStyle.Render(fmt.Sprintf("↓ %d more", len(m.availableModels)-end)))
+	}
 
 	content := strings.Join(items, "\n")
 	return commandMenuStyle.Width(m.width - 4).Render(content)